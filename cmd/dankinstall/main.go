@@ -1,9 +1,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
+	"github.com/AvengeMedia/danklinux/internal/distros"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
 	"github.com/AvengeMedia/danklinux/internal/tui"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -11,10 +14,72 @@ import (
 var Version = "dev"
 
 func main() {
+	privesc.HandleAskpass()
+
+	plain := flag.Bool("plain", false, "Render the install flow as sequential prompts and log lines (for screen readers, dumb terminals and CI)")
+	progressJSON := flag.Bool("progress-json", false, "Emit line-delimited JSON progress events on stdout for external frontends")
+	escalation := flag.String("escalation", "auto", "Privilege escalation tool: auto, sudo or doas")
+	shellRef := flag.String("shell-ref", "", "Install the DMS shell config at a tag, branch or pull request (e.g. v0.1.20, master, pr/123) instead of the latest release")
+	stagingDir := flag.String("staging-dir", "", "Write generated configs into this directory (e.g. a chezmoi/stow source tree) instead of ~/.config")
+	flag.Parse()
+
+	tool, err := privesc.ParseTool(*escalation)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	privesc.SetTool(tool)
+
+	// A bad definition only loses that distribution; the rest still install
+	if err := distros.LoadDistroPlugins(distros.PluginDir); err != nil {
+		fmt.Printf("Warning: skipped distro definitions: %v\n", err)
+	}
+
+	if err := privesc.Preauthenticate(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := distros.SetShellRef(*shellRef); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	model := tui.NewModel(Version)
+	if *stagingDir != "" {
+		model.SetStagingDir(*stagingDir)
+	}
+
+	if *progressJSON {
+		if err := tui.RunProgressJSON(model); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *plain || os.Getenv("TERM") == "dumb" {
+		if err := tui.RunPlain(model); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	broker, err := privesc.StartBroker(model.PasswordPrompter())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	p := tea.NewProgram(model, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	finalModel, err := p.Run()
+	broker.Close()
+	if err != nil {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
 	}
+
+	if m, ok := finalModel.(tui.Model); ok {
+		m.PrintStagedMapping(os.Stdout)
+	}
 }
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/AvengeMedia/danklinux/internal/autostart"
+)
+
+func runAutostartList(asJSON bool) error {
+	entries, err := autostart.DefaultPaths().List()
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		if entries == nil {
+			entries = []autostart.Entry{}
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	for _, entry := range entries {
+		line := fmt.Sprintf("%-9s %-24s %s", entry.Source, entry.Name, entry.Command)
+		if !entry.Enabled {
+			line += " (disabled)"
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func runAutostartAdd(name, command string, xdg bool) error {
+	entry, err := autostart.DefaultPaths().Add(name, command, xdg)
+	if err != nil {
+		return err
+	}
+	if xdg {
+		fmt.Printf("Added %s (%s)\n", entry.Name, entry.Path)
+	} else {
+		fmt.Printf("Added %s, starts on next login\n", entry.Name)
+	}
+	return nil
+}
+
+func runAutostartRemove(name string) error {
+	entry, err := autostart.DefaultPaths().Remove(name)
+	if err != nil {
+		return err
+	}
+	if entry.System {
+		fmt.Printf("Disabled system entry %s\n", entry.Name)
+	} else {
+		fmt.Printf("Removed %s\n", entry.Name)
+	}
+	return nil
+}
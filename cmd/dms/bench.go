@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/server"
+	"github.com/AvengeMedia/danklinux/internal/server/wayland"
+)
+
+// runBenchGamma times ramp generation locally for each size, then shows
+// the apply latency the running server measured against the compositor
+func runBenchGamma(sizes []uint, iterations int) error {
+	rampSizes := wayland.BenchRampSizes
+	if len(sizes) > 0 {
+		rampSizes = make([]uint32, len(sizes))
+		for i, size := range sizes {
+			rampSizes[i] = uint32(size)
+		}
+	}
+
+	results, err := wayland.BenchRamps(rampSizes, iterations, 1.0)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Ramp generation, average of %d runs:\n", iterations)
+	fmt.Printf("  %-6s %10s %10s %10s %10s\n", "size", "generate", "pack", "write", "total")
+	for _, r := range results {
+		fmt.Printf("  %-6d %10s %10s %10s %10s\n", r.RampSize, formatBench(r.Generate), formatBench(r.Pack), formatBench(r.Write), formatBench(r.Total()))
+	}
+
+	// A transition sends one frame every 33ms
+	for _, r := range results {
+		if r.Total() > 10*time.Millisecond {
+			fmt.Printf("  size %d takes over 10ms per frame and can stutter transitions\n", r.RampSize)
+		}
+	}
+
+	fmt.Println()
+	result, err := server.Call("wayland.gamma.getState", nil)
+	if err != nil {
+		fmt.Printf("Apply latency: unavailable (%v)\n", err)
+		return nil
+	}
+	var state wayland.State
+	if err := json.Unmarshal(result, &state); err != nil {
+		return err
+	}
+
+	latency := state.ApplyLatency
+	if latency.Count == 0 {
+		fmt.Println("Apply latency: nothing applied yet")
+		return nil
+	}
+	fmt.Printf("Apply latency over %d applies: last %.2fms, average %.2fms, slowest %.2fms\n", latency.Count, latency.LastMs, latency.AvgMs, latency.MaxMs)
+	if lookups := latency.RampCacheHits + latency.RampCacheMisses; lookups > 0 {
+		fmt.Printf("Ramp cache: %d of %d ramps reused\n", latency.RampCacheHits, lookups)
+	}
+	for _, out := range latency.Outputs {
+		name := out.Output
+		if name == "" {
+			name = "(unnamed)"
+		}
+		fmt.Printf("  %-16s size %-5d %.2fms\n", name, out.RampSize, out.Ms)
+	}
+	return nil
+}
+
+func formatBench(d time.Duration) string {
+	return fmt.Sprintf("%.1fµs", float64(d.Nanoseconds())/1000)
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/AvengeMedia/danklinux/internal/log"
 	"github.com/AvengeMedia/danklinux/internal/plugins"
 	"github.com/AvengeMedia/danklinux/internal/server"
+	"github.com/AvengeMedia/danklinux/internal/server/wallpaper"
+	"github.com/AvengeMedia/danklinux/internal/version"
 	"github.com/spf13/cobra"
 )
 
@@ -57,6 +59,522 @@ var ipcCmd = &cobra.Command{
 	},
 }
 
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Lock the session",
+	Long:  "Show the DMS lock screen via IPC, falling back to loginctl lock-session when the shell isn't running",
+	Run: func(cmd *cobra.Command, args []string) {
+		now, _ := cmd.Flags().GetBool("now")
+		suspendAfter, _ := cmd.Flags().GetDuration("suspend-after")
+		if err := runLock(now, suspendAfter); err != nil {
+			log.Fatalf("Error locking session: %v", err)
+		}
+	},
+}
+
+var dank16Cmd = &cobra.Command{
+	Use:   "dank16 <primary-color>",
+	Short: "Generate the dank16 terminal palette",
+	Long:  "Generate a 16 color terminal palette from the theme's primary color. With --sync, write it for Ghostty, Kitty, Alacritty and foot and apply it to running terminals",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		light, _ := cmd.Flags().GetBool("light")
+		sync, _ := cmd.Flags().GetBool("sync")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		if err := runDank16(args[0], light, sync, jsonOutput); err != nil {
+			log.Fatalf("Error generating palette: %v", err)
+		}
+	},
+}
+
+var wallpaperCmd = &cobra.Command{
+	Use:   "wallpaper",
+	Short: "Manage wallpapers",
+	Long:  "Set per-output wallpapers, transitions and slideshows through the running dms server (swww or hyprpaper)",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runWallpaperGet(); err != nil {
+			log.Fatalf("Error getting wallpaper state: %v", err)
+		}
+	},
+}
+
+var wallpaperSetCmd = &cobra.Command{
+	Use:   "set <image>",
+	Short: "Set the wallpaper",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		fill, _ := cmd.Flags().GetString("fill")
+		if err := runWallpaperSet(args[0], output, fill); err != nil {
+			log.Fatalf("Error setting wallpaper: %v", err)
+		}
+	},
+}
+
+var wallpaperSlideshowCmd = &cobra.Command{
+	Use:   "slideshow <directory>",
+	Short: "Cycle through the images in a directory",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		interval, _ := cmd.Flags().GetDuration("interval")
+		shuffle, _ := cmd.Flags().GetBool("shuffle")
+		outputs, _ := cmd.Flags().GetStringSlice("output")
+		if err := runWallpaperSlideshow(args[0], interval, shuffle, outputs); err != nil {
+			log.Fatalf("Error starting slideshow: %v", err)
+		}
+	},
+}
+
+var wallpaperNextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Advance the slideshow",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := callAndPrint("wallpaper.next", nil); err != nil {
+			log.Fatalf("Error advancing slideshow: %v", err)
+		}
+	},
+}
+
+var wallpaperStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the slideshow",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := callAndPrint("wallpaper.stopSlideshow", nil); err != nil {
+			log.Fatalf("Error stopping slideshow: %v", err)
+		}
+	},
+}
+
+var wallpaperTransitionCmd = &cobra.Command{
+	Use:   "transition <type>",
+	Short: "Set the wallpaper transition (swww only)",
+	Long:  "Set the wallpaper transition: " + strings.Join(wallpaper.Transitions, ", "),
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		duration, _ := cmd.Flags().GetFloat64("duration")
+		if err := runWallpaperTransition(args[0], duration); err != nil {
+			log.Fatalf("Error setting transition: %v", err)
+		}
+	},
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and change configuration",
+	Long:  "Read and change configuration. Plain keys are DMS shell settings (settings.json, values are checked against the current type); niri.<option> edits ~/.config/niri/config.kdl in place (e.g. niri.gaps, niri.layout.focus-ring.width, niri.binds.Mod+T)",
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a configuration value",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runConfigGet(args[0]); err != nil {
+			log.Fatalf("Error reading %s: %v", args[0], err)
+		}
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>...",
+	Short: "Change a configuration value",
+	Args:  cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runConfigSet(args[0], args[1:]); err != nil {
+			log.Fatalf("Error setting %s: %v", args[0], err)
+		}
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list [prefix]",
+	Short: "List shell settings",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		prefix := ""
+		if len(args) > 0 {
+			prefix = args[0]
+		}
+		if err := runConfigList(prefix); err != nil {
+			log.Fatalf("Error listing settings: %v", err)
+		}
+	},
+}
+
+var configDefaultsCmd = &cobra.Command{
+	Use:   "defaults [category] [app]",
+	Short: "Show or set default applications",
+	Long:  "Show or set the default browser, terminal, file-manager and image-viewer. With a category, also lists the installed apps that can handle it; with an app (desktop file ID, e.g. firefox or org.gnome.Nautilus.desktop), makes it the default in ~/.config/mimeapps.list (terminals go to ~/.config/xdg-terminals.list)",
+	Args:  cobra.MaximumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runConfigDefaults(args); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	},
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync settings between machines",
+	Long:  "Sync shell settings, theme, dms config and installed plugins through a git remote or a shared folder",
+}
+
+var syncInitCmd = &cobra.Command{
+	Use:   "init <git-remote|folder>",
+	Short: "Set up settings sync on this machine",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runSyncInit(args[0]); err != nil {
+			log.Fatalf("Error setting up sync: %v", err)
+		}
+	},
+}
+
+var syncStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which synced items changed here or remotely",
+	Run: func(cmd *cobra.Command, args []string) {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		if err := runSyncStatus(asJSON); err != nil {
+			log.Fatalf("Error getting sync status: %v", err)
+		}
+	},
+}
+
+var syncPushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Push local settings to the sync folder",
+	Run: func(cmd *cobra.Command, args []string) {
+		force, _ := cmd.Flags().GetBool("force")
+		if err := runSyncPush(force); err != nil {
+			log.Fatalf("Error pushing settings: %v", err)
+		}
+	},
+}
+
+var syncPullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Apply settings from the sync folder",
+	Run: func(cmd *cobra.Command, args []string) {
+		force, _ := cmd.Flags().GetBool("force")
+		if err := runSyncPull(force); err != nil {
+			log.Fatalf("Error pulling settings: %v", err)
+		}
+	},
+}
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Switch between named settings profiles",
+	Long:  "Switch between profiles defined in ~/.config/dms/profiles.json, each bundling night light, wallpaper, audio output, VPN and monitor layout",
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles, marking the active one",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runProfileList(); err != nil {
+			log.Fatalf("Error listing profiles: %v", err)
+		}
+	},
+}
+
+var profileApplyCmd = &cobra.Command{
+	Use:   "apply [name]",
+	Short: "Apply a profile",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		auto, _ := cmd.Flags().GetBool("auto")
+		if auto == (len(args) == 1) {
+			log.Fatal("Specify either a profile name or --auto")
+		}
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		}
+		if err := runProfileApply(name, auto); err != nil {
+			log.Fatalf("Error applying profile: %v", err)
+		}
+	},
+}
+
+var autostartCmd = &cobra.Command{
+	Use:   "autostart",
+	Short: "Manage programs started with the session",
+	Long:  "Manage XDG autostart entries and the exec-once / spawn-at-startup lines dms keeps in sync across the Hyprland and niri configs",
+}
+
+var autostartListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List autostart entries from dms, XDG autostart and the compositor configs",
+	Run: func(cmd *cobra.Command, args []string) {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		if err := runAutostartList(asJSON); err != nil {
+			log.Fatalf("Error listing autostart entries: %v", err)
+		}
+	},
+}
+
+var autostartAddCmd = &cobra.Command{
+	Use:   "add <command...>",
+	Short: "Start a command with the session",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		xdg, _ := cmd.Flags().GetBool("xdg")
+		if err := runAutostartAdd(name, strings.Join(args, " "), xdg); err != nil {
+			log.Fatalf("Error adding autostart entry: %v", err)
+		}
+	},
+}
+
+var autostartRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Stop starting an entry with the session",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runAutostartRemove(args[0]); err != nil {
+			log.Fatalf("Error removing autostart entry: %v", err)
+		}
+	},
+}
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Show recent shell logs or recorded crashes",
+	Long:  "Print the last lines of the running shell's log, or with --crashes list the quickshell crashes and dms panics recorded in $XDG_STATE_HOME/dms/crashes",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		showCrashes, _ := cmd.Flags().GetBool("crashes")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		lines, _ := cmd.Flags().GetInt("lines")
+
+		var err error
+		if showCrashes {
+			err = runLogsCrashes(asJSON)
+		} else {
+			err = runLogs(lines)
+		}
+		if err != nil {
+			log.Fatalf("Error reading logs: %v", err)
+		}
+	},
+}
+
+var reportIssueCmd = &cobra.Command{
+	Use:   "report-issue",
+	Short: "Prepare a bug report with versions, checks and redacted logs",
+	Long:  "Assemble component versions, health checks and recent logs into a markdown issue template. Secrets, addresses and user names are redacted, and nothing is sent: the report is printed or saved, and --open only opens a prefilled GitHub issue page for you to review",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		title, _ := cmd.Flags().GetString("title")
+		repo, _ := cmd.Flags().GetString("repo")
+		lines, _ := cmd.Flags().GetInt("lines")
+		open, _ := cmd.Flags().GetBool("open")
+		if err := runReportIssue(output, title, repo, lines, open); err != nil {
+			log.Fatalf("Error creating report: %v", err)
+		}
+	},
+}
+
+var testSessionCmd = &cobra.Command{
+	Use:   "test-session",
+	Short: "Preview DMS in a nested compositor window",
+	Long:  "Start niri or Hyprland nested in a window of the current Wayland session, using your compositor config with its startup programs replaced by dms run, so config and theme changes can be tried without logging out",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		compositor, _ := cmd.Flags().GetString("compositor")
+		config, _ := cmd.Flags().GetString("config")
+		if err := runTestSession(compositor, config); err != nil {
+			log.Fatalf("Error running test session: %v", err)
+		}
+	},
+}
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Show compositor keybindings",
+}
+
+var keysCheatsheetCmd = &cobra.Command{
+	Use:   "cheatsheet",
+	Short: "Show a cheat sheet of your keybindings",
+	Long:  "Print the keybindings of your niri or Hyprland config, grouped by section, and save them to ~/.local/state/DankMaterialShell/keybinds.md with an image next to it. The installer writes the first one; running this picks up binds you added since",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		compositor, _ := cmd.Flags().GetString("compositor")
+		open, _ := cmd.Flags().GetBool("open")
+		if err := runKeysCheatsheet(compositor, open); err != nil {
+			log.Fatalf("Error showing keybindings: %v", err)
+		}
+	},
+}
+
+var setupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Set up system integration",
+}
+
+var setupPrivilegesCmd = &cobra.Command{
+	Use:   "privileges",
+	Short: "Install polkit rules for privileged actions",
+	Long:  "Install polkit rules so setting the hostname, restarting the greeter, joining the greeter group and toggling greeter theme sync prompt through the polkit agent, instead of needing the CLI to be run with sudo. Group and theme sync changes run this dms binary as a fixed-argument helper, so it must be installed root-owned. Members of the admin group authenticate as themselves, once every few minutes",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		printOnly, _ := cmd.Flags().GetBool("print")
+		if err := runSetupPrivileges(printOnly); err != nil {
+			log.Fatalf("Error setting up privileges: %v", err)
+		}
+	},
+}
+
+var privilegedCmd = &cobra.Command{
+	Use:    "privileged <action>",
+	Short:  "Run a fixed privileged action (used through pkexec)",
+	Hidden: true,
+	Args:   cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runPrivileged(args); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	},
+}
+
+var networkCmd = &cobra.Command{
+	Use:   "network",
+	Short: "Manage NetworkManager connection profiles",
+}
+
+var networkExportCmd = &cobra.Command{
+	Use:   "export <ssid|vpn|uuid> <file>",
+	Short: "Export a saved connection profile to a file",
+	Long:  "Write a saved NetworkManager profile to a file for moving it to another machine. Machine-specific values like the device MAC address are left out, and so are passwords unless --secrets is given",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		withSecrets, _ := cmd.Flags().GetBool("secrets")
+		if err := runNetworkExport(args[0], args[1], withSecrets); err != nil {
+			log.Fatalf("Error exporting profile: %v", err)
+		}
+	},
+}
+
+var networkImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a connection profile exported with dms network export",
+	Long:  "Add an exported profile to NetworkManager. A saved profile with the same UUID, or the same name and type, is a conflict: the import stops unless --on-conflict says to replace it, import under a new name or skip",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		onConflict, _ := cmd.Flags().GetString("on-conflict")
+		if err := runNetworkImport(args[0], onConflict); err != nil {
+			log.Fatalf("Error importing profile: %v", err)
+		}
+	},
+}
+
+var networkQRCmd = &cobra.Command{
+	Use:   "qr <ssid>",
+	Short: "Show a QR code for joining a saved WiFi network",
+	Long:  "Print a QR code phones can scan to join a saved WiFi network, password included. NetworkManager asks the secret agent for passwords only kept in the keyring. --png also writes it as an image",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pngPath, _ := cmd.Flags().GetString("png")
+		yes, _ := cmd.Flags().GetBool("yes")
+		if err := runNetworkQR(args[0], pngPath, yes); err != nil {
+			log.Fatalf("Error creating QR code: %v", err)
+		}
+	},
+}
+
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage secrets stored by dms",
+	Long:  "Store API keys and tokens dms needs, encrypted in ~/.config/dms/secrets.json. The key is kept in the user's keyring (Secret Service), or in ~/.local/share/dms/secrets.key when there is no keyring",
+}
+
+var secretSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Store a secret, read from the terminal or stdin",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runSecretSet(args[0]); err != nil {
+			log.Fatalf("Error storing secret: %v", err)
+		}
+	},
+}
+
+var secretGetCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Print a stored secret",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runSecretGet(args[0]); err != nil {
+			log.Fatalf("Error reading secret: %v", err)
+		}
+	},
+}
+
+var secretRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Remove a stored secret",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runSecretRm(args[0]); err != nil {
+			log.Fatalf("Error removing secret: %v", err)
+		}
+	},
+}
+
+var themesCmd = &cobra.Command{
+	Use:   "themes",
+	Short: "Install and apply icon and cursor themes",
+	Long:  "Install supported icon and cursor themes from distro packages or upstream releases and apply them to GTK, Qt, Hyprland and niri in one step, rolling back if any part fails",
+}
+
+var themesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List supported and installed themes",
+	Run: func(cmd *cobra.Command, args []string) {
+		runThemesList()
+	},
+}
+
+var themesInstallCmd = &cobra.Command{
+	Use:   "install <theme|variant>",
+	Short: "Install a supported theme",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		apply, _ := cmd.Flags().GetBool("apply")
+		size, _ := cmd.Flags().GetInt("size")
+		if err := runThemesInstall(args[0], apply, size); err != nil {
+			log.Fatalf("Error installing theme: %v", err)
+		}
+	},
+}
+
+var themesApplyCmd = &cobra.Command{
+	Use:   "apply <name>",
+	Short: "Make an installed theme the current icon or cursor theme",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		size, _ := cmd.Flags().GetInt("size")
+		if err := runThemesApply(args[0], size); err != nil {
+			log.Fatalf("Error applying theme: %v", err)
+		}
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a configuration value",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runConfigUnset(args[0]); err != nil {
+			log.Fatalf("Error unsetting %s: %v", args[0], err)
+		}
+	},
+}
+
 var debugSrvCmd = &cobra.Command{
 	Use:   "debug-srv",
 	Short: "Start the debug server",
@@ -68,6 +586,30 @@ var debugSrvCmd = &cobra.Command{
 	},
 }
 
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Debugging tools",
+}
+
+var debugBenchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure the cost of hot paths",
+}
+
+var debugBenchGammaCmd = &cobra.Command{
+	Use:   "gamma",
+	Short: "Benchmark gamma ramp generation and apply latency",
+	Long:  "Time generating, packing and writing gamma ramps for each output size, then show the apply latency the running server measured, for troubleshooting night light stutter",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		sizes, _ := cmd.Flags().GetUintSlice("size")
+		iterations, _ := cmd.Flags().GetInt("iterations")
+		if err := runBenchGamma(sizes, iterations); err != nil {
+			log.Fatalf("Error benchmarking gamma: %v", err)
+		}
+	},
+}
+
 var pluginsCmd = &cobra.Command{
 	Use:   "plugins",
 	Short: "Manage DMS plugins",
@@ -123,6 +665,9 @@ var pluginsUninstallCmd = &cobra.Command{
 func runVersion(cmd *cobra.Command, args []string) {
 	printASCII()
 	fmt.Printf("%s\n", Version)
+	if shellRef, err := version.GetCurrentDMSVersion(); err == nil {
+		fmt.Printf("Shell: %s\n", shellRef)
+	}
 }
 
 func startDebugServer() error {
@@ -4,6 +4,7 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -15,6 +16,8 @@ import (
 	"github.com/AvengeMedia/danklinux/internal/distros"
 	"github.com/AvengeMedia/danklinux/internal/errdefs"
 	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
+	"github.com/AvengeMedia/danklinux/internal/server"
 	"github.com/AvengeMedia/danklinux/internal/version"
 	"github.com/spf13/cobra"
 )
@@ -24,7 +27,10 @@ var updateCmd = &cobra.Command{
 	Short: "Update DankMaterialShell to the latest version",
 	Long:  "Update DankMaterialShell to the latest version using the appropriate package manager for your distribution",
 	Run: func(cmd *cobra.Command, args []string) {
-		runUpdate()
+		force, _ := cmd.Flags().GetBool("force")
+		yes, _ := cmd.Flags().GetBool("yes")
+		ref, _ := cmd.Flags().GetString("ref")
+		runUpdate(force, yes, ref)
 	},
 }
 
@@ -54,6 +60,31 @@ var greeterInstallCmd = &cobra.Command{
 	},
 }
 
+var greeterNetworkCmd = &cobra.Command{
+	Use:   "network",
+	Short: "Run the greeter network server",
+	Long:  "Start a constrained server that only exposes the network module, for joining Wi-Fi from the greeter",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := server.StartGreeter(); err != nil {
+			log.Fatalf("Error starting greeter network server: %v", err)
+		}
+	},
+}
+
+var greeterSyncThemeCmd = &cobra.Command{
+	Use:   "sync-theme",
+	Short: "Match the greeter to your wallpaper and theme",
+	Long:  "Copy your wallpaper, generated palette and settings into the greeter cache. With --enable, the running dms server keeps the copies in sync whenever the theme changes; --disable goes back to the installer's symlinks",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		enable, _ := cmd.Flags().GetBool("enable")
+		disable, _ := cmd.Flags().GetBool("disable")
+		if err := runGreeterSyncTheme(enable, disable); err != nil {
+			log.Fatalf("Error syncing greeter theme: %v", err)
+		}
+	},
+}
+
 func runUpdateCheck() {
 	fmt.Println("Checking for DankMaterialShell updates...")
 	fmt.Println()
@@ -78,7 +109,28 @@ func runUpdateCheck() {
 	}
 }
 
-func runUpdate() {
+func runUpdate(force, yes bool, ref string) {
+	if !yes && onMeteredConnection() {
+		fmt.Println("You are on a metered connection; updating may download a lot of data.")
+		if !confirmUpdate() {
+			log.Info("Update cancelled.")
+			return
+		}
+	}
+
+	if ref != "" {
+		if err := updateShellRef(ref, force); err != nil {
+			if errors.Is(err, errdefs.ErrUpdateCancelled) {
+				log.Info("Update cancelled.")
+				return
+			}
+			log.Fatalf("Error switching DMS shell ref: %v", err)
+		}
+		log.Info("Update complete! Restarting DMS...")
+		restartShell()
+		return
+	}
+
 	osInfo, err := distros.GetOSInfo()
 	if err != nil {
 		log.Fatalf("Error detecting OS: %v", err)
@@ -92,13 +144,13 @@ func runUpdate() {
 	var updateErr error
 	switch config.Family {
 	case distros.FamilyArch:
-		updateErr = updateArchLinux()
+		updateErr = updateArchLinux(force)
 	case distros.FamilyNix:
-		updateErr = updateNixOS()
+		updateErr = updateNixOS(force)
 	case distros.FamilySUSE:
-		updateErr = updateOtherDistros()
+		updateErr = updateOtherDistros(force)
 	default:
-		updateErr = updateOtherDistros()
+		updateErr = updateOtherDistros(force)
 	}
 
 	if updateErr != nil {
@@ -116,12 +168,12 @@ func runUpdate() {
 	restartShell()
 }
 
-func updateArchLinux() error {
+func updateArchLinux(force bool) error {
 	homeDir, err := os.UserHomeDir()
 	if err == nil {
 		dmsPath := filepath.Join(homeDir, ".config", "quickshell", "dms")
 		if _, err := os.Stat(dmsPath); err == nil {
-			return updateOtherDistros()
+			return updateOtherDistros(force)
 		}
 	}
 
@@ -133,7 +185,7 @@ func updateArchLinux() error {
 	} else {
 		fmt.Println("Info: Neither dms-shell-bin nor dms-shell-git package found.")
 		fmt.Println("Info: Falling back to git-based update method...")
-		return updateOtherDistros()
+		return updateOtherDistros(force)
 	}
 
 	var helper string
@@ -148,7 +200,31 @@ func updateArchLinux() error {
 	} else {
 		fmt.Println("Error: Neither yay nor paru found - please install an AUR helper")
 		fmt.Println("Info: Falling back to git-based update method...")
-		return updateOtherDistros()
+		return updateOtherDistros(force)
+	}
+
+	// The package isn't a checkout dms can look into, so the requirements
+	// come from what it will build: master's tip for -git, the latest
+	// release for -bin
+	var target string
+	var req version.Requirements
+	var ok bool
+	if packageName == "dms-shell-git" {
+		target, req, ok, err = version.RequirementsForBranch("master")
+	} else {
+		target, req, ok, err = version.RequirementsForRelease()
+	}
+	if target == "" {
+		target = packageName
+	}
+	// GitHub being unreachable or rate limiting shouldn't hold back the
+	// package manager; only requirements that were read and aren't met do
+	if err != nil {
+		fmt.Printf("\nWarning: can't check compatibility of shell %s: %v\n", target, err)
+		ok = false
+	}
+	if err := checkUpdateCompatibility(target, req, ok, nil, server.APIVersion, force); err != nil {
+		return err
 	}
 
 	fmt.Printf("This will update DankMaterialShell using %s.\n", helper)
@@ -168,7 +244,7 @@ func updateArchLinux() error {
 	return nil
 }
 
-func updateNixOS() error {
+func updateNixOS(force bool) error {
 	fmt.Println("This will update DankMaterialShell using nix profile.")
 	if !confirmUpdate() {
 		return errdefs.ErrUpdateCancelled
@@ -182,14 +258,14 @@ func updateNixOS() error {
 	if err != nil {
 		fmt.Printf("Error: Failed to update using nix profile: %v\n", err)
 		fmt.Println("Falling back to git-based update method...")
-		return updateOtherDistros()
+		return updateOtherDistros(force)
 	}
 
 	fmt.Println("dms successfully updated")
 	return nil
 }
 
-func updateOtherDistros() error {
+func updateOtherDistros(force bool) error {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return fmt.Errorf("failed to get user home directory: %w", err)
@@ -220,12 +296,17 @@ func updateOtherDistros() error {
 		return errdefs.ErrUpdateCancelled
 	}
 
+	// A freshly installed binary is the latest release, so only the running
+	// binary's API version is checked when that update fails
+	apiVersion := server.APIVersion
+
 	fmt.Println("\n=== Updating dms binary ===")
 	if err := updateDMSBinary(); err != nil {
 		fmt.Printf("Warning: Failed to update dms binary: %v\n", err)
 		fmt.Println("Continuing with shell configuration update...")
 	} else {
 		fmt.Println("dms binary successfully updated")
+		apiVersion = 0
 	}
 
 	fmt.Println("\n=== Updating DMS shell configuration ===")
@@ -286,6 +367,11 @@ func updateOtherDistros() error {
 		fmt.Printf("Current tag: %s\n", currentTag)
 		fmt.Printf("Latest tag: %s\n", latestTag)
 
+		req, ok, err := version.RequirementsAt(dmsPath, latestTag)
+		if err := checkUpdateCompatibility(latestTag, req, ok, err, apiVersion, force); err != nil {
+			return err
+		}
+
 		if hasLocalChanges {
 			fmt.Println("\nWarning: You have local changes in your DMS configuration.")
 			if offerReclone(dmsPath) {
@@ -316,6 +402,11 @@ func updateOtherDistros() error {
 
 	fmt.Printf("Current branch: %s\n", currentBranch)
 
+	req, ok, err := version.RequirementsAt(dmsPath, "origin/"+currentBranch)
+	if err := checkUpdateCompatibility(currentBranch+"@origin", req, ok, err, apiVersion, force); err != nil {
+		return err
+	}
+
 	if hasLocalChanges {
 		fmt.Println("\nWarning: You have local changes in your DMS configuration.")
 		if offerReclone(dmsPath) {
@@ -339,6 +430,98 @@ func updateOtherDistros() error {
 	return nil
 }
 
+// updateShellRef moves a git-based shell config to a tag, branch or pull
+// request, e.g. to test a shell pre-release
+func updateShellRef(ref string, force bool) error {
+	if _, err := version.ParseShellRef(ref); err != nil {
+		return err
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	dmsPath := filepath.Join(homeDir, ".config", "quickshell", "dms")
+	if _, err := os.Stat(filepath.Join(dmsPath, ".git")); err != nil {
+		return fmt.Errorf("no git checkout at %s; reinstall with 'dankinstall --shell-ref %s' to follow a ref", dmsPath, ref)
+	}
+
+	if current, err := version.GetCurrentDMSVersion(); err == nil {
+		fmt.Printf("Current ref: %s\n", current)
+	}
+	fmt.Printf("Target ref:  %s\n", ref)
+
+	// Fetch before checking so branches and pull requests that aren't local
+	// yet resolve to what will actually be checked out
+	parsed, err := version.FetchShellRef(dmsPath, ref)
+	if err != nil {
+		return err
+	}
+	rev := parsed.Revision(dmsPath)
+	req, ok, err := version.RequirementsAt(dmsPath, rev)
+	if err := checkUpdateCompatibility(rev, req, ok, err, server.APIVersion, force); err != nil {
+		return err
+	}
+
+	statusOutput, _ := exec.Command("git", "-C", dmsPath, "status", "--porcelain").Output()
+	if len(strings.TrimSpace(string(statusOutput))) > 0 {
+		fmt.Println("\nWarning: You have local changes in your DMS configuration.")
+		if !offerReclone(dmsPath) {
+			return errdefs.ErrUpdateCancelled
+		}
+	}
+
+	if err := version.SwitchShellRef(dmsPath, parsed); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nSwitched DMS shell to %s\n", parsed.Checkout)
+	return nil
+}
+
+// checkUpdateCompatibility refuses a shell update that the installed dms
+// binary or quickshell can't run, unless forced. req, ok and lookupErr are the
+// result of reading the compat file the target shell ships: a shell without
+// one isn't checked, but one whose requirements can't be read is refused too
+func checkUpdateCompatibility(targetShell string, req version.Requirements, ok bool, lookupErr error, apiVersion int, force bool) error {
+	if lookupErr != nil {
+		fmt.Printf("\nWarning: can't check compatibility of shell %s: %v\n", targetShell, lookupErr)
+		if force {
+			fmt.Println("Continuing anyway because --force was given.")
+			return nil
+		}
+		fmt.Println("Re-run with --force to update without the check.")
+		return fmt.Errorf("unknown requirements for shell %s", targetShell)
+	}
+	if !ok {
+		return nil
+	}
+
+	quickshellVersion, _ := version.GetQuickshellVersion()
+	problems := version.CheckCompatibility(req, version.Components{
+		Shell:      targetShell,
+		APIVersion: apiVersion,
+		Quickshell: quickshellVersion,
+	})
+	if len(problems) == 0 {
+		return nil
+	}
+
+	fmt.Println("\nWarning: the update would leave known-incompatible components:")
+	for _, problem := range problems {
+		fmt.Printf("  - %s\n", problem)
+	}
+
+	if force {
+		fmt.Println("Continuing anyway because --force was given.")
+		return nil
+	}
+
+	fmt.Println("Update the listed components first, or re-run with --force.")
+	return fmt.Errorf("incompatible component versions for shell %s", targetShell)
+}
+
 func offerReclone(dmsPath string) bool {
 	fmt.Println("\nWould you like to backup and re-clone the repository? (y/N): ")
 	reader := bufio.NewReader(os.Stdin)
@@ -382,6 +565,23 @@ func isPackageInstalled(packageName string) bool {
 	return err == nil
 }
 
+// onMeteredConnection asks a running dms server whether the primary link is
+// metered; without a server it assumes it isn't
+func onMeteredConnection() bool {
+	result, err := server.Call("network.getState", nil)
+	if err != nil {
+		return false
+	}
+
+	var state struct {
+		Metered bool `json:"metered"`
+	}
+	if err := json.Unmarshal(result, &state); err != nil {
+		return false
+	}
+	return state.Metered
+}
+
 func confirmUpdate() bool {
 	fmt.Print("Do you want to proceed with the update? (y/N): ")
 	reader := bufio.NewReader(os.Stdin)
@@ -503,7 +703,7 @@ func updateDMSBinary() error {
 
 	fmt.Printf("Installing to %s...\n", currentPath)
 
-	replaceCmd := exec.Command("sudo", "install", "-m", "0755", decompressedPath, currentPath)
+	replaceCmd := exec.Command(string(privesc.CurrentTool()), "install", "-m", "0755", decompressedPath, currentPath)
 	replaceCmd.Stdin = os.Stdin
 	replaceCmd.Stdout = os.Stdout
 	replaceCmd.Stderr = os.Stderr
@@ -513,3 +713,17 @@ func updateDMSBinary() error {
 
 	return nil
 }
+
+func applyEscalation(cmd *cobra.Command, args []string) error {
+	name, err := cmd.Flags().GetString("escalation")
+	if err != nil {
+		return nil
+	}
+
+	tool, err := privesc.ParseTool(name)
+	if err != nil {
+		return err
+	}
+	privesc.SetTool(tool)
+	return nil
+}
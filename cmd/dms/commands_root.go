@@ -7,6 +7,7 @@ import (
 	"github.com/AvengeMedia/danklinux/internal/distros"
 	"github.com/AvengeMedia/danklinux/internal/dms"
 	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 )
@@ -35,6 +36,12 @@ func runInteractiveMode(cmd *cobra.Command, args []string) {
 	}
 
 	model := dms.NewModel(Version)
+	broker, err := privesc.StartBroker(model.PasswordPrompter())
+	if err != nil {
+		log.Fatalf("Error starting password broker: %v", err)
+	}
+	defer broker.Close()
+
 	p := tea.NewProgram(model, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		log.Fatalf("Error running program: %v", err)
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/config"
+	"github.com/AvengeMedia/danklinux/internal/kdl"
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server/apps"
+)
+
+func isNiriKey(key string) bool {
+	return strings.HasPrefix(key, "niri.")
+}
+
+func runConfigGet(key string) error {
+	if isNiriKey(key) {
+		doc, err := config.LoadNiriConfig(config.NiriConfigPath())
+		if err != nil {
+			return err
+		}
+		value, err := config.GetNiriOption(doc, key)
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	}
+
+	settings, err := config.LoadShellSettings(config.ShellSettingsPath())
+	if err != nil {
+		return err
+	}
+	value, err := settings.Get(key)
+	if err != nil {
+		return err
+	}
+	fmt.Println(config.FormatSetting(value))
+	return nil
+}
+
+func runConfigSet(key string, values []string) error {
+	if isNiriKey(key) {
+		return config.EditNiriConfig(config.NiriConfigPath(), func(doc *kdl.Document) error {
+			return config.SetNiriOption(doc, key, values)
+		})
+	}
+
+	path := config.ShellSettingsPath()
+	settings, err := config.LoadShellSettings(path)
+	if err != nil {
+		return err
+	}
+	if err := settings.Set(key, strings.Join(values, " ")); err != nil {
+		return err
+	}
+	if err := settings.Save(path); err != nil {
+		return err
+	}
+
+	notifyShellSettingsChanged()
+	return nil
+}
+
+// notifyShellSettingsChanged asks a running shell to re-read its settings;
+// the shell also watches the file, so failing here is not an error
+func notifyShellSettingsChanged() {
+	if _, err := shellIPCOutput("settings", "reload"); err != nil {
+		log.Debugf("Shell settings reload via IPC failed: %v", err)
+	}
+}
+
+func runConfigUnset(key string) error {
+	if !isNiriKey(key) {
+		return fmt.Errorf("shell settings can't be removed, set them to a new value instead")
+	}
+
+	return config.EditNiriConfig(config.NiriConfigPath(), func(doc *kdl.Document) error {
+		return config.UnsetNiriOption(doc, key)
+	})
+}
+
+func runConfigList(prefix string) error {
+	settings, err := config.LoadShellSettings(config.ShellSettingsPath())
+	if err != nil {
+		return err
+	}
+
+	for _, key := range settings.List(prefix) {
+		value, _ := settings.Get(key)
+		fmt.Printf("%s = %s\n", key, config.FormatSetting(value))
+	}
+	return nil
+}
+
+func runConfigDefaults(args []string) error {
+	switch len(args) {
+	case 0:
+		for _, d := range apps.LoadDefaults() {
+			printDefault(d)
+		}
+		return nil
+	case 1:
+		for _, d := range apps.LoadDefaults() {
+			if d.Category == args[0] {
+				printDefault(d)
+			}
+		}
+		candidates, err := apps.DefaultCandidates(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println("\nAvailable:")
+		for _, app := range candidates {
+			fmt.Printf("  %-40s %s\n", app.ID, app.Name)
+		}
+		return nil
+	default:
+		if err := apps.SetDefault(args[0], args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Default %s set to %s\n", args[0], args[1])
+		return nil
+	}
+}
+
+func printDefault(d apps.Default) {
+	switch {
+	case d.ID == "":
+		fmt.Printf("%-13s (not set)\n", d.Category)
+	case d.Name == "":
+		fmt.Printf("%-13s %s (not installed)\n", d.Category, d.ID)
+	default:
+		fmt.Printf("%-13s %s (%s)\n", d.Category, d.Name, d.ID)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/dank16"
+)
+
+func runDank16(primary string, light, sync, jsonOutput bool) error {
+	palette, err := dank16.Generate(primary, light)
+	if err != nil {
+		return err
+	}
+
+	if sync {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return err
+		}
+		written, err := dank16.WriteConfigs(configDir, palette)
+		if err != nil {
+			return err
+		}
+		dank16.LiveApply(palette, written)
+
+		names := make([]string, 0, len(written))
+		for _, term := range written {
+			names = append(names, term.Name)
+		}
+		if len(names) == 0 {
+			fmt.Fprintln(os.Stderr, "No supported terminal config directories found")
+		} else {
+			fmt.Fprintf(os.Stderr, "Synced terminal colors: %s\n", strings.Join(names, ", "))
+		}
+	}
+
+	if jsonOutput {
+		return json.NewEncoder(os.Stdout).Encode(palette)
+	}
+	if !sync {
+		for i, color := range palette.Colors {
+			fmt.Printf("color%d %s\n", i, color)
+		}
+		fmt.Printf("background %s\nforeground %s\n", palette.Background, palette.Foreground)
+	}
+	return nil
+}
@@ -16,7 +16,7 @@ func installGreeter() error {
 	}
 
 	// Step 1: Ensure greetd is installed
-	if err := greeter.EnsureGreetdInstalled(logFunc, ""); err != nil {
+	if err := greeter.EnsureGreetdInstalled(logFunc); err != nil {
 		return err
 	}
 
@@ -50,25 +50,25 @@ func installGreeter() error {
 
 	// Step 4: Setup dms-greeter group and permissions
 	fmt.Println("\nSetting up dms-greeter group and permissions...")
-	if err := greeter.SetupDMSGroup(logFunc, ""); err != nil {
+	if err := greeter.SetupDMSGroup(logFunc); err != nil {
 		return err
 	}
 
 	// Step 5: Copy greeter files
 	fmt.Println("\nCopying greeter files...")
-	if err := greeter.CopyGreeterFiles(dmsPath, selectedCompositor, logFunc, ""); err != nil {
+	if err := greeter.CopyGreeterFiles(dmsPath, selectedCompositor, logFunc); err != nil {
 		return err
 	}
 
 	// Step 6: Configure greetd
 	fmt.Println("\nConfiguring greetd...")
-	if err := greeter.ConfigureGreetd(dmsPath, selectedCompositor, logFunc, ""); err != nil {
+	if err := greeter.ConfigureGreetd(dmsPath, selectedCompositor, logFunc); err != nil {
 		return err
 	}
 
 	// Step 7: Sync DMS configs
 	fmt.Println("\nSynchronizing DMS configurations...")
-	if err := greeter.SyncDMSConfigs(dmsPath, logFunc, ""); err != nil {
+	if err := greeter.SyncDMSConfigs(dmsPath, logFunc); err != nil {
 		return err
 	}
 
@@ -80,3 +80,34 @@ func installGreeter() error {
 
 	return nil
 }
+
+func runGreeterSyncTheme(enable, disable bool) error {
+	logFunc := func(msg string) {
+		fmt.Println(msg)
+	}
+	paths := greeter.DefaultThemeSyncPaths()
+
+	switch {
+	case enable && disable:
+		return fmt.Errorf("--enable and --disable can't be combined")
+	case enable:
+		if err := greeter.EnableThemeSync(paths, logFunc); err != nil {
+			return err
+		}
+		fmt.Println("The greeter now follows your theme while dms is running.")
+		return nil
+	case disable:
+		return greeter.DisableThemeSync(paths, logFunc)
+	}
+
+	changed, err := paths.Sync()
+	if err != nil {
+		return err
+	}
+	if changed {
+		fmt.Println("✓ Synced wallpaper and theme to the greeter")
+	} else {
+		fmt.Println("✓ Greeter theme is already up to date")
+	}
+	return nil
+}
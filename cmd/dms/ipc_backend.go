@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/server"
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
+)
+
+// runBackendIPCCommand serves the IPC targets implemented by the dms server
+// rather than the shell. It reports whether args were handled
+func runBackendIPCCommand(args []string) (bool, error) {
+	if len(args) == 1 && args[0] == "health" {
+		return true, runHealth()
+	}
+	if len(args) < 2 {
+		return false, nil
+	}
+
+	switch {
+	case args[0] == "idle" && args[1] == "caffeinate":
+		return true, runCaffeinate(args[2:])
+	case args[0] == "idle" && (args[1] == "inhibit" || args[1] == "uninhibit"):
+		return true, runIdleInhibit(args[1:])
+	case args[0] == "kb":
+		return true, runKeyboardLayout(args[1:])
+	case args[0] == "zoom":
+		return true, runZoom(args[1:])
+	case args[0] == "mic":
+		return true, runMic(args[1:])
+	case args[0] == "unit":
+		return true, runUnit(args[1:])
+	case args[0] == "breaks":
+		return true, runBreaks(args[1:])
+	case args[0] == "network" && (args[1] == "export-bundle" || args[1] == "import-bundle"):
+		return true, runNetworkBundle(args[1:])
+	case args[0] == "clock" && args[1] == "sync":
+		return true, callAndPrint("timezones.syncNow", nil)
+	case args[0] == "clock" && args[1] == "set-timezone":
+		if len(args) < 3 {
+			return true, fmt.Errorf("usage: dms ipc clock set-timezone <timezone>")
+		}
+		return true, callAndPrint("timezones.setTimezone", map[string]interface{}{"timezone": args[2]})
+	}
+	return false, nil
+}
+
+func runCaffeinate(args []string) error {
+	params := map[string]interface{}{}
+	if len(args) > 0 {
+		duration, err := parseCaffeineDuration(args[0])
+		if err != nil {
+			return err
+		}
+		params["duration"] = duration.Seconds()
+	}
+
+	return callAndPrint("idle.caffeinate", params)
+}
+
+func runIdleInhibit(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: dms ipc idle %s <app> [reason]", args[0])
+	}
+	params := map[string]interface{}{"app": args[1]}
+	if args[0] == "inhibit" && len(args) > 2 {
+		params["reason"] = strings.Join(args[2:], " ")
+	}
+	return callAndPrint("idle."+args[0], params)
+}
+
+func runKeyboardLayout(args []string) error {
+	switch args[0] {
+	case "next", "prev":
+		return callAndPrint("keyboard."+args[0], nil)
+	case "set":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: dms ipc kb set <layout>")
+		}
+		return callAndPrint("keyboard.set", map[string]interface{}{"layout": args[1]})
+	}
+	return fmt.Errorf("unknown kb command %q (use next, prev or set <layout>)", args[0])
+}
+
+func runZoom(args []string) error {
+	switch args[0] {
+	case "in":
+		return callAndPrint("magnifier.zoomIn", nil)
+	case "out":
+		return callAndPrint("magnifier.zoomOut", nil)
+	case "toggle":
+		return callAndPrint("magnifier.toggle", nil)
+	case "reset":
+		return callAndPrint("magnifier.set", map[string]interface{}{"zoom": 1.0})
+	case "set":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: dms ipc zoom set <factor>")
+		}
+		factor, err := strconv.ParseFloat(args[1], 64)
+		if err != nil {
+			return fmt.Errorf("invalid zoom factor %q", args[1])
+		}
+		return callAndPrint("magnifier.set", map[string]interface{}{"zoom": factor})
+	}
+	return fmt.Errorf("unknown zoom command %q (use in, out, toggle, reset or set <factor>)", args[0])
+}
+
+func runMic(args []string) error {
+	switch args[0] {
+	case "toggle":
+		return callAndPrint("privacy.toggleMicMute", nil)
+	case "mute", "unmute":
+		return callAndPrint("privacy.setMicMute", map[string]interface{}{"muted": args[0] == "mute"})
+	}
+	return fmt.Errorf("unknown mic command %q (use toggle, mute or unmute)", args[0])
+}
+
+func runUnit(args []string) error {
+	switch args[0] {
+	case "restart", "watch", "unwatch":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: dms ipc unit %s <unit> [user|system]", args[0])
+		}
+		params := map[string]interface{}{"unit": args[1]}
+		if len(args) > 2 {
+			params["scope"] = args[2]
+		}
+		return callAndPrint("systemd."+args[0], params)
+	}
+	return fmt.Errorf("unknown unit command %q (use restart, watch or unwatch)", args[0])
+}
+
+func runBreaks(args []string) error {
+	switch args[0] {
+	case "on", "off":
+		return callAndPrint("breaks.setEnabled", map[string]interface{}{"enabled": args[0] == "on"})
+	case "snooze":
+		params := map[string]interface{}{}
+		if len(args) > 1 {
+			minutes, err := strconv.ParseFloat(args[1], 64)
+			if err != nil || minutes <= 0 {
+				return fmt.Errorf("invalid snooze minutes %q", args[1])
+			}
+			params["minutes"] = minutes
+		}
+		return callAndPrint("breaks.snooze", params)
+	case "skip":
+		return callAndPrint("breaks.skip", nil)
+	case "now":
+		return callAndPrint("breaks.now", nil)
+	}
+	return fmt.Errorf("unknown breaks command %q (use on, off, snooze [minutes], skip or now)", args[0])
+}
+
+func runHealth() error {
+	result, err := server.Call("getHealth", nil)
+	if err != nil {
+		return err
+	}
+
+	var report budget.Report
+	if err := json.Unmarshal(result, &report); err != nil {
+		return err
+	}
+
+	if report.Throttled {
+		fmt.Printf("Throttled:  yes (%s)\n", strings.Join(report.Reasons, ", "))
+	} else {
+		fmt.Println("Throttled:  no")
+	}
+	fmt.Printf("Goroutines: %d (limit %d)\n", report.Goroutines, report.Limits.Goroutines)
+	fmt.Printf("Memory:     %.1f MB heap, %.1f MB from the OS (limit %.0f MB heap)\n", report.HeapMB, report.SysMB, report.Limits.MemoryMB)
+	if len(report.Modules) == 0 {
+		return nil
+	}
+
+	fmt.Println("D-Bus messages/s:")
+	for _, usage := range report.Modules {
+		marker := ""
+		if usage.OverLimit {
+			marker = "  over limit"
+		}
+		fmt.Printf("  %-12s %6.1f calls %6.1f signals (limit %.0f)%s\n", usage.Module, usage.CallsPerSec, usage.SignalsPerSec, usage.Limit, marker)
+	}
+	return nil
+}
+
+func callAndPrint(method string, params map[string]interface{}) error {
+	result, err := server.Call(method, params)
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(result, &response); err != nil {
+		return err
+	}
+	fmt.Println(response.Message)
+	return nil
+}
+
+// parseCaffeineDuration accepts Go durations (90m, 1h30m) or bare minutes
+func parseCaffeineDuration(value string) (time.Duration, error) {
+	if minutes, err := strconv.Atoi(value); err == nil && minutes >= 0 {
+		return time.Duration(minutes) * time.Minute, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid duration %q (use e.g. 30m, 1h or minutes)", value)
+	}
+	return duration, nil
+}
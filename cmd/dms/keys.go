@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/AvengeMedia/danklinux/internal/config"
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/testsession"
+)
+
+// runKeysCheatsheet regenerates the cheat sheet from the compositor config
+// in use, so binds added since the install show up, and prints it. When the
+// config can't be read the one written at install time is shown
+func runKeysCheatsheet(compositor string, open bool) error {
+	if compositor == "" {
+		detected, err := testsession.Detect()
+		if err != nil {
+			return err
+		}
+		compositor = detected
+	}
+
+	source := config.NiriConfigPath()
+	if compositor == testsession.Hyprland {
+		source = config.HyprlandConfigPath()
+	}
+
+	content, err := os.ReadFile(source)
+	if err == nil {
+		err = config.WriteCheatsheet(compositor, string(content), source)
+	}
+	if err != nil {
+		if _, statErr := os.Stat(config.CheatsheetPath()); statErr != nil {
+			return err
+		}
+		log.Warnf("Showing the saved cheat sheet: %v", err)
+	}
+
+	markdown, err := os.ReadFile(config.CheatsheetPath())
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(markdown))
+
+	image := config.CheatsheetImagePath()
+	if !open {
+		fmt.Fprintf(os.Stderr, "\nImage: %s (--open to view it)\n", image)
+		return nil
+	}
+	if err := exec.Command("xdg-open", image).Start(); err != nil {
+		return fmt.Errorf("failed to open %s: %w", image, err)
+	}
+	return nil
+}
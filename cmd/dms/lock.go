@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+func shellIPCOutput(args ...string) (string, error) {
+	configPath, err := locateDMSConfig()
+	if err != nil {
+		return "", err
+	}
+
+	cmdArgs := append([]string{"-p", configPath, "ipc", "call"}, args...)
+	output, err := exec.Command("qs", cmdArgs...).Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+func loginctlLockSession() error {
+	args := []string{"lock-session"}
+	if sessionID := os.Getenv("XDG_SESSION_ID"); sessionID != "" {
+		args = append(args, sessionID)
+	}
+	if output, err := exec.Command("loginctl", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("loginctl lock-session failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// lockSession shows the shell's lock screen, falling back to logind when the
+// shell isn't running. With now, logind is also told to lock so the session
+// is marked locked before returning, which is what sleep hooks need
+func lockSession(now bool) error {
+	_, ipcErr := shellIPCOutput("lock", "lock")
+	if ipcErr == nil && !now {
+		return nil
+	}
+
+	if err := loginctlLockSession(); err != nil {
+		if ipcErr == nil {
+			log.Warnf("Shell locked, but %v", err)
+			return nil
+		}
+		return fmt.Errorf("shell IPC unavailable (%v) and %w", ipcErr, err)
+	}
+	return nil
+}
+
+// sessionStillLocked assumes the session stays locked when the shell can't
+// be asked, so a suspend is never skipped just because IPC is down
+func sessionStillLocked() bool {
+	output, err := shellIPCOutput("lock", "isLocked")
+	if err != nil {
+		return true
+	}
+	return output != "false"
+}
+
+func runLock(now bool, suspendAfter time.Duration) error {
+	if err := lockSession(now); err != nil {
+		return err
+	}
+
+	if suspendAfter <= 0 {
+		return nil
+	}
+
+	log.Infof("Suspending in %s unless unlocked", suspendAfter)
+	time.Sleep(suspendAfter)
+
+	if !sessionStillLocked() {
+		log.Info("Session unlocked, not suspending")
+		return nil
+	}
+
+	if output, err := exec.Command("systemctl", "suspend").CombinedOutput(); err != nil {
+		return fmt.Errorf("systemctl suspend failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
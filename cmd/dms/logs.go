@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/AvengeMedia/danklinux/internal/crashes"
+	"github.com/AvengeMedia/danklinux/internal/report"
+)
+
+func runLogs(lines int) error {
+	source, logs := report.ReadLogs(lines)
+	if source == "" {
+		return fmt.Errorf("no shell logs found (is quickshell or journalctl installed?)")
+	}
+	fmt.Println(logs)
+	return nil
+}
+
+func runLogsCrashes(asJSON bool) error {
+	list, err := crashes.List(crashes.Dir())
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		if list == nil {
+			list = []crashes.Crash{}
+		}
+		data, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(list) == 0 {
+		fmt.Println("No crashes recorded.")
+		return nil
+	}
+
+	for _, crash := range list {
+		component := crash.Component
+		if crash.Version != "" {
+			component += " " + crash.Version
+		}
+		fmt.Printf("%s  %-22s %s\n", crash.Time.Format("2006-01-02 15:04:05"), component, crash.Reason)
+		if crash.Details != "" {
+			fmt.Printf("    details: %s\n", crash.Details)
+		}
+		if crash.CoreDump != "" {
+			fmt.Printf("    core:    %s\n", crash.CoreDump)
+		}
+	}
+	return nil
+}
@@ -4,8 +4,13 @@ package main
 
 import (
 	"os"
+	"time"
 
+	"github.com/AvengeMedia/danklinux/internal/distros"
 	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
+	"github.com/AvengeMedia/danklinux/internal/report"
+	"github.com/AvengeMedia/danklinux/internal/server"
 )
 
 var Version = "dev"
@@ -16,20 +21,92 @@ func init() {
 	runCmd.Flags().Bool("daemon-child", false, "Internal flag for daemon child process")
 	runCmd.Flags().MarkHidden("daemon-child")
 
+	lockCmd.Flags().Bool("now", false, "Also lock through logind so the session is locked before returning (for sleep hooks)")
+	lockCmd.Flags().Duration("suspend-after", 0, "Suspend after this long (e.g. 30s) unless unlocked first")
+
+	dank16Cmd.Flags().Bool("light", false, "Generate a palette for a light theme")
+	dank16Cmd.Flags().Bool("sync", false, "Write terminal color files and live-apply them to running terminals")
+	dank16Cmd.Flags().Bool("json", false, "Print the palette as JSON")
+
+	wallpaperSetCmd.Flags().StringP("output", "o", "", "Output to set (default: all outputs)")
+	wallpaperSetCmd.Flags().String("fill", "cover", "Fill mode: cover, fit or center")
+	wallpaperSlideshowCmd.Flags().Duration("interval", 10*time.Minute, "Time between wallpapers")
+	wallpaperSlideshowCmd.Flags().Bool("shuffle", false, "Pick images in random order")
+	wallpaperSlideshowCmd.Flags().StringSliceP("output", "o", nil, "Outputs to cycle (default: all outputs)")
+	wallpaperTransitionCmd.Flags().Float64("duration", -1, "Transition duration in seconds")
+	wallpaperCmd.AddCommand(wallpaperSetCmd, wallpaperSlideshowCmd, wallpaperNextCmd, wallpaperStopCmd, wallpaperTransitionCmd)
+	configCmd.AddCommand(configGetCmd, configSetCmd, configListCmd, configUnsetCmd, configDefaultsCmd)
+	syncStatusCmd.Flags().Bool("json", false, "Print the status as JSON")
+	syncPushCmd.Flags().Bool("force", false, "Overwrite items that also changed remotely")
+	syncPullCmd.Flags().Bool("force", false, "Overwrite items that also changed locally")
+	syncCmd.AddCommand(syncInitCmd, syncStatusCmd, syncPushCmd, syncPullCmd)
+	profileApplyCmd.Flags().Bool("auto", false, "Apply the profile whose conditions match the connected outputs")
+	profileCmd.AddCommand(profileListCmd, profileApplyCmd)
+
+	autostartListCmd.Flags().Bool("json", false, "Print the entries as JSON")
+	autostartAddCmd.Flags().String("name", "", "Entry name (default: the command's program name)")
+	autostartAddCmd.Flags().Bool("xdg", false, "Write an XDG autostart desktop entry instead of compositor exec lines")
+	autostartCmd.AddCommand(autostartListCmd, autostartAddCmd, autostartRemoveCmd)
+
+	themesInstallCmd.Flags().Bool("apply", false, "Apply the theme after installing it")
+	themesInstallCmd.Flags().Int("size", 0, "Cursor size (default: keep the current size)")
+	themesApplyCmd.Flags().Int("size", 0, "Cursor size (default: keep the current size)")
+	themesCmd.AddCommand(themesListCmd, themesInstallCmd, themesApplyCmd)
+
+	reportIssueCmd.Flags().StringP("output", "o", "", "Save the report to a file instead of printing it")
+	reportIssueCmd.Flags().String("title", "", "Issue title for the prefilled link")
+	reportIssueCmd.Flags().String("repo", report.DefaultRepo, "GitHub repository to file the issue in")
+	reportIssueCmd.Flags().Int("lines", 150, "Number of recent log lines to include (0 to leave logs out)")
+	reportIssueCmd.Flags().Bool("open", false, "Open a prefilled GitHub issue page in the browser")
+
+	logsCmd.Flags().Bool("crashes", false, "List recorded crashes instead of the log")
+	logsCmd.Flags().Bool("json", false, "Output crashes as JSON")
+	logsCmd.Flags().IntP("lines", "n", 200, "Number of log lines to show")
+
+	testSessionCmd.Flags().String("compositor", "", "Compositor to nest: niri or hyprland (default: the current one)")
+	testSessionCmd.Flags().String("config", "", "Compositor config to start from (default: your own)")
+
+	setupPrivilegesCmd.Flags().Bool("print", false, "Print the rules instead of installing them")
+	setupCmd.AddCommand(setupPrivilegesCmd)
+
+	secretCmd.AddCommand(secretSetCmd, secretGetCmd, secretRmCmd)
+
+	keysCheatsheetCmd.Flags().String("compositor", "", "Compositor whose config to read: niri or hyprland (default: the current one)")
+	keysCheatsheetCmd.Flags().Bool("open", false, "Open the cheat sheet image")
+	keysCmd.AddCommand(keysCheatsheetCmd)
+
+	networkExportCmd.Flags().Bool("secrets", false, "Include passwords and keys (the file is then only readable by you)")
+	networkImportCmd.Flags().String("on-conflict", "", "What to do when the profile already exists: replace, rename or skip")
+	networkQRCmd.Flags().String("png", "", "Also save the QR code as a PNG image")
+	networkQRCmd.Flags().Bool("yes", false, "Don't ask before showing the password")
+	networkCmd.AddCommand(networkExportCmd, networkImportCmd, networkQRCmd)
+
+	debugBenchGammaCmd.Flags().UintSlice("size", nil, "Ramp sizes to measure (default 256,1024,4096)")
+	debugBenchGammaCmd.Flags().Int("iterations", 200, "Runs to average per size")
+	debugBenchCmd.AddCommand(debugBenchGammaCmd)
+	debugCmd.AddCommand(debugBenchCmd)
+
 	// Add subcommands to plugins
 	pluginsCmd.AddCommand(pluginsBrowseCmd, pluginsListCmd, pluginsInstallCmd, pluginsUninstallCmd)
 
 	// Add commands to root (excluding updateCmd and greeterCmd)
-	rootCmd.AddCommand(versionCmd, runCmd, restartCmd, killCmd, ipcCmd, debugSrvCmd, pluginsCmd)
+	rootCmd.AddCommand(versionCmd, runCmd, restartCmd, killCmd, lockCmd, dank16Cmd, wallpaperCmd, configCmd, syncCmd, profileCmd, autostartCmd, themesCmd, logsCmd, reportIssueCmd, testSessionCmd, setupCmd, privilegedCmd, secretCmd, keysCmd, networkCmd, ipcCmd, debugSrvCmd, debugCmd, pluginsCmd)
 	rootCmd.SetHelpTemplate(getHelpTemplate())
 }
 
 func main() {
-	// Block root
-	if os.Geteuid() == 0 {
+	privesc.HandleAskpass()
+
+	// Block root, except for the helper pkexec starts
+	if os.Geteuid() == 0 && !isPrivilegedHelper() {
 		log.Fatal("This program should not be run as root. Exiting.")
 	}
 
+	if err := distros.LoadDistroPlugins(distros.PluginDir); err != nil {
+		log.Warnf("Skipped distro definitions: %v", err)
+	}
+
+	server.BinaryVersion = Version
 	if err := rootCmd.Execute(); err != nil {
 		log.Fatal(err)
 	}
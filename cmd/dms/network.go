@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/server"
+	"github.com/AvengeMedia/danklinux/internal/server/network"
+	"github.com/charmbracelet/x/term"
+	"github.com/skip2/go-qrcode"
+)
+
+func runNetworkExport(name, path string, withSecrets bool) error {
+	profile, err := network.ExportProfile(name, withSecrets)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0644)
+	if withSecrets {
+		mode = 0600
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	// An existing file keeps its mode unless changed before writing
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %s (%s) to %s\n", profile.ID, profile.Type, path)
+	return nil
+}
+
+func runNetworkImport(path, onConflict string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var profile network.ProfileFile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	result, err := network.ImportProfile(&profile, onConflict)
+	if err != nil {
+		return err
+	}
+
+	switch result.Action {
+	case "skipped":
+		fmt.Printf("Skipped %s, a profile with that name already exists\n", result.ID)
+	case "renamed":
+		fmt.Printf("Imported %s as %s\n", profile.ID, result.ID)
+	default:
+		fmt.Printf("Imported %s (%s)\n", result.ID, result.Action)
+	}
+	return nil
+}
+
+func runNetworkQR(ssid, pngPath string, yes bool) error {
+	if !yes {
+		if !term.IsTerminal(os.Stdin.Fd()) {
+			return fmt.Errorf("this shows the password of %s, pass --yes to confirm", ssid)
+		}
+		fmt.Fprintf(os.Stderr, "Show the password of %s as a QR code? [y/N] ", ssid)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			return fmt.Errorf("canceled")
+		}
+	}
+
+	qr, err := network.WiFiQRCode(ssid)
+	if err != nil {
+		return err
+	}
+
+	code, err := qrcode.New(qr.Payload, qrcode.Medium)
+	if err != nil {
+		return err
+	}
+	fmt.Print(code.ToSmallString(false))
+	fmt.Printf("Scan to join %s\n", qr.SSID)
+
+	if pngPath != "" {
+		// The code holds the password
+		if err := os.WriteFile(pngPath, qr.PNG, 0600); err != nil {
+			return err
+		}
+		fmt.Printf("Saved to %s\n", pngPath)
+	}
+	return nil
+}
+
+// runNetworkBundle serves dms ipc network export-bundle <file> [secrets] and
+// import-bundle <file> [replace|rename|skip]. The server does the work, so
+// the path is made absolute first
+func runNetworkBundle(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: dms ipc network export-bundle <file> [secrets] | import-bundle <file> [replace|rename|skip]")
+	}
+	path, err := filepath.Abs(args[1])
+	if err != nil {
+		return err
+	}
+
+	if args[0] == "export-bundle" {
+		params := map[string]interface{}{"path": path}
+		if len(args) > 2 {
+			if args[2] != "secrets" {
+				return fmt.Errorf("unknown export-bundle option %q (use secrets)", args[2])
+			}
+			passphrase, err := readPassphrase("Passphrase for the secrets: ", true)
+			if err != nil {
+				return err
+			}
+			if passphrase == "" {
+				return fmt.Errorf("empty passphrase, leave out secrets to export without them")
+			}
+			params["passphrase"] = passphrase
+		}
+
+		result, err := server.Call("network.bundle.export", params)
+		if err != nil {
+			return err
+		}
+		var manifest network.BundleManifest
+		if err := json.Unmarshal(result, &manifest); err != nil {
+			return err
+		}
+		for _, p := range manifest.Profiles {
+			fmt.Printf("  %s (%s)\n", p.ID, p.Type)
+		}
+		secrets := "without secrets"
+		if manifest.Secrets {
+			secrets = "with encrypted secrets"
+		}
+		fmt.Printf("Exported %d profile(s) %s to %s\n", len(manifest.Profiles), secrets, path)
+		return nil
+	}
+
+	params := map[string]interface{}{"path": path}
+	if len(args) > 2 {
+		params["onConflict"] = args[2]
+	}
+	manifest, err := network.ReadBundleManifest(path)
+	if err != nil {
+		return err
+	}
+	if manifest.Secrets {
+		passphrase, err := readPassphrase("Passphrase (empty to import without secrets): ", false)
+		if err != nil {
+			return err
+		}
+		params["passphrase"] = passphrase
+	}
+
+	result, err := server.Call("network.bundle.import", params)
+	if err != nil {
+		return err
+	}
+	var imported network.BundleImportResult
+	if err := json.Unmarshal(result, &imported); err != nil {
+		return err
+	}
+	for _, r := range imported.Imported {
+		fmt.Printf("  %-8s %s\n", r.Action, r.ID)
+	}
+	for id, reason := range imported.Failed {
+		fmt.Printf("  failed   %s: %s\n", id, reason)
+	}
+	fmt.Printf("Imported %d of %d profile(s)\n", len(imported.Imported), len(manifest.Profiles))
+	if imported.SecretsSkipped {
+		fmt.Println("Passwords were not restored; NetworkManager asks for them on the next connect")
+	}
+	return nil
+}
+
+// readPassphrase reads a passphrase without echo on a terminal, asking twice
+// when confirm is set, or one line from stdin otherwise
+func readPassphrase(prompt string, confirm bool) (string, error) {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read passphrase from stdin: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	data, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if !confirm {
+		return string(data), nil
+	}
+	if len(data) == 0 {
+		return "", nil
+	}
+
+	fmt.Fprint(os.Stderr, "Repeat the passphrase: ")
+	again, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if string(again) != string(data) {
+		return "", fmt.Errorf("passphrases don't match")
+	}
+	return string(data), nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+
+	"github.com/AvengeMedia/danklinux/internal/greeter"
+)
+
+// runPrivileged runs one of privesc.HelperActions. pkexec starts it as root
+// and sets PKEXEC_UID to the user who authenticated
+func runPrivileged(args []string) error {
+	if os.Geteuid() != 0 {
+		return fmt.Errorf("must be run through pkexec")
+	}
+	uid := os.Getenv("PKEXEC_UID")
+	if uid == "" {
+		return fmt.Errorf("PKEXEC_UID is not set")
+	}
+	caller, err := user.LookupId(uid)
+	if err != nil {
+		return fmt.Errorf("failed to look up uid %s: %w", uid, err)
+	}
+
+	switch {
+	case len(args) == 1 && args[0] == "greeter-group":
+		return greeter.JoinGreeterGroup(caller.Username)
+	case len(args) == 2 && args[0] == "greeter-theme-sync" && (args[1] == "on" || args[1] == "off"):
+		return greeter.SetCacheShared(args[1] == "on")
+	}
+	return fmt.Errorf("unknown privileged action %v", args)
+}
+
+func isPrivilegedHelper() bool {
+	return len(os.Args) > 1 && os.Args[1] == "privileged" && os.Getenv("PKEXEC_UID") != ""
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/profile"
+	"github.com/AvengeMedia/danklinux/internal/server"
+)
+
+func runProfileList() error {
+	config, err := profile.Load(profile.ConfigPath())
+	if err != nil {
+		return err
+	}
+
+	current := profile.ReadCurrent()
+	for _, name := range config.Names() {
+		marker := "  "
+		if name == current {
+			marker = "* "
+		}
+		fmt.Println(marker + name)
+	}
+	return nil
+}
+
+func runProfileApply(name string, auto bool) error {
+	config, err := profile.Load(profile.ConfigPath())
+	if err != nil {
+		return err
+	}
+
+	var p *profile.Profile
+	if auto {
+		connected := profile.ConnectedOutputs()
+		p = config.Match(connected)
+		if p == nil {
+			return fmt.Errorf("no profile matches the connected outputs (%s)", strings.Join(connected, ", "))
+		}
+		// Auto mode runs from hooks on every output change, so only switch
+		// when the match actually changed
+		if p.Name == profile.ReadCurrent() {
+			log.Debugf("Profile %s already active", p.Name)
+			return nil
+		}
+	} else if p, err = config.Get(name); err != nil {
+		return err
+	}
+
+	applier := profile.NewApplier(func(method string, params map[string]interface{}) error {
+		_, err := server.Call(method, params)
+		return err
+	})
+
+	failed := 0
+	for _, step := range applier.Apply(p) {
+		if step.Err != nil {
+			log.Warnf("Profile %s: %s: %v", p.Name, step.Name, step.Err)
+			failed++
+		}
+	}
+	if err := profile.WriteCurrent(p.Name); err != nil {
+		log.Warnf("Failed to record active profile: %v", err)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("profile %s applied with %d failed step(s)", p.Name, failed)
+	}
+	fmt.Printf("Applied profile %s\n", p.Name)
+	return nil
+}
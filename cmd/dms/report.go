@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/AvengeMedia/danklinux/internal/report"
+)
+
+func runReportIssue(output, title, repo string, lines int, open bool) error {
+	fmt.Fprintln(os.Stderr, "Collecting diagnostics (nothing is sent anywhere)...")
+	r := report.Collect(Version, lines, report.DefaultRedactor())
+	markdown := r.Markdown(true)
+
+	if output != "" {
+		if err := os.WriteFile(output, []byte(markdown), 0600); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Report saved to %s\n", output)
+	} else {
+		fmt.Print(markdown)
+	}
+
+	if !open {
+		fmt.Fprintln(os.Stderr, "Review the report, then paste it into a new issue, or rerun with --open for a prefilled link")
+		return nil
+	}
+
+	issueURL, logsOmitted := r.IssueURL(repo, title)
+	if logsOmitted {
+		fmt.Fprintln(os.Stderr, "Logs are too long for the link and were left out; attach them from the report")
+	}
+	fmt.Fprintf(os.Stderr, "\n%s\n", issueURL)
+	if err := exec.Command("xdg-open", issueURL).Start(); err != nil {
+		fmt.Fprintln(os.Stderr, "Could not open a browser; open the link above to review and submit the issue")
+	}
+	return nil
+}
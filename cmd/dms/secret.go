@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/secrets"
+	"github.com/charmbracelet/x/term"
+)
+
+func runSecretSet(name string) error {
+	var value string
+	if term.IsTerminal(os.Stdin.Fd()) {
+		fmt.Fprintf(os.Stderr, "Value for %s: ", name)
+		data, err := term.ReadPassword(os.Stdin.Fd())
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return err
+		}
+		value = string(data)
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("failed to read value from stdin: %w", err)
+		}
+		value = strings.TrimRight(line, "\r\n")
+	}
+	if value == "" {
+		return fmt.Errorf("empty value, use dms secret rm to remove a secret")
+	}
+
+	store := secrets.Open(secrets.Path())
+	if err := store.Set(name, value); err != nil {
+		return err
+	}
+	source, _ := store.KeySource()
+	fmt.Printf("Stored %s (key in %s)\n", name, source)
+	return nil
+}
+
+func runSecretGet(name string) error {
+	value, err := secrets.Open(secrets.Path()).Get(name)
+	if errors.Is(err, secrets.ErrNotFound) {
+		return fmt.Errorf("no secret named %s", name)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println(value)
+	return nil
+}
+
+func runSecretRm(name string) error {
+	err := secrets.Open(secrets.Path()).Remove(name)
+	if errors.Is(err, secrets.ErrNotFound) {
+		return fmt.Errorf("no secret named %s", name)
+	}
+	return err
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/AvengeMedia/danklinux/internal/privesc"
+)
+
+func runSetupPrivileges(printOnly bool) error {
+	if printOnly {
+		helper, err := privesc.HelperPath()
+		if err != nil {
+			return err
+		}
+		fmt.Print(privesc.PolkitRules(privesc.AdminGroup(), helper))
+		return nil
+	}
+
+	if err := privesc.InstallPolkitRules(func(msg string) { fmt.Println(msg) }); err != nil {
+		return err
+	}
+	fmt.Println("Privileged dms commands now prompt through your polkit agent when run from the session.")
+	return nil
+}
@@ -11,8 +11,10 @@ import (
 	"strings"
 	"syscall"
 
+	"github.com/AvengeMedia/danklinux/internal/crashes"
 	"github.com/AvengeMedia/danklinux/internal/log"
 	"github.com/AvengeMedia/danklinux/internal/server"
+	"github.com/AvengeMedia/danklinux/internal/utils"
 )
 
 func locateDMSConfig() (string, error) {
@@ -65,7 +67,7 @@ func getPIDFilePath() string {
 
 func writePIDFile(childPID int) error {
 	pidFile := getPIDFilePath()
-	return os.WriteFile(pidFile, []byte(strconv.Itoa(childPID)), 0644)
+	return utils.WriteFileAtomic(pidFile, []byte(strconv.Itoa(childPID)), 0644)
 }
 
 func removePIDFile() {
@@ -134,6 +136,12 @@ func runShellInteractive() {
 	go printASCII()
 	fmt.Fprintf(os.Stderr, "dms %s\n", Version)
 
+	if capture, err := crashes.CapturePanics(Version); err != nil {
+		log.Warnf("Failed to set up crash capture: %v", err)
+	} else {
+		defer capture.Close()
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -178,6 +186,7 @@ func runShellInteractive() {
 
 	go func() {
 		if err := cmd.Wait(); err != nil {
+			recordQuickshellCrash(cmd, err)
 			errChan <- fmt.Errorf("quickshell exited: %w", err)
 		} else {
 			errChan <- fmt.Errorf("quickshell exited")
@@ -201,6 +210,12 @@ func runShellInteractive() {
 	}
 }
 
+func recordQuickshellCrash(cmd *exec.Cmd, err error) {
+	if crash, ok := crashes.RecordQuickshellExit(Version, cmd.Process.Pid, err); ok {
+		log.Errorf("quickshell crashed: %s (report saved as %s, see dms logs --crashes)", crash.Reason, crash.ID)
+	}
+}
+
 func restartShell() {
 	killShell()
 	runShellDaemon()
@@ -285,6 +300,12 @@ func runShellDaemon() {
 
 	fmt.Fprintf(os.Stderr, "dms %s\n", Version)
 
+	if capture, err := crashes.CapturePanics(Version); err != nil {
+		log.Warnf("Failed to set up crash capture: %v", err)
+	} else {
+		defer capture.Close()
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -336,6 +357,7 @@ func runShellDaemon() {
 
 	go func() {
 		if err := cmd.Wait(); err != nil {
+			recordQuickshellCrash(cmd, err)
 			errChan <- fmt.Errorf("quickshell exited: %w", err)
 		} else {
 			errChan <- fmt.Errorf("quickshell exited")
@@ -368,6 +390,13 @@ func runShellIPCCommand(args []string) {
 		args = append([]string{"call"}, args...)
 	}
 
+	if handled, err := runBackendIPCCommand(args[1:]); handled {
+		if err != nil {
+			log.Fatalf("Error running IPC command: %v", err)
+		}
+		return
+	}
+
 	configPath, err := locateDMSConfig()
 	if err != nil {
 		log.Fatalf("Error locating DMS config: %v", err)
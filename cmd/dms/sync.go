@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/AvengeMedia/danklinux/internal/plugins"
+	"github.com/AvengeMedia/danklinux/internal/settingsync"
+)
+
+func syncItems() []settingsync.Item {
+	listPlugins := func() ([]string, error) {
+		manager, err := plugins.NewManager()
+		if err != nil {
+			return nil, err
+		}
+		return manager.ListInstalled()
+	}
+	return settingsync.DefaultItems(listPlugins, installPluginCLI)
+}
+
+func printSyncStatuses(statuses []settingsync.ItemStatus, asJSON bool) error {
+	if asJSON {
+		data, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	for _, status := range statuses {
+		line := fmt.Sprintf("%-20s %s", status.Name, status.State)
+		if status.Note != "" {
+			line += " (" + status.Note + ")"
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func runSyncInit(target string) error {
+	syncer, err := settingsync.Init(target, syncItems())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Syncing settings through %s\n", syncer.Dir)
+	return nil
+}
+
+func runSyncStatus(asJSON bool) error {
+	syncer, err := settingsync.Load(syncItems())
+	if err != nil {
+		return err
+	}
+	statuses, err := syncer.Status()
+	if err != nil {
+		return err
+	}
+	return printSyncStatuses(statuses, asJSON)
+}
+
+func runSyncPush(force bool) error {
+	syncer, err := settingsync.Load(syncItems())
+	if err != nil {
+		return err
+	}
+	statuses, err := syncer.Push(force)
+	if err != nil {
+		return err
+	}
+	return printSyncStatuses(statuses, false)
+}
+
+func runSyncPull(force bool) error {
+	syncer, err := settingsync.Load(syncItems())
+	if err != nil {
+		return err
+	}
+	statuses, err := syncer.Pull(force)
+	if err != nil {
+		return err
+	}
+	if err := printSyncStatuses(statuses, false); err != nil {
+		return err
+	}
+	notifyShellSettingsChanged()
+	return nil
+}
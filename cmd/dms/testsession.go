@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/testsession"
+)
+
+func runTestSession(compositor, config string) error {
+	dms, err := os.Executable()
+	if err != nil {
+		dms = "dms"
+	}
+
+	session, err := testsession.Prepare(testsession.Options{
+		Compositor: compositor,
+		Config:     config,
+		DMS:        dms,
+	})
+	if err != nil {
+		return err
+	}
+	defer session.Cleanup()
+
+	log.Infof("Starting nested %s with %s", session.Compositor, session.Config)
+	fmt.Println("Close the window or press Ctrl+C here to end the test session.")
+
+	cmd := session.Cmd
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", session.Compositor, err)
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go func() {
+		if _, ok := <-sigChan; ok {
+			cmd.Process.Signal(syscall.SIGTERM)
+		}
+	}()
+
+	if err := cmd.Wait(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && !exitErr.Exited() {
+			return nil
+		}
+		return fmt.Errorf("%s exited: %w", session.Compositor, err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/themes"
+)
+
+func runThemesList() {
+	installed := make(map[string]bool)
+	for _, theme := range themes.ListInstalled() {
+		installed[theme.Name] = true
+	}
+
+	fmt.Println("Available:")
+	for _, theme := range themes.Catalog() {
+		var variants []string
+		for _, variant := range theme.Variants {
+			if installed[variant] {
+				variant += "*"
+			}
+			variants = append(variants, variant)
+		}
+		fmt.Printf("  %-10s %-7s %s\n", theme.ID, theme.Kind, strings.Join(variants, ", "))
+	}
+
+	fmt.Println("\nInstalled:")
+	for _, theme := range themes.ListInstalled() {
+		fmt.Printf("  %-7s %s\n", theme.Kind, theme.Name)
+	}
+}
+
+// runThemesInstall installs a catalog theme by ID or variant name and, with
+// apply, switches to that variant (or the theme's first one)
+func runThemesInstall(name string, apply bool, cursorSize int) error {
+	theme, ok := themes.Lookup(name)
+	if !ok {
+		return fmt.Errorf("unknown theme %q, see dms themes list", name)
+	}
+
+	if err := themes.Install(context.Background(), theme, func(msg string) { log.Info(msg) }); err != nil {
+		return err
+	}
+	if !apply {
+		return nil
+	}
+
+	variant := theme.Variants[0]
+	for _, v := range theme.Variants {
+		if strings.EqualFold(v, name) {
+			variant = v
+		}
+	}
+	return runThemesApply(variant, cursorSize)
+}
+
+func runThemesApply(name string, cursorSize int) error {
+	kind, err := themes.DefaultApplier().Apply(name, cursorSize)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Applied %s %s theme\n", name, kind)
+	return nil
+}
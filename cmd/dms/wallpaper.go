@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/server"
+	"github.com/AvengeMedia/danklinux/internal/server/wallpaper"
+)
+
+func runWallpaperSet(path, output, fill string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	return callAndPrint("wallpaper.set", map[string]interface{}{
+		"path":   absPath,
+		"output": output,
+		"fill":   fill,
+	})
+}
+
+func runWallpaperSlideshow(dir string, interval time.Duration, shuffle bool, outputs []string) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	params := map[string]interface{}{
+		"dir":      absDir,
+		"interval": interval.Seconds(),
+		"shuffle":  shuffle,
+	}
+	if len(outputs) > 0 {
+		params["outputs"] = outputs
+	}
+	return callAndPrint("wallpaper.startSlideshow", params)
+}
+
+func runWallpaperTransition(transitionType string, duration float64) error {
+	params := map[string]interface{}{"type": transitionType}
+	if duration >= 0 {
+		params["duration"] = duration
+	}
+	return callAndPrint("wallpaper.setTransition", params)
+}
+
+func runWallpaperGet() error {
+	result, err := server.Call("wallpaper.getState", nil)
+	if err != nil {
+		return err
+	}
+
+	var state wallpaper.State
+	if err := json.Unmarshal(result, &state); err != nil {
+		return err
+	}
+
+	fmt.Printf("Backend:    %s\n", state.Backend)
+	fmt.Printf("Transition: %s (%gs)\n", state.Transition.Type, state.Transition.Duration)
+
+	outputs := make([]string, 0, len(state.Outputs))
+	for name := range state.Outputs {
+		outputs = append(outputs, name)
+	}
+	sort.Strings(outputs)
+	for _, name := range outputs {
+		label := name
+		if name == wallpaper.AllOutputs {
+			label = "all outputs"
+		}
+		fmt.Printf("%-12s%s (%s)\n", label+":", state.Outputs[name].Path, state.Outputs[name].Fill)
+	}
+
+	if state.Slideshow != nil {
+		fmt.Printf("Slideshow:  %s every %s", state.Slideshow.Dir, time.Duration(state.Slideshow.Interval)*time.Second)
+		if state.Slideshow.Shuffle {
+			fmt.Print(", shuffled")
+		}
+		if state.NextChange != nil {
+			fmt.Printf(", next in %s", time.Until(*state.NextChange).Round(time.Second))
+		}
+		fmt.Println()
+	}
+	return nil
+}
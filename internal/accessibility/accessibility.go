@@ -0,0 +1,238 @@
+// Package accessibility applies the installer's optional accessibility
+// setup: the Orca screen reader, larger text, reduced motion and high
+// contrast, across the shell, GTK and the compositor
+package accessibility
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/autostart"
+	"github.com/AvengeMedia/danklinux/internal/config"
+	"github.com/AvengeMedia/danklinux/internal/distros"
+	"github.com/AvengeMedia/danklinux/internal/kdl"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+const (
+	ScreenReader  = "screen-reader"
+	LargeText     = "large-text"
+	ReducedMotion = "reduced-motion"
+	HighContrast  = "high-contrast"
+)
+
+const largeTextScale = 1.25
+
+type Feature struct {
+	Key         string
+	Name        string
+	Description string
+}
+
+func Features() []Feature {
+	return []Feature{
+		{ScreenReader, "Screen reader", "Install Orca and start it with the session"},
+		{LargeText, "Large text", fmt.Sprintf("Scale shell and app text by %.2gx", largeTextScale)},
+		{ReducedMotion, "Reduced motion", "Turn off shell, GTK and compositor animations"},
+		{HighContrast, "High contrast", "High-contrast GTK theme and opaque shell surfaces"},
+	}
+}
+
+// packages lists what each feature needs installed, per distro family
+var packages = map[string]map[distros.DistroFamily][]string{
+	ScreenReader: {
+		distros.FamilyArch:   {"orca"},
+		distros.FamilyFedora: {"orca"},
+		distros.FamilySUSE:   {"orca"},
+		distros.FamilyUbuntu: {"orca"},
+		distros.FamilyDebian: {"orca"},
+	},
+	HighContrast: {
+		distros.FamilyArch:   {"gnome-themes-extra"},
+		distros.FamilyFedora: {"gnome-themes-extra"},
+		distros.FamilySUSE:   {"gnome-themes-extra"},
+		distros.FamilyUbuntu: {"gnome-themes-extra"},
+		distros.FamilyDebian: {"gnome-themes-extra"},
+	},
+}
+
+// Paths are the files the setup edits
+type Paths struct {
+	ShellSettings string
+	Hyprland      string
+	Niri          string
+	Autostart     autostart.Paths
+}
+
+func DefaultPaths() Paths {
+	home := os.Getenv("HOME")
+	return Paths{
+		ShellSettings: config.ShellSettingsPath(),
+		Hyprland:      filepath.Join(home, ".config", "hypr", "hyprland.conf"),
+		Niri:          config.NiriConfigPath(),
+		Autostart:     autostart.DefaultPaths(),
+	}
+}
+
+type gsetting struct {
+	schema, key, value string
+}
+
+// Setup installs and configures the selected features. Package failures
+// abort; settings that can't be written (no gsettings schema, compositor not
+// configured) are logged and skipped
+func Setup(ctx context.Context, selected map[string]bool, family distros.DistroFamily, paths Paths, logFunc func(string)) error {
+	if err := installPackages(ctx, selected, family, logFunc); err != nil {
+		return err
+	}
+
+	shell := make(map[string]interface{})
+	var gsettings []gsetting
+
+	if selected[ScreenReader] {
+		gsettings = append(gsettings,
+			gsetting{"org.gnome.desktop.a11y.applications", "screen-reader-enabled", "true"},
+			gsetting{"org.gnome.desktop.interface", "toolkit-accessibility", "true"})
+		if err := enableOrcaAutostart(paths.Autostart); err != nil {
+			logFunc(fmt.Sprintf("⚠ Could not add Orca to autostart: %v", err))
+		} else {
+			logFunc("✓ Orca starts with the session")
+		}
+	}
+	if selected[LargeText] {
+		gsettings = append(gsettings, gsetting{"org.gnome.desktop.interface", "text-scaling-factor", fmt.Sprint(largeTextScale)})
+		shell["fontScale"] = largeTextScale
+	}
+	if selected[ReducedMotion] {
+		gsettings = append(gsettings, gsetting{"org.gnome.desktop.interface", "enable-animations", "false"})
+		shell["animationSpeed"] = 0
+		if err := disableCompositorAnimations(paths); err != nil {
+			logFunc(fmt.Sprintf("⚠ Could not turn off compositor animations: %v", err))
+		}
+	}
+	if selected[HighContrast] {
+		gsettings = append(gsettings,
+			gsetting{"org.gnome.desktop.a11y.interface", "high-contrast", "true"},
+			gsetting{"org.gnome.desktop.interface", "gtk-theme", "HighContrast"})
+		shell["popupTransparency"] = 1.0
+		shell["dankBarTransparency"] = 1.0
+	}
+
+	for _, setting := range gsettings {
+		if err := setGSetting(ctx, setting); err != nil {
+			logFunc(fmt.Sprintf("⚠ %v", err))
+		}
+	}
+
+	if len(shell) > 0 {
+		if err := updateShellSettings(paths.ShellSettings, shell); err != nil {
+			return fmt.Errorf("failed to update shell settings: %w", err)
+		}
+		logFunc("✓ Accessibility shell settings saved")
+	}
+	return nil
+}
+
+func installPackages(ctx context.Context, selected map[string]bool, family distros.DistroFamily, logFunc func(string)) error {
+	var pkgs []string
+	for _, feature := range Features() {
+		if selected[feature.Key] {
+			pkgs = append(pkgs, packages[feature.Key][family]...)
+		}
+	}
+	if len(pkgs) == 0 {
+		return nil
+	}
+
+	var args []string
+	switch family {
+	case distros.FamilyArch:
+		args = append([]string{"pacman", "-S", "--needed", "--noconfirm"}, pkgs...)
+	case distros.FamilyFedora:
+		args = append([]string{"dnf", "install", "-y"}, pkgs...)
+	case distros.FamilySUSE:
+		args = append([]string{"zypper", "install", "-y"}, pkgs...)
+	case distros.FamilyUbuntu, distros.FamilyDebian:
+		args = append([]string{"apt-get", "install", "-y"}, pkgs...)
+	default:
+		logFunc(fmt.Sprintf("Add %s to your system configuration to finish accessibility setup", strings.Join(pkgs, ", ")))
+		return nil
+	}
+
+	logFunc(fmt.Sprintf("Installing %s...", strings.Join(pkgs, ", ")))
+	if output, err := privesc.Command(ctx, args[0], args[1:]...).CombinedOutput(); err != nil {
+		logFunc(strings.TrimSpace(string(output)))
+		return fmt.Errorf("failed to install accessibility packages: %w", err)
+	}
+	logFunc(fmt.Sprintf("✓ Installed %s", strings.Join(pkgs, ", ")))
+	return nil
+}
+
+func setGSetting(ctx context.Context, setting gsetting) error {
+	output, err := exec.CommandContext(ctx, "gsettings", "set", setting.schema, setting.key, setting.value).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("gsettings set %s %s failed: %s", setting.schema, setting.key, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func enableOrcaAutostart(paths autostart.Paths) error {
+	entries, err := paths.List()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Name == "orca" && entry.Enabled {
+			return nil
+		}
+	}
+	_, err = paths.Add("orca", "orca --replace", false)
+	return err
+}
+
+// updateShellSettings merges values into settings.json, creating it when
+// the shell hasn't run yet; the shell fills in every other default
+func updateShellSettings(path string, values map[string]interface{}) error {
+	settings := make(config.ShellSettings)
+	if _, err := os.Stat(path); err == nil {
+		if settings, err = config.LoadShellSettings(path); err != nil {
+			return err
+		}
+	}
+	for key, value := range values {
+		settings[key] = value
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return settings.Save(path)
+}
+
+var hyprlandAnimationsLine = "animations:enabled = false"
+
+func disableCompositorAnimations(paths Paths) error {
+	if data, err := os.ReadFile(paths.Hyprland); err == nil {
+		content := string(data)
+		if !strings.Contains(content, hyprlandAnimationsLine) {
+			if !strings.HasSuffix(content, "\n") {
+				content += "\n"
+			}
+			content += "\n# Reduced motion (dankinstall accessibility setup)\n" + hyprlandAnimationsLine + "\n"
+			if err := utils.WriteFileAtomic(paths.Hyprland, []byte(content), 0644); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := os.Stat(paths.Niri); err == nil {
+		return config.EditNiriConfig(paths.Niri, func(doc *kdl.Document) error {
+			return config.SetNiriOption(doc, "niri.animations.off", []string{"true"})
+		})
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package accessibility
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/config"
+)
+
+func TestUpdateShellSettingsCreatesAndMerges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "DankMaterialShell", "settings.json")
+
+	if err := updateShellSettings(path, map[string]interface{}{"fontScale": 1.25}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := updateShellSettings(path, map[string]interface{}{"animationSpeed": 0}); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	settings, err := config.LoadShellSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings["fontScale"] != 1.25 {
+		t.Errorf("fontScale = %v, want 1.25", settings["fontScale"])
+	}
+	if settings["animationSpeed"] != float64(0) {
+		t.Errorf("animationSpeed = %v, want 0", settings["animationSpeed"])
+	}
+}
+
+func TestDisableCompositorAnimationsHyprland(t *testing.T) {
+	dir := t.TempDir()
+	paths := Paths{
+		Hyprland: filepath.Join(dir, "hyprland.conf"),
+		Niri:     filepath.Join(dir, "missing.kdl"),
+	}
+	if err := os.WriteFile(paths.Hyprland, []byte("monitor = ,preferred,auto,1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := disableCompositorAnimations(paths); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(paths.Hyprland)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), hyprlandAnimationsLine); n != 1 {
+		t.Errorf("animations line appears %d times, want 1:\n%s", n, data)
+	}
+	if !strings.HasPrefix(string(data), "monitor = ,preferred,auto,1\n") {
+		t.Errorf("existing config not preserved:\n%s", data)
+	}
+}
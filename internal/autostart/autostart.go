@@ -0,0 +1,209 @@
+// Package autostart manages programs started with the session: XDG
+// autostart desktop entries and the DMS-managed block of exec-once /
+// spawn-at-startup lines kept in sync across the Hyprland and niri configs
+package autostart
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+// Entry sources. Hyprland and niri entries are lines outside the managed
+// block and are only listed, never edited
+const (
+	SourceDMS      = "dms"
+	SourceXDG      = "xdg"
+	SourceHyprland = "hyprland"
+	SourceNiri     = "niri"
+)
+
+type Entry struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+	Source  string `json:"source"`
+	Enabled bool   `json:"enabled"`
+	// System is set for XDG entries from /etc/xdg/autostart, which are
+	// disabled with a user override instead of being deleted
+	System bool   `json:"system,omitempty"`
+	Path   string `json:"path,omitempty"`
+}
+
+type Paths struct {
+	Managed         string
+	UserAutostart   string
+	SystemAutostart []string
+	Hyprland        string
+	Niri            string
+}
+
+func DefaultPaths() Paths {
+	home := os.Getenv("HOME")
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+	configDirs := os.Getenv("XDG_CONFIG_DIRS")
+	if configDirs == "" {
+		configDirs = "/etc/xdg"
+	}
+
+	var system []string
+	for _, dir := range filepath.SplitList(configDirs) {
+		system = append(system, filepath.Join(dir, "autostart"))
+	}
+
+	return Paths{
+		Managed:         filepath.Join(configHome, "dms", "autostart.json"),
+		UserAutostart:   filepath.Join(configHome, "autostart"),
+		SystemAutostart: system,
+		Hyprland:        filepath.Join(configHome, "hypr", "hyprland.conf"),
+		Niri:            filepath.Join(configHome, "niri", "config.kdl"),
+	}
+}
+
+type managedEntry struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+}
+
+func (p Paths) loadManaged() ([]managedEntry, error) {
+	data, err := os.ReadFile(p.Managed)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entries []managedEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", p.Managed, err)
+	}
+	return entries, nil
+}
+
+func (p Paths) saveManaged(entries []managedEntry) error {
+	if entries == nil {
+		entries = []managedEntry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p.Managed), 0755); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(p.Managed, append(data, '\n'), 0644)
+}
+
+// List returns managed, XDG and compositor entries, in that order
+func (p Paths) List() ([]Entry, error) {
+	managed, err := p.loadManaged()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, m := range managed {
+		entries = append(entries, Entry{Name: m.Name, Command: m.Command, Source: SourceDMS, Enabled: true, Path: p.Managed})
+	}
+	entries = append(entries, p.listXDG()...)
+	entries = append(entries, p.listCompositor()...)
+	return entries, nil
+}
+
+func defaultName(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return ""
+	}
+	return filepath.Base(fields[0])
+}
+
+// Add registers a command to start with the session. By default it goes to
+// the managed block of every compositor config; xdg writes a desktop entry
+// to ~/.config/autostart instead
+func (p Paths) Add(name, command string, xdg bool) (Entry, error) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return Entry{}, fmt.Errorf("command is empty")
+	}
+	if strings.ContainsAny(command, "\n\r") {
+		return Entry{}, fmt.Errorf("command must be a single line")
+	}
+	if name == "" {
+		name = defaultName(command)
+	}
+
+	existing, err := p.List()
+	if err != nil {
+		return Entry{}, err
+	}
+	for _, entry := range existing {
+		if entry.Name == name && (entry.Source == SourceDMS || entry.Source == SourceXDG) {
+			return Entry{}, fmt.Errorf("an autostart entry named %q already exists", name)
+		}
+	}
+
+	if xdg {
+		return p.addXDG(name, command)
+	}
+
+	managed, err := p.loadManaged()
+	if err != nil {
+		return Entry{}, err
+	}
+	managed = append(managed, managedEntry{Name: name, Command: command})
+	if err := p.saveManaged(managed); err != nil {
+		return Entry{}, err
+	}
+	if err := p.Sync(); err != nil {
+		return Entry{}, err
+	}
+	return Entry{Name: name, Command: command, Source: SourceDMS, Enabled: true, Path: p.Managed}, nil
+}
+
+// Remove deletes a managed or user XDG entry by name (or desktop file ID).
+// System XDG entries are hidden with a user override
+func (p Paths) Remove(name string) (Entry, error) {
+	managed, err := p.loadManaged()
+	if err != nil {
+		return Entry{}, err
+	}
+	for i, m := range managed {
+		if m.Name != name {
+			continue
+		}
+		managed = append(managed[:i], managed[i+1:]...)
+		if err := p.saveManaged(managed); err != nil {
+			return Entry{}, err
+		}
+		return Entry{Name: m.Name, Command: m.Command, Source: SourceDMS}, p.Sync()
+	}
+
+	for _, entry := range p.listXDG() {
+		id := strings.TrimSuffix(filepath.Base(entry.Path), ".desktop")
+		if entry.Name != name && id != name && filepath.Base(entry.Path) != name {
+			continue
+		}
+		return entry, p.removeXDG(entry)
+	}
+
+	for _, entry := range p.listCompositor() {
+		if entry.Name == name || entry.Command == name {
+			return Entry{}, fmt.Errorf("%q is not managed by dms; remove it from %s", name, entry.Path)
+		}
+	}
+	return Entry{}, fmt.Errorf("no autostart entry named %q", name)
+}
+
+func sortEntries(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+	})
+}
@@ -0,0 +1,134 @@
+package autostart
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testHyprland = `monitor = , preferred, auto, 1
+exec-once = waybar
+`
+
+const testNiri = `input {
+    keyboard {
+        numlock
+    }
+}
+
+spawn-at-startup "sh" "-c" "swaybg -i ~/wall.png"
+`
+
+func testPaths(t *testing.T) Paths {
+	dir := t.TempDir()
+	p := Paths{
+		Managed:         filepath.Join(dir, "dms", "autostart.json"),
+		UserAutostart:   filepath.Join(dir, "autostart"),
+		SystemAutostart: []string{filepath.Join(dir, "xdg", "autostart")},
+		Hyprland:        filepath.Join(dir, "hyprland.conf"),
+		Niri:            filepath.Join(dir, "config.kdl"),
+	}
+	require.NoError(t, os.WriteFile(p.Hyprland, []byte(testHyprland), 0644))
+	require.NoError(t, os.WriteFile(p.Niri, []byte(testNiri), 0644))
+	require.NoError(t, os.MkdirAll(p.SystemAutostart[0], 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(p.SystemAutostart[0], "nm-applet.desktop"),
+		[]byte("[Desktop Entry]\nName=Network\nExec=nm-applet\n"), 0644))
+	return p
+}
+
+func read(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestReplaceBlock(t *testing.T) {
+	withBlock := replaceBlock("a\n", "#", []string{"exec-once = b"})
+	assert.Equal(t, "a\n\n# "+blockBegin+"\nexec-once = b\n# "+blockEnd+"\n", withBlock)
+
+	updated := replaceBlock(withBlock, "#", []string{"exec-once = c"})
+	assert.Contains(t, updated, "exec-once = c")
+	assert.NotContains(t, updated, "exec-once = b")
+
+	assert.Equal(t, "a\n", replaceBlock(withBlock, "#", nil))
+	assert.Equal(t, "a", replaceBlock("a", "#", nil))
+}
+
+func TestAddSyncsCompositors(t *testing.T) {
+	p := testPaths(t)
+
+	entry, err := p.Add("", `notify-send "hello world"`, false)
+	require.NoError(t, err)
+	assert.Equal(t, "notify-send", entry.Name)
+	assert.Equal(t, SourceDMS, entry.Source)
+
+	assert.Contains(t, read(t, p.Hyprland), "exec-once = waybar\n\n# "+blockBegin+"\nexec-once = notify-send \"hello world\"\n")
+	assert.Contains(t, read(t, p.Niri), `spawn-at-startup "sh" "-c" "notify-send \"hello world\""`)
+
+	_, err = p.Add("notify-send", "notify-send again", false)
+	assert.Error(t, err)
+
+	_, err = p.Remove("notify-send")
+	require.NoError(t, err)
+	assert.Equal(t, testHyprland, read(t, p.Hyprland))
+	assert.Equal(t, testNiri, read(t, p.Niri))
+}
+
+func TestList(t *testing.T) {
+	p := testPaths(t)
+	_, err := p.Add("mako", "mako", false)
+	require.NoError(t, err)
+
+	entries, err := p.List()
+	require.NoError(t, err)
+
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Source+":"+entry.Name+":"+entry.Command)
+	}
+	// The managed block lines aren't listed again as compositor entries
+	assert.Equal(t, []string{
+		"dms:mako:mako",
+		"xdg:Network:nm-applet",
+		"hyprland:waybar:waybar",
+		"niri:swaybg:swaybg -i ~/wall.png",
+	}, got)
+}
+
+func TestXDG(t *testing.T) {
+	p := testPaths(t)
+
+	entry, err := p.Add("Sync Client", "syncthing serve", true)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(p.UserAutostart, "sync-client.desktop"), entry.Path)
+	assert.Contains(t, read(t, entry.Path), "Exec=syncthing serve\n")
+
+	_, err = p.Remove("Sync Client")
+	require.NoError(t, err)
+	assert.NoFileExists(t, entry.Path)
+
+	// System entries get a hidden user override
+	removed, err := p.Remove("nm-applet")
+	require.NoError(t, err)
+	assert.True(t, removed.System)
+	assert.Contains(t, read(t, filepath.Join(p.UserAutostart, "nm-applet.desktop")), "Hidden=true")
+
+	entries, err := p.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "Network", entries[0].Name)
+	assert.False(t, entries[0].Enabled)
+	assert.False(t, entries[0].System)
+}
+
+func TestRemoveUnmanaged(t *testing.T) {
+	p := testPaths(t)
+	_, err := p.Remove("waybar")
+	assert.ErrorContains(t, err, "not managed by dms")
+
+	_, err = p.Remove("missing")
+	assert.ErrorContains(t, err, "no autostart entry")
+}
@@ -0,0 +1,195 @@
+package autostart
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/config"
+	"github.com/AvengeMedia/danklinux/internal/kdl"
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+const (
+	blockBegin = "BEGIN DMS AUTOSTART (managed by `dms autostart`, edits here are overwritten)"
+	blockEnd   = "END DMS AUTOSTART"
+)
+
+type compositor struct {
+	source  string
+	path    string
+	comment string
+	line    func(command string) string
+	write   func(path, content string) error
+}
+
+func (p Paths) compositors() []compositor {
+	return []compositor{
+		{
+			source:  SourceHyprland,
+			path:    p.Hyprland,
+			comment: "#",
+			line:    func(command string) string { return "exec-once = " + command },
+			write:   writeFile,
+		},
+		{
+			source:  SourceNiri,
+			path:    p.Niri,
+			comment: "//",
+			line: func(command string) string {
+				return fmt.Sprintf(`spawn-at-startup "sh" "-c" %s`, kdl.String(command).Raw)
+			},
+			write: config.WriteNiriConfig,
+		},
+	}
+}
+
+func writeFile(path, content string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(path, []byte(content), info.Mode().Perm())
+}
+
+// replaceBlock swaps the lines between the markers for lines, appending a
+// new block at the end when there is none and dropping it when lines is
+// empty
+func replaceBlock(content, comment string, lines []string) string {
+	begin := comment + " " + blockBegin
+	end := comment + " " + blockEnd
+
+	var block string
+	if len(lines) > 0 {
+		block = begin + "\n" + strings.Join(lines, "\n") + "\n" + end + "\n"
+	}
+
+	start := strings.Index(content, begin)
+	if start == -1 {
+		if block == "" {
+			return content
+		}
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		return content + "\n" + block
+	}
+
+	stop := strings.Index(content[start:], end)
+	if stop == -1 {
+		stop = len(content)
+	} else {
+		stop = start + stop + len(end)
+		if stop < len(content) && content[stop] == '\n' {
+			stop++
+		}
+	}
+	if block == "" {
+		// Also drop the blank line the block was appended after
+		prefix := strings.TrimRight(content[:start], "\n")
+		if prefix != "" {
+			prefix += "\n"
+		}
+		return prefix + content[stop:]
+	}
+	return content[:start] + block + content[stop:]
+}
+
+// Sync rewrites the managed block in every compositor config that exists
+func (p Paths) Sync() error {
+	managed, err := p.loadManaged()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range p.compositors() {
+		data, err := os.ReadFile(c.path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+
+		lines := make([]string, 0, len(managed))
+		for _, m := range managed {
+			lines = append(lines, c.line(m.Command))
+		}
+		content := replaceBlock(string(data), c.comment, lines)
+		if content == string(data) {
+			continue
+		}
+		if err := c.write(c.path, content); err != nil {
+			return fmt.Errorf("failed to update %s: %w", c.path, err)
+		}
+	}
+	return nil
+}
+
+var hyprExecRegex = regexp.MustCompile(`^\s*exec-once\s*=\s*(.+?)\s*$`)
+
+// niriCommand turns a spawn-at-startup line back into a command line,
+// unwrapping the sh -c form
+func niriCommand(line string) string {
+	if !strings.HasPrefix(line, "spawn-at-startup") {
+		return ""
+	}
+	doc, err := kdl.Parse(line)
+	if err != nil {
+		return ""
+	}
+	node := doc.Root.Child("spawn-at-startup")
+	if node == nil {
+		return ""
+	}
+
+	var parts []string
+	for _, arg := range node.Args() {
+		parts = append(parts, arg.String())
+	}
+	if len(parts) == 3 && (parts[0] == "sh" || parts[0] == "bash") && parts[1] == "-c" {
+		return parts[2]
+	}
+	return strings.Join(parts, " ")
+}
+
+// listCompositor returns startup lines outside the managed block
+func (p Paths) listCompositor() []Entry {
+	var entries []Entry
+	for _, c := range p.compositors() {
+		data, err := os.ReadFile(c.path)
+		if err != nil {
+			continue
+		}
+
+		inBlock := false
+		for _, line := range strings.Split(string(data), "\n") {
+			trimmed := strings.TrimSpace(line)
+			switch {
+			case trimmed == c.comment+" "+blockBegin:
+				inBlock = true
+				continue
+			case trimmed == c.comment+" "+blockEnd:
+				inBlock = false
+				continue
+			case inBlock:
+				continue
+			}
+
+			var command string
+			if c.source == SourceHyprland {
+				if match := hyprExecRegex.FindStringSubmatch(line); match != nil {
+					command = match[1]
+				}
+			} else {
+				command = niriCommand(trimmed)
+			}
+			if command == "" {
+				continue
+			}
+			entries = append(entries, Entry{Name: defaultName(command), Command: command, Source: c.source, Enabled: true, Path: c.path})
+		}
+	}
+	return entries
+}
@@ -0,0 +1,122 @@
+package autostart
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+func readDesktopEntry(path string) map[string]string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	inEntry := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inEntry = line == "[Desktop Entry]"
+			continue
+		}
+		if !inEntry {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return values
+}
+
+func xdgEnabled(values map[string]string) bool {
+	return values["Hidden"] != "true" && values["X-GNOME-Autostart-enabled"] != "false"
+}
+
+// listXDG merges the autostart dirs; a user file shadows the system file
+// with the same name
+func (p Paths) listXDG() []Entry {
+	seen := make(map[string]bool)
+	var entries []Entry
+	dirs := append([]string{p.UserAutostart}, p.SystemAutostart...)
+	for i, dir := range dirs {
+		files, _ := filepath.Glob(filepath.Join(dir, "*.desktop"))
+		var dirEntries []Entry
+		for _, path := range files {
+			base := filepath.Base(path)
+			if seen[base] {
+				continue
+			}
+			seen[base] = true
+
+			values := readDesktopEntry(path)
+			if values == nil {
+				continue
+			}
+			name := values["Name"]
+			if name == "" {
+				name = strings.TrimSuffix(base, ".desktop")
+			}
+			dirEntries = append(dirEntries, Entry{
+				Name:    name,
+				Command: values["Exec"],
+				Source:  SourceXDG,
+				Enabled: xdgEnabled(values),
+				System:  i > 0,
+				Path:    path,
+			})
+		}
+		sortEntries(dirEntries)
+		entries = append(entries, dirEntries...)
+	}
+	return entries
+}
+
+var slugRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slug(name string) string {
+	return strings.Trim(slugRegex.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+func (p Paths) addXDG(name, command string) (Entry, error) {
+	id := slug(name)
+	if id == "" {
+		return Entry{}, fmt.Errorf("invalid name %q", name)
+	}
+	path := filepath.Join(p.UserAutostart, id+".desktop")
+	if _, err := os.Stat(path); err == nil {
+		return Entry{}, fmt.Errorf("%s already exists", path)
+	}
+
+	content := fmt.Sprintf("[Desktop Entry]\nType=Application\nName=%s\nExec=%s\nX-DMS-Managed=true\n", name, command)
+	if err := os.MkdirAll(p.UserAutostart, 0755); err != nil {
+		return Entry{}, err
+	}
+	if err := utils.WriteFileAtomic(path, []byte(content), 0644); err != nil {
+		return Entry{}, err
+	}
+	return Entry{Name: name, Command: command, Source: SourceXDG, Enabled: true, Path: path}, nil
+}
+
+func (p Paths) removeXDG(entry Entry) error {
+	if !entry.System {
+		return os.Remove(entry.Path)
+	}
+
+	// Per the autostart spec, a user file with Hidden=true disables the
+	// system entry of the same name
+	override := filepath.Join(p.UserAutostart, filepath.Base(entry.Path))
+	content := fmt.Sprintf("[Desktop Entry]\nType=Application\nName=%s\nExec=%s\nHidden=true\n", entry.Name, entry.Command)
+	if err := os.MkdirAll(p.UserAutostart, 0755); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(override, []byte(content), 0644)
+}
@@ -0,0 +1,255 @@
+package config
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/kdl"
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+// Keybind is a binding as written in the compositor config. Description is
+// niri's hotkey-overlay-title or the text of a Hyprland bindd
+type Keybind struct {
+	Section     string
+	Combo       string
+	Action      string
+	Description string
+}
+
+// Label is what the cheat sheet shows for the bind
+func (k Keybind) Label() string {
+	if k.Description != "" {
+		return k.Description
+	}
+	return k.Action
+}
+
+// sectionRegex matches the "=== Audio Controls ===" comments the templates
+// group binds under
+var sectionRegex = regexp.MustCompile(`^\s*(?://|#)\s*===\s*(.+?)\s*===\s*$`)
+
+func sectionTitle(comment string) (string, bool) {
+	match := sectionRegex.FindStringSubmatch(comment)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// NiriKeybinds lists the binds block of a niri config in order
+func NiriKeybinds(content string) ([]Keybind, error) {
+	doc, err := kdl.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	section := doc.Find("binds")
+	if section == nil {
+		return nil, nil
+	}
+
+	var binds []Keybind
+	current := ""
+	for _, node := range section.Children {
+		for _, comment := range node.Comments {
+			if title, ok := sectionTitle(comment); ok {
+				current = title
+			}
+		}
+		if node.Disabled {
+			continue
+		}
+
+		bind := Keybind{Section: current, Combo: node.Name}
+		if title, ok := node.Prop("hotkey-overlay-title"); ok && title.IsString() {
+			bind.Description = title.String()
+		}
+		for _, action := range node.Children {
+			if action.Disabled {
+				continue
+			}
+			words := []string{action.Name}
+			for _, arg := range action.Args() {
+				words = append(words, arg.String())
+			}
+			bind.Action = strings.Join(words, " ")
+			break
+		}
+		binds = append(binds, bind)
+	}
+	return binds, nil
+}
+
+var hyprlandBindLineRegex = regexp.MustCompile(`^\s*bind([a-z]*)\s*=\s*(.+)$`)
+
+// HyprlandKeybinds lists the bind lines of a Hyprland config in order, with
+// variables like $mod expanded
+func HyprlandKeybinds(content string) []Keybind {
+	vars := parseHyprlandVariables(content)
+
+	var binds []Keybind
+	current := ""
+	for _, line := range strings.Split(content, "\n") {
+		if title, ok := sectionTitle(line); ok {
+			current = title
+			continue
+		}
+		match := hyprlandBindLineRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		fields := 4
+		described := strings.Contains(match[1], "d")
+		if described {
+			fields = 5
+		}
+		parts := strings.SplitN(match[2], ",", fields)
+		if len(parts) < fields-1 {
+			continue
+		}
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+
+		bind := Keybind{Section: current}
+		mods := strings.Fields(strings.ToUpper(expandHyprlandVariables(parts[0], vars)))
+		bind.Combo = strings.Join(append(mods, parts[1]), "+")
+		rest := parts[2:]
+		if described {
+			bind.Description, rest = rest[0], rest[1:]
+		}
+		bind.Action = expandHyprlandVariables(strings.TrimSpace(strings.Join(rest, " ")), vars)
+		binds = append(binds, bind)
+	}
+	return binds
+}
+
+// CheatsheetPath is where the markdown cheat sheet is kept; the image sits
+// next to it as keybinds.svg
+func CheatsheetPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".local", "state", "DankMaterialShell", "keybinds.md")
+}
+
+func CheatsheetImagePath() string {
+	return strings.TrimSuffix(CheatsheetPath(), ".md") + ".svg"
+}
+
+func HyprlandConfigPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".config", "hypr", "hyprland.conf")
+}
+
+// CompositorKeybinds reads the binds of a niri or hyprland config
+func CompositorKeybinds(compositor, content string) ([]Keybind, error) {
+	switch compositor {
+	case "niri":
+		return NiriKeybinds(content)
+	case "hyprland":
+		return HyprlandKeybinds(content), nil
+	}
+	return nil, fmt.Errorf("unsupported compositor: %s", compositor)
+}
+
+// WriteCheatsheet renders the binds of a compositor config as markdown and
+// as an image, replacing the previous cheat sheet
+func WriteCheatsheet(compositor, content, source string) error {
+	binds, err := CompositorKeybinds(compositor, content)
+	if err != nil {
+		return fmt.Errorf("failed to read keybindings: %w", err)
+	}
+	if len(binds) == 0 {
+		return fmt.Errorf("no keybindings found in %s", source)
+	}
+
+	title := "Niri keybindings"
+	if compositor == "hyprland" {
+		title = "Hyprland keybindings"
+	}
+
+	path := CheatsheetPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := utils.WriteFileAtomic(path, []byte(CheatsheetMarkdown(title, source, binds)), 0644); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(CheatsheetImagePath(), []byte(CheatsheetSVG(title, binds)), 0644)
+}
+
+func CheatsheetMarkdown(title, source string, binds []Keybind) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\nGenerated by dms from %s.\n", title, source)
+
+	current := "\x00"
+	for _, bind := range binds {
+		if bind.Section != current {
+			current = bind.Section
+			heading := current
+			if heading == "" {
+				heading = "Other"
+			}
+			fmt.Fprintf(&b, "\n## %s\n\n| Keys | Action |\n| --- | --- |\n", heading)
+		}
+		label := strings.ReplaceAll(bind.Label(), "|", `\|`)
+		fmt.Fprintf(&b, "| `%s` | %s |\n", bind.Combo, label)
+	}
+	return b.String()
+}
+
+const (
+	cheatsheetWidth      = 960
+	cheatsheetMargin     = 32
+	cheatsheetRowHeight  = 24
+	cheatsheetLabelX     = 320
+	cheatsheetLabelChars = 72
+)
+
+// CheatsheetSVG lays the binds out as one column of combo and label rows
+// under their section headings
+func CheatsheetSVG(title string, binds []Keybind) string {
+	var rows strings.Builder
+	y := cheatsheetMargin + 24
+	fmt.Fprintf(&rows, `<text x="%d" y="%d" font-size="24" font-weight="bold" fill="#e6e1e5">%s</text>`+"\n",
+		cheatsheetMargin, y, html.EscapeString(title))
+
+	current := "\x00"
+	for _, bind := range binds {
+		if bind.Section != current {
+			current = bind.Section
+			heading := current
+			if heading == "" {
+				heading = "Other"
+			}
+			y += cheatsheetRowHeight * 3 / 2
+			fmt.Fprintf(&rows, `<text x="%d" y="%d" font-weight="bold" fill="#d0bcff">%s</text>`+"\n",
+				cheatsheetMargin, y, html.EscapeString(heading))
+		}
+		y += cheatsheetRowHeight
+		label := bind.Label()
+		if runes := []rune(label); len(runes) > cheatsheetLabelChars {
+			label = string(runes[:cheatsheetLabelChars-1]) + "…"
+		}
+		fmt.Fprintf(&rows, `<text x="%d" y="%d" fill="#ccc2dc">%s</text><text x="%d" y="%d" fill="#e6e1e5">%s</text>`+"\n",
+			cheatsheetMargin, y, html.EscapeString(bind.Combo), cheatsheetLabelX, y, html.EscapeString(label))
+	}
+
+	height := y + cheatsheetMargin
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="monospace" font-size="14">
+<rect width="100%%" height="100%%" rx="16" fill="#1c1b1f"/>
+%s</svg>
+`, cheatsheetWidth, height, rows.String())
+}
+
+// writeCheatsheet refreshes the cheat sheet after a compositor config is
+// deployed. It only helps new users, so failing is a warning
+func (cd *ConfigDeployer) writeCheatsheet(compositor, content, source string) {
+	if err := WriteCheatsheet(compositor, content, source); err != nil {
+		cd.log(fmt.Sprintf("Warning: Failed to write keybinding cheat sheet: %v", err))
+		return
+	}
+	cd.log(fmt.Sprintf("Wrote keybinding cheat sheet to %s", CheatsheetPath()))
+}
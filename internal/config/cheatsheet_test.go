@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNiriKeybinds(t *testing.T) {
+	binds, err := NiriKeybinds(`binds {
+    // === Application Launchers ===
+    Mod+T hotkey-overlay-title="Open Terminal" { spawn "foot"; }
+    Mod+Space {
+        spawn "dms" "ipc" "call" "spotlight" "toggle";
+    }
+    /-Mod+X { quit; }
+
+    // === Window Management ===
+    Mod+Q { close-window; }
+}
+`)
+	require.NoError(t, err)
+	assert.Equal(t, []Keybind{
+		{Section: "Application Launchers", Combo: "Mod+T", Action: "spawn foot", Description: "Open Terminal"},
+		{Section: "Application Launchers", Combo: "Mod+Space", Action: "spawn dms ipc call spotlight toggle"},
+		{Section: "Window Management", Combo: "Mod+Q", Action: "close-window"},
+	}, binds)
+}
+
+func TestNiriKeybindsTemplate(t *testing.T) {
+	binds, err := NiriKeybinds(NiriConfig)
+	require.NoError(t, err)
+	require.NotEmpty(t, binds)
+	for _, bind := range binds {
+		assert.NotEmpty(t, bind.Action, bind.Combo)
+	}
+}
+
+func TestHyprlandKeybinds(t *testing.T) {
+	binds := HyprlandKeybinds(`$mod = SUPER
+$term = foot
+
+# === Launchers ===
+bind = $mod, T, exec, $term
+bindd = $mod SHIFT, E, Log out, exit
+bindel = , XF86AudioRaiseVolume, exec, dms ipc call audio increment 3
+# bind = $mod, X, exec, commented
+`)
+	assert.Equal(t, []Keybind{
+		{Section: "Launchers", Combo: "SUPER+T", Action: "exec foot"},
+		{Section: "Launchers", Combo: "SUPER+SHIFT+E", Action: "exit", Description: "Log out"},
+		{Section: "Launchers", Combo: "XF86AudioRaiseVolume", Action: "exec dms ipc call audio increment 3"},
+	}, binds)
+}
+
+func TestWriteCheatsheet(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	require.NoError(t, WriteCheatsheet("hyprland", "# === Windows ===\nbind = SUPER, Q, killactive\nbindd = SUPER, P, Pipe | split, pseudo\n", "hyprland.conf"))
+
+	markdown, err := os.ReadFile(CheatsheetPath())
+	require.NoError(t, err)
+	assert.Contains(t, string(markdown), "## Windows")
+	assert.Contains(t, string(markdown), "| `SUPER+Q` | killactive |")
+	assert.Contains(t, string(markdown), `Pipe \| split`)
+
+	image, err := os.ReadFile(filepath.Join(filepath.Dir(CheatsheetPath()), "keybinds.svg"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(image), "<svg"))
+
+	assert.Error(t, WriteCheatsheet("hyprland", "$mod = SUPER\n", "hyprland.conf"))
+}
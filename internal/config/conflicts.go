@@ -0,0 +1,327 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/AvengeMedia/danklinux/internal/kdl"
+)
+
+// ConflictSection is a part of a compositor config that users customize
+// and the DMS template also fills in
+type ConflictSection string
+
+const (
+	ConflictBinds ConflictSection = "binds"
+	// ConflictStartup is exec-once in Hyprland and spawn-at-startup in niri
+	ConflictStartup ConflictSection = "exec-once"
+	// ConflictEnv is env in Hyprland and the environment block in niri
+	ConflictEnv ConflictSection = "env"
+)
+
+var conflictSections = []ConflictSection{ConflictBinds, ConflictStartup, ConflictEnv}
+
+// ConflictChoice is how a conflicting section is resolved when the config
+// is replaced
+type ConflictChoice int
+
+const (
+	// ConflictUseDMS deploys the template's entries, as a plain replace does
+	ConflictUseDMS ConflictChoice = iota
+	// ConflictKeepMine deploys the previous config's entries in their place
+	ConflictKeepMine
+	// ConflictCommentBoth deploys both sets commented out, to merge by hand
+	ConflictCommentBoth
+)
+
+func (c ConflictChoice) String() string {
+	switch c {
+	case ConflictKeepMine:
+		return "Keep mine"
+	case ConflictCommentBoth:
+		return "Comment both"
+	default:
+		return "Use DMS"
+	}
+}
+
+// ConfigConflict is a section of an existing config with entries the
+// template doesn't have
+type ConfigConflict struct {
+	Section ConflictSection
+	// Mine are the previous config's entries missing from the template
+	Mine []string
+	// Total counts every entry of the section in the previous config
+	Total int
+}
+
+const keptComment = "=== Kept from your previous config ==="
+const commentedComment = "=== From your previous config, commented out by the installer ==="
+
+// DetectConfigConflicts compares an existing niri or Hyprland config with
+// the template the installer would deploy. Sections the user left alone
+// aren't conflicts
+func DetectConfigConflicts(wm deps.WindowManager, terminal deps.Terminal, existing string) ([]ConfigConflict, error) {
+	cd := NewConfigDeployer(nil)
+	switch wm {
+	case deps.WindowManagerNiri:
+		return niriConflicts(cd.renderTemplate(NiriConfig, terminal), existing)
+	case deps.WindowManagerHyprland:
+		return hyprlandConflicts(cd.renderTemplate(HyprlandConfig, terminal), existing), nil
+	}
+	return nil, nil
+}
+
+// SetConflictChoices resolves the sections DetectConfigConflicts found when
+// the compositor config is replaced. Sections without a choice use DMS
+func (cd *ConfigDeployer) SetConflictChoices(choices map[ConflictSection]ConflictChoice) {
+	cd.conflictChoices = choices
+}
+
+func (cd *ConfigDeployer) logConflictChoices() {
+	for _, section := range conflictSections {
+		if choice, ok := cd.conflictChoices[section]; ok {
+			cd.log(fmt.Sprintf("Resolved %s: %s", section, choice))
+		}
+	}
+}
+
+func conflictsFrom(template, existing map[ConflictSection][]string) []ConfigConflict {
+	var conflicts []ConfigConflict
+	for _, section := range conflictSections {
+		known := make(map[string]bool)
+		for _, entry := range template[section] {
+			known[conflictKey(entry)] = true
+		}
+		conflict := ConfigConflict{Section: section, Total: len(existing[section])}
+		for _, entry := range existing[section] {
+			if !known[conflictKey(entry)] {
+				conflict.Mine = append(conflict.Mine, entry)
+			}
+		}
+		if len(conflict.Mine) > 0 {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	return conflicts
+}
+
+// conflictKey ignores spacing, so "bind=SUPER,T" matches "bind = SUPER, T"
+func conflictKey(entry string) string {
+	return strings.Join(strings.Fields(entry), "")
+}
+
+var hyprlandConflictRegex = map[ConflictSection]*regexp.Regexp{
+	ConflictBinds:   regexp.MustCompile(`^\s*bind[a-z]*\s*=`),
+	ConflictStartup: regexp.MustCompile(`^\s*exec-once\s*=`),
+	ConflictEnv:     regexp.MustCompile(`^\s*env\s*=`),
+}
+
+func hyprlandSectionLines(content string) map[ConflictSection][]string {
+	lines := make(map[ConflictSection][]string)
+	for _, line := range strings.Split(content, "\n") {
+		for section, re := range hyprlandConflictRegex {
+			if re.MatchString(line) {
+				lines[section] = append(lines[section], strings.TrimSpace(line))
+			}
+		}
+	}
+	return lines
+}
+
+func hyprlandConflicts(template, existing string) []ConfigConflict {
+	return conflictsFrom(hyprlandSectionLines(template), hyprlandSectionLines(existing))
+}
+
+// resolveHyprlandConflicts swaps the template's lines of each section for
+// the previous config's, or comments both out. Variables the kept lines use
+// and the template lacks are carried over with them
+func resolveHyprlandConflicts(config, existing string, choices map[ConflictSection]ConflictChoice) string {
+	mine := hyprlandSectionLines(existing)
+	vars := parseHyprlandVariables(config)
+	userVars := parseHyprlandVariables(existing)
+
+	for _, section := range conflictSections {
+		choice := choices[section]
+		if choice == ConflictUseDMS || len(mine[section]) == 0 {
+			continue
+		}
+		re := hyprlandConflictRegex[section]
+
+		var block []string
+		switch choice {
+		case ConflictKeepMine:
+			block = append(block, "# "+keptComment)
+			for _, name := range referencedHyprlandVariables(mine[section], userVars) {
+				if _, ok := vars[name]; !ok {
+					block = append(block, fmt.Sprintf("$%s = %s", name, userVars[name]))
+					vars[name] = userVars[name]
+				}
+			}
+			block = append(block, mine[section]...)
+		case ConflictCommentBoth:
+			block = append(block, "# "+commentedComment)
+			for _, line := range mine[section] {
+				block = append(block, "# "+line)
+			}
+		}
+
+		var out []string
+		placed := false
+		for _, line := range strings.Split(config, "\n") {
+			if !re.MatchString(line) {
+				out = append(out, line)
+				continue
+			}
+			if choice == ConflictCommentBoth {
+				out = append(out, "# "+strings.TrimSpace(line))
+				continue
+			}
+			if !placed {
+				out = append(out, block...)
+				placed = true
+			}
+		}
+		if choice == ConflictCommentBoth || !placed {
+			out = append(out, "", strings.Join(block, "\n"))
+		}
+		config = strings.Join(out, "\n")
+	}
+	return config
+}
+
+func referencedHyprlandVariables(lines []string, vars map[string]string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for name := range vars {
+		for _, line := range lines {
+			if strings.Contains(line, "$"+name) && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// niriStartupNodes are the top level nodes that make up the startup section
+var niriStartupNodes = map[string]bool{"spawn-at-startup": true, "spawn-sh-at-startup": true}
+
+// niriSectionNodes returns the enabled nodes of each section
+func niriSectionNodes(doc *kdl.Document) map[ConflictSection][]*kdl.Node {
+	nodes := make(map[ConflictSection][]*kdl.Node)
+	for section, block := range map[ConflictSection]string{ConflictBinds: "binds", ConflictEnv: "environment"} {
+		if parent := doc.Find(block); parent != nil {
+			for _, child := range parent.Children {
+				if !child.Disabled {
+					nodes[section] = append(nodes[section], child)
+				}
+			}
+		}
+	}
+	for _, child := range doc.Root.Children {
+		if niriStartupNodes[child.Name] && !child.Disabled {
+			nodes[ConflictStartup] = append(nodes[ConflictStartup], child)
+		}
+	}
+	return nodes
+}
+
+func niriSectionText(doc *kdl.Document) map[ConflictSection][]string {
+	text := make(map[ConflictSection][]string)
+	for section, nodes := range niriSectionNodes(doc) {
+		for _, node := range nodes {
+			text[section] = append(text[section], niriNodeText(node))
+		}
+	}
+	return text
+}
+
+// niriNodeText is a one line rendering of a node and its children
+func niriNodeText(n *kdl.Node) string {
+	parts := []string{n.Name}
+	for _, entry := range n.Entries {
+		if entry.Disabled {
+			continue
+		}
+		if entry.Key != "" {
+			parts = append(parts, entry.Key+"="+entry.Value.Raw)
+		} else {
+			parts = append(parts, entry.Value.Raw)
+		}
+	}
+	var children []string
+	for _, child := range n.Children {
+		if !child.Disabled {
+			children = append(children, niriNodeText(child)+";")
+		}
+	}
+	if len(children) > 0 {
+		parts = append(parts, "{ "+strings.Join(children, " ")+" }")
+	}
+	return strings.Join(parts, " ")
+}
+
+func niriConflicts(template, existing string) ([]ConfigConflict, error) {
+	templateDoc, err := kdl.Parse(template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse niri template: %w", err)
+	}
+	existingDoc, err := kdl.Parse(existing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse existing niri config: %w", err)
+	}
+	return conflictsFrom(niriSectionText(templateDoc), niriSectionText(existingDoc)), nil
+}
+
+// resolveNiriConflicts moves the previous config's nodes of each section
+// into doc in place of the template's, or disables both with /-
+func resolveNiriConflicts(doc, existing *kdl.Document, choices map[ConflictSection]ConflictChoice) {
+	ours := niriSectionNodes(doc)
+	mine := niriSectionNodes(existing)
+
+	for _, section := range conflictSections {
+		choice := choices[section]
+		if choice == ConflictUseDMS || len(mine[section]) == 0 {
+			continue
+		}
+
+		parent := doc.Root
+		switch section {
+		case ConflictBinds:
+			parent = doc.Ensure("binds")
+		case ConflictEnv:
+			parent = doc.Ensure("environment")
+		}
+
+		index := len(parent.Children)
+		for i, node := range ours[section] {
+			if i == 0 {
+				index = parent.Index(node)
+			}
+			if choice == ConflictCommentBoth {
+				node.Disabled = true
+				index = parent.Index(node) + 1
+			} else {
+				parent.Remove(node)
+			}
+		}
+
+		comment := "// " + keptComment
+		if choice == ConflictCommentBoth {
+			comment = "// " + commentedComment
+		}
+		for i, node := range mine[section] {
+			node.Disabled = choice == ConflictCommentBoth
+			node.Comments = nil
+			if i == 0 {
+				node.Comments = []string{"", comment}
+			}
+			parent.Insert(index+i, node)
+		}
+	}
+}
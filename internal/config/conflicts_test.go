@@ -0,0 +1,110 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/AvengeMedia/danklinux/internal/kdl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const conflictHyprlandTemplate = `$mod = SUPER
+env = XCURSOR_SIZE,24
+exec-once = dms run
+bind = $mod, T, exec, ghostty
+bind = $mod, Q, killactive
+`
+
+const conflictHyprlandUser = `$mainMod = ALT
+env = XCURSOR_SIZE, 24
+exec-once=dms run
+exec-once = nm-applet
+bind = $mainMod, Return, exec, foot
+`
+
+func TestHyprlandConflicts(t *testing.T) {
+	conflicts := hyprlandConflicts(conflictHyprlandTemplate, conflictHyprlandUser)
+	assert.Equal(t, []ConfigConflict{
+		{Section: ConflictBinds, Mine: []string{"bind = $mainMod, Return, exec, foot"}, Total: 1},
+		{Section: ConflictStartup, Mine: []string{"exec-once = nm-applet"}, Total: 2},
+	}, conflicts, "spacing differences aren't conflicts")
+}
+
+func TestResolveHyprlandConflicts(t *testing.T) {
+	resolved := resolveHyprlandConflicts(conflictHyprlandTemplate, conflictHyprlandUser, map[ConflictSection]ConflictChoice{
+		ConflictBinds:   ConflictKeepMine,
+		ConflictStartup: ConflictCommentBoth,
+	})
+
+	assert.NotContains(t, resolved, "bind = $mod, T, exec, ghostty")
+	assert.Contains(t, resolved, "# "+keptComment+"\n$mainMod = ALT\nbind = $mainMod, Return, exec, foot\n")
+	assert.Contains(t, resolved, "\n# exec-once = dms run\n")
+	assert.Contains(t, resolved, "# "+commentedComment+"\n# exec-once=dms run\n# exec-once = nm-applet")
+	assert.Contains(t, resolved, "env = XCURSOR_SIZE,24", "sections without a choice use DMS")
+}
+
+const conflictNiriTemplate = `spawn-at-startup "dms" "run"
+environment {
+    QT_QPA_PLATFORM "wayland"
+}
+binds {
+    Mod+T { spawn "ghostty"; }
+    Mod+Q { close-window; }
+}
+`
+
+const conflictNiriUser = `spawn-at-startup "dms" "run"
+spawn-at-startup "waybar"
+binds {
+    Mod+Return { spawn "foot"; }
+    Mod+Q { close-window; }
+}
+`
+
+func TestNiriConflicts(t *testing.T) {
+	conflicts, err := niriConflicts(conflictNiriTemplate, conflictNiriUser)
+	require.NoError(t, err)
+	assert.Equal(t, []ConfigConflict{
+		{Section: ConflictBinds, Mine: []string{`Mod+Return { spawn "foot"; }`}, Total: 2},
+		{Section: ConflictStartup, Mine: []string{`spawn-at-startup "waybar"`}, Total: 2},
+	}, conflicts)
+}
+
+func TestResolveNiriConflicts(t *testing.T) {
+	doc, err := kdl.Parse(conflictNiriTemplate)
+	require.NoError(t, err)
+	existing, err := kdl.Parse(conflictNiriUser)
+	require.NoError(t, err)
+
+	resolveNiriConflicts(doc, existing, map[ConflictSection]ConflictChoice{
+		ConflictBinds:   ConflictKeepMine,
+		ConflictStartup: ConflictCommentBoth,
+	})
+	out := doc.String()
+
+	binds, err := NiriKeybinds(out)
+	require.NoError(t, err)
+	require.Len(t, binds, 2)
+	assert.Equal(t, "Mod+Return", binds[0].Combo)
+	assert.Equal(t, "Mod+Q", binds[1].Combo)
+
+	assert.Contains(t, out, `/-spawn-at-startup "dms" "run"`)
+	assert.Contains(t, out, `/-spawn-at-startup "waybar"`)
+	assert.Equal(t, 1, strings.Count(out, commentedComment))
+	assert.Contains(t, out, `QT_QPA_PLATFORM "wayland"`)
+}
+
+func TestDetectConfigConflictsTemplate(t *testing.T) {
+	cd := NewConfigDeployer(nil)
+	conflicts, err := DetectConfigConflicts(deps.WindowManagerNiri, deps.TerminalGhostty, cd.renderTemplate(NiriConfig, deps.TerminalGhostty))
+	require.NoError(t, err)
+	assert.Empty(t, conflicts, "an untouched DMS config has no conflicts")
+
+	conflicts, err = DetectConfigConflicts(deps.WindowManagerHyprland, deps.TerminalKitty, cd.renderTemplate(HyprlandConfig, deps.TerminalGhostty))
+	require.NoError(t, err)
+	require.Len(t, conflicts, 2, "the terminal bind and env differ")
+	assert.Equal(t, ConflictBinds, conflicts[0].Section)
+	assert.Equal(t, ConflictEnv, conflicts[1].Section)
+}
@@ -10,15 +10,21 @@ import (
 	"time"
 
 	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/AvengeMedia/danklinux/internal/kdl"
+	"github.com/AvengeMedia/danklinux/internal/utils"
 )
 
 type ConfigDeployer struct {
-	logChan chan<- string
+	logChan         chan<- string
+	migration       *MigrationSource
+	stagingDir      string
+	conflictChoices map[ConflictSection]ConflictChoice
 }
 
 type DeploymentResult struct {
 	ConfigType string
 	Path       string
+	StagedPath string
 	BackupPath string
 	Deployed   bool
 	Error      error
@@ -30,6 +36,34 @@ func NewConfigDeployer(logChan chan<- string) *ConfigDeployer {
 	}
 }
 
+// SetMigration imports the given pieces of an existing setup into the
+// deployed templates
+func (cd *ConfigDeployer) SetMigration(migration *MigrationSource) {
+	cd.migration = migration
+}
+
+// SetStagingDir writes generated configs into dir (e.g. a chezmoi or stow
+// source tree) instead of directly into the home directory
+func (cd *ConfigDeployer) SetStagingDir(dir string) {
+	cd.stagingDir = dir
+}
+
+// outputPath returns where a config is written, redirecting into the staging
+// directory when one is set
+func (cd *ConfigDeployer) outputPath(result *DeploymentResult) string {
+	if cd.stagingDir == "" {
+		return result.Path
+	}
+
+	rel, err := filepath.Rel(os.Getenv("HOME"), result.Path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = strings.TrimPrefix(result.Path, string(filepath.Separator))
+	}
+
+	result.StagedPath = filepath.Join(cd.stagingDir, rel)
+	return result.StagedPath
+}
+
 func (cd *ConfigDeployer) log(message string) {
 	if cd.logChan != nil {
 		cd.logChan <- message
@@ -99,6 +133,14 @@ func (cd *ConfigDeployer) DeployConfigurationsSelectiveWithReinstalls(ctx contex
 		}
 	}
 
+	if cd.migration != nil && cd.migration.Wallpaper != "" {
+		wallpaperResult, err := cd.importWallpaper()
+		results = append(results, wallpaperResult)
+		if err != nil {
+			cd.log(fmt.Sprintf("Warning: Failed to import wallpaper: %v", err))
+		}
+	}
+
 	return results, nil
 }
 
@@ -109,7 +151,8 @@ func (cd *ConfigDeployer) deployNiriConfig(terminal deps.Terminal) (DeploymentRe
 		Path:       filepath.Join(os.Getenv("HOME"), ".config", "niri", "config.kdl"),
 	}
 
-	configDir := filepath.Dir(result.Path)
+	outputPath := cd.outputPath(&result)
+	configDir := filepath.Dir(outputPath)
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		result.Error = fmt.Errorf("failed to create config directory: %w", err)
 		return result, result.Error
@@ -126,15 +169,59 @@ func (cd *ConfigDeployer) deployNiriConfig(terminal deps.Terminal) (DeploymentRe
 		}
 		existingConfig = string(existingData)
 
-		timestamp := time.Now().Format("2006-01-02_15-04-05")
-		result.BackupPath = result.Path + ".backup." + timestamp
-		if err := os.WriteFile(result.BackupPath, existingData, 0644); err != nil {
-			result.Error = fmt.Errorf("failed to create backup: %w", err)
-			return result, result.Error
+		if cd.stagingDir == "" {
+			timestamp := time.Now().Format("2006-01-02_15-04-05")
+			result.BackupPath = result.Path + ".backup." + timestamp
+			if err := utils.WriteFileAtomic(result.BackupPath, existingData, 0644); err != nil {
+				result.Error = fmt.Errorf("failed to create backup: %w", err)
+				return result, result.Error
+			}
+			cd.log(fmt.Sprintf("Backed up existing config to %s", result.BackupPath))
+		}
+	}
+
+	template := cd.renderTemplate(NiriConfig, terminal)
+
+	doc, err := kdl.Parse(template)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to parse niri template: %w", err)
+		return result, result.Error
+	}
+
+	// If there was an existing config, merge the output sections
+	if existingConfig != "" {
+		if existing, err := kdl.Parse(existingConfig); err != nil {
+			cd.log(fmt.Sprintf("Warning: Failed to merge output sections: %v", err))
+		} else if mergeNiriOutputs(doc, existing) {
+			cd.log("Successfully merged existing output sections")
+		}
+	}
+
+	cd.applyNiriMigrationDoc(doc)
+
+	if existingConfig != "" && len(cd.conflictChoices) > 0 {
+		if existing, err := kdl.Parse(existingConfig); err != nil {
+			cd.log(fmt.Sprintf("Warning: Failed to resolve conflicting sections: %v", err))
+		} else {
+			resolveNiriConflicts(doc, existing, cd.conflictChoices)
+			cd.logConflictChoices()
 		}
-		cd.log(fmt.Sprintf("Backed up existing config to %s", result.BackupPath))
 	}
 
+	if err := utils.WriteFileAtomic(outputPath, []byte(doc.String()), 0644); err != nil {
+		result.Error = fmt.Errorf("failed to write config: %w", err)
+		return result, result.Error
+	}
+
+	result.Deployed = true
+	cd.log("Successfully deployed Niri configuration")
+	cd.writeCheatsheet("niri", doc.String(), result.Path)
+	return result, nil
+}
+
+// renderTemplate fills in the polkit agent and terminal of a compositor
+// template
+func (cd *ConfigDeployer) renderTemplate(template string, terminal deps.Terminal) string {
 	// Detect polkit agent path
 	polkitPath, err := cd.detectPolkitAgent()
 	if err != nil {
@@ -155,28 +242,8 @@ func (cd *ConfigDeployer) deployNiriConfig(terminal deps.Terminal) (DeploymentRe
 		terminalCommand = "ghostty" // fallback to ghostty
 	}
 
-	newConfig := strings.ReplaceAll(NiriConfig, "{{POLKIT_AGENT_PATH}}", polkitPath)
-	newConfig = strings.ReplaceAll(newConfig, "{{TERMINAL_COMMAND}}", terminalCommand)
-
-	// If there was an existing config, merge the output sections
-	if existingConfig != "" {
-		mergedConfig, err := cd.mergeNiriOutputSections(newConfig, existingConfig)
-		if err != nil {
-			cd.log(fmt.Sprintf("Warning: Failed to merge output sections: %v", err))
-		} else {
-			newConfig = mergedConfig
-			cd.log("Successfully merged existing output sections")
-		}
-	}
-
-	if err := os.WriteFile(result.Path, []byte(newConfig), 0644); err != nil {
-		result.Error = fmt.Errorf("failed to write config: %w", err)
-		return result, result.Error
-	}
-
-	result.Deployed = true
-	cd.log("Successfully deployed Niri configuration")
-	return result, nil
+	template = strings.ReplaceAll(template, "{{POLKIT_AGENT_PATH}}", polkitPath)
+	return strings.ReplaceAll(template, "{{TERMINAL_COMMAND}}", terminalCommand)
 }
 
 // deployGhosttyConfig handles Ghostty configuration deployment with backup
@@ -186,7 +253,8 @@ func (cd *ConfigDeployer) deployGhosttyConfig() (DeploymentResult, error) {
 		Path:       filepath.Join(os.Getenv("HOME"), ".config", "ghostty", "config"),
 	}
 
-	configDir := filepath.Dir(result.Path)
+	outputPath := cd.outputPath(&result)
+	configDir := filepath.Dir(outputPath)
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		result.Error = fmt.Errorf("failed to create config directory: %w", err)
 		return result, result.Error
@@ -201,16 +269,18 @@ func (cd *ConfigDeployer) deployGhosttyConfig() (DeploymentResult, error) {
 			return result, result.Error
 		}
 
-		timestamp := time.Now().Format("2006-01-02_15-04-05")
-		result.BackupPath = result.Path + ".backup." + timestamp
-		if err := os.WriteFile(result.BackupPath, existingData, 0644); err != nil {
-			result.Error = fmt.Errorf("failed to create backup: %w", err)
-			return result, result.Error
+		if cd.stagingDir == "" {
+			timestamp := time.Now().Format("2006-01-02_15-04-05")
+			result.BackupPath = result.Path + ".backup." + timestamp
+			if err := utils.WriteFileAtomic(result.BackupPath, existingData, 0644); err != nil {
+				result.Error = fmt.Errorf("failed to create backup: %w", err)
+				return result, result.Error
+			}
+			cd.log(fmt.Sprintf("Backed up existing config to %s", result.BackupPath))
 		}
-		cd.log(fmt.Sprintf("Backed up existing config to %s", result.BackupPath))
 	}
 
-	if err := os.WriteFile(result.Path, []byte(GhosttyConfig), 0644); err != nil {
+	if err := utils.WriteFileAtomic(outputPath, []byte(GhosttyConfig), 0644); err != nil {
 		result.Error = fmt.Errorf("failed to write config: %w", err)
 		return result, result.Error
 	}
@@ -227,7 +297,8 @@ func (cd *ConfigDeployer) deployKittyConfig() (DeploymentResult, error) {
 		Path:       filepath.Join(os.Getenv("HOME"), ".config", "kitty", "kitty.conf"),
 	}
 
-	configDir := filepath.Dir(result.Path)
+	outputPath := cd.outputPath(&result)
+	configDir := filepath.Dir(outputPath)
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		result.Error = fmt.Errorf("failed to create config directory: %w", err)
 		return result, result.Error
@@ -242,16 +313,18 @@ func (cd *ConfigDeployer) deployKittyConfig() (DeploymentResult, error) {
 			return result, result.Error
 		}
 
-		timestamp := time.Now().Format("2006-01-02_15-04-05")
-		result.BackupPath = result.Path + ".backup." + timestamp
-		if err := os.WriteFile(result.BackupPath, existingData, 0644); err != nil {
-			result.Error = fmt.Errorf("failed to create backup: %w", err)
-			return result, result.Error
+		if cd.stagingDir == "" {
+			timestamp := time.Now().Format("2006-01-02_15-04-05")
+			result.BackupPath = result.Path + ".backup." + timestamp
+			if err := utils.WriteFileAtomic(result.BackupPath, existingData, 0644); err != nil {
+				result.Error = fmt.Errorf("failed to create backup: %w", err)
+				return result, result.Error
+			}
+			cd.log(fmt.Sprintf("Backed up existing config to %s", result.BackupPath))
 		}
-		cd.log(fmt.Sprintf("Backed up existing config to %s", result.BackupPath))
 	}
 
-	if err := os.WriteFile(result.Path, []byte(KittyConfig), 0644); err != nil {
+	if err := utils.WriteFileAtomic(outputPath, []byte(KittyConfig), 0644); err != nil {
 		result.Error = fmt.Errorf("failed to write config: %w", err)
 		return result, result.Error
 	}
@@ -296,47 +369,6 @@ func (cd *ConfigDeployer) detectPolkitAgent() (string, error) {
 }
 
 // mergeNiriOutputSections extracts output sections from existing config and merges them into the new config
-func (cd *ConfigDeployer) mergeNiriOutputSections(newConfig, existingConfig string) (string, error) {
-	// Regular expression to match output sections (including commented ones)
-	outputRegex := regexp.MustCompile(`(?m)^(/-)?\s*output\s+"[^"]+"\s*\{[^{}]*(?:\{[^{}]*\}[^{}]*)*\}`)
-
-	// Find all output sections in the existing config
-	existingOutputs := outputRegex.FindAllString(existingConfig, -1)
-
-	if len(existingOutputs) == 0 {
-		// No output sections to merge
-		return newConfig, nil
-	}
-
-	// Remove the example output section from the new config
-	exampleOutputRegex := regexp.MustCompile(`(?m)^/-output "eDP-2" \{[^{}]*(?:\{[^{}]*\}[^{}]*)*\}`)
-	mergedConfig := exampleOutputRegex.ReplaceAllString(newConfig, "")
-
-	// Find where to insert the output sections (after the input section)
-	inputEndRegex := regexp.MustCompile(`(?m)^}$`)
-	inputMatches := inputEndRegex.FindAllStringIndex(newConfig, -1)
-
-	if len(inputMatches) < 1 {
-		return "", fmt.Errorf("could not find insertion point for output sections")
-	}
-
-	// Insert after the first closing brace (end of input section)
-	insertPos := inputMatches[0][1]
-
-	var builder strings.Builder
-	builder.WriteString(mergedConfig[:insertPos])
-	builder.WriteString("\n// Outputs from existing configuration\n")
-
-	for _, output := range existingOutputs {
-		builder.WriteString(output)
-		builder.WriteString("\n")
-	}
-
-	builder.WriteString(mergedConfig[insertPos:])
-
-	return builder.String(), nil
-}
-
 // deployHyprlandConfig handles Hyprland configuration deployment with backup and merging
 func (cd *ConfigDeployer) deployHyprlandConfig(terminal deps.Terminal) (DeploymentResult, error) {
 	result := DeploymentResult{
@@ -344,7 +376,8 @@ func (cd *ConfigDeployer) deployHyprlandConfig(terminal deps.Terminal) (Deployme
 		Path:       filepath.Join(os.Getenv("HOME"), ".config", "hypr", "hyprland.conf"),
 	}
 
-	configDir := filepath.Dir(result.Path)
+	outputPath := cd.outputPath(&result)
+	configDir := filepath.Dir(outputPath)
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		result.Error = fmt.Errorf("failed to create config directory: %w", err)
 		return result, result.Error
@@ -361,37 +394,18 @@ func (cd *ConfigDeployer) deployHyprlandConfig(terminal deps.Terminal) (Deployme
 		}
 		existingConfig = string(existingData)
 
-		timestamp := time.Now().Format("2006-01-02_15-04-05")
-		result.BackupPath = result.Path + ".backup." + timestamp
-		if err := os.WriteFile(result.BackupPath, existingData, 0644); err != nil {
-			result.Error = fmt.Errorf("failed to create backup: %w", err)
-			return result, result.Error
+		if cd.stagingDir == "" {
+			timestamp := time.Now().Format("2006-01-02_15-04-05")
+			result.BackupPath = result.Path + ".backup." + timestamp
+			if err := utils.WriteFileAtomic(result.BackupPath, existingData, 0644); err != nil {
+				result.Error = fmt.Errorf("failed to create backup: %w", err)
+				return result, result.Error
+			}
+			cd.log(fmt.Sprintf("Backed up existing config to %s", result.BackupPath))
 		}
-		cd.log(fmt.Sprintf("Backed up existing config to %s", result.BackupPath))
 	}
 
-	// Detect polkit agent path
-	polkitPath, err := cd.detectPolkitAgent()
-	if err != nil {
-		cd.log(fmt.Sprintf("Warning: Could not detect polkit agent: %v", err))
-		polkitPath = "/usr/lib/mate-polkit/polkit-mate-authentication-agent-1" // fallback
-	}
-
-	// Determine terminal command based on choice
-	var terminalCommand string
-	switch terminal {
-	case deps.TerminalGhostty:
-		terminalCommand = "ghostty"
-	case deps.TerminalKitty:
-		terminalCommand = "kitty"
-	case deps.TerminalAlacritty:
-		terminalCommand = "alacritty"
-	default:
-		terminalCommand = "ghostty" // fallback to ghostty
-	}
-
-	newConfig := strings.ReplaceAll(HyprlandConfig, "{{POLKIT_AGENT_PATH}}", polkitPath)
-	newConfig = strings.ReplaceAll(newConfig, "{{TERMINAL_COMMAND}}", terminalCommand)
+	newConfig := cd.renderTemplate(HyprlandConfig, terminal)
 
 	// If there was an existing config, merge the monitor sections
 	if existingConfig != "" {
@@ -404,13 +418,40 @@ func (cd *ConfigDeployer) deployHyprlandConfig(terminal deps.Terminal) (Deployme
 		}
 	}
 
-	if err := os.WriteFile(result.Path, []byte(newConfig), 0644); err != nil {
+	newConfig = cd.applyHyprlandMigration(newConfig)
+
+	if existingConfig != "" && len(cd.conflictChoices) > 0 {
+		newConfig = resolveHyprlandConflicts(newConfig, existingConfig, cd.conflictChoices)
+		cd.logConflictChoices()
+	}
+
+	if hyprlandVersion, err := detectHyprlandVersion(); err != nil {
+		cd.log(fmt.Sprintf("Could not detect Hyprland version, deploying the default template: %v", err))
+	} else {
+		cd.log(fmt.Sprintf("Detected Hyprland %s", hyprlandVersion))
+		var applied []string
+		newConfig, applied = PatchHyprlandConfig(newConfig, hyprlandVersion)
+		for _, change := range applied {
+			cd.log(fmt.Sprintf("Adjusted for Hyprland %s: %s", hyprlandVersion, change))
+		}
+		for _, warning := range HyprlandDeprecations(newConfig, hyprlandVersion) {
+			cd.log(fmt.Sprintf("Warning: deprecated Hyprland option: %s", warning))
+		}
+		if existingConfig != "" {
+			for _, warning := range HyprlandDeprecations(existingConfig, hyprlandVersion) {
+				cd.log(fmt.Sprintf("Warning: previous config uses a deprecated Hyprland option: %s", warning))
+			}
+		}
+	}
+
+	if err := utils.WriteFileAtomic(outputPath, []byte(newConfig), 0644); err != nil {
 		result.Error = fmt.Errorf("failed to write config: %w", err)
 		return result, result.Error
 	}
 
 	result.Deployed = true
 	cd.log("Successfully deployed Hyprland configuration")
+	cd.writeCheatsheet("hyprland", newConfig, result.Path)
 	return result, nil
 }
 
@@ -473,6 +473,38 @@ general {
 	})
 }
 
+func TestStagingDirDeployment(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "dankinstall-staging-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tempDir)
+	defer os.Setenv("HOME", originalHome)
+
+	existingContent := "font-size = 14\n"
+	ghosttyPath := filepath.Join(tempDir, ".config", "ghostty", "config")
+	require.NoError(t, os.MkdirAll(filepath.Dir(ghosttyPath), 0755))
+	require.NoError(t, os.WriteFile(ghosttyPath, []byte(existingContent), 0644))
+
+	stagingDir := filepath.Join(tempDir, "dotfiles")
+	cd := NewConfigDeployer(make(chan string, 100))
+	cd.SetStagingDir(stagingDir)
+
+	result, err := cd.deployGhosttyConfig()
+	require.NoError(t, err)
+
+	assert.True(t, result.Deployed)
+	assert.Equal(t, ghosttyPath, result.Path)
+	assert.Equal(t, filepath.Join(stagingDir, ".config", "ghostty", "config"), result.StagedPath)
+	assert.Empty(t, result.BackupPath)
+	assert.FileExists(t, result.StagedPath)
+
+	content, err := os.ReadFile(ghosttyPath)
+	require.NoError(t, err)
+	assert.Equal(t, existingContent, string(content))
+}
+
 func TestNiriConfigStructure(t *testing.T) {
 	// Verify the embedded Niri config has expected sections
 	assert.Contains(t, NiriConfig, "input {")
@@ -147,10 +147,16 @@ bind = $mod, Y, exec, dms ipc call dankdash wallpaper
 bind = $mod, TAB, exec, dms ipc call hypr toggleOverview
 
 # === Security ===
-bind = $mod ALT, L, exec, dms ipc call lock lock
+bind = $mod ALT, L, exec, dms lock
+bindl = , switch:on:Lid Switch, exec, dms lock --now
 bind = $mod SHIFT, E, exit
 bind = CTRL ALT, Delete, exec, dms ipc call processlist toggle
 
+# === Magnifier ===
+binde = $mod ALT, equal, exec, dms ipc zoom in
+binde = $mod ALT, minus, exec, dms ipc zoom out
+bind = $mod ALT, 0, exec, dms ipc zoom toggle
+
 # === Audio Controls ===
 bindel = , XF86AudioRaiseVolume, exec, dms ipc call audio increment 3
 bindel = , XF86AudioLowerVolume, exec, dms ipc call audio decrement 3
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/version"
+)
+
+var hyprlandVersionPattern = regexp.MustCompile(`v?(\d+\.\d+\.\d+)`)
+
+// detectHyprlandVersion asks the running compositor first and falls back to
+// the installed binary, which is what the installer has before first launch
+var detectHyprlandVersion = func() (string, error) {
+	if output, err := exec.Command("hyprctl", "version", "-j").Output(); err == nil {
+		var info struct {
+			Tag string `json:"tag"`
+		}
+		if json.Unmarshal(output, &info) == nil {
+			if match := hyprlandVersionPattern.FindStringSubmatch(info.Tag); match != nil {
+				return match[1], nil
+			}
+		}
+	}
+
+	output, err := exec.Command("Hyprland", "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("hyprland not found: %w", err)
+	}
+	return parseHyprlandVersion(string(output))
+}
+
+func parseHyprlandVersion(output string) (string, error) {
+	// "Hyprland 0.45.2 built from branch ..." or "... Tag: v0.45.2, ..."
+	if i := strings.Index(output, "Tag:"); i >= 0 {
+		output = output[i:]
+	}
+	match := hyprlandVersionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return "", fmt.Errorf("could not find a version in %q", strings.TrimSpace(output))
+	}
+	return match[1], nil
+}
+
+// hyprlandPatch rewrites the template for versions before or from Version
+type hyprlandPatch struct {
+	Version     string
+	Before      bool
+	Description string
+	Apply       func(string) string
+}
+
+var legacyShadowBlock = regexp.MustCompile(`(?m)^([ \t]*)shadow \{\n\s*enabled = (\S+)\n\s*range = (\S+)\n\s*render_power = (\S+)\n\s*offset = ([^\n]+)\n\s*color = (\S+)\n\s*\}`)
+
+var windowRuleV2 = regexp.MustCompile(`(?m)^windowrulev2 =`)
+
+var hyprlandPatches = []hyprlandPatch{
+	{
+		Version:     "0.45.0",
+		Before:      true,
+		Description: "decoration:shadow block converted to the pre-0.45 drop_shadow options",
+		Apply: func(config string) string {
+			return legacyShadowBlock.ReplaceAllString(config,
+				"${1}drop_shadow = ${2}\n${1}shadow_range = ${3}\n${1}shadow_render_power = ${4}\n${1}shadow_offset = ${5}\n${1}col.shadow = ${6}")
+		},
+	},
+	{
+		Version:     "0.48.0",
+		Description: "windowrulev2 renamed to windowrule",
+		Apply: func(config string) string {
+			return windowRuleV2.ReplaceAllString(config, "windowrule =")
+		},
+	},
+}
+
+type hyprlandDeprecation struct {
+	Pattern *regexp.Regexp
+	Version string
+	Message string
+}
+
+var hyprlandDeprecations = []hyprlandDeprecation{
+	{regexp.MustCompile(`(?m)^\s*(drop_shadow|shadow_range|shadow_render_power|col\.shadow)\s*=`), "0.45.0", "shadow options moved into decoration:shadow { }"},
+	{regexp.MustCompile(`(?m)^\s*windowrulev2\s*=`), "0.48.0", "windowrulev2 is deprecated, use windowrule"},
+	{regexp.MustCompile(`(?m)^\s*new_is_master\s*=`), "0.41.0", "master:new_is_master was replaced by master:new_status"},
+	{regexp.MustCompile(`(?m)^\s*no_gaps_when_only\s*=`), "0.45.0", "no_gaps_when_only was removed, use workspace rules"},
+	{regexp.MustCompile(`(?m)^\s*workspace_swipe\s*=`), "0.51.0", "gestures:workspace_swipe was replaced by gesture = 3, horizontal, workspace"},
+}
+
+// PatchHyprlandConfig adapts the template to hyprlandVersion and returns the
+// applied changes
+func PatchHyprlandConfig(config, hyprlandVersion string) (string, []string) {
+	var applied []string
+	for _, patch := range hyprlandPatches {
+		older := version.CompareVersions(hyprlandVersion, patch.Version) < 0
+		if older != patch.Before {
+			continue
+		}
+		if patched := patch.Apply(config); patched != config {
+			config = patched
+			applied = append(applied, patch.Description)
+		}
+	}
+	return config, applied
+}
+
+// HyprlandDeprecations lists options in config that hyprlandVersion no longer accepts
+func HyprlandDeprecations(config, hyprlandVersion string) []string {
+	var warnings []string
+	for _, deprecation := range hyprlandDeprecations {
+		if version.CompareVersions(hyprlandVersion, deprecation.Version) < 0 {
+			continue
+		}
+		if deprecation.Pattern.MatchString(config) {
+			warnings = append(warnings, fmt.Sprintf("%s (since %s)", deprecation.Message, deprecation.Version))
+		}
+	}
+	return warnings
+}
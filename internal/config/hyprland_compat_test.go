@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHyprlandVersion(t *testing.T) {
+	v, err := parseHyprlandVersion("Hyprland 0.45.2 built from branch  at commit 12f9a0d0b93f691d4d9923716557154d74777b0a  (version: bump to 0.45.2).\nTag: v0.45.2, commits: 5451\n")
+	require.NoError(t, err)
+	assert.Equal(t, "0.45.2", v)
+
+	v, err = parseHyprlandVersion("Hyprland 0.50.1 built from branch v0.50.1 at commit abc")
+	require.NoError(t, err)
+	assert.Equal(t, "0.50.1", v)
+
+	_, err = parseHyprlandVersion("command not found")
+	assert.Error(t, err)
+}
+
+func TestPatchHyprlandConfig(t *testing.T) {
+	old, applied := PatchHyprlandConfig(HyprlandConfig, "0.44.1")
+	assert.Len(t, applied, 1)
+	assert.Contains(t, old, "    drop_shadow = true\n    shadow_range = 30\n")
+	assert.Contains(t, old, "    col.shadow = rgba(00000070)")
+	assert.NotContains(t, old, "shadow {")
+	assert.Contains(t, old, "windowrulev2 = float")
+	assert.Empty(t, HyprlandDeprecations(old, "0.44.1"))
+
+	current, applied := PatchHyprlandConfig(HyprlandConfig, "0.46.2")
+	assert.Empty(t, applied)
+	assert.Equal(t, HyprlandConfig, current)
+
+	newer, applied := PatchHyprlandConfig(HyprlandConfig, "0.49.0")
+	assert.Len(t, applied, 1)
+	assert.Contains(t, newer, "windowrule = float, class:^(steam)$")
+	assert.NotContains(t, newer, "windowrulev2")
+	assert.Empty(t, HyprlandDeprecations(newer, "0.49.0"))
+}
+
+func TestHyprlandDeprecations(t *testing.T) {
+	config := "decoration {\n    drop_shadow = true\n}\ngestures {\n    workspace_swipe = true\n}\n"
+	assert.Empty(t, HyprlandDeprecations(config, "0.44.0"))
+	assert.Len(t, HyprlandDeprecations(config, "0.45.0"), 1)
+	assert.Len(t, HyprlandDeprecations(config, "0.51.0"), 2)
+}
+
+func TestHyprlandDeploymentPatchesForVersion(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	original := detectHyprlandVersion
+	detectHyprlandVersion = func() (string, error) { return "0.49.0", nil }
+	defer func() { detectHyprlandVersion = original }()
+
+	logChan := make(chan string, 100)
+	cd := NewConfigDeployer(logChan)
+
+	result, err := cd.deployHyprlandConfig(deps.TerminalGhostty)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(result.Path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "windowrule = noborder, class:^(kitty)$")
+	assert.Equal(t, filepath.Join(tempDir, ".config", "hypr", "hyprland.conf"), result.Path)
+}
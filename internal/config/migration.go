@@ -0,0 +1,547 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/AvengeMedia/danklinux/internal/kdl"
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+type SetupKind string
+
+const (
+	SetupHyprland SetupKind = "hyprland"
+	SetupWaybar   SetupKind = "waybar"
+	SetupAGS      SetupKind = "ags"
+	SetupEww      SetupKind = "eww"
+)
+
+type DetectedSetup struct {
+	Kind SetupKind
+	Path string
+}
+
+// HyprlandBind is an exec keybinding parsed from an existing Hyprland config
+type HyprlandBind struct {
+	Mods    []string
+	Key     string
+	Command string
+}
+
+// MigrationSource holds the pieces of an existing setup that can be imported
+// into the DMS templates instead of being overwritten
+type MigrationSource struct {
+	Setups    []DetectedSetup
+	Keybinds  []HyprlandBind
+	Monitors  []string
+	Wallpaper string
+	// NetworkBundle is a bundle made with dms ipc network export-bundle on
+	// the previous install
+	NetworkBundle string
+}
+
+// NetworkBundleName is the file name dms ipc network export-bundle suggests,
+// looked for in the home directory
+const NetworkBundleName = "dms-network.tar.gz"
+
+func (m *MigrationSource) HasImports() bool {
+	return m != nil && (len(m.Keybinds) > 0 || len(m.Monitors) > 0 || m.Wallpaper != "" || m.NetworkBundle != "")
+}
+
+// DetectMigrationSource looks for popular setups (waybar+hyprland dotfiles,
+// ags, eww) under homeDir and extracts keybinds, monitor layout and wallpaper
+func DetectMigrationSource(homeDir string) *MigrationSource {
+	source := &MigrationSource{}
+	configDir := filepath.Join(homeDir, ".config")
+
+	setupDirs := []struct {
+		kind SetupKind
+		path string
+	}{
+		{SetupWaybar, filepath.Join(configDir, "waybar")},
+		{SetupAGS, filepath.Join(configDir, "ags")},
+		{SetupEww, filepath.Join(configDir, "eww")},
+	}
+
+	for _, setup := range setupDirs {
+		if info, err := os.Stat(setup.path); err == nil && info.IsDir() {
+			source.Setups = append(source.Setups, DetectedSetup{Kind: setup.kind, Path: setup.path})
+		}
+	}
+
+	hyprDir := filepath.Join(configDir, "hypr")
+	hyprPath := filepath.Join(hyprDir, "hyprland.conf")
+	if data, err := os.ReadFile(hyprPath); err == nil {
+		content := string(data)
+		if !strings.Contains(content, "exec-once = dms run") {
+			source.Setups = append(source.Setups, DetectedSetup{Kind: SetupHyprland, Path: hyprPath})
+			source.Keybinds = parseHyprlandExecBinds(content)
+			source.Monitors = parseHyprlandMonitors(content)
+			source.Wallpaper = parseWallpaperFromExec(content)
+		}
+	}
+
+	if source.Wallpaper == "" {
+		if data, err := os.ReadFile(filepath.Join(hyprDir, "hyprpaper.conf")); err == nil {
+			source.Wallpaper = parseHyprpaperWallpaper(string(data))
+		}
+	}
+
+	if source.Wallpaper != "" {
+		source.Wallpaper = expandHome(source.Wallpaper, homeDir)
+		if _, err := os.Stat(source.Wallpaper); err != nil {
+			source.Wallpaper = ""
+		}
+	}
+
+	bundle := filepath.Join(homeDir, NetworkBundleName)
+	if info, err := os.Stat(bundle); err == nil && info.Mode().IsRegular() {
+		source.NetworkBundle = bundle
+	}
+
+	return source
+}
+
+func parseHyprlandVariables(content string) map[string]string {
+	vars := make(map[string]string)
+	varRegex := regexp.MustCompile(`(?m)^\s*\$(\w+)\s*=\s*(.+?)\s*$`)
+	for _, match := range varRegex.FindAllStringSubmatch(content, -1) {
+		vars[match[1]] = strings.TrimSpace(stripHyprlandComment(match[2]))
+	}
+	return vars
+}
+
+// stripHyprlandComment drops a trailing "# comment". Hyprland reads "##" as
+// a literal "#", so commands like "notify-send ##1" keep theirs
+func stripHyprlandComment(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '#' {
+			b.WriteByte(value[i])
+			continue
+		}
+		if i+1 < len(value) && value[i+1] == '#' {
+			b.WriteByte('#')
+			i++
+			continue
+		}
+		break
+	}
+	return b.String()
+}
+
+// expandHyprlandVariables substitutes the longest names first, as Hyprland
+// does, so $terminal isn't read as $term followed by "inal"
+func expandHyprlandVariables(value string, vars map[string]string) string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
+
+	for _, name := range names {
+		value = strings.ReplaceAll(value, "$"+name, vars[name])
+	}
+	return value
+}
+
+func parseHyprlandExecBinds(content string) []HyprlandBind {
+	vars := parseHyprlandVariables(content)
+	bindRegex := regexp.MustCompile(`(?m)^\s*bind[elmnr]*\s*=\s*(.+)$`)
+
+	var binds []HyprlandBind
+	for _, match := range bindRegex.FindAllStringSubmatch(content, -1) {
+		parts := strings.SplitN(stripHyprlandComment(match[1]), ",", 4)
+		if len(parts) < 4 || strings.TrimSpace(parts[2]) != "exec" {
+			continue
+		}
+
+		command := strings.TrimSpace(parts[3])
+		if command == "" || referencesReplacedBar(command) {
+			continue
+		}
+
+		binds = append(binds, HyprlandBind{
+			Mods:    splitHyprlandMods(expandHyprlandVariables(parts[0], vars)),
+			Key:     strings.TrimSpace(parts[1]),
+			Command: expandHyprlandVariables(command, vars),
+		})
+	}
+
+	return binds
+}
+
+func referencesReplacedBar(command string) bool {
+	for _, bar := range []string{"waybar", "ags", "eww"} {
+		for _, field := range strings.Fields(command) {
+			if filepath.Base(field) == bar {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parseHyprlandMonitors(content string) []string {
+	monitorRegex := regexp.MustCompile(`(?m)^\s*monitor\s*=.*$`)
+	var monitors []string
+	for _, line := range monitorRegex.FindAllString(content, -1) {
+		monitors = append(monitors, strings.TrimSpace(line))
+	}
+	return monitors
+}
+
+func parseWallpaperFromExec(content string) string {
+	patterns := []*regexp.Regexp{
+		regexp.MustCompile(`(?m)^\s*exec(?:-once)?\s*=.*\bswaybg\b.*\s-i\s+("[^"]+"|\S+)`),
+		regexp.MustCompile(`(?m)^\s*exec(?:-once)?\s*=.*\bswww\s+img\s+("[^"]+"|\S+)`),
+	}
+
+	for _, pattern := range patterns {
+		if match := pattern.FindStringSubmatch(content); match != nil {
+			return strings.Trim(match[1], `"`)
+		}
+	}
+	return ""
+}
+
+func parseHyprpaperWallpaper(content string) string {
+	wallpaperRegex := regexp.MustCompile(`(?m)^\s*wallpaper\s*=\s*(.+)$`)
+	match := wallpaperRegex.FindStringSubmatch(content)
+	if match == nil {
+		return ""
+	}
+
+	value := strings.TrimSpace(match[1])
+	if idx := strings.Index(value, ","); idx >= 0 {
+		value = strings.TrimSpace(value[idx+1:])
+	}
+	return value
+}
+
+func expandHome(path, homeDir string) string {
+	if path == "~" {
+		return homeDir
+	}
+	if strings.HasPrefix(path, "~/") {
+		return filepath.Join(homeDir, path[2:])
+	}
+	return path
+}
+
+// hyprlandModNames maps Hyprland modifier names to niri's
+var hyprlandModNames = map[string]string{
+	"SUPER":   "Mod",
+	"WIN":     "Mod",
+	"LOGO":    "Mod",
+	"MOD4":    "Mod",
+	"SHIFT":   "Shift",
+	"CTRL":    "Ctrl",
+	"CONTROL": "Ctrl",
+	"ALT":     "Alt",
+	"MOD1":    "Alt",
+}
+
+// splitHyprlandMods splits a modmask written as "SUPER SHIFT", "SUPER_SHIFT"
+// or "SUPERSHIFT" into modifier names. Tokens that aren't made of known
+// names are kept as written, and so is a modmask that names nothing, so the
+// bind isn't mistaken for one without modifiers
+func splitHyprlandMods(value string) []string {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	tokens := strings.FieldsFunc(value, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '_'
+	})
+
+	var mods []string
+	for _, token := range tokens {
+		if names, ok := splitConcatenatedMods(token); ok {
+			mods = append(mods, names...)
+		} else {
+			mods = append(mods, token)
+		}
+	}
+	if len(mods) == 0 && value != "" {
+		return []string{value}
+	}
+	return mods
+}
+
+// splitConcatenatedMods reads token as a run of modifier names. No name is
+// a prefix of another, so there is at most one way to split it
+func splitConcatenatedMods(token string) ([]string, bool) {
+	if token == "" {
+		return nil, true
+	}
+	for name := range hyprlandModNames {
+		if !strings.HasPrefix(token, name) {
+			continue
+		}
+		if rest, ok := splitConcatenatedMods(token[len(name):]); ok {
+			return append([]string{name}, rest...), true
+		}
+	}
+	return nil, false
+}
+
+func hyprlandModsToNiri(mods []string) ([]string, error) {
+	var result []string
+	for _, mod := range mods {
+		name, ok := hyprlandModNames[mod]
+		if !ok {
+			return nil, fmt.Errorf("unknown modifier %q", mod)
+		}
+		if !slices.Contains(result, name) {
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
+
+func hyprlandKeyToNiri(key string) string {
+	switch strings.ToLower(key) {
+	case "return", "enter":
+		return "Return"
+	case "space":
+		return "Space"
+	case "escape":
+		return "Escape"
+	case "tab":
+		return "Tab"
+	case "comma":
+		return "Comma"
+	case "period":
+		return "Period"
+	case "slash":
+		return "Slash"
+	case "minus":
+		return "Minus"
+	case "equal":
+		return "Equal"
+	}
+
+	if len(key) == 1 {
+		return strings.ToUpper(key)
+	}
+	return key
+}
+
+func (b HyprlandBind) niriCombo() (string, error) {
+	mods, err := hyprlandModsToNiri(b.Mods)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(append(mods, hyprlandKeyToNiri(b.Key)), "+"), nil
+}
+
+func (b HyprlandBind) hyprlandCombo() string {
+	return strings.Join(b.Mods, " ") + ", " + strings.ToUpper(b.Key)
+}
+
+// comboKey identifies the key combination regardless of how the modifiers
+// are written, so SUPER SHIFT, SHIFT SUPER and SHIFT WIN all match
+func (b HyprlandBind) comboKey() string {
+	mods := make([]string, len(b.Mods))
+	for i, mod := range b.Mods {
+		mod = strings.ToUpper(mod)
+		if name, ok := hyprlandModNames[mod]; ok {
+			mod = name
+		}
+		mods[i] = mod
+	}
+	sort.Strings(mods)
+	return strings.Join(mods, " ") + ", " + strings.ToUpper(b.Key)
+}
+
+func convertHyprlandMonitorToNiri(line string) (string, bool) {
+	value := strings.TrimSpace(line[strings.Index(line, "=")+1:])
+	parts := strings.Split(value, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	if len(parts) < 2 || parts[0] == "" {
+		return "", false
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "output \"%s\" {\n", parts[0])
+
+	if parts[1] == "disable" {
+		b.WriteString("    off\n}")
+		return b.String(), true
+	}
+
+	if parts[1] != "preferred" && parts[1] != "highres" && parts[1] != "highrr" {
+		fmt.Fprintf(&b, "    mode \"%s\"\n", parts[1])
+	}
+
+	if len(parts) > 2 {
+		var x, y int
+		if _, err := fmt.Sscanf(parts[2], "%dx%d", &x, &y); err == nil {
+			fmt.Fprintf(&b, "    position x=%d y=%d\n", x, y)
+		}
+	}
+
+	if len(parts) > 3 && parts[3] != "auto" {
+		fmt.Fprintf(&b, "    scale %s\n", parts[3])
+	}
+
+	b.WriteString("}")
+	return b.String(), true
+}
+
+func (cd *ConfigDeployer) applyNiriMigration(config string) string {
+	doc, err := kdl.Parse(config)
+	if err != nil {
+		cd.log(fmt.Sprintf("Warning: Could not parse niri config for migration: %v", err))
+		return config
+	}
+	if !cd.applyNiriMigrationDoc(doc) {
+		return config
+	}
+	return doc.String()
+}
+
+// applyNiriMigrationDoc imports the monitor layout and keybinds from the
+// migration source, reporting whether anything changed
+func (cd *ConfigDeployer) applyNiriMigrationDoc(doc *kdl.Document) bool {
+	if cd.migration == nil {
+		return false
+	}
+	changed := false
+
+	hasOutputs := false
+	for _, output := range doc.Root.ChildrenNamed("output") {
+		if !output.Disabled {
+			hasOutputs = true
+		}
+	}
+
+	if len(cd.migration.Monitors) > 0 && !hasOutputs {
+		var outputs []string
+		for _, monitor := range cd.migration.Monitors {
+			if output, ok := convertHyprlandMonitorToNiri(monitor); ok {
+				outputs = append(outputs, output)
+			}
+		}
+
+		if imported, err := kdl.Parse(strings.Join(outputs, "\n")); err != nil {
+			cd.log(fmt.Sprintf("Warning: Failed to import monitor layout: %v", err))
+		} else if mergeNiriOutputs(doc, imported) {
+			changed = true
+			cd.log(fmt.Sprintf("Imported %d monitor(s) from existing Hyprland configuration", len(outputs)))
+		}
+	}
+
+	if len(cd.migration.Keybinds) > 0 {
+		if count := importNiriKeybinds(doc, cd.migration.Keybinds, cd.log); count > 0 {
+			changed = true
+			cd.log(fmt.Sprintf("Imported %d keybind(s) from existing configuration", count))
+		}
+	}
+
+	return changed
+}
+
+func (cd *ConfigDeployer) applyHyprlandMigration(config string) string {
+	if cd.migration == nil || len(cd.migration.Keybinds) == 0 {
+		return config
+	}
+
+	existing := make(map[string]bool)
+	for _, combo := range parseHyprlandBindCombos(config) {
+		existing[combo] = true
+	}
+
+	var imported strings.Builder
+	imported.WriteString("\n\n# === Imported from existing configuration ===\n")
+	count := 0
+	for _, bind := range cd.migration.Keybinds {
+		if existing[bind.comboKey()] {
+			cd.log(fmt.Sprintf("Skipping imported keybind %s (conflicts with DMS default)", bind.hyprlandCombo()))
+			continue
+		}
+		fmt.Fprintf(&imported, "bind = %s, exec, %s\n", bind.hyprlandCombo(), bind.Command)
+		count++
+	}
+
+	if count == 0 {
+		return config
+	}
+
+	cd.log(fmt.Sprintf("Imported %d keybind(s) from existing configuration", count))
+	return strings.TrimRight(config, "\n") + strings.TrimRight(imported.String(), "\n") + "\n"
+}
+
+// parseHyprlandBindCombos returns the comboKey of every bind in content,
+// resolving modifier variables from content's own definitions
+func parseHyprlandBindCombos(content string) []string {
+	vars := parseHyprlandVariables(content)
+
+	bindRegex := regexp.MustCompile(`(?m)^\s*bind[elmnrd]*\s*=\s*(.+)$`)
+	var combos []string
+	for _, match := range bindRegex.FindAllStringSubmatch(content, -1) {
+		parts := strings.SplitN(stripHyprlandComment(match[1]), ",", 3)
+		if len(parts) < 2 {
+			continue
+		}
+		bind := HyprlandBind{
+			Mods: splitHyprlandMods(expandHyprlandVariables(parts[0], vars)),
+			Key:  strings.TrimSpace(parts[1]),
+		}
+		combos = append(combos, bind.comboKey())
+	}
+	return combos
+}
+
+// importWallpaper stores the detected wallpaper in the DMS session state
+func (cd *ConfigDeployer) importWallpaper() (DeploymentResult, error) {
+	result := DeploymentResult{
+		ConfigType: "Wallpaper",
+		Path:       filepath.Join(os.Getenv("HOME"), ".local", "state", "DankMaterialShell", "session.json"),
+	}
+
+	session := make(map[string]interface{})
+	if data, err := os.ReadFile(result.Path); err == nil {
+		if err := json.Unmarshal(data, &session); err != nil {
+			result.Error = fmt.Errorf("failed to parse session state: %w", err)
+			return result, result.Error
+		}
+	}
+
+	session["wallpaperPath"] = cd.migration.Wallpaper
+
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		result.Error = fmt.Errorf("failed to encode session state: %w", err)
+		return result, result.Error
+	}
+
+	outputPath := cd.outputPath(&result)
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		result.Error = fmt.Errorf("failed to create state directory: %w", err)
+		return result, result.Error
+	}
+
+	if err := utils.WriteFileAtomic(outputPath, data, 0644); err != nil {
+		result.Error = fmt.Errorf("failed to write session state: %w", err)
+		return result, result.Error
+	}
+
+	result.Deployed = true
+	cd.log(fmt.Sprintf("Imported wallpaper %s", cd.migration.Wallpaper))
+	return result, nil
+}
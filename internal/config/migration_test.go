@@ -0,0 +1,270 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/kdl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testHyprlandUserConfig = `$mainMod = SUPER
+$terminal = foot
+
+monitor = DP-1, 2560x1440@144, 0x0, 1
+monitor = HDMI-A-1, preferred, 2560x0, auto
+
+exec-once = waybar
+exec-once = swaybg -i ~/Pictures/wall.png
+
+bind = $mainMod, Return, exec, $terminal
+bind = $mainMod SHIFT, B, exec, pkill waybar
+bind = $mainMod, T, exec, kitty
+bind = $mainMod, E, exec, thunar
+bind = $mainMod, Q, killactive
+`
+
+func TestDetectMigrationSource(t *testing.T) {
+	homeDir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(homeDir, ".config", "waybar"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(homeDir, ".config", "hypr"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(homeDir, "Pictures"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(homeDir, ".config", "hypr", "hyprland.conf"), []byte(testHyprlandUserConfig), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(homeDir, "Pictures", "wall.png"), []byte{}, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(homeDir, NetworkBundleName), []byte{}, 0600))
+
+	source := DetectMigrationSource(homeDir)
+
+	kinds := make([]SetupKind, 0, len(source.Setups))
+	for _, setup := range source.Setups {
+		kinds = append(kinds, setup.Kind)
+	}
+	assert.Contains(t, kinds, SetupWaybar)
+	assert.Contains(t, kinds, SetupHyprland)
+	assert.NotContains(t, kinds, SetupAGS)
+
+	require.Len(t, source.Keybinds, 3)
+	assert.Equal(t, []string{"SUPER"}, source.Keybinds[0].Mods)
+	assert.Equal(t, "Return", source.Keybinds[0].Key)
+	assert.Equal(t, "foot", source.Keybinds[0].Command)
+
+	assert.Len(t, source.Monitors, 2)
+	assert.Equal(t, filepath.Join(homeDir, "Pictures", "wall.png"), source.Wallpaper)
+	assert.Equal(t, filepath.Join(homeDir, NetworkBundleName), source.NetworkBundle)
+	assert.True(t, source.HasImports())
+}
+
+func TestDetectMigrationSource_NetworkBundleOnly(t *testing.T) {
+	homeDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(homeDir, NetworkBundleName), []byte{}, 0600))
+
+	source := DetectMigrationSource(homeDir)
+	assert.Empty(t, source.Setups)
+	assert.True(t, source.HasImports())
+}
+
+func TestDetectMigrationSource_Empty(t *testing.T) {
+	source := DetectMigrationSource(t.TempDir())
+	assert.Empty(t, source.Setups)
+	assert.False(t, source.HasImports())
+}
+
+func TestParseHyprpaperWallpaper(t *testing.T) {
+	assert.Equal(t, "/tmp/a.png", parseHyprpaperWallpaper("preload = /tmp/a.png\nwallpaper = DP-1,/tmp/a.png\n"))
+	assert.Equal(t, "/tmp/b.png", parseHyprpaperWallpaper("wallpaper = ,/tmp/b.png\n"))
+	assert.Empty(t, parseHyprpaperWallpaper("preload = /tmp/a.png\n"))
+}
+
+func TestConvertHyprlandMonitorToNiri(t *testing.T) {
+	output, ok := convertHyprlandMonitorToNiri("monitor = DP-1, 2560x1440@144, 1920x0, 1.25")
+	require.True(t, ok)
+	assert.Contains(t, output, `output "DP-1" {`)
+	assert.Contains(t, output, `mode "2560x1440@144"`)
+	assert.Contains(t, output, "position x=1920 y=0")
+	assert.Contains(t, output, "scale 1.25")
+
+	output, ok = convertHyprlandMonitorToNiri("monitor = eDP-1, disable")
+	require.True(t, ok)
+	assert.Contains(t, output, "off")
+
+	_, ok = convertHyprlandMonitorToNiri("monitor = , preferred, auto, 1")
+	assert.False(t, ok)
+}
+
+func TestApplyNiriMigration(t *testing.T) {
+	cd := &ConfigDeployer{}
+	cd.SetMigration(&MigrationSource{
+		Keybinds: []HyprlandBind{
+			{Mods: []string{"SUPER"}, Key: "E", Command: "thunar"},
+			{Mods: []string{"SUPER"}, Key: "T", Command: "kitty"},
+		},
+		Monitors: []string{"monitor = DP-1, 2560x1440@144, 0x0, 1"},
+	})
+
+	result := cd.applyNiriMigration(NiriConfig)
+
+	assert.Contains(t, result, `Mod+E { spawn "sh" "-c" "thunar"; }`)
+	assert.NotContains(t, result, `"kitty"; }`)
+	assert.Contains(t, result, `output "DP-1" {`)
+	assert.True(t, strings.HasSuffix(strings.TrimSpace(result[:strings.Index(result, "debug {")]), "}"))
+}
+
+func TestApplyHyprlandMigration(t *testing.T) {
+	cd := &ConfigDeployer{}
+	cd.SetMigration(&MigrationSource{
+		Keybinds: []HyprlandBind{
+			{Mods: []string{"SUPER"}, Key: "E", Command: "thunar"},
+			{Mods: []string{"SUPER"}, Key: "T", Command: "kitty"},
+		},
+	})
+
+	result := cd.applyHyprlandMigration(HyprlandConfig)
+
+	assert.Contains(t, result, "bind = SUPER, E, exec, thunar")
+	assert.NotContains(t, result, "bind = SUPER, T, exec, kitty")
+}
+
+func TestParseHyprlandBindCombosMainMod(t *testing.T) {
+	combos := parseHyprlandBindCombos(`$mainMod = SUPER # the Windows key
+bind = $mainMod, Return, exec, foot
+bind = SHIFT $mainMod, Q, killactive
+bind = $mod, E, exec, thunar
+`)
+	assert.Equal(t, []string{"Mod, RETURN", "Mod Shift, Q", "$MOD, E"}, combos,
+		"variables come from the file only, and modifiers are compared sorted")
+}
+
+func TestHyprlandComboKeyAliases(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+	}{
+		{"order", []string{"SUPER", "SHIFT"}, []string{"SHIFT", "SUPER"}},
+		{"win", []string{"WIN"}, []string{"SUPER"}},
+		{"logo", []string{"LOGO", "SHIFT"}, []string{"SHIFT", "SUPER"}},
+		{"mod4", []string{"MOD4"}, []string{"SUPER"}},
+		{"control", []string{"CONTROL", "SUPER"}, []string{"SUPER", "CTRL"}},
+		{"mod1", []string{"MOD1"}, []string{"ALT"}},
+		{"case", []string{"super", "ctrl"}, []string{"CTRL", "SUPER"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := HyprlandBind{Mods: tt.a, Key: "q"}
+			b := HyprlandBind{Mods: tt.b, Key: "Q"}
+			assert.Equal(t, a.comboKey(), b.comboKey())
+		})
+	}
+
+	assert.NotEqual(t, HyprlandBind{Mods: []string{"SUPER"}, Key: "Q"}.comboKey(), HyprlandBind{Mods: []string{"ALT"}, Key: "Q"}.comboKey())
+}
+
+func TestApplyHyprlandMigrationModifierOrder(t *testing.T) {
+	cd := &ConfigDeployer{}
+	cd.SetMigration(&MigrationSource{
+		Keybinds: []HyprlandBind{
+			{Mods: []string{"SHIFT", "SUPER"}, Key: "Q", Command: "wlogout"},
+			{Mods: []string{"CTRL", "SUPER"}, Key: "Q", Command: "hyprlock"},
+			{Mods: []string{"SUPER", "CTRL"}, Key: "L", Command: "loginctl lock-session"},
+		},
+	})
+
+	result := cd.applyHyprlandMigration("$mainMod = SUPER\nbind = $mainMod SHIFT, Q, killactive\nbind = CONTROL WIN, L, exec, swaylock\n")
+
+	assert.NotContains(t, result, "wlogout", "SHIFT SUPER is the same combo as SUPER SHIFT")
+	assert.Contains(t, result, "bind = CTRL SUPER, Q, exec, hyprlock")
+	assert.NotContains(t, result, "lock-session", "CONTROL WIN is the same combo as SUPER CTRL")
+}
+
+func TestParseHyprlandExecBindsComments(t *testing.T) {
+	binds := parseHyprlandExecBinds(`$terminal = foot # my terminal
+bind = SUPER, Return, exec, $terminal # open a terminal
+bind = SUPER, N, exec, notify-send "issue ##42"
+`)
+	require.Len(t, binds, 2)
+	assert.Equal(t, "foot", binds[0].Command)
+	assert.Equal(t, `notify-send "issue #42"`, binds[1].Command)
+}
+
+func TestImportWallpaper(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+
+	statePath := filepath.Join(homeDir, ".local", "state", "DankMaterialShell", "session.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(statePath), 0755))
+	require.NoError(t, os.WriteFile(statePath, []byte(`{"nightModeEnabled": true}`), 0644))
+
+	cd := &ConfigDeployer{}
+	cd.SetMigration(&MigrationSource{Wallpaper: "/tmp/wall.png"})
+
+	result, err := cd.importWallpaper()
+	require.NoError(t, err)
+	assert.True(t, result.Deployed)
+
+	data, err := os.ReadFile(statePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"wallpaperPath": "/tmp/wall.png"`)
+	assert.Contains(t, string(data), `"nightModeEnabled": true`)
+}
+
+func TestParseHyprlandExecBindsMods(t *testing.T) {
+	binds := parseHyprlandExecBinds(`$mainMod = SUPER
+bind = SUPER_SHIFT, Return, exec, kitty
+bind = SUPERSHIFT, E, exec, thunar
+bind = $mainMod CTRL_ALT, L, exec, hyprlock
+bind = HYPER, H, exec, foot
+bind = , Print, exec, grim
+`)
+	require.Len(t, binds, 5)
+	assert.Equal(t, []string{"SUPER", "SHIFT"}, binds[0].Mods)
+	assert.Equal(t, []string{"SUPER", "SHIFT"}, binds[1].Mods)
+	assert.Equal(t, []string{"SUPER", "CTRL", "ALT"}, binds[2].Mods)
+	assert.Equal(t, []string{"HYPER"}, binds[3].Mods)
+	assert.Empty(t, binds[4].Mods)
+
+	combo, err := binds[0].niriCombo()
+	require.NoError(t, err)
+	assert.Equal(t, "Mod+Shift+Return", combo)
+	combo, err = binds[1].niriCombo()
+	require.NoError(t, err)
+	assert.Equal(t, "Mod+Shift+E", combo)
+	_, err = binds[3].niriCombo()
+	assert.Error(t, err, "unknown modifiers aren't dropped")
+	combo, err = binds[4].niriCombo()
+	require.NoError(t, err)
+	assert.Equal(t, "Print", combo)
+
+	assert.Equal(t, []string{"_"}, splitHyprlandMods("_"), "a modmask naming nothing isn't a bare key")
+}
+
+func TestImportNiriKeybindsSkipsUnmappedMods(t *testing.T) {
+	doc, err := kdl.Parse("binds {\n}\n")
+	require.NoError(t, err)
+
+	var logs []string
+	count := importNiriKeybinds(doc, []HyprlandBind{
+		{Mods: []string{"HYPER"}, Key: "Return", Command: "kitty"},
+		{Mods: []string{"_"}, Key: "E", Command: "thunar"},
+	}, func(msg string) { logs = append(logs, msg) })
+	assert.Zero(t, count)
+	assert.Nil(t, doc.Find("binds").Child("Return"))
+	assert.Nil(t, doc.Find("binds").Child("E"))
+	require.Len(t, logs, 2)
+	assert.Contains(t, logs[0], "Skipping imported keybind HYPER, RETURN")
+}
+
+func TestExpandHyprlandVariablesSharedPrefix(t *testing.T) {
+	vars := parseHyprlandVariables("$term = kitty\n$terminal = foot\n$mod = SUPER\n$modShift = SUPER SHIFT\n")
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, "foot -e kitty", expandHyprlandVariables("$terminal -e $term", vars))
+		assert.Equal(t, "SUPER SHIFT", expandHyprlandVariables("$modShift", vars))
+	}
+
+	binds := parseHyprlandExecBinds("$term = kitty\n$terminal = foot\nbind = SUPER, Return, exec, $terminal\n")
+	require.Len(t, binds, 1)
+	assert.Equal(t, "foot", binds[0].Command)
+}
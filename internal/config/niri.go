@@ -113,6 +113,9 @@ overview {
         off
     }
 }
+switch-events {
+    lid-close { spawn "dms" "lock" "--now"; }
+}
 // Add lines like this to spawn processes at startup.
 // Note that running niri as a session supports xdg-desktop-autostart,
 // which may be more convenient to use.
@@ -249,7 +252,7 @@ binds {
     
     // === Security ===
     Mod+Alt+L hotkey-overlay-title="Lock Screen" { 
-        spawn "dms" "ipc" "call" "lock" "lock"; 
+        spawn "dms" "lock"; 
     }
     Mod+Shift+E { quit; }
     Ctrl+Alt+Delete hotkey-overlay-title="Task Manager" { 
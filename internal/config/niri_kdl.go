@@ -0,0 +1,263 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/kdl"
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+// mergeNiriOutputs replaces the template's example outputs with the outputs
+// (including disabled ones) from an existing config
+func mergeNiriOutputs(doc, existing *kdl.Document) bool {
+	existingOutputs := existing.Root.ChildrenNamed("output")
+	if len(existingOutputs) == 0 {
+		return false
+	}
+
+	root := doc.Root
+	insertAt := -1
+	var explanation []string
+	for _, example := range root.ChildrenNamed("output") {
+		if insertAt < 0 {
+			insertAt = root.Index(example)
+			explanation = example.Comments
+		}
+		root.Remove(example)
+	}
+	if insertAt < 0 {
+		insertAt = 0
+		if input := root.Child("input"); input != nil {
+			insertAt = root.Index(input) + 1
+		}
+	}
+
+	for i, output := range existingOutputs {
+		if i == 0 {
+			output.Comments = append(append([]string{}, explanation...), "// Outputs from existing configuration")
+		}
+		root.Insert(insertAt+i, output)
+	}
+	return true
+}
+
+func (cd *ConfigDeployer) mergeNiriOutputSections(newConfig, existingConfig string) (string, error) {
+	doc, err := kdl.Parse(newConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse new config: %w", err)
+	}
+	existing, err := kdl.Parse(existingConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse existing config: %w", err)
+	}
+
+	if !mergeNiriOutputs(doc, existing) {
+		return newConfig, nil
+	}
+	return doc.String(), nil
+}
+
+// importNiriKeybinds appends spawn binds, skipping combos the config binds already
+func importNiriKeybinds(doc *kdl.Document, binds []HyprlandBind, log func(string)) int {
+	section := doc.Ensure("binds")
+	count := 0
+	for _, bind := range binds {
+		combo, err := bind.niriCombo()
+		if err != nil {
+			log(fmt.Sprintf("Warning: Skipping imported keybind %s: %v", bind.hyprlandCombo(), err))
+			continue
+		}
+		if section.Child(combo) != nil {
+			log(fmt.Sprintf("Skipping imported keybind %s (conflicts with DMS default)", combo))
+			continue
+		}
+
+		node := &kdl.Node{Name: combo, Inline: true}
+		spawn := kdl.NewNode("spawn", kdl.String("sh"), kdl.String("-c"), kdl.String(bind.Command))
+		spawn.Semicolon = true
+		node.Append(spawn)
+		if count == 0 {
+			node.Comments = []string{"// === Imported from existing configuration ==="}
+		}
+		section.Append(node)
+		count++
+	}
+	return count
+}
+
+// niriOptionAliases maps short keys to their place in the config
+var niriOptionAliases = map[string]string{
+	"gaps":                  "layout.gaps",
+	"center-focused-column": "layout.center-focused-column",
+	"focus-ring":            "layout.focus-ring.width",
+	"focus-ring.width":      "layout.focus-ring.width",
+	"border":                "layout.border.width",
+	"border.width":          "layout.border.width",
+	"shadow":                "layout.shadow.on",
+	"keyboard.layout":       "input.keyboard.xkb.layout",
+	"xkb.layout":            "input.keyboard.xkb.layout",
+	"repeat-rate":           "input.keyboard.repeat-rate",
+	"repeat-delay":          "input.keyboard.repeat-delay",
+	"natural-scroll":        "input.touchpad.natural-scroll",
+	"tap":                   "input.touchpad.tap",
+}
+
+// NiriOptionPath turns "gaps" or "layout.gaps" into node names; bind combos
+// contain no dots so "binds.Mod+T" stays two nodes
+func NiriOptionPath(key string) []string {
+	key = strings.TrimPrefix(key, "niri.")
+	if alias, ok := niriOptionAliases[key]; ok {
+		key = alias
+	}
+	return strings.Split(key, ".")
+}
+
+// SetNiriOption sets a leaf's arguments. Booleans toggle flag nodes such as
+// prefer-no-csd, and binds.<combo> takes an action like: spawn "foot"
+func SetNiriOption(doc *kdl.Document, key string, values []string) error {
+	path := NiriOptionPath(key)
+	if len(values) == 0 {
+		return fmt.Errorf("no value given for %s", key)
+	}
+
+	if len(path) == 2 && path[0] == "binds" {
+		return setNiriBind(doc, path[1], strings.Join(values, " "))
+	}
+
+	existing := doc.Find(path...)
+	if existing != nil && len(existing.Children) > 0 {
+		return fmt.Errorf("%s is a section, set one of its options instead", strings.Join(path, "."))
+	}
+
+	if len(values) == 1 && (values[0] == "true" || values[0] == "false") &&
+		(existing == nil || len(existing.Args()) == 0) {
+		if values[0] == "false" {
+			if existing != nil {
+				doc.Find(path[:len(path)-1]...).Remove(existing)
+			}
+			return nil
+		}
+		node := doc.Ensure(path...)
+		node.Block = false
+		return nil
+	}
+
+	args := make([]kdl.Value, 0, len(values))
+	for _, value := range values {
+		args = append(args, kdl.ParseValue(value))
+	}
+	node := doc.Ensure(path...)
+	node.Block = false
+	node.SetArgs(args...)
+	return nil
+}
+
+func setNiriBind(doc *kdl.Document, combo, action string) error {
+	actions, err := kdl.Parse(action)
+	if err != nil {
+		return fmt.Errorf("invalid bind action %q: %w", action, err)
+	}
+	if len(actions.Root.Children) == 0 {
+		return fmt.Errorf("no bind action given for %s", combo)
+	}
+
+	binds := doc.Ensure("binds")
+	node := binds.Child(combo)
+	if node == nil {
+		node = &kdl.Node{Name: combo}
+		binds.Append(node)
+	}
+	node.Children = nil
+	node.Inline = true
+	for _, child := range actions.Root.Children {
+		child.Comments = nil
+		child.Semicolon = true
+		node.Append(child)
+	}
+	return nil
+}
+
+// GetNiriOption returns a leaf's arguments, or the KDL text of a section
+func GetNiriOption(doc *kdl.Document, key string) (string, error) {
+	path := NiriOptionPath(key)
+	node := doc.Find(path...)
+	if node == nil {
+		return "", fmt.Errorf("%s is not set", strings.Join(path, "."))
+	}
+
+	if node.Block {
+		section := &kdl.Document{Root: &kdl.Node{Block: true, Children: []*kdl.Node{node}}}
+		return strings.TrimRight(section.String(), "\n"), nil
+	}
+
+	args := node.Args()
+	if len(args) == 0 {
+		return "true", nil
+	}
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		parts = append(parts, arg.String())
+	}
+	return strings.Join(parts, " "), nil
+}
+
+func UnsetNiriOption(doc *kdl.Document, key string) error {
+	path := NiriOptionPath(key)
+	node := doc.Find(path...)
+	if node == nil {
+		return fmt.Errorf("%s is not set", strings.Join(path, "."))
+	}
+	doc.Find(path[:len(path)-1]...).Remove(node)
+	return nil
+}
+
+func NiriConfigPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".config", "niri", "config.kdl")
+}
+
+func LoadNiriConfig(path string) (*kdl.Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := kdl.Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// EditNiriConfig applies edit to the config and writes it back, having niri
+// validate the result first when it is installed; niri reloads on change
+func EditNiriConfig(path string, edit func(*kdl.Document) error) error {
+	doc, err := LoadNiriConfig(path)
+	if err != nil {
+		return err
+	}
+	if err := edit(doc); err != nil {
+		return err
+	}
+	return WriteNiriConfig(path, doc.String())
+}
+
+// WriteNiriConfig replaces the config with content once niri (when
+// installed) has validated it
+func WriteNiriConfig(path, content string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomicValidated(path, []byte(content), info.Mode().Perm(), func(tmp string) error {
+		if _, err := exec.LookPath("niri"); err != nil {
+			return nil
+		}
+		if output, err := exec.Command("niri", "validate", "-c", tmp).CombinedOutput(); err != nil {
+			return fmt.Errorf("niri rejected the change: %s", strings.TrimSpace(string(output)))
+		}
+		return nil
+	})
+}
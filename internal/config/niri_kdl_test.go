@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/kdl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNiriTemplateParses(t *testing.T) {
+	doc, err := kdl.Parse(NiriConfig)
+	require.NoError(t, err)
+
+	reparsed, err := kdl.Parse(doc.String())
+	require.NoError(t, err)
+	assert.Equal(t, doc.String(), reparsed.String(), "writer output is stable")
+
+	require.NotNil(t, doc.Find("layout", "gaps"))
+	require.NotNil(t, doc.Find("binds", "Mod+T"))
+}
+
+func TestNiriOptions(t *testing.T) {
+	doc, err := kdl.Parse(NiriConfig)
+	require.NoError(t, err)
+
+	require.NoError(t, SetNiriOption(doc, "niri.gaps", []string{"8"}))
+	value, err := GetNiriOption(doc, "layout.gaps")
+	require.NoError(t, err)
+	assert.Equal(t, "8", value)
+
+	require.NoError(t, SetNiriOption(doc, "center-focused-column", []string{"always"}))
+	assert.Contains(t, doc.String(), `center-focused-column "always"`)
+
+	require.NoError(t, SetNiriOption(doc, "prefer-no-csd", []string{"true"}))
+	value, err = GetNiriOption(doc, "prefer-no-csd")
+	require.NoError(t, err)
+	assert.Equal(t, "true", value)
+	require.NoError(t, SetNiriOption(doc, "prefer-no-csd", []string{"false"}))
+	assert.Nil(t, doc.Find("prefer-no-csd"))
+
+	require.NoError(t, SetNiriOption(doc, "binds.Mod+Shift+B", []string{`spawn "firefox"`}))
+	assert.Contains(t, doc.String(), `    Mod+Shift+B { spawn "firefox"; }`)
+	require.NoError(t, SetNiriOption(doc, "binds.Mod+Shift+B", []string{"spawn", `"chromium"`}))
+	assert.Contains(t, doc.String(), `    Mod+Shift+B { spawn "chromium"; }`)
+
+	assert.Error(t, SetNiriOption(doc, "layout", []string{"5"}))
+	assert.Error(t, SetNiriOption(doc, "binds.Mod+X", []string{"{"}))
+
+	require.NoError(t, UnsetNiriOption(doc, "binds.Mod+Shift+B"))
+	assert.NotContains(t, doc.String(), "Mod+Shift+B")
+	assert.Error(t, UnsetNiriOption(doc, "binds.Mod+Shift+B"))
+
+	_, err = kdl.Parse(doc.String())
+	require.NoError(t, err)
+}
+
+func TestEditNiriConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.kdl")
+	require.NoError(t, os.WriteFile(path, []byte("layout {\n    gaps 5\n}\n"), 0600))
+	t.Setenv("PATH", t.TempDir())
+
+	require.NoError(t, EditNiriConfig(path, func(doc *kdl.Document) error {
+		return SetNiriOption(doc, "gaps", []string{"12"})
+	}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "layout {\n    gaps 12\n}\n", string(data))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+// ShellSettings is the shell's settings.json; keys may be dotted to reach
+// into nested objects
+type ShellSettings map[string]interface{}
+
+func ShellSettingsPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".config", "DankMaterialShell", "settings.json")
+}
+
+func LoadShellSettings(path string) (ShellSettings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no shell settings at %s (open the settings panel once to create them)", path)
+		}
+		return nil, err
+	}
+
+	settings := make(ShellSettings)
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// Save writes the settings atomically so the shell's file watcher never
+// reads a half-written file
+func (s ShellSettings) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteFileAtomic(path, append(data, '\n'), 0644)
+}
+
+func (s ShellSettings) lookup(key string) (map[string]interface{}, string, bool) {
+	parts := strings.Split(key, ".")
+	current := map[string]interface{}(s)
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			return nil, "", false
+		}
+		current = next
+	}
+	last := parts[len(parts)-1]
+	_, ok := current[last]
+	return current, last, ok
+}
+
+func (s ShellSettings) Get(key string) (interface{}, error) {
+	parent, name, ok := s.lookup(key)
+	if !ok {
+		return nil, fmt.Errorf("unknown setting %q (see dms config list)", key)
+	}
+	return parent[name], nil
+}
+
+// Set converts value to the type the setting already has, so a typo can't
+// turn a number into a string the shell then fails to read
+func (s ShellSettings) Set(key, value string) error {
+	parent, name, ok := s.lookup(key)
+	if !ok {
+		return fmt.Errorf("unknown setting %q (see dms config list)", key)
+	}
+
+	converted, err := convertSetting(parent[name], value)
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	parent[name] = converted
+	return nil
+}
+
+func convertSetting(current interface{}, value string) (interface{}, error) {
+	switch current.(type) {
+	case bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("expected true or false, got %q", value)
+		}
+		return b, nil
+	case float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number, got %q", value)
+		}
+		return f, nil
+	case string:
+		return value, nil
+	case []interface{}:
+		var list []interface{}
+		if err := json.Unmarshal([]byte(value), &list); err != nil {
+			return nil, fmt.Errorf("expected a JSON array, got %q", value)
+		}
+		return list, nil
+	case map[string]interface{}:
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(value), &object); err != nil {
+			return nil, fmt.Errorf("expected a JSON object, got %q", value)
+		}
+		return object, nil
+	}
+
+	// null: take whatever JSON the value is, falling back to a string
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(value), &decoded); err == nil {
+		return decoded, nil
+	}
+	return value, nil
+}
+
+// List flattens nested objects into sorted dotted keys, optionally limited
+// to keys starting with prefix
+func (s ShellSettings) List(prefix string) []string {
+	var keys []string
+	var walk func(path string, value interface{})
+	walk = func(path string, value interface{}) {
+		if object, ok := value.(map[string]interface{}); ok && len(object) > 0 {
+			for name, child := range object {
+				walk(path+"."+name, child)
+			}
+			return
+		}
+		keys = append(keys, path)
+	}
+	for name, value := range s {
+		walk(name, value)
+	}
+
+	filtered := keys[:0]
+	for _, key := range keys {
+		if strings.HasPrefix(key, prefix) {
+			filtered = append(filtered, key)
+		}
+	}
+	sort.Strings(filtered)
+	return filtered
+}
+
+// FormatSetting prints strings bare and everything else as JSON
+func FormatSetting(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSettings(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestShellSettingsTypedSet(t *testing.T) {
+	path := writeSettings(t, `{
+  "use24HourClock": true,
+  "barHeight": 32,
+  "currentThemeName": "blue",
+  "widgetOrder": ["clock", "media"],
+  "weather": {"location": "Berlin", "units": "metric"},
+  "wallpaperPath": null
+}`)
+
+	settings, err := LoadShellSettings(path)
+	require.NoError(t, err)
+
+	require.NoError(t, settings.Set("use24HourClock", "false"))
+	require.NoError(t, settings.Set("barHeight", "40"))
+	require.NoError(t, settings.Set("currentThemeName", "purple"))
+	require.NoError(t, settings.Set("widgetOrder", `["media"]`))
+	require.NoError(t, settings.Set("weather.units", "imperial"))
+	require.NoError(t, settings.Set("wallpaperPath", "/tmp/wall.png"))
+
+	assert.Error(t, settings.Set("use24HourClock", "maybe"))
+	assert.Error(t, settings.Set("barHeight", "tall"))
+	assert.Error(t, settings.Set("widgetOrder", "clock"))
+	assert.Error(t, settings.Set("doesNotExist", "1"))
+	assert.Error(t, settings.Set("weather.missing.deep", "1"))
+
+	require.NoError(t, settings.Save(path))
+	reloaded, err := LoadShellSettings(path)
+	require.NoError(t, err)
+
+	value, err := reloaded.Get("barHeight")
+	require.NoError(t, err)
+	assert.Equal(t, "40", FormatSetting(value))
+	value, err = reloaded.Get("weather.units")
+	require.NoError(t, err)
+	assert.Equal(t, "imperial", FormatSetting(value))
+	value, err = reloaded.Get("use24HourClock")
+	require.NoError(t, err)
+	assert.Equal(t, false, value)
+	value, err = reloaded.Get("wallpaperPath")
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/wall.png", value)
+}
+
+func TestShellSettingsList(t *testing.T) {
+	settings, err := LoadShellSettings(writeSettings(t, `{"b": 1, "a": {"y": true, "x": "s"}, "empty": {}}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a.x", "a.y", "b", "empty"}, settings.List(""))
+	assert.Equal(t, []string{"a.x", "a.y"}, settings.List("a."))
+}
+
+func TestLoadShellSettingsMissing(t *testing.T) {
+	_, err := LoadShellSettings(filepath.Join(t.TempDir(), "settings.json"))
+	assert.ErrorContains(t, err, "no shell settings")
+}
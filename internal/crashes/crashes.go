@@ -0,0 +1,149 @@
+// Package crashes records quickshell crashes and dms daemon panics in
+// $XDG_STATE_HOME/dms/crashes so they survive the session and can be attached
+// to bug reports
+package crashes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+const (
+	ComponentQuickshell = "quickshell"
+	ComponentDaemon     = "dms"
+)
+
+// maxReports bounds the directory, since core dumps can be large
+const maxReports = 20
+
+type Crash struct {
+	ID        string    `json:"id"`
+	Time      time.Time `json:"time"`
+	Component string    `json:"component"`
+	Version   string    `json:"version,omitempty"`
+	PID       int       `json:"pid,omitempty"`
+	Reason    string    `json:"reason"`
+	ExitCode  int       `json:"exitCode,omitempty"`
+	Signal    string    `json:"signal,omitempty"`
+	// Details holds the stack trace or coredumpctl info, CoreDump the core
+	// file; both are paths inside the crash directory
+	Details  string `json:"details,omitempty"`
+	CoreDump string `json:"coreDump,omitempty"`
+}
+
+func Dir() string {
+	stateDir := os.Getenv("XDG_STATE_HOME")
+	if stateDir == "" {
+		stateDir = filepath.Join(os.Getenv("HOME"), ".local", "state")
+	}
+	return filepath.Join(stateDir, "dms", "crashes")
+}
+
+func newID(t time.Time, component string) string {
+	return fmt.Sprintf("%s-%s", t.Format("20060102-150405.000"), component)
+}
+
+// Save writes the crash metadata and, when given, its details next to it
+func Save(dir string, crash Crash, details []byte) (Crash, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return crash, err
+	}
+	if crash.Time.IsZero() {
+		crash.Time = time.Now()
+	}
+	if crash.ID == "" {
+		crash.ID = newID(crash.Time, crash.Component)
+	}
+
+	if len(details) > 0 {
+		crash.Details = filepath.Join(dir, crash.ID+".txt")
+		if err := utils.WriteFileAtomic(crash.Details, details, 0600); err != nil {
+			return crash, err
+		}
+	}
+
+	data, err := json.MarshalIndent(crash, "", "  ")
+	if err != nil {
+		return crash, err
+	}
+	if err := utils.WriteFileAtomic(filepath.Join(dir, crash.ID+".json"), data, 0600); err != nil {
+		return crash, err
+	}
+
+	prune(dir, maxReports)
+	return crash, nil
+}
+
+// List returns recorded crashes, newest first. Panics left behind by daemons
+// that have since exited are recorded first
+func List(dir string) ([]Crash, error) {
+	collectPendingPanics(dir)
+	return readAll(dir)
+}
+
+func readAll(dir string) ([]Crash, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Crash
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var crash Crash
+		if err := json.Unmarshal(data, &crash); err != nil {
+			continue
+		}
+		list = append(list, crash)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Time.After(list[j].Time) })
+	return list, nil
+}
+
+// Since returns the crashes recorded after t, newest first
+func Since(dir string, t time.Time) ([]Crash, error) {
+	all, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+	var recent []Crash
+	for _, crash := range all {
+		if crash.Time.After(t) {
+			recent = append(recent, crash)
+		}
+	}
+	return recent, nil
+}
+
+func prune(dir string, keep int) {
+	list, err := readAll(dir)
+	if err != nil || len(list) <= keep {
+		return
+	}
+	for _, crash := range list[keep:] {
+		os.Remove(filepath.Join(dir, crash.ID+".json"))
+		if crash.Details != "" {
+			os.Remove(crash.Details)
+		}
+		if crash.CoreDump != "" {
+			os.Remove(crash.CoreDump)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package crashes
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFromExit(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		crash  bool
+		signal string
+		code   int
+	}{
+		{"clean exit", "exit 0", false, "", 0},
+		{"exit status", "exit 3", true, "", 3},
+		{"abort", "kill -ABRT $$", true, "SIGABRT", 0},
+		{"terminated", "kill -TERM $$", false, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("sh", "-c", tt.script)
+			err := cmd.Run()
+			crash, ok := FromExit(ComponentQuickshell, cmd.Process.Pid, err)
+			if ok != tt.crash {
+				t.Fatalf("crash = %v, want %v (err %v)", ok, tt.crash, err)
+			}
+			if crash.Signal != tt.signal || crash.ExitCode != tt.code {
+				t.Errorf("got signal %q code %d, want %q %d", crash.Signal, crash.ExitCode, tt.signal, tt.code)
+			}
+			if ok && crash.Reason == "" {
+				t.Error("missing reason")
+			}
+		})
+	}
+}
+
+func TestSaveListAndPrune(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < maxReports+3; i++ {
+		crash := Crash{Component: ComponentQuickshell, Reason: "exited with status 1", Time: start.Add(time.Duration(i) * time.Minute)}
+		if _, err := Save(dir, crash, []byte(fmt.Sprintf("details %d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != maxReports {
+		t.Fatalf("got %d reports, want %d", len(list), maxReports)
+	}
+	if !list[0].Time.After(list[1].Time) {
+		t.Error("reports are not newest first")
+	}
+	if data, err := os.ReadFile(list[0].Details); err != nil || string(data) != fmt.Sprintf("details %d", maxReports+2) {
+		t.Errorf("details = %q, %v", data, err)
+	}
+
+	oldest := newID(start, ComponentQuickshell)
+	if _, err := os.Stat(filepath.Join(dir, oldest+".txt")); !os.IsNotExist(err) {
+		t.Error("pruned report details were kept")
+	}
+
+	recent, err := Since(dir, start.Add(time.Duration(maxReports+1)*time.Minute))
+	if err != nil || len(recent) != 1 {
+		t.Errorf("Since returned %d reports, %v", len(recent), err)
+	}
+}
+
+func TestCollectPendingPanics(t *testing.T) {
+	dir := t.TempDir()
+
+	// A finished process' PID is (almost certainly) not reused this quickly
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	deadPID := cmd.Process.Pid
+
+	trace := "goroutine 1 [running]:\npanic: runtime error: index out of range [3] with length 2\n\ngoroutine 7 [running]:\nmain.main()\n"
+	pending := filepath.Join(dir, fmt.Sprintf("%s%d-v1.2.0.panic", pendingPrefix, deadPID))
+	if err := os.WriteFile(pending, []byte(trace), 0600); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, fmt.Sprintf("%s%d-v1.2.0.panic", pendingPrefix, deadPID+1))
+	if err := os.WriteFile(empty, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	live := filepath.Join(dir, fmt.Sprintf("%s%d-dev.panic", pendingPrefix, os.Getpid()))
+	if err := os.WriteFile(live, []byte("panic: not yet"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("got %d reports, want 1", len(list))
+	}
+	crash := list[0]
+	if crash.Component != ComponentDaemon || crash.PID != deadPID || crash.Version != "v1.2.0" {
+		t.Errorf("unexpected crash %+v", crash)
+	}
+	if crash.Reason != "panic: runtime error: index out of range [3] with length 2" {
+		t.Errorf("reason = %q", crash.Reason)
+	}
+	if _, err := os.Stat(pending); !os.IsNotExist(err) {
+		t.Error("pending panic file was not removed")
+	}
+	if _, err := os.Stat(empty); !os.IsNotExist(err) {
+		t.Error("empty pending file was not removed")
+	}
+	if _, err := os.Stat(live); err != nil {
+		t.Error("the running process' pending file was removed")
+	}
+}
@@ -0,0 +1,119 @@
+package crashes
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const pendingPrefix = "pending-"
+
+// PanicCapture routes fatal panics from any goroutine into a file in the
+// crash directory. A panic ends the process, so the file is turned into a
+// crash report by the next List or CapturePanics
+type PanicCapture struct {
+	file *os.File
+}
+
+func CapturePanics(version string) (*PanicCapture, error) {
+	dir := Dir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	collectPendingPanics(dir)
+
+	name := fmt.Sprintf("%s%d-%s.panic", pendingPrefix, os.Getpid(), sanitizeVersion(version))
+	file, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := debug.SetCrashOutput(file, debug.CrashOptions{}); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	return &PanicCapture{file: file}, nil
+}
+
+// Close stops capturing after a clean shutdown
+func (p *PanicCapture) Close() {
+	debug.SetCrashOutput(nil, debug.CrashOptions{})
+	p.file.Close()
+	os.Remove(p.file.Name())
+}
+
+func sanitizeVersion(version string) string {
+	if version == "" {
+		return "unknown"
+	}
+	return strings.NewReplacer("/", "_", "-", "_").Replace(version)
+}
+
+func parsePendingName(name string) (int, string, bool) {
+	if !strings.HasPrefix(name, pendingPrefix) || !strings.HasSuffix(name, ".panic") {
+		return 0, "", false
+	}
+	pidPart, version, _ := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(name, pendingPrefix), ".panic"), "-")
+	pid, err := strconv.Atoi(pidPart)
+	if err != nil {
+		return 0, "", false
+	}
+	return pid, version, true
+}
+
+func processAlive(pid int) bool {
+	return syscall.Kill(pid, 0) == nil
+}
+
+func collectPendingPanics(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		pid, version, ok := parsePendingName(entry.Name())
+		if !ok || pid == os.Getpid() || processAlive(pid) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if len(bytes.TrimSpace(data)) > 0 {
+			crash := Crash{
+				Component: ComponentDaemon,
+				Version:   version,
+				PID:       pid,
+				Reason:    panicReason(data),
+			}
+			if info, err := entry.Info(); err == nil {
+				crash.Time = info.ModTime()
+			}
+			if _, err := Save(dir, crash, data); err != nil {
+				continue
+			}
+		}
+		os.Remove(path)
+	}
+}
+
+// panicReason picks the "panic: ..." or "fatal error: ..." line
+func panicReason(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "panic: ") || strings.HasPrefix(line, "fatal error: ") {
+			return line
+		}
+	}
+	return "panic"
+}
@@ -0,0 +1,103 @@
+package crashes
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// crashSignals are the signals that mean the process died on its own;
+// SIGTERM and SIGKILL come from shutdowns and restarts
+var crashSignals = map[syscall.Signal]bool{
+	syscall.SIGABRT: true,
+	syscall.SIGSEGV: true,
+	syscall.SIGBUS:  true,
+	syscall.SIGFPE:  true,
+	syscall.SIGILL:  true,
+	syscall.SIGTRAP: true,
+	syscall.SIGSYS:  true,
+}
+
+// FromExit classifies the error returned by waiting on a process. It reports
+// false for clean exits and for processes stopped by a signal
+func FromExit(component string, pid int, err error) (Crash, bool) {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return Crash{}, false
+	}
+
+	crash := Crash{Component: component, PID: pid}
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		if !crashSignals[status.Signal()] {
+			return Crash{}, false
+		}
+		crash.Signal = unix.SignalName(status.Signal())
+		crash.Reason = fmt.Sprintf("killed by %s (%s)", crash.Signal, status.Signal())
+		return crash, true
+	}
+
+	if exitErr.ExitCode() <= 0 {
+		return Crash{}, false
+	}
+	crash.ExitCode = exitErr.ExitCode()
+	crash.Reason = fmt.Sprintf("exited with status %d", crash.ExitCode)
+	return crash, true
+}
+
+// RecordQuickshellExit saves a crash report if quickshell died abnormally,
+// attaching systemd-coredump's backtrace and core when available
+func RecordQuickshellExit(version string, pid int, waitErr error) (Crash, bool) {
+	crash, ok := FromExit(ComponentQuickshell, pid, waitErr)
+	if !ok {
+		return Crash{}, false
+	}
+	crash.Version = version
+	crash.Time = time.Now()
+	crash.ID = newID(crash.Time, crash.Component)
+
+	dir := Dir()
+	var details []byte
+	if crash.Signal != "" {
+		details, crash.CoreDump = collectCoreDump(dir, crash.ID, pid)
+	}
+
+	saved, err := Save(dir, crash, details)
+	if err != nil {
+		return Crash{}, false
+	}
+	return saved, true
+}
+
+// collectCoreDump asks coredumpctl for the process' backtrace and core.
+// systemd-coredump processes the dump asynchronously, so give it a moment
+func collectCoreDump(dir, id string, pid int) ([]byte, string) {
+	if _, err := exec.LookPath("coredumpctl"); err != nil {
+		return nil, ""
+	}
+
+	match := strconv.Itoa(pid)
+	var info []byte
+	for attempt := 0; attempt < 10; attempt++ {
+		output, err := exec.Command("coredumpctl", "--no-pager", "info", match).Output()
+		if err == nil && len(output) > 0 {
+			info = output
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	if info == nil {
+		return nil, ""
+	}
+
+	corePath := filepath.Join(dir, id+".core")
+	if err := exec.Command("coredumpctl", "--no-pager", "dump", match, "--output", corePath).Run(); err != nil {
+		return info, ""
+	}
+	return info, corePath
+}
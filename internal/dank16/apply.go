@@ -0,0 +1,88 @@
+package dank16
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+// LiveApply pushes the palette into running terminals so open windows pick
+// it up immediately:
+//   - kitty reloads its config on SIGUSR1
+//   - ghostty reloads its config on SIGUSR2
+//   - alacritty watches its imports and reloads on its own
+//   - foot has no reload, so the palette is sent as OSC escape sequences to
+//     the user's ptys, which foot (and any other terminal) applies in place
+func LiveApply(p Palette, written []Terminal) {
+	for _, term := range written {
+		var err error
+		switch term.Name {
+		case "kitty":
+			err = signalProcesses("kitty", syscall.SIGUSR1)
+		case "ghostty":
+			err = signalProcesses("ghostty", syscall.SIGUSR2)
+		case "foot":
+			err = writeSequences(p)
+		}
+		if err != nil {
+			log.Debugf("dank16: live apply for %s failed: %v", term.Name, err)
+		}
+	}
+}
+
+func signalProcesses(name string, sig syscall.Signal) error {
+	if _, err := exec.LookPath("pkill"); err != nil {
+		return err
+	}
+	err := exec.Command("pkill", fmt.Sprintf("-%d", int(sig)), "-u", fmt.Sprint(os.Getuid()), "-x", name).Run()
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		// No matching processes
+		return nil
+	}
+	return err
+}
+
+// Sequences returns the OSC escapes that set the palette, background,
+// foreground and cursor colors of a running terminal
+func Sequences(p Palette) string {
+	var b strings.Builder
+	for i, color := range p.Colors {
+		fmt.Fprintf(&b, "\033]4;%d;%s\033\\", i, color)
+	}
+	fmt.Fprintf(&b, "\033]10;%s\033\\", p.Foreground)
+	fmt.Fprintf(&b, "\033]11;%s\033\\", p.Background)
+	fmt.Fprintf(&b, "\033]12;%s\033\\", p.Foreground)
+	return b.String()
+}
+
+func writeSequences(p Palette) error {
+	ptys, err := filepath.Glob("/dev/pts/[0-9]*")
+	if err != nil {
+		return err
+	}
+
+	seq := []byte(Sequences(p))
+	uid := uint32(os.Getuid())
+	for _, pty := range ptys {
+		info, err := os.Stat(pty)
+		if err != nil {
+			continue
+		}
+		if stat, ok := info.Sys().(*syscall.Stat_t); !ok || stat.Uid != uid {
+			continue
+		}
+
+		f, err := os.OpenFile(pty, os.O_WRONLY|syscall.O_NOCTTY|syscall.O_NONBLOCK, 0)
+		if err != nil {
+			continue
+		}
+		f.Write(seq)
+		f.Close()
+	}
+	return nil
+}
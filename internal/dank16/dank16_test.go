@@ -0,0 +1,75 @@
+package dank16
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	dark, err := Generate("#42a5f5", false)
+	require.NoError(t, err)
+	light, err := Generate("42a5f5", true)
+	require.NoError(t, err)
+
+	for _, p := range []Palette{dark, light} {
+		for i, color := range p.Colors {
+			assert.Regexp(t, `^#[0-9a-f]{6}$`, color, "color %d", i)
+		}
+	}
+	assert.NotEqual(t, dark.Background, light.Background)
+
+	bg, _ := parseHex(dark.Background)
+	fg, _ := parseHex(dark.Foreground)
+	assert.Less(t, bg.v, fg.v, "dark palette needs a dark background")
+
+	_, err = Generate("blue", false)
+	assert.Error(t, err)
+}
+
+func TestHexRoundTrip(t *testing.T) {
+	for _, color := range []string{"#000000", "#ffffff", "#ff0000", "#42a5f5", "#7e57c2"} {
+		c, err := parseHex(color)
+		require.NoError(t, err)
+		assert.Equal(t, color, c.hex())
+	}
+}
+
+func TestWriteConfigs(t *testing.T) {
+	p, err := Generate("#7e57c2", false)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "kitty"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "foot"), 0755))
+
+	written, err := WriteConfigs(dir, p)
+	require.NoError(t, err)
+	require.Len(t, written, 2)
+	assert.Equal(t, "kitty", written[0].Name)
+	assert.Equal(t, "foot", written[1].Name)
+
+	kitty, err := os.ReadFile(filepath.Join(dir, "kitty", "dank-theme.conf"))
+	require.NoError(t, err)
+	assert.Contains(t, string(kitty), "color15 "+p.Colors[15])
+
+	foot, err := os.ReadFile(filepath.Join(dir, "foot", "dank-colors.ini"))
+	require.NoError(t, err)
+	assert.Contains(t, string(foot), "regular1="+strings.TrimPrefix(p.Colors[1], "#"))
+
+	_, err = os.Stat(filepath.Join(dir, "ghostty"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSequences(t *testing.T) {
+	p, err := Generate("#42a5f5", false)
+	require.NoError(t, err)
+
+	seq := Sequences(p)
+	assert.Contains(t, seq, "\033]4;0;"+p.Colors[0]+"\033\\")
+	assert.Contains(t, seq, "\033]11;"+p.Background+"\033\\")
+}
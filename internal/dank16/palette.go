@@ -0,0 +1,143 @@
+package dank16
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Palette is a 16 color ANSI terminal palette plus background and foreground
+type Palette struct {
+	Colors     [16]string `json:"colors"`
+	Background string     `json:"background"`
+	Foreground string     `json:"foreground"`
+}
+
+type hsv struct {
+	h, s, v float64
+}
+
+// ANSI hues for red, green, yellow, blue, magenta and cyan
+var ansiHues = [6]float64{0, 120, 60, 220, 300, 180}
+
+// Generate derives a palette from the theme's primary color. The ANSI hues
+// are pulled a little towards the primary hue so the palette matches the
+// theme while staying readable, and blue is the primary itself
+func Generate(primary string, light bool) (Palette, error) {
+	base, err := parseHex(primary)
+	if err != nil {
+		return Palette{}, err
+	}
+
+	var p Palette
+	if light {
+		p.Background = hsv{base.h, 0.04, 0.98}.hex()
+		p.Foreground = hsv{base.h, 0.15, 0.16}.hex()
+		p.Colors[0] = hsv{base.h, 0.10, 0.20}.hex()
+		p.Colors[7] = hsv{base.h, 0.06, 0.72}.hex()
+		p.Colors[8] = hsv{base.h, 0.08, 0.45}.hex()
+		p.Colors[15] = hsv{base.h, 0.04, 0.92}.hex()
+	} else {
+		p.Background = hsv{base.h, 0.12, 0.09}.hex()
+		p.Foreground = hsv{base.h, 0.06, 0.92}.hex()
+		p.Colors[0] = hsv{base.h, 0.12, 0.16}.hex()
+		p.Colors[7] = hsv{base.h, 0.06, 0.85}.hex()
+		p.Colors[8] = hsv{base.h, 0.10, 0.42}.hex()
+		p.Colors[15] = hsv{base.h, 0.03, 0.98}.hex()
+	}
+
+	for i, hue := range ansiHues {
+		h := blendHue(hue, base.h, 0.15)
+		sat, val := 0.60, 0.85
+		if light {
+			sat, val = 0.75, 0.58
+		}
+		if i == 3 {
+			h = base.h
+			sat = math.Max(base.s, 0.45)
+		}
+
+		normal := hsv{h, sat, val}
+		bright := hsv{h, sat * 0.8, math.Min(val+0.12, 1)}
+		if light {
+			bright = hsv{h, math.Min(sat+0.1, 1), val - 0.1}
+		}
+
+		p.Colors[i+1] = normal.hex()
+		p.Colors[i+9] = bright.hex()
+	}
+
+	return p, nil
+}
+
+// blendHue moves from towards to by amount along the shorter arc
+func blendHue(from, to, amount float64) float64 {
+	diff := math.Mod(to-from+540, 360) - 180
+	return math.Mod(from+diff*amount+360, 360)
+}
+
+func parseHex(color string) (hsv, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(color), "#")
+	if len(hex) != 6 {
+		return hsv{}, fmt.Errorf("invalid color %q (expected #rrggbb)", color)
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return hsv{}, fmt.Errorf("invalid color %q (expected #rrggbb)", color)
+	}
+
+	r := float64(value>>16&0xff) / 255
+	g := float64(value>>8&0xff) / 255
+	b := float64(value&0xff) / 255
+
+	maxC := math.Max(r, math.Max(g, b))
+	minC := math.Min(r, math.Min(g, b))
+	delta := maxC - minC
+
+	var h float64
+	switch {
+	case delta == 0:
+		h = 0
+	case maxC == r:
+		h = 60 * math.Mod((g-b)/delta, 6)
+	case maxC == g:
+		h = 60 * ((b-r)/delta + 2)
+	default:
+		h = 60 * ((r-g)/delta + 4)
+	}
+	if h < 0 {
+		h += 360
+	}
+
+	var s float64
+	if maxC > 0 {
+		s = delta / maxC
+	}
+	return hsv{h, s, maxC}, nil
+}
+
+func (c hsv) hex() string {
+	chroma := c.v * c.s
+	x := chroma * (1 - math.Abs(math.Mod(c.h/60, 2)-1))
+	m := c.v - chroma
+
+	var r, g, b float64
+	switch {
+	case c.h < 60:
+		r, g, b = chroma, x, 0
+	case c.h < 120:
+		r, g, b = x, chroma, 0
+	case c.h < 180:
+		r, g, b = 0, chroma, x
+	case c.h < 240:
+		r, g, b = 0, x, chroma
+	case c.h < 300:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x",
+		int(math.Round((r+m)*255)), int(math.Round((g+m)*255)), int(math.Round((b+m)*255)))
+}
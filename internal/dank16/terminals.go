@@ -0,0 +1,100 @@
+package dank16
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+// Terminal knows where a terminal reads the dank16 colors and how to render them
+type Terminal struct {
+	Name   string
+	Binary string
+	// Path is relative to the XDG config dir
+	Path   string
+	Render func(Palette) string
+}
+
+var Terminals = []Terminal{
+	{Name: "ghostty", Binary: "ghostty", Path: "ghostty/config-dankcolors", Render: renderGhostty},
+	{Name: "kitty", Binary: "kitty", Path: "kitty/dank-theme.conf", Render: renderKitty},
+	{Name: "alacritty", Binary: "alacritty", Path: "alacritty/dank-theme.toml", Render: renderAlacritty},
+	{Name: "foot", Binary: "foot", Path: "foot/dank-colors.ini", Render: renderFoot},
+}
+
+func renderGhostty(p Palette) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "background = %s\n", p.Background)
+	fmt.Fprintf(&b, "foreground = %s\n", p.Foreground)
+	fmt.Fprintf(&b, "cursor-color = %s\n", p.Foreground)
+	fmt.Fprintf(&b, "selection-background = %s\n", p.Colors[8])
+	fmt.Fprintf(&b, "selection-foreground = %s\n", p.Foreground)
+	for i, color := range p.Colors {
+		fmt.Fprintf(&b, "palette = %d=%s\n", i, color)
+	}
+	return b.String()
+}
+
+func renderKitty(p Palette) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "background %s\n", p.Background)
+	fmt.Fprintf(&b, "foreground %s\n", p.Foreground)
+	fmt.Fprintf(&b, "cursor %s\n", p.Foreground)
+	fmt.Fprintf(&b, "selection_background %s\n", p.Colors[8])
+	fmt.Fprintf(&b, "selection_foreground %s\n", p.Foreground)
+	for i, color := range p.Colors {
+		fmt.Fprintf(&b, "color%d %s\n", i, color)
+	}
+	return b.String()
+}
+
+var alacrittyNames = [8]string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+func renderAlacritty(p Palette) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[colors.primary]\nbackground = '%s'\nforeground = '%s'\n", p.Background, p.Foreground)
+	b.WriteString("\n[colors.normal]\n")
+	for i, name := range alacrittyNames {
+		fmt.Fprintf(&b, "%s = '%s'\n", name, p.Colors[i])
+	}
+	b.WriteString("\n[colors.bright]\n")
+	for i, name := range alacrittyNames {
+		fmt.Fprintf(&b, "%s = '%s'\n", name, p.Colors[i+8])
+	}
+	return b.String()
+}
+
+func renderFoot(p Palette) string {
+	var b strings.Builder
+	b.WriteString("[colors]\n")
+	fmt.Fprintf(&b, "background=%s\n", strings.TrimPrefix(p.Background, "#"))
+	fmt.Fprintf(&b, "foreground=%s\n", strings.TrimPrefix(p.Foreground, "#"))
+	for i := 0; i < 8; i++ {
+		fmt.Fprintf(&b, "regular%d=%s\n", i, strings.TrimPrefix(p.Colors[i], "#"))
+	}
+	for i := 0; i < 8; i++ {
+		fmt.Fprintf(&b, "bright%d=%s\n", i, strings.TrimPrefix(p.Colors[i+8], "#"))
+	}
+	return b.String()
+}
+
+// WriteConfigs writes the palette for every terminal whose config directory
+// exists and returns the terminals that were written
+func WriteConfigs(configDir string, p Palette) ([]Terminal, error) {
+	var written []Terminal
+	for _, term := range Terminals {
+		path := filepath.Join(configDir, term.Path)
+		if _, err := os.Stat(filepath.Dir(path)); err != nil {
+			continue
+		}
+
+		if err := utils.WriteFileAtomic(path, []byte(term.Render(p)), 0644); err != nil {
+			return written, fmt.Errorf("failed to write %s colors: %w", term.Name, err)
+		}
+		written = append(written, term)
+	}
+	return written, nil
+}
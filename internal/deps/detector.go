@@ -18,6 +18,7 @@ type PackageVariant int
 const (
 	VariantStable PackageVariant = iota
 	VariantGit
+	VariantBinary // prebuilt GitHub release binary
 )
 
 type Dependency struct {
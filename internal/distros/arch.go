@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
 )
 
 func init() {
@@ -222,6 +223,10 @@ func (a *ArchDistribution) getMatugenMapping(variant deps.PackageVariant) Packag
 }
 
 func (a *ArchDistribution) getDMSMapping(variant deps.PackageVariant) PackageMapping {
+	if shellRef != "" {
+		return PackageMapping{Name: "dms", Repository: RepoTypeManual, BuildFunc: "installDankMaterialShell"}
+	}
+
 	if forceDMSGit || variant == deps.VariantGit {
 		return PackageMapping{Name: "dms-shell-git", Repository: RepoTypeAUR}
 	}
@@ -251,7 +256,7 @@ func (a *ArchDistribution) detectXwaylandSatellite() deps.Dependency {
 	}
 }
 
-func (a *ArchDistribution) InstallPrerequisites(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (a *ArchDistribution) InstallPrerequisites(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	progressChan <- InstallProgressMsg{
 		Phase:      PhasePrerequisites,
 		Progress:   0.06,
@@ -284,7 +289,7 @@ func (a *ArchDistribution) InstallPrerequisites(ctx context.Context, sudoPasswor
 		LogOutput:   "Installing base-devel development tools",
 	}
 
-	cmd := exec.CommandContext(ctx, "bash", "-c", fmt.Sprintf("echo '%s' | sudo -S pacman -S --needed --noconfirm base-devel", sudoPassword))
+	cmd := exec.CommandContext(ctx, "bash", "-c", fmt.Sprintf("%s pacman -S --needed --noconfirm base-devel", privesc.ShellPrefix()))
 	if err := a.runWithProgress(cmd, progressChan, PhasePrerequisites, 0.08, 0.10); err != nil {
 		return fmt.Errorf("failed to install base-devel: %w", err)
 	}
@@ -300,7 +305,7 @@ func (a *ArchDistribution) InstallPrerequisites(ctx context.Context, sudoPasswor
 	return nil
 }
 
-func (a *ArchDistribution) InstallPackages(ctx context.Context, dependencies []deps.Dependency, wm deps.WindowManager, sudoPassword string, reinstallFlags map[string]bool, progressChan chan<- InstallProgressMsg) error {
+func (a *ArchDistribution) InstallPackages(ctx context.Context, dependencies []deps.Dependency, wm deps.WindowManager, reinstallFlags map[string]bool, progressChan chan<- InstallProgressMsg) error {
 	// Phase 1: Check Prerequisites
 	progressChan <- InstallProgressMsg{
 		Phase:      PhasePrerequisites,
@@ -310,7 +315,7 @@ func (a *ArchDistribution) InstallPackages(ctx context.Context, dependencies []d
 		LogOutput:  "Starting prerequisite check...",
 	}
 
-	if err := a.InstallPrerequisites(ctx, sudoPassword, progressChan); err != nil {
+	if err := a.InstallPrerequisites(ctx, progressChan); err != nil {
 		return fmt.Errorf("failed to install prerequisites: %w", err)
 	}
 
@@ -326,7 +331,7 @@ func (a *ArchDistribution) InstallPackages(ctx context.Context, dependencies []d
 			NeedsSudo:  true,
 			LogOutput:  fmt.Sprintf("Installing system packages: %s", strings.Join(systemPkgs, ", ")),
 		}
-		if err := a.installSystemPackages(ctx, systemPkgs, sudoPassword, progressChan); err != nil {
+		if err := a.installSystemPackages(ctx, systemPkgs, progressChan); err != nil {
 			return fmt.Errorf("failed to install system packages: %w", err)
 		}
 	}
@@ -340,7 +345,7 @@ func (a *ArchDistribution) InstallPackages(ctx context.Context, dependencies []d
 			IsComplete: false,
 			LogOutput:  fmt.Sprintf("Installing AUR packages: %s", strings.Join(aurPkgs, ", ")),
 		}
-		if err := a.installAURPackages(ctx, aurPkgs, sudoPassword, progressChan); err != nil {
+		if err := a.installAURPackages(ctx, aurPkgs, progressChan); err != nil {
 			return fmt.Errorf("failed to install AUR packages: %w", err)
 		}
 	}
@@ -354,7 +359,7 @@ func (a *ArchDistribution) InstallPackages(ctx context.Context, dependencies []d
 			IsComplete: false,
 			LogOutput:  fmt.Sprintf("Building from source: %s", strings.Join(manualPkgs, ", ")),
 		}
-		if err := a.InstallManualPackages(ctx, manualPkgs, sudoPassword, progressChan); err != nil {
+		if err := a.InstallManualPackages(ctx, manualPkgs, progressChan); err != nil {
 			return fmt.Errorf("failed to install manual packages: %w", err)
 		}
 	}
@@ -418,7 +423,7 @@ func (a *ArchDistribution) categorizePackages(dependencies []deps.Dependency, wm
 	return systemPkgs, aurPkgs, manualPkgs
 }
 
-func (a *ArchDistribution) installSystemPackages(ctx context.Context, packages []string, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (a *ArchDistribution) installSystemPackages(ctx context.Context, packages []string, progressChan chan<- InstallProgressMsg) error {
 	if len(packages) == 0 {
 		return nil
 	}
@@ -437,12 +442,12 @@ func (a *ArchDistribution) installSystemPackages(ctx context.Context, packages [
 		CommandInfo: fmt.Sprintf("sudo %s", strings.Join(args, " ")),
 	}
 
-	cmdStr := fmt.Sprintf("echo '%s' | sudo -S %s", sudoPassword, strings.Join(args, " "))
+	cmdStr := fmt.Sprintf("%s %s", privesc.ShellPrefix(), strings.Join(args, " "))
 	cmd := exec.CommandContext(ctx, "bash", "-c", cmdStr)
 	return a.runWithProgress(cmd, progressChan, PhaseSystemPackages, 0.40, 0.60)
 }
 
-func (a *ArchDistribution) installAURPackages(ctx context.Context, packages []string, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (a *ArchDistribution) installAURPackages(ctx context.Context, packages []string, progressChan chan<- InstallProgressMsg) error {
 	if len(packages) == 0 {
 		return nil
 	}
@@ -470,7 +475,7 @@ func (a *ArchDistribution) installAURPackages(ctx context.Context, packages []st
 			CommandInfo: "Reinstalling prerequisite AUR package for quickshell",
 		}
 
-		if err := a.installSingleAURPackage(ctx, "google-breakpad", sudoPassword, progressChan, 0.63, 0.65); err != nil {
+		if err := a.installSingleAURPackage(ctx, "google-breakpad", progressChan, 0.63, 0.65); err != nil {
 			return fmt.Errorf("failed to reinstall google-breakpad prerequisite for quickshell: %w", err)
 		}
 	}
@@ -486,7 +491,7 @@ func (a *ArchDistribution) installAURPackages(ctx context.Context, packages []st
 				CommandInfo: "Installing prerequisite for niri-git",
 			}
 
-			if err := a.installSingleAURPackage(ctx, "makepkg-git-lfs-proto", sudoPassword, progressChan, 0.65, 0.67); err != nil {
+			if err := a.installSingleAURPackage(ctx, "makepkg-git-lfs-proto", progressChan, 0.65, 0.67); err != nil {
 				return fmt.Errorf("failed to install makepkg-git-lfs-proto prerequisite for niri: %w", err)
 			}
 		}
@@ -509,7 +514,7 @@ func (a *ArchDistribution) installAURPackages(ctx context.Context, packages []st
 			CommandInfo: fmt.Sprintf("Building and installing %s", pkg),
 		}
 
-		if err := a.installSingleAURPackage(ctx, pkg, sudoPassword, progressChan, currentProgress, currentProgress+progressStep); err != nil {
+		if err := a.installSingleAURPackage(ctx, pkg, progressChan, currentProgress, currentProgress+progressStep); err != nil {
 			return fmt.Errorf("failed to install AUR package %s: %w", pkg, err)
 		}
 	}
@@ -555,7 +560,7 @@ func (a *ArchDistribution) reorderAURPackages(packages []string) []string {
 	return result
 }
 
-func (a *ArchDistribution) installSingleAURPackage(ctx context.Context, pkg, sudoPassword string, progressChan chan<- InstallProgressMsg, startProgress, endProgress float64) error {
+func (a *ArchDistribution) installSingleAURPackage(ctx context.Context, pkg string, progressChan chan<- InstallProgressMsg, startProgress, endProgress float64) error {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return fmt.Errorf("failed to get user home directory: %w", err)
@@ -651,9 +656,9 @@ func (a *ArchDistribution) installSingleAURPackage(ctx context.Context, pkg, sud
 					deps=$(echo "$deps" | sed 's/google-breakpad//g' | sed 's/  / /g' | sed 's/^ *//g' | sed 's/ *$//g')
 				fi
 				if [ ! -z "$deps" ] && [ "$deps" != " " ]; then
-					echo '%s' | sudo -S pacman -S --needed --noconfirm $deps
+					%s pacman -S --needed --noconfirm $deps
 				fi
-			`, srcinfoPath, pkg, sudoPassword))
+			`, srcinfoPath, pkg, privesc.ShellPrefix()))
 
 		if err := a.runWithProgress(depsCmd, progressChan, PhaseAURPackages, startProgress+0.3*(endProgress-startProgress), startProgress+0.35*(endProgress-startProgress)); err != nil {
 			return fmt.Errorf("FAILED to install runtime dependencies for %s: %w", pkg, err)
@@ -663,9 +668,9 @@ func (a *ArchDistribution) installSingleAURPackage(ctx context.Context, pkg, sud
 			fmt.Sprintf(`
 				makedeps=$(grep -E "^[[:space:]]*makedepends = " "%s" | sed 's/^[[:space:]]*makedepends = //' | tr '\n' ' ')
 				if [ ! -z "$makedeps" ]; then
-					echo '%s' | sudo -S pacman -S --needed --noconfirm $makedeps
+					%s pacman -S --needed --noconfirm $makedeps
 				fi
-			`, srcinfoPath, sudoPassword))
+			`, srcinfoPath, privesc.ShellPrefix()))
 
 		if err := a.runWithProgress(makedepsCmd, progressChan, PhaseAURPackages, startProgress+0.35*(endProgress-startProgress), startProgress+0.4*(endProgress-startProgress)); err != nil {
 			return fmt.Errorf("FAILED to install make dependencies for %s: %w", pkg, err)
@@ -749,7 +754,7 @@ func (a *ArchDistribution) installSingleAURPackage(ctx context.Context, pkg, sud
 	installArgs := []string{"pacman", "-U", "--noconfirm"}
 	installArgs = append(installArgs, files...)
 
-	cmdStr := fmt.Sprintf("echo '%s' | sudo -S %s", sudoPassword, strings.Join(installArgs, " "))
+	cmdStr := fmt.Sprintf("%s %s", privesc.ShellPrefix(), strings.Join(installArgs, " "))
 	installCmd := exec.CommandContext(ctx, "bash", "-c", cmdStr)
 
 	fileNames := make([]string, len(files))
@@ -13,12 +13,36 @@ import (
 	"time"
 
 	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
 	"github.com/AvengeMedia/danklinux/internal/version"
 )
 
 const forceQuickshellGit = false
 const forceDMSGit = false
 
+// shellRef pins the DMS shell config to a tag, branch or pull request instead
+// of the latest release; set through SetShellRef
+var shellRef string
+
+// SetShellRef makes installs clone the DMS shell config at ref, which also
+// switches package-based DMS installs to the git config so the ref applies
+func SetShellRef(ref string) error {
+	if ref == "" {
+		shellRef = ""
+		return nil
+	}
+	if _, err := version.ParseShellRef(ref); err != nil {
+		return err
+	}
+	shellRef = ref
+	return nil
+}
+
+// ShellRef returns the ref set with SetShellRef, empty for the latest release
+func ShellRef() string {
+	return shellRef
+}
+
 // BaseDistribution provides common functionality for all distributions
 type BaseDistribution struct {
 	logChan chan<- string
@@ -514,7 +538,7 @@ func (b *BaseDistribution) runWithProgressStep(cmd *exec.Cmd, progressChan chan<
 }
 
 // installDMSBinary installs the DMS binary from GitHub releases
-func (b *BaseDistribution) installDMSBinary(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (b *BaseDistribution) installDMSBinary(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	b.log("Installing/updating DMS binary...")
 
 	// Detect architecture
@@ -598,7 +622,7 @@ func (b *BaseDistribution) installDMSBinary(ctx context.Context, sudoPassword st
 
 	// Install to /usr/local/bin
 	installCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S cp %s /usr/local/bin/dms", sudoPassword, binaryPath))
+		fmt.Sprintf("%s cp %s /usr/local/bin/dms", privesc.ShellPrefix(), binaryPath))
 	if err := installCmd.Run(); err != nil {
 		return fmt.Errorf("failed to install DMS binary: %w", err)
 	}
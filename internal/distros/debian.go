@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
 )
 
 func init() {
@@ -164,7 +165,7 @@ func (d *DebianDistribution) GetPackageMapping(wm deps.WindowManager) map[string
 	return packages
 }
 
-func (d *DebianDistribution) InstallPrerequisites(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (d *DebianDistribution) InstallPrerequisites(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	progressChan <- InstallProgressMsg{
 		Phase:      PhasePrerequisites,
 		Progress:   0.06,
@@ -173,7 +174,7 @@ func (d *DebianDistribution) InstallPrerequisites(ctx context.Context, sudoPassw
 		LogOutput:  "Updating APT package lists",
 	}
 
-	updateCmd := exec.CommandContext(ctx, "bash", "-c", fmt.Sprintf("echo '%s' | sudo -S apt-get update", sudoPassword))
+	updateCmd := exec.CommandContext(ctx, "bash", "-c", fmt.Sprintf("%s apt-get update", privesc.ShellPrefix()))
 	if err := d.runWithProgress(updateCmd, progressChan, PhasePrerequisites, 0.06, 0.07); err != nil {
 		return fmt.Errorf("failed to update package lists: %w", err)
 	}
@@ -190,7 +191,7 @@ func (d *DebianDistribution) InstallPrerequisites(ctx context.Context, sudoPassw
 
 	checkCmd := exec.CommandContext(ctx, "dpkg", "-l", "build-essential")
 	if err := checkCmd.Run(); err != nil {
-		cmd := exec.CommandContext(ctx, "bash", "-c", fmt.Sprintf("echo '%s' | sudo -S apt-get install -y build-essential", sudoPassword))
+		cmd := exec.CommandContext(ctx, "bash", "-c", fmt.Sprintf("%s apt-get install -y build-essential", privesc.ShellPrefix()))
 		if err := d.runWithProgress(cmd, progressChan, PhasePrerequisites, 0.08, 0.09); err != nil {
 			return fmt.Errorf("failed to install build-essential: %w", err)
 		}
@@ -207,7 +208,7 @@ func (d *DebianDistribution) InstallPrerequisites(ctx context.Context, sudoPassw
 	}
 
 	devToolsCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S apt-get install -y curl wget git cmake ninja-build pkg-config libxcb-cursor-dev", sudoPassword))
+		fmt.Sprintf("%s apt-get install -y curl wget git cmake ninja-build pkg-config libxcb-cursor-dev", privesc.ShellPrefix()))
 	if err := d.runWithProgress(devToolsCmd, progressChan, PhasePrerequisites, 0.10, 0.12); err != nil {
 		return fmt.Errorf("failed to install development tools: %w", err)
 	}
@@ -223,7 +224,7 @@ func (d *DebianDistribution) InstallPrerequisites(ctx context.Context, sudoPassw
 	return nil
 }
 
-func (d *DebianDistribution) InstallPackages(ctx context.Context, dependencies []deps.Dependency, wm deps.WindowManager, sudoPassword string, reinstallFlags map[string]bool, progressChan chan<- InstallProgressMsg) error {
+func (d *DebianDistribution) InstallPackages(ctx context.Context, dependencies []deps.Dependency, wm deps.WindowManager, reinstallFlags map[string]bool, progressChan chan<- InstallProgressMsg) error {
 	progressChan <- InstallProgressMsg{
 		Phase:      PhasePrerequisites,
 		Progress:   0.05,
@@ -232,7 +233,7 @@ func (d *DebianDistribution) InstallPackages(ctx context.Context, dependencies [
 		LogOutput:  "Starting prerequisite check...",
 	}
 
-	if err := d.InstallPrerequisites(ctx, sudoPassword, progressChan); err != nil {
+	if err := d.InstallPrerequisites(ctx, progressChan); err != nil {
 		return fmt.Errorf("failed to install prerequisites: %w", err)
 	}
 
@@ -247,7 +248,7 @@ func (d *DebianDistribution) InstallPackages(ctx context.Context, dependencies [
 			NeedsSudo:  true,
 			LogOutput:  fmt.Sprintf("Installing system packages: %s", strings.Join(systemPkgs, ", ")),
 		}
-		if err := d.installAPTPackages(ctx, systemPkgs, sudoPassword, progressChan); err != nil {
+		if err := d.installAPTPackages(ctx, systemPkgs, progressChan); err != nil {
 			return fmt.Errorf("failed to install APT packages: %w", err)
 		}
 	}
@@ -260,7 +261,7 @@ func (d *DebianDistribution) InstallPackages(ctx context.Context, dependencies [
 			IsComplete: false,
 			LogOutput:  "Installing build tools for manual compilation",
 		}
-		if err := d.installBuildDependencies(ctx, manualPkgs, sudoPassword, progressChan); err != nil {
+		if err := d.installBuildDependencies(ctx, manualPkgs, progressChan); err != nil {
 			return fmt.Errorf("failed to install build dependencies: %w", err)
 		}
 
@@ -271,7 +272,7 @@ func (d *DebianDistribution) InstallPackages(ctx context.Context, dependencies [
 			IsComplete: false,
 			LogOutput:  fmt.Sprintf("Building from source: %s", strings.Join(manualPkgs, ", ")),
 		}
-		if err := d.InstallManualPackages(ctx, manualPkgs, sudoPassword, progressChan); err != nil {
+		if err := d.InstallManualPackages(ctx, manualPkgs, progressChan); err != nil {
 			return fmt.Errorf("failed to install manual packages: %w", err)
 		}
 	}
@@ -323,7 +324,7 @@ func (d *DebianDistribution) categorizePackages(dependencies []deps.Dependency,
 	return systemPkgs, manualPkgs
 }
 
-func (d *DebianDistribution) installAPTPackages(ctx context.Context, packages []string, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (d *DebianDistribution) installAPTPackages(ctx context.Context, packages []string, progressChan chan<- InstallProgressMsg) error {
 	if len(packages) == 0 {
 		return nil
 	}
@@ -342,12 +343,12 @@ func (d *DebianDistribution) installAPTPackages(ctx context.Context, packages []
 		CommandInfo: fmt.Sprintf("sudo %s", strings.Join(args, " ")),
 	}
 
-	cmdStr := fmt.Sprintf("echo '%s' | sudo -S %s", sudoPassword, strings.Join(args, " "))
+	cmdStr := fmt.Sprintf("%s %s", privesc.ShellPrefix(), strings.Join(args, " "))
 	cmd := exec.CommandContext(ctx, "bash", "-c", cmdStr)
 	return d.runWithProgress(cmd, progressChan, PhaseSystemPackages, 0.40, 0.60)
 }
 
-func (d *DebianDistribution) installBuildDependencies(ctx context.Context, manualPkgs []string, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (d *DebianDistribution) installBuildDependencies(ctx context.Context, manualPkgs []string, progressChan chan<- InstallProgressMsg) error {
 	buildDeps := make(map[string]bool)
 
 	for _, pkg := range manualPkgs {
@@ -401,11 +402,11 @@ func (d *DebianDistribution) installBuildDependencies(ctx context.Context, manua
 	for _, pkg := range manualPkgs {
 		switch pkg {
 		case "niri", "matugen":
-			if err := d.installRust(ctx, sudoPassword, progressChan); err != nil {
+			if err := d.installRust(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install Rust: %w", err)
 			}
 		case "cliphist", "dgop":
-			if err := d.installGo(ctx, sudoPassword, progressChan); err != nil {
+			if err := d.installGo(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install Go: %w", err)
 			}
 		}
@@ -423,12 +424,12 @@ func (d *DebianDistribution) installBuildDependencies(ctx context.Context, manua
 	args := []string{"apt-get", "install", "-y"}
 	args = append(args, depList...)
 
-	cmdStr := fmt.Sprintf("echo '%s' | sudo -S %s", sudoPassword, strings.Join(args, " "))
+	cmdStr := fmt.Sprintf("%s %s", privesc.ShellPrefix(), strings.Join(args, " "))
 	cmd := exec.CommandContext(ctx, "bash", "-c", cmdStr)
 	return d.runWithProgress(cmd, progressChan, PhaseSystemPackages, 0.80, 0.82)
 }
 
-func (d *DebianDistribution) installRust(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (d *DebianDistribution) installRust(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	if d.commandExists("cargo") {
 		return nil
 	}
@@ -443,7 +444,7 @@ func (d *DebianDistribution) installRust(ctx context.Context, sudoPassword strin
 	}
 
 	rustupInstallCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S apt-get install -y rustup", sudoPassword))
+		fmt.Sprintf("%s apt-get install -y rustup", privesc.ShellPrefix()))
 	if err := d.runWithProgress(rustupInstallCmd, progressChan, PhaseSystemPackages, 0.82, 0.83); err != nil {
 		return fmt.Errorf("failed to install rustup: %w", err)
 	}
@@ -468,7 +469,7 @@ func (d *DebianDistribution) installRust(ctx context.Context, sudoPassword strin
 	return nil
 }
 
-func (d *DebianDistribution) installGo(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (d *DebianDistribution) installGo(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	if d.commandExists("go") {
 		return nil
 	}
@@ -483,11 +484,11 @@ func (d *DebianDistribution) installGo(ctx context.Context, sudoPassword string,
 	}
 
 	installCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S apt-get install -y golang-go", sudoPassword))
+		fmt.Sprintf("%s apt-get install -y golang-go", privesc.ShellPrefix()))
 	return d.runWithProgress(installCmd, progressChan, PhaseSystemPackages, 0.87, 0.90)
 }
 
-func (d *DebianDistribution) installGhosttyDebian(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (d *DebianDistribution) installGhosttyDebian(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	d.log("Installing Ghostty using Debian installer script...")
 
 	progressChan <- InstallProgressMsg{
@@ -501,7 +502,7 @@ func (d *DebianDistribution) installGhosttyDebian(ctx context.Context, sudoPassw
 	}
 
 	installCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S /bin/bash -c \"$(curl -fsSL https://raw.githubusercontent.com/mkasberg/ghostty-ubuntu/HEAD/install.sh)\"", sudoPassword))
+		fmt.Sprintf("%s /bin/bash -c \"$(curl -fsSL https://raw.githubusercontent.com/mkasberg/ghostty-ubuntu/HEAD/install.sh)\"", privesc.ShellPrefix()))
 
 	if err := d.runWithProgress(installCmd, progressChan, PhaseSystemPackages, 0.1, 0.9); err != nil {
 		return fmt.Errorf("failed to install Ghostty: %w", err)
@@ -511,7 +512,7 @@ func (d *DebianDistribution) installGhosttyDebian(ctx context.Context, sudoPassw
 	return nil
 }
 
-func (d *DebianDistribution) InstallManualPackages(ctx context.Context, packages []string, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (d *DebianDistribution) InstallManualPackages(ctx context.Context, packages []string, progressChan chan<- InstallProgressMsg) error {
 	if len(packages) == 0 {
 		return nil
 	}
@@ -521,11 +522,11 @@ func (d *DebianDistribution) InstallManualPackages(ctx context.Context, packages
 	for _, pkg := range packages {
 		switch pkg {
 		case "ghostty":
-			if err := d.installGhosttyDebian(ctx, sudoPassword, progressChan); err != nil {
+			if err := d.installGhosttyDebian(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install ghostty: %w", err)
 			}
 		default:
-			if err := d.ManualPackageInstaller.InstallManualPackages(ctx, []string{pkg}, sudoPassword, progressChan); err != nil {
+			if err := d.ManualPackageInstaller.InstallManualPackages(ctx, []string{pkg}, progressChan); err != nil {
 				return fmt.Errorf("failed to install %s: %w", pkg, err)
 			}
 		}
@@ -0,0 +1,214 @@
+package distros
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+)
+
+// EnvironmentKind describes where the installer is running
+type EnvironmentKind string
+
+const (
+	EnvironmentBareMetal EnvironmentKind = "bare-metal"
+	EnvironmentContainer EnvironmentKind = "container"
+	EnvironmentWSL       EnvironmentKind = "wsl"
+	EnvironmentVM        EnvironmentKind = "vm"
+)
+
+// Environment holds the detected runtime environment
+type Environment struct {
+	Kind   EnvironmentKind
+	Name   string // e.g. docker, podman, kvm, WSL2
+	HasDRM bool   // a /dev/dri card node exists
+}
+
+// Headless reports whether a Wayland compositor can't run here: containers,
+// WSL, and VMs without a DRM device
+func (e Environment) Headless() bool {
+	switch e.Kind {
+	case EnvironmentContainer, EnvironmentWSL:
+		return true
+	case EnvironmentVM:
+		return !e.HasDRM
+	}
+	return false
+}
+
+// Guidance explains what the installer does differently in a headless environment
+func (e Environment) Guidance() []string {
+	if !e.Headless() {
+		return nil
+	}
+
+	var where string
+	switch e.Kind {
+	case EnvironmentContainer:
+		where = "a container"
+	case EnvironmentWSL:
+		where = "WSL"
+	default:
+		where = "a virtual machine without a GPU/DRM device"
+	}
+	if e.Name != "" {
+		where += " (" + e.Name + ")"
+	}
+
+	return []string{
+		"Running inside " + where + ": no seat or DRM device is available for a Wayland compositor.",
+		"The compositor, screenshot tools and night mode (gamma) steps are skipped.",
+		"dms, matugen, dgop and the configuration files are still installed so they can be used or copied to a real session.",
+	}
+}
+
+// DetectEnvironment checks for containers, WSL and virtual machines
+func DetectEnvironment() Environment {
+	return detectEnvironment("/", os.Getenv)
+}
+
+func detectEnvironment(root string, getenv func(string) string) Environment {
+	env := Environment{Kind: EnvironmentBareMetal}
+
+	cards, _ := filepath.Glob(filepath.Join(root, "dev", "dri", "card*"))
+	env.HasDRM = len(cards) > 0
+
+	if name := detectContainer(root, getenv); name != "" {
+		env.Kind = EnvironmentContainer
+		env.Name = name
+		return env
+	}
+
+	if name := detectWSL(root, getenv); name != "" {
+		env.Kind = EnvironmentWSL
+		env.Name = name
+		return env
+	}
+
+	if name := detectVM(root); name != "" {
+		env.Kind = EnvironmentVM
+		env.Name = name
+	}
+
+	return env
+}
+
+func detectContainer(root string, getenv func(string) string) string {
+	if name := getenv("container"); name != "" {
+		return name
+	}
+	if fileExists(filepath.Join(root, ".dockerenv")) {
+		return "docker"
+	}
+	if fileExists(filepath.Join(root, "run", ".containerenv")) {
+		return "podman"
+	}
+
+	if data, err := os.ReadFile(filepath.Join(root, "proc", "1", "cgroup")); err == nil {
+		cgroup := string(data)
+		for _, name := range []string{"docker", "kubepods", "lxc", "containerd"} {
+			if strings.Contains(cgroup, name) {
+				return name
+			}
+		}
+	}
+
+	return ""
+}
+
+func detectWSL(root string, getenv func(string) string) string {
+	if getenv("WSL_DISTRO_NAME") != "" || getenv("WSL_INTEROP") != "" {
+		return "WSL"
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "proc", "version"))
+	if err != nil {
+		return ""
+	}
+	version := strings.ToLower(string(data))
+	if strings.Contains(version, "microsoft") || strings.Contains(version, "wsl") {
+		if strings.Contains(version, "wsl2") {
+			return "WSL2"
+		}
+		return "WSL"
+	}
+	return ""
+}
+
+func detectVM(root string) string {
+	if root == "/" {
+		if out, err := exec.Command("systemd-detect-virt", "--vm").Output(); err == nil {
+			if name := strings.TrimSpace(string(out)); name != "" && name != "none" {
+				return name
+			}
+		}
+	}
+
+	vendors := []struct {
+		marker string
+		name   string
+	}{
+		{"qemu", "qemu"},
+		{"kvm", "kvm"},
+		{"vmware", "vmware"},
+		{"virtualbox", "oracle"},
+		{"innotek", "oracle"},
+		{"microsoft corporation", "microsoft"},
+		{"xen", "xen"},
+		{"parallels", "parallels"},
+	}
+
+	for _, file := range []string{"sys_vendor", "product_name"} {
+		data, err := os.ReadFile(filepath.Join(root, "sys", "class", "dmi", "id", file))
+		if err != nil {
+			continue
+		}
+		value := strings.ToLower(strings.TrimSpace(string(data)))
+		for _, vendor := range vendors {
+			if strings.Contains(value, vendor.marker) {
+				return vendor.name
+			}
+		}
+	}
+
+	return ""
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// headlessSkippedDependencies need a running compositor or graphical session
+var headlessSkippedDependencies = map[string]bool{
+	"niri":                   true,
+	"hyprland":               true,
+	"hyprctl":                true,
+	"hyprpicker":             true,
+	"grim":                   true,
+	"slurp":                  true,
+	"grimblast":              true,
+	"jq":                     true,
+	"xwayland-satellite":     true,
+	"quickshell":             true,
+	"ghostty":                true,
+	"kitty":                  true,
+	"alacritty":              true,
+	"xdg-desktop-portal-gtk": true,
+	"mate-polkit":            true,
+}
+
+// FilterHeadlessDependencies drops compositor and GUI-only dependencies,
+// keeping the pieces that are useful without a graphical session
+func FilterHeadlessDependencies(dependencies []deps.Dependency) []deps.Dependency {
+	filtered := make([]deps.Dependency, 0, len(dependencies))
+	for _, dep := range dependencies {
+		if headlessSkippedDependencies[dep.Name] {
+			continue
+		}
+		filtered = append(filtered, dep)
+	}
+	return filtered
+}
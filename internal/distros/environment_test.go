@@ -0,0 +1,116 @@
+package distros
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func noEnv(string) string { return "" }
+
+func TestDetectEnvironment(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(root string)
+		getenv   func(string) string
+		kind     EnvironmentKind
+		headless bool
+	}{
+		{
+			name:   "bare metal",
+			setup:  func(root string) { writeTestFile(t, filepath.Join(root, "dev", "dri", "card0"), "") },
+			getenv: noEnv,
+			kind:   EnvironmentBareMetal,
+		},
+		{
+			name:     "docker",
+			setup:    func(root string) { writeTestFile(t, filepath.Join(root, ".dockerenv"), "") },
+			getenv:   noEnv,
+			kind:     EnvironmentContainer,
+			headless: true,
+		},
+		{
+			name:  "systemd-nspawn",
+			setup: func(root string) {},
+			getenv: func(key string) string {
+				if key == "container" {
+					return "systemd-nspawn"
+				}
+				return ""
+			},
+			kind:     EnvironmentContainer,
+			headless: true,
+		},
+		{
+			name: "wsl",
+			setup: func(root string) {
+				writeTestFile(t, filepath.Join(root, "proc", "version"), "Linux version 5.15.90.1-microsoft-standard-WSL2")
+			},
+			getenv:   noEnv,
+			kind:     EnvironmentWSL,
+			headless: true,
+		},
+		{
+			name: "vm without drm",
+			setup: func(root string) {
+				writeTestFile(t, filepath.Join(root, "sys", "class", "dmi", "id", "sys_vendor"), "QEMU\n")
+			},
+			getenv:   noEnv,
+			kind:     EnvironmentVM,
+			headless: true,
+		},
+		{
+			name: "vm with virtio gpu",
+			setup: func(root string) {
+				writeTestFile(t, filepath.Join(root, "sys", "class", "dmi", "id", "sys_vendor"), "QEMU\n")
+				writeTestFile(t, filepath.Join(root, "dev", "dri", "card0"), "")
+			},
+			getenv: noEnv,
+			kind:   EnvironmentVM,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			tt.setup(root)
+
+			env := detectEnvironment(root, tt.getenv)
+			if env.Kind != tt.kind {
+				t.Errorf("Expected kind %s, got %s", tt.kind, env.Kind)
+			}
+			if env.Headless() != tt.headless {
+				t.Errorf("Expected headless=%v, got %v", tt.headless, env.Headless())
+			}
+			if tt.headless && len(env.Guidance()) == 0 {
+				t.Error("Expected guidance for headless environment")
+			}
+		})
+	}
+}
+
+func TestFilterHeadlessDependencies(t *testing.T) {
+	filtered := FilterHeadlessDependencies([]deps.Dependency{
+		{Name: "dms (DankMaterialShell)"},
+		{Name: "niri"},
+		{Name: "ghostty"},
+		{Name: "matugen"},
+		{Name: "grim"},
+	})
+
+	if len(filtered) != 2 || filtered[0].Name != "dms (DankMaterialShell)" || filtered[1].Name != "matugen" {
+		t.Errorf("Unexpected filtered dependencies: %+v", filtered)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
 )
 
 func init() {
@@ -178,6 +179,10 @@ func (f *FedoraDistribution) getQuickshellMapping(variant deps.PackageVariant) P
 }
 
 func (f *FedoraDistribution) getDmsMapping(variant deps.PackageVariant) PackageMapping {
+	if shellRef != "" {
+		return PackageMapping{Name: "dms", Repository: RepoTypeManual, BuildFunc: "installDankMaterialShell"}
+	}
+
 	if variant == deps.VariantGit {
 		return PackageMapping{Name: "dms-git", Repository: RepoTypeCOPR, RepoURL: "avengemedia/dms-git"}
 	}
@@ -241,7 +246,7 @@ func (f *FedoraDistribution) getPrerequisites() []string {
 	}
 }
 
-func (f *FedoraDistribution) InstallPrerequisites(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (f *FedoraDistribution) InstallPrerequisites(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	prerequisites := f.getPrerequisites()
 	var missingPkgs []string
 
@@ -286,7 +291,7 @@ func (f *FedoraDistribution) InstallPrerequisites(ctx context.Context, sudoPassw
 
 	args := []string{"dnf", "install", "-y"}
 	args = append(args, missingPkgs...)
-	cmdStr := fmt.Sprintf("echo '%s' | sudo -S %s", sudoPassword, strings.Join(args, " "))
+	cmdStr := fmt.Sprintf("%s %s", privesc.ShellPrefix(), strings.Join(args, " "))
 	cmd := exec.CommandContext(ctx, "bash", "-c", cmdStr)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -299,7 +304,7 @@ func (f *FedoraDistribution) InstallPrerequisites(ctx context.Context, sudoPassw
 	return nil
 }
 
-func (f *FedoraDistribution) InstallPackages(ctx context.Context, dependencies []deps.Dependency, wm deps.WindowManager, sudoPassword string, reinstallFlags map[string]bool, progressChan chan<- InstallProgressMsg) error {
+func (f *FedoraDistribution) InstallPackages(ctx context.Context, dependencies []deps.Dependency, wm deps.WindowManager, reinstallFlags map[string]bool, progressChan chan<- InstallProgressMsg) error {
 	// Phase 1: Check Prerequisites
 	progressChan <- InstallProgressMsg{
 		Phase:      PhasePrerequisites,
@@ -309,7 +314,7 @@ func (f *FedoraDistribution) InstallPackages(ctx context.Context, dependencies [
 		LogOutput:  "Starting prerequisite check...",
 	}
 
-	if err := f.InstallPrerequisites(ctx, sudoPassword, progressChan); err != nil {
+	if err := f.InstallPrerequisites(ctx, progressChan); err != nil {
 		return fmt.Errorf("failed to install prerequisites: %w", err)
 	}
 
@@ -324,7 +329,7 @@ func (f *FedoraDistribution) InstallPackages(ctx context.Context, dependencies [
 			IsComplete: false,
 			LogOutput:  "Setting up COPR repositories for additional packages",
 		}
-		if err := f.enableCOPRRepos(ctx, coprPkgs, sudoPassword, progressChan); err != nil {
+		if err := f.enableCOPRRepos(ctx, coprPkgs, progressChan); err != nil {
 			return fmt.Errorf("failed to enable COPR repositories: %w", err)
 		}
 	}
@@ -339,7 +344,7 @@ func (f *FedoraDistribution) InstallPackages(ctx context.Context, dependencies [
 			NeedsSudo:  true,
 			LogOutput:  fmt.Sprintf("Installing system packages: %s", strings.Join(dnfPkgs, ", ")),
 		}
-		if err := f.installDNFPackages(ctx, dnfPkgs, sudoPassword, progressChan); err != nil {
+		if err := f.installDNFPackages(ctx, dnfPkgs, progressChan); err != nil {
 			return fmt.Errorf("failed to install DNF packages: %w", err)
 		}
 	}
@@ -354,7 +359,7 @@ func (f *FedoraDistribution) InstallPackages(ctx context.Context, dependencies [
 			IsComplete: false,
 			LogOutput:  fmt.Sprintf("Installing COPR packages: %s", strings.Join(coprPkgNames, ", ")),
 		}
-		if err := f.installCOPRPackages(ctx, coprPkgNames, sudoPassword, progressChan); err != nil {
+		if err := f.installCOPRPackages(ctx, coprPkgNames, progressChan); err != nil {
 			return fmt.Errorf("failed to install COPR packages: %w", err)
 		}
 	}
@@ -368,7 +373,7 @@ func (f *FedoraDistribution) InstallPackages(ctx context.Context, dependencies [
 			IsComplete: false,
 			LogOutput:  fmt.Sprintf("Building from source: %s", strings.Join(manualPkgs, ", ")),
 		}
-		if err := f.InstallManualPackages(ctx, manualPkgs, sudoPassword, progressChan); err != nil {
+		if err := f.InstallManualPackages(ctx, manualPkgs, progressChan); err != nil {
 			return fmt.Errorf("failed to install manual packages: %w", err)
 		}
 	}
@@ -439,7 +444,7 @@ func (f *FedoraDistribution) extractPackageNames(packages []PackageMapping) []st
 	return names
 }
 
-func (f *FedoraDistribution) enableCOPRRepos(ctx context.Context, coprPkgs []PackageMapping, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (f *FedoraDistribution) enableCOPRRepos(ctx context.Context, coprPkgs []PackageMapping, progressChan chan<- InstallProgressMsg) error {
 	enabledRepos := make(map[string]bool)
 
 	for _, pkg := range coprPkgs {
@@ -455,7 +460,7 @@ func (f *FedoraDistribution) enableCOPRRepos(ctx context.Context, coprPkgs []Pac
 			}
 
 			cmd := exec.CommandContext(ctx, "bash", "-c",
-				fmt.Sprintf("echo '%s' | sudo -S dnf copr enable -y %s 2>&1", sudoPassword, pkg.RepoURL))
+				fmt.Sprintf("%s dnf copr enable -y %s 2>&1", privesc.ShellPrefix(), pkg.RepoURL))
 			output, err := cmd.CombinedOutput()
 			if err != nil {
 				f.logError(fmt.Sprintf("failed to enable COPR repo %s", pkg.RepoURL), err)
@@ -478,7 +483,7 @@ func (f *FedoraDistribution) enableCOPRRepos(ctx context.Context, coprPkgs []Pac
 				}
 
 				priorityCmd := exec.CommandContext(ctx, "bash", "-c",
-					fmt.Sprintf("echo '%s' | sudo -S bash -c 'echo \"priority=1\" | tee -a /etc/yum.repos.d/_copr:copr.fedorainfracloud.org:yalter:niri-git.repo' 2>&1", sudoPassword))
+					fmt.Sprintf("%s bash -c 'echo \"priority=1\" | tee -a /etc/yum.repos.d/_copr:copr.fedorainfracloud.org:yalter:niri-git.repo' 2>&1", privesc.ShellPrefix()))
 				priorityOutput, err := priorityCmd.CombinedOutput()
 				if err != nil {
 					f.logError("failed to set niri COPR repo priority", err)
@@ -493,7 +498,7 @@ func (f *FedoraDistribution) enableCOPRRepos(ctx context.Context, coprPkgs []Pac
 	return nil
 }
 
-func (f *FedoraDistribution) installDNFPackages(ctx context.Context, packages []string, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (f *FedoraDistribution) installDNFPackages(ctx context.Context, packages []string, progressChan chan<- InstallProgressMsg) error {
 	if len(packages) == 0 {
 		return nil
 	}
@@ -512,12 +517,12 @@ func (f *FedoraDistribution) installDNFPackages(ctx context.Context, packages []
 		CommandInfo: fmt.Sprintf("sudo %s", strings.Join(args, " ")),
 	}
 
-	cmdStr := fmt.Sprintf("echo '%s' | sudo -S %s", sudoPassword, strings.Join(args, " "))
+	cmdStr := fmt.Sprintf("%s %s", privesc.ShellPrefix(), strings.Join(args, " "))
 	cmd := exec.CommandContext(ctx, "bash", "-c", cmdStr)
 	return f.runWithProgress(cmd, progressChan, PhaseSystemPackages, 0.40, 0.60)
 }
 
-func (f *FedoraDistribution) installCOPRPackages(ctx context.Context, packages []string, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (f *FedoraDistribution) installCOPRPackages(ctx context.Context, packages []string, progressChan chan<- InstallProgressMsg) error {
 	if len(packages) == 0 {
 		return nil
 	}
@@ -536,7 +541,7 @@ func (f *FedoraDistribution) installCOPRPackages(ctx context.Context, packages [
 		CommandInfo: fmt.Sprintf("sudo %s", strings.Join(args, " ")),
 	}
 
-	cmdStr := fmt.Sprintf("echo '%s' | sudo -S %s", sudoPassword, strings.Join(args, " "))
+	cmdStr := fmt.Sprintf("%s %s", privesc.ShellPrefix(), strings.Join(args, " "))
 	cmd := exec.CommandContext(ctx, "bash", "-c", cmdStr)
 	return f.runWithProgress(cmd, progressChan, PhaseAURPackages, 0.70, 0.85)
 }
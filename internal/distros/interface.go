@@ -87,13 +87,13 @@ type Distribution interface {
 	DetectDependenciesWithTerminal(ctx context.Context, wm deps.WindowManager, terminal deps.Terminal) ([]deps.Dependency, error)
 
 	// Package Installation
-	InstallPackages(ctx context.Context, dependencies []deps.Dependency, wm deps.WindowManager, sudoPassword string, reinstallFlags map[string]bool, progressChan chan<- InstallProgressMsg) error
+	InstallPackages(ctx context.Context, dependencies []deps.Dependency, wm deps.WindowManager, reinstallFlags map[string]bool, progressChan chan<- InstallProgressMsg) error
 
 	// Package Mapping
 	GetPackageMapping(wm deps.WindowManager) map[string]PackageMapping
 
 	// Prerequisites
-	InstallPrerequisites(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error
+	InstallPrerequisites(ctx context.Context, progressChan chan<- InstallProgressMsg) error
 }
 
 // DistroConfig holds configuration for a distribution
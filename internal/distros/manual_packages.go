@@ -7,6 +7,9 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/privesc"
+	"github.com/AvengeMedia/danklinux/internal/version"
 )
 
 // ManualPackageInstaller provides methods for installing packages from source
@@ -15,7 +18,7 @@ type ManualPackageInstaller struct {
 }
 
 // InstallManualPackages handles packages that need manual building
-func (m *ManualPackageInstaller) InstallManualPackages(ctx context.Context, packages []string, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (m *ManualPackageInstaller) InstallManualPackages(ctx context.Context, packages []string, progressChan chan<- InstallProgressMsg) error {
 	if len(packages) == 0 {
 		return nil
 	}
@@ -25,47 +28,47 @@ func (m *ManualPackageInstaller) InstallManualPackages(ctx context.Context, pack
 	for _, pkg := range packages {
 		switch pkg {
 		case "dms (DankMaterialShell)", "dms":
-			if err := m.installDankMaterialShell(ctx, sudoPassword, progressChan); err != nil {
+			if err := m.installDankMaterialShell(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install DankMaterialShell: %w", err)
 			}
 		case "dgop":
-			if err := m.installDgop(ctx, sudoPassword, progressChan); err != nil {
+			if err := m.installDgop(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install dgop: %w", err)
 			}
 		case "grimblast":
-			if err := m.installGrimblast(ctx, sudoPassword, progressChan); err != nil {
+			if err := m.installGrimblast(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install grimblast: %w", err)
 			}
 		case "niri":
-			if err := m.installNiri(ctx, sudoPassword, progressChan); err != nil {
+			if err := m.installNiri(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install niri: %w", err)
 			}
 		case "quickshell":
-			if err := m.installQuickshell(ctx, sudoPassword, progressChan); err != nil {
+			if err := m.installQuickshell(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install quickshell: %w", err)
 			}
 		case "hyprland":
-			if err := m.installHyprland(ctx, sudoPassword, progressChan); err != nil {
+			if err := m.installHyprland(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install hyprland: %w", err)
 			}
 		case "hyprpicker":
-			if err := m.installHyprpicker(ctx, sudoPassword, progressChan); err != nil {
+			if err := m.installHyprpicker(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install hyprpicker: %w", err)
 			}
 		case "ghostty":
-			if err := m.installGhostty(ctx, sudoPassword, progressChan); err != nil {
+			if err := m.installGhostty(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install ghostty: %w", err)
 			}
 		case "matugen":
-			if err := m.installMatugen(ctx, sudoPassword, progressChan); err != nil {
+			if err := m.installMatugen(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install matugen: %w", err)
 			}
 		case "cliphist":
-			if err := m.installCliphist(ctx, sudoPassword, progressChan); err != nil {
+			if err := m.installCliphist(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install cliphist: %w", err)
 			}
 		case "xwayland-satellite":
-			if err := m.installXwaylandSatellite(ctx, sudoPassword, progressChan); err != nil {
+			if err := m.installXwaylandSatellite(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install xwayland-satellite: %w", err)
 			}
 		default:
@@ -76,7 +79,7 @@ func (m *ManualPackageInstaller) InstallManualPackages(ctx context.Context, pack
 	return nil
 }
 
-func (m *ManualPackageInstaller) installDgop(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (m *ManualPackageInstaller) installDgop(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	m.log("Installing dgop from source...")
 
 	homeDir := os.Getenv("HOME")
@@ -125,7 +128,7 @@ func (m *ManualPackageInstaller) installDgop(ctx context.Context, sudoPassword s
 		CommandInfo: "sudo make install",
 	}
 
-	installCmd := exec.CommandContext(ctx, "bash", "-c", fmt.Sprintf("echo '%s' | sudo -S make install", sudoPassword))
+	installCmd := exec.CommandContext(ctx, "bash", "-c", fmt.Sprintf("%s make install", privesc.ShellPrefix()))
 	installCmd.Dir = tmpDir
 	if err := installCmd.Run(); err != nil {
 		m.logError("failed to install dgop", err)
@@ -136,7 +139,7 @@ func (m *ManualPackageInstaller) installDgop(ctx context.Context, sudoPassword s
 	return nil
 }
 
-func (m *ManualPackageInstaller) installGrimblast(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (m *ManualPackageInstaller) installGrimblast(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	m.log("Installing grimblast script for Hyprland...")
 
 	progressChan <- InstallProgressMsg{
@@ -180,7 +183,7 @@ func (m *ManualPackageInstaller) installGrimblast(ctx context.Context, sudoPassw
 	}
 
 	installCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S cp %s /usr/local/bin/grimblast", sudoPassword, tmpPath))
+		fmt.Sprintf("%s cp %s /usr/local/bin/grimblast", privesc.ShellPrefix(), tmpPath))
 	if err := installCmd.Run(); err != nil {
 		m.logError("failed to install grimblast", err)
 		return fmt.Errorf("failed to install grimblast: %w", err)
@@ -192,7 +195,7 @@ func (m *ManualPackageInstaller) installGrimblast(ctx context.Context, sudoPassw
 	return nil
 }
 
-func (m *ManualPackageInstaller) installNiri(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (m *ManualPackageInstaller) installNiri(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	m.log("Installing niri from source...")
 
 	homeDir, _ := os.UserHomeDir()
@@ -252,7 +255,7 @@ func (m *ManualPackageInstaller) installNiri(ctx context.Context, sudoPassword s
 	}
 
 	installDebCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S dpkg -i %s/target/debian/niri_*.deb", sudoPassword, buildDir))
+		fmt.Sprintf("%s dpkg -i %s/target/debian/niri_*.deb", privesc.ShellPrefix(), buildDir))
 
 	output, err := installDebCmd.CombinedOutput()
 	if err != nil {
@@ -266,7 +269,7 @@ func (m *ManualPackageInstaller) installNiri(ctx context.Context, sudoPassword s
 	return nil
 }
 
-func (m *ManualPackageInstaller) installQuickshell(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (m *ManualPackageInstaller) installQuickshell(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	m.log("Installing quickshell from source...")
 
 	homeDir := os.Getenv("HOME")
@@ -358,7 +361,7 @@ func (m *ManualPackageInstaller) installQuickshell(ctx context.Context, sudoPass
 	}
 
 	installCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("cd %s && echo '%s' | sudo -S cmake --install build", tmpDir, sudoPassword))
+		fmt.Sprintf("cd %s && %s cmake --install build", tmpDir, privesc.ShellPrefix()))
 	if err := installCmd.Run(); err != nil {
 		return fmt.Errorf("failed to install quickshell: %w", err)
 	}
@@ -367,7 +370,7 @@ func (m *ManualPackageInstaller) installQuickshell(ctx context.Context, sudoPass
 	return nil
 }
 
-func (m *ManualPackageInstaller) installHyprland(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (m *ManualPackageInstaller) installHyprland(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	m.log("Installing Hyprland from source...")
 
 	homeDir := os.Getenv("HOME")
@@ -421,7 +424,7 @@ func (m *ManualPackageInstaller) installHyprland(ctx context.Context, sudoPasswo
 	}
 
 	installCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("cd %s && echo '%s' | sudo -S make install", tmpDir, sudoPassword))
+		fmt.Sprintf("cd %s && %s make install", tmpDir, privesc.ShellPrefix()))
 	if err := installCmd.Run(); err != nil {
 		return fmt.Errorf("failed to install Hyprland: %w", err)
 	}
@@ -430,7 +433,7 @@ func (m *ManualPackageInstaller) installHyprland(ctx context.Context, sudoPasswo
 	return nil
 }
 
-func (m *ManualPackageInstaller) installHyprpicker(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (m *ManualPackageInstaller) installHyprpicker(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	m.log("Installing hyprpicker from source...")
 
 	homeDir := os.Getenv("HOME")
@@ -487,7 +490,7 @@ func (m *ManualPackageInstaller) installHyprpicker(ctx context.Context, sudoPass
 	}
 
 	installCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("cd %s && echo '%s' | sudo -S make install", tmpDir, sudoPassword))
+		fmt.Sprintf("cd %s && %s make install", tmpDir, privesc.ShellPrefix()))
 	if err := installCmd.Run(); err != nil {
 		return fmt.Errorf("failed to install hyprpicker: %w", err)
 	}
@@ -496,7 +499,7 @@ func (m *ManualPackageInstaller) installHyprpicker(ctx context.Context, sudoPass
 	return nil
 }
 
-func (m *ManualPackageInstaller) installGhostty(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (m *ManualPackageInstaller) installGhostty(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	m.log("Installing Ghostty from source...")
 
 	homeDir := os.Getenv("HOME")
@@ -553,7 +556,7 @@ func (m *ManualPackageInstaller) installGhostty(ctx context.Context, sudoPasswor
 	}
 
 	installCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S cp %s/zig-out/bin/ghostty /usr/local/bin/", sudoPassword, tmpDir))
+		fmt.Sprintf("%s cp %s/zig-out/bin/ghostty /usr/local/bin/", privesc.ShellPrefix(), tmpDir))
 	if err := installCmd.Run(); err != nil {
 		return fmt.Errorf("failed to install Ghostty: %w", err)
 	}
@@ -562,7 +565,7 @@ func (m *ManualPackageInstaller) installGhostty(ctx context.Context, sudoPasswor
 	return nil
 }
 
-func (m *ManualPackageInstaller) installMatugen(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (m *ManualPackageInstaller) installMatugen(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	m.log("Installing matugen from source...")
 
 	progressChan <- InstallProgressMsg{
@@ -591,15 +594,13 @@ func (m *ManualPackageInstaller) installMatugen(ctx context.Context, sudoPasswor
 		CommandInfo: fmt.Sprintf("sudo cp %s %s", sourcePath, targetPath),
 	}
 
-	copyCmd := exec.CommandContext(ctx, "sudo", "-S", "cp", sourcePath, targetPath)
-	copyCmd.Stdin = strings.NewReader(sudoPassword + "\n")
+	copyCmd := privesc.Command(ctx, "cp", sourcePath, targetPath)
 	if err := copyCmd.Run(); err != nil {
 		return fmt.Errorf("failed to copy matugen to /usr/local/bin: %w", err)
 	}
 
 	// Make it executable
-	chmodCmd := exec.CommandContext(ctx, "sudo", "-S", "chmod", "+x", targetPath)
-	chmodCmd.Stdin = strings.NewReader(sudoPassword + "\n")
+	chmodCmd := privesc.Command(ctx, "chmod", "+x", targetPath)
 	if err := chmodCmd.Run(); err != nil {
 		return fmt.Errorf("failed to make matugen executable: %w", err)
 	}
@@ -608,11 +609,11 @@ func (m *ManualPackageInstaller) installMatugen(ctx context.Context, sudoPasswor
 	return nil
 }
 
-func (m *ManualPackageInstaller) installDankMaterialShell(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (m *ManualPackageInstaller) installDankMaterialShell(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	m.log("Installing DankMaterialShell (DMS)...")
 
 	// Always install/update the DMS binary
-	if err := m.installDMSBinary(ctx, sudoPassword, progressChan); err != nil {
+	if err := m.installDMSBinary(ctx, progressChan); err != nil {
 		m.logError("Failed to install DMS binary", err)
 	}
 
@@ -639,7 +640,13 @@ func (m *ManualPackageInstaller) installDankMaterialShell(ctx context.Context, s
 			return fmt.Errorf("failed to clone DankMaterialShell: %w", err)
 		}
 
-		if !forceDMSGit {
+		if shellRef != "" {
+			checkedOut, err := version.CheckoutShellRef(dmsPath, shellRef)
+			if err != nil {
+				return err
+			}
+			m.log(fmt.Sprintf("Checked out shell ref: %s", checkedOut))
+		} else if !forceDMSGit {
 			fetchCmd := exec.CommandContext(ctx, "git", "-C", dmsPath, "fetch", "--tags")
 			if err := fetchCmd.Run(); err == nil {
 				tagCmd := exec.CommandContext(ctx, "git", "-C", dmsPath, "describe", "--tags", "--abbrev=0", "origin/master")
@@ -664,6 +671,15 @@ func (m *ManualPackageInstaller) installDankMaterialShell(ctx context.Context, s
 			CommandInfo: "git pull in ~/.config/quickshell/dms",
 		}
 
+		if shellRef != "" {
+			if checkedOut, err := version.CheckoutShellRef(dmsPath, shellRef); err != nil {
+				m.logError("Failed to switch DankMaterialShell config ref", err)
+			} else {
+				m.log(fmt.Sprintf("Checked out shell ref: %s", checkedOut))
+			}
+			return nil
+		}
+
 		pullCmd := exec.CommandContext(ctx, "git", "pull")
 		pullCmd.Dir = dmsPath
 		if err := pullCmd.Run(); err != nil {
@@ -676,7 +692,7 @@ func (m *ManualPackageInstaller) installDankMaterialShell(ctx context.Context, s
 	return nil
 }
 
-func (m *ManualPackageInstaller) installCliphist(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (m *ManualPackageInstaller) installCliphist(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	m.log("Installing cliphist from source...")
 
 	progressChan <- InstallProgressMsg{
@@ -705,15 +721,13 @@ func (m *ManualPackageInstaller) installCliphist(ctx context.Context, sudoPasswo
 		CommandInfo: fmt.Sprintf("sudo cp %s %s", sourcePath, targetPath),
 	}
 
-	copyCmd := exec.CommandContext(ctx, "sudo", "-S", "cp", sourcePath, targetPath)
-	copyCmd.Stdin = strings.NewReader(sudoPassword + "\n")
+	copyCmd := privesc.Command(ctx, "cp", sourcePath, targetPath)
 	if err := copyCmd.Run(); err != nil {
 		return fmt.Errorf("failed to copy cliphist to /usr/local/bin: %w", err)
 	}
 
 	// Make it executable
-	chmodCmd := exec.CommandContext(ctx, "sudo", "-S", "chmod", "+x", targetPath)
-	chmodCmd.Stdin = strings.NewReader(sudoPassword + "\n")
+	chmodCmd := privesc.Command(ctx, "chmod", "+x", targetPath)
 	if err := chmodCmd.Run(); err != nil {
 		return fmt.Errorf("failed to make cliphist executable: %w", err)
 	}
@@ -722,7 +736,7 @@ func (m *ManualPackageInstaller) installCliphist(ctx context.Context, sudoPasswo
 	return nil
 }
 
-func (m *ManualPackageInstaller) installXwaylandSatellite(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (m *ManualPackageInstaller) installXwaylandSatellite(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	m.log("Installing xwayland-satellite from source...")
 
 	progressChan <- InstallProgressMsg{
@@ -751,14 +765,12 @@ func (m *ManualPackageInstaller) installXwaylandSatellite(ctx context.Context, s
 		CommandInfo: fmt.Sprintf("sudo cp %s %s", sourcePath, targetPath),
 	}
 
-	copyCmd := exec.CommandContext(ctx, "sudo", "-S", "cp", sourcePath, targetPath)
-	copyCmd.Stdin = strings.NewReader(sudoPassword + "\n")
+	copyCmd := privesc.Command(ctx, "cp", sourcePath, targetPath)
 	if err := copyCmd.Run(); err != nil {
 		return fmt.Errorf("failed to copy xwayland-satellite to /usr/local/bin: %w", err)
 	}
 
-	chmodCmd := exec.CommandContext(ctx, "sudo", "-S", "chmod", "+x", targetPath)
-	chmodCmd.Stdin = strings.NewReader(sudoPassword + "\n")
+	chmodCmd := privesc.Command(ctx, "chmod", "+x", targetPath)
 	if err := chmodCmd.Run(); err != nil {
 		return fmt.Errorf("failed to make xwayland-satellite executable: %w", err)
 	}
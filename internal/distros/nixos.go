@@ -282,7 +282,7 @@ func (n *NixOSDistribution) GetPackageMapping(wm deps.WindowManager) map[string]
 	return packages
 }
 
-func (n *NixOSDistribution) InstallPrerequisites(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (n *NixOSDistribution) InstallPrerequisites(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	progressChan <- InstallProgressMsg{
 		Phase:      PhasePrerequisites,
 		Progress:   0.10,
@@ -293,7 +293,7 @@ func (n *NixOSDistribution) InstallPrerequisites(ctx context.Context, sudoPasswo
 	return nil
 }
 
-func (n *NixOSDistribution) InstallPackages(ctx context.Context, dependencies []deps.Dependency, wm deps.WindowManager, sudoPassword string, reinstallFlags map[string]bool, progressChan chan<- InstallProgressMsg) error {
+func (n *NixOSDistribution) InstallPackages(ctx context.Context, dependencies []deps.Dependency, wm deps.WindowManager, reinstallFlags map[string]bool, progressChan chan<- InstallProgressMsg) error {
 	// Phase 1: Check Prerequisites
 	progressChan <- InstallProgressMsg{
 		Phase:      PhasePrerequisites,
@@ -303,7 +303,7 @@ func (n *NixOSDistribution) InstallPackages(ctx context.Context, dependencies []
 		LogOutput:  "Starting prerequisite check...",
 	}
 
-	if err := n.InstallPrerequisites(ctx, sudoPassword, progressChan); err != nil {
+	if err := n.InstallPrerequisites(ctx, progressChan); err != nil {
 		return fmt.Errorf("failed to install prerequisites: %w", err)
 	}
 
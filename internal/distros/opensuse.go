@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
 )
 
 func init() {
@@ -220,7 +221,7 @@ func (o *OpenSUSEDistribution) getPrerequisites() []string {
 	}
 }
 
-func (o *OpenSUSEDistribution) InstallPrerequisites(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (o *OpenSUSEDistribution) InstallPrerequisites(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	prerequisites := o.getPrerequisites()
 	var missingPkgs []string
 
@@ -265,7 +266,7 @@ func (o *OpenSUSEDistribution) InstallPrerequisites(ctx context.Context, sudoPas
 
 	args := []string{"zypper", "install", "-y"}
 	args = append(args, missingPkgs...)
-	cmdStr := fmt.Sprintf("echo '%s' | sudo -S %s", sudoPassword, strings.Join(args, " "))
+	cmdStr := fmt.Sprintf("%s %s", privesc.ShellPrefix(), strings.Join(args, " "))
 	cmd := exec.CommandContext(ctx, "bash", "-c", cmdStr)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -278,7 +279,7 @@ func (o *OpenSUSEDistribution) InstallPrerequisites(ctx context.Context, sudoPas
 	return nil
 }
 
-func (o *OpenSUSEDistribution) InstallPackages(ctx context.Context, dependencies []deps.Dependency, wm deps.WindowManager, sudoPassword string, reinstallFlags map[string]bool, progressChan chan<- InstallProgressMsg) error {
+func (o *OpenSUSEDistribution) InstallPackages(ctx context.Context, dependencies []deps.Dependency, wm deps.WindowManager, reinstallFlags map[string]bool, progressChan chan<- InstallProgressMsg) error {
 	// Phase 1: Check Prerequisites
 	progressChan <- InstallProgressMsg{
 		Phase:      PhasePrerequisites,
@@ -288,7 +289,7 @@ func (o *OpenSUSEDistribution) InstallPackages(ctx context.Context, dependencies
 		LogOutput:  "Starting prerequisite check...",
 	}
 
-	if err := o.InstallPrerequisites(ctx, sudoPassword, progressChan); err != nil {
+	if err := o.InstallPrerequisites(ctx, progressChan); err != nil {
 		return fmt.Errorf("failed to install prerequisites: %w", err)
 	}
 
@@ -304,7 +305,7 @@ func (o *OpenSUSEDistribution) InstallPackages(ctx context.Context, dependencies
 			NeedsSudo:  true,
 			LogOutput:  fmt.Sprintf("Installing system packages: %s", strings.Join(systemPkgs, ", ")),
 		}
-		if err := o.installZypperPackages(ctx, systemPkgs, sudoPassword, progressChan); err != nil {
+		if err := o.installZypperPackages(ctx, systemPkgs, progressChan); err != nil {
 			return fmt.Errorf("failed to install zypper packages: %w", err)
 		}
 	}
@@ -318,7 +319,7 @@ func (o *OpenSUSEDistribution) InstallPackages(ctx context.Context, dependencies
 			IsComplete: false,
 			LogOutput:  fmt.Sprintf("Building from source: %s", strings.Join(manualPkgs, ", ")),
 		}
-		if err := o.InstallManualPackages(ctx, manualPkgs, sudoPassword, progressChan); err != nil {
+		if err := o.InstallManualPackages(ctx, manualPkgs, progressChan); err != nil {
 			return fmt.Errorf("failed to install manual packages: %w", err)
 		}
 	}
@@ -378,7 +379,7 @@ func (o *OpenSUSEDistribution) categorizePackages(dependencies []deps.Dependency
 	return systemPkgs, manualPkgs
 }
 
-func (o *OpenSUSEDistribution) installZypperPackages(ctx context.Context, packages []string, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (o *OpenSUSEDistribution) installZypperPackages(ctx context.Context, packages []string, progressChan chan<- InstallProgressMsg) error {
 	if len(packages) == 0 {
 		return nil
 	}
@@ -397,13 +398,13 @@ func (o *OpenSUSEDistribution) installZypperPackages(ctx context.Context, packag
 		CommandInfo: fmt.Sprintf("sudo %s", strings.Join(args, " ")),
 	}
 
-	cmdStr := fmt.Sprintf("echo '%s' | sudo -S %s", sudoPassword, strings.Join(args, " "))
+	cmdStr := fmt.Sprintf("%s %s", privesc.ShellPrefix(), strings.Join(args, " "))
 	cmd := exec.CommandContext(ctx, "bash", "-c", cmdStr)
 	return o.runWithProgress(cmd, progressChan, PhaseSystemPackages, 0.40, 0.60)
 }
 
 // installQuickshell overrides the base implementation to set openSUSE-specific CFLAGS
-func (o *OpenSUSEDistribution) installQuickshell(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (o *OpenSUSEDistribution) installQuickshell(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	o.log("Installing quickshell from source (with openSUSE-specific build flags)...")
 
 	homeDir := os.Getenv("HOME")
@@ -515,7 +516,7 @@ func (o *OpenSUSEDistribution) installQuickshell(ctx context.Context, sudoPasswo
 	}
 
 	installCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("cd %s && echo '%s' | sudo -S cmake --install build", tmpDir, sudoPassword))
+		fmt.Sprintf("cd %s && %s cmake --install build", tmpDir, privesc.ShellPrefix()))
 	if err := installCmd.Run(); err != nil {
 		return fmt.Errorf("failed to install quickshell: %w", err)
 	}
@@ -524,7 +525,7 @@ func (o *OpenSUSEDistribution) installQuickshell(ctx context.Context, sudoPasswo
 	return nil
 }
 
-func (o *OpenSUSEDistribution) installRust(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (o *OpenSUSEDistribution) installRust(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	if o.commandExists("cargo") {
 		return nil
 	}
@@ -539,7 +540,7 @@ func (o *OpenSUSEDistribution) installRust(ctx context.Context, sudoPassword str
 	}
 
 	rustupInstallCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S zypper install -y rustup", sudoPassword))
+		fmt.Sprintf("%s zypper install -y rustup", privesc.ShellPrefix()))
 	if err := o.runWithProgress(rustupInstallCmd, progressChan, PhaseSystemPackages, 0.82, 0.83); err != nil {
 		return fmt.Errorf("failed to install rustup: %w", err)
 	}
@@ -565,7 +566,7 @@ func (o *OpenSUSEDistribution) installRust(ctx context.Context, sudoPassword str
 }
 
 // InstallManualPackages overrides the base implementation to use openSUSE-specific builds
-func (o *OpenSUSEDistribution) InstallManualPackages(ctx context.Context, packages []string, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (o *OpenSUSEDistribution) InstallManualPackages(ctx context.Context, packages []string, progressChan chan<- InstallProgressMsg) error {
 	if len(packages) == 0 {
 		return nil
 	}
@@ -575,7 +576,7 @@ func (o *OpenSUSEDistribution) InstallManualPackages(ctx context.Context, packag
 	// Install Rust if needed for matugen
 	for _, pkg := range packages {
 		if pkg == "matugen" {
-			if err := o.installRust(ctx, sudoPassword, progressChan); err != nil {
+			if err := o.installRust(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install Rust: %w", err)
 			}
 			break
@@ -584,12 +585,12 @@ func (o *OpenSUSEDistribution) InstallManualPackages(ctx context.Context, packag
 
 	for _, pkg := range packages {
 		if pkg == "quickshell" {
-			if err := o.installQuickshell(ctx, sudoPassword, progressChan); err != nil {
+			if err := o.installQuickshell(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install quickshell: %w", err)
 			}
 		} else {
 			// Use the base ManualPackageInstaller for other packages
-			if err := o.ManualPackageInstaller.InstallManualPackages(ctx, []string{pkg}, sudoPassword, progressChan); err != nil {
+			if err := o.ManualPackageInstaller.InstallManualPackages(ctx, []string{pkg}, progressChan); err != nil {
 				return fmt.Errorf("failed to install %s: %w", pkg, err)
 			}
 		}
@@ -24,6 +24,7 @@ type OSInfo struct {
 	VersionID    string
 	PrettyName   string
 	Architecture string
+	Environment  Environment
 }
 
 // GetOSInfo detects the current OS and returns information about it
@@ -38,6 +39,7 @@ func GetOSInfo() (*OSInfo, error) {
 
 	info := &OSInfo{
 		Architecture: runtime.GOARCH,
+		Environment:  DetectEnvironment(),
 	}
 
 	file, err := os.Open("/etc/os-release")
@@ -0,0 +1,411 @@
+package distros
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
+)
+
+// PluginDir is where the installer loads out-of-tree distribution
+// definitions from at startup
+const PluginDir = "/usr/share/danklinux/distros.d"
+
+// PluginDefinition describes a distribution without requiring Go code changes.
+// Definitions are JSON files in PluginDir, one distribution per file.
+type PluginDefinition struct {
+	ID             string             `json:"id"`
+	ColorHex       string             `json:"colorHex"`
+	Family         DistroFamily       `json:"family"`
+	PackageManager PackageManagerType `json:"packageManager"`
+	Commands       PluginCommands     `json:"commands"`
+	Prerequisites  []string           `json:"prerequisites"`
+	RepoSetup      []string           `json:"repoSetup"`
+	Packages       PluginPackageMap   `json:"packages"`
+}
+
+// PluginCommands holds the package manager command templates. {package} and
+// {packages} are replaced with a single package or a space separated list
+type PluginCommands struct {
+	Query   string `json:"query"`
+	Install string `json:"install"`
+}
+
+// PluginPackageMap maps dependency names to packages, split by window manager
+type PluginPackageMap struct {
+	Common   map[string]PluginPackage `json:"common"`
+	Niri     map[string]PluginPackage `json:"niri"`
+	Hyprland map[string]PluginPackage `json:"hyprland"`
+}
+
+// PluginPackage is the JSON form of PackageMapping
+type PluginPackage struct {
+	Name       string         `json:"name"`
+	Repository RepositoryType `json:"repository"`
+	RepoURL    string         `json:"repoUrl"`
+	BuildFunc  string         `json:"buildFunc"`
+}
+
+// LoadDistroPlugins registers every definition found in dir, in file name
+// order. A missing dir is not an error. Invalid files and definitions that
+// would replace a built-in or an earlier file's distribution are skipped and
+// reported in the returned error, so nothing is shadowed without notice.
+func LoadDistroPlugins(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("failed to list distro definitions in %s: %w", dir, err)
+	}
+	sort.Strings(files)
+
+	var errs []error
+	for _, file := range files {
+		def, err := LoadPluginDefinition(file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, exists := Registry[def.ID]; exists {
+			errs = append(errs, fmt.Errorf("distro definition %s: %s is already registered", file, def.ID))
+			continue
+		}
+
+		definition := def
+		Register(def.ID, def.ColorHex, def.Family, func(config DistroConfig, logChan chan<- string) Distribution {
+			return NewPluginDistribution(config, definition, logChan)
+		})
+	}
+
+	return errors.Join(errs...)
+}
+
+// LoadPluginDefinition reads and validates a single definition file
+func LoadPluginDefinition(path string) (*PluginDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read distro definition %s: %w", path, err)
+	}
+
+	var def PluginDefinition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("failed to parse distro definition %s: %w", path, err)
+	}
+
+	if def.ID == "" {
+		return nil, fmt.Errorf("distro definition %s: missing id", path)
+	}
+	if def.Commands.Install == "" {
+		return nil, fmt.Errorf("distro definition %s: missing commands.install", path)
+	}
+	if def.ColorHex == "" {
+		def.ColorHex = "#1793D1"
+	}
+
+	return &def, nil
+}
+
+type PluginDistribution struct {
+	*BaseDistribution
+	*ManualPackageInstaller
+	config     DistroConfig
+	definition *PluginDefinition
+}
+
+func NewPluginDistribution(config DistroConfig, definition *PluginDefinition, logChan chan<- string) *PluginDistribution {
+	base := NewBaseDistribution(logChan)
+	return &PluginDistribution{
+		BaseDistribution:       base,
+		ManualPackageInstaller: &ManualPackageInstaller{BaseDistribution: base},
+		config:                 config,
+		definition:             definition,
+	}
+}
+
+func (p *PluginDistribution) GetID() string {
+	return p.config.ID
+}
+
+func (p *PluginDistribution) GetColorHex() string {
+	return p.config.ColorHex
+}
+
+func (p *PluginDistribution) GetFamily() DistroFamily {
+	return p.config.Family
+}
+
+func (p *PluginDistribution) GetPackageManager() PackageManagerType {
+	return p.definition.PackageManager
+}
+
+func (p *PluginDistribution) DetectDependencies(ctx context.Context, wm deps.WindowManager) ([]deps.Dependency, error) {
+	return p.DetectDependenciesWithTerminal(ctx, wm, deps.TerminalGhostty)
+}
+
+func (p *PluginDistribution) DetectDependenciesWithTerminal(ctx context.Context, wm deps.WindowManager, terminal deps.Terminal) ([]deps.Dependency, error) {
+	var dependencies []deps.Dependency
+
+	dependencies = append(dependencies, p.detectDMS())
+	dependencies = append(dependencies, p.detectSpecificTerminal(terminal))
+	dependencies = append(dependencies, p.detectGit())
+	dependencies = append(dependencies, p.detectWindowManager(wm))
+	dependencies = append(dependencies, p.detectQuickshell())
+
+	if wm == deps.WindowManagerHyprland {
+		dependencies = append(dependencies, p.detectHyprlandTools()...)
+	}
+
+	dependencies = append(dependencies, p.detectMatugen())
+	dependencies = append(dependencies, p.detectDgop())
+	dependencies = append(dependencies, p.detectClipboardTools()...)
+
+	// Anything else the definition maps is detected through its query command
+	known := make(map[string]bool)
+	for _, dep := range dependencies {
+		known[dep.Name] = true
+	}
+
+	packages := p.GetPackageMapping(wm)
+	extra := make([]string, 0, len(packages))
+	for name := range packages {
+		if !known[name] && !isTerminalDependency(name) {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+
+	for _, name := range extra {
+		status := deps.StatusMissing
+		if p.packageInstalled(packages[name].Name) {
+			status = deps.StatusInstalled
+		}
+		dependencies = append(dependencies, deps.Dependency{
+			Name:        name,
+			Status:      status,
+			Description: fmt.Sprintf("Required by %s", p.config.ID),
+			Required:    true,
+		})
+	}
+
+	return dependencies, nil
+}
+
+func isTerminalDependency(name string) bool {
+	switch name {
+	case "ghostty", "kitty", "alacritty":
+		return true
+	}
+	return false
+}
+
+func (p *PluginDistribution) packageInstalled(pkg string) bool {
+	if p.definition.Commands.Query == "" {
+		return p.commandExists(pkg)
+	}
+
+	cmdStr := strings.ReplaceAll(p.definition.Commands.Query, "{package}", pkg)
+	cmd := exec.Command("sh", "-c", cmdStr)
+	return cmd.Run() == nil
+}
+
+func (p *PluginDistribution) GetPackageMapping(wm deps.WindowManager) map[string]PackageMapping {
+	packages := make(map[string]PackageMapping)
+	addPluginPackages(packages, p.definition.Packages.Common)
+
+	switch wm {
+	case deps.WindowManagerHyprland:
+		addPluginPackages(packages, p.definition.Packages.Hyprland)
+	case deps.WindowManagerNiri:
+		addPluginPackages(packages, p.definition.Packages.Niri)
+	}
+
+	return packages
+}
+
+func addPluginPackages(packages map[string]PackageMapping, source map[string]PluginPackage) {
+	for depName, pkg := range source {
+		mapping := PackageMapping{
+			Name:       pkg.Name,
+			Repository: pkg.Repository,
+			RepoURL:    pkg.RepoURL,
+			BuildFunc:  pkg.BuildFunc,
+		}
+		if mapping.Name == "" {
+			mapping.Name = depName
+		}
+		if mapping.Repository == "" {
+			mapping.Repository = RepoTypeSystem
+		}
+		packages[depName] = mapping
+	}
+}
+
+func (p *PluginDistribution) InstallPrerequisites(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
+	var missingPkgs []string
+	for _, pkg := range p.definition.Prerequisites {
+		if !p.packageInstalled(pkg) {
+			missingPkgs = append(missingPkgs, pkg)
+		}
+	}
+
+	if len(missingPkgs) == 0 {
+		p.log("All prerequisites already installed")
+		return nil
+	}
+
+	progressChan <- InstallProgressMsg{
+		Phase:     PhasePrerequisites,
+		Progress:  0.08,
+		Step:      fmt.Sprintf("Installing %d prerequisites...", len(missingPkgs)),
+		NeedsSudo: true,
+		LogOutput: fmt.Sprintf("Installing prerequisites: %s", strings.Join(missingPkgs, ", ")),
+	}
+
+	if err := p.installSystemPackages(ctx, missingPkgs, progressChan, PhasePrerequisites, 0.08, 0.10); err != nil {
+		return fmt.Errorf("failed to install prerequisites: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PluginDistribution) InstallPackages(ctx context.Context, dependencies []deps.Dependency, wm deps.WindowManager, reinstallFlags map[string]bool, progressChan chan<- InstallProgressMsg) error {
+	progressChan <- InstallProgressMsg{
+		Phase:     PhasePrerequisites,
+		Progress:  0.05,
+		Step:      "Checking system prerequisites...",
+		LogOutput: "Starting prerequisite check...",
+	}
+
+	if err := p.InstallPrerequisites(ctx, progressChan); err != nil {
+		return err
+	}
+
+	if len(p.definition.RepoSetup) > 0 {
+		progressChan <- InstallProgressMsg{
+			Phase:     PhaseSystemPackages,
+			Progress:  0.15,
+			Step:      "Setting up repositories...",
+			LogOutput: fmt.Sprintf("Running %d repository setup step(s)", len(p.definition.RepoSetup)),
+		}
+		if err := p.runRepoSetup(ctx, progressChan); err != nil {
+			return fmt.Errorf("failed to set up repositories: %w", err)
+		}
+	}
+
+	systemPkgs, manualPkgs := p.categorizePackages(dependencies, wm, reinstallFlags)
+
+	if len(systemPkgs) > 0 {
+		progressChan <- InstallProgressMsg{
+			Phase:     PhaseSystemPackages,
+			Progress:  0.35,
+			Step:      fmt.Sprintf("Installing %d system packages...", len(systemPkgs)),
+			NeedsSudo: true,
+			LogOutput: fmt.Sprintf("Installing system packages: %s", strings.Join(systemPkgs, ", ")),
+		}
+		if err := p.installSystemPackages(ctx, systemPkgs, progressChan, PhaseSystemPackages, 0.40, 0.80); err != nil {
+			return fmt.Errorf("failed to install system packages: %w", err)
+		}
+	}
+
+	if len(manualPkgs) > 0 {
+		progressChan <- InstallProgressMsg{
+			Phase:     PhaseSystemPackages,
+			Progress:  0.85,
+			Step:      fmt.Sprintf("Building %d packages from source...", len(manualPkgs)),
+			LogOutput: fmt.Sprintf("Building from source: %s", strings.Join(manualPkgs, ", ")),
+		}
+		if err := p.InstallManualPackages(ctx, manualPkgs, progressChan); err != nil {
+			return fmt.Errorf("failed to install manual packages: %w", err)
+		}
+	}
+
+	progressChan <- InstallProgressMsg{
+		Phase:     PhaseConfiguration,
+		Progress:  0.90,
+		Step:      "Configuring system...",
+		LogOutput: "Starting post-installation configuration...",
+	}
+
+	progressChan <- InstallProgressMsg{
+		Phase:      PhaseComplete,
+		Progress:   1.0,
+		Step:       "Installation complete!",
+		IsComplete: true,
+		LogOutput:  "All packages installed and configured successfully",
+	}
+
+	return nil
+}
+
+func (p *PluginDistribution) categorizePackages(dependencies []deps.Dependency, wm deps.WindowManager, reinstallFlags map[string]bool) ([]string, []string) {
+	systemPkgs := []string{}
+	manualPkgs := []string{}
+
+	packageMap := p.GetPackageMapping(wm)
+
+	for _, dep := range dependencies {
+		if dep.Status == deps.StatusInstalled && !reinstallFlags[dep.Name] {
+			continue
+		}
+
+		pkgInfo, exists := packageMap[dep.Name]
+		if !exists {
+			p.log(fmt.Sprintf("Warning: No package mapping for %s", dep.Name))
+			continue
+		}
+
+		if pkgInfo.Repository == RepoTypeManual {
+			manualPkgs = append(manualPkgs, dep.Name)
+			continue
+		}
+		systemPkgs = append(systemPkgs, pkgInfo.Name)
+	}
+
+	return systemPkgs, manualPkgs
+}
+
+func (p *PluginDistribution) runRepoSetup(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
+	for i, step := range p.definition.RepoSetup {
+		p.log(fmt.Sprintf("Repository setup: %s", step))
+		progressChan <- InstallProgressMsg{
+			Phase:       PhaseSystemPackages,
+			Progress:    0.15 + 0.15*float64(i)/float64(len(p.definition.RepoSetup)),
+			Step:        "Setting up repositories...",
+			NeedsSudo:   true,
+			CommandInfo: fmt.Sprintf("sudo %s", step),
+		}
+
+		cmd := exec.CommandContext(ctx, "bash", "-c", fmt.Sprintf("%s %s 2>&1", privesc.ShellPrefix(), step))
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			p.logError(fmt.Sprintf("repository setup step failed: %s", step), err)
+			p.log(fmt.Sprintf("Repository setup output: %s", string(output)))
+			return fmt.Errorf("repository setup step %q failed: %w", step, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *PluginDistribution) installSystemPackages(ctx context.Context, packages []string, progressChan chan<- InstallProgressMsg, phase InstallPhase, startProgress, endProgress float64) error {
+	installCmd := strings.ReplaceAll(p.definition.Commands.Install, "{packages}", strings.Join(packages, " "))
+
+	p.log(fmt.Sprintf("Installing packages: %s", strings.Join(packages, ", ")))
+	progressChan <- InstallProgressMsg{
+		Phase:       phase,
+		Progress:    startProgress,
+		Step:        "Installing system packages...",
+		NeedsSudo:   true,
+		CommandInfo: fmt.Sprintf("sudo %s", installCmd),
+	}
+
+	cmdStr := fmt.Sprintf("%s %s", privesc.ShellPrefix(), installCmd)
+	cmd := exec.CommandContext(ctx, "bash", "-c", cmdStr)
+	return p.runWithProgress(cmd, progressChan, phase, startProgress, endProgress)
+}
@@ -0,0 +1,95 @@
+package distros
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+)
+
+const testPluginDefinition = `{
+  "id": "testdistro",
+  "colorHex": "#123456",
+  "family": "arch",
+  "packageManager": "pacman",
+  "commands": {
+    "query": "pacman -Q {package}",
+    "install": "pacman -S --needed --noconfirm {packages}"
+  },
+  "packages": {
+    "common": {
+      "git": {"name": "git"},
+      "dms (DankMaterialShell)": {"repository": "manual", "buildFunc": "installDankMaterialShell"}
+    },
+    "niri": {
+      "niri": {"name": "niri-bin"}
+    }
+  }
+}`
+
+func TestLoadDistroPlugins(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "testdistro.json"), []byte(testPluginDefinition), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "arch.json"), []byte(`{"id": "arch", "commands": {"install": "true"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{"id": `), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer delete(Registry, "testdistro")
+
+	archConfig := Registry["arch"]
+	err := LoadDistroPlugins(dir)
+	if err == nil {
+		t.Fatal("Expected errors for the broken and the shadowing definitions")
+	}
+	if !strings.Contains(err.Error(), "broken.json") || !strings.Contains(err.Error(), "arch is already registered") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if Registry["arch"].ColorHex != archConfig.ColorHex {
+		t.Error("Expected built-in arch registration to be kept")
+	}
+
+	logChan := make(chan string, 10)
+	defer close(logChan)
+
+	distro, err := NewDistribution("testdistro", logChan)
+	if err != nil {
+		t.Fatalf("Expected plugin distribution to be registered: %v", err)
+	}
+	if distro.GetColorHex() != "#123456" || distro.GetFamily() != FamilyArch || distro.GetPackageManager() != PackageManagerPacman {
+		t.Errorf("Unexpected metadata: %s %s %s", distro.GetColorHex(), distro.GetFamily(), distro.GetPackageManager())
+	}
+
+	mapping := distro.GetPackageMapping(deps.WindowManagerNiri)
+	if mapping["niri"].Name != "niri-bin" || mapping["niri"].Repository != RepoTypeSystem {
+		t.Errorf("Unexpected niri mapping: %+v", mapping["niri"])
+	}
+	if mapping["dms (DankMaterialShell)"].Repository != RepoTypeManual {
+		t.Errorf("Unexpected dms mapping: %+v", mapping["dms (DankMaterialShell)"])
+	}
+	if _, exists := distro.GetPackageMapping(deps.WindowManagerHyprland)["niri"]; exists {
+		t.Error("Expected niri mapping to be omitted for Hyprland")
+	}
+}
+
+func TestLoadDistroPlugins_MissingDir(t *testing.T) {
+	if err := LoadDistroPlugins(filepath.Join(t.TempDir(), "missing")); err != nil {
+		t.Errorf("Expected a missing directory to load nothing, got %v", err)
+	}
+}
+
+func TestLoadPluginDefinition_MissingInstall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(path, []byte(`{"id": "bad"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPluginDefinition(path); err == nil {
+		t.Error("Expected error for definition without install command")
+	}
+}
@@ -0,0 +1,64 @@
+package distros
+
+// ProgressProtocolVersion is bumped whenever ProgressEvent changes incompatibly
+const ProgressProtocolVersion = 1
+
+func (p InstallPhase) String() string {
+	switch p {
+	case PhasePrerequisites:
+		return "prerequisites"
+	case PhaseAURHelper:
+		return "aur_helper"
+	case PhaseSystemPackages:
+		return "system_packages"
+	case PhaseAURPackages:
+		return "aur_packages"
+	case PhaseCursorTheme:
+		return "cursor_theme"
+	case PhaseConfiguration:
+		return "configuration"
+	case PhaseComplete:
+		return "complete"
+	default:
+		return "unknown"
+	}
+}
+
+// ProgressEvent is the line-delimited JSON form of installer output used by
+// external frontends. Type is one of hello, message, log, prompt, progress,
+// complete or error.
+type ProgressEvent struct {
+	Type        string   `json:"type"`
+	Version     int      `json:"version,omitempty"`
+	Phase       string   `json:"phase,omitempty"`
+	Progress    *float64 `json:"progress,omitempty"`
+	Step        string   `json:"step,omitempty"`
+	IsComplete  bool     `json:"isComplete,omitempty"`
+	NeedsSudo   bool     `json:"needsSudo,omitempty"`
+	CommandInfo string   `json:"commandInfo,omitempty"`
+	LogOutput   string   `json:"logOutput,omitempty"`
+	Message     string   `json:"message,omitempty"`
+	Prompt      string   `json:"prompt,omitempty"`
+	Options     []string `json:"options,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// Event converts an InstallProgressMsg into a progress ProgressEvent
+func (m InstallProgressMsg) Event() ProgressEvent {
+	progress := m.Progress
+	event := ProgressEvent{
+		Type:        "progress",
+		Phase:       m.Phase.String(),
+		Progress:    &progress,
+		Step:        m.Step,
+		IsComplete:  m.IsComplete,
+		NeedsSudo:   m.NeedsSudo,
+		CommandInfo: m.CommandInfo,
+		LogOutput:   m.LogOutput,
+	}
+	if m.Error != nil {
+		event.Error = m.Error.Error()
+	}
+	return event
+}
@@ -0,0 +1,44 @@
+package distros
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInstallProgressMsg_Event(t *testing.T) {
+	msg := InstallProgressMsg{
+		Phase:       PhaseSystemPackages,
+		Progress:    0.4,
+		Step:        "Installing system packages...",
+		NeedsSudo:   true,
+		CommandInfo: "sudo pacman -S git",
+		Error:       errors.New("boom"),
+	}
+
+	data, err := json.Marshal(msg.Event())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := string(data)
+	for _, want := range []string{`"type":"progress"`, `"phase":"system_packages"`, `"progress":0.4`, `"needsSudo":true`, `"error":"boom"`} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Expected %s in %s", want, line)
+		}
+	}
+	if strings.Contains(line, "logOutput") {
+		t.Errorf("Expected empty fields to be omitted: %s", line)
+	}
+}
+
+func TestInstallProgressMsg_EventZeroProgress(t *testing.T) {
+	data, err := json.Marshal(InstallProgressMsg{Phase: PhasePrerequisites}.Event())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"progress":0`) {
+		t.Errorf("Expected zero progress to be kept: %s", data)
+	}
+}
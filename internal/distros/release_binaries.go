@@ -0,0 +1,289 @@
+package distros
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
+)
+
+// ReleaseBinary is a dependency that can be installed from prebuilt GitHub
+// release assets instead of AUR/COPR/source builds
+type ReleaseBinary struct {
+	Name string // binary and dependency name
+	Repo string // owner/repo on GitHub
+}
+
+var releaseBinaries = map[string]ReleaseBinary{
+	"dgop":    {Name: "dgop", Repo: "AvengeMedia/dgop"},
+	"matugen": {Name: "matugen", Repo: "InioX/matugen"},
+}
+
+type releaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+type githubRelease struct {
+	TagName string         `json:"tag_name"`
+	Assets  []releaseAsset `json:"assets"`
+}
+
+// SupportsReleaseBinary reports whether a dependency can use deps.VariantBinary
+func SupportsReleaseBinary(name string) bool {
+	_, ok := releaseBinaries[name]
+	return ok
+}
+
+// SplitReleaseBinaries separates dependencies selected as release binaries
+// from the ones left to the distribution's package manager
+func SplitReleaseBinaries(dependencies []deps.Dependency, reinstallFlags map[string]bool) (binaries []deps.Dependency, rest []deps.Dependency) {
+	for _, dep := range dependencies {
+		if dep.Variant == deps.VariantBinary && SupportsReleaseBinary(dep.Name) {
+			if dep.Status != deps.StatusInstalled || reinstallFlags[dep.Name] {
+				binaries = append(binaries, dep)
+			}
+			continue
+		}
+		rest = append(rest, dep)
+	}
+	return binaries, rest
+}
+
+// InstallReleaseBinaries downloads, verifies and installs each dependency
+// into /usr/local/bin
+func InstallReleaseBinaries(ctx context.Context, dependencies []deps.Dependency, progressChan chan<- InstallProgressMsg, logChan chan<- string) error {
+	base := NewBaseDistribution(logChan)
+	for _, dep := range dependencies {
+		binary, ok := releaseBinaries[dep.Name]
+		if !ok {
+			continue
+		}
+		if err := base.installReleaseBinary(ctx, binary, progressChan); err != nil {
+			return fmt.Errorf("failed to install %s release binary: %w", binary.Name, err)
+		}
+	}
+	return nil
+}
+
+func (b *BaseDistribution) installReleaseBinary(ctx context.Context, binary ReleaseBinary, progressChan chan<- InstallProgressMsg) error {
+	b.log(fmt.Sprintf("Installing %s from GitHub release binaries...", binary.Name))
+
+	progressChan <- InstallProgressMsg{
+		Phase:       PhaseSystemPackages,
+		Progress:    0.12,
+		Step:        fmt.Sprintf("Fetching latest %s release...", binary.Name),
+		CommandInfo: fmt.Sprintf("curl https://api.github.com/repos/%s/releases/latest", binary.Repo),
+	}
+
+	releaseOutput, err := exec.CommandContext(ctx, "curl", "-fsSL", "--max-time", "15",
+		fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", binary.Repo)).Output()
+	if err != nil {
+		return fmt.Errorf("failed to query latest release: %w", err)
+	}
+
+	var release githubRelease
+	if err := json.Unmarshal(releaseOutput, &release); err != nil {
+		return fmt.Errorf("failed to parse release metadata: %w", err)
+	}
+
+	asset, ok := selectReleaseAsset(release.Assets, binary.Name, runtime.GOARCH)
+	if !ok {
+		return fmt.Errorf("no %s asset for linux/%s in release %s", binary.Name, runtime.GOARCH, release.TagName)
+	}
+	checksumAsset, ok := selectChecksumAsset(release.Assets, asset.Name)
+	if !ok {
+		return fmt.Errorf("release %s publishes no checksum for %s", release.TagName, asset.Name)
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	tmpDir := filepath.Join(homeDir, ".cache", "dankinstall", binary.Name+"-release")
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	progressChan <- InstallProgressMsg{
+		Phase:       PhaseSystemPackages,
+		Progress:    0.14,
+		Step:        fmt.Sprintf("Downloading %s %s...", binary.Name, release.TagName),
+		CommandInfo: fmt.Sprintf("curl -L %s", asset.URL),
+	}
+
+	assetPath := filepath.Join(tmpDir, asset.Name)
+	if err := exec.CommandContext(ctx, "curl", "-fsSL", asset.URL, "-o", assetPath).Run(); err != nil {
+		return fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+
+	checksumOutput, err := exec.CommandContext(ctx, "curl", "-fsSL", checksumAsset.URL).Output()
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", checksumAsset.Name, err)
+	}
+
+	expected, ok := findChecksum(string(checksumOutput), asset.Name)
+	if !ok {
+		return fmt.Errorf("%s has no entry for %s", checksumAsset.Name, asset.Name)
+	}
+	if err := verifySHA256(assetPath, expected); err != nil {
+		return err
+	}
+	b.log(fmt.Sprintf("Checksum verified for %s", asset.Name))
+
+	binaryPath, err := extractReleaseBinary(ctx, assetPath, tmpDir, binary.Name)
+	if err != nil {
+		return err
+	}
+
+	progressChan <- InstallProgressMsg{
+		Phase:       PhaseSystemPackages,
+		Progress:    0.16,
+		Step:        fmt.Sprintf("Installing %s to /usr/local/bin...", binary.Name),
+		NeedsSudo:   true,
+		CommandInfo: fmt.Sprintf("sudo install -m 0755 %s /usr/local/bin/%s", binary.Name, binary.Name),
+	}
+
+	installCmd := privesc.Command(ctx, "install", "-m", "0755", binaryPath, filepath.Join("/usr/local/bin", binary.Name))
+	if output, err := installCmd.CombinedOutput(); err != nil {
+		b.log(fmt.Sprintf("install output: %s", string(output)))
+		return fmt.Errorf("failed to install %s: %w", binary.Name, err)
+	}
+
+	b.log(fmt.Sprintf("%s %s installed from release binary", binary.Name, release.TagName))
+	return nil
+}
+
+func archAliases(goarch string) []string {
+	switch goarch {
+	case "amd64":
+		return []string{"amd64", "x86_64", "x64"}
+	case "arm64":
+		return []string{"arm64", "aarch64"}
+	default:
+		return []string{goarch}
+	}
+}
+
+func isChecksumAsset(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.Contains(lower, "checksum") || strings.Contains(lower, "sha256") || strings.HasSuffix(lower, ".sha256sum")
+}
+
+func selectReleaseAsset(assets []releaseAsset, name, goarch string) (releaseAsset, bool) {
+	for _, asset := range assets {
+		lower := strings.ToLower(asset.Name)
+		if isChecksumAsset(lower) || !strings.Contains(lower, name) {
+			continue
+		}
+		if strings.Contains(lower, "darwin") || strings.Contains(lower, "windows") || strings.HasSuffix(lower, ".zip") {
+			continue
+		}
+		for _, alias := range archAliases(goarch) {
+			if strings.Contains(lower, alias) {
+				return asset, true
+			}
+		}
+	}
+	return releaseAsset{}, false
+}
+
+func selectChecksumAsset(assets []releaseAsset, assetName string) (releaseAsset, bool) {
+	for _, asset := range assets {
+		if asset.Name == assetName+".sha256" || asset.Name == assetName+".sha256sum" {
+			return asset, true
+		}
+	}
+	for _, asset := range assets {
+		if isChecksumAsset(asset.Name) {
+			return asset, true
+		}
+	}
+	return releaseAsset{}, false
+}
+
+// findChecksum reads sha256sum style output ("<hash>  <file>"), also
+// accepting a single bare hash for per-asset checksum files
+func findChecksum(content, assetName string) (string, bool) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch len(fields) {
+		case 0:
+			continue
+		case 1:
+			if len(fields[0]) == sha256.Size*2 {
+				return strings.ToLower(fields[0]), true
+			}
+		default:
+			if strings.TrimPrefix(fields[1], "*") == assetName {
+				return strings.ToLower(fields[0]), true
+			}
+		}
+	}
+	return "", false
+}
+
+func verifySHA256(path, expected string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return err
+	}
+
+	actual := hex.EncodeToString(hash.Sum(nil))
+	if actual != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", filepath.Base(path), expected, actual)
+	}
+	return nil
+}
+
+func extractReleaseBinary(ctx context.Context, assetPath, tmpDir, name string) (string, error) {
+	lower := strings.ToLower(assetPath)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		extractDir := filepath.Join(tmpDir, "extract")
+		if err := os.MkdirAll(extractDir, 0755); err != nil {
+			return "", err
+		}
+		if err := exec.CommandContext(ctx, "tar", "-xzf", assetPath, "-C", extractDir).Run(); err != nil {
+			return "", fmt.Errorf("failed to extract %s: %w", filepath.Base(assetPath), err)
+		}
+
+		var found string
+		filepath.Walk(extractDir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && found == "" && !info.IsDir() && info.Name() == name {
+				found = path
+			}
+			return nil
+		})
+		if found == "" {
+			return "", fmt.Errorf("%s not found in %s", name, filepath.Base(assetPath))
+		}
+		return found, nil
+	case strings.HasSuffix(lower, ".gz"):
+		if err := exec.CommandContext(ctx, "gunzip", "-f", assetPath).Run(); err != nil {
+			return "", fmt.Errorf("failed to extract %s: %w", filepath.Base(assetPath), err)
+		}
+		return strings.TrimSuffix(assetPath, filepath.Ext(assetPath)), nil
+	default:
+		return assetPath, nil
+	}
+}
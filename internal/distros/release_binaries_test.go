@@ -0,0 +1,109 @@
+package distros
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/deps"
+)
+
+func TestSelectReleaseAsset(t *testing.T) {
+	assets := []releaseAsset{
+		{Name: "checksums.txt"},
+		{Name: "dgop-darwin-arm64.gz"},
+		{Name: "dgop-linux-amd64.gz"},
+		{Name: "dgop-linux-arm64.gz"},
+	}
+
+	tests := []struct {
+		goarch string
+		want   string
+		ok     bool
+	}{
+		{"amd64", "dgop-linux-amd64.gz", true},
+		{"arm64", "dgop-linux-arm64.gz", true},
+		{"riscv64", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goarch, func(t *testing.T) {
+			asset, ok := selectReleaseAsset(assets, "dgop", tt.goarch)
+			if ok != tt.ok || asset.Name != tt.want {
+				t.Errorf("selectReleaseAsset(%s) = %q, %v; want %q, %v", tt.goarch, asset.Name, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSelectChecksumAsset(t *testing.T) {
+	assets := []releaseAsset{
+		{Name: "checksums.txt"},
+		{Name: "matugen-x86_64.tar.gz"},
+		{Name: "matugen-x86_64.tar.gz.sha256"},
+	}
+
+	asset, ok := selectChecksumAsset(assets, "matugen-x86_64.tar.gz")
+	if !ok || asset.Name != "matugen-x86_64.tar.gz.sha256" {
+		t.Errorf("expected per-asset checksum, got %q, %v", asset.Name, ok)
+	}
+
+	asset, ok = selectChecksumAsset(assets[:2], "matugen-x86_64.tar.gz")
+	if !ok || asset.Name != "checksums.txt" {
+		t.Errorf("expected checksums.txt fallback, got %q, %v", asset.Name, ok)
+	}
+}
+
+func TestFindChecksum(t *testing.T) {
+	hash := "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855"
+	content := "0000  dgop-linux-arm64.gz\n" + hash + " *dgop-linux-amd64.gz\n"
+
+	got, ok := findChecksum(content, "dgop-linux-amd64.gz")
+	if !ok || got != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("findChecksum = %q, %v", got, ok)
+	}
+
+	if _, ok := findChecksum(content, "dgop-linux-riscv64.gz"); ok {
+		t.Error("expected no checksum for unknown asset")
+	}
+
+	if got, ok := findChecksum(hash+"\n", "anything"); !ok || len(got) != 64 {
+		t.Errorf("expected bare hash to be accepted, got %q, %v", got, ok)
+	}
+}
+
+func TestVerifySHA256(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dgop")
+	writeTestFile(t, path, "binary")
+
+	sum := sha256.Sum256([]byte("binary"))
+	if err := verifySHA256(path, hex.EncodeToString(sum[:])); err != nil {
+		t.Errorf("expected checksum to match: %v", err)
+	}
+	if err := verifySHA256(path, "deadbeef"); err == nil {
+		t.Error("expected checksum mismatch")
+	}
+}
+
+func TestSplitReleaseBinaries(t *testing.T) {
+	dependencies := []deps.Dependency{
+		{Name: "dgop", Status: deps.StatusMissing, Variant: deps.VariantBinary},
+		{Name: "matugen", Status: deps.StatusInstalled, Variant: deps.VariantBinary},
+		{Name: "niri", Status: deps.StatusMissing, Variant: deps.VariantBinary},
+		{Name: "ghostty", Status: deps.StatusMissing},
+	}
+
+	binaries, rest := SplitReleaseBinaries(dependencies, map[string]bool{})
+	if len(binaries) != 1 || binaries[0].Name != "dgop" {
+		t.Errorf("unexpected binaries: %+v", binaries)
+	}
+	if len(rest) != 2 || rest[0].Name != "niri" || rest[1].Name != "ghostty" {
+		t.Errorf("unexpected rest: %+v", rest)
+	}
+
+	binaries, _ = SplitReleaseBinaries(dependencies, map[string]bool{"matugen": true})
+	if len(binaries) != 2 {
+		t.Errorf("expected reinstall to include matugen, got %+v", binaries)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
 )
 
 func init() {
@@ -188,7 +189,7 @@ func (u *UbuntuDistribution) GetPackageMapping(wm deps.WindowManager) map[string
 	return packages
 }
 
-func (u *UbuntuDistribution) InstallPrerequisites(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (u *UbuntuDistribution) InstallPrerequisites(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	progressChan <- InstallProgressMsg{
 		Phase:      PhasePrerequisites,
 		Progress:   0.06,
@@ -197,7 +198,7 @@ func (u *UbuntuDistribution) InstallPrerequisites(ctx context.Context, sudoPassw
 		LogOutput:  "Updating APT package lists",
 	}
 
-	updateCmd := exec.CommandContext(ctx, "bash", "-c", fmt.Sprintf("echo '%s' | sudo -S apt-get update", sudoPassword))
+	updateCmd := exec.CommandContext(ctx, "bash", "-c", fmt.Sprintf("%s apt-get update", privesc.ShellPrefix()))
 	if err := u.runWithProgress(updateCmd, progressChan, PhasePrerequisites, 0.06, 0.07); err != nil {
 		return fmt.Errorf("failed to update package lists: %w", err)
 	}
@@ -215,7 +216,7 @@ func (u *UbuntuDistribution) InstallPrerequisites(ctx context.Context, sudoPassw
 	checkCmd := exec.CommandContext(ctx, "dpkg", "-l", "build-essential")
 	if err := checkCmd.Run(); err != nil {
 		// Not installed, install it
-		cmd := exec.CommandContext(ctx, "bash", "-c", fmt.Sprintf("echo '%s' | sudo -S apt-get install -y build-essential", sudoPassword))
+		cmd := exec.CommandContext(ctx, "bash", "-c", fmt.Sprintf("%s apt-get install -y build-essential", privesc.ShellPrefix()))
 		if err := u.runWithProgress(cmd, progressChan, PhasePrerequisites, 0.08, 0.09); err != nil {
 			return fmt.Errorf("failed to install build-essential: %w", err)
 		}
@@ -232,7 +233,7 @@ func (u *UbuntuDistribution) InstallPrerequisites(ctx context.Context, sudoPassw
 	}
 
 	devToolsCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S apt-get install -y curl wget git cmake ninja-build pkg-config", sudoPassword))
+		fmt.Sprintf("%s apt-get install -y curl wget git cmake ninja-build pkg-config", privesc.ShellPrefix()))
 	if err := u.runWithProgress(devToolsCmd, progressChan, PhasePrerequisites, 0.10, 0.12); err != nil {
 		return fmt.Errorf("failed to install development tools: %w", err)
 	}
@@ -248,7 +249,7 @@ func (u *UbuntuDistribution) InstallPrerequisites(ctx context.Context, sudoPassw
 	return nil
 }
 
-func (u *UbuntuDistribution) InstallPackages(ctx context.Context, dependencies []deps.Dependency, wm deps.WindowManager, sudoPassword string, reinstallFlags map[string]bool, progressChan chan<- InstallProgressMsg) error {
+func (u *UbuntuDistribution) InstallPackages(ctx context.Context, dependencies []deps.Dependency, wm deps.WindowManager, reinstallFlags map[string]bool, progressChan chan<- InstallProgressMsg) error {
 	// Phase 1: Check Prerequisites
 	progressChan <- InstallProgressMsg{
 		Phase:      PhasePrerequisites,
@@ -258,7 +259,7 @@ func (u *UbuntuDistribution) InstallPackages(ctx context.Context, dependencies [
 		LogOutput:  "Starting prerequisite check...",
 	}
 
-	if err := u.InstallPrerequisites(ctx, sudoPassword, progressChan); err != nil {
+	if err := u.InstallPrerequisites(ctx, progressChan); err != nil {
 		return fmt.Errorf("failed to install prerequisites: %w", err)
 	}
 
@@ -273,7 +274,7 @@ func (u *UbuntuDistribution) InstallPackages(ctx context.Context, dependencies [
 			IsComplete: false,
 			LogOutput:  "Setting up PPA repositories for additional packages",
 		}
-		if err := u.enablePPARepos(ctx, ppaPkgs, sudoPassword, progressChan); err != nil {
+		if err := u.enablePPARepos(ctx, ppaPkgs, progressChan); err != nil {
 			return fmt.Errorf("failed to enable PPA repositories: %w", err)
 		}
 	}
@@ -288,7 +289,7 @@ func (u *UbuntuDistribution) InstallPackages(ctx context.Context, dependencies [
 			NeedsSudo:  true,
 			LogOutput:  fmt.Sprintf("Installing system packages: %s", strings.Join(systemPkgs, ", ")),
 		}
-		if err := u.installAPTPackages(ctx, systemPkgs, sudoPassword, progressChan); err != nil {
+		if err := u.installAPTPackages(ctx, systemPkgs, progressChan); err != nil {
 			return fmt.Errorf("failed to install APT packages: %w", err)
 		}
 	}
@@ -303,7 +304,7 @@ func (u *UbuntuDistribution) InstallPackages(ctx context.Context, dependencies [
 			IsComplete: false,
 			LogOutput:  fmt.Sprintf("Installing PPA packages: %s", strings.Join(ppaPkgNames, ", ")),
 		}
-		if err := u.installPPAPackages(ctx, ppaPkgNames, sudoPassword, progressChan); err != nil {
+		if err := u.installPPAPackages(ctx, ppaPkgNames, progressChan); err != nil {
 			return fmt.Errorf("failed to install PPA packages: %w", err)
 		}
 	}
@@ -317,7 +318,7 @@ func (u *UbuntuDistribution) InstallPackages(ctx context.Context, dependencies [
 			IsComplete: false,
 			LogOutput:  "Installing build tools for manual compilation",
 		}
-		if err := u.installBuildDependencies(ctx, manualPkgs, sudoPassword, progressChan); err != nil {
+		if err := u.installBuildDependencies(ctx, manualPkgs, progressChan); err != nil {
 			return fmt.Errorf("failed to install build dependencies: %w", err)
 		}
 
@@ -328,7 +329,7 @@ func (u *UbuntuDistribution) InstallPackages(ctx context.Context, dependencies [
 			IsComplete: false,
 			LogOutput:  fmt.Sprintf("Building from source: %s", strings.Join(manualPkgs, ", ")),
 		}
-		if err := u.InstallManualPackages(ctx, manualPkgs, sudoPassword, progressChan); err != nil {
+		if err := u.InstallManualPackages(ctx, manualPkgs, progressChan); err != nil {
 			return fmt.Errorf("failed to install manual packages: %w", err)
 		}
 	}
@@ -394,11 +395,11 @@ func (u *UbuntuDistribution) extractPackageNames(packages []PackageMapping) []st
 	return names
 }
 
-func (u *UbuntuDistribution) enablePPARepos(ctx context.Context, ppaPkgs []PackageMapping, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (u *UbuntuDistribution) enablePPARepos(ctx context.Context, ppaPkgs []PackageMapping, progressChan chan<- InstallProgressMsg) error {
 	enabledRepos := make(map[string]bool)
 
 	installPPACmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S apt-get install -y software-properties-common", sudoPassword))
+		fmt.Sprintf("%s apt-get install -y software-properties-common", privesc.ShellPrefix()))
 	if err := u.runWithProgress(installPPACmd, progressChan, PhaseSystemPackages, 0.15, 0.17); err != nil {
 		return fmt.Errorf("failed to install software-properties-common: %w", err)
 	}
@@ -416,7 +417,7 @@ func (u *UbuntuDistribution) enablePPARepos(ctx context.Context, ppaPkgs []Packa
 			}
 
 			cmd := exec.CommandContext(ctx, "bash", "-c",
-				fmt.Sprintf("echo '%s' | sudo -S add-apt-repository -y %s", sudoPassword, pkg.RepoURL))
+				fmt.Sprintf("%s add-apt-repository -y %s", privesc.ShellPrefix(), pkg.RepoURL))
 			if err := u.runWithProgress(cmd, progressChan, PhaseSystemPackages, 0.20, 0.22); err != nil {
 				u.logError(fmt.Sprintf("failed to enable PPA repo %s", pkg.RepoURL), err)
 				return fmt.Errorf("failed to enable PPA repo %s: %w", pkg.RepoURL, err)
@@ -436,7 +437,7 @@ func (u *UbuntuDistribution) enablePPARepos(ctx context.Context, ppaPkgs []Packa
 			CommandInfo: "sudo apt-get update",
 		}
 
-		updateCmd := exec.CommandContext(ctx, "bash", "-c", fmt.Sprintf("echo '%s' | sudo -S apt-get update", sudoPassword))
+		updateCmd := exec.CommandContext(ctx, "bash", "-c", fmt.Sprintf("%s apt-get update", privesc.ShellPrefix()))
 		if err := u.runWithProgress(updateCmd, progressChan, PhaseSystemPackages, 0.25, 0.27); err != nil {
 			return fmt.Errorf("failed to update package lists after adding PPAs: %w", err)
 		}
@@ -445,7 +446,7 @@ func (u *UbuntuDistribution) enablePPARepos(ctx context.Context, ppaPkgs []Packa
 	return nil
 }
 
-func (u *UbuntuDistribution) installAPTPackages(ctx context.Context, packages []string, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (u *UbuntuDistribution) installAPTPackages(ctx context.Context, packages []string, progressChan chan<- InstallProgressMsg) error {
 	if len(packages) == 0 {
 		return nil
 	}
@@ -464,12 +465,12 @@ func (u *UbuntuDistribution) installAPTPackages(ctx context.Context, packages []
 		CommandInfo: fmt.Sprintf("sudo %s", strings.Join(args, " ")),
 	}
 
-	cmdStr := fmt.Sprintf("echo '%s' | sudo -S %s", sudoPassword, strings.Join(args, " "))
+	cmdStr := fmt.Sprintf("%s %s", privesc.ShellPrefix(), strings.Join(args, " "))
 	cmd := exec.CommandContext(ctx, "bash", "-c", cmdStr)
 	return u.runWithProgress(cmd, progressChan, PhaseSystemPackages, 0.40, 0.60)
 }
 
-func (u *UbuntuDistribution) installPPAPackages(ctx context.Context, packages []string, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (u *UbuntuDistribution) installPPAPackages(ctx context.Context, packages []string, progressChan chan<- InstallProgressMsg) error {
 	if len(packages) == 0 {
 		return nil
 	}
@@ -488,12 +489,12 @@ func (u *UbuntuDistribution) installPPAPackages(ctx context.Context, packages []
 		CommandInfo: fmt.Sprintf("sudo %s", strings.Join(args, " ")),
 	}
 
-	cmdStr := fmt.Sprintf("echo '%s' | sudo -S %s", sudoPassword, strings.Join(args, " "))
+	cmdStr := fmt.Sprintf("%s %s", privesc.ShellPrefix(), strings.Join(args, " "))
 	cmd := exec.CommandContext(ctx, "bash", "-c", cmdStr)
 	return u.runWithProgress(cmd, progressChan, PhaseAURPackages, 0.70, 0.85)
 }
 
-func (u *UbuntuDistribution) installBuildDependencies(ctx context.Context, manualPkgs []string, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (u *UbuntuDistribution) installBuildDependencies(ctx context.Context, manualPkgs []string, progressChan chan<- InstallProgressMsg) error {
 	buildDeps := make(map[string]bool)
 
 	for _, pkg := range manualPkgs {
@@ -552,15 +553,15 @@ func (u *UbuntuDistribution) installBuildDependencies(ctx context.Context, manua
 	for _, pkg := range manualPkgs {
 		switch pkg {
 		case "niri", "matugen":
-			if err := u.installRust(ctx, sudoPassword, progressChan); err != nil {
+			if err := u.installRust(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install Rust: %w", err)
 			}
 		case "ghostty":
-			if err := u.installZig(ctx, sudoPassword, progressChan); err != nil {
+			if err := u.installZig(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install Zig: %w", err)
 			}
 		case "cliphist", "dgop":
-			if err := u.installGo(ctx, sudoPassword, progressChan); err != nil {
+			if err := u.installGo(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install Go: %w", err)
 			}
 		}
@@ -578,12 +579,12 @@ func (u *UbuntuDistribution) installBuildDependencies(ctx context.Context, manua
 	args := []string{"apt-get", "install", "-y"}
 	args = append(args, depList...)
 
-	cmdStr := fmt.Sprintf("echo '%s' | sudo -S %s", sudoPassword, strings.Join(args, " "))
+	cmdStr := fmt.Sprintf("%s %s", privesc.ShellPrefix(), strings.Join(args, " "))
 	cmd := exec.CommandContext(ctx, "bash", "-c", cmdStr)
 	return u.runWithProgress(cmd, progressChan, PhaseSystemPackages, 0.80, 0.82)
 }
 
-func (u *UbuntuDistribution) installRust(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (u *UbuntuDistribution) installRust(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	if u.commandExists("cargo") {
 		return nil
 	}
@@ -598,7 +599,7 @@ func (u *UbuntuDistribution) installRust(ctx context.Context, sudoPassword strin
 	}
 
 	rustupInstallCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S apt-get install -y rustup", sudoPassword))
+		fmt.Sprintf("%s apt-get install -y rustup", privesc.ShellPrefix()))
 	if err := u.runWithProgress(rustupInstallCmd, progressChan, PhaseSystemPackages, 0.82, 0.83); err != nil {
 		return fmt.Errorf("failed to install rustup: %w", err)
 	}
@@ -624,7 +625,7 @@ func (u *UbuntuDistribution) installRust(ctx context.Context, sudoPassword strin
 	return nil
 }
 
-func (u *UbuntuDistribution) installZig(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (u *UbuntuDistribution) installZig(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	if u.commandExists("zig") {
 		return nil
 	}
@@ -648,17 +649,17 @@ func (u *UbuntuDistribution) installZig(ctx context.Context, sudoPassword string
 	}
 
 	extractCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S tar -xf %s -C /opt/", sudoPassword, zigTmp))
+		fmt.Sprintf("%s tar -xf %s -C /opt/", privesc.ShellPrefix(), zigTmp))
 	if err := u.runWithProgress(extractCmd, progressChan, PhaseSystemPackages, 0.85, 0.86); err != nil {
 		return fmt.Errorf("failed to extract Zig: %w", err)
 	}
 
 	linkCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S ln -sf /opt/zig-linux-x86_64-0.11.0/zig /usr/local/bin/zig", sudoPassword))
+		fmt.Sprintf("%s ln -sf /opt/zig-linux-x86_64-0.11.0/zig /usr/local/bin/zig", privesc.ShellPrefix()))
 	return u.runWithProgress(linkCmd, progressChan, PhaseSystemPackages, 0.86, 0.87)
 }
 
-func (u *UbuntuDistribution) installGo(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (u *UbuntuDistribution) installGo(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	if u.commandExists("go") {
 		return nil
 	}
@@ -673,7 +674,7 @@ func (u *UbuntuDistribution) installGo(ctx context.Context, sudoPassword string,
 	}
 
 	addPPACmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S add-apt-repository -y ppa:longsleep/golang-backports", sudoPassword))
+		fmt.Sprintf("%s add-apt-repository -y ppa:longsleep/golang-backports", privesc.ShellPrefix()))
 	if err := u.runWithProgress(addPPACmd, progressChan, PhaseSystemPackages, 0.87, 0.88); err != nil {
 		return fmt.Errorf("failed to add Go PPA: %w", err)
 	}
@@ -688,7 +689,7 @@ func (u *UbuntuDistribution) installGo(ctx context.Context, sudoPassword string,
 	}
 
 	updateCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S apt-get update", sudoPassword))
+		fmt.Sprintf("%s apt-get update", privesc.ShellPrefix()))
 	if err := u.runWithProgress(updateCmd, progressChan, PhaseSystemPackages, 0.88, 0.89); err != nil {
 		return fmt.Errorf("failed to update package lists after adding Go PPA: %w", err)
 	}
@@ -703,11 +704,11 @@ func (u *UbuntuDistribution) installGo(ctx context.Context, sudoPassword string,
 	}
 
 	installCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S apt-get install -y golang-go", sudoPassword))
+		fmt.Sprintf("%s apt-get install -y golang-go", privesc.ShellPrefix()))
 	return u.runWithProgress(installCmd, progressChan, PhaseSystemPackages, 0.89, 0.90)
 }
 
-func (u *UbuntuDistribution) installGhosttyUbuntu(ctx context.Context, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (u *UbuntuDistribution) installGhosttyUbuntu(ctx context.Context, progressChan chan<- InstallProgressMsg) error {
 	u.log("Installing Ghostty using Ubuntu installer script...")
 
 	progressChan <- InstallProgressMsg{
@@ -721,7 +722,7 @@ func (u *UbuntuDistribution) installGhosttyUbuntu(ctx context.Context, sudoPassw
 	}
 
 	installCmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("echo '%s' | sudo -S /bin/bash -c \"$(curl -fsSL https://raw.githubusercontent.com/mkasberg/ghostty-ubuntu/HEAD/install.sh)\"", sudoPassword))
+		fmt.Sprintf("%s /bin/bash -c \"$(curl -fsSL https://raw.githubusercontent.com/mkasberg/ghostty-ubuntu/HEAD/install.sh)\"", privesc.ShellPrefix()))
 
 	if err := u.runWithProgress(installCmd, progressChan, PhaseSystemPackages, 0.1, 0.9); err != nil {
 		return fmt.Errorf("failed to install Ghostty: %w", err)
@@ -732,7 +733,7 @@ func (u *UbuntuDistribution) installGhosttyUbuntu(ctx context.Context, sudoPassw
 }
 
 // Override InstallManualPackages for Ubuntu to handle Ubuntu-specific installations
-func (u *UbuntuDistribution) InstallManualPackages(ctx context.Context, packages []string, sudoPassword string, progressChan chan<- InstallProgressMsg) error {
+func (u *UbuntuDistribution) InstallManualPackages(ctx context.Context, packages []string, progressChan chan<- InstallProgressMsg) error {
 	if len(packages) == 0 {
 		return nil
 	}
@@ -742,12 +743,12 @@ func (u *UbuntuDistribution) InstallManualPackages(ctx context.Context, packages
 	for _, pkg := range packages {
 		switch pkg {
 		case "ghostty":
-			if err := u.installGhosttyUbuntu(ctx, sudoPassword, progressChan); err != nil {
+			if err := u.installGhosttyUbuntu(ctx, progressChan); err != nil {
 				return fmt.Errorf("failed to install ghostty: %w", err)
 			}
 		default:
 			// Use the base ManualPackageInstaller for other packages
-			if err := u.ManualPackageInstaller.InstallManualPackages(ctx, []string{pkg}, sudoPassword, progressChan); err != nil {
+			if err := u.ManualPackageInstaller.InstallManualPackages(ctx, []string{pkg}, progressChan); err != nil {
 				return fmt.Errorf("failed to install %s: %w", pkg, err)
 			}
 		}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -15,7 +16,6 @@ type AppState int
 const (
 	StateMainMenu AppState = iota
 	StateUpdate
-	StateUpdatePassword
 	StateUpdateProgress
 	StateShell
 	StatePluginsMenu
@@ -26,7 +26,6 @@ const (
 	StatePluginInstalledDetail
 	StateGreeterMenu
 	StateGreeterCompositorSelect
-	StateGreeterPassword
 	StateGreeterInstalling
 	StateAbout
 )
@@ -34,7 +33,7 @@ const (
 type Model struct {
 	version      string
 	detector     *Detector
-	dependencies []DependencyInfo
+	dependencies []deps.Dependency
 	state        AppState
 	selectedItem int
 	width        int
@@ -43,16 +42,20 @@ type Model struct {
 	// Menu items
 	menuItems []MenuItem
 
-	updateDeps        []DependencyInfo
+	updateDeps        []deps.Dependency
 	selectedUpdateDep int
 	updateToggles     map[string]bool
 
 	updateProgressChan chan updateProgressMsg
 	updateProgress     updateProgressMsg
 	updateLogs         []string
-	sudoPassword       string
-	passwordInput      string
-	passwordError      string
+
+	// passwordRequest is the broker prompt shown over the current state,
+	// nil when none is waiting
+	passwordRequests chan privesc.PasswordRequest
+	passwordRequest  *privesc.PasswordRequest
+	passwordInput    string
+	rememberSudo     bool
 
 	// Window manager states
 	hyprlandInstalled bool
@@ -63,10 +66,6 @@ type Model struct {
 	greeterInstallChan      chan greeterProgressMsg
 	greeterProgress         greeterProgressMsg
 	greeterLogs             []string
-	greeterNeedsPassword    bool
-	greeterPasswordInput    string
-	greeterPasswordError    string
-	greeterSudoPassword     string
 	greeterCompositors      []string
 	greeterSelectedComp     int
 	greeterChosenCompositor string
@@ -137,6 +136,7 @@ func NewModel(version string) Model {
 		hyprlandInstalled:   hyprlandInstalled,
 		niriInstalled:       niriInstalled,
 		greeterInstallChan:  make(chan greeterProgressMsg, 100),
+		passwordRequests:    make(chan privesc.PasswordRequest),
 		pluginInstallStatus: make(map[string]bool),
 	}
 
@@ -181,7 +181,7 @@ func (m *Model) isShellRunning() bool {
 }
 
 func (m Model) Init() tea.Cmd {
-	return nil
+	return m.listenForPasswordRequests()
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -282,42 +282,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.pluginsError = ""
 		}
 		return m, nil
-	case greeterPasswordValidMsg:
-		if msg.valid {
-			m.greeterSudoPassword = msg.password
-			m.greeterPasswordInput = ""
-			m.greeterPasswordError = ""
-			m.state = StateGreeterInstalling
-			m.greeterProgress = greeterProgressMsg{step: "Starting greeter installation..."}
-			m.greeterLogs = []string{}
-			return m, tea.Batch(m.performGreeterInstall(), m.waitForGreeterProgress())
-		} else {
-			m.greeterPasswordError = "Incorrect password. Please try again."
-			m.greeterPasswordInput = ""
-		}
-		return m, nil
-	case passwordValidMsg:
-		if msg.valid {
-			m.sudoPassword = msg.password
-			m.passwordInput = ""
-			m.passwordError = ""
-			m.state = StateUpdateProgress
-			m.updateProgress = updateProgressMsg{progress: 0.0, step: "Starting update..."}
-			m.updateLogs = []string{}
-			return m, tea.Batch(m.performUpdate(), m.waitForProgress())
-		} else {
-			m.passwordError = "Incorrect password. Please try again."
-			m.passwordInput = ""
-		}
+	case passwordRequestMsg:
+		req := privesc.PasswordRequest(msg)
+		m.passwordRequest = &req
+		m.passwordInput = ""
 		return m, nil
 	case tea.KeyMsg:
+		if m.passwordRequest != nil {
+			return m.updatePasswordView(msg)
+		}
 		switch m.state {
 		case StateMainMenu:
 			return m.updateMainMenu(msg)
 		case StateUpdate:
 			return m.updateUpdateView(msg)
-		case StateUpdatePassword:
-			return m.updatePasswordView(msg)
 		case StateUpdateProgress:
 			return m.updateProgressView(msg)
 		case StateShell:
@@ -338,8 +316,6 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateGreeterMenu(msg)
 		case StateGreeterCompositorSelect:
 			return m.updateGreeterCompositorSelect(msg)
-		case StateGreeterPassword:
-			return m.updateGreeterPasswordView(msg)
 		case StateGreeterInstalling:
 			return m.updateGreeterInstalling(msg)
 		case StateAbout:
@@ -362,10 +338,7 @@ type updateCompleteMsg struct {
 	err error
 }
 
-type passwordValidMsg struct {
-	password string
-	valid    bool
-}
+type passwordRequestMsg privesc.PasswordRequest
 
 type greeterProgressMsg struct {
 	step      string
@@ -374,11 +347,6 @@ type greeterProgressMsg struct {
 	logOutput string
 }
 
-type greeterPasswordValidMsg struct {
-	password string
-	valid    bool
-}
-
 func (m Model) waitForProgress() tea.Cmd {
 	return func() tea.Msg {
 		return <-m.updateProgressChan
@@ -392,13 +360,15 @@ func (m Model) waitForGreeterProgress() tea.Cmd {
 }
 
 func (m Model) View() string {
+	if m.passwordRequest != nil {
+		return m.renderPasswordView()
+	}
+
 	switch m.state {
 	case StateMainMenu:
 		return m.renderMainMenu()
 	case StateUpdate:
 		return m.renderUpdateView()
-	case StateUpdatePassword:
-		return m.renderPasswordView()
 	case StateUpdateProgress:
 		return m.renderProgressView()
 	case StateShell:
@@ -419,8 +389,6 @@ func (m Model) View() string {
 		return m.renderGreeterMenu()
 	case StateGreeterCompositorSelect:
 		return m.renderGreeterCompositorSelect()
-	case StateGreeterPassword:
-		return m.renderGreeterPasswordView()
 	case StateGreeterInstalling:
 		return m.renderGreeterInstalling()
 	case StateAbout:
@@ -6,6 +6,8 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -26,7 +28,7 @@ const (
 type Model struct {
 	version      string
 	detector     *Detector
-	dependencies []DependencyInfo
+	dependencies []deps.Dependency
 	state        AppState
 	selectedItem int
 	width        int
@@ -259,3 +261,11 @@ func (m Model) View() string {
 		return m.renderMainMenu()
 	}
 }
+
+// PasswordPrompter declines every prompt; packages are updated by the
+// distribution, so this build has no privileged steps
+func (m Model) PasswordPrompter() privesc.Prompter {
+	return func(string, bool) (string, bool, bool) {
+		return "", false, false
+	}
+}
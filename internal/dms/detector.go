@@ -116,27 +116,22 @@ func (d *Detector) commandExists(cmd string) bool {
 	return err == nil
 }
 
-func (d *Detector) GetInstalledComponents() []DependencyInfo {
+func (d *Detector) GetInstalledComponents() []deps.Dependency {
 	dependencies, err := d.GetDependencyStatus()
 	if err != nil {
-		return []DependencyInfo{}
+		return []deps.Dependency{}
 	}
 
 	isNixOS := d.isNixOS()
 
-	var components []DependencyInfo
+	var components []deps.Dependency
 	for _, dep := range dependencies {
 		// On NixOS, filter out the window managers themselves but keep their components
 		if isNixOS && (dep.Name == "hyprland" || dep.Name == "niri") {
 			continue
 		}
 
-		components = append(components, DependencyInfo{
-			Name:        dep.Name,
-			Status:      dep.Status,
-			Description: dep.Description,
-			Required:    dep.Required,
-		})
+		components = append(components, dep)
 	}
 
 	return components
@@ -158,10 +153,3 @@ func (d *Detector) isNixOS() bool {
 
 	return false
 }
-
-type DependencyInfo struct {
-	Name        string
-	Status      deps.DependencyStatus
-	Description string
-	Required    bool
-}
@@ -5,13 +5,11 @@ package dms
 import (
 	"context"
 	"fmt"
-	"os/exec"
-	"strings"
-	"time"
 
 	"github.com/AvengeMedia/danklinux/internal/deps"
 	"github.com/AvengeMedia/danklinux/internal/distros"
 	"github.com/AvengeMedia/danklinux/internal/greeter"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -50,28 +48,50 @@ func (m Model) updateUpdateView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
-		m.state = StateUpdatePassword
-		m.passwordInput = ""
-		m.passwordError = ""
-		return m, nil
+		// Privileged steps ask for a password through the broker when
+		// they need one
+		m.state = StateUpdateProgress
+		m.updateProgress = updateProgressMsg{progress: 0.0, step: "Starting update..."}
+		m.updateLogs = []string{}
+		return m, tea.Batch(m.performUpdate(), m.waitForProgress())
 	}
 	return m, nil
 }
 
+// PasswordPrompter shows each broker prompt as a modal over the current
+// screen
+func (m Model) PasswordPrompter() privesc.Prompter {
+	return privesc.ChannelPrompter(m.passwordRequests)
+}
+
+func (m Model) listenForPasswordRequests() tea.Cmd {
+	return func() tea.Msg {
+		return passwordRequestMsg(<-m.passwordRequests)
+	}
+}
+
+// updatePasswordView answers the waiting broker prompt. The broker checks
+// the password and asks again with Retry set when it is wrong
 func (m Model) updatePasswordView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+c":
 		return m, tea.Quit
 	case "esc":
-		m.state = StateUpdate
+		m.passwordRequest.Cancel()
+		m.passwordRequest = nil
 		m.passwordInput = ""
-		m.passwordError = ""
+		return m, m.listenForPasswordRequests()
+	case "tab":
+		m.rememberSudo = !m.rememberSudo
 		return m, nil
 	case "enter":
 		if m.passwordInput == "" {
 			return m, nil
 		}
-		return m, m.validatePassword(m.passwordInput)
+		m.passwordRequest.Answer(m.passwordInput, m.rememberSudo)
+		m.passwordRequest = nil
+		m.passwordInput = ""
+		return m, m.listenForPasswordRequests()
 	case "backspace":
 		if len(m.passwordInput) > 0 {
 			m.passwordInput = m.passwordInput[:len(m.passwordInput)-1]
@@ -98,38 +118,6 @@ func (m Model) updateProgressView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m Model) validatePassword(password string) tea.Cmd {
-	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-
-		cmd := exec.CommandContext(ctx, "sudo", "-S", "-v")
-		stdin, err := cmd.StdinPipe()
-		if err != nil {
-			return passwordValidMsg{password: "", valid: false}
-		}
-
-		go func() {
-			defer stdin.Close()
-			fmt.Fprintf(stdin, "%s\n", password)
-		}()
-
-		output, err := cmd.CombinedOutput()
-		outputStr := string(output)
-
-		if err != nil {
-			if strings.Contains(outputStr, "Sorry, try again") ||
-				strings.Contains(outputStr, "incorrect password") ||
-				strings.Contains(outputStr, "authentication failure") {
-				return passwordValidMsg{password: "", valid: false}
-			}
-			return passwordValidMsg{password: "", valid: false}
-		}
-
-		return passwordValidMsg{password: password, valid: true}
-	}
-}
-
 func (m Model) performUpdate() tea.Cmd {
 	var depsToUpdate []deps.Dependency
 
@@ -155,7 +143,6 @@ func (m Model) performUpdate() tea.Cmd {
 		wm = deps.WindowManagerNiri
 	}
 
-	sudoPassword := m.sudoPassword
 	reinstallFlags := make(map[string]bool)
 	for name, toggled := range m.updateToggles {
 		if toggled {
@@ -171,7 +158,7 @@ func (m Model) performUpdate() tea.Cmd {
 
 		go func() {
 			ctx := context.Background()
-			err := distribution.InstallPackages(ctx, depsToUpdate, wm, sudoPassword, reinstallFlags, installerChan)
+			err := distribution.InstallPackages(ctx, depsToUpdate, wm, reinstallFlags, installerChan)
 			close(installerChan)
 
 			if err != nil {
@@ -228,10 +215,7 @@ func (m Model) updateGreeterMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				return m, nil
 			} else {
 				m.greeterChosenCompositor = compositors[0]
-				m.state = StateGreeterPassword
-				m.greeterPasswordInput = ""
-				m.greeterPasswordError = ""
-				return m, nil
+				return m.startGreeterInstall()
 			}
 		}
 	}
@@ -255,38 +239,16 @@ func (m Model) updateGreeterCompositorSelect(msg tea.KeyMsg) (tea.Model, tea.Cmd
 		}
 	case "enter", " ":
 		m.greeterChosenCompositor = m.greeterCompositors[m.greeterSelectedComp]
-		m.state = StateGreeterPassword
-		m.greeterPasswordInput = ""
-		m.greeterPasswordError = ""
-		return m, nil
+		return m.startGreeterInstall()
 	}
 	return m, nil
 }
 
-func (m Model) updateGreeterPasswordView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "ctrl+c":
-		return m, tea.Quit
-	case "esc":
-		m.state = StateGreeterMenu
-		m.greeterPasswordInput = ""
-		m.greeterPasswordError = ""
-		return m, nil
-	case "enter":
-		if m.greeterPasswordInput == "" {
-			return m, nil
-		}
-		return m, m.validateGreeterPassword(m.greeterPasswordInput)
-	case "backspace":
-		if len(m.greeterPasswordInput) > 0 {
-			m.greeterPasswordInput = m.greeterPasswordInput[:len(m.greeterPasswordInput)-1]
-		}
-	default:
-		if len(msg.String()) == 1 && msg.String()[0] >= 32 && msg.String()[0] <= 126 {
-			m.greeterPasswordInput += msg.String()
-		}
-	}
-	return m, nil
+func (m Model) startGreeterInstall() (tea.Model, tea.Cmd) {
+	m.state = StateGreeterInstalling
+	m.greeterProgress = greeterProgressMsg{step: "Starting greeter installation..."}
+	m.greeterLogs = []string{}
+	return m, tea.Batch(m.performGreeterInstall(), m.waitForGreeterProgress())
 }
 
 func (m Model) updateGreeterInstalling(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -305,7 +267,6 @@ func (m Model) updateGreeterInstalling(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 func (m Model) performGreeterInstall() tea.Cmd {
 	progressChan := m.greeterInstallChan
-	sudoPassword := m.greeterSudoPassword
 	compositor := m.greeterChosenCompositor
 
 	return func() tea.Msg {
@@ -315,7 +276,7 @@ func (m Model) performGreeterInstall() tea.Cmd {
 			}
 
 			progressChan <- greeterProgressMsg{step: "Checking greetd installation..."}
-			if err := performGreeterInstallSteps(progressChan, logFunc, sudoPassword, compositor); err != nil {
+			if err := performGreeterInstallSteps(progressChan, logFunc, compositor); err != nil {
 				progressChan <- greeterProgressMsg{step: "Installation failed", complete: true, err: err}
 				return
 			}
@@ -326,40 +287,8 @@ func (m Model) performGreeterInstall() tea.Cmd {
 	}
 }
 
-func (m Model) validateGreeterPassword(password string) tea.Cmd {
-	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-
-		cmd := exec.CommandContext(ctx, "sudo", "-S", "-v")
-		stdin, err := cmd.StdinPipe()
-		if err != nil {
-			return greeterPasswordValidMsg{password: "", valid: false}
-		}
-
-		go func() {
-			defer stdin.Close()
-			fmt.Fprintf(stdin, "%s\n", password)
-		}()
-
-		output, err := cmd.CombinedOutput()
-		outputStr := string(output)
-
-		if err != nil {
-			if strings.Contains(outputStr, "Sorry, try again") ||
-				strings.Contains(outputStr, "incorrect password") ||
-				strings.Contains(outputStr, "authentication failure") {
-				return greeterPasswordValidMsg{password: "", valid: false}
-			}
-			return greeterPasswordValidMsg{password: "", valid: false}
-		}
-
-		return greeterPasswordValidMsg{password: password, valid: true}
-	}
-}
-
-func performGreeterInstallSteps(progressChan chan greeterProgressMsg, logFunc func(string), sudoPassword string, compositor string) error {
-	if err := greeter.EnsureGreetdInstalled(logFunc, sudoPassword); err != nil {
+func performGreeterInstallSteps(progressChan chan greeterProgressMsg, logFunc func(string), compositor string) error {
+	if err := greeter.EnsureGreetdInstalled(logFunc); err != nil {
 		return err
 	}
 
@@ -373,17 +302,17 @@ func performGreeterInstallSteps(progressChan chan greeterProgressMsg, logFunc fu
 	logFunc(fmt.Sprintf("✓ Selected compositor: %s", compositor))
 
 	progressChan <- greeterProgressMsg{step: "Copying greeter files..."}
-	if err := greeter.CopyGreeterFiles(dmsPath, compositor, logFunc, sudoPassword); err != nil {
+	if err := greeter.CopyGreeterFiles(dmsPath, compositor, logFunc); err != nil {
 		return err
 	}
 
 	progressChan <- greeterProgressMsg{step: "Configuring greetd..."}
-	if err := greeter.ConfigureGreetd(dmsPath, compositor, logFunc, sudoPassword); err != nil {
+	if err := greeter.ConfigureGreetd(dmsPath, compositor, logFunc); err != nil {
 		return err
 	}
 
 	progressChan <- greeterProgressMsg{step: "Synchronizing DMS configurations..."}
-	if err := greeter.SyncDMSConfigs(dmsPath, logFunc, sudoPassword); err != nil {
+	if err := greeter.SyncDMSConfigs(dmsPath, logFunc); err != nil {
 		return err
 	}
 
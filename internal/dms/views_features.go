@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/AvengeMedia/danklinux/internal/deps"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -121,7 +122,7 @@ func (m Model) renderPasswordView() string {
 	normalStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#FFFFFF"))
 
-	b.WriteString(normalStyle.Render("Package installation requires sudo privileges."))
+	b.WriteString(normalStyle.Render("The next step requires sudo privileges."))
 	b.WriteString("\n")
 	b.WriteString(normalStyle.Render("Please enter your password to continue:"))
 	b.WriteString("\n\n")
@@ -133,10 +134,17 @@ func (m Model) renderPasswordView() string {
 	b.WriteString(inputStyle.Render("Password: " + maskedPassword))
 	b.WriteString("\n")
 
-	if m.passwordError != "" {
+	remember := "[ ] Remember for this run"
+	if m.rememberSudo {
+		remember = "[x] Remember for this run"
+	}
+	b.WriteString(normalStyle.Render(remember))
+	b.WriteString("\n")
+
+	if m.passwordRequest.Retry {
 		errorStyle := lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#FF0000"))
-		b.WriteString(errorStyle.Render("✗ " + m.passwordError))
+		b.WriteString(errorStyle.Render("✗ Incorrect password. Please try again."))
 		b.WriteString("\n")
 	}
 
@@ -145,7 +153,7 @@ func (m Model) renderPasswordView() string {
 		Foreground(lipgloss.Color("#888888")).
 		MarginTop(1)
 
-	instructions := "Enter: Continue, Esc: Back, Ctrl+C: Cancel"
+	instructions := "Enter: Continue, Tab: Toggle remember, Esc: Cancel step, Ctrl+C: Quit"
 	b.WriteString(instructionStyle.Render(instructions))
 
 	return b.String()
@@ -250,9 +258,9 @@ func (m Model) renderProgressView() string {
 	return b.String()
 }
 
-func (m Model) getFilteredDeps() []DependencyInfo {
+func (m Model) getFilteredDeps() []deps.Dependency {
 	categories := m.categorizeDependencies()
-	var filtered []DependencyInfo
+	var filtered []deps.Dependency
 
 	for _, category := range []string{"Shell", "Shared Components", "Hyprland Components", "Niri Components"} {
 		deps, exists := categories[category]
@@ -264,7 +272,7 @@ func (m Model) getFilteredDeps() []DependencyInfo {
 	return filtered
 }
 
-func (m Model) getDepAtVisualIndex(index int) *DependencyInfo {
+func (m Model) getDepAtVisualIndex(index int) *deps.Dependency {
 	filtered := m.getFilteredDeps()
 	if index >= 0 && index < len(filtered) {
 		return &filtered[index]
@@ -272,53 +280,6 @@ func (m Model) getDepAtVisualIndex(index int) *DependencyInfo {
 	return nil
 }
 
-func (m Model) renderGreeterPasswordView() string {
-	var b strings.Builder
-
-	b.WriteString(m.renderBanner())
-	b.WriteString("\n")
-
-	headerStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Bold(true).
-		MarginBottom(1)
-
-	b.WriteString(headerStyle.Render("Sudo Authentication"))
-	b.WriteString("\n\n")
-
-	normalStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FFFFFF"))
-
-	b.WriteString(normalStyle.Render("Greeter installation requires sudo privileges."))
-	b.WriteString("\n")
-	b.WriteString(normalStyle.Render("Please enter your password to continue:"))
-	b.WriteString("\n\n")
-
-	inputStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#00D4AA"))
-
-	maskedPassword := strings.Repeat("*", len(m.greeterPasswordInput))
-	b.WriteString(inputStyle.Render("Password: " + maskedPassword))
-	b.WriteString("\n")
-
-	if m.greeterPasswordError != "" {
-		errorStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF0000"))
-		b.WriteString(errorStyle.Render("✗ " + m.greeterPasswordError))
-		b.WriteString("\n")
-	}
-
-	b.WriteString("\n")
-	instructionStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#888888")).
-		MarginTop(1)
-
-	instructions := "Enter: Continue, Esc: Back, Ctrl+C: Cancel"
-	b.WriteString(instructionStyle.Render(instructions))
-
-	return b.String()
-}
-
 func (m Model) renderGreeterCompositorSelect() string {
 	var b strings.Builder
 
@@ -488,8 +449,8 @@ func (m Model) renderGreeterInstalling() string {
 	return b.String()
 }
 
-func (m Model) categorizeDependencies() map[string][]DependencyInfo {
-	categories := map[string][]DependencyInfo{
+func (m Model) categorizeDependencies() map[string][]deps.Dependency {
+	categories := map[string][]deps.Dependency{
 		"Shell":               {},
 		"Shared Components":   {},
 		"Hyprland Components": {},
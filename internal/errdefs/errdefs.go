@@ -13,6 +13,7 @@ const (
 	ErrTypeInvalidGamma
 	ErrTypeInvalidLocation
 	ErrTypeInvalidManualTimes
+	ErrTypeInvalidTransition
 	ErrTypeNoWaylandDisplay
 	ErrTypeNoGammaControl
 	ErrTypeNotInitialized
@@ -47,6 +48,9 @@ const (
 	ErrWifiDisabled     = "wifi-disabled"
 	ErrAlreadyConnected = "already-connected"
 	ErrConnectionFailed = "connection-failed"
+	ErrWPSTimeout       = "wps-timeout"
+	ErrWPSOverlap       = "wps-overlap"
+	ErrSuperseded       = "superseded"
 )
 
 var (
@@ -56,6 +60,7 @@ var (
 	ErrInvalidGamma          = NewCustomError(ErrTypeInvalidGamma, "gamma must be between 0 and 10")
 	ErrInvalidLocation       = NewCustomError(ErrTypeInvalidLocation, "invalid latitude/longitude")
 	ErrInvalidManualTimes    = NewCustomError(ErrTypeInvalidManualTimes, "both sunrise and sunset must be set or neither")
+	ErrInvalidTransition     = NewCustomError(ErrTypeInvalidTransition, "transition must be between 0 and 180 minutes")
 	ErrNoWaylandDisplay      = NewCustomError(ErrTypeNoWaylandDisplay, "no wayland display available")
 	ErrNoGammaControl        = NewCustomError(ErrTypeNoGammaControl, "compositor does not support gamma control")
 	ErrNotInitialized        = NewCustomError(ErrTypeNotInitialized, "manager not initialized")
@@ -11,6 +11,8 @@ import (
 
 	"github.com/AvengeMedia/danklinux/internal/config"
 	"github.com/AvengeMedia/danklinux/internal/distros"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
+	"github.com/AvengeMedia/danklinux/internal/utils"
 )
 
 // DetectDMSPath checks for DMS installation following XDG Base Directory specification
@@ -61,7 +63,7 @@ func PromptCompositorChoice(compositors []string) (string, error) {
 }
 
 // EnsureGreetdInstalled checks if greetd is installed and installs it if not
-func EnsureGreetdInstalled(logFunc func(string), sudoPassword string) error {
+func EnsureGreetdInstalled(logFunc func(string)) error {
 	if commandExists("greetd") {
 		logFunc("✓ greetd is already installed")
 		return nil
@@ -84,44 +86,19 @@ func EnsureGreetdInstalled(logFunc func(string), sudoPassword string) error {
 
 	switch config.Family {
 	case distros.FamilyArch:
-		if sudoPassword != "" {
-			installCmd = exec.CommandContext(ctx, "bash", "-c",
-				fmt.Sprintf("echo '%s' | sudo -S pacman -S --needed --noconfirm greetd", sudoPassword))
-		} else {
-			installCmd = exec.CommandContext(ctx, "sudo", "pacman", "-S", "--needed", "--noconfirm", "greetd")
-		}
+		installCmd = privesc.Command(ctx, "pacman", "-S", "--needed", "--noconfirm", "greetd")
 
 	case distros.FamilyFedora:
-		if sudoPassword != "" {
-			installCmd = exec.CommandContext(ctx, "bash", "-c",
-				fmt.Sprintf("echo '%s' | sudo -S dnf install -y greetd", sudoPassword))
-		} else {
-			installCmd = exec.CommandContext(ctx, "sudo", "dnf", "install", "-y", "greetd")
-		}
+		installCmd = privesc.Command(ctx, "dnf", "install", "-y", "greetd")
 
 	case distros.FamilySUSE:
-		if sudoPassword != "" {
-			installCmd = exec.CommandContext(ctx, "bash", "-c",
-				fmt.Sprintf("echo '%s' | sudo -S zypper install -y greetd", sudoPassword))
-		} else {
-			installCmd = exec.CommandContext(ctx, "sudo", "zypper", "install", "-y", "greetd")
-		}
+		installCmd = privesc.Command(ctx, "zypper", "install", "-y", "greetd")
 
 	case distros.FamilyUbuntu:
-		if sudoPassword != "" {
-			installCmd = exec.CommandContext(ctx, "bash", "-c",
-				fmt.Sprintf("echo '%s' | sudo -S apt-get install -y greetd", sudoPassword))
-		} else {
-			installCmd = exec.CommandContext(ctx, "sudo", "apt-get", "install", "-y", "greetd")
-		}
+		installCmd = privesc.Command(ctx, "apt-get", "install", "-y", "greetd")
 
 	case distros.FamilyDebian:
-		if sudoPassword != "" {
-			installCmd = exec.CommandContext(ctx, "bash", "-c",
-				fmt.Sprintf("echo '%s' | sudo -S apt-get install -y greetd", sudoPassword))
-		} else {
-			installCmd = exec.CommandContext(ctx, "sudo", "apt-get", "install", "-y", "greetd")
-		}
+		installCmd = privesc.Command(ctx, "apt-get", "install", "-y", "greetd")
 
 	case distros.FamilyNix:
 		return fmt.Errorf("on NixOS, please add greetd to your configuration.nix")
@@ -142,7 +119,7 @@ func EnsureGreetdInstalled(logFunc func(string), sudoPassword string) error {
 }
 
 // CopyGreeterFiles installs the dms-greeter wrapper and sets up cache directory
-func CopyGreeterFiles(dmsPath, compositor string, logFunc func(string), sudoPassword string) error {
+func CopyGreeterFiles(dmsPath, compositor string, logFunc func(string)) error {
 	// Check if dms-greeter is already in PATH
 	if commandExists("dms-greeter") {
 		logFunc("✓ dms-greeter wrapper already installed")
@@ -156,12 +133,12 @@ func CopyGreeterFiles(dmsPath, compositor string, logFunc func(string), sudoPass
 		}
 
 		wrapperDst := "/usr/local/bin/dms-greeter"
-		if err := runSudoCmd(sudoPassword, "cp", wrapperSrc, wrapperDst); err != nil {
+		if err := runSudoCmd("cp", wrapperSrc, wrapperDst); err != nil {
 			return fmt.Errorf("failed to copy dms-greeter wrapper: %w", err)
 		}
 		logFunc(fmt.Sprintf("✓ Installed dms-greeter wrapper to %s", wrapperDst))
 
-		if err := runSudoCmd(sudoPassword, "chmod", "+x", wrapperDst); err != nil {
+		if err := runSudoCmd("chmod", "+x", wrapperDst); err != nil {
 			return fmt.Errorf("failed to make wrapper executable: %w", err)
 		}
 
@@ -169,13 +146,13 @@ func CopyGreeterFiles(dmsPath, compositor string, logFunc func(string), sudoPass
 		osInfo, err := distros.GetOSInfo()
 		if err == nil {
 			if config, exists := distros.Registry[osInfo.Distribution.ID]; exists && (config.Family == distros.FamilyFedora || config.Family == distros.FamilySUSE) {
-				if err := runSudoCmd(sudoPassword, "semanage", "fcontext", "-a", "-t", "bin_t", wrapperDst); err != nil {
+				if err := runSudoCmd("semanage", "fcontext", "-a", "-t", "bin_t", wrapperDst); err != nil {
 					logFunc(fmt.Sprintf("⚠ Warning: Failed to set SELinux fcontext: %v", err))
 				} else {
 					logFunc("✓ Set SELinux fcontext for dms-greeter")
 				}
 
-				if err := runSudoCmd(sudoPassword, "restorecon", "-v", wrapperDst); err != nil {
+				if err := runSudoCmd("restorecon", "-v", wrapperDst); err != nil {
 					logFunc(fmt.Sprintf("⚠ Warning: Failed to restore SELinux context: %v", err))
 				} else {
 					logFunc("✓ Restored SELinux context for dms-greeter")
@@ -185,16 +162,15 @@ func CopyGreeterFiles(dmsPath, compositor string, logFunc func(string), sudoPass
 	}
 
 	// Create cache directory with proper permissions
-	cacheDir := "/var/cache/dms-greeter"
-	if err := runSudoCmd(sudoPassword, "mkdir", "-p", cacheDir); err != nil {
+	if err := runSudoCmd("mkdir", "-p", cacheDir); err != nil {
 		return fmt.Errorf("failed to create cache directory: %w", err)
 	}
 
-	if err := runSudoCmd(sudoPassword, "chown", "greeter:greeter", cacheDir); err != nil {
+	if err := runSudoCmd("chown", "greeter:greeter", cacheDir); err != nil {
 		return fmt.Errorf("failed to set cache directory owner: %w", err)
 	}
 
-	if err := runSudoCmd(sudoPassword, "chmod", "750", cacheDir); err != nil {
+	if err := runSudoCmd("chmod", "750", cacheDir); err != nil {
 		return fmt.Errorf("failed to set cache directory permissions: %w", err)
 	}
 	logFunc(fmt.Sprintf("✓ Created cache directory %s (owner: greeter:greeter, permissions: 750)", cacheDir))
@@ -202,7 +178,7 @@ func CopyGreeterFiles(dmsPath, compositor string, logFunc func(string), sudoPass
 	return nil
 }
 
-func SetupDMSGroup(logFunc func(string), sudoPassword string) error {
+func SetupDMSGroup(logFunc func(string)) error {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return fmt.Errorf("failed to get user home directory: %w", err)
@@ -217,7 +193,12 @@ func SetupDMSGroup(logFunc func(string), sudoPassword string) error {
 	}
 
 	// Add current user to greeter group for file access permissions
-	if err := runSudoCmd(sudoPassword, "usermod", "-aG", "greeter", currentUser); err != nil {
+	if privesc.HelperAvailable() {
+		err = privesc.RunHelper("greeter-group")
+	} else {
+		err = runSudoCmd("usermod", "-aG", "greeter", currentUser)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to add %s to greeter group: %w", currentUser, err)
 	}
 	logFunc(fmt.Sprintf("✓ Added %s to greeter group (logout/login required for changes to take effect)", currentUser))
@@ -240,12 +221,12 @@ func SetupDMSGroup(logFunc func(string), sudoPassword string) error {
 			}
 		}
 
-		if err := runSudoCmd(sudoPassword, "chgrp", "-R", "greeter", dir.path); err != nil {
+		if err := runSudoCmd("chgrp", "-R", "greeter", dir.path); err != nil {
 			logFunc(fmt.Sprintf("⚠ Warning: Failed to set group for %s: %v", dir.desc, err))
 			continue
 		}
 
-		if err := runSudoCmd(sudoPassword, "chmod", "-R", "g+rX", dir.path); err != nil {
+		if err := runSudoCmd("chmod", "-R", "g+rX", dir.path); err != nil {
 			logFunc(fmt.Sprintf("⚠ Warning: Failed to set permissions for %s: %v", dir.desc, err))
 			continue
 		}
@@ -256,14 +237,20 @@ func SetupDMSGroup(logFunc func(string), sudoPassword string) error {
 	return nil
 }
 
-func SyncDMSConfigs(dmsPath string, logFunc func(string), sudoPassword string) error {
+// JoinGreeterGroup is the root side of the greeter-group helper action
+func JoinGreeterGroup(username string) error {
+	if output, err := exec.Command("usermod", "-aG", "greeter", username).CombinedOutput(); err != nil {
+		return fmt.Errorf("usermod failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func SyncDMSConfigs(dmsPath string, logFunc func(string)) error {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return fmt.Errorf("failed to get user home directory: %w", err)
 	}
 
-	cacheDir := "/var/cache/dms-greeter"
-
 	symlinks := []struct {
 		source string
 		target string
@@ -296,15 +283,15 @@ func SyncDMSConfigs(dmsPath string, logFunc func(string), sudoPassword string) e
 		}
 
 		if _, err := os.Stat(link.source); os.IsNotExist(err) {
-			if err := os.WriteFile(link.source, []byte("{}"), 0644); err != nil {
+			if err := utils.WriteFileAtomic(link.source, []byte("{}"), 0644); err != nil {
 				logFunc(fmt.Sprintf("⚠ Warning: Could not create %s: %v", link.source, err))
 				continue
 			}
 		}
 
-		runSudoCmd(sudoPassword, "rm", "-f", link.target)
+		runSudoCmd("rm", "-f", link.target)
 
-		if err := runSudoCmd(sudoPassword, "ln", "-sf", link.source, link.target); err != nil {
+		if err := runSudoCmd("ln", "-sf", link.source, link.target); err != nil {
 			logFunc(fmt.Sprintf("⚠ Warning: Failed to create symlink for %s: %v", link.desc, err))
 			continue
 		}
@@ -315,12 +302,12 @@ func SyncDMSConfigs(dmsPath string, logFunc func(string), sudoPassword string) e
 	return nil
 }
 
-func ConfigureGreetd(dmsPath, compositor string, logFunc func(string), sudoPassword string) error {
+func ConfigureGreetd(dmsPath, compositor string, logFunc func(string)) error {
 	configPath := "/etc/greetd/config.toml"
 
 	if _, err := os.Stat(configPath); err == nil {
 		backupPath := configPath + ".backup"
-		if err := runSudoCmd(sudoPassword, "cp", configPath, backupPath); err != nil {
+		if err := runSudoCmd("cp", configPath, backupPath); err != nil {
 			return fmt.Errorf("failed to backup config: %w", err)
 		}
 		logFunc(fmt.Sprintf("✓ Backed up existing config to %s", backupPath))
@@ -388,35 +375,47 @@ user = "greeter"
 
 	newConfig := strings.Join(finalLines, "\n")
 
-	tmpFile := "/tmp/greetd-config.toml"
-	if err := os.WriteFile(tmpFile, []byte(newConfig), 0644); err != nil {
-		return fmt.Errorf("failed to write temp config: %w", err)
-	}
-
-	if err := runSudoCmd(sudoPassword, "mv", tmpFile, configPath); err != nil {
-		return fmt.Errorf("failed to move config to /etc/greetd: %w", err)
+	if err := installSystemFile([]byte(newConfig), configPath, "644"); err != nil {
+		return fmt.Errorf("failed to write config to /etc/greetd: %w", err)
 	}
 
 	logFunc(fmt.Sprintf("✓ Updated greetd configuration (user: greeter, command: %s --command %s -p %s)", wrapperCmd, compositorLower, dmsPath))
 	return nil
 }
 
-func runSudoCmd(sudoPassword string, command string, args ...string) error {
-	var cmd *exec.Cmd
-
-	if sudoPassword != "" {
-		fullArgs := append([]string{command}, args...)
-		quotedArgs := make([]string, len(fullArgs))
-		for i, arg := range fullArgs {
-			quotedArgs[i] = "'" + strings.ReplaceAll(arg, "'", "'\\''") + "'"
-		}
-		cmdStr := strings.Join(quotedArgs, " ")
+// installSystemFile replaces a root-owned file with data atomically: it is
+// installed next to dest with mode, synced, and renamed over dest
+func installSystemFile(data []byte, dest, mode string) error {
+	f, err := os.CreateTemp("", "dms-greeter-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
 
-		cmd = exec.Command("bash", "-c", fmt.Sprintf("echo '%s' | sudo -S %s", sudoPassword, cmdStr))
-	} else {
-		cmd = exec.Command("sudo", append([]string{command}, args...)...)
+	staged := dest + ".dms-tmp"
+	if err := runSudoCmd("install", "-m", mode, f.Name(), staged); err != nil {
+		return err
+	}
+	if err := runSudoCmd("sync", staged); err != nil {
+		runSudoCmd("rm", "-f", staged)
+		return err
 	}
+	if err := runSudoCmd("mv", "-f", staged, dest); err != nil {
+		runSudoCmd("rm", "-f", staged)
+		return err
+	}
+	return nil
+}
 
+func runSudoCmd(command string, args ...string) error {
+	cmd := privesc.Command(context.Background(), command, args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
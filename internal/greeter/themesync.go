@@ -0,0 +1,365 @@
+package greeter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+const (
+	cacheDir          = "/var/cache/dms-greeter"
+	themeSyncInterval = 3 * time.Second
+)
+
+// ThemeSyncPaths are the user's theme files and the greeter cache they are
+// copied into. Copies, unlike the symlinks SyncDMSConfigs creates, don't need
+// the greeter to read the user's home
+type ThemeSyncPaths struct {
+	Settings string
+	Session  string
+	Colors   string
+	CacheDir string
+	Config   string
+}
+
+func DefaultThemeSyncPaths() ThemeSyncPaths {
+	home := os.Getenv("HOME")
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+
+	return ThemeSyncPaths{
+		Settings: filepath.Join(home, ".config", "DankMaterialShell", "settings.json"),
+		Session:  filepath.Join(home, ".local", "state", "DankMaterialShell", "session.json"),
+		Colors:   filepath.Join(home, ".cache", "quickshell", "dankshell", "dms-colors.json"),
+		CacheDir: cacheDir,
+		Config:   filepath.Join(configHome, "dms", "greeter.json"),
+	}
+}
+
+type themeSyncConfig struct {
+	ThemeSync bool `json:"themeSync"`
+}
+
+func (p ThemeSyncPaths) Enabled() bool {
+	data, err := os.ReadFile(p.Config)
+	if err != nil {
+		return false
+	}
+	var cfg themeSyncConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return false
+	}
+	return cfg.ThemeSync
+}
+
+func (p ThemeSyncPaths) setEnabled(enabled bool) error {
+	if err := os.MkdirAll(filepath.Dir(p.Config), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(themeSyncConfig{ThemeSync: enabled}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeGreeterFile(p.Config, data, 0644)
+}
+
+// EnableThemeSync lets the greeter group write the cache directory, with the
+// setgid bit so copies stay readable by the greeter, then syncs once. The
+// user has to be in the greeter group, which takes effect at the next login
+func EnableThemeSync(p ThemeSyncPaths, logFunc func(string)) error {
+	if err := p.setCacheShared(true); err != nil {
+		return fmt.Errorf("failed to make %s writable by the greeter group: %w", p.CacheDir, err)
+	}
+	logFunc(fmt.Sprintf("✓ Made %s writable by the greeter group", p.CacheDir))
+
+	if err := p.setEnabled(true); err != nil {
+		return fmt.Errorf("failed to save greeter config: %w", err)
+	}
+
+	if _, err := p.Sync(); err != nil {
+		logFunc(fmt.Sprintf("⚠ Initial sync failed (%v); it will run once you log in again with greeter group access", err))
+		return nil
+	}
+	logFunc("✓ Synced wallpaper and theme to the greeter")
+	return nil
+}
+
+// DisableThemeSync stops syncing and puts back the symlinks the greeter
+// installer creates
+func DisableThemeSync(p ThemeSyncPaths, logFunc func(string)) error {
+	if err := p.setEnabled(false); err != nil {
+		return fmt.Errorf("failed to save greeter config: %w", err)
+	}
+
+	entries, _ := filepath.Glob(filepath.Join(p.CacheDir, "wallpaper*"))
+	for _, path := range entries {
+		os.Remove(path)
+	}
+	if err := p.setCacheShared(false); err != nil {
+		logFunc(fmt.Sprintf("⚠ Warning: Failed to reset permissions of %s: %v", p.CacheDir, err))
+	}
+	return SyncDMSConfigs("", logFunc)
+}
+
+// setCacheShared switches the cache directory between greeter-group
+// writable and its install default. The default cache goes through the
+// polkit helper when its rules are installed
+func (p ThemeSyncPaths) setCacheShared(shared bool) error {
+	mode, state := "750", "off"
+	if shared {
+		mode, state = "2770", "on"
+	}
+	if p.CacheDir == cacheDir && privesc.HelperAvailable() {
+		return privesc.RunHelper("greeter-theme-sync", state)
+	}
+	return runSudoCmd("chmod", mode, p.CacheDir)
+}
+
+// SetCacheShared is the root side of the greeter-theme-sync helper action
+func SetCacheShared(shared bool) error {
+	mode := os.FileMode(0750)
+	if shared {
+		mode = os.ModeSetgid | 0770
+	}
+	return os.Chmod(cacheDir, mode)
+}
+
+// Sync copies settings, colors and wallpapers into the cache directory,
+// pointing the session's wallpaper paths at the copies. It reports whether
+// anything was written
+func (p ThemeSyncPaths) Sync() (bool, error) {
+	changed := false
+
+	for _, file := range []struct{ source, name string }{
+		{p.Settings, "settings.json"},
+		{p.Colors, "colors.json"},
+	} {
+		data, err := os.ReadFile(file.source)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return changed, err
+		}
+		wrote, err := p.writeIfChanged(file.name, data)
+		if err != nil {
+			return changed, err
+		}
+		changed = changed || wrote
+	}
+
+	data, err := os.ReadFile(p.Session)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return changed, nil
+		}
+		return changed, err
+	}
+
+	var session map[string]interface{}
+	if err := json.Unmarshal(data, &session); err != nil {
+		return changed, fmt.Errorf("failed to parse %s: %w", p.Session, err)
+	}
+
+	keep := map[string]bool{}
+	copyWallpaper := func(source, name string) (string, error) {
+		// Solid colors and empty values aren't files
+		if !filepath.IsAbs(source) {
+			return source, nil
+		}
+		name += strings.ToLower(filepath.Ext(source))
+		keep[name] = true
+		wrote, err := p.copyIfChanged(source, name)
+		if err != nil {
+			return "", err
+		}
+		changed = changed || wrote
+		return filepath.Join(p.CacheDir, name), nil
+	}
+
+	if path, ok := session["wallpaperPath"].(string); ok {
+		if session["wallpaperPath"], err = copyWallpaper(path, "wallpaper"); err != nil {
+			return changed, err
+		}
+	}
+	if monitors, ok := session["monitorWallpapers"].(map[string]interface{}); ok {
+		for monitor, value := range monitors {
+			path, ok := value.(string)
+			if !ok {
+				continue
+			}
+			if monitors[monitor], err = copyWallpaper(path, "wallpaper-"+sanitizeName(monitor)); err != nil {
+				return changed, err
+			}
+		}
+	}
+
+	stale, _ := filepath.Glob(filepath.Join(p.CacheDir, "wallpaper*"))
+	for _, path := range stale {
+		if !keep[filepath.Base(path)] {
+			os.Remove(path)
+			changed = true
+		}
+	}
+
+	out, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return changed, err
+	}
+	wrote, err := p.writeIfChanged("session.json", out)
+	return changed || wrote, err
+}
+
+func sanitizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == os.PathSeparator || r == ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+func (p ThemeSyncPaths) writeIfChanged(name string, data []byte) (bool, error) {
+	target := filepath.Join(p.CacheDir, name)
+	// A symlink left by the installer is replaced by the copy
+	if info, err := os.Lstat(target); err == nil && info.Mode().IsRegular() {
+		if existing, err := os.ReadFile(target); err == nil && string(existing) == string(data) {
+			return false, nil
+		}
+	}
+	if err := writeGreeterFile(target, data, 0640); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (p ThemeSyncPaths) copyIfChanged(source, name string) (bool, error) {
+	srcInfo, err := os.Stat(source)
+	if err != nil {
+		return false, err
+	}
+	target := filepath.Join(p.CacheDir, name)
+	if info, err := os.Lstat(target); err == nil && info.Mode().IsRegular() &&
+		info.Size() == srcInfo.Size() && !info.ModTime().Before(srcInfo.ModTime()) {
+		return false, nil
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return false, err
+	}
+	defer in.Close()
+
+	tmp := target + ".dms-tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
+	if err != nil {
+		return false, err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return false, err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return false, err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return false, err
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		os.Remove(tmp)
+		return false, err
+	}
+	return true, nil
+}
+
+func writeGreeterFile(path string, data []byte, perm os.FileMode) error {
+	// WriteFileAtomic would write through a symlink left by the installer
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	if err := utils.WriteFileAtomic(path, data, perm); err != nil {
+		return err
+	}
+	// An existing file keeps its mode otherwise
+	return os.Chmod(path, perm)
+}
+
+// ThemeSyncer polls the theme files while sync is enabled and copies them to
+// the greeter when they change
+type ThemeSyncer struct {
+	paths    ThemeSyncPaths
+	interval time.Duration
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+	lastErr  string
+}
+
+func NewThemeSyncer(paths ThemeSyncPaths) *ThemeSyncer {
+	return &ThemeSyncer{
+		paths:    paths,
+		interval: themeSyncInterval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+func (s *ThemeSyncer) Start() {
+	s.wg.Add(1)
+	go s.loop()
+}
+
+func (s *ThemeSyncer) loop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			if !s.paths.Enabled() {
+				continue
+			}
+			s.syncOnce()
+		}
+	}
+}
+
+func (s *ThemeSyncer) syncOnce() {
+	changed, err := s.paths.Sync()
+	if err != nil {
+		// Only log each distinct failure, e.g. missing group access
+		if msg := err.Error(); msg != s.lastErr {
+			s.lastErr = msg
+			log.Warnf("Greeter theme sync failed: %v", err)
+		}
+		return
+	}
+	s.lastErr = ""
+	if changed {
+		log.Info("Synced theme to the greeter")
+	}
+}
+
+func (s *ThemeSyncer) Close() {
+	close(s.stopChan)
+	s.wg.Wait()
+}
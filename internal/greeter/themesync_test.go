@@ -0,0 +1,96 @@
+package greeter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testThemeSyncPaths(t *testing.T) ThemeSyncPaths {
+	t.Helper()
+	home := t.TempDir()
+	cache := filepath.Join(home, "cache")
+	require.NoError(t, os.MkdirAll(cache, 0755))
+	return ThemeSyncPaths{
+		Settings: filepath.Join(home, "settings.json"),
+		Session:  filepath.Join(home, "session.json"),
+		Colors:   filepath.Join(home, "dms-colors.json"),
+		CacheDir: cache,
+		Config:   filepath.Join(home, "dms", "greeter.json"),
+	}
+}
+
+func TestThemeSync_CopiesThemeAndWallpapers(t *testing.T) {
+	p := testThemeSyncPaths(t)
+	home := filepath.Dir(p.Settings)
+
+	wallpaper := filepath.Join(home, "Pictures", "beach.JPG")
+	side := filepath.Join(home, "Pictures", "side.png")
+	require.NoError(t, os.MkdirAll(filepath.Dir(wallpaper), 0755))
+	require.NoError(t, os.WriteFile(wallpaper, []byte("jpeg"), 0644))
+	require.NoError(t, os.WriteFile(side, []byte("png"), 0644))
+	require.NoError(t, os.WriteFile(p.Settings, []byte(`{"currentThemeName":"dynamic"}`), 0644))
+	require.NoError(t, os.WriteFile(p.Colors, []byte(`{"primary":"#ff0000"}`), 0644))
+
+	session := map[string]interface{}{
+		"wallpaperPath":     wallpaper,
+		"monitorWallpapers": map[string]interface{}{"DP-1": side, "HDMI-A-1": "#1e1e2e"},
+	}
+	data, _ := json.Marshal(session)
+	require.NoError(t, os.WriteFile(p.Session, data, 0644))
+
+	// The installer's symlink is replaced by a copy
+	require.NoError(t, os.Symlink(p.Colors, filepath.Join(p.CacheDir, "colors.json")))
+
+	changed, err := p.Sync()
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	colors, err := os.Lstat(filepath.Join(p.CacheDir, "colors.json"))
+	require.NoError(t, err)
+	assert.True(t, colors.Mode().IsRegular())
+	assert.Equal(t, os.FileMode(0640), colors.Mode().Perm())
+
+	copied, err := os.ReadFile(filepath.Join(p.CacheDir, "wallpaper.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", string(copied))
+
+	var synced map[string]interface{}
+	data, err = os.ReadFile(filepath.Join(p.CacheDir, "session.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &synced))
+	assert.Equal(t, filepath.Join(p.CacheDir, "wallpaper.jpg"), synced["wallpaperPath"])
+	monitors := synced["monitorWallpapers"].(map[string]interface{})
+	assert.Equal(t, filepath.Join(p.CacheDir, "wallpaper-DP-1.png"), monitors["DP-1"])
+	assert.Equal(t, "#1e1e2e", monitors["HDMI-A-1"])
+
+	changed, err = p.Sync()
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestThemeSync_RemovesStaleWallpapers(t *testing.T) {
+	p := testThemeSyncPaths(t)
+	require.NoError(t, os.WriteFile(filepath.Join(p.CacheDir, "wallpaper.png"), []byte("old"), 0640))
+	require.NoError(t, os.WriteFile(p.Session, []byte(`{"wallpaperPath":"#000000"}`), 0644))
+
+	changed, err := p.Sync()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.NoFileExists(t, filepath.Join(p.CacheDir, "wallpaper.png"))
+}
+
+func TestThemeSync_Enabled(t *testing.T) {
+	p := testThemeSyncPaths(t)
+	assert.False(t, p.Enabled())
+
+	require.NoError(t, p.setEnabled(true))
+	assert.True(t, p.Enabled())
+
+	require.NoError(t, p.setEnabled(false))
+	assert.False(t, p.Enabled())
+}
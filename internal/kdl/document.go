@@ -0,0 +1,235 @@
+// Package kdl reads and writes the subset of KDL used by niri's config while
+// keeping comments and layout, so programs can edit a config a person also edits
+package kdl
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Value keeps a literal as written so numbers like 0.80 and raw strings
+// survive a round trip
+type Value struct {
+	Raw string
+}
+
+// Entry is a positional argument, or a property when Key is set
+type Entry struct {
+	Key      string
+	Value    Value
+	Disabled bool
+}
+
+type Node struct {
+	Name     string
+	Entries  []Entry
+	Children []*Node
+	Block    bool
+	Disabled bool
+	// Inline blocks are written on one line: Mod+T { spawn "foot"; }
+	Inline bool
+	// Semicolon terminates the node with ; as niri's bind actions are
+	Semicolon bool
+	// Comments are the comment lines above the node; "" is a blank line
+	Comments []string
+	Trailing string
+	// Footer holds comments between the last child and the closing brace
+	Footer []string
+}
+
+type Document struct {
+	Root *Node
+}
+
+func String(s string) Value {
+	return Value{Raw: quote(s)}
+}
+
+func Int(n int) Value {
+	return Value{Raw: strconv.Itoa(n)}
+}
+
+func Float(f float64) Value {
+	raw := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.ContainsAny(raw, ".e") {
+		raw += ".0"
+	}
+	return Value{Raw: raw}
+}
+
+func Bool(b bool) Value {
+	return Value{Raw: strconv.FormatBool(b)}
+}
+
+// ParseValue turns user input into a value: numbers and booleans stay bare,
+// already quoted strings are kept and anything else becomes a string
+func ParseValue(text string) Value {
+	switch {
+	case text == "true" || text == "false" || text == "null":
+		return Value{Raw: text}
+	case isNumber(text):
+		return Value{Raw: text}
+	case len(text) >= 2 && text[0] == '"' && text[len(text)-1] == '"':
+		if _, err := unquote(text); err == nil {
+			return Value{Raw: text}
+		}
+	}
+	return String(text)
+}
+
+func isNumber(text string) bool {
+	if _, err := strconv.ParseInt(strings.ReplaceAll(text, "_", ""), 0, 64); err == nil {
+		return true
+	}
+	_, err := strconv.ParseFloat(strings.ReplaceAll(text, "_", ""), 64)
+	return err == nil && !strings.ContainsAny(strings.ToLower(text), "inx")
+}
+
+// String returns the decoded text of a string value, or the literal otherwise
+func (v Value) String() string {
+	if s, err := unquote(v.Raw); err == nil {
+		return s
+	}
+	return v.Raw
+}
+
+func (v Value) IsString() bool {
+	_, err := unquote(v.Raw)
+	return err == nil
+}
+
+func NewNode(name string, args ...Value) *Node {
+	n := &Node{Name: name}
+	for _, arg := range args {
+		n.Entries = append(n.Entries, Entry{Value: arg})
+	}
+	return n
+}
+
+// Args returns the enabled positional arguments
+func (n *Node) Args() []Value {
+	var args []Value
+	for _, entry := range n.Entries {
+		if entry.Key == "" && !entry.Disabled {
+			args = append(args, entry.Value)
+		}
+	}
+	return args
+}
+
+// SetArgs replaces the positional arguments, keeping properties
+func (n *Node) SetArgs(args ...Value) {
+	entries := make([]Entry, 0, len(n.Entries)+len(args))
+	for _, arg := range args {
+		entries = append(entries, Entry{Value: arg})
+	}
+	for _, entry := range n.Entries {
+		if entry.Key != "" {
+			entries = append(entries, entry)
+		}
+	}
+	n.Entries = entries
+}
+
+func (n *Node) Prop(key string) (Value, bool) {
+	for i := len(n.Entries) - 1; i >= 0; i-- {
+		if n.Entries[i].Key == key && !n.Entries[i].Disabled {
+			return n.Entries[i].Value, true
+		}
+	}
+	return Value{}, false
+}
+
+func (n *Node) SetProp(key string, value Value) {
+	for i := range n.Entries {
+		if n.Entries[i].Key == key {
+			n.Entries[i].Value = value
+			n.Entries[i].Disabled = false
+			return
+		}
+	}
+	n.Entries = append(n.Entries, Entry{Key: key, Value: value})
+}
+
+// Child returns the last enabled child called name; like niri, later
+// duplicates win
+func (n *Node) Child(name string) *Node {
+	for i := len(n.Children) - 1; i >= 0; i-- {
+		if n.Children[i].Name == name && !n.Children[i].Disabled {
+			return n.Children[i]
+		}
+	}
+	return nil
+}
+
+// ChildrenNamed returns every child called name, disabled ones included
+func (n *Node) ChildrenNamed(name string) []*Node {
+	var nodes []*Node
+	for _, child := range n.Children {
+		if child.Name == name {
+			nodes = append(nodes, child)
+		}
+	}
+	return nodes
+}
+
+func (n *Node) Append(children ...*Node) {
+	n.Block = true
+	n.Children = append(n.Children, children...)
+}
+
+// Insert puts child at index, clamped to the children list
+func (n *Node) Insert(index int, child *Node) {
+	n.Block = true
+	if index < 0 {
+		index = 0
+	}
+	if index > len(n.Children) {
+		index = len(n.Children)
+	}
+	n.Children = append(n.Children[:index], append([]*Node{child}, n.Children[index:]...)...)
+}
+
+func (n *Node) Remove(child *Node) bool {
+	for i, c := range n.Children {
+		if c == child {
+			n.Children = append(n.Children[:i], n.Children[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (n *Node) Index(child *Node) int {
+	for i, c := range n.Children {
+		if c == child {
+			return i
+		}
+	}
+	return -1
+}
+
+// Find walks enabled children by name
+func (d *Document) Find(path ...string) *Node {
+	node := d.Root
+	for _, name := range path {
+		if node = node.Child(name); node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// Ensure walks path, appending any missing nodes as blocks
+func (d *Document) Ensure(path ...string) *Node {
+	node := d.Root
+	for _, name := range path {
+		child := node.Child(name)
+		if child == nil {
+			child = &Node{Name: name}
+			node.Append(child)
+		}
+		node = child
+	}
+	return node
+}
@@ -0,0 +1,114 @@
+package kdl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sample = `// Top comment
+input {
+    keyboard {
+        xkb {
+            layout "us,de"
+        }
+        numlock
+    }
+}
+
+/-output "eDP-2" {
+    mode "2560x1600@239.998993"
+    position x=2560 y=0
+}
+layout {
+    gaps 5 // Inline comment
+    struts {
+    }
+}
+window-rule {
+    match app-id=r#"^org\.gnome\."# title="a \"quoted\" title"
+    geometry-corner-radius 12
+}
+binds {
+    Mod+T hotkey-overlay-title="Open Terminal" { spawn "foot"; }
+    Mod+Space {
+        spawn "dms" "ipc" "call" "spotlight" "toggle";
+    }
+    // trailing comment in block
+}
+`
+
+func TestRoundTrip(t *testing.T) {
+	doc, err := Parse(sample)
+	require.NoError(t, err)
+	assert.Equal(t, sample, doc.String())
+}
+
+func TestParseStructure(t *testing.T) {
+	doc, err := Parse(sample)
+	require.NoError(t, err)
+
+	layout := doc.Find("input", "keyboard", "xkb", "layout")
+	require.NotNil(t, layout)
+	assert.Equal(t, "us,de", layout.Args()[0].String())
+
+	assert.Nil(t, doc.Find("output"), "slashdashed nodes are skipped")
+	outputs := doc.Root.ChildrenNamed("output")
+	require.Len(t, outputs, 1)
+	assert.True(t, outputs[0].Disabled)
+
+	match := doc.Find("window-rule", "match")
+	appID, ok := match.Prop("app-id")
+	require.True(t, ok)
+	assert.Equal(t, `^org\.gnome\.`, appID.String())
+	title, _ := match.Prop("title")
+	assert.Equal(t, `a "quoted" title`, title.String())
+
+	bind := doc.Find("binds", "Mod+T")
+	require.NotNil(t, bind)
+	assert.True(t, bind.Inline)
+	assert.Equal(t, "spawn", bind.Children[0].Name)
+	assert.Equal(t, "// Inline comment", doc.Find("layout", "gaps").Trailing)
+}
+
+func TestEdit(t *testing.T) {
+	doc, err := Parse(sample)
+	require.NoError(t, err)
+
+	doc.Find("layout", "gaps").SetArgs(Int(8))
+	doc.Ensure("layout", "focus-ring", "width").SetArgs(Int(2))
+	doc.Find("window-rule", "match").SetProp("app-id", String("kitty"))
+
+	out := doc.String()
+	assert.Contains(t, out, "    gaps 8 // Inline comment\n")
+	assert.Contains(t, out, "    focus-ring {\n        width 2\n    }\n")
+	assert.Contains(t, out, `match app-id="kitty" title=`)
+
+	reparsed, err := Parse(out)
+	require.NoError(t, err)
+	assert.Equal(t, out, reparsed.String())
+}
+
+func TestParseValue(t *testing.T) {
+	assert.Equal(t, "8", ParseValue("8").Raw)
+	assert.Equal(t, "0.5", ParseValue("0.5").Raw)
+	assert.Equal(t, "true", ParseValue("true").Raw)
+	assert.Equal(t, `"never"`, ParseValue("never").Raw)
+	assert.Equal(t, `"inf"`, ParseValue("inf").Raw)
+	assert.Equal(t, `"quoted"`, ParseValue(`"quoted"`).Raw)
+	assert.Equal(t, "1.0", Float(1).Raw)
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{
+		"layout {\n    gaps 5\n",
+		"}",
+		`name "unterminated`,
+		"/* open",
+		"(type)node",
+	} {
+		_, err := Parse(src)
+		assert.Error(t, err, src)
+	}
+}
@@ -0,0 +1,490 @@
+package kdl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type parser struct {
+	src  []rune
+	pos  int
+	line int
+}
+
+func Parse(src string) (*Document, error) {
+	p := &parser{src: []rune(strings.ReplaceAll(src, "\r\n", "\n")), line: 1}
+	root := &Node{Block: true}
+	children, footer, err := p.parseNodes(false)
+	if err != nil {
+		return nil, err
+	}
+	root.Children = children
+	root.Footer = trimBlankEdges(footer)
+	return &Document{Root: root}, nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("kdl: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) peek() rune {
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) peekAt(offset int) rune {
+	if p.pos+offset >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos+offset]
+}
+
+func (p *parser) next() rune {
+	r := p.peek()
+	p.pos++
+	if r == '\n' {
+		p.line++
+	}
+	return r
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+// skipSpace skips blanks, line continuations and block comments on the
+// current line, returning any block comment it passed
+func (p *parser) skipSpace() (string, error) {
+	var comment string
+	for !p.eof() {
+		r := p.peek()
+		switch {
+		case r == ' ' || r == '\t' || r == '\uFEFF':
+			p.next()
+		case r == '\\':
+			p.next()
+			for p.peek() == ' ' || p.peek() == '\t' {
+				p.next()
+			}
+			if p.peek() == '/' && p.peekAt(1) == '/' {
+				p.skipLine()
+			}
+			if p.peek() != '\n' {
+				return "", p.errorf("expected newline after line continuation")
+			}
+			p.next()
+		case r == '/' && p.peekAt(1) == '*':
+			block, err := p.blockComment()
+			if err != nil {
+				return "", err
+			}
+			comment = block
+		default:
+			return comment, nil
+		}
+	}
+	return comment, nil
+}
+
+func (p *parser) skipLine() string {
+	start := p.pos
+	for !p.eof() && p.peek() != '\n' {
+		p.next()
+	}
+	return string(p.src[start:p.pos])
+}
+
+func (p *parser) blockComment() (string, error) {
+	start := p.pos
+	depth := 0
+	for !p.eof() {
+		switch {
+		case p.peek() == '/' && p.peekAt(1) == '*':
+			depth++
+			p.next()
+			p.next()
+		case p.peek() == '*' && p.peekAt(1) == '/':
+			depth--
+			p.next()
+			p.next()
+			if depth == 0 {
+				return string(p.src[start:p.pos]), nil
+			}
+		default:
+			p.next()
+		}
+	}
+	return "", p.errorf("unterminated block comment")
+}
+
+// parseNodes reads nodes until EOF, or the closing brace when inBlock; it
+// returns the comments left over at the end
+func (p *parser) parseNodes(inBlock bool) ([]*Node, []string, error) {
+	var nodes []*Node
+	var pending []string
+	lineStart := true
+	nodeOnLine := false
+
+	for {
+		comment, err := p.skipSpace()
+		if err != nil {
+			return nil, nil, err
+		}
+		if comment != "" {
+			pending = append(pending, comment)
+			lineStart = false
+		}
+
+		switch r := p.peek(); {
+		case p.eof():
+			if inBlock {
+				return nil, nil, p.errorf("unexpected end of input, missing }")
+			}
+			return nodes, pending, nil
+		case r == '\n':
+			p.next()
+			if lineStart {
+				pending = append(pending, "")
+			}
+			lineStart = true
+			nodeOnLine = false
+		case r == ';':
+			p.next()
+			if nodeOnLine {
+				nodes[len(nodes)-1].Semicolon = true
+			}
+		case r == '/' && p.peekAt(1) == '/':
+			text := p.skipLine()
+			if nodeOnLine && comment == "" && nodes[len(nodes)-1].Trailing == "" {
+				nodes[len(nodes)-1].Trailing = text
+			} else {
+				pending = append(pending, text)
+			}
+			lineStart = false
+		case r == '}':
+			if !inBlock {
+				return nil, nil, p.errorf("unexpected }")
+			}
+			return nodes, pending, nil
+		default:
+			disabled := false
+			if r == '/' && p.peekAt(1) == '-' {
+				p.next()
+				p.next()
+				disabled = true
+				if _, err := p.skipSpace(); err != nil {
+					return nil, nil, err
+				}
+			}
+
+			node, err := p.parseNode()
+			if err != nil {
+				return nil, nil, err
+			}
+			node.Disabled = disabled
+			node.Comments = collapseBlanks(pending)
+			pending = nil
+			nodes = append(nodes, node)
+			lineStart = false
+			nodeOnLine = true
+		}
+	}
+}
+
+func (p *parser) parseNode() (*Node, error) {
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	node := &Node{Name: name}
+
+	for {
+		if _, err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+
+		r := p.peek()
+		switch {
+		case p.eof() || r == '\n' || r == ';' || r == '}':
+			return node, nil
+		case r == '/' && p.peekAt(1) == '/':
+			return node, nil
+		case r == '{':
+			if err := p.parseChildren(node); err != nil {
+				return nil, err
+			}
+			return node, nil
+		}
+
+		disabled := false
+		if r == '/' && p.peekAt(1) == '-' {
+			p.next()
+			p.next()
+			disabled = true
+			if _, err := p.skipSpace(); err != nil {
+				return nil, err
+			}
+			if p.peek() == '{' {
+				return nil, p.errorf("disabled children blocks are not supported")
+			}
+		}
+
+		entry, err := p.parseEntry()
+		if err != nil {
+			return nil, err
+		}
+		entry.Disabled = disabled
+		node.Entries = append(node.Entries, entry)
+	}
+}
+
+func (p *parser) parseChildren(node *Node) error {
+	p.next() // {
+	startLine := p.line
+	children, footer, err := p.parseNodes(true)
+	if err != nil {
+		return err
+	}
+	p.next() // }
+
+	node.Block = true
+	node.Children = children
+	node.Footer = trimBlankEdges(footer)
+	node.Inline = p.line == startLine
+
+	// A comment right after the closing brace belongs to this node
+	if _, err := p.skipSpace(); err != nil {
+		return err
+	}
+	if p.peek() == '/' && p.peekAt(1) == '/' {
+		node.Trailing = p.skipLine()
+	}
+	return nil
+}
+
+func (p *parser) parseName() (string, error) {
+	switch p.peek() {
+	case '"':
+		raw, err := p.parseString()
+		if err != nil {
+			return "", err
+		}
+		return unquote(raw)
+	case 'r':
+		if p.peekAt(1) == '"' || p.peekAt(1) == '#' {
+			raw, err := p.parseRawString()
+			if err != nil {
+				return "", err
+			}
+			return unquote(raw)
+		}
+	case '(':
+		return "", p.errorf("type annotations are not supported")
+	}
+
+	ident := p.parseIdent()
+	if ident == "" {
+		return "", p.errorf("expected node name, found %q", p.peek())
+	}
+	return ident, nil
+}
+
+func (p *parser) parseEntry() (Entry, error) {
+	raw, err := p.parseLiteral()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	if p.peek() != '=' {
+		return Entry{Value: Value{Raw: raw}}, nil
+	}
+	p.next()
+
+	key := raw
+	if s, err := unquote(raw); err == nil {
+		key = s
+	}
+	value, err := p.parseLiteral()
+	if err != nil {
+		return Entry{}, err
+	}
+	return Entry{Key: key, Value: Value{Raw: value}}, nil
+}
+
+func (p *parser) parseLiteral() (string, error) {
+	switch r := p.peek(); {
+	case r == '"':
+		return p.parseString()
+	case r == 'r' && (p.peekAt(1) == '"' || p.peekAt(1) == '#'):
+		return p.parseRawString()
+	case r == '(':
+		return "", p.errorf("type annotations are not supported")
+	}
+
+	literal := p.parseIdent()
+	if literal == "" {
+		return "", p.errorf("unexpected %q", p.peek())
+	}
+	return literal, nil
+}
+
+func isIdentRune(r rune) bool {
+	if unicode.IsSpace(r) || r == 0 {
+		return false
+	}
+	return !strings.ContainsRune(`\/(){}<>;[]=,"`, r)
+}
+
+func (p *parser) parseIdent() string {
+	start := p.pos
+	for !p.eof() && isIdentRune(p.peek()) {
+		p.next()
+	}
+	return string(p.src[start:p.pos])
+}
+
+func (p *parser) parseString() (string, error) {
+	start := p.pos
+	p.next() // "
+	for !p.eof() {
+		switch p.next() {
+		case '\\':
+			p.next()
+		case '"':
+			return string(p.src[start:p.pos]), nil
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *parser) parseRawString() (string, error) {
+	start := p.pos
+	p.next() // r
+	hashes := 0
+	for p.peek() == '#' {
+		p.next()
+		hashes++
+	}
+	if p.next() != '"' {
+		return "", p.errorf("invalid raw string")
+	}
+
+	closing := "\"" + strings.Repeat("#", hashes)
+	for !p.eof() {
+		if p.next() == '"' && strings.HasPrefix(string(p.src[p.pos-1:min(p.pos-1+len(closing), len(p.src))]), closing) {
+			for i := 0; i < hashes; i++ {
+				p.next()
+			}
+			return string(p.src[start:p.pos]), nil
+		}
+	}
+	return "", p.errorf("unterminated raw string")
+}
+
+func unquote(raw string) (string, error) {
+	if strings.HasPrefix(raw, "r") {
+		trimmed := strings.TrimLeft(raw[1:], "#")
+		hashes := len(raw) - 1 - len(trimmed)
+		if len(trimmed) < 2+hashes || trimmed[0] != '"' || !strings.HasSuffix(trimmed, "\""+strings.Repeat("#", hashes)) {
+			return "", fmt.Errorf("not a raw string")
+		}
+		return trimmed[1 : len(trimmed)-1-hashes], nil
+	}
+
+	if len(raw) < 2 || raw[0] != '"' || raw[len(raw)-1] != '"' {
+		return "", fmt.Errorf("not a string")
+	}
+
+	var b strings.Builder
+	body := []rune(raw[1 : len(raw)-1])
+	for i := 0; i < len(body); i++ {
+		if body[i] != '\\' {
+			b.WriteRune(body[i])
+			continue
+		}
+		i++
+		if i >= len(body) {
+			return "", fmt.Errorf("dangling escape")
+		}
+		switch body[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case '\\', '"', '/':
+			b.WriteRune(body[i])
+		case 'u':
+			end := strings.IndexRune(string(body[i:]), '}')
+			if i+1 >= len(body) || body[i+1] != '{' || end < 0 {
+				return "", fmt.Errorf("invalid unicode escape")
+			}
+			code, err := strconv.ParseUint(string(body[i+2:i+end]), 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape")
+			}
+			b.WriteRune(rune(code))
+			i += end
+		default:
+			return "", fmt.Errorf("invalid escape \\%c", body[i])
+		}
+	}
+	return b.String(), nil
+}
+
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func collapseBlanks(lines []string) []string {
+	var out []string
+	for _, line := range lines {
+		if line == "" && (len(out) == 0 || out[len(out)-1] == "") {
+			if len(out) == 0 {
+				out = append(out, line)
+			}
+			continue
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+func trimBlankEdges(lines []string) []string {
+	lines = collapseBlanks(lines)
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
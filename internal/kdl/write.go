@@ -0,0 +1,121 @@
+package kdl
+
+import (
+	"strings"
+)
+
+const indent = "    "
+
+func (d *Document) String() string {
+	var b strings.Builder
+	for i, node := range d.Root.Children {
+		comments := node.Comments
+		if i == 0 {
+			comments = trimBlankEdges(comments)
+		}
+		writeNode(&b, node, comments, 0)
+	}
+	for _, line := range d.Root.Footer {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func writeComments(b *strings.Builder, comments []string, depth int) {
+	prefix := strings.Repeat(indent, depth)
+	for _, comment := range comments {
+		if comment == "" {
+			b.WriteByte('\n')
+			continue
+		}
+		b.WriteString(prefix)
+		b.WriteString(strings.TrimSpace(comment))
+		b.WriteByte('\n')
+	}
+}
+
+func writeNode(b *strings.Builder, n *Node, comments []string, depth int) {
+	writeComments(b, comments, depth)
+
+	b.WriteString(strings.Repeat(indent, depth))
+	writeHead(b, n)
+
+	if n.Block {
+		switch {
+		case len(n.Children) == 0 && len(n.Footer) == 0 && n.Inline:
+			b.WriteString(" {}")
+		case n.inlineable():
+			b.WriteString(" {")
+			for _, child := range n.Children {
+				b.WriteByte(' ')
+				writeHead(b, child)
+				b.WriteByte(';')
+			}
+			b.WriteString(" }")
+		default:
+			b.WriteString(" {\n")
+			for i, child := range n.Children {
+				childComments := child.Comments
+				if i == 0 {
+					childComments = trimBlankEdges(childComments)
+				}
+				writeNode(b, child, childComments, depth+1)
+			}
+			writeComments(b, n.Footer, depth+1)
+			b.WriteString(strings.Repeat(indent, depth))
+			b.WriteByte('}')
+		}
+	}
+
+	if !n.Block && n.Semicolon {
+		b.WriteByte(';')
+	}
+	if n.Trailing != "" {
+		b.WriteByte(' ')
+		b.WriteString(n.Trailing)
+	}
+	b.WriteByte('\n')
+}
+
+func (n *Node) inlineable() bool {
+	if !n.Inline || len(n.Footer) > 0 {
+		return false
+	}
+	for _, child := range n.Children {
+		if child.Block || child.Trailing != "" || len(child.Comments) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func writeHead(b *strings.Builder, n *Node) {
+	if n.Disabled {
+		b.WriteString("/-")
+	}
+	b.WriteString(formatIdent(n.Name))
+	for _, entry := range n.Entries {
+		b.WriteByte(' ')
+		if entry.Disabled {
+			b.WriteString("/-")
+		}
+		if entry.Key != "" {
+			b.WriteString(formatIdent(entry.Key))
+			b.WriteByte('=')
+		}
+		b.WriteString(entry.Value.Raw)
+	}
+}
+
+func formatIdent(name string) string {
+	if name == "" || isNumber(name) || (name[0] >= '0' && name[0] <= '9') {
+		return quote(name)
+	}
+	for _, r := range name {
+		if !isIdentRune(r) {
+			return quote(name)
+		}
+	}
+	return name
+}
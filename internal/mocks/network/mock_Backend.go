@@ -20,17 +20,17 @@ func (_m *MockBackend) EXPECT() *MockBackend_Expecter {
 	return &MockBackend_Expecter{mock: &_m.Mock}
 }
 
-// ActivateWiredConnection provides a mock function with given fields: uuid
-func (_m *MockBackend) ActivateWiredConnection(uuid string) error {
-	ret := _m.Called(uuid)
+// ActivateWiredConnection provides a mock function with given fields: uuid, device
+func (_m *MockBackend) ActivateWiredConnection(uuid string, device string) error {
+	ret := _m.Called(uuid, device)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ActivateWiredConnection")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(uuid)
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(uuid, device)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -45,13 +45,14 @@ type MockBackend_ActivateWiredConnection_Call struct {
 
 // ActivateWiredConnection is a helper method to define mock.On call
 //   - uuid string
-func (_e *MockBackend_Expecter) ActivateWiredConnection(uuid interface{}) *MockBackend_ActivateWiredConnection_Call {
-	return &MockBackend_ActivateWiredConnection_Call{Call: _e.mock.On("ActivateWiredConnection", uuid)}
+//   - device string
+func (_e *MockBackend_Expecter) ActivateWiredConnection(uuid interface{}, device interface{}) *MockBackend_ActivateWiredConnection_Call {
+	return &MockBackend_ActivateWiredConnection_Call{Call: _e.mock.On("ActivateWiredConnection", uuid, device)}
 }
 
-func (_c *MockBackend_ActivateWiredConnection_Call) Run(run func(uuid string)) *MockBackend_ActivateWiredConnection_Call {
+func (_c *MockBackend_ActivateWiredConnection_Call) Run(run func(uuid string, device string)) *MockBackend_ActivateWiredConnection_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(string), args[1].(string))
 	})
 	return _c
 }
@@ -61,7 +62,53 @@ func (_c *MockBackend_ActivateWiredConnection_Call) Return(_a0 error) *MockBacke
 	return _c
 }
 
-func (_c *MockBackend_ActivateWiredConnection_Call) RunAndReturn(run func(string) error) *MockBackend_ActivateWiredConnection_Call {
+func (_c *MockBackend_ActivateWiredConnection_Call) RunAndReturn(run func(string, string) error) *MockBackend_ActivateWiredConnection_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CancelConnect provides a mock function with given fields: ssid
+func (_m *MockBackend) CancelConnect(ssid string) error {
+	ret := _m.Called(ssid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelConnect")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(ssid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBackend_CancelConnect_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CancelConnect'
+type MockBackend_CancelConnect_Call struct {
+	*mock.Call
+}
+
+// CancelConnect is a helper method to define mock.On call
+//   - ssid string
+func (_e *MockBackend_Expecter) CancelConnect(ssid interface{}) *MockBackend_CancelConnect_Call {
+	return &MockBackend_CancelConnect_Call{Call: _e.mock.On("CancelConnect", ssid)}
+}
+
+func (_c *MockBackend_CancelConnect_Call) Run(run func(ssid string)) *MockBackend_CancelConnect_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockBackend_CancelConnect_Call) Return(_a0 error) *MockBackend_CancelConnect_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBackend_CancelConnect_Call) RunAndReturn(run func(string) error) *MockBackend_CancelConnect_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -190,17 +237,17 @@ func (_c *MockBackend_Close_Call) RunAndReturn(run func()) *MockBackend_Close_Ca
 	return _c
 }
 
-// ConnectEthernet provides a mock function with no fields
-func (_m *MockBackend) ConnectEthernet() error {
-	ret := _m.Called()
+// ConnectEthernet provides a mock function with given fields: auth
+func (_m *MockBackend) ConnectEthernet(auth *network.ConnectionRequest) error {
+	ret := _m.Called(auth)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ConnectEthernet")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func() error); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(*network.ConnectionRequest) error); ok {
+		r0 = rf(auth)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -214,13 +261,14 @@ type MockBackend_ConnectEthernet_Call struct {
 }
 
 // ConnectEthernet is a helper method to define mock.On call
-func (_e *MockBackend_Expecter) ConnectEthernet() *MockBackend_ConnectEthernet_Call {
-	return &MockBackend_ConnectEthernet_Call{Call: _e.mock.On("ConnectEthernet")}
+//   - auth *network.ConnectionRequest
+func (_e *MockBackend_Expecter) ConnectEthernet(auth interface{}) *MockBackend_ConnectEthernet_Call {
+	return &MockBackend_ConnectEthernet_Call{Call: _e.mock.On("ConnectEthernet", auth)}
 }
 
-func (_c *MockBackend_ConnectEthernet_Call) Run(run func()) *MockBackend_ConnectEthernet_Call {
+func (_c *MockBackend_ConnectEthernet_Call) Run(run func(auth *network.ConnectionRequest)) *MockBackend_ConnectEthernet_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(*network.ConnectionRequest))
 	})
 	return _c
 }
@@ -230,7 +278,7 @@ func (_c *MockBackend_ConnectEthernet_Call) Return(_a0 error) *MockBackend_Conne
 	return _c
 }
 
-func (_c *MockBackend_ConnectEthernet_Call) RunAndReturn(run func() error) *MockBackend_ConnectEthernet_Call {
+func (_c *MockBackend_ConnectEthernet_Call) RunAndReturn(run func(*network.ConnectionRequest) error) *MockBackend_ConnectEthernet_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -612,6 +660,122 @@ func (_c *MockBackend_GetCurrentState_Call) RunAndReturn(run func() (*network.Ba
 	return _c
 }
 
+// GetIPv6Config provides a mock function with given fields: uuidOrSSID
+func (_m *MockBackend) GetIPv6Config(uuidOrSSID string) (*network.IPv6Config, error) {
+	ret := _m.Called(uuidOrSSID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetIPv6Config")
+	}
+
+	var r0 *network.IPv6Config
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*network.IPv6Config, error)); ok {
+		return rf(uuidOrSSID)
+	}
+	if rf, ok := ret.Get(0).(func(string) *network.IPv6Config); ok {
+		r0 = rf(uuidOrSSID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*network.IPv6Config)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(uuidOrSSID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBackend_GetIPv6Config_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetIPv6Config'
+type MockBackend_GetIPv6Config_Call struct {
+	*mock.Call
+}
+
+// GetIPv6Config is a helper method to define mock.On call
+//   - uuidOrSSID string
+func (_e *MockBackend_Expecter) GetIPv6Config(uuidOrSSID interface{}) *MockBackend_GetIPv6Config_Call {
+	return &MockBackend_GetIPv6Config_Call{Call: _e.mock.On("GetIPv6Config", uuidOrSSID)}
+}
+
+func (_c *MockBackend_GetIPv6Config_Call) Run(run func(uuidOrSSID string)) *MockBackend_GetIPv6Config_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockBackend_GetIPv6Config_Call) Return(_a0 *network.IPv6Config, _a1 error) *MockBackend_GetIPv6Config_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBackend_GetIPv6Config_Call) RunAndReturn(run func(string) (*network.IPv6Config, error)) *MockBackend_GetIPv6Config_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetMetered provides a mock function with given fields: uuidOrSSID
+func (_m *MockBackend) GetMetered(uuidOrSSID string) (*network.MeteredInfo, error) {
+	ret := _m.Called(uuidOrSSID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMetered")
+	}
+
+	var r0 *network.MeteredInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*network.MeteredInfo, error)); ok {
+		return rf(uuidOrSSID)
+	}
+	if rf, ok := ret.Get(0).(func(string) *network.MeteredInfo); ok {
+		r0 = rf(uuidOrSSID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*network.MeteredInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(uuidOrSSID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBackend_GetMetered_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMetered'
+type MockBackend_GetMetered_Call struct {
+	*mock.Call
+}
+
+// GetMetered is a helper method to define mock.On call
+//   - uuidOrSSID string
+func (_e *MockBackend_Expecter) GetMetered(uuidOrSSID interface{}) *MockBackend_GetMetered_Call {
+	return &MockBackend_GetMetered_Call{Call: _e.mock.On("GetMetered", uuidOrSSID)}
+}
+
+func (_c *MockBackend_GetMetered_Call) Run(run func(uuidOrSSID string)) *MockBackend_GetMetered_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockBackend_GetMetered_Call) Return(_a0 *network.MeteredInfo, _a1 error) *MockBackend_GetMetered_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBackend_GetMetered_Call) RunAndReturn(run func(string) (*network.MeteredInfo, error)) *MockBackend_GetMetered_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetPromptBroker provides a mock function with no fields
 func (_m *MockBackend) GetPromptBroker() network.PromptBroker {
 	ret := _m.Called()
@@ -659,6 +823,122 @@ func (_c *MockBackend_GetPromptBroker_Call) RunAndReturn(run func() network.Prom
 	return _c
 }
 
+// GetSearchDomains provides a mock function with given fields: uuidOrSSID
+func (_m *MockBackend) GetSearchDomains(uuidOrSSID string) ([]string, error) {
+	ret := _m.Called(uuidOrSSID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSearchDomains")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]string, error)); ok {
+		return rf(uuidOrSSID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []string); ok {
+		r0 = rf(uuidOrSSID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(uuidOrSSID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBackend_GetSearchDomains_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSearchDomains'
+type MockBackend_GetSearchDomains_Call struct {
+	*mock.Call
+}
+
+// GetSearchDomains is a helper method to define mock.On call
+//   - uuidOrSSID string
+func (_e *MockBackend_Expecter) GetSearchDomains(uuidOrSSID interface{}) *MockBackend_GetSearchDomains_Call {
+	return &MockBackend_GetSearchDomains_Call{Call: _e.mock.On("GetSearchDomains", uuidOrSSID)}
+}
+
+func (_c *MockBackend_GetSearchDomains_Call) Run(run func(uuidOrSSID string)) *MockBackend_GetSearchDomains_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockBackend_GetSearchDomains_Call) Return(_a0 []string, _a1 error) *MockBackend_GetSearchDomains_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBackend_GetSearchDomains_Call) RunAndReturn(run func(string) ([]string, error)) *MockBackend_GetSearchDomains_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetWakeOnLAN provides a mock function with given fields: uuid
+func (_m *MockBackend) GetWakeOnLAN(uuid string) (*network.WakeOnLANConfig, error) {
+	ret := _m.Called(uuid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWakeOnLAN")
+	}
+
+	var r0 *network.WakeOnLANConfig
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*network.WakeOnLANConfig, error)); ok {
+		return rf(uuid)
+	}
+	if rf, ok := ret.Get(0).(func(string) *network.WakeOnLANConfig); ok {
+		r0 = rf(uuid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*network.WakeOnLANConfig)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(uuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBackend_GetWakeOnLAN_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetWakeOnLAN'
+type MockBackend_GetWakeOnLAN_Call struct {
+	*mock.Call
+}
+
+// GetWakeOnLAN is a helper method to define mock.On call
+//   - uuid string
+func (_e *MockBackend_Expecter) GetWakeOnLAN(uuid interface{}) *MockBackend_GetWakeOnLAN_Call {
+	return &MockBackend_GetWakeOnLAN_Call{Call: _e.mock.On("GetWakeOnLAN", uuid)}
+}
+
+func (_c *MockBackend_GetWakeOnLAN_Call) Run(run func(uuid string)) *MockBackend_GetWakeOnLAN_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockBackend_GetWakeOnLAN_Call) Return(_a0 *network.WakeOnLANConfig, _a1 error) *MockBackend_GetWakeOnLAN_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBackend_GetWakeOnLAN_Call) RunAndReturn(run func(string) (*network.WakeOnLANConfig, error)) *MockBackend_GetWakeOnLAN_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetWiFiEnabled provides a mock function with no fields
 func (_m *MockBackend) GetWiFiEnabled() (bool, error) {
 	ret := _m.Called()
@@ -887,6 +1167,65 @@ func (_c *MockBackend_GetWiredNetworkDetails_Call) RunAndReturn(run func(string)
 	return _c
 }
 
+// ImportOpenVPNProfile provides a mock function with given fields: path, name
+func (_m *MockBackend) ImportOpenVPNProfile(path string, name string) (*network.VPNProfile, error) {
+	ret := _m.Called(path, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportOpenVPNProfile")
+	}
+
+	var r0 *network.VPNProfile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*network.VPNProfile, error)); ok {
+		return rf(path, name)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *network.VPNProfile); ok {
+		r0 = rf(path, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*network.VPNProfile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(path, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBackend_ImportOpenVPNProfile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ImportOpenVPNProfile'
+type MockBackend_ImportOpenVPNProfile_Call struct {
+	*mock.Call
+}
+
+// ImportOpenVPNProfile is a helper method to define mock.On call
+//   - path string
+//   - name string
+func (_e *MockBackend_Expecter) ImportOpenVPNProfile(path interface{}, name interface{}) *MockBackend_ImportOpenVPNProfile_Call {
+	return &MockBackend_ImportOpenVPNProfile_Call{Call: _e.mock.On("ImportOpenVPNProfile", path, name)}
+}
+
+func (_c *MockBackend_ImportOpenVPNProfile_Call) Run(run func(path string, name string)) *MockBackend_ImportOpenVPNProfile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockBackend_ImportOpenVPNProfile_Call) Return(_a0 *network.VPNProfile, _a1 error) *MockBackend_ImportOpenVPNProfile_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBackend_ImportOpenVPNProfile_Call) RunAndReturn(run func(string, string) (*network.VPNProfile, error)) *MockBackend_ImportOpenVPNProfile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Initialize provides a mock function with no fields
 func (_m *MockBackend) Initialize() error {
 	ret := _m.Called()
@@ -989,24 +1328,24 @@ func (_c *MockBackend_ListActiveVPN_Call) RunAndReturn(run func() ([]network.VPN
 	return _c
 }
 
-// ListVPNProfiles provides a mock function with no fields
-func (_m *MockBackend) ListVPNProfiles() ([]network.VPNProfile, error) {
+// ListSavedWiFiNetworks provides a mock function with no fields
+func (_m *MockBackend) ListSavedWiFiNetworks() ([]network.SavedWiFiNetwork, error) {
 	ret := _m.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListVPNProfiles")
+		panic("no return value specified for ListSavedWiFiNetworks")
 	}
 
-	var r0 []network.VPNProfile
+	var r0 []network.SavedWiFiNetwork
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]network.VPNProfile, error)); ok {
+	if rf, ok := ret.Get(0).(func() ([]network.SavedWiFiNetwork, error)); ok {
 		return rf()
 	}
-	if rf, ok := ret.Get(0).(func() []network.VPNProfile); ok {
+	if rf, ok := ret.Get(0).(func() []network.SavedWiFiNetwork); ok {
 		r0 = rf()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]network.VPNProfile)
+			r0 = ret.Get(0).([]network.SavedWiFiNetwork)
 		}
 	}
 
@@ -1019,39 +1358,96 @@ func (_m *MockBackend) ListVPNProfiles() ([]network.VPNProfile, error) {
 	return r0, r1
 }
 
-// MockBackend_ListVPNProfiles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListVPNProfiles'
-type MockBackend_ListVPNProfiles_Call struct {
+// MockBackend_ListSavedWiFiNetworks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSavedWiFiNetworks'
+type MockBackend_ListSavedWiFiNetworks_Call struct {
 	*mock.Call
 }
 
-// ListVPNProfiles is a helper method to define mock.On call
-func (_e *MockBackend_Expecter) ListVPNProfiles() *MockBackend_ListVPNProfiles_Call {
-	return &MockBackend_ListVPNProfiles_Call{Call: _e.mock.On("ListVPNProfiles")}
+// ListSavedWiFiNetworks is a helper method to define mock.On call
+func (_e *MockBackend_Expecter) ListSavedWiFiNetworks() *MockBackend_ListSavedWiFiNetworks_Call {
+	return &MockBackend_ListSavedWiFiNetworks_Call{Call: _e.mock.On("ListSavedWiFiNetworks")}
 }
 
-func (_c *MockBackend_ListVPNProfiles_Call) Run(run func()) *MockBackend_ListVPNProfiles_Call {
+func (_c *MockBackend_ListSavedWiFiNetworks_Call) Run(run func()) *MockBackend_ListSavedWiFiNetworks_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run()
 	})
 	return _c
 }
 
-func (_c *MockBackend_ListVPNProfiles_Call) Return(_a0 []network.VPNProfile, _a1 error) *MockBackend_ListVPNProfiles_Call {
+func (_c *MockBackend_ListSavedWiFiNetworks_Call) Return(_a0 []network.SavedWiFiNetwork, _a1 error) *MockBackend_ListSavedWiFiNetworks_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockBackend_ListVPNProfiles_Call) RunAndReturn(run func() ([]network.VPNProfile, error)) *MockBackend_ListVPNProfiles_Call {
+func (_c *MockBackend_ListSavedWiFiNetworks_Call) RunAndReturn(run func() ([]network.SavedWiFiNetwork, error)) *MockBackend_ListSavedWiFiNetworks_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ScanWiFi provides a mock function with no fields
-func (_m *MockBackend) ScanWiFi() error {
+// ListVPNProfiles provides a mock function with no fields
+func (_m *MockBackend) ListVPNProfiles() ([]network.VPNProfile, error) {
 	ret := _m.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for ScanWiFi")
+		panic("no return value specified for ListVPNProfiles")
+	}
+
+	var r0 []network.VPNProfile
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]network.VPNProfile, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []network.VPNProfile); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]network.VPNProfile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockBackend_ListVPNProfiles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListVPNProfiles'
+type MockBackend_ListVPNProfiles_Call struct {
+	*mock.Call
+}
+
+// ListVPNProfiles is a helper method to define mock.On call
+func (_e *MockBackend_Expecter) ListVPNProfiles() *MockBackend_ListVPNProfiles_Call {
+	return &MockBackend_ListVPNProfiles_Call{Call: _e.mock.On("ListVPNProfiles")}
+}
+
+func (_c *MockBackend_ListVPNProfiles_Call) Run(run func()) *MockBackend_ListVPNProfiles_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockBackend_ListVPNProfiles_Call) Return(_a0 []network.VPNProfile, _a1 error) *MockBackend_ListVPNProfiles_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockBackend_ListVPNProfiles_Call) RunAndReturn(run func() ([]network.VPNProfile, error)) *MockBackend_ListVPNProfiles_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ScanWiFi provides a mock function with no fields
+func (_m *MockBackend) ScanWiFi() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ScanWiFi")
 	}
 
 	var r0 error
@@ -1091,6 +1487,193 @@ func (_c *MockBackend_ScanWiFi_Call) RunAndReturn(run func() error) *MockBackend
 	return _c
 }
 
+// SelectWiFiDevice provides a mock function with given fields: iface
+func (_m *MockBackend) SelectWiFiDevice(iface string) error {
+	ret := _m.Called(iface)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SelectWiFiDevice")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(iface)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBackend_SelectWiFiDevice_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SelectWiFiDevice'
+type MockBackend_SelectWiFiDevice_Call struct {
+	*mock.Call
+}
+
+// SelectWiFiDevice is a helper method to define mock.On call
+//   - iface string
+func (_e *MockBackend_Expecter) SelectWiFiDevice(iface interface{}) *MockBackend_SelectWiFiDevice_Call {
+	return &MockBackend_SelectWiFiDevice_Call{Call: _e.mock.On("SelectWiFiDevice", iface)}
+}
+
+func (_c *MockBackend_SelectWiFiDevice_Call) Run(run func(iface string)) *MockBackend_SelectWiFiDevice_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *MockBackend_SelectWiFiDevice_Call) Return(_a0 error) *MockBackend_SelectWiFiDevice_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBackend_SelectWiFiDevice_Call) RunAndReturn(run func(string) error) *MockBackend_SelectWiFiDevice_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetConnectionDNS provides a mock function with given fields: uuidOrSSID, config
+func (_m *MockBackend) SetConnectionDNS(uuidOrSSID string, config network.DNSConfig) error {
+	ret := _m.Called(uuidOrSSID, config)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetConnectionDNS")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, network.DNSConfig) error); ok {
+		r0 = rf(uuidOrSSID, config)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBackend_SetConnectionDNS_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetConnectionDNS'
+type MockBackend_SetConnectionDNS_Call struct {
+	*mock.Call
+}
+
+// SetConnectionDNS is a helper method to define mock.On call
+//   - uuidOrSSID string
+//   - config network.DNSConfig
+func (_e *MockBackend_Expecter) SetConnectionDNS(uuidOrSSID interface{}, config interface{}) *MockBackend_SetConnectionDNS_Call {
+	return &MockBackend_SetConnectionDNS_Call{Call: _e.mock.On("SetConnectionDNS", uuidOrSSID, config)}
+}
+
+func (_c *MockBackend_SetConnectionDNS_Call) Run(run func(uuidOrSSID string, config network.DNSConfig)) *MockBackend_SetConnectionDNS_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(network.DNSConfig))
+	})
+	return _c
+}
+
+func (_c *MockBackend_SetConnectionDNS_Call) Return(_a0 error) *MockBackend_SetConnectionDNS_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBackend_SetConnectionDNS_Call) RunAndReturn(run func(string, network.DNSConfig) error) *MockBackend_SetConnectionDNS_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetIPv6Config provides a mock function with given fields: uuidOrSSID, config
+func (_m *MockBackend) SetIPv6Config(uuidOrSSID string, config network.IPv6Config) error {
+	ret := _m.Called(uuidOrSSID, config)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetIPv6Config")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, network.IPv6Config) error); ok {
+		r0 = rf(uuidOrSSID, config)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBackend_SetIPv6Config_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetIPv6Config'
+type MockBackend_SetIPv6Config_Call struct {
+	*mock.Call
+}
+
+// SetIPv6Config is a helper method to define mock.On call
+//   - uuidOrSSID string
+//   - config network.IPv6Config
+func (_e *MockBackend_Expecter) SetIPv6Config(uuidOrSSID interface{}, config interface{}) *MockBackend_SetIPv6Config_Call {
+	return &MockBackend_SetIPv6Config_Call{Call: _e.mock.On("SetIPv6Config", uuidOrSSID, config)}
+}
+
+func (_c *MockBackend_SetIPv6Config_Call) Run(run func(uuidOrSSID string, config network.IPv6Config)) *MockBackend_SetIPv6Config_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(network.IPv6Config))
+	})
+	return _c
+}
+
+func (_c *MockBackend_SetIPv6Config_Call) Return(_a0 error) *MockBackend_SetIPv6Config_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBackend_SetIPv6Config_Call) RunAndReturn(run func(string, network.IPv6Config) error) *MockBackend_SetIPv6Config_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetMetered provides a mock function with given fields: uuidOrSSID, mode
+func (_m *MockBackend) SetMetered(uuidOrSSID string, mode string) error {
+	ret := _m.Called(uuidOrSSID, mode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetMetered")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(uuidOrSSID, mode)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBackend_SetMetered_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetMetered'
+type MockBackend_SetMetered_Call struct {
+	*mock.Call
+}
+
+// SetMetered is a helper method to define mock.On call
+//   - uuidOrSSID string
+//   - mode string
+func (_e *MockBackend_Expecter) SetMetered(uuidOrSSID interface{}, mode interface{}) *MockBackend_SetMetered_Call {
+	return &MockBackend_SetMetered_Call{Call: _e.mock.On("SetMetered", uuidOrSSID, mode)}
+}
+
+func (_c *MockBackend_SetMetered_Call) Run(run func(uuidOrSSID string, mode string)) *MockBackend_SetMetered_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockBackend_SetMetered_Call) Return(_a0 error) *MockBackend_SetMetered_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBackend_SetMetered_Call) RunAndReturn(run func(string, string) error) *MockBackend_SetMetered_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SetPromptBroker provides a mock function with given fields: broker
 func (_m *MockBackend) SetPromptBroker(broker network.PromptBroker) error {
 	ret := _m.Called(broker)
@@ -1137,6 +1720,100 @@ func (_c *MockBackend_SetPromptBroker_Call) RunAndReturn(run func(network.Prompt
 	return _c
 }
 
+// SetSearchDomains provides a mock function with given fields: uuidOrSSID, domains
+func (_m *MockBackend) SetSearchDomains(uuidOrSSID string, domains []string) error {
+	ret := _m.Called(uuidOrSSID, domains)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetSearchDomains")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []string) error); ok {
+		r0 = rf(uuidOrSSID, domains)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBackend_SetSearchDomains_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetSearchDomains'
+type MockBackend_SetSearchDomains_Call struct {
+	*mock.Call
+}
+
+// SetSearchDomains is a helper method to define mock.On call
+//   - uuidOrSSID string
+//   - domains []string
+func (_e *MockBackend_Expecter) SetSearchDomains(uuidOrSSID interface{}, domains interface{}) *MockBackend_SetSearchDomains_Call {
+	return &MockBackend_SetSearchDomains_Call{Call: _e.mock.On("SetSearchDomains", uuidOrSSID, domains)}
+}
+
+func (_c *MockBackend_SetSearchDomains_Call) Run(run func(uuidOrSSID string, domains []string)) *MockBackend_SetSearchDomains_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *MockBackend_SetSearchDomains_Call) Return(_a0 error) *MockBackend_SetSearchDomains_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBackend_SetSearchDomains_Call) RunAndReturn(run func(string, []string) error) *MockBackend_SetSearchDomains_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetWakeOnLAN provides a mock function with given fields: uuid, config
+func (_m *MockBackend) SetWakeOnLAN(uuid string, config network.WakeOnLANConfig) error {
+	ret := _m.Called(uuid, config)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetWakeOnLAN")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, network.WakeOnLANConfig) error); ok {
+		r0 = rf(uuid, config)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBackend_SetWakeOnLAN_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetWakeOnLAN'
+type MockBackend_SetWakeOnLAN_Call struct {
+	*mock.Call
+}
+
+// SetWakeOnLAN is a helper method to define mock.On call
+//   - uuid string
+//   - config network.WakeOnLANConfig
+func (_e *MockBackend_Expecter) SetWakeOnLAN(uuid interface{}, config interface{}) *MockBackend_SetWakeOnLAN_Call {
+	return &MockBackend_SetWakeOnLAN_Call{Call: _e.mock.On("SetWakeOnLAN", uuid, config)}
+}
+
+func (_c *MockBackend_SetWakeOnLAN_Call) Run(run func(uuid string, config network.WakeOnLANConfig)) *MockBackend_SetWakeOnLAN_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(network.WakeOnLANConfig))
+	})
+	return _c
+}
+
+func (_c *MockBackend_SetWakeOnLAN_Call) Return(_a0 error) *MockBackend_SetWakeOnLAN_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBackend_SetWakeOnLAN_Call) RunAndReturn(run func(string, network.WakeOnLANConfig) error) *MockBackend_SetWakeOnLAN_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SetWiFiEnabled provides a mock function with given fields: enabled
 func (_m *MockBackend) SetWiFiEnabled(enabled bool) error {
 	ret := _m.Called(enabled)
@@ -1183,6 +1860,101 @@ func (_c *MockBackend_SetWiFiEnabled_Call) RunAndReturn(run func(bool) error) *M
 	return _c
 }
 
+// SetWiredIPConfig provides a mock function with given fields: uuid, config
+func (_m *MockBackend) SetWiredIPConfig(uuid string, config network.WiredIPConfig) error {
+	ret := _m.Called(uuid, config)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetWiredIPConfig")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, network.WiredIPConfig) error); ok {
+		r0 = rf(uuid, config)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBackend_SetWiredIPConfig_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetWiredIPConfig'
+type MockBackend_SetWiredIPConfig_Call struct {
+	*mock.Call
+}
+
+// SetWiredIPConfig is a helper method to define mock.On call
+//   - uuid string
+//   - config network.WiredIPConfig
+func (_e *MockBackend_Expecter) SetWiredIPConfig(uuid interface{}, config interface{}) *MockBackend_SetWiredIPConfig_Call {
+	return &MockBackend_SetWiredIPConfig_Call{Call: _e.mock.On("SetWiredIPConfig", uuid, config)}
+}
+
+func (_c *MockBackend_SetWiredIPConfig_Call) Run(run func(uuid string, config network.WiredIPConfig)) *MockBackend_SetWiredIPConfig_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(network.WiredIPConfig))
+	})
+	return _c
+}
+
+func (_c *MockBackend_SetWiredIPConfig_Call) Return(_a0 error) *MockBackend_SetWiredIPConfig_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBackend_SetWiredIPConfig_Call) RunAndReturn(run func(string, network.WiredIPConfig) error) *MockBackend_SetWiredIPConfig_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StartHotspot provides a mock function with given fields: ssid, password, band
+func (_m *MockBackend) StartHotspot(ssid string, password string, band string) error {
+	ret := _m.Called(ssid, password, band)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartHotspot")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(ssid, password, band)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBackend_StartHotspot_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StartHotspot'
+type MockBackend_StartHotspot_Call struct {
+	*mock.Call
+}
+
+// StartHotspot is a helper method to define mock.On call
+//   - ssid string
+//   - password string
+//   - band string
+func (_e *MockBackend_Expecter) StartHotspot(ssid interface{}, password interface{}, band interface{}) *MockBackend_StartHotspot_Call {
+	return &MockBackend_StartHotspot_Call{Call: _e.mock.On("StartHotspot", ssid, password, band)}
+}
+
+func (_c *MockBackend_StartHotspot_Call) Run(run func(ssid string, password string, band string)) *MockBackend_StartHotspot_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockBackend_StartHotspot_Call) Return(_a0 error) *MockBackend_StartHotspot_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBackend_StartHotspot_Call) RunAndReturn(run func(string, string, string) error) *MockBackend_StartHotspot_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // StartMonitoring provides a mock function with given fields: onStateChange
 func (_m *MockBackend) StartMonitoring(onStateChange func()) error {
 	ret := _m.Called(onStateChange)
@@ -1229,6 +2001,51 @@ func (_c *MockBackend_StartMonitoring_Call) RunAndReturn(run func(func()) error)
 	return _c
 }
 
+// StopHotspot provides a mock function with no fields
+func (_m *MockBackend) StopHotspot() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for StopHotspot")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBackend_StopHotspot_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StopHotspot'
+type MockBackend_StopHotspot_Call struct {
+	*mock.Call
+}
+
+// StopHotspot is a helper method to define mock.On call
+func (_e *MockBackend_Expecter) StopHotspot() *MockBackend_StopHotspot_Call {
+	return &MockBackend_StopHotspot_Call{Call: _e.mock.On("StopHotspot")}
+}
+
+func (_c *MockBackend_StopHotspot_Call) Run(run func()) *MockBackend_StopHotspot_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockBackend_StopHotspot_Call) Return(_a0 error) *MockBackend_StopHotspot_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBackend_StopHotspot_Call) RunAndReturn(run func() error) *MockBackend_StopHotspot_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // StopMonitoring provides a mock function with no fields
 func (_m *MockBackend) StopMonitoring() {
 	_m.Called()
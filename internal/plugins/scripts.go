@@ -0,0 +1,60 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// pluginManifest is the part of a plugin's own plugin.json listing the
+// scripts it may ask the daemon to run, by name
+type pluginManifest struct {
+	Scripts map[string]string `json:"scripts"`
+}
+
+// ScriptPath resolves a script declared in an installed plugin's manifest.
+// Only declared scripts inside the plugin's directory can be run
+func (m *Manager) ScriptPath(pluginID, script string) (string, error) {
+	if pluginID == "" || strings.ContainsAny(pluginID, `/\`) || pluginID == "." || pluginID == ".." {
+		return "", fmt.Errorf("invalid plugin id: %q", pluginID)
+	}
+
+	pluginDir := filepath.Join(m.pluginsDir, pluginID)
+	if resolved, err := filepath.EvalSymlinks(pluginDir); err == nil {
+		pluginDir = resolved
+	}
+
+	data, err := afero.ReadFile(m.fs, filepath.Join(pluginDir, "plugin.json"))
+	if err != nil {
+		return "", fmt.Errorf("plugin %s is not installed", pluginID)
+	}
+
+	var manifest pluginManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return "", fmt.Errorf("failed to parse plugin.json for %s: %w", pluginID, err)
+	}
+	rel, ok := manifest.Scripts[script]
+	if !ok {
+		return "", fmt.Errorf("plugin %s declares no script named %q", pluginID, script)
+	}
+
+	path := filepath.Join(pluginDir, rel)
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if !strings.HasPrefix(path, pluginDir+string(filepath.Separator)) {
+		return "", fmt.Errorf("script %q of plugin %s is outside the plugin directory", script, pluginID)
+	}
+
+	info, err := m.fs.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("script %q of plugin %s: %w", script, pluginID, err)
+	}
+	if !info.Mode().IsRegular() || info.Mode()&0111 == 0 {
+		return "", fmt.Errorf("script %q of plugin %s is not executable", script, pluginID)
+	}
+	return path, nil
+}
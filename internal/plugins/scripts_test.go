@@ -0,0 +1,52 @@
+package plugins
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptPath(t *testing.T) {
+	manager, fs, pluginsDir := setupTestManager(t)
+	pluginDir := filepath.Join(pluginsDir, "weather")
+
+	manifest := `{"id": "weather", "scripts": {"fetch": "scripts/fetch.sh", "escape": "../other/run.sh", "plain": "README.md"}}`
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(pluginDir, "plugin.json"), []byte(manifest), 0644))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(pluginDir, "scripts", "fetch.sh"), []byte("#!/bin/sh\n"), 0755))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(pluginDir, "README.md"), []byte("docs"), 0644))
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(pluginsDir, "other", "run.sh"), []byte("#!/bin/sh\n"), 0755))
+
+	t.Run("declared script", func(t *testing.T) {
+		path, err := manager.ScriptPath("weather", "fetch")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(pluginDir, "scripts", "fetch.sh"), path)
+	})
+
+	t.Run("undeclared script", func(t *testing.T) {
+		_, err := manager.ScriptPath("weather", "rm")
+		assert.ErrorContains(t, err, "declares no script")
+	})
+
+	t.Run("outside the plugin directory", func(t *testing.T) {
+		_, err := manager.ScriptPath("weather", "escape")
+		assert.ErrorContains(t, err, "outside the plugin directory")
+	})
+
+	t.Run("not executable", func(t *testing.T) {
+		_, err := manager.ScriptPath("weather", "plain")
+		assert.ErrorContains(t, err, "not executable")
+	})
+
+	t.Run("invalid plugin id", func(t *testing.T) {
+		_, err := manager.ScriptPath("../weather", "fetch")
+		assert.ErrorContains(t, err, "invalid plugin id")
+	})
+
+	t.Run("not installed", func(t *testing.T) {
+		_, err := manager.ScriptPath("missing", "fetch")
+		assert.ErrorContains(t, err, "not installed")
+	})
+}
@@ -0,0 +1,235 @@
+package privesc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const askpassSocketEnv = "DMS_ASKPASS_SOCKET"
+
+// Prompter asks the user for a password. retry is set after a wrong one; ok
+// is false when the user cancelled
+type Prompter func(prompt string, retry bool) (password string, remember bool, ok bool)
+
+// Broker answers sudo's askpass requests, so the user is only asked when a
+// command actually needs a password. sudo reaches it through SUDO_ASKPASS
+// and a socket in a directory only this user can open; the password goes
+// over that socket and the askpass pipe, never onto a command line
+type Broker struct {
+	dir      string
+	listener net.Listener
+	prompt   Prompter
+	validate func(string) bool
+
+	promptMu sync.Mutex
+	wg       sync.WaitGroup
+}
+
+var (
+	brokerMu     sync.Mutex
+	activeBroker *Broker
+)
+
+// StartBroker starts answering askpass requests with prompt and makes sudo
+// commands built by this package use it until Close
+func StartBroker(prompt Prompter) (*Broker, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find own executable: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "dms-askpass-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create askpass directory: %w", err)
+	}
+
+	socket := filepath.Join(dir, "broker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to listen on %s: %w", socket, err)
+	}
+
+	script := filepath.Join(dir, "askpass")
+	content := fmt.Sprintf("#!/bin/sh\n%s=%s exec %s \"$@\"\n", askpassSocketEnv, shellQuote(socket), shellQuote(exe))
+	if err := os.WriteFile(script, []byte(content), 0700); err != nil {
+		listener.Close()
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to write askpass script: %w", err)
+	}
+
+	b := &Broker{
+		dir:      dir,
+		listener: listener,
+		prompt:   prompt,
+		validate: ValidatePassword,
+	}
+
+	brokerMu.Lock()
+	activeBroker = b
+	os.Setenv("SUDO_ASKPASS", script)
+	brokerMu.Unlock()
+
+	b.wg.Add(1)
+	go b.serve()
+	return b, nil
+}
+
+// Close stops the broker; sudo falls back to asking on the terminal
+func (b *Broker) Close() {
+	brokerMu.Lock()
+	if activeBroker == b {
+		activeBroker = nil
+		os.Unsetenv("SUDO_ASKPASS")
+	}
+	brokerMu.Unlock()
+
+	b.listener.Close()
+	b.wg.Wait()
+	os.RemoveAll(b.dir)
+}
+
+func brokerActive() bool {
+	brokerMu.Lock()
+	defer brokerMu.Unlock()
+	return activeBroker != nil
+}
+
+func (b *Broker) serve() {
+	defer b.wg.Done()
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		go b.handle(conn)
+	}
+}
+
+func (b *Broker) handle(conn net.Conn) {
+	defer conn.Close()
+
+	prompt, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && prompt == "" {
+		return
+	}
+	password, ok := b.password(strings.TrimSpace(prompt))
+	if !ok {
+		return
+	}
+	fmt.Fprintf(conn, "%s\n", password)
+}
+
+// password serves the password remembered for this run, or asks until a
+// valid one is typed so a typo doesn't use up one of sudo's attempts.
+// Requests are asked one at a time
+func (b *Broker) password(prompt string) (string, bool) {
+	b.promptMu.Lock()
+	defer b.promptMu.Unlock()
+
+	if password, ok := Remembered(); ok {
+		return password, true
+	}
+
+	notifyMultiplexer("dms: sudo is waiting for your password")
+	for attempt := 0; attempt < 3; attempt++ {
+		password, remember, ok := b.prompt(prompt, attempt > 0)
+		if !ok {
+			return "", false
+		}
+		if !b.validate(password) {
+			continue
+		}
+		if remember {
+			Remember(password)
+		}
+		return password, true
+	}
+	return "", false
+}
+
+// notifyMultiplexer flags the prompt when the UI may be in a tmux, screen or
+// zellij pane the user isn't looking at. The bell marks the window in all
+// three
+func notifyMultiplexer(message string) {
+	switch {
+	case os.Getenv("TMUX") != "":
+		exec.Command("tmux", "display-message", message).Run()
+	case os.Getenv("STY") != "":
+		exec.Command("screen", "-S", os.Getenv("STY"), "-X", "echo", message).Run()
+	}
+	if os.Getenv("TMUX") != "" || os.Getenv("STY") != "" || os.Getenv("ZELLIJ") != "" {
+		fmt.Fprint(os.Stderr, "\a")
+	}
+}
+
+// HandleAskpass turns the process into sudo's askpass helper when the
+// broker's askpass script started it. Call it first thing in main
+func HandleAskpass() {
+	socket := os.Getenv(askpassSocketEnv)
+	if socket == "" {
+		return
+	}
+	os.Exit(runAskpass(socket, strings.Join(os.Args[1:], " "), os.Stdout))
+}
+
+func runAskpass(socket, prompt string, out io.Writer) int {
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "askpass: %v\n", err)
+		return 1
+	}
+	defer conn.Close()
+
+	fmt.Fprintf(conn, "%s\n", strings.ReplaceAll(prompt, "\n", " "))
+	password, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return 1
+	}
+	fmt.Fprint(out, password)
+	return 0
+}
+
+// PasswordRequest is a broker prompt handed to an event-loop UI. Answer or
+// Cancel it exactly once
+type PasswordRequest struct {
+	Prompt string
+	Retry  bool
+	reply  chan passwordReply
+}
+
+type passwordReply struct {
+	password string
+	remember bool
+	ok       bool
+}
+
+func (r PasswordRequest) Answer(password string, remember bool) {
+	r.reply <- passwordReply{password: password, remember: remember, ok: true}
+}
+
+func (r PasswordRequest) Cancel() {
+	r.reply <- passwordReply{}
+}
+
+// ChannelPrompter hands each prompt to requests and waits for the answer,
+// for UIs that can't block in a callback
+func ChannelPrompter(requests chan<- PasswordRequest) Prompter {
+	return func(prompt string, retry bool) (string, bool, bool) {
+		req := PasswordRequest{Prompt: prompt, Retry: retry, reply: make(chan passwordReply, 1)}
+		requests <- req
+		reply := <-req.reply
+		return reply.password, reply.remember, reply.ok
+	}
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
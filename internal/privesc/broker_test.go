@@ -0,0 +1,103 @@
+package privesc
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func startTestBroker(t *testing.T, prompt Prompter) (*Broker, string) {
+	t.Helper()
+	b, err := StartBroker(prompt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.validate = func(password string) bool { return password == "right" }
+	t.Cleanup(b.Close)
+	return b, b.listener.Addr().String()
+}
+
+func askpass(t *testing.T, socket string) (string, int) {
+	t.Helper()
+	var out strings.Builder
+	code := runAskpass(socket, "[sudo] password for test: ", &out)
+	return out.String(), code
+}
+
+func TestBrokerRetriesAndRemembers(t *testing.T) {
+	defer Forget()
+
+	var retries []bool
+	answers := []string{"wrong", "right"}
+	_, socket := startTestBroker(t, func(prompt string, retry bool) (string, bool, bool) {
+		if !strings.Contains(prompt, "password for test") {
+			t.Errorf("Expected sudo's prompt, got %q", prompt)
+		}
+		retries = append(retries, retry)
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, true, true
+	})
+
+	out, code := askpass(t, socket)
+	if code != 0 || out != "right\n" {
+		t.Fatalf("Expected the validated password, got %q (exit %d)", out, code)
+	}
+	if len(retries) != 2 || retries[0] || !retries[1] {
+		t.Errorf("Expected a retry after the wrong password, got %v", retries)
+	}
+	if password, ok := Remembered(); !ok || password != "right" {
+		t.Errorf("Expected the password to be remembered, got %q %v", password, ok)
+	}
+
+	out, code = askpass(t, socket)
+	if code != 0 || out != "right\n" || len(retries) != 2 {
+		t.Errorf("Expected the remembered password without prompting, got %q (exit %d, %d prompts)", out, code, len(retries))
+	}
+}
+
+func TestBrokerCancel(t *testing.T) {
+	defer Forget()
+
+	_, socket := startTestBroker(t, func(string, bool) (string, bool, bool) {
+		return "", false, false
+	})
+
+	if out, code := askpass(t, socket); code == 0 || out != "" {
+		t.Errorf("Expected a cancelled prompt to fail askpass, got %q (exit %d)", out, code)
+	}
+	if _, ok := Remembered(); ok {
+		t.Error("Expected nothing remembered after cancelling")
+	}
+}
+
+func TestShellPrefixUsesBroker(t *testing.T) {
+	SetTool(ToolSudo)
+	defer SetTool("")
+
+	if prefix := ShellPrefix(); prefix != "sudo" {
+		t.Errorf("Expected plain sudo without a broker, got %q", prefix)
+	}
+
+	b, err := StartBroker(func(string, bool) (string, bool, bool) { return "", false, false })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prefix := ShellPrefix(); prefix != "sudo -A" {
+		t.Errorf("Expected sudo -A with a broker, got %q", prefix)
+	}
+	if os.Getenv("SUDO_ASKPASS") == "" {
+		t.Error("Expected SUDO_ASKPASS to point at the askpass script")
+	}
+	if args := strings.Join(Command(t.Context(), "true").Args, " "); args != "sudo -A true" {
+		t.Errorf("Expected sudo -A true, got %q", args)
+	}
+
+	b.Close()
+	if prefix := ShellPrefix(); prefix != "sudo" {
+		t.Errorf("Expected plain sudo after Close, got %q", prefix)
+	}
+	if os.Getenv("SUDO_ASKPASS") != "" {
+		t.Error("Expected SUDO_ASKPASS to be cleared on Close")
+	}
+}
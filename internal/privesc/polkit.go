@@ -0,0 +1,216 @@
+package privesc
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const PolkitRulesPath = "/etc/polkit-1/rules.d/50-dms.rules"
+
+// Helper actions are what dms privileged runs as root through pkexec. Each
+// is a fixed command line, so the rule can match it exactly
+var HelperActions = [][]string{
+	{"greeter-group"},
+	{"greeter-theme-sync", "on"},
+	{"greeter-theme-sync", "off"},
+}
+
+// polkitRule is one action DMS performs with privileges. Condition is a
+// JavaScript expression over action
+type polkitRule struct {
+	comment   string
+	condition string
+}
+
+func polkitRulesFor(helper string) []polkitRule {
+	var lines []string
+	for _, action := range HelperActions {
+		commandLine := strings.Join(append([]string{helper, "privileged"}, action...), " ")
+		lines = append(lines, fmt.Sprintf("command == %q", commandLine))
+	}
+
+	return []polkitRule{
+		{
+			comment: "Setting the hostname from the shell",
+			condition: `action.id == "org.freedesktop.hostname1.set-hostname" ||
+        action.id == "org.freedesktop.hostname1.set-static-hostname"`,
+		},
+		{
+			comment: "Restarting the greeter after changing its config",
+			condition: `action.id == "org.freedesktop.systemd1.manage-units" &&
+        action.lookup("unit") == "greetd.service"`,
+		},
+		{
+			comment: "Greeter group membership and theme sync through the dms helper",
+			condition: fmt.Sprintf(`action.id == "org.freedesktop.policykit.exec" &&
+        action.lookup("program") == %q &&
+        (function(command) { return %s; })(action.lookup("command_line") || "")`,
+				helper, strings.Join(lines, " ||\n            ")),
+		},
+	}
+}
+
+// PolkitRules renders rules that let members of group in a local, active
+// session authenticate as themselves for DMS's privileged actions. The
+// answer is kept for a few minutes, so a multi-step change prompts once.
+// helper is the root-owned dms binary pkexec may run
+func PolkitRules(group, helper string) string {
+	var sb strings.Builder
+	sb.WriteString("// Generated by dms setup privileges\n")
+	sb.WriteString("polkit.addRule(function(action, subject) {\n")
+	fmt.Fprintf(&sb, "    if (!subject.local || !subject.active || !subject.isInGroup(%q)) {\n", group)
+	sb.WriteString("        return polkit.Result.NOT_HANDLED;\n    }\n")
+	for _, rule := range polkitRulesFor(helper) {
+		fmt.Fprintf(&sb, "\n    // %s\n    if (%s) {\n        return polkit.Result.AUTH_SELF_KEEP;\n    }\n", rule.comment, rule.condition)
+	}
+	sb.WriteString("\n    return polkit.Result.NOT_HANDLED;\n});\n")
+	return sb.String()
+}
+
+// HelperPath is the running dms binary, which the rules let pkexec run. It
+// has to be owned by root and writable only by root, or the rules would
+// hand root to whoever can replace it
+func HelperPath() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	path, err := filepath.EvalSymlinks(exe)
+	if err != nil {
+		return "", err
+	}
+	if err := checkRootOwned(path); err != nil {
+		return "", fmt.Errorf("%s can't be the polkit helper: %w; install dms system-wide first", path, err)
+	}
+	return path, nil
+}
+
+func checkRootOwned(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || stat.Uid != 0 {
+		return fmt.Errorf("not owned by root")
+	}
+	if info.Mode().Perm()&0022 != 0 {
+		return fmt.Errorf("writable by group or others")
+	}
+	return nil
+}
+
+// HelperAvailable reports whether the installed rules cover this binary and
+// a polkit agent can ask, so RunHelper prompts instead of sudo
+func HelperAvailable() bool {
+	if os.Getenv("WAYLAND_DISPLAY") == "" {
+		return false
+	}
+	if _, err := exec.LookPath("pkexec"); err != nil {
+		return false
+	}
+	helper, err := HelperPath()
+	if err != nil {
+		return false
+	}
+	rules, err := os.ReadFile(PolkitRulesPath)
+	return err == nil && strings.Contains(string(rules), fmt.Sprintf("%q", helper))
+}
+
+// RunHelper runs dms privileged with one of HelperActions through pkexec
+func RunHelper(action ...string) error {
+	helper, err := HelperPath()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "pkexec", append([]string{helper, "privileged"}, action...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("pkexec %s: %w", strings.Join(action, " "), err)
+	}
+	return nil
+}
+
+// AdminGroup is the group allowed to administer the system: wheel on most
+// distros, sudo on Debian and Ubuntu
+func AdminGroup() string {
+	return adminGroupFrom("/etc/group")
+}
+
+func adminGroupFrom(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return "wheel"
+	}
+	defer f.Close()
+
+	hasSudo := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, _, _ := strings.Cut(scanner.Text(), ":")
+		switch name {
+		case "wheel":
+			return "wheel"
+		case "sudo":
+			hasSudo = true
+		}
+	}
+	if hasSudo {
+		return "sudo"
+	}
+	return "wheel"
+}
+
+func PolkitRulesInstalled() bool {
+	_, err := os.Stat(PolkitRulesPath)
+	return err == nil
+}
+
+// InstallPolkitRules writes the rules for the admin group
+func InstallPolkitRules(logFunc func(string)) error {
+	tmp, err := os.CreateTemp("", "dms-polkit-*.rules")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	helper, err := HelperPath()
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	group := AdminGroup()
+	if _, err := tmp.WriteString(PolkitRules(group, helper)); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temp polkit rules: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	args := []string{"-D", "-m", "644", tmp.Name(), PolkitRulesPath}
+	cmd := Command(ctx, "install", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to install %s: %w", PolkitRulesPath, err)
+	}
+
+	logFunc(fmt.Sprintf("✓ Installed polkit rules for the %s group to %s", group, PolkitRulesPath))
+	return nil
+}
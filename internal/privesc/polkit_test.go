@@ -0,0 +1,92 @@
+package privesc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPolkitRules(t *testing.T) {
+	rules := PolkitRules("wheel", "/usr/bin/dms")
+
+	if !strings.Contains(rules, `subject.isInGroup("wheel")`) {
+		t.Error("Expected rules to be scoped to the wheel group")
+	}
+	for _, action := range []string{
+		"org.freedesktop.hostname1.set-static-hostname",
+		"org.freedesktop.systemd1.manage-units",
+		"org.freedesktop.policykit.exec",
+	} {
+		if !strings.Contains(rules, action) {
+			t.Errorf("Expected rules to cover %s", action)
+		}
+	}
+	if strings.Contains(rules, "polkit.Result.YES") {
+		t.Error("Rules must still ask for authentication")
+	}
+	for _, line := range []string{
+		`command == "/usr/bin/dms privileged greeter-group"`,
+		`command == "/usr/bin/dms privileged greeter-theme-sync on"`,
+		`command == "/usr/bin/dms privileged greeter-theme-sync off"`,
+		`action.lookup("program") == "/usr/bin/dms"`,
+	} {
+		if !strings.Contains(rules, line) {
+			t.Errorf("Expected rules to contain %s", line)
+		}
+	}
+	if strings.Contains(rules, ".test(") || strings.Contains(rules, "RegExp") {
+		t.Error("Helper rules must match exact command lines, not patterns")
+	}
+	if strings.Count(rules, "{") != strings.Count(rules, "}") {
+		t.Error("Unbalanced braces in generated rules")
+	}
+}
+
+func TestCheckRootOwned(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dms")
+	if err := os.WriteFile(path, nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if os.Geteuid() != 0 {
+		if err := checkRootOwned(path); err == nil {
+			t.Error("Expected a user-owned helper to be rejected")
+		}
+		return
+	}
+	if err := checkRootOwned(path); err != nil {
+		t.Errorf("Expected a root-owned 0755 helper to pass: %v", err)
+	}
+	if err := os.Chmod(path, 0775); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkRootOwned(path); err == nil {
+		t.Error("Expected a group-writable helper to be rejected")
+	}
+}
+
+func TestAdminGroupFrom(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"wheel", "root:x:0:\nwheel:x:10:alice\n", "wheel"},
+		{"sudo only", "root:x:0:\nsudo:x:27:alice\n", "sudo"},
+		{"both", "sudo:x:27:\nwheel:x:10:\n", "wheel"},
+		{"neither", "root:x:0:\n", "wheel"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if got := adminGroupFrom(path); got != tt.want {
+				t.Errorf("adminGroupFrom() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package privesc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Tool is the privilege escalation command used for installer and updater steps
+type Tool string
+
+const (
+	ToolSudo Tool = "sudo"
+	ToolDoas Tool = "doas"
+)
+
+var (
+	toolMu      sync.Mutex
+	currentTool Tool
+
+	rememberedMu       sync.Mutex
+	rememberedPassword string
+	remembered         bool
+)
+
+// DetectTool prefers sudo, falling back to doas on systems without it
+// (Void, Alpine, some Artix setups). pkexec is never used for installer
+// steps; the polkit rules only cover the helper actions in polkit.go
+func DetectTool() Tool {
+	if _, err := exec.LookPath("sudo"); err != nil {
+		if _, err := exec.LookPath("doas"); err == nil {
+			return ToolDoas
+		}
+	}
+	return ToolSudo
+}
+
+// ParseTool validates an --escalation value. "auto" and "" detect the tool.
+func ParseTool(name string) (Tool, error) {
+	switch name {
+	case "", "auto":
+		return DetectTool(), nil
+	case string(ToolSudo), string(ToolDoas):
+		if _, err := exec.LookPath(name); err != nil {
+			return "", fmt.Errorf("%s not found in PATH", name)
+		}
+		return Tool(name), nil
+	default:
+		return "", fmt.Errorf("unsupported escalation tool: %s (expected sudo or doas)", name)
+	}
+}
+
+// SetTool overrides the detected escalation tool
+func SetTool(tool Tool) {
+	toolMu.Lock()
+	defer toolMu.Unlock()
+	currentTool = tool
+}
+
+// CurrentTool returns the escalation tool in use, detecting it on first use
+func CurrentTool() Tool {
+	toolMu.Lock()
+	defer toolMu.Unlock()
+	if currentTool == "" {
+		currentTool = DetectTool()
+	}
+	return currentTool
+}
+
+// ShellPrefix returns what to put in front of a privileged command inside a
+// bash -c string. sudo asks the running Broker through SUDO_ASKPASS, or the
+// terminal when there is none. doas can't do either, so it runs
+// non-interactively and relies on a nopass or persist rule.
+func ShellPrefix() string {
+	switch CurrentTool() {
+	case ToolDoas:
+		return "doas -n"
+	}
+	if brokerActive() {
+		return "sudo -A"
+	}
+	return "sudo"
+}
+
+// Command builds a privileged command, getting the password the same way as
+// ShellPrefix
+func Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	switch CurrentTool() {
+	case ToolDoas:
+		return exec.CommandContext(ctx, "doas", append([]string{"-n", name}, args...)...)
+	}
+
+	if brokerActive() {
+		return exec.CommandContext(ctx, "sudo", append([]string{"-A", name}, args...)...)
+	}
+	cmd := exec.CommandContext(ctx, "sudo", append([]string{name}, args...)...)
+	cmd.Stdin = os.Stdin
+	return cmd
+}
+
+// Preauthenticate lets doas ask for the password on the terminal before a
+// TUI takes it over, so later doas -n calls succeed through persist
+func Preauthenticate() error {
+	if CurrentTool() != ToolDoas || CanRunWithoutPassword() {
+		return nil
+	}
+
+	cmd := exec.Command("doas", "true")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("doas authentication failed: %w", err)
+	}
+
+	if !CanRunWithoutPassword() {
+		return fmt.Errorf("doas needs a 'persist' or 'nopass' rule in /etc/doas.conf for non-interactive use")
+	}
+	return nil
+}
+
+// CanRunWithoutPassword reports whether escalation works without a password,
+// either through NOPASSWD/nopass rules or a still-valid timestamp
+func CanRunWithoutPassword() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return exec.CommandContext(ctx, string(CurrentTool()), "-n", "true").Run() == nil
+}
+
+// ValidatePassword checks password with sudo -v, writing it to stdin so
+// special characters are handled. doas can't take a password this way, so
+// only a preauthenticated or nopass setup validates.
+func ValidatePassword(password string) bool {
+	if CurrentTool() == ToolDoas {
+		return CanRunWithoutPassword()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sudo", "-S", "-v")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return false
+	}
+
+	go func() {
+		defer stdin.Close()
+		fmt.Fprintf(stdin, "%s\n", password)
+	}()
+
+	return cmd.Run() == nil
+}
+
+// Remember keeps password for the rest of this run so later escalations
+// don't prompt again
+func Remember(password string) {
+	rememberedMu.Lock()
+	defer rememberedMu.Unlock()
+	rememberedPassword = password
+	remembered = true
+}
+
+// Forget drops any remembered password
+func Forget() {
+	rememberedMu.Lock()
+	defer rememberedMu.Unlock()
+	rememberedPassword = ""
+	remembered = false
+}
+
+// Remembered returns the password remembered for this run, if any
+func Remembered() (string, bool) {
+	rememberedMu.Lock()
+	defer rememberedMu.Unlock()
+	return rememberedPassword, remembered
+}
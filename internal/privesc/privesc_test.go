@@ -0,0 +1,22 @@
+package privesc
+
+import "testing"
+
+func TestRememberForget(t *testing.T) {
+	defer Forget()
+
+	if _, ok := Remembered(); ok {
+		t.Fatal("Expected nothing remembered initially")
+	}
+
+	Remember("secret")
+	password, ok := Remembered()
+	if !ok || password != "secret" {
+		t.Errorf("Expected remembered password, got %q %v", password, ok)
+	}
+
+	Forget()
+	if _, ok := Remembered(); ok {
+		t.Error("Expected password to be forgotten")
+	}
+}
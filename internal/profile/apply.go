@@ -0,0 +1,163 @@
+package profile
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Step is the outcome of applying one part of a profile
+type Step struct {
+	Name string
+	Err  error
+}
+
+// Applier applies profiles through the dms server for state it owns and
+// through external tools for the rest
+type Applier struct {
+	Call func(method string, params map[string]interface{}) error
+	Run  func(name string, args ...string) error
+}
+
+func NewApplier(call func(method string, params map[string]interface{}) error) *Applier {
+	return &Applier{
+		Call: call,
+		Run: func(name string, args ...string) error {
+			output, err := exec.Command(name, args...).CombinedOutput()
+			if err != nil {
+				return fmt.Errorf("%s: %s", name, strings.TrimSpace(string(output)))
+			}
+			return nil
+		},
+	}
+}
+
+// Apply runs every part of the profile, continuing past failures so one
+// missing device doesn't leave the rest unapplied
+func (a *Applier) Apply(p *Profile) []Step {
+	var steps []Step
+	if len(p.Outputs) > 0 {
+		steps = append(steps, Step{"outputs", a.applyOutputs(p.Outputs)})
+	}
+	if p.Gamma != nil {
+		steps = append(steps, Step{"gamma", a.applyGamma(p.Gamma)})
+	}
+	if p.Wallpaper != nil {
+		steps = append(steps, Step{"wallpaper", a.Call("wallpaper.set", map[string]interface{}{
+			"path":   p.Wallpaper.Path,
+			"output": p.Wallpaper.Output,
+			"fill":   p.Wallpaper.Fill,
+		})})
+	}
+	if p.Audio != nil {
+		steps = append(steps, Step{"audio", a.Run("pactl", "set-default-sink", p.Audio.Sink)})
+	}
+	if p.VPN != nil {
+		steps = append(steps, Step{"vpn", a.applyVPN(p.VPN)})
+	}
+	return steps
+}
+
+func (a *Applier) applyGamma(g *Gamma) error {
+	if g.Low > 0 && g.High > 0 {
+		if err := a.Call("wayland.gamma.setTemperature", map[string]interface{}{"low": g.Low, "high": g.High}); err != nil {
+			return err
+		}
+	}
+	if g.Latitude != nil && g.Longitude != nil {
+		if err := a.Call("wayland.gamma.setLocation", map[string]interface{}{"latitude": *g.Latitude, "longitude": *g.Longitude}); err != nil {
+			return err
+		}
+	}
+	if g.UseIPLocation != nil {
+		if err := a.Call("wayland.gamma.setUseIPLocation", map[string]interface{}{"use": *g.UseIPLocation}); err != nil {
+			return err
+		}
+	}
+	if g.Sunrise != "" || g.Sunset != "" {
+		if err := a.Call("wayland.gamma.setManualTimes", map[string]interface{}{"sunrise": g.Sunrise, "sunset": g.Sunset}); err != nil {
+			return err
+		}
+	}
+	if g.Enabled != nil {
+		return a.Call("wayland.gamma.setEnabled", map[string]interface{}{"enabled": *g.Enabled})
+	}
+	return nil
+}
+
+func (a *Applier) applyVPN(v *VPN) error {
+	if v.Disconnect {
+		return a.Call("network.vpn.disconnectAll", nil)
+	}
+	if v.Connect != "" {
+		return a.Call("network.vpn.connect", map[string]interface{}{"uuidOrName": v.Connect})
+	}
+	return nil
+}
+
+func (a *Applier) applyOutputs(outputs []Output) error {
+	var commands [][]string
+	switch {
+	case os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "":
+		commands = hyprlandOutputCommands(outputs)
+	case os.Getenv("NIRI_SOCKET") != "":
+		commands = niriOutputCommands(outputs)
+	default:
+		return fmt.Errorf("monitor layout not supported on this compositor")
+	}
+
+	for _, command := range commands {
+		if err := a.Run(command[0], command[1:]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hyprlandOutputCommands(outputs []Output) [][]string {
+	var commands [][]string
+	for _, out := range outputs {
+		rule := out.Name + ",disable"
+		if out.Enabled == nil || *out.Enabled {
+			mode, position, scale := "preferred", "auto", "1"
+			if out.Mode != "" {
+				mode = out.Mode
+			}
+			if out.Position != "" {
+				position = strings.ReplaceAll(out.Position, ",", "x")
+			}
+			if out.Scale > 0 {
+				scale = strconv.FormatFloat(out.Scale, 'f', -1, 64)
+			}
+			rule = strings.Join([]string{out.Name, mode, position, scale}, ",")
+		}
+		commands = append(commands, []string{"hyprctl", "keyword", "monitor", rule})
+	}
+	return commands
+}
+
+func niriOutputCommands(outputs []Output) [][]string {
+	var commands [][]string
+	for _, out := range outputs {
+		msg := []string{"niri", "msg", "output", out.Name}
+		if out.Enabled != nil && !*out.Enabled {
+			commands = append(commands, append(msg, "off"))
+			continue
+		}
+
+		commands = append(commands, append(append([]string{}, msg...), "on"))
+		if out.Mode != "" {
+			commands = append(commands, append(append([]string{}, msg...), "mode", out.Mode))
+		}
+		if out.Position != "" {
+			x, y, _ := strings.Cut(strings.ReplaceAll(out.Position, "x", ","), ",")
+			commands = append(commands, append(append([]string{}, msg...), "position", "set", x, y))
+		}
+		if out.Scale > 0 {
+			commands = append(commands, append(append([]string{}, msg...), "scale", strconv.FormatFloat(out.Scale, 'f', -1, 64)))
+		}
+	}
+	return commands
+}
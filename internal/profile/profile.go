@@ -0,0 +1,226 @@
+// Package profile bundles shell state (night light schedule, wallpaper,
+// audio output, VPN and monitor layout) into named profiles such as work,
+// home or presentation
+package profile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+type Gamma struct {
+	Enabled       *bool    `json:"enabled,omitempty"`
+	Low           int      `json:"low,omitempty"`
+	High          int      `json:"high,omitempty"`
+	UseIPLocation *bool    `json:"useIPLocation,omitempty"`
+	Latitude      *float64 `json:"latitude,omitempty"`
+	Longitude     *float64 `json:"longitude,omitempty"`
+	Sunrise       string   `json:"sunrise,omitempty"`
+	Sunset        string   `json:"sunset,omitempty"`
+}
+
+type Wallpaper struct {
+	Path   string `json:"path"`
+	Output string `json:"output,omitempty"`
+	Fill   string `json:"fill,omitempty"`
+}
+
+type Audio struct {
+	Sink string `json:"sink"`
+}
+
+// VPN connects the named connection, or drops all VPNs when Disconnect is set
+type VPN struct {
+	Connect    string `json:"connect,omitempty"`
+	Disconnect bool   `json:"disconnect,omitempty"`
+}
+
+type Output struct {
+	Name     string  `json:"name"`
+	Enabled  *bool   `json:"enabled,omitempty"`
+	Mode     string  `json:"mode,omitempty"`
+	Position string  `json:"position,omitempty"`
+	Scale    float64 `json:"scale,omitempty"`
+}
+
+// When selects a profile for dms profile apply --auto
+type When struct {
+	Outputs []string `json:"outputs,omitempty"`
+	Docked  *bool    `json:"docked,omitempty"`
+}
+
+type Profile struct {
+	Name      string     `json:"-"`
+	Gamma     *Gamma     `json:"gamma,omitempty"`
+	Wallpaper *Wallpaper `json:"wallpaper,omitempty"`
+	Audio     *Audio     `json:"audio,omitempty"`
+	VPN       *VPN       `json:"vpn,omitempty"`
+	Outputs   []Output   `json:"outputs,omitempty"`
+	When      *When      `json:"when,omitempty"`
+}
+
+type Config struct {
+	Profiles map[string]*Profile `json:"profiles"`
+}
+
+func configHome() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.Getenv("HOME"), ".config")
+}
+
+func ConfigPath() string {
+	return filepath.Join(configHome(), "dms", "profiles.json")
+}
+
+func CurrentPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".local", "state", "DankMaterialShell", "profile")
+}
+
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no profiles defined (create %s)", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid profiles file %s: %w", path, err)
+	}
+	for name, p := range config.Profiles {
+		if p == nil {
+			return nil, fmt.Errorf("profile %s is empty", name)
+		}
+		p.Name = name
+	}
+	return &config, nil
+}
+
+func (c *Config) Names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *Config) Get(name string) (*Profile, error) {
+	p, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q (have: %s)", name, strings.Join(c.Names(), ", "))
+	}
+	return p, nil
+}
+
+// Match picks the profile whose conditions hold for the connected outputs,
+// preferring the one that names the most outputs
+func (c *Config) Match(connected []string) *Profile {
+	var best *Profile
+	bestScore := -1
+	for _, name := range c.Names() {
+		p := c.Profiles[name]
+		if p.When == nil || !p.When.matches(connected) {
+			continue
+		}
+		score := len(p.When.Outputs)
+		if p.When.Docked != nil {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = p, score
+		}
+	}
+	return best
+}
+
+func (w *When) matches(connected []string) bool {
+	have := make(map[string]bool, len(connected))
+	for _, name := range connected {
+		have[name] = true
+	}
+	for _, name := range w.Outputs {
+		if !have[name] {
+			return false
+		}
+	}
+	if w.Docked != nil && *w.Docked != Docked(connected) {
+		return false
+	}
+	return true
+}
+
+// Docked reports whether an external display is connected
+func Docked(connected []string) bool {
+	for _, name := range connected {
+		if !isInternalOutput(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func isInternalOutput(name string) bool {
+	for _, prefix := range []string{"eDP", "LVDS", "DSI"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+const drmDir = "/sys/class/drm"
+
+// ConnectedOutputs lists connectors with a display attached, using the
+// connector names compositors use (DP-1 rather than card1-DP-1)
+func ConnectedOutputs() []string {
+	return connectedOutputsAt(drmDir)
+}
+
+func connectedOutputsAt(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var outputs []string
+	for _, entry := range entries {
+		card, connector, ok := strings.Cut(entry.Name(), "-")
+		if !ok || !strings.HasPrefix(card, "card") {
+			continue
+		}
+		status, err := os.ReadFile(filepath.Join(dir, entry.Name(), "status"))
+		if err != nil || strings.TrimSpace(string(status)) != "connected" {
+			continue
+		}
+		outputs = append(outputs, connector)
+	}
+	sort.Strings(outputs)
+	return outputs
+}
+
+func ReadCurrent() string {
+	data, err := os.ReadFile(CurrentPath())
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func WriteCurrent(name string) error {
+	path := CurrentPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(path, []byte(name+"\n"), 0644)
+}
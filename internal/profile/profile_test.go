@@ -0,0 +1,111 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `{
+  "profiles": {
+    "home": {"when": {"docked": false}, "audio": {"sink": "speakers"}},
+    "work": {
+      "when": {"outputs": ["DP-1", "DP-2"]},
+      "vpn": {"connect": "office"},
+      "gamma": {"enabled": true, "low": 4000, "high": 6500},
+      "outputs": [{"name": "eDP-1", "enabled": false}, {"name": "DP-1", "mode": "2560x1440@144", "position": "0,0", "scale": 1.25}]
+    },
+    "presentation": {"when": {"docked": true}, "gamma": {"enabled": false}}
+  }
+}`
+
+func loadTestConfig(t *testing.T) *Config {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	require.NoError(t, os.WriteFile(path, []byte(testConfig), 0644))
+	config, err := Load(path)
+	require.NoError(t, err)
+	return config
+}
+
+func TestLoad(t *testing.T) {
+	config := loadTestConfig(t)
+	assert.Equal(t, []string{"home", "presentation", "work"}, config.Names())
+
+	p, err := config.Get("work")
+	require.NoError(t, err)
+	assert.Equal(t, "work", p.Name)
+
+	_, err = config.Get("gaming")
+	assert.Error(t, err)
+}
+
+func TestMatch(t *testing.T) {
+	config := loadTestConfig(t)
+
+	assert.Equal(t, "home", config.Match([]string{"eDP-1"}).Name)
+	assert.Equal(t, "presentation", config.Match([]string{"eDP-1", "HDMI-A-1"}).Name)
+	assert.Equal(t, "work", config.Match([]string{"eDP-1", "DP-1", "DP-2"}).Name)
+	assert.Nil(t, (&Config{}).Match([]string{"eDP-1"}))
+}
+
+func TestConnectedOutputs(t *testing.T) {
+	dir := t.TempDir()
+	for name, status := range map[string]string{
+		"card1-eDP-1":    "connected",
+		"card1-DP-1":     "disconnected",
+		"card1-HDMI-A-1": "connected",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, name), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name, "status"), []byte(status+"\n"), 0644))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "renderD128"), 0755))
+
+	assert.Equal(t, []string{"HDMI-A-1", "eDP-1"}, connectedOutputsAt(dir))
+}
+
+func TestApply(t *testing.T) {
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "test")
+	t.Setenv("NIRI_SOCKET", "")
+	config := loadTestConfig(t)
+	p, _ := config.Get("work")
+
+	var calls []string
+	var commands [][]string
+	applier := &Applier{
+		Call: func(method string, params map[string]interface{}) error {
+			calls = append(calls, method)
+			return nil
+		},
+		Run: func(name string, args ...string) error {
+			commands = append(commands, append([]string{name}, args...))
+			return nil
+		},
+	}
+
+	for _, step := range applier.Apply(p) {
+		assert.NoError(t, step.Err, step.Name)
+	}
+	assert.Equal(t, []string{"wayland.gamma.setTemperature", "wayland.gamma.setEnabled", "network.vpn.connect"}, calls)
+	assert.Equal(t, [][]string{
+		{"hyprctl", "keyword", "monitor", "eDP-1,disable"},
+		{"hyprctl", "keyword", "monitor", "DP-1,2560x1440@144,0x0,1.25"},
+	}, commands)
+}
+
+func TestNiriOutputCommands(t *testing.T) {
+	disabled := false
+	commands := niriOutputCommands([]Output{
+		{Name: "eDP-1", Enabled: &disabled},
+		{Name: "DP-1", Mode: "2560x1440@144", Position: "1920,0", Scale: 1.5},
+	})
+	assert.Equal(t, [][]string{
+		{"niri", "msg", "output", "eDP-1", "off"},
+		{"niri", "msg", "output", "DP-1", "on"},
+		{"niri", "msg", "output", "DP-1", "mode", "2560x1440@144"},
+		{"niri", "msg", "output", "DP-1", "position", "set", "1920", "0"},
+		{"niri", "msg", "output", "DP-1", "scale", "1.5"},
+	}, commands)
+}
@@ -0,0 +1,66 @@
+package report
+
+import (
+	"net"
+	"os"
+	"os/user"
+	"regexp"
+	"strings"
+)
+
+var (
+	secretPattern = regexp.MustCompile(`(?i)\b(password|passwd|passphrase|psk|token|secret|api[_-]?key)(\s*[=:]\s*)("[^"]*"|\S+)`)
+	ssidPattern   = regexp.MustCompile(`(?i)\b(ssid\s*[=:]\s*)("[^"]*"|\S+)`)
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	macPattern    = regexp.MustCompile(`\b(?:[0-9a-fA-F]{2}:){5}[0-9a-fA-F]{2}\b`)
+	ipv4Pattern   = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	ipv6Pattern   = regexp.MustCompile(`[0-9a-fA-F]{0,4}(?::[0-9a-fA-F]{0,4}){2,7}`)
+)
+
+// Redactor strips personal details from report text: secrets, SSIDs,
+// addresses, e-mails and the user's home, login and host names
+type Redactor struct {
+	Home     string
+	User     string
+	Hostname string
+}
+
+func DefaultRedactor() Redactor {
+	r := Redactor{Home: os.Getenv("HOME")}
+	if u, err := user.Current(); err == nil {
+		r.User = u.Username
+	}
+	r.Hostname, _ = os.Hostname()
+	return r
+}
+
+func (r Redactor) Redact(text string) string {
+	text = secretPattern.ReplaceAllString(text, "$1$2<redacted>")
+	text = ssidPattern.ReplaceAllString(text, "$1<ssid>")
+	text = emailPattern.ReplaceAllString(text, "<email>")
+	text = macPattern.ReplaceAllString(text, "<mac>")
+	text = ipv4Pattern.ReplaceAllStringFunc(text, func(match string) string {
+		if ip := net.ParseIP(match); ip == nil || ip.IsLoopback() || ip.IsUnspecified() {
+			return match
+		}
+		return "<ip>"
+	})
+	// The IPv6 pattern also matches clock times, so only replace real addresses
+	text = ipv6Pattern.ReplaceAllStringFunc(text, func(match string) string {
+		if ip := net.ParseIP(match); ip == nil || ip.IsLoopback() || ip.IsUnspecified() {
+			return match
+		}
+		return "<ip>"
+	})
+
+	if r.Home != "" && r.Home != "/" {
+		text = strings.ReplaceAll(text, r.Home, "~")
+	}
+	if len(r.User) >= 3 {
+		text = regexp.MustCompile(`\b`+regexp.QuoteMeta(r.User)+`\b`).ReplaceAllString(text, "<user>")
+	}
+	if len(r.Hostname) >= 3 && r.Hostname != "localhost" {
+		text = regexp.MustCompile(`\b`+regexp.QuoteMeta(r.Hostname)+`\b`).ReplaceAllString(text, "<host>")
+	}
+	return text
+}
@@ -0,0 +1,271 @@
+// Package report assembles a markdown bug report from local diagnostics. It
+// only reads the system; nothing is sent anywhere
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/config"
+	"github.com/AvengeMedia/danklinux/internal/crashes"
+	"github.com/AvengeMedia/danklinux/internal/distros"
+	"github.com/AvengeMedia/danklinux/internal/server"
+	"github.com/AvengeMedia/danklinux/internal/version"
+)
+
+const DefaultRepo = "AvengeMedia/DankMaterialShell"
+
+// GitHub rejects issue URLs much longer than this
+const maxIssueURLLength = 8000
+
+const (
+	crashWindow = 7 * 24 * time.Hour
+	maxCrashes  = 5
+)
+
+type Component struct {
+	Name    string
+	Version string
+}
+
+type Check struct {
+	Name   string
+	OK     bool
+	Detail string
+}
+
+type Report struct {
+	Components []Component
+	Checks     []Check
+	Crashes    []crashes.Crash
+	Logs       string
+	LogSource  string
+}
+
+// Collect gathers versions, runs the health checks and reads the last
+// logLines lines of shell logs, redacting everything with r
+func Collect(binaryVersion string, logLines int, r Redactor) *Report {
+	report := &Report{}
+
+	report.Components = append(report.Components, Component{"dms", binaryVersion})
+	shell, shellErr := version.GetCurrentDMSVersion()
+	report.Components = append(report.Components, Component{"DankMaterialShell", valueOr(shell, shellErr)})
+	quickshell, qsErr := version.GetQuickshellVersion()
+	report.Components = append(report.Components, Component{"quickshell", valueOr(quickshell, qsErr)})
+
+	compositor := detectCompositor()
+	if compositor != "" {
+		report.Components = append(report.Components, Component{compositor, compositorVersion(compositor)})
+	}
+	if osInfo, err := distros.GetOSInfo(); err == nil {
+		report.Components = append(report.Components, Component{"OS", osInfo.PrettyName})
+	}
+	if kernel, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		report.Components = append(report.Components, Component{"Kernel", strings.TrimSpace(string(kernel))})
+	}
+
+	report.Checks = runChecks(compositor, shell, quickshell)
+
+	if recent, err := crashes.Since(crashes.Dir(), time.Now().Add(-crashWindow)); err == nil {
+		if len(recent) > maxCrashes {
+			recent = recent[:maxCrashes]
+		}
+		report.Crashes = recent
+	}
+	for i := range report.Crashes {
+		report.Crashes[i].Reason = r.Redact(report.Crashes[i].Reason)
+		report.Crashes[i].Details = r.Redact(report.Crashes[i].Details)
+		report.Crashes[i].CoreDump = r.Redact(report.Crashes[i].CoreDump)
+	}
+
+	report.LogSource, report.Logs = ReadLogs(logLines)
+	report.Logs = r.Redact(report.Logs)
+	for i := range report.Checks {
+		report.Checks[i].Detail = r.Redact(report.Checks[i].Detail)
+	}
+
+	return report
+}
+
+func valueOr(value string, err error) string {
+	if err != nil || value == "" {
+		return "not found"
+	}
+	return value
+}
+
+func detectCompositor() string {
+	switch {
+	case os.Getenv("NIRI_SOCKET") != "":
+		return "niri"
+	case os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "":
+		return "Hyprland"
+	}
+	return ""
+}
+
+func compositorVersion(compositor string) string {
+	var cmd *exec.Cmd
+	if compositor == "niri" {
+		cmd = exec.Command("niri", "--version")
+	} else {
+		cmd = exec.Command("hyprctl", "version")
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return "unknown"
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return line
+}
+
+func runChecks(compositor, shell, quickshell string) []Check {
+	var checks []Check
+
+	if compositor == "" {
+		checks = append(checks, Check{"Compositor", false, "not running under niri or Hyprland"})
+	} else {
+		checks = append(checks, Check{"Compositor", true, compositor})
+	}
+
+	shellPath, err := config.LocateDMSConfig()
+	if err != nil {
+		checks = append(checks, Check{"Shell config", false, err.Error()})
+	} else {
+		checks = append(checks, Check{"Shell config", true, shellPath})
+	}
+
+	for _, command := range []string{"qs", "matugen", "dgop"} {
+		if path, err := exec.LookPath(command); err != nil {
+			checks = append(checks, Check{command, false, "not in PATH"})
+		} else {
+			checks = append(checks, Check{command, true, path})
+		}
+	}
+
+	apiVersion := 0
+	if result, err := server.Call("getServerInfo", nil); err != nil {
+		checks = append(checks, Check{"dms server", false, err.Error()})
+	} else {
+		var info server.ServerInfo
+		if err := json.Unmarshal(result, &info); err == nil {
+			apiVersion = info.APIVersion
+		}
+		checks = append(checks, Check{"dms server", true, fmt.Sprintf("API v%d, %s", info.APIVersion, strings.Join(info.Capabilities, ", "))})
+	}
+
+	if shellPath == "" {
+		return checks
+	}
+	req, ok, err := version.RequirementsIn(shellPath)
+	switch {
+	case err != nil:
+		checks = append(checks, Check{"Compatibility", false, err.Error()})
+		return checks
+	case !ok:
+		checks = append(checks, Check{"Compatibility", true, "shell declares no requirements"})
+		return checks
+	}
+
+	problems := version.CheckCompatibility(req, version.Components{Shell: shell, APIVersion: apiVersion, Quickshell: quickshell})
+	if len(problems) == 0 {
+		checks = append(checks, Check{"Compatibility", true, "components match"})
+	}
+	for _, problem := range problems {
+		checks = append(checks, Check{"Compatibility", false, problem.String()})
+	}
+
+	return checks
+}
+
+// ReadLogs returns the source and the last lines of the shell log. It
+// prefers quickshell's own log of the running shell and falls back to the
+// user journal
+func ReadLogs(lines int) (string, string) {
+	if lines <= 0 {
+		return "", ""
+	}
+
+	if configPath, err := config.LocateDMSConfig(); err == nil {
+		if output, err := exec.Command("qs", "log", "-p", configPath).Output(); err == nil && len(output) > 0 {
+			return "quickshell", lastLines(string(output), lines)
+		}
+	}
+
+	output, err := exec.Command("journalctl", "--user", "-b", "--no-pager", "-o", "short", "-n", fmt.Sprint(lines)).Output()
+	if err != nil {
+		return "", ""
+	}
+	return "journal", strings.TrimRight(string(output), "\n")
+}
+
+func lastLines(text string, n int) string {
+	all := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(all) > n {
+		all = all[len(all)-n:]
+	}
+	return strings.Join(all, "\n")
+}
+
+// Markdown renders the report for pasting into an issue
+func (r *Report) Markdown(includeLogs bool) string {
+	var b strings.Builder
+
+	b.WriteString("## Description\n\n<!-- What happened, and what did you expect? -->\n\n")
+	b.WriteString("## Steps to reproduce\n\n1. \n\n")
+
+	b.WriteString("## System\n\n| Component | Version |\n|---|---|\n")
+	for _, component := range r.Components {
+		fmt.Fprintf(&b, "| %s | %s |\n", component.Name, component.Version)
+	}
+
+	b.WriteString("\n## Checks\n\n")
+	for _, check := range r.Checks {
+		mark := "✅"
+		if !check.OK {
+			mark = "❌"
+		}
+		fmt.Fprintf(&b, "- %s **%s**: %s\n", mark, check.Name, check.Detail)
+	}
+
+	if len(r.Crashes) > 0 {
+		b.WriteString("\n## Recent crashes\n\n")
+		for _, crash := range r.Crashes {
+			component := crash.Component
+			if crash.Version != "" {
+				component += " " + crash.Version
+			}
+			fmt.Fprintf(&b, "- %s **%s**: %s", crash.Time.Format("2006-01-02 15:04"), component, crash.Reason)
+			if crash.Details != "" {
+				fmt.Fprintf(&b, " (attach `%s`)", crash.Details)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if includeLogs && r.Logs != "" {
+		fmt.Fprintf(&b, "\n## Logs (%s, redacted)\n\n<details>\n\n```\n%s\n```\n\n</details>\n", r.LogSource, r.Logs)
+	}
+
+	return b.String()
+}
+
+// IssueURL returns a prefilled new-issue URL for repo. Logs are left out when
+// they would make the URL too long for GitHub, and reported as omitted
+func (r *Report) IssueURL(repo, title string) (string, bool) {
+	build := func(body string) string {
+		query := url.Values{"title": {title}, "body": {body}}
+		return fmt.Sprintf("https://github.com/%s/issues/new?%s", repo, query.Encode())
+	}
+
+	issueURL := build(r.Markdown(true))
+	if len(issueURL) <= maxIssueURLLength || r.Logs == "" {
+		return issueURL, false
+	}
+	return build(r.Markdown(false) + "\n## Logs\n\n<!-- Too long for the link; paste them from the saved report -->\n"), true
+}
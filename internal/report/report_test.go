@@ -0,0 +1,92 @@
+package report
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/crashes"
+)
+
+func TestRedact(t *testing.T) {
+	r := Redactor{Home: "/home/alice", User: "alice", Hostname: "workstation"}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"home", "loading /home/alice/.config/niri/config.kdl", "loading ~/.config/niri/config.kdl"},
+		{"user", "session for alice opened", "session for <user> opened"},
+		{"hostname", "workstation kernel: usb 1-2", "<host> kernel: usb 1-2"},
+		{"password", "password=hunter2 next", "password=<redacted> next"},
+		{"quoted psk", `psk: "my secret" ok`, "psk: <redacted> ok"},
+		{"ssid", "Connection failed: SSID=HomeNet, state=120", "Connection failed: SSID=<ssid> state=120"},
+		{"email", "mail bob@example.com now", "mail <email> now"},
+		{"mac", "bssid aa:bb:cc:dd:ee:ff", "bssid <mac>"},
+		{"ipv4", "got 192.168.1.23 via dhcp", "got <ip> via dhcp"},
+		{"loopback kept", "listening on 127.0.0.1", "listening on 127.0.0.1"},
+		{"ipv6", "addr fe80::1c2:3ff:fe4a:5b6c/64", "addr <ip>/64"},
+		{"time kept", "Oct 16 12:34:56 started", "Oct 16 12:34:56 started"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Redact(tt.input); got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	if got := lastLines("a\nb\nc\nd\n", 2); got != "c\nd" {
+		t.Errorf("lastLines = %q", got)
+	}
+	if got := lastLines("a\nb", 5); got != "a\nb" {
+		t.Errorf("lastLines = %q", got)
+	}
+}
+
+func TestIssueURLDropsLongLogs(t *testing.T) {
+	r := &Report{
+		Components: []Component{{"dms", "v1.0.0"}},
+		Checks:     []Check{{"qs", false, "not in PATH"}},
+		Crashes: []crashes.Crash{{
+			Time:      time.Date(2025, 3, 4, 10, 30, 0, 0, time.UTC),
+			Component: crashes.ComponentQuickshell,
+			Version:   "v1.0.0",
+			Reason:    "killed by SIGSEGV (segmentation fault)",
+			Details:   "~/.local/state/dms/crashes/x.txt",
+		}},
+		Logs:      "short log",
+		LogSource: "journal",
+	}
+
+	issueURL, omitted := r.IssueURL(DefaultRepo, "Bar disappears")
+	if omitted {
+		t.Fatal("short logs should stay in the URL")
+	}
+	parsed, err := url.Parse(issueURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := parsed.Query().Get("body")
+	if !strings.Contains(body, "short log") || !strings.Contains(body, "| dms | v1.0.0 |") || !strings.Contains(body, "❌ **qs**") ||
+		!strings.Contains(body, "- 2025-03-04 10:30 **quickshell v1.0.0**: killed by SIGSEGV (segmentation fault) (attach `~/.local/state/dms/crashes/x.txt`)") {
+		t.Errorf("unexpected body:\n%s", body)
+	}
+	if parsed.Query().Get("title") != "Bar disappears" {
+		t.Errorf("title = %q", parsed.Query().Get("title"))
+	}
+
+	r.Logs = strings.Repeat("line of log output\n", 1000)
+	issueURL, omitted = r.IssueURL(DefaultRepo, "Bar disappears")
+	if !omitted {
+		t.Fatal("long logs should be omitted")
+	}
+	if len(issueURL) > maxIssueURLLength {
+		t.Errorf("URL length %d exceeds limit", len(issueURL))
+	}
+}
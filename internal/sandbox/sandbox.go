@@ -0,0 +1,366 @@
+// Package sandbox runs commands declared by plugins and hooks with a clean
+// environment, a timeout, optional network isolation and cgroup limits
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/secrets"
+)
+
+// Policy limits what a sandboxed command can do. Zero values fall back to
+// the defaults
+type Policy struct {
+	// Network allows network access; without it the command runs in an
+	// empty network namespace
+	Network bool `json:"network"`
+	// Timeout in seconds
+	Timeout int `json:"timeout,omitempty"`
+	// MemoryMax and CPUQuota use systemd's syntax, e.g. "256M" and "50%"
+	MemoryMax string `json:"memoryMax,omitempty"`
+	CPUQuota  string `json:"cpuQuota,omitempty"`
+	TasksMax  int    `json:"tasksMax,omitempty"`
+	// Env names variables passed through besides the base set
+	Env []string `json:"env,omitempty"`
+	// Secrets maps variables to dms secrets, e.g. an API key stored with
+	// `dms secret set weather.apiKey`, decrypted only for the command
+	Secrets map[string]string `json:"secrets,omitempty"`
+}
+
+const (
+	defaultTimeout   = 30
+	defaultMemoryMax = "256M"
+	defaultCPUQuota  = "50%"
+	defaultTasksMax  = 32
+)
+
+// baseEnv is what every sandboxed command gets
+var baseEnv = []string{"PATH", "HOME", "LANG", "LC_ALL", "TERM"}
+
+// wrapperEnv is what systemd-run needs to reach the user manager
+var wrapperEnv = []string{"PATH", "XDG_RUNTIME_DIR", "DBUS_SESSION_BUS_ADDRESS"}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		Timeout:   defaultTimeout,
+		MemoryMax: defaultMemoryMax,
+		CPUQuota:  defaultCPUQuota,
+		TasksMax:  defaultTasksMax,
+	}
+}
+
+func (p Policy) withDefaults() Policy {
+	if p.Timeout <= 0 {
+		p.Timeout = defaultTimeout
+	}
+	if p.MemoryMax == "" {
+		p.MemoryMax = defaultMemoryMax
+	}
+	if p.CPUQuota == "" {
+		p.CPUQuota = defaultCPUQuota
+	}
+	if p.TasksMax <= 0 {
+		p.TasksMax = defaultTasksMax
+	}
+	return p
+}
+
+func (p Policy) TimeoutDuration() time.Duration {
+	return time.Duration(p.withDefaults().Timeout) * time.Second
+}
+
+// PoliciesPath holds per-plugin policies keyed by plugin ID. Its directory
+// is mounted read-only for every sandboxed command, so a script can't grant
+// itself more on its next run
+func PoliciesPath() string {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		configDir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(configDir, "dms", "plugin-policy.json")
+}
+
+// PolicyFor reads id's policy from path, defaulting when the file or the
+// entry is missing
+func PolicyFor(path, id string) (Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DefaultPolicy(), nil
+		}
+		return Policy{}, err
+	}
+
+	var policies map[string]Policy
+	if err := json.Unmarshal(data, &policies); err != nil {
+		return Policy{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	policy, ok := policies[id]
+	if !ok {
+		return DefaultPolicy(), nil
+	}
+	return policy.withDefaults(), nil
+}
+
+// tools are the wrappers usable on this system
+type tools struct {
+	systemdRun bool
+	unshare    bool
+	timeout    bool
+}
+
+var (
+	unshareOnce   sync.Once
+	unshareUsable bool
+)
+
+// canUnshare checks once that unprivileged user namespaces work, since
+// AppArmor (Ubuntu 24.04) or a hardened kernel can forbid them even when
+// unshare is installed
+func canUnshare() bool {
+	unshareOnce.Do(func() {
+		unshareUsable = exec.Command("unshare", "--map-root-user", "--mount", "--net", "--", "true").Run() == nil
+	})
+	return unshareUsable
+}
+
+func detectTools() tools {
+	var t tools
+	if _, err := exec.LookPath("systemd-run"); err == nil {
+		// --user needs a reachable user manager
+		if runtime := os.Getenv("XDG_RUNTIME_DIR"); runtime != "" {
+			if _, err := os.Stat(filepath.Join(runtime, "systemd", "private")); err == nil {
+				t.systemdRun = true
+			}
+		}
+	}
+	if _, err := exec.LookPath("unshare"); err == nil {
+		t.unshare = canUnshare()
+	}
+	if _, err := exec.LookPath("timeout"); err == nil {
+		t.timeout = true
+	}
+	return t
+}
+
+func filterEnv(environ []string, names []string) []string {
+	allowed := make(map[string]bool, len(names))
+	for _, name := range names {
+		allowed[name] = true
+	}
+	var out []string
+	for _, kv := range environ {
+		if name, _, ok := strings.Cut(kv, "="); ok && allowed[name] {
+			out = append(out, kv)
+		}
+	}
+	return out
+}
+
+// ErrNoIsolation is returned on a system that can't isolate a command at
+// all, since it would run with the network, no limits and a writable policy
+var ErrNoIsolation = errors.New("no sandbox available: unprivileged user namespaces are disabled and there is no systemd user manager")
+
+// readOnlyScript bind-mounts each argument before "--" read-only, then
+// execs the rest
+const readOnlyScript = `while [ "$1" != -- ]; do mount --bind "$1" "$1" && mount -o remount,bind,ro "$1" "$1" || exit 125; shift; done; shift; exec "$@"`
+
+// argv wraps name and args, making readOnly unwritable. Isolation goes
+// through a user namespace when the system allows one, with systemd-run
+// adding cgroup limits around it; otherwise through a transient systemd
+// user service. Nothing runs when neither is available. env is what the
+// command gets; only the names end up in the arguments, so values like
+// secrets never show in a process list
+func argv(p Policy, t tools, dir string, readOnly, env []string, name string, args ...string) ([]string, error) {
+	var out []string
+	switch {
+	case t.unshare:
+		if t.systemdRun {
+			out = append(out, "systemd-run", "--user", "--scope", "--quiet", "--collect")
+			out = append(out, limitProperties(p)...)
+			out = append(out, "--")
+		}
+		out = append(out, "unshare", "--map-root-user", "--mount")
+		if !p.Network {
+			out = append(out, "--net")
+		}
+		out = append(out, "--", "sh", "-c", readOnlyScript, "sh")
+		out = append(out, readOnly...)
+		out = append(out, "--")
+	case t.systemdRun:
+		out = append(out, "systemd-run", "--user", "--pipe", "--wait", "--quiet", "--collect", "--working-directory="+dir)
+		out = append(out, limitProperties(p)...)
+		for _, path := range readOnly {
+			out = append(out, "-p", "ReadOnlyPaths="+path)
+		}
+		if !p.Network {
+			out = append(out, "-p", "PrivateNetwork=yes", "-p", "IPAddressDeny=any")
+		}
+		// A service starts from the user manager's environment, so the
+		// command's variables are copied over by name
+		for _, name := range envNames(env) {
+			out = append(out, "-E", name)
+		}
+		out = append(out, "--")
+	default:
+		return nil, ErrNoIsolation
+	}
+	if t.timeout {
+		out = append(out, "timeout", "-k", "2", strconv.Itoa(p.Timeout))
+	}
+	out = append(out, "env")
+	for _, name := range wrapperOnly(env) {
+		out = append(out, "-u", name)
+	}
+	out = append(out, name)
+	return append(out, args...), nil
+}
+
+func envNames(env []string) []string {
+	names := make([]string, 0, len(env))
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		names = append(names, name)
+	}
+	return names
+}
+
+// wrapperOnly lists the wrapperEnv variables the command itself shouldn't see
+func wrapperOnly(env []string) []string {
+	kept := make(map[string]bool, len(env))
+	for _, name := range envNames(env) {
+		kept[name] = true
+	}
+	var out []string
+	for _, name := range wrapperEnv {
+		if !kept[name] {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// unenforced lists what of p argv can't apply with t. A user namespace
+// alone has no cgroup to hold the limits
+func unenforced(p Policy, t tools) []string {
+	if !t.unshare || t.systemdRun {
+		return nil
+	}
+	return []string{"MemoryMax=" + p.MemoryMax, "CPUQuota=" + p.CPUQuota, "TasksMax=" + strconv.Itoa(p.TasksMax)}
+}
+
+func limitProperties(p Policy) []string {
+	return []string{
+		"-p", "MemoryMax=" + p.MemoryMax,
+		"-p", "CPUQuota=" + p.CPUQuota,
+		"-p", "TasksMax=" + strconv.Itoa(p.TasksMax),
+	}
+}
+
+// Command builds the sandboxed command. ctx should carry the policy's
+// timeout as a backstop for when the timeout utility is missing
+func Command(ctx context.Context, p Policy, dir, name string, args ...string) (*exec.Cmd, error) {
+	p = p.withDefaults()
+	environ := os.Environ()
+
+	// The policy directory has to exist to be mounted over; otherwise the
+	// command could create it
+	policyDir := filepath.Dir(PoliciesPath())
+	if err := os.MkdirAll(policyDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", policyDir, err)
+	}
+
+	env := filterEnv(environ, append(append([]string{}, baseEnv...), p.Env...))
+	secretEnv, err := resolveSecrets(secrets.Open(secrets.Path()), p.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	env = append(env, secretEnv...)
+	t := detectTools()
+	full, err := argv(p, t, dir, []string{policyDir}, env, name, args...)
+	if err != nil {
+		return nil, err
+	}
+	if limits := unenforced(p, t); len(limits) > 0 {
+		log.Warnf("Running %s without %s: no systemd user manager to enforce them", name, strings.Join(limits, ", "))
+	}
+
+	cmd := exec.CommandContext(ctx, full[0], full[1:]...)
+	cmd.Dir = dir
+	cmd.Env = append(env, filterEnv(environ, wrapperOnly(env))...)
+	return cmd, nil
+}
+
+// resolveSecrets turns a policy's secrets into NAME=value pairs, sorted so
+// the command line is stable
+func resolveSecrets(store interface{ Get(string) (string, error) }, wanted map[string]string) ([]string, error) {
+	names := make([]string, 0, len(wanted))
+	for name := range wanted {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	env := make([]string, 0, len(names))
+	for _, name := range names {
+		value, err := store.Get(wanted[name])
+		if errors.Is(err, secrets.ErrNotFound) {
+			return nil, fmt.Errorf("secret %s for %s is not set; add it with 'dms secret set %s'", wanted[name], name, wanted[name])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secret %s: %w", wanted[name], err)
+		}
+		env = append(env, name+"="+value)
+	}
+	return env, nil
+}
+
+// Run runs the command under p and returns its output
+func Run(p Policy, dir, name string, args ...string) (stdout, stderr []byte, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.TimeoutDuration()+5*time.Second)
+	defer cancel()
+
+	cmd, err := Command(ctx, p, dir, name, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var outBuf, errBuf limitedBuffer
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+	err = cmd.Run()
+	return outBuf.Bytes(), errBuf.Bytes(), err
+}
+
+// outputLimit caps what is kept of a command's output
+const outputLimit = 64 * 1024
+
+type limitedBuffer struct {
+	buf []byte
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := outputLimit - len(b.buf); room > 0 {
+		if len(p) > room {
+			b.buf = append(b.buf, p[:room]...)
+		} else {
+			b.buf = append(b.buf, p...)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf
+}
@@ -0,0 +1,181 @@
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AvengeMedia/danklinux/internal/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArgv(t *testing.T) {
+	p := DefaultPolicy()
+	env := []string{"PATH=/usr/bin"}
+	readOnly := []string{"/home/a/.config/dms"}
+
+	t.Run("all wrappers", func(t *testing.T) {
+		args, err := argv(p, tools{systemdRun: true, unshare: true, timeout: true}, "/plugin", readOnly, env, "/bin/script", "a")
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"systemd-run", "--user", "--scope", "--quiet", "--collect",
+			"-p", "MemoryMax=256M", "-p", "CPUQuota=50%", "-p", "TasksMax=32", "--",
+			"unshare", "--map-root-user", "--mount", "--net", "--",
+			"sh", "-c", readOnlyScript, "sh", "/home/a/.config/dms", "--",
+			"timeout", "-k", "2", "30",
+			"env", "-u", "XDG_RUNTIME_DIR", "-u", "DBUS_SESSION_BUS_ADDRESS", "/bin/script", "a",
+		}, args)
+	})
+
+	t.Run("network allowed keeps the read-only mounts", func(t *testing.T) {
+		p := p
+		p.Network = true
+		args, err := argv(p, tools{unshare: true}, "/plugin", readOnly, env, "/bin/script")
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"unshare", "--map-root-user", "--mount", "--",
+			"sh", "-c", readOnlyScript, "sh", "/home/a/.config/dms", "--",
+			"env", "-u", "XDG_RUNTIME_DIR", "-u", "DBUS_SESSION_BUS_ADDRESS", "/bin/script",
+		}, args)
+	})
+
+	t.Run("restricted user namespaces fall back to a systemd service", func(t *testing.T) {
+		args, err := argv(p, tools{systemdRun: true}, "/plugin", readOnly, env, "/bin/script")
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"systemd-run", "--user", "--pipe", "--wait", "--quiet", "--collect", "--working-directory=/plugin",
+			"-p", "MemoryMax=256M", "-p", "CPUQuota=50%", "-p", "TasksMax=32",
+			"-p", "ReadOnlyPaths=/home/a/.config/dms",
+			"-p", "PrivateNetwork=yes", "-p", "IPAddressDeny=any",
+			"-E", "PATH", "--",
+			"env", "-u", "XDG_RUNTIME_DIR", "-u", "DBUS_SESSION_BUS_ADDRESS", "/bin/script",
+		}, args)
+	})
+
+	t.Run("values stay out of the arguments", func(t *testing.T) {
+		p := p
+		p.Network = true
+		args, err := argv(p, tools{unshare: true}, "/plugin", nil, []string{"PATH=/usr/bin", "XDG_RUNTIME_DIR=/run/user/1000", "API_KEY=hunter2"}, "/bin/script")
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"unshare", "--map-root-user", "--mount", "--",
+			"sh", "-c", readOnlyScript, "sh", "--",
+			"env", "-u", "DBUS_SESSION_BUS_ADDRESS", "/bin/script",
+		}, args)
+	})
+
+	t.Run("nothing runs without any isolation", func(t *testing.T) {
+		_, err := argv(p, tools{timeout: true}, "/plugin", readOnly, env, "/bin/script")
+		assert.ErrorIs(t, err, ErrNoIsolation)
+
+		p := p
+		p.Network = true
+		_, err = argv(p, tools{timeout: true}, "/plugin", readOnly, env, "/bin/script")
+		assert.ErrorIs(t, err, ErrNoIsolation, "the policy would still be writable and nothing limited")
+	})
+}
+
+func TestUnenforced(t *testing.T) {
+	p := DefaultPolicy()
+	assert.Empty(t, unenforced(p, tools{systemdRun: true, unshare: true}))
+	assert.Empty(t, unenforced(p, tools{systemdRun: true}))
+	assert.Equal(t, []string{"MemoryMax=256M", "CPUQuota=50%", "TasksMax=32"}, unenforced(p, tools{unshare: true}),
+		"a user namespace alone can't hold the limits")
+}
+
+func TestPolicyIsReadOnlyInSandbox(t *testing.T) {
+	if !canUnshare() {
+		t.Skip("unprivileged user namespaces unavailable")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := PoliciesPath()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(`{}`), 0644))
+
+	grant := `echo '{"evil": {"network": true}}' > "$1" || mv "$1" "$1.old"`
+	_, _, err := Run(DefaultPolicy(), t.TempDir(), "/bin/sh", "-c", grant, "sh", path)
+	assert.Error(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, string(data), "a sandboxed script must not rewrite its policy")
+
+	_, _, err = Run(DefaultPolicy(), t.TempDir(), "/bin/sh", "-c", `echo '{}' > "$1/plugin-policy.json.new"`, "sh", filepath.Dir(path))
+	assert.Error(t, err, "nor create files next to it")
+}
+
+type mapSecrets map[string]string
+
+func (m mapSecrets) Get(name string) (string, error) {
+	value, ok := m[name]
+	if !ok {
+		return "", secrets.ErrNotFound
+	}
+	return value, nil
+}
+
+func TestResolveSecrets(t *testing.T) {
+	store := mapSecrets{"weather.apiKey": "abc123", "weather.user": "me"}
+
+	env, err := resolveSecrets(store, map[string]string{"WEATHER_USER": "weather.user", "WEATHER_KEY": "weather.apiKey"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"WEATHER_KEY=abc123", "WEATHER_USER=me"}, env)
+
+	_, err = resolveSecrets(store, map[string]string{"TOKEN": "missing"})
+	assert.ErrorContains(t, err, "dms secret set missing")
+}
+
+func TestFilterEnv(t *testing.T) {
+	environ := []string{"PATH=/usr/bin", "HOME=/home/a", "SECRET_TOKEN=x", "LANG=C=weird"}
+	assert.Equal(t, []string{"PATH=/usr/bin", "LANG=C=weird"}, filterEnv(environ, []string{"PATH", "LANG"}))
+}
+
+func TestPolicyFor(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plugin-policy.json")
+
+	p, err := PolicyFor(path, "weather")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPolicy(), p)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"weather": {"network": true, "timeout": 5, "env": ["WEATHER_LANG"], "secrets": {"WEATHER_KEY": "weather.apiKey"}}}`), 0644))
+
+	p, err = PolicyFor(path, "weather")
+	require.NoError(t, err)
+	assert.True(t, p.Network)
+	assert.Equal(t, 5, p.Timeout)
+	assert.Equal(t, "256M", p.MemoryMax)
+	assert.Equal(t, []string{"WEATHER_LANG"}, p.Env)
+	assert.Equal(t, map[string]string{"WEATHER_KEY": "weather.apiKey"}, p.Secrets)
+
+	p, err = PolicyFor(path, "other")
+	require.NoError(t, err)
+	assert.False(t, p.Network)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{`), 0644))
+	_, err = PolicyFor(path, "weather")
+	assert.Error(t, err)
+}
+
+func TestRunCleansEnvironment(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("SANDBOX_TEST_SECRET", "leaked")
+	t.Setenv("SANDBOX_TEST_ALLOWED", "kept")
+
+	p := Policy{Network: true, Timeout: 5, Env: []string{"SANDBOX_TEST_ALLOWED"}}
+	stdout, _, err := Run(p, t.TempDir(), "/bin/sh", "-c", `echo "$SANDBOX_TEST_SECRET|$SANDBOX_TEST_ALLOWED"`)
+	require.NoError(t, err)
+	assert.Equal(t, "|kept\n", string(stdout))
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	var b limitedBuffer
+	chunk := make([]byte, outputLimit-10)
+	n, err := b.Write(chunk)
+	require.NoError(t, err)
+	assert.Equal(t, len(chunk), n)
+	n, _ = b.Write(make([]byte, 100))
+	assert.Equal(t, 100, n)
+	assert.Len(t, b.Bytes(), outputLimit)
+}
@@ -0,0 +1,213 @@
+package secrets
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	secretsBusName       = "org.freedesktop.secrets"
+	secretsPath          = "/org/freedesktop/secrets"
+	secretsDefaultPath   = "/org/freedesktop/secrets/aliases/default"
+	secretsService       = "org.freedesktop.Secret.Service"
+	secretsCollection    = "org.freedesktop.Secret.Collection"
+	secretsItem          = "org.freedesktop.Secret.Item"
+	secretsPrompt        = "org.freedesktop.Secret.Prompt"
+	secretsPromptTimeout = 2 * time.Minute
+)
+
+// secretValue is the Secret Service's Secret struct, (oayays)
+type secretValue struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+// KeyringAvailable reports whether a Secret Service, e.g. GNOME Keyring or
+// KeePassXC, runs or can be started on the session bus
+func KeyringAvailable() bool {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+
+	var names []string
+	if err := conn.BusObject().Call("org.freedesktop.DBus.ListActivatableNames", 0).Store(&names); err == nil {
+		for _, name := range names {
+			if name == secretsBusName {
+				return true
+			}
+		}
+	}
+	var owned bool
+	err = conn.BusObject().Call("org.freedesktop.DBus.NameHasOwner", 0, secretsBusName).Store(&owned)
+	return err == nil && owned
+}
+
+type keyringSession struct {
+	conn    *dbus.Conn
+	service dbus.BusObject
+	path    dbus.ObjectPath
+}
+
+func openKeyring() (*keyringSession, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to session bus: %w", err)
+	}
+
+	s := &keyringSession{conn: conn, service: conn.Object(secretsBusName, secretsPath)}
+	var output dbus.Variant
+	if err := s.service.Call(secretsService+".OpenSession", 0, "plain", dbus.MakeVariant("")).Store(&output, &s.path); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open keyring session: %w", err)
+	}
+	return s, nil
+}
+
+func (s *keyringSession) close() {
+	s.conn.Object(secretsBusName, s.path).Call("org.freedesktop.Secret.Session.Close", 0)
+	s.conn.Close()
+}
+
+// search returns the unlocked items matching attributes, unlocking the first
+// locked one when none is
+func (s *keyringSession) search(attributes map[string]string) ([]dbus.ObjectPath, error) {
+	var unlocked, locked []dbus.ObjectPath
+	if err := s.service.Call(secretsService+".SearchItems", 0, attributes).Store(&unlocked, &locked); err != nil {
+		return nil, fmt.Errorf("failed to search keyring: %w", err)
+	}
+	if len(unlocked) > 0 || len(locked) == 0 {
+		return unlocked, nil
+	}
+
+	var prompt dbus.ObjectPath
+	if err := s.service.Call(secretsService+".Unlock", 0, locked[:1]).Store(&unlocked, &prompt); err != nil {
+		return nil, fmt.Errorf("failed to unlock keyring: %w", err)
+	}
+	if prompt != "/" {
+		if err := runPrompt(s.conn, prompt); err != nil {
+			return nil, err
+		}
+		unlocked = locked[:1]
+	}
+	return unlocked, nil
+}
+
+// KeyringLookup returns the secret of an item matching attributes, or
+// ErrNotFound
+func KeyringLookup(attributes map[string]string) ([]byte, error) {
+	s, err := openKeyring()
+	if err != nil {
+		return nil, err
+	}
+	defer s.close()
+
+	items, err := s.search(attributes)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrNotFound
+	}
+
+	var secret secretValue
+	if err := s.conn.Object(secretsBusName, items[0]).Call(secretsItem+".GetSecret", 0, s.path).Store(&secret); err != nil {
+		return nil, fmt.Errorf("failed to read secret from keyring: %w", err)
+	}
+	return secret.Value, nil
+}
+
+// KeyringStore saves value in the default collection, replacing the item
+// with the same attributes
+func KeyringStore(label string, attributes map[string]string, value []byte) error {
+	s, err := openKeyring()
+	if err != nil {
+		return err
+	}
+	defer s.close()
+
+	props := map[string]dbus.Variant{
+		secretsItem + ".Label":      dbus.MakeVariant(label),
+		secretsItem + ".Attributes": dbus.MakeVariant(attributes),
+	}
+	secret := secretValue{
+		Session:     s.path,
+		Value:       value,
+		ContentType: "text/plain",
+	}
+
+	var item, prompt dbus.ObjectPath
+	if err := s.conn.Object(secretsBusName, secretsDefaultPath).Call(secretsCollection+".CreateItem", 0, props, secret, true).Store(&item, &prompt); err != nil {
+		return fmt.Errorf("failed to store secret in keyring: %w", err)
+	}
+	if prompt != "/" {
+		return runPrompt(s.conn, prompt)
+	}
+	return nil
+}
+
+// KeyringDelete removes every item matching attributes
+func KeyringDelete(attributes map[string]string) error {
+	s, err := openKeyring()
+	if err != nil {
+		return err
+	}
+	defer s.close()
+
+	var unlocked, locked []dbus.ObjectPath
+	if err := s.service.Call(secretsService+".SearchItems", 0, attributes).Store(&unlocked, &locked); err != nil {
+		return fmt.Errorf("failed to search keyring: %w", err)
+	}
+	for _, item := range append(unlocked, locked...) {
+		var prompt dbus.ObjectPath
+		if err := s.conn.Object(secretsBusName, item).Call(secretsItem+".Delete", 0).Store(&prompt); err != nil {
+			return fmt.Errorf("failed to delete secret from keyring: %w", err)
+		}
+		if prompt != "/" {
+			if err := runPrompt(s.conn, prompt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// runPrompt shows a keyring prompt, e.g. for the keyring password, and waits
+// for the user to answer it
+func runPrompt(conn *dbus.Conn, prompt dbus.ObjectPath) error {
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(prompt),
+		dbus.WithMatchInterface(secretsPrompt),
+		dbus.WithMatchMember("Completed"),
+	); err != nil {
+		return err
+	}
+	signals := make(chan *dbus.Signal, 1)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	if err := conn.Object(secretsBusName, prompt).Call(secretsPrompt+".Prompt", 0, "").Err; err != nil {
+		return fmt.Errorf("failed to show keyring prompt: %w", err)
+	}
+
+	timeout := time.After(secretsPromptTimeout)
+	for {
+		select {
+		case sig := <-signals:
+			if sig.Path != prompt || len(sig.Body) == 0 {
+				continue
+			}
+			if dismissed, _ := sig.Body[0].(bool); dismissed {
+				return fmt.Errorf("keyring prompt was dismissed")
+			}
+			return nil
+		case <-timeout:
+			return fmt.Errorf("timed out waiting for the keyring prompt")
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package secrets
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const keySize = 32
+
+var errNoKey = errors.New("no key has been created yet")
+
+// KeyFilePath holds the machine key used when there is no keyring
+func KeyFilePath() string {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		dataDir = filepath.Join(os.Getenv("HOME"), ".local", "share")
+	}
+	return filepath.Join(dataDir, "dms", "secrets.key")
+}
+
+type fileKey struct {
+	path string
+}
+
+func (k fileKey) name() string { return "file" }
+
+func (k fileKey) available() bool { return true }
+
+func (k fileKey) key(create bool) ([]byte, error) {
+	info, err := os.Stat(k.path)
+	switch {
+	case os.IsNotExist(err):
+		if !create {
+			return nil, errNoKey
+		}
+		return k.create()
+	case err != nil:
+		return nil, err
+	case info.Mode().Perm()&0077 != 0:
+		return nil, fmt.Errorf("%s is accessible by other users, run chmod 600 on it", k.path)
+	}
+
+	data, err := os.ReadFile(k.path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != keySize {
+		return nil, fmt.Errorf("%s is not a valid key", k.path)
+	}
+	return data, nil
+}
+
+func (k fileKey) create() ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(k.path), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(k.path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(key); err != nil {
+		f.Close()
+		os.Remove(k.path)
+		return nil, err
+	}
+	return key, f.Close()
+}
+
+var keyringAttributes = map[string]string{
+	"application": "dms",
+	"purpose":     "secrets-key",
+}
+
+// keyringKey keeps the key in the user's keyring through the Secret Service
+// API, which GNOME Keyring and KeePassXC implement
+type keyringKey struct{}
+
+func (k keyringKey) name() string { return "keyring" }
+
+func (k keyringKey) available() bool { return KeyringAvailable() }
+
+func (k keyringKey) key(create bool) ([]byte, error) {
+	value, err := KeyringLookup(keyringAttributes)
+	switch {
+	case err == nil:
+		key, err := base64.StdEncoding.DecodeString(string(value))
+		if err != nil || len(key) != keySize {
+			return nil, fmt.Errorf("the dms key in the keyring is not valid")
+		}
+		return key, nil
+	case !errors.Is(err, ErrNotFound):
+		return nil, err
+	case !create:
+		return nil, errNoKey
+	}
+
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := KeyringStore("DMS secrets key", keyringAttributes, []byte(base64.StdEncoding.EncodeToString(key))); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,227 @@
+// Package secrets keeps values the daemon has to store itself, like API
+// keys and tokens, encrypted at rest. The encryption key lives in the user's
+// keyring, or in a key file readable only by the user when there is none
+package secrets
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/AvengeMedia/danklinux/internal/utils"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+var ErrNotFound = errors.New("secret not found")
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+const fileVersion = 1
+
+// keySource provides the 32-byte key secrets are sealed with
+type keySource interface {
+	name() string
+	available() bool
+	// key returns the key, creating it when create is set and there is none
+	key(create bool) ([]byte, error)
+}
+
+type secretsFile struct {
+	Version int `json:"version"`
+	// Key names the keySource the secrets were sealed with
+	Key     string            `json:"key"`
+	Secrets map[string]string `json:"secrets"`
+}
+
+type Store struct {
+	path    string
+	sources []keySource
+}
+
+func Path() string {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		configDir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(configDir, "dms", "secrets.json")
+}
+
+// Open returns the store at path, preferring the keyring for new stores
+func Open(path string) *Store {
+	return &Store{
+		path:    path,
+		sources: []keySource{keyringKey{}, fileKey{path: KeyFilePath()}},
+	}
+}
+
+func (s *Store) Get(name string) (string, error) {
+	f, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	sealed, ok := f.Secrets[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	src, err := s.source(f.Key)
+	if err != nil {
+		return "", err
+	}
+	key, err := src.key(false)
+	if err != nil {
+		return "", fmt.Errorf("failed to get key from %s: %w", src.name(), err)
+	}
+	return open(key, name, sealed)
+}
+
+func (s *Store) Set(name, value string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+
+	f, err := s.load()
+	if err != nil {
+		return err
+	}
+	if f.Key == "" {
+		f.Key = s.preferred().name()
+	}
+
+	src, err := s.source(f.Key)
+	if err != nil {
+		return err
+	}
+	key, err := src.key(true)
+	if err != nil {
+		return fmt.Errorf("failed to get key from %s: %w", src.name(), err)
+	}
+	sealed, err := seal(key, name, value)
+	if err != nil {
+		return err
+	}
+
+	f.Secrets[name] = sealed
+	return s.save(f)
+}
+
+func (s *Store) Remove(name string) error {
+	f, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := f.Secrets[name]; !ok {
+		return ErrNotFound
+	}
+	delete(f.Secrets, name)
+	return s.save(f)
+}
+
+func (s *Store) Names() ([]string, error) {
+	f, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(f.Secrets))
+	for name := range f.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// KeySource names where the store's key is kept, empty for a new store
+func (s *Store) KeySource() (string, error) {
+	f, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	return f.Key, nil
+}
+
+func (s *Store) preferred() keySource {
+	for _, src := range s.sources {
+		if src.available() {
+			return src
+		}
+	}
+	return s.sources[len(s.sources)-1]
+}
+
+func (s *Store) source(name string) (keySource, error) {
+	for _, src := range s.sources {
+		if src.name() == name {
+			return src, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key source %q in %s", name, s.path)
+}
+
+func (s *Store) load() (*secretsFile, error) {
+	f := &secretsFile{Version: fileVersion, Secrets: map[string]string{}}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return f, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	if f.Version > fileVersion {
+		return nil, fmt.Errorf("%s was written by a newer dms (version %d)", s.path, f.Version)
+	}
+	if f.Secrets == nil {
+		f.Secrets = map[string]string{}
+	}
+	return f, nil
+}
+
+func (s *Store) save(f *secretsFile) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(s.path, data, 0600)
+}
+
+// seal encrypts value with the secret's name as associated data, so a sealed
+// value can't be moved to another name
+func seal(key []byte, name, value string) (string, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(value), []byte(name))), nil
+}
+
+func open(key []byte, name, sealed string) (string, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < aead.NonceSize() {
+		return "", fmt.Errorf("secret %s is corrupt", name)
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("secret %s can't be decrypted with the current key", name)
+	}
+	return string(plain), nil
+}
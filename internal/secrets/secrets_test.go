@@ -0,0 +1,143 @@
+package secrets
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memKey struct {
+	id    string
+	avail bool
+	k     []byte
+}
+
+func (m *memKey) name() string { return m.id }
+
+func (m *memKey) available() bool { return m.avail }
+
+func (m *memKey) key(create bool) ([]byte, error) {
+	if m.k == nil {
+		if !create {
+			return nil, errNoKey
+		}
+		m.k = make([]byte, keySize)
+		for i := range m.k {
+			m.k[i] = byte(i)
+		}
+	}
+	return m.k, nil
+}
+
+func testStore(t *testing.T, sources ...keySource) *Store {
+	return &Store{path: filepath.Join(t.TempDir(), "dms", "secrets.json"), sources: sources}
+}
+
+func TestStore_RoundTrip(t *testing.T) {
+	s := testStore(t, &memKey{id: "keyring", avail: true})
+
+	require.NoError(t, s.Set("weather.apiKey", "abc123"))
+	value, err := s.Get("weather.apiKey")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", value)
+
+	names, err := s.Names()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"weather.apiKey"}, names)
+
+	info, err := os.Stat(s.path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	data, err := os.ReadFile(s.path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "abc123")
+}
+
+func TestStore_PrefersAvailableSource(t *testing.T) {
+	keyring := &memKey{id: "keyring"}
+	file := &memKey{id: "file", avail: true}
+	s := testStore(t, keyring, file)
+
+	require.NoError(t, s.Set("token", "x"))
+	source, err := s.KeySource()
+	require.NoError(t, err)
+	assert.Equal(t, "file", source)
+	assert.Nil(t, keyring.k)
+
+	// Once chosen the source sticks, even if the keyring shows up later
+	keyring.avail = true
+	require.NoError(t, s.Set("other", "y"))
+	assert.Nil(t, keyring.k)
+}
+
+func TestStore_GetMissing(t *testing.T) {
+	s := testStore(t, &memKey{id: "file", avail: true})
+
+	_, err := s.Get("nope")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, s.Remove("nope"), ErrNotFound)
+}
+
+func TestStore_Remove(t *testing.T) {
+	s := testStore(t, &memKey{id: "file", avail: true})
+
+	require.NoError(t, s.Set("a", "1"))
+	require.NoError(t, s.Set("b", "2"))
+	require.NoError(t, s.Remove("a"))
+
+	names, err := s.Names()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, names)
+}
+
+func TestStore_InvalidName(t *testing.T) {
+	s := testStore(t, &memKey{id: "file", avail: true})
+
+	for _, name := range []string{"", "has space", "../x", ".hidden"} {
+		assert.Error(t, s.Set(name, "v"), name)
+	}
+}
+
+func TestStore_ValueBoundToName(t *testing.T) {
+	s := testStore(t, &memKey{id: "file", avail: true})
+	require.NoError(t, s.Set("a", "secret"))
+
+	data, err := os.ReadFile(s.path)
+	require.NoError(t, err)
+	var f secretsFile
+	require.NoError(t, json.Unmarshal(data, &f))
+	f.Secrets["b"] = f.Secrets["a"]
+	require.NoError(t, s.save(&f))
+
+	_, err = s.Get("b")
+	assert.Error(t, err)
+}
+
+func TestFileKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dms", "secrets.key")
+	k := fileKey{path: path}
+
+	_, err := k.key(false)
+	assert.ErrorIs(t, err, errNoKey)
+
+	created, err := k.key(true)
+	require.NoError(t, err)
+	assert.Len(t, created, keySize)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	again, err := k.key(false)
+	require.NoError(t, err)
+	assert.Equal(t, created, again)
+
+	require.NoError(t, os.Chmod(path, 0644))
+	_, err = k.key(false)
+	assert.Error(t, err)
+}
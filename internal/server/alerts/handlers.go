@@ -0,0 +1,87 @@
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "alerts manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "alerts.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "alerts.setConfig":
+		handleSetConfig(conn, req, manager)
+	case "alerts.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleSetConfig(conn net.Conn, req Request, manager *Manager) {
+	config := manager.GetConfig()
+	if enabled, ok := req.Params["enabled"].(bool); ok {
+		config.Enabled = enabled
+	}
+	if action, ok := req.Params["batteryAction"].(string); ok {
+		config.BatteryAction = Action(action)
+	}
+	if path, ok := req.Params["diskPath"].(string); ok {
+		config.DiskPath = path
+	}
+	ints := map[string]*int{
+		"batteryLow":      &config.BatteryLow,
+		"batteryCritical": &config.BatteryCritical,
+		"batteryActionAt": &config.BatteryActionAt,
+		"diskLow":         &config.DiskLow,
+		"diskCritical":    &config.DiskCritical,
+	}
+	for key, dst := range ints {
+		if v, ok := req.Params[key].(float64); ok {
+			*dst = int(v)
+		}
+	}
+
+	if err := manager.SetConfig(config); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, manager.GetState())
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package alerts
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+const (
+	defaultPollInterval = 30 * time.Second
+	// The hysteresis keeps an alert from repeating when the charge or free
+	// space hovers around a threshold
+	batteryHysteresis = 2
+	diskHysteresis    = 256 * 1024 * 1024
+
+	keyBattery = "battery"
+	keyDisk    = "disk"
+)
+
+func NewManager() (*Manager, error) {
+	notifier, err := newDBusNotifier()
+	if err != nil {
+		return nil, fmt.Errorf("notifications not available: %w", err)
+	}
+
+	m := newManager(notifier, commandExecutor{}, readBattery, freeSpace)
+	m.start()
+	return m, nil
+}
+
+func newManager(notifier Notifier, executor Executor, battery func() BatteryReading, free func(string) (uint64, error)) *Manager {
+	config := DefaultConfig()
+	return &Manager{
+		config:       config,
+		state:        &State{Config: config},
+		notifier:     notifier,
+		executor:     executor,
+		battery:      battery,
+		freeSpace:    free,
+		batteryLevel: LevelOK,
+		diskLevel:    LevelOK,
+		pollInterval: defaultPollInterval,
+		subscribers:  make(map[string]chan State),
+		dirty:        make(chan struct{}, 1),
+		stopChan:     make(chan struct{}),
+	}
+}
+
+func (m *Manager) start() {
+	m.wg.Add(2)
+	go m.notifierLoop()
+	go m.loop()
+}
+
+func (m *Manager) loop() {
+	defer m.wg.Done()
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	m.check()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			m.check()
+		}
+	}
+}
+
+// batteryLevelFor is the alert level for percent, only easing off once the
+// charge is batteryHysteresis above the threshold that raised it
+func batteryLevelFor(config Config, percent int, current Level) Level {
+	critical := config.BatteryCritical
+	low := config.BatteryLow
+	switch current {
+	case LevelCritical:
+		critical += batteryHysteresis
+		low += batteryHysteresis
+	case LevelLow:
+		low += batteryHysteresis
+	}
+
+	switch {
+	case percent <= critical:
+		return LevelCritical
+	case percent <= low:
+		return LevelLow
+	}
+	return LevelOK
+}
+
+func diskLevelFor(config Config, free uint64, current Level) Level {
+	const mb = 1024 * 1024
+	critical := uint64(config.DiskCritical) * mb
+	low := uint64(config.DiskLow) * mb
+	switch current {
+	case LevelCritical:
+		critical += diskHysteresis
+		low += diskHysteresis
+	case LevelLow:
+		low += diskHysteresis
+	}
+
+	switch {
+	case free < critical:
+		return LevelCritical
+	case free < low:
+		return LevelLow
+	}
+	return LevelOK
+}
+
+func levelRank(level Level) int {
+	switch level {
+	case LevelLow:
+		return 1
+	case LevelCritical:
+		return 2
+	}
+	return 0
+}
+
+// check reads the battery and disk and alerts when either gets worse.
+// Alerts escalate from low to critical, then to the battery action
+func (m *Manager) check() {
+	m.checkMutex.Lock()
+	defer m.checkMutex.Unlock()
+
+	config := m.GetConfig()
+	reading := m.battery()
+
+	battery := Battery{Present: reading.Present, Percent: reading.Percent, Discharging: reading.Discharging, Level: LevelOK}
+	switch {
+	case !reading.Present, !reading.Discharging:
+		m.batteryLevel = LevelOK
+		m.lastAction = ""
+	default:
+		level := batteryLevelFor(config, reading.Percent, m.batteryLevel)
+		if config.Enabled && levelRank(level) > levelRank(m.batteryLevel) {
+			m.alertBattery(config, level, reading.Percent)
+		}
+		m.batteryLevel = level
+		battery.Level = level
+
+		if config.Enabled && config.BatteryAction != ActionNone && m.lastAction == "" && reading.Percent <= config.BatteryActionAt {
+			m.lastAction = config.BatteryAction
+			log.Warnf("alerts: battery at %d%%, running %s", reading.Percent, config.BatteryAction)
+			if err := m.executor.Run(config.BatteryAction); err != nil {
+				log.Warnf("alerts: failed to %s: %v", config.BatteryAction, err)
+			}
+		}
+	}
+
+	disk := Disk{Path: config.DiskPath, Level: LevelOK}
+	if free, err := m.freeSpace(config.DiskPath); err == nil {
+		disk.Free = free
+		level := diskLevelFor(config, free, m.diskLevel)
+		if config.Enabled && levelRank(level) > levelRank(m.diskLevel) {
+			m.alertDisk(config, level, free)
+		}
+		m.diskLevel = level
+		disk.Level = level
+	}
+
+	m.stateMutex.Lock()
+	m.state = &State{
+		Config:     config,
+		Battery:    battery,
+		Disk:       disk,
+		LastAction: m.lastAction,
+	}
+	m.stateMutex.Unlock()
+
+	m.notifySubscribers()
+}
+
+func (m *Manager) alertBattery(config Config, level Level, percent int) {
+	summary := "Battery low"
+	body := fmt.Sprintf("%d%% remaining. Plug in soon", percent)
+	icon := "battery-caution"
+	if level == LevelCritical {
+		summary = "Battery critically low"
+		icon = "battery-empty"
+		body = fmt.Sprintf("%d%% remaining. Plug in now", percent)
+		if config.BatteryAction != ActionNone {
+			body = fmt.Sprintf("%d%% remaining. The system will %s at %d%%", percent, config.BatteryAction, config.BatteryActionAt)
+		}
+	}
+	if err := m.notifier.Notify(keyBattery, summary, body, icon, level == LevelCritical); err != nil {
+		log.Warnf("alerts: failed to show battery alert: %v", err)
+	}
+}
+
+func (m *Manager) alertDisk(config Config, level Level, free uint64) {
+	summary := "Low disk space"
+	if level == LevelCritical {
+		summary = "Disk almost full"
+	}
+	body := fmt.Sprintf("%s free on %s", formatBytes(free), config.DiskPath)
+	if err := m.notifier.Notify(keyDisk, summary, body, "drive-harddisk", level == LevelCritical); err != nil {
+		log.Warnf("alerts: failed to show disk alert: %v", err)
+	}
+}
+
+func formatBytes(b uint64) string {
+	const gb = 1024 * 1024 * 1024
+	if b >= gb {
+		return fmt.Sprintf("%.1f GB", float64(b)/gb)
+	}
+	return fmt.Sprintf("%d MB", b/(1024*1024))
+}
+
+func (m *Manager) GetConfig() Config {
+	m.configMutex.RLock()
+	defer m.configMutex.RUnlock()
+	return m.config
+}
+
+// SetConfig applies new thresholds. Alerts already shown aren't repeated
+// unless the new thresholds make things worse
+func (m *Manager) SetConfig(config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	m.configMutex.Lock()
+	m.config = config
+	m.configMutex.Unlock()
+
+	m.check()
+	return nil
+}
+
+func (m *Manager) notifierLoop() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.dirty:
+			state := m.GetState()
+
+			m.subMutex.RLock()
+			if m.lastNotified != nil && !stateChanged(m.lastNotified, &state) {
+				m.subMutex.RUnlock()
+				continue
+			}
+			for _, ch := range m.subscribers {
+				select {
+				case ch <- state:
+				default:
+				}
+			}
+			m.subMutex.RUnlock()
+
+			m.lastNotified = &state
+		}
+	}
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	m.wg.Wait()
+	m.notifier.Close()
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
@@ -0,0 +1,199 @@
+package alerts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeNotifier struct {
+	shown []string
+}
+
+func (n *fakeNotifier) Notify(key, summary, body, icon string, critical bool) error {
+	n.shown = append(n.shown, summary)
+	return nil
+}
+
+func (n *fakeNotifier) Close() {}
+
+type fakeExecutor struct {
+	ran []Action
+}
+
+func (e *fakeExecutor) Run(action Action) error {
+	e.ran = append(e.ran, action)
+	return nil
+}
+
+type sources struct {
+	battery BatteryReading
+	free    uint64
+}
+
+const gb = 1024 * 1024 * 1024
+
+func newTestManager(t *testing.T) (*Manager, *fakeNotifier, *fakeExecutor, *sources) {
+	t.Helper()
+	n := &fakeNotifier{}
+	e := &fakeExecutor{}
+	s := &sources{
+		battery: BatteryReading{Present: true, Percent: 80, Discharging: true},
+		free:    100 * gb,
+	}
+	m := newManager(n, e,
+		func() BatteryReading { return s.battery },
+		func(string) (uint64, error) { return s.free, nil })
+	config := m.GetConfig()
+	config.Enabled = true
+	config.BatteryAction = ActionSuspend
+	require.NoError(t, m.SetConfig(config))
+	m.check()
+	return m, n, e, s
+}
+
+func (s *sources) discharge(m *Manager, percent int) {
+	s.battery.Percent = percent
+	m.check()
+}
+
+func TestBatteryAlertsEscalate(t *testing.T) {
+	m, n, e, s := newTestManager(t)
+	assert.Equal(t, LevelOK, m.GetState().Battery.Level)
+
+	s.discharge(m, 15)
+	s.discharge(m, 12)
+	assert.Equal(t, []string{"Battery low"}, n.shown)
+	assert.Equal(t, LevelLow, m.GetState().Battery.Level)
+
+	s.discharge(m, 5)
+	s.discharge(m, 4)
+	assert.Equal(t, []string{"Battery low", "Battery critically low"}, n.shown)
+	assert.Empty(t, e.ran)
+
+	s.discharge(m, 3)
+	s.discharge(m, 2)
+	assert.Equal(t, []Action{ActionSuspend}, e.ran, "the action runs once per discharge")
+	assert.Equal(t, ActionSuspend, m.GetState().LastAction)
+}
+
+func TestBatteryAlertHysteresis(t *testing.T) {
+	m, n, _, s := newTestManager(t)
+
+	s.discharge(m, 15)
+	s.discharge(m, 16)
+	s.discharge(m, 15)
+	assert.Len(t, n.shown, 1, "hovering at the threshold alerts once")
+
+	s.discharge(m, 18)
+	assert.Equal(t, LevelOK, m.GetState().Battery.Level)
+	s.discharge(m, 15)
+	assert.Len(t, n.shown, 2)
+}
+
+func TestChargingResets(t *testing.T) {
+	m, n, e, s := newTestManager(t)
+
+	s.discharge(m, 3)
+	assert.Equal(t, []string{"Battery critically low"}, n.shown, "a sudden drop skips the low alert")
+	assert.Len(t, e.ran, 1)
+
+	s.battery.Discharging = false
+	m.check()
+	assert.Equal(t, LevelOK, m.GetState().Battery.Level)
+	assert.Empty(t, m.GetState().LastAction)
+
+	s.battery.Discharging = true
+	s.discharge(m, 3)
+	assert.Len(t, e.ran, 2)
+}
+
+func TestNoAction(t *testing.T) {
+	m, _, e, s := newTestManager(t)
+	config := m.GetConfig()
+	config.BatteryAction = ActionNone
+	require.NoError(t, m.SetConfig(config))
+
+	s.discharge(m, 1)
+	assert.Empty(t, e.ran)
+}
+
+func TestDisabled(t *testing.T) {
+	m, n, e, s := newTestManager(t)
+	config := m.GetConfig()
+	config.Enabled = false
+	require.NoError(t, m.SetConfig(config))
+
+	s.free = 0
+	s.discharge(m, 1)
+	assert.Empty(t, n.shown)
+	assert.Empty(t, e.ran)
+	assert.Equal(t, LevelCritical, m.GetState().Battery.Level, "levels are still reported")
+}
+
+func TestDiskAlerts(t *testing.T) {
+	m, n, _, s := newTestManager(t)
+
+	s.free = 4 * gb
+	m.check()
+	s.free = 4*gb + 100*1024*1024
+	m.check()
+	assert.Equal(t, []string{"Low disk space"}, n.shown)
+
+	s.free = gb / 2
+	m.check()
+	assert.Equal(t, []string{"Low disk space", "Disk almost full"}, n.shown)
+	assert.Equal(t, Disk{Path: "/home", Free: gb / 2, Level: LevelCritical}, m.GetState().Disk)
+}
+
+func TestDefaultsOptIn(t *testing.T) {
+	config := DefaultConfig()
+	assert.False(t, config.Enabled)
+	assert.Equal(t, ActionNone, config.BatteryAction)
+}
+
+func TestValidate(t *testing.T) {
+	valid := DefaultConfig()
+	require.NoError(t, valid.Validate())
+
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.BatteryCritical = 20 },
+		func(c *Config) { c.BatteryAction, c.BatteryActionAt = ActionSuspend, 10 },
+		func(c *Config) { c.BatteryAction = "reboot" },
+		func(c *Config) { c.DiskPath = "home" },
+		func(c *Config) { c.DiskLow = 10 },
+	} {
+		config := DefaultConfig()
+		mutate(&config)
+		assert.Error(t, config.Validate())
+	}
+
+	config := DefaultConfig()
+	config.BatteryAction = ActionNone
+	config.BatteryActionAt = 0
+	assert.NoError(t, config.Validate())
+}
+
+func writeSupply(t *testing.T, dir, name string, attrs map[string]string) {
+	t.Helper()
+	supply := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(supply, 0o755))
+	for attr, value := range attrs {
+		require.NoError(t, os.WriteFile(filepath.Join(supply, attr), []byte(value+"\n"), 0o644))
+	}
+}
+
+func TestReadBatteryAt(t *testing.T) {
+	dir := t.TempDir()
+	assert.False(t, readBatteryAt(dir).Present)
+
+	writeSupply(t, dir, "AC", map[string]string{"type": "Mains", "online": "0"})
+	writeSupply(t, dir, "BAT0", map[string]string{"type": "Battery", "status": "Discharging", "energy_now": "10000", "energy_full": "50000"})
+	writeSupply(t, dir, "BAT1", map[string]string{"type": "Battery", "status": "Unknown", "energy_now": "30000", "energy_full": "50000"})
+	writeSupply(t, dir, "hidpp_battery_0", map[string]string{"type": "Battery", "scope": "Device", "capacity": "5", "status": "Discharging"})
+
+	assert.Equal(t, BatteryReading{Present: true, Percent: 40, Discharging: true}, readBatteryAt(dir))
+}
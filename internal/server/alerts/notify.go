@@ -0,0 +1,63 @@
+package alerts
+
+import (
+	"sync"
+
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	notifyDest  = "org.freedesktop.Notifications"
+	notifyPath  = "/org/freedesktop/Notifications"
+	notifyIface = "org.freedesktop.Notifications"
+	notifyApp   = "DankMaterialShell"
+
+	urgencyNormal   = byte(1)
+	urgencyCritical = byte(2)
+)
+
+type dbusNotifier struct {
+	conn *dbus.Conn
+
+	mu  sync.Mutex
+	ids map[string]uint32
+}
+
+func newDBusNotifier() (*dbusNotifier, error) {
+	conn, err := dbus.ConnectSessionBus(budget.DBusOptions("alerts")...)
+	if err != nil {
+		return nil, err
+	}
+	return &dbusNotifier{conn: conn, ids: make(map[string]uint32)}, nil
+}
+
+// Notify shows an alert. Critical alerts stay until dismissed
+func (n *dbusNotifier) Notify(key, summary, body, icon string, critical bool) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	urgency := urgencyNormal
+	timeout := int32(-1)
+	if critical {
+		urgency = urgencyCritical
+		timeout = 0
+	}
+	hints := map[string]dbus.Variant{
+		"urgency":  dbus.MakeVariant(urgency),
+		"category": dbus.MakeVariant("device"),
+	}
+
+	var id uint32
+	err := n.conn.Object(notifyDest, notifyPath).Call(notifyIface+".Notify", 0,
+		notifyApp, n.ids[key], icon, summary, body, []string{}, hints, timeout).Store(&id)
+	if err != nil {
+		return err
+	}
+	n.ids[key] = id
+	return nil
+}
+
+func (n *dbusNotifier) Close() {
+	n.conn.Close()
+}
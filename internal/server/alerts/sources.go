@@ -0,0 +1,113 @@
+package alerts
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const powerSupplyDir = "/sys/class/power_supply"
+
+func readSupply(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func readSupplyInt(dir, name string) (int64, bool) {
+	v, err := strconv.ParseInt(readSupply(dir, name), 10, 64)
+	return v, err == nil
+}
+
+// readBatteryAt combines the system batteries under dir, weighting each by
+// its capacity. Peripheral batteries (scope Device) are left out
+func readBatteryAt(dir string) BatteryReading {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return BatteryReading{}
+	}
+
+	var reading BatteryReading
+	var now, full int64
+	var capacities []int64
+	for _, entry := range entries {
+		supply := filepath.Join(dir, entry.Name())
+		if readSupply(supply, "type") != "Battery" || readSupply(supply, "scope") == "Device" {
+			continue
+		}
+		if present, ok := readSupplyInt(supply, "present"); ok && present == 0 {
+			continue
+		}
+		reading.Present = true
+		if readSupply(supply, "status") == "Discharging" {
+			reading.Discharging = true
+		}
+
+		n, okNow := readSupplyInt(supply, "energy_now")
+		f, okFull := readSupplyInt(supply, "energy_full")
+		if !okNow || !okFull {
+			n, okNow = readSupplyInt(supply, "charge_now")
+			f, okFull = readSupplyInt(supply, "charge_full")
+		}
+		if okNow && okFull && f > 0 {
+			now += n
+			full += f
+		} else if capacity, ok := readSupplyInt(supply, "capacity"); ok {
+			capacities = append(capacities, capacity)
+		}
+	}
+
+	switch {
+	case full > 0:
+		reading.Percent = int(now * 100 / full)
+	case len(capacities) > 0:
+		var sum int64
+		for _, c := range capacities {
+			sum += c
+		}
+		reading.Percent = int(sum / int64(len(capacities)))
+	}
+	reading.Percent = min(max(reading.Percent, 0), 100)
+	return reading
+}
+
+func readBattery() BatteryReading {
+	return readBatteryAt(powerSupplyDir)
+}
+
+func freeSpace(path string) (uint64, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return st.Bavail * uint64(st.Bsize), nil
+}
+
+// commandExecutor runs the battery actions through systemd, as the idle
+// timeline does for suspend
+type commandExecutor struct{}
+
+func (commandExecutor) Run(action Action) error {
+	var verb string
+	switch action {
+	case ActionSuspend:
+		verb = "suspend"
+	case ActionHibernate:
+		verb = "hibernate"
+	case ActionPowerOff:
+		verb = "poweroff"
+	default:
+		return fmt.Errorf("unknown battery action: %s", action)
+	}
+	if output, err := exec.Command("systemctl", verb).CombinedOutput(); err != nil {
+		return fmt.Errorf("systemctl %s: %s", verb, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package alerts
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+type Level string
+
+const (
+	LevelOK       Level = "ok"
+	LevelLow      Level = "low"
+	LevelCritical Level = "critical"
+)
+
+type Action string
+
+const (
+	ActionNone      Action = "none"
+	ActionSuspend   Action = "suspend"
+	ActionHibernate Action = "hibernate"
+	ActionPowerOff  Action = "poweroff"
+)
+
+// Config thresholds are battery percentages and free space in MB
+type Config struct {
+	Enabled         bool `json:"enabled"`
+	BatteryLow      int  `json:"batteryLow"`
+	BatteryCritical int  `json:"batteryCritical"`
+	// BatteryAction runs once the battery drops to BatteryActionAt while
+	// discharging
+	BatteryAction   Action `json:"batteryAction"`
+	BatteryActionAt int    `json:"batteryActionAt"`
+	DiskPath        string `json:"diskPath"`
+	DiskLow         int    `json:"diskLow"`
+	DiskCritical    int    `json:"diskCritical"`
+}
+
+type Battery struct {
+	Present     bool  `json:"present"`
+	Percent     int   `json:"percent"`
+	Discharging bool  `json:"discharging"`
+	Level       Level `json:"level"`
+}
+
+type Disk struct {
+	Path string `json:"path"`
+	// Free is the space available to the user, in bytes
+	Free  uint64 `json:"free"`
+	Level Level  `json:"level"`
+}
+
+type State struct {
+	Config  Config  `json:"config"`
+	Battery Battery `json:"battery"`
+	Disk    Disk    `json:"disk"`
+	// LastAction is the automatic action taken for the current discharge,
+	// cleared once the battery charges
+	LastAction Action `json:"lastAction,omitempty"`
+}
+
+// BatteryReading is the combined charge of the system batteries
+type BatteryReading struct {
+	Present     bool
+	Percent     int
+	Discharging bool
+}
+
+// Notifier shows alerts through the notification daemon. Each key keeps a
+// single notification that later alerts replace
+type Notifier interface {
+	Notify(key, summary, body, icon string, critical bool) error
+	Close()
+}
+
+type Executor interface {
+	Run(action Action) error
+}
+
+type Manager struct {
+	config      Config
+	configMutex sync.RWMutex
+	state       *State
+	stateMutex  sync.RWMutex
+
+	notifier  Notifier
+	executor  Executor
+	battery   func() BatteryReading
+	freeSpace func(path string) (uint64, error)
+
+	checkMutex   sync.Mutex
+	batteryLevel Level
+	diskLevel    Level
+	lastAction   Action
+
+	pollInterval time.Duration
+	subscribers  map[string]chan State
+	subMutex     sync.RWMutex
+	dirty        chan struct{}
+	stopChan     chan struct{}
+	wg           sync.WaitGroup
+	lastNotified *State
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Enabled:         false,
+		BatteryLow:      15,
+		BatteryCritical: 5,
+		BatteryAction:   ActionNone,
+		BatteryActionAt: 3,
+		DiskPath:        "/home",
+		DiskLow:         5 * 1024,
+		DiskCritical:    1024,
+	}
+}
+
+func (c *Config) Validate() error {
+	if c.BatteryLow < 1 || c.BatteryLow > 100 {
+		return fmt.Errorf("batteryLow must be between 1 and 100")
+	}
+	if c.BatteryCritical < 1 || c.BatteryCritical > c.BatteryLow {
+		return fmt.Errorf("batteryCritical must be between 1 and batteryLow")
+	}
+	switch c.BatteryAction {
+	case ActionNone:
+	case ActionSuspend, ActionHibernate, ActionPowerOff:
+		if c.BatteryActionAt < 1 || c.BatteryActionAt > c.BatteryCritical {
+			return fmt.Errorf("batteryActionAt must be between 1 and batteryCritical")
+		}
+	default:
+		return fmt.Errorf("batteryAction must be none, suspend, hibernate or poweroff")
+	}
+	if c.DiskPath == "" || c.DiskPath[0] != '/' {
+		return fmt.Errorf("diskPath must be an absolute path")
+	}
+	if c.DiskCritical < 0 || c.DiskLow < c.DiskCritical {
+		return fmt.Errorf("diskLow must be at least diskCritical")
+	}
+	return nil
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.state == nil {
+		return State{}
+	}
+	return *m.state
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func stateChanged(old, new *State) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	return !reflect.DeepEqual(old, new)
+}
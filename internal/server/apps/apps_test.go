@@ -0,0 +1,179 @@
+package apps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func setupDataDirs(t *testing.T) (string, string) {
+	t.Helper()
+	user := t.TempDir()
+	system := t.TempDir()
+
+	writeFile(t, filepath.Join(system, "applications", "firefox.desktop"), `[Desktop Entry]
+Type=Application
+Name=Firefox
+Name[de]=Firefox Webbrowser
+GenericName=Web Browser
+Keywords=internet;www;
+Exec=firefox %u
+Icon=firefox
+Actions=new-window;private;
+
+[Desktop Action new-window]
+Name=New Window
+Exec=firefox --new-window
+
+[Desktop Action private]
+Name=New Private Window
+Exec=firefox --private-window
+`)
+	writeFile(t, filepath.Join(system, "applications", "org.gnome.Files.desktop"), `[Desktop Entry]
+Type=Application
+Name=Files
+Exec=nautilus --new-window %U
+Icon=/opt/files.png
+`)
+	writeFile(t, filepath.Join(system, "applications", "kde", "dolphin.desktop"), `[Desktop Entry]
+Type=Application
+Name=Dolphin
+Exec=dolphin
+OnlyShowIn=KDE;
+`)
+	writeFile(t, filepath.Join(system, "applications", "hidden.desktop"), `[Desktop Entry]
+Type=Application
+Name=Helper
+Exec=helper
+NoDisplay=true
+`)
+	// The user's copy of files shadows the system one
+	writeFile(t, filepath.Join(user, "applications", "org.gnome.Files.desktop"), `[Desktop Entry]
+Type=Application
+Name=Files
+Hidden=true
+`)
+
+	writeFile(t, filepath.Join(system, "icons", "hicolor", "48x48", "apps", "firefox.png"), "")
+	writeFile(t, filepath.Join(system, "icons", "hicolor", "scalable", "apps", "firefox.svg"), "")
+	return user, system
+}
+
+func TestScanApps(t *testing.T) {
+	user, system := setupDataDirs(t)
+
+	apps := scanApps(applicationDirs([]string{user, system}), []string{"de_DE", "de"}, []string{"Hyprland"})
+	require.Len(t, apps, 1)
+
+	app := apps[0]
+	assert.Equal(t, "firefox.desktop", app.ID)
+	assert.Equal(t, "Firefox Webbrowser", app.Name)
+	assert.Equal(t, "firefox", app.Command)
+	assert.Equal(t, []string{"internet", "www"}, app.Keywords)
+	require.Len(t, app.Actions, 2)
+	assert.Equal(t, "private", app.Actions[1].ID)
+	assert.Equal(t, "firefox --private-window", app.Actions[1].Exec)
+
+	apps = scanApps(applicationDirs([]string{system}), nil, []string{"KDE"})
+	ids := []string{}
+	for _, app := range apps {
+		ids = append(ids, app.ID)
+	}
+	assert.ElementsMatch(t, []string{"firefox.desktop", "org.gnome.Files.desktop", "kde-dolphin.desktop"}, ids)
+}
+
+func TestStripFieldCodes(t *testing.T) {
+	assert.Equal(t, "nautilus --new-window", stripFieldCodes("nautilus --new-window %U"))
+	assert.Equal(t, "printf 100%", stripFieldCodes("printf 100%% %f"))
+}
+
+func TestIconIndexPrefersScalable(t *testing.T) {
+	_, system := setupDataDirs(t)
+	writeFile(t, filepath.Join(system, "pixmaps", "legacy.xpm"), "")
+
+	icons := buildIconIndex([]string{system}, "Missing")
+	assert.Equal(t, filepath.Join(system, "icons", "hicolor", "scalable", "apps", "firefox.svg"), icons.resolve("firefox"))
+	assert.Equal(t, filepath.Join(system, "pixmaps", "legacy.xpm"), icons.resolve("legacy"))
+	assert.Empty(t, icons.resolve("unknown"))
+}
+
+func TestIconThemeInheritance(t *testing.T) {
+	base := t.TempDir()
+	writeFile(t, filepath.Join(base, "icons", "Child", "index.theme"), "[Icon Theme]\nName=Child\nInherits=Parent,hicolor\n")
+	writeFile(t, filepath.Join(base, "icons", "Parent", "64x64", "apps", "term.png"), "")
+	writeFile(t, filepath.Join(base, "icons", "hicolor", "scalable", "apps", "term.svg"), "")
+
+	icons := buildIconIndex([]string{base}, "Child")
+	assert.Equal(t, filepath.Join(base, "icons", "Parent", "64x64", "apps", "term.png"), icons.resolve("term"))
+}
+
+func TestSearchRanking(t *testing.T) {
+	dir := t.TempDir()
+	m := newManager(nil, filepath.Join(dir, "usage.json"))
+	m.apps = []*App{
+		{ID: "firefox.desktop", Name: "Firefox", GenericName: "Web Browser", Command: "firefox"},
+		{ID: "files.desktop", Name: "Files", Command: "nautilus"},
+		{ID: "libreoffice-writer.desktop", Name: "LibreOffice Writer", Keywords: []string{"word"}, Command: "libreoffice --writer"},
+	}
+
+	results := m.Search("fi", 0)
+	require.Len(t, results, 3)
+	assert.Equal(t, "files.desktop", results[0].ID)
+	assert.Equal(t, "libreoffice-writer.desktop", results[2].ID)
+
+	require.NoError(t, m.RecordLaunch("firefox.desktop"))
+	require.NoError(t, m.RecordLaunch("firefox.desktop"))
+	results = m.Search("fi", 0)
+	assert.Equal(t, "firefox.desktop", results[0].ID)
+	assert.Equal(t, 2, results[0].Launches)
+
+	assert.Equal(t, "libreoffice-writer.desktop", m.Search("writer", 0)[0].ID)
+	assert.Equal(t, "libreoffice-writer.desktop", m.Search("word", 0)[0].ID)
+	assert.Equal(t, "firefox.desktop", m.Search("browser", 0)[0].ID)
+	assert.Equal(t, "libreoffice-writer.desktop", m.Search("lbw", 0)[0].ID)
+	assert.Len(t, m.Search("", 1), 1)
+	assert.Equal(t, "firefox.desktop", m.Search("", 0)[0].ID)
+
+	assert.Error(t, m.RecordLaunch("missing.desktop"))
+
+	reloaded := loadUsage(filepath.Join(dir, "usage.json"))
+	assert.Equal(t, 2, reloaded["firefox.desktop"].Count)
+}
+
+func TestFrecencyDecays(t *testing.T) {
+	now := time.Now()
+	recent := &usageEntry{Count: 3, Last: now.Unix()}
+	old := &usageEntry{Count: 3, Last: now.Add(-60 * 24 * time.Hour).Unix()}
+	assert.Greater(t, recent.frecency(now), old.frecency(now))
+	assert.Zero(t, (*usageEntry)(nil).frecency(now))
+}
+
+func TestReindexDetectsChanges(t *testing.T) {
+	user, system := setupDataDirs(t)
+	m := newManager([]string{user, system}, filepath.Join(t.TempDir(), "usage.json"))
+	m.Reindex()
+
+	state := m.GetState()
+	assert.True(t, state.Ready)
+	assert.Equal(t, 1, state.Apps)
+	assert.False(t, m.dirsChanged())
+
+	writeFile(t, filepath.Join(user, "applications", "new", "editor.desktop"), "[Desktop Entry]\nType=Application\nName=Editor\nExec=editor\n")
+	assert.True(t, m.dirsChanged())
+	m.Reindex()
+
+	app, err := m.Get("new-editor.desktop")
+	require.NoError(t, err)
+	assert.Equal(t, "Editor", app.Name)
+	assert.Equal(t, 2, m.GetState().Generation)
+}
@@ -0,0 +1,375 @@
+package apps
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+const (
+	CategoryBrowser     = "browser"
+	CategoryTerminal    = "terminal"
+	CategoryFileManager = "file-manager"
+	CategoryImageViewer = "image-viewer"
+
+	defaultAppsSection = "Default Applications"
+	terminalCategory   = "TerminalEmulator"
+)
+
+// categoryMimeTypes lists the MIME types set for each category. The first
+// is the one an app must handle to be offered for the category. Terminals
+// have no MIME type and go through xdg-terminals.list instead
+var categoryMimeTypes = map[string][]string{
+	CategoryBrowser:     {"x-scheme-handler/http", "x-scheme-handler/https", "text/html", "application/xhtml+xml"},
+	CategoryFileManager: {"inode/directory"},
+	CategoryImageViewer: {"image/png", "image/jpeg", "image/gif", "image/webp", "image/svg+xml", "image/bmp", "image/tiff", "image/avif"},
+	CategoryTerminal:    nil,
+}
+
+type Default struct {
+	Category string `json:"category"`
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Icon     string `json:"iconPath,omitempty"`
+}
+
+func Categories() []string {
+	categories := make([]string, 0, len(categoryMimeTypes))
+	for category := range categoryMimeTypes {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+func configHome() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.Getenv("HOME"), ".config")
+}
+
+func configDirs() []string {
+	dirs := []string{configHome()}
+	xdgDirs := os.Getenv("XDG_CONFIG_DIRS")
+	if xdgDirs == "" {
+		xdgDirs = "/etc/xdg"
+	}
+	return append(dirs, filepath.SplitList(xdgDirs)...)
+}
+
+// listPaths returns the lookup order for a list file such as
+// mimeapps.list: desktop-specific variants before generic ones, user
+// config before system config, then the applications data dirs
+func listPaths(name string, desktops, dataDirs []string) []string {
+	var paths []string
+	add := func(dir string) {
+		for _, desktop := range desktops {
+			paths = append(paths, filepath.Join(dir, strings.ToLower(desktop)+"-"+name))
+		}
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	for _, dir := range configDirs() {
+		add(dir)
+	}
+	if name == "mimeapps.list" {
+		for _, dir := range applicationDirs(dataDirs) {
+			add(dir)
+		}
+	}
+	return paths
+}
+
+// iniFile keeps the original lines so editing a key leaves comments,
+// ordering and unrelated sections untouched
+type iniFile struct {
+	lines []string
+}
+
+func readIniFile(path string) (*iniFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &iniFile{}, nil
+		}
+		return nil, err
+	}
+	text := strings.TrimRight(string(data), "\n")
+	if text == "" {
+		return &iniFile{}, nil
+	}
+	return &iniFile{lines: strings.Split(text, "\n")}, nil
+}
+
+// find returns the line index of the section header and of the key within
+// it, -1 when missing
+func (f *iniFile) find(section, key string) (int, int) {
+	sectionLine, keyLine := -1, -1
+	inSection := false
+	for i, line := range f.lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			inSection = trimmed[1:len(trimmed)-1] == section
+			if inSection && sectionLine == -1 {
+				sectionLine = i
+			}
+			continue
+		}
+		if !inSection {
+			continue
+		}
+		if k, _, ok := strings.Cut(trimmed, "="); ok && strings.TrimSpace(k) == key {
+			keyLine = i
+		}
+	}
+	return sectionLine, keyLine
+}
+
+func (f *iniFile) get(section, key string) (string, bool) {
+	_, keyLine := f.find(section, key)
+	if keyLine == -1 {
+		return "", false
+	}
+	_, value, _ := strings.Cut(f.lines[keyLine], "=")
+	return strings.TrimSpace(value), true
+}
+
+func (f *iniFile) set(section, key, value string) {
+	entry := key + "=" + value
+	sectionLine, keyLine := f.find(section, key)
+	switch {
+	case keyLine != -1:
+		f.lines[keyLine] = entry
+	case sectionLine != -1:
+		// Append after the section's last non-blank line
+		insert := sectionLine + 1
+		for i := sectionLine + 1; i < len(f.lines); i++ {
+			trimmed := strings.TrimSpace(f.lines[i])
+			if strings.HasPrefix(trimmed, "[") {
+				break
+			}
+			if trimmed != "" {
+				insert = i + 1
+			}
+		}
+		f.lines = append(f.lines[:insert], append([]string{entry}, f.lines[insert:]...)...)
+	default:
+		if len(f.lines) > 0 {
+			f.lines = append(f.lines, "")
+		}
+		f.lines = append(f.lines, "["+section+"]", entry)
+	}
+}
+
+func (f *iniFile) write(path string) error {
+	return writeLines(path, f.lines)
+}
+
+func writeLines(path string, lines []string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+func readLines(path string) []string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+type defaultsResolver struct {
+	apps     map[string]*App
+	desktops []string
+	dataDirs []string
+}
+
+func newDefaultsResolver(apps []*App, desktops, dataDirs []string) *defaultsResolver {
+	r := &defaultsResolver{apps: make(map[string]*App, len(apps)), desktops: desktops, dataDirs: dataDirs}
+	for _, app := range apps {
+		r.apps[app.ID] = app
+	}
+	return r
+}
+
+// current finds the category's default: the first installed app listed for
+// its primary MIME type (or in xdg-terminals.list), falling back to the
+// first listed ID when none of them is installed
+func (r *defaultsResolver) current(category string) (string, error) {
+	mimeTypes, ok := categoryMimeTypes[category]
+	if !ok {
+		return "", fmt.Errorf("unknown category %q (use %s)", category, strings.Join(Categories(), ", "))
+	}
+
+	var candidates []string
+	if category == CategoryTerminal {
+		for _, path := range listPaths("xdg-terminals.list", r.desktops, r.dataDirs) {
+			candidates = append(candidates, readLines(path)...)
+		}
+	} else {
+		for _, path := range listPaths("mimeapps.list", r.desktops, r.dataDirs) {
+			file, err := readIniFile(path)
+			if err != nil {
+				continue
+			}
+			if value, ok := file.get(defaultAppsSection, mimeTypes[0]); ok {
+				candidates = append(candidates, splitList(value)...)
+			}
+		}
+	}
+
+	for _, id := range candidates {
+		if _, installed := r.apps[id]; installed {
+			return id, nil
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates[0], nil
+	}
+	return "", nil
+}
+
+func (r *defaultsResolver) defaults() []Default {
+	var defaults []Default
+	for _, category := range Categories() {
+		id, _ := r.current(category)
+		d := Default{Category: category, ID: id}
+		if app, ok := r.apps[id]; ok {
+			d.Name = app.Name
+			d.Icon = app.IconPath
+		}
+		defaults = append(defaults, d)
+	}
+	return defaults
+}
+
+func handlesCategory(app *App, category string) bool {
+	if category == CategoryTerminal {
+		for _, c := range app.Categories {
+			if c == terminalCategory {
+				return true
+			}
+		}
+		return false
+	}
+	primary := categoryMimeTypes[category][0]
+	for _, mime := range app.MimeTypes {
+		if mime == primary {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *defaultsResolver) candidates(category string) ([]App, error) {
+	if _, ok := categoryMimeTypes[category]; !ok {
+		return nil, fmt.Errorf("unknown category %q (use %s)", category, strings.Join(Categories(), ", "))
+	}
+	var apps []App
+	for _, app := range r.apps {
+		if handlesCategory(app, category) {
+			apps = append(apps, *app)
+		}
+	}
+	sort.Slice(apps, func(i, j int) bool {
+		return strings.ToLower(apps[i].Name) < strings.ToLower(apps[j].Name)
+	})
+	return apps, nil
+}
+
+// set makes id the default for category in the user's mimeapps.list (or
+// xdg-terminals.list). The app must be installed and handle the category
+func (r *defaultsResolver) set(category, id string) error {
+	mimeTypes, ok := categoryMimeTypes[category]
+	if !ok {
+		return fmt.Errorf("unknown category %q (use %s)", category, strings.Join(Categories(), ", "))
+	}
+	if !strings.HasSuffix(id, ".desktop") {
+		id += ".desktop"
+	}
+	app, ok := r.apps[id]
+	if !ok {
+		return fmt.Errorf("app not found: %s", id)
+	}
+	if !handlesCategory(app, category) {
+		if category == CategoryTerminal {
+			return fmt.Errorf("%s is not a terminal emulator", id)
+		}
+		return fmt.Errorf("%s does not handle %s", id, mimeTypes[0])
+	}
+
+	if category == CategoryTerminal {
+		path := filepath.Join(configHome(), "xdg-terminals.list")
+		lines := []string{id}
+		for _, line := range readLines(path) {
+			if line != id {
+				lines = append(lines, line)
+			}
+		}
+		return writeLines(path, lines)
+	}
+
+	path := filepath.Join(configHome(), "mimeapps.list")
+	file, err := readIniFile(path)
+	if err != nil {
+		return err
+	}
+	for _, mime := range mimeTypes {
+		file.set(defaultAppsSection, mime, id+";")
+	}
+	return file.write(path)
+}
+
+// LoadDefaults resolves default apps without a running server, for the CLI
+func LoadDefaults() []Default {
+	return standaloneResolver().defaults()
+}
+
+func SetDefault(category, id string) error {
+	return standaloneResolver().set(category, id)
+}
+
+func DefaultCandidates(category string) ([]App, error) {
+	return standaloneResolver().candidates(category)
+}
+
+func standaloneResolver() *defaultsResolver {
+	dataDirs := defaultDataDirs()
+	desktops := splitDesktops(os.Getenv("XDG_CURRENT_DESKTOP"))
+	return newDefaultsResolver(scanApps(applicationDirs(dataDirs), localeKeys(), desktops), desktops, dataDirs)
+}
+
+func (m *Manager) resolver() *defaultsResolver {
+	m.appsMutex.RLock()
+	defer m.appsMutex.RUnlock()
+	return newDefaultsResolver(m.apps, m.desktops, m.dataDirs)
+}
+
+func (m *Manager) Defaults() []Default {
+	return m.resolver().defaults()
+}
+
+func (m *Manager) SetDefault(category, id string) error {
+	return m.resolver().set(category, id)
+}
+
+func (m *Manager) DefaultCandidates(category string) ([]App, error) {
+	return m.resolver().candidates(category)
+}
@@ -0,0 +1,104 @@
+package apps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testResolver(t *testing.T) (*defaultsResolver, string) {
+	t.Helper()
+	config := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", config)
+	t.Setenv("XDG_CONFIG_DIRS", filepath.Join(config, "system"))
+
+	apps := []*App{
+		{ID: "firefox.desktop", Name: "Firefox", MimeTypes: []string{"text/html", "x-scheme-handler/http"}},
+		{ID: "chromium.desktop", Name: "Chromium", MimeTypes: []string{"x-scheme-handler/http"}},
+		{ID: "foot.desktop", Name: "Foot", Categories: []string{"System", "TerminalEmulator"}},
+		{ID: "org.gnome.Loupe.desktop", Name: "Image Viewer", MimeTypes: []string{"image/png"}},
+	}
+	return newDefaultsResolver(apps, []string{"niri"}, nil), config
+}
+
+func TestSetDefaultPreservesOtherEntries(t *testing.T) {
+	r, config := testResolver(t)
+	path := filepath.Join(config, "mimeapps.list")
+	writeFile(t, path, "# mine\n[Added Associations]\ntext/plain=gedit.desktop;\n\n[Default Applications]\napplication/pdf=org.gnome.Evince.desktop;\nx-scheme-handler/http=chromium.desktop;\n")
+
+	require.NoError(t, r.set(CategoryBrowser, "firefox"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `# mine
+[Added Associations]
+text/plain=gedit.desktop;
+
+[Default Applications]
+application/pdf=org.gnome.Evince.desktop;
+x-scheme-handler/http=firefox.desktop;
+x-scheme-handler/https=firefox.desktop;
+text/html=firefox.desktop;
+application/xhtml+xml=firefox.desktop;
+`, string(data))
+
+	id, err := r.current(CategoryBrowser)
+	require.NoError(t, err)
+	assert.Equal(t, "firefox.desktop", id)
+}
+
+func TestSetDefaultValidates(t *testing.T) {
+	r, _ := testResolver(t)
+
+	assert.ErrorContains(t, r.set(CategoryBrowser, "missing.desktop"), "not found")
+	assert.ErrorContains(t, r.set(CategoryBrowser, "foot.desktop"), "does not handle")
+	assert.ErrorContains(t, r.set(CategoryTerminal, "firefox.desktop"), "not a terminal")
+	assert.ErrorContains(t, r.set("mail", "firefox.desktop"), "unknown category")
+}
+
+func TestCurrentDefaultLookupOrder(t *testing.T) {
+	r, config := testResolver(t)
+
+	writeFile(t, filepath.Join(config, "system", "mimeapps.list"), "[Default Applications]\nimage/png=org.gnome.Loupe.desktop;\ninode/directory=thunar.desktop;\n")
+	writeFile(t, filepath.Join(config, "niri-mimeapps.list"), "[Default Applications]\nimage/png=removed.desktop;gone.desktop;\n")
+
+	// Uninstalled apps are skipped in favour of the next installed one
+	id, err := r.current(CategoryImageViewer)
+	require.NoError(t, err)
+	assert.Equal(t, "org.gnome.Loupe.desktop", id)
+
+	// Nothing installed: report what is configured
+	id, err = r.current(CategoryFileManager)
+	require.NoError(t, err)
+	assert.Equal(t, "thunar.desktop", id)
+
+	defaults := r.defaults()
+	require.Len(t, defaults, 4)
+	assert.Equal(t, Default{Category: CategoryImageViewer, ID: "org.gnome.Loupe.desktop", Name: "Image Viewer"}, defaults[2])
+	assert.Equal(t, Default{Category: CategoryTerminal}, defaults[3])
+}
+
+func TestSetDefaultTerminal(t *testing.T) {
+	r, config := testResolver(t)
+	path := filepath.Join(config, "xdg-terminals.list")
+	writeFile(t, path, "kitty.desktop\nfoot.desktop\n")
+
+	require.NoError(t, r.set(CategoryTerminal, "foot.desktop"))
+	assert.Equal(t, []string{"foot.desktop", "kitty.desktop"}, readLines(path))
+
+	id, err := r.current(CategoryTerminal)
+	require.NoError(t, err)
+	assert.Equal(t, "foot.desktop", id)
+
+	candidates, err := r.candidates(CategoryTerminal)
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+	assert.Equal(t, "foot.desktop", candidates[0].ID)
+
+	candidates, err = r.candidates(CategoryBrowser)
+	require.NoError(t, err)
+	assert.Len(t, candidates, 2)
+}
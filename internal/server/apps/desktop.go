@@ -0,0 +1,238 @@
+package apps
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type desktopGroup map[string]string
+
+// parseDesktopFile reads the groups of a desktop entry file
+func parseDesktopFile(path string) (map[string]desktopGroup, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	groups := make(map[string]desktopGroup)
+	var current desktopGroup
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = make(desktopGroup)
+			groups[line[1:len(line)-1]] = current
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		current[strings.TrimSpace(key)] = unescapeValue(strings.TrimSpace(value))
+	}
+	return groups, scanner.Err()
+}
+
+func unescapeValue(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	return strings.NewReplacer(`\s`, " ", `\n`, "\n", `\t`, "\t", `\r`, "\r", `\\`, `\`).Replace(value)
+}
+
+// localeKeys lists the localized key suffixes to try for LANG, most
+// specific first, e.g. [pt_BR] then [pt]
+func localeKeys() []string {
+	lang := os.Getenv("LC_MESSAGES")
+	if lang == "" {
+		lang = os.Getenv("LANG")
+	}
+	lang, _, _ = strings.Cut(lang, ".")
+	lang, _, _ = strings.Cut(lang, "@")
+	if lang == "" || lang == "C" || lang == "POSIX" {
+		return nil
+	}
+	keys := []string{lang}
+	if short, _, ok := strings.Cut(lang, "_"); ok {
+		keys = append(keys, short)
+	}
+	return keys
+}
+
+func (g desktopGroup) localized(key string, locales []string) string {
+	for _, locale := range locales {
+		if value, ok := g[key+"["+locale+"]"]; ok && value != "" {
+			return value
+		}
+	}
+	return g[key]
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// stripFieldCodes removes %f, %U and friends from an Exec line
+func stripFieldCodes(exec string) string {
+	var fields []string
+	for _, field := range strings.Fields(exec) {
+		if len(field) == 2 && field[0] == '%' {
+			continue
+		}
+		fields = append(fields, strings.ReplaceAll(field, "%%", "%"))
+	}
+	return strings.Join(fields, " ")
+}
+
+func shownIn(group desktopGroup, desktops []string) bool {
+	matches := func(list string) bool {
+		for _, item := range splitList(list) {
+			for _, desktop := range desktops {
+				if strings.EqualFold(item, desktop) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	if only, ok := group["OnlyShowIn"]; ok && !matches(only) {
+		return false
+	}
+	if not, ok := group["NotShowIn"]; ok && matches(not) {
+		return false
+	}
+	return true
+}
+
+// loadApp turns a desktop file into an App, or nil when it shouldn't be
+// shown in a launcher
+func loadApp(id, path string, locales, desktops []string) *App {
+	groups, err := parseDesktopFile(path)
+	if err != nil {
+		return nil
+	}
+	entry, ok := groups["Desktop Entry"]
+	if !ok || entry["Type"] != "Application" || entry["NoDisplay"] == "true" || entry["Hidden"] == "true" {
+		return nil
+	}
+	if entry["Name"] == "" || entry["Exec"] == "" || !shownIn(entry, desktops) {
+		return nil
+	}
+	if tryExec := entry["TryExec"]; tryExec != "" && !executableExists(tryExec) {
+		return nil
+	}
+
+	app := &App{
+		ID:          id,
+		Name:        entry.localized("Name", locales),
+		GenericName: entry.localized("GenericName", locales),
+		Comment:     entry.localized("Comment", locales),
+		Icon:        entry["Icon"],
+		Exec:        entry["Exec"],
+		Command:     stripFieldCodes(entry["Exec"]),
+		Terminal:    entry["Terminal"] == "true",
+		Categories:  splitList(entry["Categories"]),
+		Keywords:    splitList(entry.localized("Keywords", locales)),
+		MimeTypes:   splitList(entry["MimeType"]),
+		Path:        path,
+	}
+
+	for _, actionID := range splitList(entry["Actions"]) {
+		group, ok := groups["Desktop Action "+actionID]
+		if !ok || group["Name"] == "" || group["Exec"] == "" {
+			continue
+		}
+		app.Actions = append(app.Actions, Action{
+			ID:   actionID,
+			Name: group.localized("Name", locales),
+			Exec: group["Exec"],
+			Icon: group["Icon"],
+		})
+	}
+	return app
+}
+
+func executableExists(name string) bool {
+	if filepath.IsAbs(name) {
+		info, err := os.Stat(name)
+		return err == nil && info.Mode()&0111 != 0
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err == nil && info.Mode()&0111 != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// applicationDirs returns the applications directories in precedence
+// order: the user's data dir first, then XDG_DATA_DIRS
+func applicationDirs(dataDirs []string) []string {
+	dirs := make([]string, 0, len(dataDirs))
+	for _, dir := range dataDirs {
+		dirs = append(dirs, filepath.Join(dir, "applications"))
+	}
+	return dirs
+}
+
+func defaultDataDirs() []string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(os.Getenv("HOME"), ".local", "share")
+	}
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	return append([]string{dataHome}, filepath.SplitList(dataDirs)...)
+}
+
+// scanApps loads every desktop entry. A desktop file ID found in an
+// earlier directory shadows later ones, including hidden entries, which is
+// how users remove system apps from their launcher
+func scanApps(appDirs, locales, desktops []string) []*App {
+	seen := make(map[string]bool)
+	var apps []*App
+	for _, dir := range appDirs {
+		filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if path == dir {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if entry.IsDir() || !strings.HasSuffix(path, ".desktop") {
+				return nil
+			}
+			rel, _ := filepath.Rel(dir, path)
+			id := strings.ReplaceAll(rel, string(filepath.Separator), "-")
+			if seen[id] {
+				return nil
+			}
+			seen[id] = true
+			if app := loadApp(id, path, locales, desktops); app != nil {
+				apps = append(apps, app)
+			}
+			return nil
+		})
+	}
+	return apps
+}
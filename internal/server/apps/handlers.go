@@ -0,0 +1,135 @@
+package apps
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "apps manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "apps.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "apps.search":
+		query, _ := req.Params["query"].(string)
+		limit := 0
+		if l, ok := req.Params["limit"].(float64); ok {
+			limit = int(l)
+		}
+		models.Respond(conn, req.ID, manager.Search(query, limit))
+	case "apps.list":
+		models.Respond(conn, req.ID, manager.List())
+	case "apps.get":
+		id, ok := req.Params["id"].(string)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'id' parameter")
+			return
+		}
+		app, err := manager.Get(id)
+		if err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		models.Respond(conn, req.ID, app)
+	case "apps.recordLaunch":
+		id, ok := req.Params["id"].(string)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'id' parameter")
+			return
+		}
+		if err := manager.RecordLaunch(id); err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "launch recorded"})
+	case "apps.setIconTheme":
+		theme, ok := req.Params["theme"].(string)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'theme' parameter")
+			return
+		}
+		if err := manager.SetIconTheme(theme); err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		models.Respond(conn, req.ID, SuccessResult{Success: true, Message: fmt.Sprintf("icon theme set to %s", theme)})
+	case "apps.getDefaults":
+		models.Respond(conn, req.ID, manager.Defaults())
+	case "apps.candidates":
+		category, ok := req.Params["category"].(string)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'category' parameter")
+			return
+		}
+		candidates, err := manager.DefaultCandidates(category)
+		if err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		models.Respond(conn, req.ID, candidates)
+	case "apps.setDefault":
+		category, ok := req.Params["category"].(string)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'category' parameter")
+			return
+		}
+		id, ok := req.Params["id"].(string)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'id' parameter")
+			return
+		}
+		if err := manager.SetDefault(category, id); err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		models.Respond(conn, req.ID, SuccessResult{Success: true, Message: fmt.Sprintf("default %s set to %s", category, id)})
+	case "apps.reindex":
+		manager.Reindex()
+		models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "reindexed"})
+	case "apps.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
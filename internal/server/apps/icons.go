@@ -0,0 +1,165 @@
+package apps
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var iconExtensions = map[string]bool{".svg": true, ".png": true, ".xpm": true}
+
+type iconCandidate struct {
+	path string
+	rank int
+}
+
+// iconIndex maps icon names to files for a theme and the themes it
+// inherits from, ending with hicolor and /usr/share/pixmaps
+type iconIndex map[string]string
+
+func currentIconTheme() string {
+	output, err := exec.Command("gsettings", "get", "org.gnome.desktop.interface", "icon-theme").Output()
+	if err != nil {
+		return "hicolor"
+	}
+	theme := strings.Trim(strings.TrimSpace(string(output)), "'")
+	if theme == "" {
+		return "hicolor"
+	}
+	return theme
+}
+
+func iconBaseDirs(dataDirs []string) []string {
+	bases := []string{filepath.Join(os.Getenv("HOME"), ".icons")}
+	for _, dir := range dataDirs {
+		bases = append(bases, filepath.Join(dir, "icons"))
+	}
+	return bases
+}
+
+func themeInherits(bases []string, theme string) []string {
+	for _, base := range bases {
+		file, err := os.Open(filepath.Join(base, theme, "index.theme"))
+		if err != nil {
+			continue
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "Inherits="); ok {
+				return splitCommaList(value)
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+func splitCommaList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// themeChain resolves inheritance breadth first, always ending in hicolor
+func themeChain(bases []string, theme string) []string {
+	var chain []string
+	seen := map[string]bool{}
+	queue := []string{theme}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if seen[name] || name == "hicolor" {
+			continue
+		}
+		seen[name] = true
+		chain = append(chain, name)
+		queue = append(queue, themeInherits(bases, name)...)
+	}
+	return append(chain, "hicolor")
+}
+
+// sizeRank prefers scalable icons, then the largest raster size
+func sizeRank(rel string) int {
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		if part == "scalable" {
+			return 10000
+		}
+		size, _, _ := strings.Cut(part, "x")
+		if n, err := strconv.Atoi(size); err == nil {
+			return n
+		}
+	}
+	return 0
+}
+
+func buildIconIndex(dataDirs []string, theme string) iconIndex {
+	bases := iconBaseDirs(dataDirs)
+	index := iconIndex{}
+	for _, name := range themeChain(bases, theme) {
+		found := map[string]iconCandidate{}
+		for _, base := range bases {
+			root := filepath.Join(base, name)
+			filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+				if err != nil {
+					if path == root {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				ext := filepath.Ext(path)
+				if entry.IsDir() || !iconExtensions[ext] {
+					return nil
+				}
+				icon := strings.TrimSuffix(entry.Name(), ext)
+				if _, done := index[icon]; done {
+					return nil
+				}
+				rel, _ := filepath.Rel(root, path)
+				rank := sizeRank(rel)
+				if current, ok := found[icon]; !ok || rank > current.rank {
+					found[icon] = iconCandidate{path: path, rank: rank}
+				}
+				return nil
+			})
+		}
+		// Fill in after the whole theme is walked so a theme only loses
+		// to an earlier theme, not to a smaller size of itself
+		for icon, candidate := range found {
+			index[icon] = candidate.path
+		}
+	}
+
+	for _, dir := range dataDirs {
+		entries, _ := os.ReadDir(filepath.Join(dir, "pixmaps"))
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			icon := strings.TrimSuffix(entry.Name(), ext)
+			if _, done := index[icon]; !done && iconExtensions[ext] {
+				index[icon] = filepath.Join(dir, "pixmaps", entry.Name())
+			}
+		}
+	}
+	return index
+}
+
+func (idx iconIndex) resolve(icon string) string {
+	if icon == "" {
+		return ""
+	}
+	if filepath.IsAbs(icon) {
+		if _, err := os.Stat(icon); err == nil {
+			return icon
+		}
+		return ""
+	}
+	return idx[icon]
+}
@@ -0,0 +1,239 @@
+package apps
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+func NewManager() (*Manager, error) {
+	m := newManager(defaultDataDirs(), defaultUsagePath())
+	m.desktops = splitDesktops(os.Getenv("XDG_CURRENT_DESKTOP"))
+	m.iconTheme = currentIconTheme()
+
+	// Walking large icon themes takes a moment, so the first index is
+	// built in the background and State.Ready flips once it is done
+	m.wg.Add(2)
+	go func() {
+		defer m.wg.Done()
+		m.Reindex()
+		m.pollLoop()
+	}()
+	go m.notifier()
+	return m, nil
+}
+
+func newManager(dataDirs []string, usagePath string) *Manager {
+	return &Manager{
+		dataDirs:     dataDirs,
+		usagePath:    usagePath,
+		iconTheme:    "hicolor",
+		usage:        loadUsage(usagePath),
+		state:        &State{},
+		pollInterval: 10 * time.Second,
+		subscribers:  make(map[string]chan State),
+		dirty:        make(chan struct{}, 1),
+		stopChan:     make(chan struct{}),
+	}
+}
+
+func splitDesktops(value string) []string {
+	var desktops []string
+	for _, desktop := range strings.Split(value, ":") {
+		if desktop != "" {
+			desktops = append(desktops, desktop)
+		}
+	}
+	return desktops
+}
+
+func (m *Manager) pollLoop() {
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			if m.dirsChanged() {
+				log.Info("apps: application directories changed, reindexing")
+				m.Reindex()
+			}
+		}
+	}
+}
+
+// scanDirMtimes records the modification time of every applications
+// directory and subdirectory; adding or removing a desktop file bumps it
+func (m *Manager) scanDirMtimes() map[string]time.Time {
+	mtimes := make(map[string]time.Time)
+	for _, dir := range applicationDirs(m.dataDirs) {
+		filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || !entry.IsDir() {
+				return nil
+			}
+			if info, err := entry.Info(); err == nil {
+				mtimes[path] = info.ModTime()
+			}
+			return nil
+		})
+	}
+	return mtimes
+}
+
+func (m *Manager) dirsChanged() bool {
+	current := m.scanDirMtimes()
+	m.appsMutex.RLock()
+	defer m.appsMutex.RUnlock()
+	if len(current) != len(m.dirMtimes) {
+		return true
+	}
+	for dir, mtime := range current {
+		if !m.dirMtimes[dir].Equal(mtime) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reindex rescans desktop entries and icons
+func (m *Manager) Reindex() {
+	mtimes := m.scanDirMtimes()
+	m.stateMutex.RLock()
+	theme := m.iconTheme
+	m.stateMutex.RUnlock()
+
+	apps := scanApps(applicationDirs(m.dataDirs), localeKeys(), m.desktops)
+	icons := buildIconIndex(m.dataDirs, theme)
+	for _, app := range apps {
+		app.IconPath = icons.resolve(app.Icon)
+		for i := range app.Actions {
+			app.Actions[i].IconPath = icons.resolve(app.Actions[i].Icon)
+		}
+	}
+
+	m.appsMutex.Lock()
+	m.apps = apps
+	m.dirMtimes = mtimes
+	m.appsMutex.Unlock()
+
+	m.stateMutex.Lock()
+	m.state = &State{
+		Ready:      true,
+		Apps:       len(apps),
+		IconTheme:  theme,
+		Generation: m.state.Generation + 1,
+		UpdatedAt:  time.Now().Unix(),
+	}
+	m.stateMutex.Unlock()
+	m.notifySubscribers()
+}
+
+// List returns every indexed app sorted by name
+func (m *Manager) List() []App {
+	m.appsMutex.RLock()
+	apps := make([]App, 0, len(m.apps))
+	for _, app := range m.apps {
+		apps = append(apps, *app)
+	}
+	m.appsMutex.RUnlock()
+
+	m.usageMutex.Lock()
+	for i := range apps {
+		if entry := m.usage[apps[i].ID]; entry != nil {
+			apps[i].Launches = entry.Count
+		}
+	}
+	m.usageMutex.Unlock()
+
+	sort.SliceStable(apps, func(i, j int) bool {
+		return strings.ToLower(apps[i].Name) < strings.ToLower(apps[j].Name)
+	})
+	return apps
+}
+
+func (m *Manager) Get(id string) (App, error) {
+	m.appsMutex.RLock()
+	defer m.appsMutex.RUnlock()
+	for _, app := range m.apps {
+		if app.ID == id {
+			return *app, nil
+		}
+	}
+	return App{}, fmt.Errorf("app not found: %s", id)
+}
+
+// RecordLaunch counts a launch of the app towards its ranking
+func (m *Manager) RecordLaunch(id string) error {
+	if _, err := m.Get(id); err != nil {
+		return err
+	}
+
+	m.usageMutex.Lock()
+	defer m.usageMutex.Unlock()
+	entry := m.usage[id]
+	if entry == nil {
+		entry = &usageEntry{}
+		m.usage[id] = entry
+	}
+	entry.Count++
+	entry.Last = time.Now().Unix()
+	return saveUsage(m.usagePath, m.usage)
+}
+
+func (m *Manager) SetIconTheme(theme string) error {
+	if theme == "" || strings.ContainsRune(theme, '/') {
+		return fmt.Errorf("invalid icon theme %q", theme)
+	}
+	m.stateMutex.Lock()
+	m.iconTheme = theme
+	m.stateMutex.Unlock()
+	m.Reindex()
+	return nil
+}
+
+func (m *Manager) notifier() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.dirty:
+			state := m.GetState()
+
+			m.subMutex.RLock()
+			if m.lastNotified != nil && !stateChanged(m.lastNotified, &state) {
+				m.subMutex.RUnlock()
+				continue
+			}
+			for _, ch := range m.subscribers {
+				select {
+				case ch <- state:
+				default:
+				}
+			}
+			m.subMutex.RUnlock()
+
+			m.lastNotified = &state
+		}
+	}
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	m.wg.Wait()
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
@@ -0,0 +1,113 @@
+package apps
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxUsageBoost caps how much launch history can add, so a frequently used
+// app never outranks an exact name match for something else
+const maxUsageBoost = 30.0
+
+func matchScore(text, query string) float64 {
+	text = strings.ToLower(text)
+	switch {
+	case text == "":
+		return 0
+	case text == query:
+		return 100
+	case strings.HasPrefix(text, query):
+		return 80
+	}
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_' || r == '.'
+	}) {
+		if strings.HasPrefix(word, query) {
+			return 60
+		}
+	}
+	if strings.Contains(text, query) {
+		return 40
+	}
+	return 0
+}
+
+// isSubsequence matches loose abbreviations such as "lbo" for LibreOffice
+func isSubsequence(text, query string) bool {
+	text = strings.ToLower(text)
+	i := 0
+	for _, r := range text {
+		if i < len(query) && rune(query[i]) == r {
+			i++
+		}
+	}
+	return i == len(query)
+}
+
+func scoreApp(app *App, query string) float64 {
+	score := matchScore(app.Name, query)
+	if s := matchScore(app.GenericName, query) * 0.6; s > score {
+		score = s
+	}
+	for _, keyword := range app.Keywords {
+		if s := matchScore(keyword, query) * 0.6; s > score {
+			score = s
+		}
+	}
+	if fields := strings.Fields(app.Command); len(fields) > 0 {
+		if s := matchScore(filepath.Base(fields[0]), query) * 0.7; s > score {
+			score = s
+		}
+	}
+	if s := matchScore(app.Comment, query) * 0.3; s > score {
+		score = s
+	}
+	if score == 0 && len(query) > 1 && isSubsequence(app.Name, query) {
+		score = 10
+	}
+	return score
+}
+
+func (m *Manager) Search(query string, limit int) []Result {
+	query = strings.ToLower(strings.TrimSpace(query))
+	now := time.Now()
+
+	m.appsMutex.RLock()
+	apps := m.apps
+	m.appsMutex.RUnlock()
+
+	m.usageMutex.Lock()
+	results := make([]Result, 0, len(apps))
+	for _, app := range apps {
+		score := 0.0
+		if query != "" {
+			if score = scoreApp(app, query); score == 0 {
+				continue
+			}
+		}
+		entry := m.usage[app.ID]
+		boost := entry.frecency(now)
+		if boost > maxUsageBoost {
+			boost = maxUsageBoost
+		}
+		result := Result{App: *app, Score: score + boost}
+		if entry != nil {
+			result.Launches = entry.Count
+		}
+		results = append(results, result)
+	}
+	m.usageMutex.Unlock()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return strings.ToLower(results[i].Name) < strings.ToLower(results[j].Name)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
@@ -0,0 +1,115 @@
+package apps
+
+import (
+	"sync"
+	"time"
+)
+
+type Action struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Exec     string `json:"exec"`
+	Icon     string `json:"icon,omitempty"`
+	IconPath string `json:"iconPath,omitempty"`
+}
+
+// App is one launchable desktop entry. ID is the desktop file ID, e.g.
+// org.gnome.Nautilus.desktop
+type App struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	GenericName string `json:"genericName,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+	Icon        string `json:"icon,omitempty"`
+	IconPath    string `json:"iconPath,omitempty"`
+	Exec        string `json:"exec"`
+	// Command is Exec without field codes such as %U
+	Command    string   `json:"command"`
+	Terminal   bool     `json:"terminal"`
+	Categories []string `json:"categories,omitempty"`
+	Keywords   []string `json:"keywords,omitempty"`
+	MimeTypes  []string `json:"mimeTypes,omitempty"`
+	Actions    []Action `json:"actions,omitempty"`
+	Path       string   `json:"path"`
+	Launches   int      `json:"launches"`
+}
+
+type Result struct {
+	App
+	Score float64 `json:"score"`
+}
+
+type State struct {
+	Ready     bool   `json:"ready"`
+	Apps      int    `json:"apps"`
+	IconTheme string `json:"iconTheme"`
+	// Generation changes whenever the index is rebuilt, so the launcher
+	// knows to refetch
+	Generation int   `json:"generation"`
+	UpdatedAt  int64 `json:"updatedAt"`
+}
+
+type Manager struct {
+	dataDirs  []string
+	usagePath string
+	desktops  []string
+	iconTheme string
+
+	apps       []*App
+	appsMutex  sync.RWMutex
+	dirMtimes  map[string]time.Time
+	usage      map[string]*usageEntry
+	usageMutex sync.Mutex
+
+	state      *State
+	stateMutex sync.RWMutex
+
+	pollInterval time.Duration
+
+	subscribers  map[string]chan State
+	subMutex     sync.RWMutex
+	dirty        chan struct{}
+	stopChan     chan struct{}
+	wg           sync.WaitGroup
+	lastNotified *State
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.state == nil {
+		return State{}
+	}
+	return *m.state
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func stateChanged(old, new *State) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	return *old != *new
+}
@@ -0,0 +1,62 @@
+package apps
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+type usageEntry struct {
+	Count int   `json:"count"`
+	Last  int64 `json:"last"`
+}
+
+func defaultUsagePath() string {
+	return filepath.Join(os.Getenv("HOME"), ".local", "state", "DankMaterialShell", "app-usage.json")
+}
+
+func loadUsage(path string) map[string]*usageEntry {
+	usage := make(map[string]*usageEntry)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return usage
+	}
+	if err := json.Unmarshal(data, &usage); err != nil {
+		return make(map[string]*usageEntry)
+	}
+	return usage
+}
+
+func saveUsage(path string, usage map[string]*usageEntry) error {
+	data, err := json.MarshalIndent(usage, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(path, data, 0644)
+}
+
+// frecency weights launch count by how recently the app was last used
+func (e *usageEntry) frecency(now time.Time) float64 {
+	if e == nil || e.Count == 0 {
+		return 0
+	}
+	age := now.Sub(time.Unix(e.Last, 0))
+	weight := 0.25
+	switch {
+	case age < 4*time.Hour:
+		weight = 4
+	case age < 24*time.Hour:
+		weight = 2
+	case age < 7*24*time.Hour:
+		weight = 1
+	case age < 30*24*time.Hour:
+		weight = 0.5
+	}
+	return float64(e.Count) * weight
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/AvengeMedia/danklinux/internal/errdefs"
 	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
 	"github.com/godbus/dbus/v5"
 )
 
@@ -69,7 +70,7 @@ type BluezAgent struct {
 }
 
 func NewBluezAgent(broker PromptBroker) (*BluezAgent, error) {
-	conn, err := dbus.ConnectSystemBus()
+	conn, err := dbus.ConnectSystemBus(budget.DBusOptions("bluetooth")...)
 	if err != nil {
 		return nil, fmt.Errorf("system bus connection failed: %w", err)
 	}
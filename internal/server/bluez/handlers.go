@@ -34,6 +34,8 @@ func HandleRequest(conn net.Conn, req Request, manager *Manager) {
 		handleStopDiscovery(conn, req, manager)
 	case "bluetooth.setPowered":
 		handleSetPowered(conn, req, manager)
+	case "bluetooth.togglePowered":
+		handleTogglePowered(conn, req, manager)
 	case "bluetooth.pair":
 		handlePairDevice(conn, req, manager)
 	case "bluetooth.connect":
@@ -93,6 +95,20 @@ func handleSetPowered(conn net.Conn, req Request, manager *Manager) {
 	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "powered state updated"})
 }
 
+func handleTogglePowered(conn net.Conn, req Request, manager *Manager) {
+	powered, err := manager.TogglePowered()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	message := "bluetooth powered off"
+	if powered {
+		message = "bluetooth powered on"
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: message})
+}
+
 func handlePairDevice(conn net.Conn, req Request, manager *Manager) {
 	devicePath, ok := req.Params["device"].(string)
 	if !ok {
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
 	"github.com/godbus/dbus/v5"
 )
 
@@ -17,7 +18,7 @@ const (
 )
 
 func NewManager() (*Manager, error) {
-	conn, err := dbus.ConnectSystemBus()
+	conn, err := dbus.ConnectSystemBus(budget.DBusOptions("bluetooth")...)
 	if err != nil {
 		return nil, fmt.Errorf("system bus connection failed: %w", err)
 	}
@@ -541,6 +542,15 @@ func (m *Manager) SetPowered(powered bool) error {
 	return obj.Call(propertiesIface+".Set", 0, adapter1Iface, "Powered", dbus.MakeVariant(powered)).Err
 }
 
+// TogglePowered flips the adapter power and returns the new state
+func (m *Manager) TogglePowered() (bool, error) {
+	powered := !m.GetState().Powered
+	if err := m.SetPowered(powered); err != nil {
+		return false, err
+	}
+	return powered, nil
+}
+
 func (m *Manager) PairDevice(devicePath string) error {
 	m.pendingPairingsMux.Lock()
 	m.pendingPairings[devicePath] = true
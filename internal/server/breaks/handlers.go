@@ -0,0 +1,115 @@
+package breaks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "breaks manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "breaks.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "breaks.setConfig":
+		handleSetConfig(conn, req, manager)
+	case "breaks.setEnabled":
+		enabled, ok := req.Params["enabled"].(bool)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'enabled' parameter")
+			return
+		}
+		manager.SetEnabled(enabled)
+		message := "break reminders disabled"
+		if enabled {
+			message = "break reminders enabled"
+		}
+		models.Respond(conn, req.ID, SuccessResult{Success: true, Message: message})
+	case "breaks.snooze":
+		snooze := snoozeDuration
+		if minutes, ok := req.Params["minutes"].(float64); ok {
+			if minutes <= 0 || minutes > 24*60 {
+				models.RespondError(conn, req.ID, "minutes must be between 0 and 1440")
+				return
+			}
+			snooze = time.Duration(minutes * float64(time.Minute))
+		}
+		manager.Snooze(snooze)
+		models.Respond(conn, req.ID, SuccessResult{Success: true, Message: fmt.Sprintf("next break in %s", snooze)})
+	case "breaks.skip":
+		manager.Skip()
+		models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "break skipped"})
+	case "breaks.now":
+		manager.TakeBreak()
+		models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "break started"})
+	case "breaks.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleSetConfig(conn net.Conn, req Request, manager *Manager) {
+	config := manager.GetConfig()
+	if enabled, ok := req.Params["enabled"].(bool); ok {
+		config.Enabled = enabled
+	}
+	ints := map[string]*int{
+		"interval":      &config.Interval,
+		"nightInterval": &config.NightInterval,
+		"duration":      &config.Duration,
+		"idleReset":     &config.IdleReset,
+	}
+	for key, dst := range ints {
+		if v, ok := req.Params[key].(float64); ok {
+			*dst = int(v)
+		}
+	}
+
+	if err := manager.SetConfig(config); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, manager.GetState())
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package breaks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+const (
+	defaultPollInterval = 5 * time.Second
+	// activeIdle is how long without input still counts as working, e.g.
+	// reading
+	activeIdle     = time.Minute
+	snoozeDuration = 5 * time.Minute
+
+	actionSnooze = "snooze"
+	actionSkip   = "skip"
+)
+
+func NewManager() (*Manager, error) {
+	notifier, err := newDBusNotifier()
+	if err != nil {
+		return nil, fmt.Errorf("notifications not available: %w", err)
+	}
+
+	m := newManager(notifier)
+	m.start()
+	return m, nil
+}
+
+func newManager(notifier Notifier) *Manager {
+	config := DefaultConfig()
+	return &Manager{
+		config:       config,
+		state:        &State{Config: config},
+		notifier:     notifier,
+		idleTime:     func() time.Duration { return 0 },
+		nightTime:    func() bool { return false },
+		now:          time.Now,
+		pollInterval: defaultPollInterval,
+		subscribers:  make(map[string]chan State),
+		dirty:        make(chan struct{}, 1),
+		stopChan:     make(chan struct{}),
+	}
+}
+
+func (m *Manager) start() {
+	m.wg.Add(3)
+	go m.notifierLoop()
+	go m.loop()
+	go m.actionLoop()
+}
+
+// SetSources registers how long the session has been idle and whether night
+// light is in its night period
+func (m *Manager) SetSources(idle func() time.Duration, night func() bool) {
+	m.tickMutex.Lock()
+	m.idleTime = idle
+	m.nightTime = night
+	m.tickMutex.Unlock()
+}
+
+func (m *Manager) loop() {
+	defer m.wg.Done()
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	m.tick()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			m.tick()
+		}
+	}
+}
+
+func (m *Manager) actionLoop() {
+	defer m.wg.Done()
+	actions := m.notifier.Actions()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case action, ok := <-actions:
+			if !ok {
+				return
+			}
+			switch action {
+			case actionSnooze:
+				m.Snooze(snoozeDuration)
+			case actionSkip:
+				m.Skip()
+			}
+		}
+	}
+}
+
+func (c Config) interval(night bool) time.Duration {
+	if night && c.NightInterval > 0 {
+		return time.Duration(c.NightInterval) * time.Minute
+	}
+	return time.Duration(c.Interval) * time.Minute
+}
+
+// tick counts active time towards the next break. Being away for IdleReset,
+// including a suspend that long, counts as a break
+func (m *Manager) tick() {
+	m.tickMutex.Lock()
+	defer m.tickMutex.Unlock()
+
+	now := m.now()
+	config := m.GetConfig()
+	idleReset := time.Duration(config.IdleReset) * time.Second
+
+	var elapsed time.Duration
+	if !m.lastTick.IsZero() {
+		elapsed = now.Sub(m.lastTick)
+	}
+	m.lastTick = now
+
+	idle := m.idleTime()
+	night := m.nightTime()
+	away := idle >= idleReset || elapsed >= idleReset
+	onBreak := now.Before(m.breakUntil)
+
+	switch {
+	case !config.Enabled, away:
+		m.worked = 0
+	case idle < activeIdle && !onBreak:
+		m.worked += elapsed
+	}
+
+	interval := config.interval(night)
+	if config.Enabled && m.worked >= interval {
+		m.worked = 0
+		m.breakUntil = now.Add(time.Duration(config.Duration) * time.Second)
+		m.lastBreak = now
+		onBreak = true
+		m.remind(config)
+	}
+
+	m.stateMutex.Lock()
+	m.state = &State{
+		Config:    config,
+		Worked:    int(m.worked.Seconds()),
+		NextBreak: int((interval - m.worked).Seconds()),
+		Night:     night,
+		Away:      away,
+		OnBreak:   onBreak,
+		LastBreak: m.lastBreak,
+	}
+	m.stateMutex.Unlock()
+
+	m.notifySubscribers()
+}
+
+func (m *Manager) remind(config Config) {
+	body := fmt.Sprintf("Look away from the screen and rest your eyes for %s", formatSeconds(config.Duration))
+	if err := m.notifier.Notify("Time for a break", body, time.Duration(config.Duration)*time.Second); err != nil {
+		log.Warnf("breaks: failed to show reminder: %v", err)
+	}
+}
+
+func formatSeconds(seconds int) string {
+	switch {
+	case seconds < 60:
+		return fmt.Sprintf("%d seconds", seconds)
+	case seconds == 60:
+		return "a minute"
+	case seconds%60 == 0:
+		return fmt.Sprintf("%d minutes", seconds/60)
+	}
+	return fmt.Sprintf("%dm%ds", seconds/60, seconds%60)
+}
+
+func (m *Manager) GetConfig() Config {
+	m.configMutex.RLock()
+	defer m.configMutex.RUnlock()
+	return m.config
+}
+
+func (m *Manager) SetConfig(config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	m.configMutex.Lock()
+	m.config = config
+	m.configMutex.Unlock()
+
+	m.tick()
+	return nil
+}
+
+func (m *Manager) SetEnabled(enabled bool) {
+	m.configMutex.Lock()
+	m.config.Enabled = enabled
+	m.configMutex.Unlock()
+
+	m.tick()
+}
+
+// Snooze moves the next reminder to d of active time from now
+func (m *Manager) Snooze(d time.Duration) {
+	m.tickMutex.Lock()
+	interval := m.GetConfig().interval(m.nightTime())
+	m.worked = max(interval-d, 0)
+	m.breakUntil = time.Time{}
+	m.tickMutex.Unlock()
+
+	m.tick()
+}
+
+// Skip starts counting towards the next break from zero
+func (m *Manager) Skip() {
+	m.tickMutex.Lock()
+	m.worked = 0
+	m.breakUntil = time.Time{}
+	m.tickMutex.Unlock()
+
+	m.tick()
+}
+
+// TakeBreak shows the reminder now
+func (m *Manager) TakeBreak() {
+	m.tickMutex.Lock()
+	m.worked = m.GetConfig().interval(m.nightTime())
+	m.tickMutex.Unlock()
+
+	m.tick()
+}
+
+func (m *Manager) notifierLoop() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.dirty:
+			state := m.GetState()
+
+			m.subMutex.RLock()
+			if m.lastNotified != nil && !stateChanged(m.lastNotified, &state) {
+				m.subMutex.RUnlock()
+				continue
+			}
+			for _, ch := range m.subscribers {
+				select {
+				case ch <- state:
+				default:
+				}
+			}
+			m.subMutex.RUnlock()
+
+			m.lastNotified = &state
+		}
+	}
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	m.wg.Wait()
+	m.notifier.Close()
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
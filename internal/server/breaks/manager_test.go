@@ -0,0 +1,150 @@
+package breaks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeNotifier struct {
+	shown []string
+}
+
+func (n *fakeNotifier) Notify(summary, body string, timeout time.Duration) error {
+	n.shown = append(n.shown, summary)
+	return nil
+}
+
+func (n *fakeNotifier) Actions() <-chan string { return nil }
+func (n *fakeNotifier) Close()                 {}
+
+type clock struct {
+	now   time.Time
+	idle  time.Duration
+	night bool
+}
+
+func newTestManager(t *testing.T, config Config) (*Manager, *fakeNotifier, *clock) {
+	t.Helper()
+	n := &fakeNotifier{}
+	m := newManager(n)
+	require.NoError(t, config.Validate())
+	m.config = config
+
+	c := &clock{now: time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)}
+	m.now = func() time.Time { return c.now }
+	m.SetSources(func() time.Duration { return c.idle }, func() bool { return c.night })
+	m.tick()
+	return m, n, c
+}
+
+func (c *clock) advance(m *Manager, d time.Duration) {
+	for step := time.Duration(0); step < d; step += 5 * time.Second {
+		c.now = c.now.Add(5 * time.Second)
+		m.tick()
+	}
+}
+
+func testConfig() Config {
+	return Config{Enabled: true, Interval: 20, Duration: 20, IdleReset: 300}
+}
+
+func TestRemindsAfterInterval(t *testing.T) {
+	m, n, c := newTestManager(t, testConfig())
+
+	c.advance(m, 19*time.Minute)
+	assert.Empty(t, n.shown)
+	assert.Equal(t, 60, m.GetState().NextBreak)
+
+	c.advance(m, time.Minute)
+	require.Len(t, n.shown, 1)
+	state := m.GetState()
+	assert.True(t, state.OnBreak)
+	assert.Equal(t, c.now, state.LastBreak)
+	assert.Equal(t, 0, state.Worked)
+
+	c.advance(m, 30*time.Second)
+	assert.False(t, m.GetState().OnBreak)
+}
+
+func TestAwayCountsAsBreak(t *testing.T) {
+	m, n, c := newTestManager(t, testConfig())
+
+	c.advance(m, 15*time.Minute)
+	c.idle = 5 * time.Minute
+	c.advance(m, 5*time.Second)
+	assert.True(t, m.GetState().Away)
+	assert.Equal(t, 0, m.GetState().Worked)
+
+	c.idle = 0
+	c.advance(m, 15*time.Minute)
+	assert.Empty(t, n.shown)
+}
+
+func TestShortIdleDoesNotCount(t *testing.T) {
+	m, _, c := newTestManager(t, testConfig())
+
+	c.idle = 2 * time.Minute
+	c.advance(m, time.Minute)
+	state := m.GetState()
+	assert.False(t, state.Away)
+	assert.Equal(t, 0, state.Worked)
+}
+
+func TestSuspendCountsAsBreak(t *testing.T) {
+	m, _, c := newTestManager(t, testConfig())
+
+	c.advance(m, 10*time.Minute)
+	c.now = c.now.Add(time.Hour)
+	m.tick()
+	assert.Equal(t, 0, m.GetState().Worked)
+}
+
+func TestNightInterval(t *testing.T) {
+	config := testConfig()
+	config.NightInterval = 10
+	m, n, c := newTestManager(t, config)
+
+	c.night = true
+	c.advance(m, 10*time.Minute)
+	assert.Len(t, n.shown, 1)
+	assert.True(t, m.GetState().Night)
+}
+
+func TestDisabled(t *testing.T) {
+	config := testConfig()
+	config.Enabled = false
+	m, n, c := newTestManager(t, config)
+
+	c.advance(m, time.Hour)
+	assert.Empty(t, n.shown)
+	assert.Equal(t, 0, m.GetState().Worked)
+}
+
+func TestSnoozeAndSkip(t *testing.T) {
+	m, _, c := newTestManager(t, testConfig())
+
+	c.advance(m, 10*time.Minute)
+	m.Snooze(2 * time.Minute)
+	assert.InDelta(t, 120, m.GetState().NextBreak, 1)
+
+	m.Skip()
+	assert.InDelta(t, 20*60, m.GetState().NextBreak, 1)
+}
+
+func TestConfigValidate(t *testing.T) {
+	config := DefaultConfig()
+	assert.NoError(t, config.Validate())
+
+	bad := []Config{
+		{Interval: 0, Duration: 20, IdleReset: 300},
+		{Interval: 20, NightInterval: -1, Duration: 20, IdleReset: 300},
+		{Interval: 20, Duration: 0, IdleReset: 300},
+		{Interval: 20, Duration: 20, IdleReset: 10},
+	}
+	for _, c := range bad {
+		assert.Error(t, c.Validate())
+	}
+}
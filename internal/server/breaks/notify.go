@@ -0,0 +1,113 @@
+package breaks
+
+import (
+	"sync"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	notifyDest  = "org.freedesktop.Notifications"
+	notifyPath  = "/org/freedesktop/Notifications"
+	notifyIface = "org.freedesktop.Notifications"
+	notifyApp   = "DankMaterialShell"
+	notifyIcon  = "preferences-desktop-screensaver"
+)
+
+type dbusNotifier struct {
+	conn    *dbus.Conn
+	signals chan *dbus.Signal
+	actions chan string
+	stop    chan struct{}
+
+	mu sync.Mutex
+	// id is the last reminder, replaced by the next so only one is shown
+	id uint32
+}
+
+func newDBusNotifier() (*dbusNotifier, error) {
+	conn, err := dbus.ConnectSessionBus(budget.DBusOptions("breaks")...)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(notifyPath),
+		dbus.WithMatchInterface(notifyIface),
+		dbus.WithMatchMember("ActionInvoked"),
+	); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	n := &dbusNotifier{
+		conn:    conn,
+		signals: make(chan *dbus.Signal, 16),
+		actions: make(chan string, 1),
+		stop:    make(chan struct{}),
+	}
+	conn.Signal(n.signals)
+
+	go n.pump()
+	return n, nil
+}
+
+func (n *dbusNotifier) Notify(summary, body string, timeout time.Duration) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	actions := []string{actionSnooze, "Snooze 5 min", actionSkip, "Skip"}
+	hints := map[string]dbus.Variant{
+		"category": dbus.MakeVariant("reminder"),
+	}
+
+	var id uint32
+	err := n.conn.Object(notifyDest, notifyPath).Call(notifyIface+".Notify", 0,
+		notifyApp, n.id, notifyIcon, summary, body, actions, hints, int32(timeout.Milliseconds())).Store(&id)
+	if err != nil {
+		return err
+	}
+	n.id = id
+	return nil
+}
+
+func (n *dbusNotifier) pump() {
+	for {
+		select {
+		case <-n.stop:
+			return
+		case sig, ok := <-n.signals:
+			if !ok {
+				return
+			}
+			if sig == nil || len(sig.Body) < 2 {
+				continue
+			}
+			id, _ := sig.Body[0].(uint32)
+			action, _ := sig.Body[1].(string)
+
+			n.mu.Lock()
+			ours := id != 0 && id == n.id
+			n.mu.Unlock()
+			if !ours {
+				continue
+			}
+
+			select {
+			case n.actions <- action:
+			default:
+			}
+		}
+	}
+}
+
+func (n *dbusNotifier) Actions() <-chan string {
+	return n.actions
+}
+
+func (n *dbusNotifier) Close() {
+	close(n.stop)
+	n.conn.RemoveSignal(n.signals)
+	n.conn.Close()
+}
@@ -0,0 +1,134 @@
+package breaks
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Config is in minutes for intervals and seconds for durations
+type Config struct {
+	Enabled bool `json:"enabled"`
+	// Interval is the active time between breaks
+	Interval int `json:"interval"`
+	// NightInterval replaces Interval while night light is in its night
+	// period; 0 keeps Interval
+	NightInterval int `json:"nightInterval"`
+	// Duration is how long a break should last
+	Duration int `json:"duration"`
+	// IdleReset is how long being away counts as having taken a break
+	IdleReset int `json:"idleReset"`
+}
+
+type State struct {
+	Config Config `json:"config"`
+	// Worked is the active time since the last break, in seconds
+	Worked int `json:"worked"`
+	// NextBreak is the active time left until the next reminder, in seconds
+	NextBreak int  `json:"nextBreak"`
+	Night     bool `json:"night"`
+	Away      bool `json:"away"`
+	// OnBreak is set from a reminder until the break's duration has passed
+	OnBreak   bool      `json:"onBreak"`
+	LastBreak time.Time `json:"lastBreak,omitempty"`
+}
+
+// Notifier shows reminders through the notification daemon
+type Notifier interface {
+	Notify(summary, body string, timeout time.Duration) error
+	// Actions delivers the action keys the user picks on a reminder
+	Actions() <-chan string
+	Close()
+}
+
+type Manager struct {
+	config      Config
+	configMutex sync.RWMutex
+	state       *State
+	stateMutex  sync.RWMutex
+
+	notifier  Notifier
+	idleTime  func() time.Duration
+	nightTime func() bool
+	now       func() time.Time
+
+	tickMutex  sync.Mutex
+	worked     time.Duration
+	lastTick   time.Time
+	breakUntil time.Time
+	lastBreak  time.Time
+
+	pollInterval time.Duration
+	subscribers  map[string]chan State
+	subMutex     sync.RWMutex
+	dirty        chan struct{}
+	stopChan     chan struct{}
+	wg           sync.WaitGroup
+	lastNotified *State
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Enabled:   false,
+		Interval:  20,
+		Duration:  20,
+		IdleReset: 5 * 60,
+	}
+}
+
+func (c *Config) Validate() error {
+	if c.Interval < 1 || c.Interval > 24*60 {
+		return fmt.Errorf("interval must be between 1 and 1440 minutes")
+	}
+	if c.NightInterval < 0 || c.NightInterval > 24*60 {
+		return fmt.Errorf("nightInterval must be between 0 and 1440 minutes")
+	}
+	if c.Duration < 1 || c.Duration > 60*60 {
+		return fmt.Errorf("duration must be between 1 and 3600 seconds")
+	}
+	if c.IdleReset < 30 {
+		return fmt.Errorf("idleReset must be at least 30 seconds")
+	}
+	return nil
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.state == nil {
+		return State{}
+	}
+	return *m.state
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func stateChanged(old, new *State) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	return !reflect.DeepEqual(old, new)
+}
@@ -0,0 +1,333 @@
+package budget
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	sampleInterval = 5 * time.Second
+	// throttleHold keeps the daemon throttled for a while after the last
+	// sample over a limit, so a busy moment doesn't flap it on and off
+	throttleHold = time.Minute
+	// throttleFactor stretches poll intervals while throttled
+	throttleFactor = 4
+	powerSupplyDir = "/sys/class/power_supply"
+)
+
+// Limits are soft: going over one throttles the daemon, nothing is refused.
+// CallsPerSec applies to each module's D-Bus traffic unless ModuleCalls
+// overrides it
+type Limits struct {
+	Goroutines  int                `json:"goroutines"`
+	MemoryMB    float64            `json:"memoryMB"`
+	CallsPerSec float64            `json:"callsPerSec"`
+	ModuleCalls map[string]float64 `json:"moduleCalls,omitempty"`
+}
+
+func DefaultLimits() Limits {
+	return Limits{
+		Goroutines:  500,
+		MemoryMB:    150,
+		CallsPerSec: 50,
+		// NetworkManager floods property changes while scanning
+		ModuleCalls: map[string]float64{"network": 200},
+	}
+}
+
+func (l Limits) callLimit(module string) float64 {
+	if limit, ok := l.ModuleCalls[module]; ok {
+		return limit
+	}
+	return l.CallsPerSec
+}
+
+// ModuleUsage is one module's D-Bus traffic: method calls it made and
+// signals it received, in total and per second over the last sample
+type ModuleUsage struct {
+	Module        string  `json:"module"`
+	Calls         uint64  `json:"calls"`
+	Signals       uint64  `json:"signals"`
+	CallsPerSec   float64 `json:"callsPerSec"`
+	SignalsPerSec float64 `json:"signalsPerSec"`
+	Limit         float64 `json:"limit"`
+	OverLimit     bool    `json:"overLimit"`
+}
+
+// Report is the daemon's resource use. Goroutines and memory are process
+// wide, Go has no per-module accounting for them
+type Report struct {
+	Throttled  bool          `json:"throttled"`
+	Reasons    []string      `json:"reasons"`
+	OnBattery  bool          `json:"onBattery"`
+	Goroutines int           `json:"goroutines"`
+	HeapMB     float64       `json:"heapMB"`
+	SysMB      float64       `json:"sysMB"`
+	Modules    []ModuleUsage `json:"modules"`
+	Limits     Limits        `json:"limits"`
+	SampledAt  time.Time     `json:"sampledAt"`
+}
+
+type counter struct {
+	calls   atomic.Uint64
+	signals atomic.Uint64
+}
+
+type Tracker struct {
+	limits         Limits
+	mutex          sync.Mutex
+	counters       map[string]*counter
+	previous       map[string][2]uint64
+	lastSample     time.Time
+	report         Report
+	throttled      atomic.Bool
+	throttledUntil time.Time
+	onBattery      func() bool
+	goroutines     func() int
+	memory         func() (heapMB, sysMB float64)
+	stopChan       chan struct{}
+	wg             sync.WaitGroup
+}
+
+func NewTracker(limits Limits) *Tracker {
+	return &Tracker{
+		limits:     limits,
+		counters:   make(map[string]*counter),
+		previous:   make(map[string][2]uint64),
+		onBattery:  func() bool { return onBatteryAt(powerSupplyDir) },
+		goroutines: runtime.NumGoroutine,
+		memory:     readMemory,
+	}
+}
+
+func readMemory() (float64, float64) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return float64(stats.HeapAlloc) / (1 << 20), float64(stats.Sys) / (1 << 20)
+}
+
+func (t *Tracker) counterFor(module string) *counter {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	c, ok := t.counters[module]
+	if !ok {
+		c = &counter{}
+		t.counters[module] = c
+	}
+	return c
+}
+
+// DBusOptions count a module's traffic on a connection it opens
+func (t *Tracker) DBusOptions(module string) []dbus.ConnOption {
+	c := t.counterFor(module)
+	return []dbus.ConnOption{
+		dbus.WithOutgoingInterceptor(func(msg *dbus.Message) {
+			if msg.Type == dbus.TypeMethodCall {
+				c.calls.Add(1)
+			}
+		}),
+		dbus.WithIncomingInterceptor(func(msg *dbus.Message) {
+			if msg.Type == dbus.TypeSignal {
+				c.signals.Add(1)
+			}
+		}),
+	}
+}
+
+// Sample measures resource use since the previous sample and decides
+// whether the daemon should throttle
+func (t *Tracker) Sample(now time.Time) Report {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var elapsed float64
+	if !t.lastSample.IsZero() {
+		elapsed = now.Sub(t.lastSample).Seconds()
+	}
+
+	report := Report{
+		OnBattery:  t.onBattery(),
+		Goroutines: t.goroutines(),
+		Limits:     t.limits,
+		SampledAt:  now,
+		Reasons:    []string{},
+		Modules:    make([]ModuleUsage, 0, len(t.counters)),
+	}
+	report.HeapMB, report.SysMB = t.memory()
+
+	overLimit := false
+	if report.Goroutines > t.limits.Goroutines {
+		overLimit = true
+		report.Reasons = append(report.Reasons, fmt.Sprintf("%d goroutines over the limit of %d", report.Goroutines, t.limits.Goroutines))
+	}
+	if report.HeapMB > t.limits.MemoryMB {
+		overLimit = true
+		report.Reasons = append(report.Reasons, fmt.Sprintf("%.0f MB heap over the limit of %.0f MB", report.HeapMB, t.limits.MemoryMB))
+	}
+
+	for module, c := range t.counters {
+		calls, signals := c.calls.Load(), c.signals.Load()
+		usage := ModuleUsage{
+			Module:  module,
+			Calls:   calls,
+			Signals: signals,
+			Limit:   t.limits.callLimit(module),
+		}
+		if elapsed > 0 {
+			prev := t.previous[module]
+			usage.CallsPerSec = float64(calls-prev[0]) / elapsed
+			usage.SignalsPerSec = float64(signals-prev[1]) / elapsed
+		}
+		if usage.CallsPerSec+usage.SignalsPerSec > usage.Limit {
+			usage.OverLimit = true
+			overLimit = true
+		}
+		t.previous[module] = [2]uint64{calls, signals}
+		report.Modules = append(report.Modules, usage)
+	}
+	sort.Slice(report.Modules, func(i, j int) bool { return report.Modules[i].Module < report.Modules[j].Module })
+	for _, usage := range report.Modules {
+		if usage.OverLimit {
+			report.Reasons = append(report.Reasons, fmt.Sprintf("%s at %.0f D-Bus messages/s over the limit of %.0f", usage.Module, usage.CallsPerSec+usage.SignalsPerSec, usage.Limit))
+		}
+	}
+
+	if overLimit {
+		t.throttledUntil = now.Add(throttleHold)
+	} else if now.Before(t.throttledUntil) {
+		report.Reasons = append(report.Reasons, "recently over a limit")
+	}
+	if report.OnBattery {
+		report.Reasons = append([]string{"on battery"}, report.Reasons...)
+	}
+	report.Throttled = report.OnBattery || now.Before(t.throttledUntil)
+
+	if report.Throttled != t.throttled.Load() {
+		if report.Throttled {
+			log.Infof("budget: throttling (%s)", strings.Join(report.Reasons, ", "))
+		} else {
+			log.Info("budget: no longer throttling")
+		}
+	}
+	t.throttled.Store(report.Throttled)
+	t.lastSample = now
+	t.report = report
+	return report
+}
+
+func (t *Tracker) Report() Report {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.report
+}
+
+func (t *Tracker) Throttled() bool {
+	return t.throttled.Load()
+}
+
+// Scale stretches a poll interval while the daemon is throttled
+func (t *Tracker) Scale(d time.Duration) time.Duration {
+	if t.Throttled() {
+		return d * throttleFactor
+	}
+	return d
+}
+
+func (t *Tracker) Start() {
+	t.mutex.Lock()
+	if t.stopChan != nil {
+		t.mutex.Unlock()
+		return
+	}
+	stopChan := make(chan struct{})
+	t.stopChan = stopChan
+	t.mutex.Unlock()
+
+	t.Sample(time.Now())
+	t.wg.Add(1)
+	go t.sampleLoop(stopChan)
+}
+
+func (t *Tracker) sampleLoop(stopChan chan struct{}) {
+	defer t.wg.Done()
+	ticker := time.NewTicker(sampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopChan:
+			return
+		case now := <-ticker.C:
+			t.Sample(now)
+		}
+	}
+}
+
+func (t *Tracker) Stop() {
+	t.mutex.Lock()
+	stopChan := t.stopChan
+	t.stopChan = nil
+	t.mutex.Unlock()
+	if stopChan == nil {
+		return
+	}
+	close(stopChan)
+	t.wg.Wait()
+}
+
+// onBatteryAt treats the machine as on battery when it has a battery and no
+// online mains supply, so desktops never throttle for power
+func onBatteryAt(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+
+	hasBattery := false
+	for _, entry := range entries {
+		switch readSupplyFile(dir, entry.Name(), "type") {
+		case "Battery":
+			if readSupplyFile(dir, entry.Name(), "scope") != "Device" {
+				hasBattery = true
+			}
+		case "Mains", "USB":
+			if readSupplyFile(dir, entry.Name(), "online") == "1" {
+				return false
+			}
+		}
+	}
+	return hasBattery
+}
+
+func readSupplyFile(dir, supply, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, supply, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+var defaultTracker = NewTracker(DefaultLimits())
+
+// Start samples the daemon's resource use until Stop
+func Start() { defaultTracker.Start() }
+
+func Stop() { defaultTracker.Stop() }
+
+func DBusOptions(module string) []dbus.ConnOption { return defaultTracker.DBusOptions(module) }
+
+func Throttled() bool { return defaultTracker.Throttled() }
+
+func Scale(d time.Duration) time.Duration { return defaultTracker.Scale(d) }
+
+func Health() Report { return defaultTracker.Report() }
@@ -0,0 +1,96 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProcess struct {
+	battery    bool
+	goroutines int
+	heapMB     float64
+}
+
+func newTestTracker(p *fakeProcess) *Tracker {
+	t := NewTracker(DefaultLimits())
+	t.onBattery = func() bool { return p.battery }
+	t.goroutines = func() int { return p.goroutines }
+	t.memory = func() (float64, float64) { return p.heapMB, p.heapMB * 2 }
+	return t
+}
+
+func TestSample_WithinLimits(t *testing.T) {
+	p := &fakeProcess{goroutines: 40, heapMB: 20}
+	tracker := newTestTracker(p)
+
+	report := tracker.Sample(time.Now())
+	assert.False(t, report.Throttled)
+	assert.Empty(t, report.Reasons)
+	assert.Equal(t, 40, report.Goroutines)
+	assert.Equal(t, time.Second, tracker.Scale(time.Second))
+}
+
+func TestSample_ThrottlesOnBattery(t *testing.T) {
+	p := &fakeProcess{goroutines: 40, heapMB: 20, battery: true}
+	tracker := newTestTracker(p)
+	now := time.Now()
+
+	report := tracker.Sample(now)
+	assert.True(t, report.Throttled)
+	assert.Equal(t, []string{"on battery"}, report.Reasons)
+	assert.Equal(t, 4*time.Second, tracker.Scale(time.Second))
+
+	p.battery = false
+	assert.False(t, tracker.Sample(now.Add(sampleInterval)).Throttled)
+}
+
+func TestSample_ModuleCallRate(t *testing.T) {
+	p := &fakeProcess{goroutines: 40, heapMB: 20}
+	tracker := newTestTracker(p)
+	now := time.Now()
+	tracker.Sample(now)
+
+	c := tracker.counterFor("bluetooth")
+	c.calls.Add(400)
+	c.signals.Add(100)
+	tracker.counterFor("network").calls.Add(500)
+
+	report := tracker.Sample(now.Add(5 * time.Second))
+	require.Len(t, report.Modules, 2)
+	assert.Equal(t, "bluetooth", report.Modules[0].Module)
+	assert.InDelta(t, 80, report.Modules[0].CallsPerSec, 0.01)
+	assert.InDelta(t, 20, report.Modules[0].SignalsPerSec, 0.01)
+	assert.True(t, report.Modules[0].OverLimit)
+	assert.False(t, report.Modules[1].OverLimit, "network has a higher limit")
+	assert.True(t, report.Throttled)
+	require.Len(t, report.Reasons, 1)
+	assert.Contains(t, report.Reasons[0], "bluetooth")
+}
+
+func TestSample_HoldsThrottleAfterLimit(t *testing.T) {
+	p := &fakeProcess{goroutines: 900, heapMB: 20}
+	tracker := newTestTracker(p)
+	now := time.Now()
+
+	assert.True(t, tracker.Sample(now).Throttled)
+
+	p.goroutines = 40
+	report := tracker.Sample(now.Add(30 * time.Second))
+	assert.True(t, report.Throttled)
+	assert.Equal(t, []string{"recently over a limit"}, report.Reasons)
+
+	assert.False(t, tracker.Sample(now.Add(throttleHold+time.Second)).Throttled)
+}
+
+func TestSample_Memory(t *testing.T) {
+	p := &fakeProcess{goroutines: 40, heapMB: 400}
+	tracker := newTestTracker(p)
+
+	report := tracker.Sample(time.Now())
+	assert.True(t, report.Throttled)
+	assert.Contains(t, report.Reasons[0], "heap")
+	assert.Equal(t, report, tracker.Report())
+}
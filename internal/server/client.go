@@ -0,0 +1,81 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+// FindRunningSocket returns the socket of a live dms server, so CLI commands
+// can reach the instance started by dms run
+func FindRunningSocket() (string, error) {
+	dir := getSocketDir()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, "danklinux-") || !strings.HasSuffix(name, ".sock") {
+			continue
+		}
+
+		pid, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "danklinux-"), ".sock"))
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		if syscall.Kill(pid, 0) != nil {
+			continue
+		}
+		return filepath.Join(dir, name), nil
+	}
+
+	return "", fmt.Errorf("no running dms server found in %s (is 'dms run' active?)", dir)
+}
+
+// Call sends one request to the running dms server and returns its raw result
+func Call(method string, params map[string]interface{}) (json.RawMessage, error) {
+	socketPath, err := FindRunningSocket()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialTimeout("unix", socketPath, 2*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", socketPath, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	reader := bufio.NewReader(conn)
+	// The server greets every connection with its capabilities
+	if _, err := reader.ReadBytes('\n'); err != nil {
+		return nil, fmt.Errorf("failed to read server greeting: %w", err)
+	}
+
+	if err := json.NewEncoder(conn).Encode(models.Request{ID: 1, Method: method, Params: params}); err != nil {
+		return nil, err
+	}
+
+	var resp models.Response[json.RawMessage]
+	if err := json.NewDecoder(reader).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("%s", resp.Error)
+	}
+	if resp.Result == nil {
+		return nil, nil
+	}
+	return *resp.Result, nil
+}
@@ -39,6 +39,10 @@ func HandleRequest(conn net.Conn, req Request, manager *Manager) {
 		handleGetColorScheme(conn, req, manager)
 	case "freedesktop.settings.setIconTheme":
 		handleSetIconTheme(conn, req, manager)
+	case "freedesktop.system.getInfo":
+		models.Respond(conn, req.ID, manager.GetSystemInfo())
+	case "freedesktop.system.setHostname":
+		handleSetHostname(conn, req, manager)
 	default:
 		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
 	}
@@ -164,3 +168,19 @@ func handleSetIconTheme(conn net.Conn, req Request, manager *Manager) {
 
 	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "icon theme set"})
 }
+
+func handleSetHostname(conn net.Conn, req Request, manager *Manager) {
+	hostname, ok := req.Params["hostname"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'hostname' parameter")
+		return
+	}
+	pretty, _ := req.Params["pretty"].(string)
+
+	if err := manager.SetHostname(hostname, pretty); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "hostname updated", Value: hostname})
+}
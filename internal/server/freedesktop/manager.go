@@ -6,16 +6,17 @@ import (
 	"os"
 	"sync"
 
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
 	"github.com/godbus/dbus/v5"
 )
 
 func NewManager() (*Manager, error) {
-	systemConn, err := dbus.ConnectSystemBus()
+	systemConn, err := dbus.ConnectSystemBus(budget.DBusOptions("freedesktop")...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to system bus: %w", err)
 	}
 
-	sessionConn, err := dbus.ConnectSessionBus()
+	sessionConn, err := dbus.ConnectSessionBus(budget.DBusOptions("freedesktop")...)
 	if err != nil {
 		sessionConn = nil
 	}
@@ -0,0 +1,214 @@
+package freedesktop
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/version"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	dbusHostnameDest      = "org.freedesktop.hostname1"
+	dbusHostnamePath      = "/org/freedesktop/hostname1"
+	dbusHostnameInterface = "org.freedesktop.hostname1"
+)
+
+// Versions of the DMS components, empty when a component isn't found
+type ComponentVersions struct {
+	Shell      string `json:"shell"`
+	Backend    string `json:"backend"`
+	APIVersion int    `json:"apiVersion"`
+	Quickshell string `json:"quickshell"`
+}
+
+type SystemInfo struct {
+	Hostname       string            `json:"hostname"`
+	PrettyHostname string            `json:"prettyHostname"`
+	Vendor         string            `json:"vendor"`
+	Model          string            `json:"model"`
+	Chassis        string            `json:"chassis"`
+	OS             string            `json:"os"`
+	Kernel         string            `json:"kernel"`
+	CPU            string            `json:"cpu"`
+	MemoryTotal    uint64            `json:"memoryTotal"`
+	Uptime         float64           `json:"uptime"`
+	BootTime       int64             `json:"bootTime"`
+	Versions       ComponentVersions `json:"versions"`
+}
+
+// The roots are swapped out in tests
+var (
+	procRoot = "/proc"
+	sysRoot  = "/sys"
+	etcRoot  = "/etc"
+)
+
+func readTrimmed(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// SetBackendVersion records the dms binary version reported by system info
+func (m *Manager) SetBackendVersion(backend string, apiVersion int) {
+	m.stateMutex.Lock()
+	m.backendVersion = backend
+	m.apiVersion = apiVersion
+	m.stateMutex.Unlock()
+}
+
+// GetSystemInfo reads hostnamed when it's running and falls back to
+// /proc, /sys and /etc for the rest
+func (m *Manager) GetSystemInfo() SystemInfo {
+	info := SystemInfo{}
+	m.readHostnamed(&info)
+
+	if info.Hostname == "" {
+		info.Hostname, _ = os.Hostname()
+	}
+	if info.Vendor == "" {
+		info.Vendor = readTrimmed(sysRoot + "/class/dmi/id/sys_vendor")
+	}
+	if info.Model == "" {
+		info.Model = readTrimmed(sysRoot + "/class/dmi/id/product_name")
+	}
+	if info.OS == "" {
+		info.OS = osPrettyName(etcRoot + "/os-release")
+	}
+	if info.Kernel == "" {
+		info.Kernel = readTrimmed(procRoot + "/sys/kernel/osrelease")
+	}
+	info.CPU = cpuModel(procRoot + "/cpuinfo")
+	info.MemoryTotal = memTotal(procRoot + "/meminfo")
+	info.Uptime = uptime(procRoot + "/uptime")
+	if info.Uptime > 0 {
+		info.BootTime = time.Now().Add(-time.Duration(info.Uptime * float64(time.Second))).Unix()
+	}
+
+	m.stateMutex.RLock()
+	info.Versions.Backend = m.backendVersion
+	info.Versions.APIVersion = m.apiVersion
+	m.stateMutex.RUnlock()
+	info.Versions.Shell, _ = version.GetCurrentDMSVersion()
+	info.Versions.Quickshell, _ = version.GetQuickshellVersion()
+	return info
+}
+
+func (m *Manager) readHostnamed(info *SystemInfo) {
+	if m.systemConn == nil {
+		return
+	}
+
+	var props map[string]dbus.Variant
+	obj := m.systemConn.Object(dbusHostnameDest, dbus.ObjectPath(dbusHostnamePath))
+	if err := obj.Call(dbusPropsInterface+".GetAll", 0, dbusHostnameInterface).Store(&props); err != nil {
+		return
+	}
+
+	str := func(key string) string {
+		s, _ := props[key].Value().(string)
+		return s
+	}
+	info.Hostname = str("Hostname")
+	info.PrettyHostname = str("PrettyHostname")
+	info.Vendor = str("HardwareVendor")
+	info.Model = str("HardwareModel")
+	info.Chassis = str("Chassis")
+	info.OS = str("OperatingSystemPrettyName")
+	if release := str("KernelRelease"); release != "" {
+		info.Kernel = release
+	}
+}
+
+var hostnameRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,62}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,62}[a-zA-Z0-9])?)*$`)
+
+// SetHostname changes the static hostname, and the pretty one when given,
+// through hostnamed so polkit can ask for authentication
+func (m *Manager) SetHostname(hostname, pretty string) error {
+	if len(hostname) > 64 || !hostnameRegex.MatchString(hostname) {
+		return fmt.Errorf("invalid hostname %q", hostname)
+	}
+	if m.systemConn == nil {
+		return fmt.Errorf("no system bus connection")
+	}
+
+	obj := m.systemConn.Object(dbusHostnameDest, dbus.ObjectPath(dbusHostnamePath))
+	if err := obj.Call(dbusHostnameInterface+".SetStaticHostname", dbus.FlagAllowInteractiveAuthorization, hostname, true).Err; err != nil {
+		return fmt.Errorf("failed to set hostname: %w", err)
+	}
+	if pretty != "" {
+		if err := obj.Call(dbusHostnameInterface+".SetPrettyHostname", dbus.FlagAllowInteractiveAuthorization, pretty, true).Err; err != nil {
+			return fmt.Errorf("failed to set pretty hostname: %w", err)
+		}
+	}
+	return nil
+}
+
+func osPrettyName(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "PRETTY_NAME="); ok {
+			return strings.Trim(value, `"'`)
+		}
+	}
+	return ""
+}
+
+func cpuModel(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if ok && strings.TrimSpace(key) == "model name" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// memTotal returns MemTotal in bytes
+func memTotal(path string) uint64 {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kb, _ := strconv.ParseUint(fields[1], 10, 64)
+			return kb * 1024
+		}
+	}
+	return 0
+}
+
+func uptime(path string) float64 {
+	fields := strings.Fields(readTrimmed(path))
+	if len(fields) == 0 {
+		return 0
+	}
+	seconds, _ := strconv.ParseFloat(fields[0], 64)
+	return seconds
+}
@@ -0,0 +1,61 @@
+package freedesktop
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestGetSystemInfo_Fallbacks(t *testing.T) {
+	root := t.TempDir()
+	oldProc, oldSys, oldEtc := procRoot, sysRoot, etcRoot
+	procRoot, sysRoot, etcRoot = filepath.Join(root, "proc"), filepath.Join(root, "sys"), filepath.Join(root, "etc")
+	t.Cleanup(func() { procRoot, sysRoot, etcRoot = oldProc, oldSys, oldEtc })
+
+	writeTestFile(t, filepath.Join(procRoot, "uptime"), "3600.52 7000.10\n")
+	writeTestFile(t, filepath.Join(procRoot, "sys/kernel/osrelease"), "6.18.1-arch1-1\n")
+	writeTestFile(t, filepath.Join(procRoot, "cpuinfo"), "processor\t: 0\nvendor_id\t: AuthenticAMD\nmodel name\t: AMD Ryzen 7 7840U\n")
+	writeTestFile(t, filepath.Join(procRoot, "meminfo"), "MemTotal:       32000000 kB\nMemFree:         1000 kB\n")
+	writeTestFile(t, filepath.Join(sysRoot, "class/dmi/id/sys_vendor"), "Framework\n")
+	writeTestFile(t, filepath.Join(sysRoot, "class/dmi/id/product_name"), "Laptop 13\n")
+	writeTestFile(t, filepath.Join(etcRoot, "os-release"), "NAME=\"Arch Linux\"\nPRETTY_NAME=\"Arch Linux\"\n")
+
+	m := &Manager{state: &FreedeskState{}}
+	m.SetBackendVersion("v0.2.0", 12)
+	info := m.GetSystemInfo()
+
+	assert.NotEmpty(t, info.Hostname)
+	assert.Equal(t, "Framework", info.Vendor)
+	assert.Equal(t, "Laptop 13", info.Model)
+	assert.Equal(t, "Arch Linux", info.OS)
+	assert.Equal(t, "6.18.1-arch1-1", info.Kernel)
+	assert.Equal(t, "AMD Ryzen 7 7840U", info.CPU)
+	assert.Equal(t, uint64(32000000*1024), info.MemoryTotal)
+	assert.Equal(t, 3600.52, info.Uptime)
+	assert.NotZero(t, info.BootTime)
+	assert.Equal(t, "v0.2.0", info.Versions.Backend)
+	assert.Equal(t, 12, info.Versions.APIVersion)
+}
+
+func TestSetHostname_Validation(t *testing.T) {
+	m := &Manager{state: &FreedeskState{}}
+
+	for _, name := range []string{"", "-bad", "bad-", "has space", "under_score", string(make([]byte, 65))} {
+		err := m.SetHostname(name, "")
+		require.Error(t, err, name)
+		assert.Contains(t, err.Error(), "invalid hostname")
+	}
+
+	err := m.SetHostname("my-laptop", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no system bus")
+}
@@ -41,4 +41,7 @@ type Manager struct {
 	accountsObj dbus.BusObject
 	settingsObj dbus.BusObject
 	currentUID  uint64
+
+	backendVersion string
+	apiVersion     int
 }
@@ -0,0 +1,33 @@
+package server
+
+var greeterMode bool
+
+var greeterMethods = map[string]bool{
+	"ping":                       true,
+	"getServerInfo":              true,
+	"subscribe":                  true,
+	"network.getState":           true,
+	"network.wifi.scan":          true,
+	"network.wifi.networks":      true,
+	"network.wifi.connect":       true,
+	"network.wifi.disconnect":    true,
+	"network.wifi.toggle":        true,
+	"network.wifi.enable":        true,
+	"network.wifi.disable":       true,
+	"network.ethernet.connect":   true,
+	"network.info":               true,
+	"network.credentials.submit": true,
+	"network.credentials.cancel": true,
+	"network.subscribe":          true,
+}
+
+func isGreeterMethodAllowed(method string) bool {
+	return greeterMethods[method]
+}
+
+// StartGreeter runs a constrained server for the greeter session that only
+// exposes the network module and the methods needed to join a network.
+func StartGreeter() error {
+	greeterMode = true
+	return Start(false)
+}
@@ -0,0 +1,75 @@
+package hwmon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "hwmon manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "hwmon.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "hwmon.setThreshold":
+		kind, ok := req.Params["kind"].(string)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'kind' parameter")
+			return
+		}
+		temp, ok := req.Params["temp"].(float64)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'temp' parameter")
+			return
+		}
+		if err := manager.SetThreshold(kind, temp); err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		models.Respond(conn, req.ID, SuccessResult{Success: true, Message: fmt.Sprintf("%s threshold set to %g°C", kind, temp)})
+	case "hwmon.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package hwmon
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
+)
+
+// coolDown is how far below its threshold a sensor must drop before it
+// counts as cooled, so readings hovering at the limit don't flap
+const coolDown = 5.0
+
+var defaultThresholds = map[string]float64{
+	KindCPU: 90,
+	KindGPU: 90,
+}
+
+func NewManager() (*Manager, error) {
+	if _, err := os.Stat(hwmonDir); err != nil {
+		return nil, fmt.Errorf("hwmon not available: %w", err)
+	}
+
+	m := newManager(hwmonDir)
+	m.poll()
+
+	m.wg.Add(2)
+	go m.pollLoop()
+	go m.notifier()
+	return m, nil
+}
+
+func newManager(root string) *Manager {
+	thresholds := make(map[string]float64, len(defaultThresholds))
+	for kind, temp := range defaultThresholds {
+		thresholds[kind] = temp
+	}
+	return &Manager{
+		root:         root,
+		state:        &State{},
+		thresholds:   thresholds,
+		overheating:  make(map[string]bool),
+		pollInterval: 3 * time.Second,
+		subscribers:  make(map[string]chan State),
+		dirty:        make(chan struct{}, 1),
+		stopChan:     make(chan struct{}),
+	}
+}
+
+func (m *Manager) pollLoop() {
+	defer m.wg.Done()
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			m.poll()
+			ticker.Reset(budget.Scale(m.pollInterval))
+		}
+	}
+}
+
+func (m *Manager) poll() {
+	sensors, fans := readSensors(m.root)
+
+	m.stateMutex.Lock()
+	state := &State{Sensors: sensors, Fans: fans, Thresholds: make(map[string]float64, len(m.thresholds))}
+	for kind, temp := range m.thresholds {
+		state.Thresholds[kind] = temp
+	}
+
+	for _, sensor := range sensors {
+		switch sensor.Kind {
+		case KindCPU:
+			state.CPUTemp = maxTemp(state.CPUTemp, sensor.Temp)
+		case KindGPU:
+			state.GPUTemp = maxTemp(state.GPUTemp, sensor.Temp)
+		}
+
+		threshold := m.thresholdFor(sensor)
+		if threshold == 0 {
+			continue
+		}
+		key := sensor.Chip + "/" + sensor.Label
+		switch {
+		case !m.overheating[key] && sensor.Temp >= threshold:
+			m.overheating[key] = true
+			state.Events = append(state.Events, Event{Type: EventOverheat, Kind: sensor.Kind, Sensor: key, Temp: sensor.Temp, Threshold: threshold})
+		case m.overheating[key] && sensor.Temp <= threshold-coolDown:
+			delete(m.overheating, key)
+			state.Events = append(state.Events, Event{Type: EventCooled, Kind: sensor.Kind, Sensor: key, Temp: sensor.Temp, Threshold: threshold})
+		}
+	}
+
+	for key := range m.overheating {
+		state.Overheating = append(state.Overheating, key)
+	}
+	sort.Strings(state.Overheating)
+	m.state = state
+	m.stateMutex.Unlock()
+
+	for _, event := range state.Events {
+		if event.Type == EventOverheat {
+			log.Warnf("hwmon: %s at %.1f°C (threshold %.0f°C)", event.Sensor, event.Temp, event.Threshold)
+		} else {
+			log.Infof("hwmon: %s cooled to %.1f°C", event.Sensor, event.Temp)
+		}
+	}
+	m.notifySubscribers()
+}
+
+// thresholdFor uses the configured limit for the sensor's kind, lowered to
+// the chip's own limit when that is stricter. Other sensors never warn
+func (m *Manager) thresholdFor(sensor Sensor) float64 {
+	threshold := m.thresholds[sensor.Kind]
+	if threshold > 0 && sensor.Max > 0 && sensor.Max < threshold {
+		threshold = sensor.Max
+	}
+	return threshold
+}
+
+func maxTemp(a, b float64) float64 {
+	if b > a {
+		return b
+	}
+	return a
+}
+
+// SetThreshold sets the overheat warning temperature for cpu or gpu sensors;
+// 0 disables warnings for that kind
+func (m *Manager) SetThreshold(kind string, temp float64) error {
+	if kind != KindCPU && kind != KindGPU {
+		return fmt.Errorf("invalid sensor kind %q (use cpu or gpu)", kind)
+	}
+	if temp < 0 || temp > 150 {
+		return fmt.Errorf("threshold must be between 0 and 150°C")
+	}
+
+	m.stateMutex.Lock()
+	m.thresholds[kind] = temp
+	m.stateMutex.Unlock()
+	m.poll()
+	return nil
+}
+
+func (m *Manager) notifier() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.dirty:
+			state := m.GetState()
+
+			m.subMutex.RLock()
+			if m.lastNotified != nil && !stateChanged(m.lastNotified, &state) {
+				m.subMutex.RUnlock()
+				continue
+			}
+			for _, ch := range m.subscribers {
+				select {
+				case ch <- state:
+				default:
+				}
+			}
+			m.subMutex.RUnlock()
+
+			m.lastNotified = &state
+		}
+	}
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	m.wg.Wait()
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
@@ -0,0 +1,97 @@
+package hwmon
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeChip(t *testing.T, root, dir string, files map[string]string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0755))
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(root, dir, name), []byte(content+"\n"), 0644))
+	}
+}
+
+func setTemp(t *testing.T, root string, milli int) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "hwmon1", "temp1_input"), []byte(strconv.Itoa(milli)), 0644))
+}
+
+func testRoot(t *testing.T) string {
+	root := t.TempDir()
+	writeChip(t, root, "hwmon0", map[string]string{
+		"name":        "nvme",
+		"temp1_input": "41850",
+		"temp1_crit":  "84850",
+	})
+	writeChip(t, root, "hwmon1", map[string]string{
+		"name":        "k10temp",
+		"temp1_input": "55125",
+		"temp1_label": "Tctl",
+	})
+	writeChip(t, root, "hwmon2", map[string]string{
+		"name":        "amdgpu",
+		"temp1_input": "61000",
+		"temp1_label": "edge",
+		"temp1_crit":  "100000",
+		"fan1_input":  "1480",
+	})
+	return root
+}
+
+func TestReadSensors(t *testing.T) {
+	sensors, fans := readSensors(testRoot(t))
+
+	assert.Equal(t, []Sensor{
+		{Chip: "amdgpu", Label: "edge", Kind: KindGPU, Temp: 61, Max: 100},
+		{Chip: "k10temp", Label: "Tctl", Kind: KindCPU, Temp: 55.1},
+		{Chip: "nvme", Label: "temp1", Kind: KindOther, Temp: 41.9, Max: 84.9},
+	}, sensors)
+	assert.Equal(t, []Fan{{Chip: "amdgpu", Label: "fan1", RPM: 1480}}, fans)
+}
+
+func TestOverheatEvents(t *testing.T) {
+	root := testRoot(t)
+	m := newManager(root)
+
+	m.poll()
+	state := m.GetState()
+	assert.Equal(t, 55.1, state.CPUTemp)
+	assert.Equal(t, 61.0, state.GPUTemp)
+	assert.Empty(t, state.Events)
+
+	setTemp(t, root, 92000)
+	m.poll()
+	state = m.GetState()
+	assert.Equal(t, []Event{{Type: EventOverheat, Kind: KindCPU, Sensor: "k10temp/Tctl", Temp: 92, Threshold: 90}}, state.Events)
+	assert.Equal(t, []string{"k10temp/Tctl"}, state.Overheating)
+
+	// Still hot, and within the cool-down band: no repeated events
+	setTemp(t, root, 87000)
+	m.poll()
+	assert.Empty(t, m.GetState().Events)
+
+	setTemp(t, root, 80000)
+	m.poll()
+	state = m.GetState()
+	assert.Equal(t, EventCooled, state.Events[0].Type)
+	assert.Empty(t, state.Overheating)
+}
+
+func TestSetThreshold(t *testing.T) {
+	m := newManager(testRoot(t))
+
+	require.NoError(t, m.SetThreshold(KindGPU, 60))
+	state := m.GetState()
+	assert.Equal(t, 60.0, state.Thresholds[KindGPU])
+	assert.Equal(t, []string{"amdgpu/edge"}, state.Overheating)
+
+	assert.Error(t, m.SetThreshold("disk", 50))
+	assert.Error(t, m.SetThreshold(KindCPU, 200))
+}
@@ -0,0 +1,110 @@
+package hwmon
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const hwmonDir = "/sys/class/hwmon"
+
+var chipKinds = map[string]string{
+	"coretemp":    KindCPU,
+	"k10temp":     KindCPU,
+	"zenpower":    KindCPU,
+	"cpu_thermal": KindCPU,
+	"amdgpu":      KindGPU,
+	"nouveau":     KindGPU,
+	"radeon":      KindGPU,
+	"i915":        KindGPU,
+	"xe":          KindGPU,
+}
+
+func readTrimmed(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readMilli reads a sysfs value in thousandths, as hwmon reports degrees
+func readMilli(path string) (float64, bool) {
+	value, err := strconv.ParseFloat(readTrimmed(path), 64)
+	if err != nil {
+		return 0, false
+	}
+	return math.Round(value/100) / 10, true
+}
+
+// readSensors reads every hwmon chip below root. Chips and channels are
+// sorted so readings compare equal across polls
+func readSensors(root string) ([]Sensor, []Fan) {
+	chips, err := os.ReadDir(root)
+	if err != nil {
+		return nil, nil
+	}
+
+	var sensors []Sensor
+	var fans []Fan
+	for _, entry := range chips {
+		dir := filepath.Join(root, entry.Name())
+		chip := readTrimmed(filepath.Join(dir, "name"))
+		if chip == "" {
+			continue
+		}
+		kind, ok := chipKinds[chip]
+		if !ok {
+			kind = KindOther
+		}
+
+		inputs, _ := filepath.Glob(filepath.Join(dir, "temp*_input"))
+		for _, input := range inputs {
+			prefix := strings.TrimSuffix(input, "_input")
+			temp, ok := readMilli(input)
+			if !ok {
+				continue
+			}
+			sensor := Sensor{Chip: chip, Label: channelLabel(prefix), Kind: kind, Temp: temp}
+			if max, ok := readMilli(prefix + "_max"); ok && max > 0 {
+				sensor.Max = max
+			} else if crit, ok := readMilli(prefix + "_crit"); ok && crit > 0 {
+				sensor.Max = crit
+			}
+			sensors = append(sensors, sensor)
+		}
+
+		inputs, _ = filepath.Glob(filepath.Join(dir, "fan*_input"))
+		for _, input := range inputs {
+			rpm, err := strconv.Atoi(readTrimmed(input))
+			if err != nil {
+				continue
+			}
+			fans = append(fans, Fan{Chip: chip, Label: channelLabel(strings.TrimSuffix(input, "_input")), RPM: rpm})
+		}
+	}
+
+	sort.Slice(sensors, func(i, j int) bool {
+		if sensors[i].Chip != sensors[j].Chip {
+			return sensors[i].Chip < sensors[j].Chip
+		}
+		return sensors[i].Label < sensors[j].Label
+	})
+	sort.Slice(fans, func(i, j int) bool {
+		if fans[i].Chip != fans[j].Chip {
+			return fans[i].Chip < fans[j].Chip
+		}
+		return fans[i].Label < fans[j].Label
+	})
+	return sensors, fans
+}
+
+func channelLabel(prefix string) string {
+	if label := readTrimmed(prefix + "_label"); label != "" {
+		return label
+	}
+	return filepath.Base(prefix)
+}
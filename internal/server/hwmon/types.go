@@ -0,0 +1,118 @@
+package hwmon
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+const (
+	KindCPU   = "cpu"
+	KindGPU   = "gpu"
+	KindOther = "other"
+
+	EventOverheat = "overheat"
+	EventCooled   = "cooled"
+)
+
+type Sensor struct {
+	Chip  string  `json:"chip"`
+	Label string  `json:"label"`
+	Kind  string  `json:"kind"`
+	Temp  float64 `json:"temp"`
+	// Max is the chip's own warning limit, 0 when it doesn't report one
+	Max float64 `json:"max,omitempty"`
+}
+
+type Fan struct {
+	Chip  string `json:"chip"`
+	Label string `json:"label"`
+	RPM   int    `json:"rpm"`
+}
+
+type Event struct {
+	Type      string  `json:"type"`
+	Kind      string  `json:"kind"`
+	Sensor    string  `json:"sensor"`
+	Temp      float64 `json:"temp"`
+	Threshold float64 `json:"threshold"`
+}
+
+type State struct {
+	Sensors     []Sensor           `json:"sensors"`
+	Fans        []Fan              `json:"fans"`
+	CPUTemp     float64            `json:"cpuTemp"`
+	GPUTemp     float64            `json:"gpuTemp"`
+	Thresholds  map[string]float64 `json:"thresholds"`
+	Overheating []string           `json:"overheating"`
+	// Events are the threshold crossings of the latest reading
+	Events []Event `json:"events,omitempty"`
+}
+
+type Manager struct {
+	root string
+
+	state      *State
+	stateMutex sync.RWMutex
+	thresholds map[string]float64
+	// overheating is keyed by chip/label so each sensor warns once per episode
+	overheating map[string]bool
+
+	pollInterval time.Duration
+
+	subscribers  map[string]chan State
+	subMutex     sync.RWMutex
+	dirty        chan struct{}
+	stopChan     chan struct{}
+	wg           sync.WaitGroup
+	lastNotified *State
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.state == nil {
+		return State{}
+	}
+	stateCopy := *m.state
+	stateCopy.Sensors = append([]Sensor(nil), m.state.Sensors...)
+	stateCopy.Fans = append([]Fan(nil), m.state.Fans...)
+	stateCopy.Overheating = append([]string(nil), m.state.Overheating...)
+	stateCopy.Events = append([]Event(nil), m.state.Events...)
+	stateCopy.Thresholds = make(map[string]float64, len(m.state.Thresholds))
+	for kind, temp := range m.state.Thresholds {
+		stateCopy.Thresholds[kind] = temp
+	}
+	return stateCopy
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func stateChanged(old, new *State) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	return !reflect.DeepEqual(old, new)
+}
@@ -0,0 +1,106 @@
+package idle
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const powerSupplyDir = "/sys/class/power_supply"
+
+// commandExecutor maps timeline actions onto the tools DMS already relies on:
+// brightnessctl for dimming, dms lock, the compositor for DPMS and systemd
+// for suspend
+type commandExecutor struct {
+	run func(name string, args ...string) error
+}
+
+func newCommandExecutor() *commandExecutor {
+	return &commandExecutor{run: runCommand}
+}
+
+func runCommand(name string, args ...string) error {
+	if output, err := exec.Command(name, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %s", name, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (e *commandExecutor) Run(action Action) error {
+	switch action {
+	case ActionDim:
+		return e.run("brightnessctl", "--save", "--min-value=5%", "set", "50%-")
+	case ActionLock:
+		return e.run("dms", "lock")
+	case ActionDPMS:
+		return e.dpms(false)
+	case ActionSuspend:
+		return e.run("systemctl", "suspend")
+	}
+	return fmt.Errorf("unknown idle action: %s", action)
+}
+
+func (e *commandExecutor) Resume(action Action) error {
+	switch action {
+	case ActionDim:
+		return e.run("brightnessctl", "--restore")
+	case ActionDPMS:
+		return e.dpms(true)
+	}
+	return nil
+}
+
+func (e *commandExecutor) dpms(on bool) error {
+	switch {
+	case os.Getenv("NIRI_SOCKET") != "":
+		if on {
+			return e.run("niri", "msg", "action", "power-on-monitors")
+		}
+		return e.run("niri", "msg", "action", "power-off-monitors")
+	case os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "":
+		if on {
+			return e.run("hyprctl", "dispatch", "dpms", "on")
+		}
+		return e.run("hyprctl", "dispatch", "dpms", "off")
+	}
+	return fmt.Errorf("DPMS not supported on this compositor")
+}
+
+func onBattery() bool {
+	return onBatteryAt(powerSupplyDir)
+}
+
+// onBatteryAt treats the machine as on battery when it has a battery and no
+// online mains supply, so desktops never count as on battery
+func onBatteryAt(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+
+	hasBattery := false
+	for _, entry := range entries {
+		supplyType := readSupplyFile(dir, entry.Name(), "type")
+		switch supplyType {
+		case "Battery":
+			if readSupplyFile(dir, entry.Name(), "scope") != "Device" {
+				hasBattery = true
+			}
+		case "Mains", "USB":
+			if readSupplyFile(dir, entry.Name(), "online") == "1" {
+				return false
+			}
+		}
+	}
+	return hasBattery
+}
+
+func readSupplyFile(dir, supply, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, supply, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,186 @@
+package idle
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "idle manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "idle.getState":
+		handleGetState(conn, req, manager)
+	case "idle.setConfig":
+		handleSetConfig(conn, req, manager)
+	case "idle.setEnabled":
+		handleSetEnabled(conn, req, manager)
+	case "idle.setOverride":
+		handleSetOverride(conn, req, manager)
+	case "idle.clearOverride":
+		handleClearOverride(conn, req, manager)
+	case "idle.caffeinate":
+		handleCaffeinate(conn, req, manager)
+	case "idle.inhibit":
+		handleInhibit(conn, req, manager)
+	case "idle.uninhibit":
+		handleUninhibit(conn, req, manager)
+	case "idle.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleGetState(conn net.Conn, req Request, manager *Manager) {
+	models.Respond(conn, req.ID, manager.GetState())
+}
+
+func handleSetConfig(conn net.Conn, req Request, manager *Manager) {
+	rawSteps, ok := req.Params["steps"]
+	if !ok {
+		models.RespondError(conn, req.ID, "missing 'steps' parameter")
+		return
+	}
+
+	data, err := json.Marshal(rawSteps)
+	if err != nil {
+		models.RespondError(conn, req.ID, "invalid 'steps' parameter")
+		return
+	}
+
+	config := manager.GetConfig()
+	config.Steps = nil
+	if err := json.Unmarshal(data, &config.Steps); err != nil {
+		models.RespondError(conn, req.ID, fmt.Sprintf("invalid 'steps' parameter: %v", err))
+		return
+	}
+	if enabled, ok := req.Params["enabled"].(bool); ok {
+		config.Enabled = enabled
+	}
+
+	if err := manager.SetConfig(config); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "idle config set"})
+}
+
+func handleSetEnabled(conn net.Conn, req Request, manager *Manager) {
+	enabled, ok := req.Params["enabled"].(bool)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'enabled' parameter")
+		return
+	}
+
+	manager.SetEnabled(enabled)
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "idle enabled set"})
+}
+
+func handleSetOverride(conn net.Conn, req Request, manager *Manager) {
+	reason, _ := req.Params["reason"].(string)
+	if reason == "" {
+		reason = "manual"
+	}
+
+	var duration time.Duration
+	if seconds, ok := req.Params["duration"].(float64); ok {
+		if seconds < 0 {
+			models.RespondError(conn, req.ID, "'duration' must not be negative")
+			return
+		}
+		duration = time.Duration(seconds * float64(time.Second))
+	}
+
+	manager.SetOverride(reason, duration)
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "idle override set"})
+}
+
+func handleClearOverride(conn net.Conn, req Request, manager *Manager) {
+	manager.ClearOverride()
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "idle override cleared"})
+}
+
+func handleCaffeinate(conn net.Conn, req Request, manager *Manager) {
+	var duration time.Duration
+	if seconds, ok := req.Params["duration"].(float64); ok {
+		if seconds < 0 {
+			models.RespondError(conn, req.ID, "'duration' must not be negative")
+			return
+		}
+		duration = time.Duration(seconds * float64(time.Second))
+	}
+
+	if manager.Caffeinate(duration) {
+		models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "caffeine on"})
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "caffeine off"})
+}
+
+func handleInhibit(conn net.Conn, req Request, manager *Manager) {
+	app, ok := req.Params["app"].(string)
+	if !ok || app == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'app' parameter")
+		return
+	}
+	reason, _ := req.Params["reason"].(string)
+
+	manager.Inhibit(app, reason)
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: fmt.Sprintf("idle inhibited by %s", app)})
+}
+
+func handleUninhibit(conn net.Conn, req Request, manager *Manager) {
+	app, ok := req.Params["app"].(string)
+	if !ok || app == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'app' parameter")
+		return
+	}
+
+	if !manager.Uninhibit(app) {
+		models.Respond(conn, req.ID, SuccessResult{Success: true, Message: fmt.Sprintf("%s was not inhibiting idle", app)})
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: fmt.Sprintf("idle no longer inhibited by %s", app)})
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,425 @@
+package idle
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	defaultPollInterval = 5 * time.Second
+	caffeineReason      = "caffeinate"
+)
+
+func NewManager() (*Manager, error) {
+	conn, err := dbus.ConnectSystemBus(budget.DBusOptions("idle")...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to system bus: %w", err)
+	}
+
+	var source Source
+	wayland, err := newWaylandSource()
+	if err == nil {
+		source = wayland
+	} else {
+		log.Infof("idle: ext-idle-notify unavailable, using logind idle hint: %v", err)
+		logind, err := newLogindSource(conn)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		source = logind
+	}
+
+	m := newManager(source, newCommandExecutor(), func() []string { return listIdleInhibitors(conn) }, onBattery)
+	m.conn = conn
+	if wayland != nil {
+		// Activity resumes dimmed or blanked screens without waiting for a poll
+		wayland.setOnChange(m.trigger)
+	}
+	m.start()
+	return m, nil
+}
+
+func newManager(source Source, executor Executor, inhibitors func() []string, battery func() bool) *Manager {
+	config := DefaultConfig()
+	return &Manager{
+		config:       config,
+		state:        &State{Config: config, Source: source.Name()},
+		source:       source,
+		executor:     executor,
+		inhibitors:   inhibitors,
+		onBattery:    battery,
+		apps:         make(map[string]string),
+		fired:        make(map[Action]bool),
+		pollInterval: defaultPollInterval,
+		subscribers:  make(map[string]chan State),
+		dirty:        make(chan struct{}, 1),
+		stopChan:     make(chan struct{}),
+	}
+}
+
+func (m *Manager) start() {
+	m.wg.Add(2)
+	go m.notifier()
+	go m.loop()
+}
+
+func (m *Manager) loop() {
+	defer m.wg.Done()
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	m.tick()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			m.tick()
+		}
+	}
+}
+
+// tick advances the timeline. Inhibitors, an override or a disabled config
+// hold the effective idle time at zero, which also resumes anything fired
+func (m *Manager) tick() {
+	m.tickMutex.Lock()
+	defer m.tickMutex.Unlock()
+
+	select {
+	case <-m.stopChan:
+		return
+	default:
+	}
+
+	m.configMutex.Lock()
+	config := m.config
+	if m.override != nil && !m.override.Until.IsZero() && time.Now().After(m.override.Until) {
+		m.override = nil
+	}
+	override := m.override
+	m.configMutex.Unlock()
+
+	caffeinated := override != nil && override.Reason == caffeineReason
+	m.syncCaffeine(caffeinated, config.PauseNightLight)
+	var caffeineRemaining int
+	if caffeinated && !override.Until.IsZero() {
+		caffeineRemaining = int(math.Ceil(time.Until(override.Until).Seconds()))
+	}
+
+	idle, err := m.source.IdleTime()
+	if err != nil {
+		log.Debugf("idle: failed to read idle time: %v", err)
+		idle = 0
+	}
+
+	battery := m.onBattery()
+	inhibitedBy := slices.Concat(m.inhibitors(), m.appInhibitors())
+
+	effective := idle
+	if !config.Enabled || len(inhibitedBy) > 0 || override != nil {
+		effective = 0
+	}
+
+	m.resumeOnActivity(config.Steps, effective)
+
+	for _, step := range dueSteps(config.Steps, effective, battery) {
+		if m.fired[step.Action] {
+			continue
+		}
+		m.fired[step.Action] = true
+		if err := m.executor.Run(step.Action); err != nil {
+			log.Warnf("idle: %s failed: %v", step.Action, err)
+		}
+	}
+
+	m.stateMutex.Lock()
+	m.state.Config = config
+	m.state.IdleSeconds = int(idle.Seconds())
+	m.state.OnBattery = battery
+	m.state.Inhibited = len(inhibitedBy) > 0
+	m.state.InhibitedBy = inhibitedBy
+	m.state.Override = override
+	m.state.Fired = m.firedActions(config.Steps)
+	m.state.Caffeinated = caffeinated
+	m.state.CaffeineRemaining = caffeineRemaining
+	m.stateMutex.Unlock()
+
+	m.notifySubscribers()
+}
+
+// resumeOnActivity undoes fired steps, latest first, once the idle time drops
+// below the earliest of them
+func (m *Manager) resumeOnActivity(steps []Step, idle time.Duration) {
+	fired := m.firedSteps(steps)
+	if len(fired) == 0 || idle >= time.Duration(fired[0].Timeout)*time.Second {
+		return
+	}
+
+	for i := len(fired) - 1; i >= 0; i-- {
+		if err := m.executor.Resume(fired[i].Action); err != nil {
+			log.Warnf("idle: resuming %s failed: %v", fired[i].Action, err)
+		}
+	}
+	m.fired = make(map[Action]bool)
+}
+
+func (m *Manager) firedSteps(steps []Step) []Step {
+	var fired []Step
+	for _, step := range steps {
+		if m.fired[step.Action] {
+			fired = append(fired, step)
+		}
+	}
+	sort.SliceStable(fired, func(i, j int) bool {
+		return fired[i].Timeout < fired[j].Timeout
+	})
+	return fired
+}
+
+func (m *Manager) firedActions(steps []Step) []Action {
+	actions := []Action{}
+	for _, step := range m.firedSteps(steps) {
+		actions = append(actions, step.Action)
+	}
+	return actions
+}
+
+// dueSteps returns the steps whose timeout has passed and that apply on the
+// current power source
+func dueSteps(steps []Step, idle time.Duration, battery bool) []Step {
+	var due []Step
+	for _, step := range steps {
+		if step.OnBatteryOnly && !battery {
+			continue
+		}
+		if idle >= time.Duration(step.Timeout)*time.Second {
+			due = append(due, step)
+		}
+	}
+	return due
+}
+
+func (m *Manager) GetConfig() Config {
+	m.configMutex.RLock()
+	defer m.configMutex.RUnlock()
+	config := m.config
+	config.Steps = append([]Step(nil), m.config.Steps...)
+	return config
+}
+
+func (m *Manager) SetConfig(config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	m.configMutex.Lock()
+	m.config = config
+	m.configMutex.Unlock()
+
+	m.trigger()
+	return nil
+}
+
+func (m *Manager) SetEnabled(enabled bool) {
+	m.configMutex.Lock()
+	m.config.Enabled = enabled
+	m.configMutex.Unlock()
+
+	m.trigger()
+}
+
+// SetOverride pauses the timeline for duration, or until ClearOverride when
+// duration is zero
+func (m *Manager) SetOverride(reason string, duration time.Duration) {
+	override := &Override{Reason: reason}
+	if duration > 0 {
+		override.Until = time.Now().Add(duration)
+	}
+
+	m.configMutex.Lock()
+	m.override = override
+	m.configMutex.Unlock()
+
+	m.trigger()
+}
+
+func (m *Manager) ClearOverride() {
+	m.configMutex.Lock()
+	m.override = nil
+	m.configMutex.Unlock()
+
+	m.trigger()
+}
+
+// Inhibit holds the timeline while app has an inhibitor. Calling it again
+// for the same app replaces the reason
+func (m *Manager) Inhibit(app, reason string) {
+	m.configMutex.Lock()
+	m.apps[app] = reason
+	m.configMutex.Unlock()
+
+	m.trigger()
+}
+
+// Uninhibit releases app's inhibitor. It reports whether app held one
+func (m *Manager) Uninhibit(app string) bool {
+	m.configMutex.Lock()
+	_, ok := m.apps[app]
+	delete(m.apps, app)
+	m.configMutex.Unlock()
+
+	if ok {
+		m.trigger()
+	}
+	return ok
+}
+
+// appInhibitors lists the inhibitors taken over IPC like logind's, sorted
+// by app
+func (m *Manager) appInhibitors() []string {
+	m.configMutex.RLock()
+	defer m.configMutex.RUnlock()
+
+	holders := make([]string, 0, len(m.apps))
+	for app, reason := range m.apps {
+		if reason == "" {
+			holders = append(holders, app)
+		} else {
+			holders = append(holders, fmt.Sprintf("%s: %s", app, reason))
+		}
+	}
+	sort.Strings(holders)
+	return holders
+}
+
+// Caffeinate toggles caffeine mode: the timeline is paused, a logind
+// idle/sleep inhibitor is held and, with PauseNightLight, night light
+// transitions stop. A zero duration lasts until toggled off. It reports
+// whether caffeine mode is now on
+func (m *Manager) Caffeinate(duration time.Duration) bool {
+	m.configMutex.Lock()
+	active := m.override != nil && m.override.Reason == caffeineReason &&
+		(m.override.Until.IsZero() || time.Now().Before(m.override.Until))
+	if active {
+		m.override = nil
+	} else {
+		m.override = &Override{Reason: caffeineReason}
+		if duration > 0 {
+			m.override.Until = time.Now().Add(duration)
+		}
+	}
+	m.configMutex.Unlock()
+
+	m.trigger()
+	return !active
+}
+
+// SetNightLightHook registers how caffeine mode pauses night light
+func (m *Manager) SetNightLightHook(fn func(paused bool)) {
+	m.tickMutex.Lock()
+	m.nightLight = fn
+	m.tickMutex.Unlock()
+}
+
+func (m *Manager) syncCaffeine(active, pauseNightLight bool) {
+	if active == m.caffeinated {
+		return
+	}
+	m.caffeinated = active
+
+	if active {
+		if err := m.acquireInhibitor(); err != nil {
+			log.Warnf("idle: caffeine inhibitor unavailable: %v", err)
+		}
+	} else {
+		m.releaseInhibitor()
+	}
+
+	if m.nightLight != nil && (pauseNightLight || !active) {
+		m.nightLight(active)
+	}
+}
+
+func (m *Manager) acquireInhibitor() error {
+	if m.conn == nil || m.inhibitFile != nil {
+		return nil
+	}
+
+	var fd dbus.UnixFD
+	manager := m.conn.Object(dbusDest, dbus.ObjectPath(dbusPath))
+	if err := manager.Call(dbusManagerInterface+".Inhibit", 0, "idle:sleep", "DankMaterialShell", "Caffeine mode", "block").Store(&fd); err != nil {
+		return err
+	}
+	m.inhibitFile = os.NewFile(uintptr(fd), "caffeine-inhibit")
+	return nil
+}
+
+func (m *Manager) releaseInhibitor() {
+	if m.inhibitFile != nil {
+		m.inhibitFile.Close()
+		m.inhibitFile = nil
+	}
+}
+
+func (m *Manager) trigger() {
+	go m.tick()
+}
+
+func (m *Manager) notifier() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.dirty:
+			state := m.GetState()
+
+			m.subMutex.RLock()
+			if m.lastNotified != nil && !stateChanged(m.lastNotified, &state) {
+				m.subMutex.RUnlock()
+				continue
+			}
+			for _, ch := range m.subscribers {
+				select {
+				case ch <- state:
+				default:
+				}
+			}
+			m.subMutex.RUnlock()
+
+			m.lastNotified = &state
+		}
+	}
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	m.wg.Wait()
+
+	m.tickMutex.Lock()
+	m.syncCaffeine(false, false)
+	m.tickMutex.Unlock()
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+
+	if closer, ok := m.source.(interface{ Close() }); ok {
+		closer.Close()
+	}
+	if m.conn != nil {
+		m.conn.Close()
+	}
+}
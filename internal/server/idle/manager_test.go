@@ -0,0 +1,214 @@
+package idle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSource struct {
+	idle time.Duration
+}
+
+func (s *fakeSource) Name() string { return "fake" }
+
+func (s *fakeSource) IdleTime() (time.Duration, error) { return s.idle, nil }
+
+type fakeExecutor struct {
+	calls []string
+}
+
+func (e *fakeExecutor) Run(action Action) error {
+	e.calls = append(e.calls, "run:"+string(action))
+	return nil
+}
+
+func (e *fakeExecutor) Resume(action Action) error {
+	e.calls = append(e.calls, "resume:"+string(action))
+	return nil
+}
+
+func newTestManager(battery bool, inhibitors ...string) (*Manager, *fakeSource, *fakeExecutor) {
+	source := &fakeSource{}
+	executor := &fakeExecutor{}
+	m := newManager(source, executor, func() []string { return inhibitors }, func() bool { return battery })
+	m.config.Enabled = true
+	return m, source, executor
+}
+
+func TestTimeline_FiresStepsInOrderAndResumes(t *testing.T) {
+	m, source, executor := newTestManager(false)
+
+	source.idle = 4 * time.Minute
+	m.tick()
+	assert.Empty(t, executor.calls)
+
+	source.idle = 11 * time.Minute
+	m.tick()
+	assert.Equal(t, []string{"run:dim", "run:lock"}, executor.calls)
+	assert.Equal(t, []Action{ActionDim, ActionLock}, m.GetState().Fired)
+
+	source.idle = 13 * time.Minute
+	m.tick()
+	assert.Equal(t, "run:dpms", executor.calls[2])
+
+	source.idle = 40 * time.Minute
+	m.tick()
+	assert.Len(t, executor.calls, 3, "suspend only runs on battery")
+
+	source.idle = 0
+	m.tick()
+	assert.Equal(t, []string{"resume:dpms", "resume:lock", "resume:dim"}, executor.calls[3:])
+	assert.Empty(t, m.GetState().Fired)
+}
+
+func TestTimeline_SuspendOnBattery(t *testing.T) {
+	m, source, executor := newTestManager(true)
+
+	source.idle = 31 * time.Minute
+	m.tick()
+	assert.Contains(t, executor.calls, "run:suspend")
+	assert.True(t, m.GetState().OnBattery)
+}
+
+func TestTimeline_InhibitorsAndOverride(t *testing.T) {
+	m, source, executor := newTestManager(false, "firefox: Playing video")
+
+	source.idle = 20 * time.Minute
+	m.tick()
+	assert.Empty(t, executor.calls)
+	state := m.GetState()
+	assert.True(t, state.Inhibited)
+	assert.Equal(t, 20*60, state.IdleSeconds)
+
+	m.inhibitors = func() []string { return nil }
+	m.override = &Override{Reason: "presentation"}
+	m.tick()
+	assert.Empty(t, executor.calls)
+	require.NotNil(t, m.GetState().Override)
+
+	m.override = &Override{Reason: "expired", Until: time.Now().Add(-time.Second)}
+	m.tick()
+	assert.Nil(t, m.GetState().Override)
+	assert.Equal(t, []string{"run:dim", "run:lock", "run:dpms"}, executor.calls)
+}
+
+func TestTimeline_Disabled(t *testing.T) {
+	m, source, executor := newTestManager(true)
+	m.config.Enabled = false
+
+	source.idle = time.Hour
+	m.tick()
+	assert.Empty(t, executor.calls)
+}
+
+func TestConfigValidate(t *testing.T) {
+	config := Config{Steps: []Step{
+		{Action: ActionLock, Timeout: 600},
+		{Action: ActionDim, Timeout: 300},
+	}}
+	require.NoError(t, config.Validate())
+	assert.Equal(t, ActionDim, config.Steps[0].Action)
+
+	assert.Error(t, (&Config{Steps: []Step{{Action: "explode", Timeout: 1}}}).Validate())
+	assert.Error(t, (&Config{Steps: []Step{{Action: ActionDim, Timeout: 0}}}).Validate())
+	assert.Error(t, (&Config{Steps: []Step{{Action: ActionDim, Timeout: 1}, {Action: ActionDim, Timeout: 2}}}).Validate())
+}
+
+func TestOnBatteryAt(t *testing.T) {
+	writeSupply := func(dir, name string, files map[string]string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, name), 0755))
+		for file, content := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name, file), []byte(content+"\n"), 0644))
+		}
+	}
+
+	laptop := t.TempDir()
+	writeSupply(laptop, "BAT0", map[string]string{"type": "Battery"})
+	writeSupply(laptop, "AC", map[string]string{"type": "Mains", "online": "0"})
+	writeSupply(laptop, "hidpp_battery_0", map[string]string{"type": "Battery", "scope": "Device"})
+	assert.True(t, onBatteryAt(laptop))
+
+	writeSupply(laptop, "AC", map[string]string{"type": "Mains", "online": "1"})
+	assert.False(t, onBatteryAt(laptop))
+
+	desktop := t.TempDir()
+	writeSupply(desktop, "hidpp_battery_0", map[string]string{"type": "Battery", "scope": "Device"})
+	assert.False(t, onBatteryAt(desktop))
+
+	assert.False(t, onBatteryAt(filepath.Join(desktop, "missing")))
+}
+
+func TestCaffeinate(t *testing.T) {
+	m, source, executor := newTestManager(false)
+	m.config.PauseNightLight = true
+
+	var nightLight []bool
+	m.SetNightLightHook(func(paused bool) { nightLight = append(nightLight, paused) })
+
+	source.idle = time.Hour
+	require.True(t, m.Caffeinate(30*time.Minute))
+	m.tick()
+
+	state := m.GetState()
+	assert.True(t, state.Caffeinated)
+	assert.InDelta(t, 30*60, state.CaffeineRemaining, 2)
+	assert.Empty(t, executor.calls)
+
+	require.False(t, m.Caffeinate(0))
+	m.tick()
+
+	state = m.GetState()
+	assert.False(t, state.Caffeinated)
+	assert.Zero(t, state.CaffeineRemaining)
+	assert.Equal(t, []bool{true, false}, nightLight)
+	assert.Contains(t, executor.calls, "run:lock")
+}
+
+func TestAppInhibitors(t *testing.T) {
+	m, source, executor := newTestManager(false, "firefox: Playing video")
+
+	m.Inhibit("mpv", "Playing")
+	m.Inhibit("presenter", "")
+	source.idle = time.Hour
+	m.tick()
+	assert.Empty(t, executor.calls)
+	assert.Equal(t, []string{"firefox: Playing video", "mpv: Playing", "presenter"}, m.GetState().InhibitedBy)
+
+	m.inhibitors = func() []string { return nil }
+	assert.True(t, m.Uninhibit("mpv"))
+	assert.False(t, m.Uninhibit("mpv"))
+	m.tick()
+	assert.Empty(t, executor.calls)
+	assert.Equal(t, []string{"presenter"}, m.GetState().InhibitedBy)
+
+	m.Uninhibit("presenter")
+	m.tick()
+	assert.False(t, m.GetState().Inhibited)
+	assert.Contains(t, executor.calls, "run:dpms")
+}
+
+func TestWaylandSourceIdleTime(t *testing.T) {
+	s := &waylandSource{}
+	var changes int
+	s.setOnChange(func() { changes++ })
+
+	idle, err := s.IdleTime()
+	require.NoError(t, err)
+	assert.Zero(t, idle)
+
+	s.markIdle(time.Now().Add(-time.Minute))
+	idle, err = s.IdleTime()
+	require.NoError(t, err)
+	assert.InDelta(t, (time.Minute + waylandIdleTimeout).Seconds(), idle.Seconds(), 1, "the notification timeout counts as idle")
+
+	s.markResumed()
+	idle, err = s.IdleTime()
+	require.NoError(t, err)
+	assert.Zero(t, idle)
+	assert.Equal(t, 2, changes)
+}
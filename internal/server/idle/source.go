@@ -0,0 +1,96 @@
+package idle
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	dbusDest             = "org.freedesktop.login1"
+	dbusPath             = "/org/freedesktop/login1"
+	dbusManagerInterface = "org.freedesktop.login1.Manager"
+	dbusSessionInterface = "org.freedesktop.login1.Session"
+)
+
+// logindSource reads the session's IdleHint, which compositors and idle
+// daemons maintain through SetIdleHint
+type logindSource struct {
+	session dbus.BusObject
+}
+
+func newLogindSource(conn *dbus.Conn) (*logindSource, error) {
+	sessionID := os.Getenv("XDG_SESSION_ID")
+	if sessionID == "" {
+		sessionID = "self"
+	}
+
+	var sessionPath dbus.ObjectPath
+	manager := conn.Object(dbusDest, dbus.ObjectPath(dbusPath))
+	if err := manager.Call(dbusManagerInterface+".GetSession", 0, sessionID).Store(&sessionPath); err != nil {
+		return nil, fmt.Errorf("failed to get session path: %w", err)
+	}
+
+	return &logindSource{session: conn.Object(dbusDest, sessionPath)}, nil
+}
+
+func (s *logindSource) Name() string {
+	return "logind"
+}
+
+func (s *logindSource) IdleTime() (time.Duration, error) {
+	hint, err := s.session.GetProperty(dbusSessionInterface + ".IdleHint")
+	if err != nil {
+		return 0, err
+	}
+	if idle, ok := hint.Value().(bool); !ok || !idle {
+		return 0, nil
+	}
+
+	since, err := s.session.GetProperty(dbusSessionInterface + ".IdleSinceHint")
+	if err != nil {
+		return 0, err
+	}
+	usec, ok := since.Value().(uint64)
+	if !ok || usec == 0 {
+		return 0, nil
+	}
+
+	idle := time.Since(time.UnixMicro(int64(usec)))
+	if idle < 0 {
+		return 0, nil
+	}
+	return idle, nil
+}
+
+// listIdleInhibitors returns who holds a blocking logind idle inhibitor,
+// e.g. video players and presentation tools
+func listIdleInhibitors(conn *dbus.Conn) []string {
+	var inhibitors []struct {
+		What string
+		Who  string
+		Why  string
+		Mode string
+		UID  uint32
+		PID  uint32
+	}
+
+	manager := conn.Object(dbusDest, dbus.ObjectPath(dbusPath))
+	if err := manager.Call(dbusManagerInterface+".ListInhibitors", 0).Store(&inhibitors); err != nil {
+		return nil
+	}
+
+	uid := uint32(os.Getuid())
+	var holders []string
+	for _, inhibitor := range inhibitors {
+		if inhibitor.Mode != "block" || inhibitor.UID != uid || !slices.Contains(strings.Split(inhibitor.What, ":"), "idle") {
+			continue
+		}
+		holders = append(holders, fmt.Sprintf("%s: %s", inhibitor.Who, inhibitor.Why))
+	}
+	return holders
+}
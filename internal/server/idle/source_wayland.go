@@ -0,0 +1,175 @@
+package idle
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/errdefs"
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/proto/ext_idle_notify"
+	wlclient "github.com/yaslama/go-wayland/wayland/client"
+)
+
+// waylandIdleTimeout is the notification timeout. The compositor reports
+// idled this long after the last input, so it is added back to the idle time
+const waylandIdleTimeout = 5 * time.Second
+
+// waylandSource follows an ext-idle-notify-v1 notification. The compositor
+// holds it off while a Wayland idle inhibitor is active, so fullscreen video
+// and apps using zwp_idle_inhibit_manager_v1 keep the session awake
+type waylandSource struct {
+	display  *wlclient.Display
+	notifier *ext_idle_notify.ExtIdleNotifierV1
+
+	mutex     sync.Mutex
+	idleSince time.Time
+	err       error
+	closed    bool
+	// onChange runs when the compositor reports idled or resumed
+	onChange func()
+
+	wg sync.WaitGroup
+}
+
+func newWaylandSource() (*waylandSource, error) {
+	display, err := wlclient.Connect("")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errdefs.ErrNoWaylandDisplay, err)
+	}
+
+	s := &waylandSource{display: display}
+	if err := s.setup(); err != nil {
+		display.Context().Close()
+		return nil, err
+	}
+
+	s.wg.Add(1)
+	go s.dispatch()
+	return s, nil
+}
+
+func (s *waylandSource) setup() error {
+	ctx := s.display.Context()
+	registry, err := s.display.GetRegistry()
+	if err != nil {
+		return fmt.Errorf("failed to get registry: %w", err)
+	}
+
+	var seat *wlclient.Seat
+	registry.SetGlobalHandler(func(e wlclient.RegistryGlobalEvent) {
+		switch e.Interface {
+		case ext_idle_notify.ExtIdleNotifierV1InterfaceName:
+			notifier := ext_idle_notify.NewExtIdleNotifierV1(ctx)
+			if err := registry.Bind(e.Name, e.Interface, 1, notifier); err == nil {
+				s.notifier = notifier
+			}
+		case "wl_seat":
+			if seat != nil {
+				return
+			}
+			candidate := wlclient.NewSeat(ctx)
+			if err := registry.Bind(e.Name, e.Interface, 1, candidate); err == nil {
+				seat = candidate
+			}
+		}
+	})
+
+	if err := s.display.Roundtrip(); err != nil {
+		return fmt.Errorf("registry roundtrip failed: %w", err)
+	}
+	if s.notifier == nil {
+		return fmt.Errorf("compositor does not support %s", ext_idle_notify.ExtIdleNotifierV1InterfaceName)
+	}
+	if seat == nil {
+		return fmt.Errorf("no wl_seat available")
+	}
+
+	notification, err := s.notifier.GetIdleNotification(uint32(waylandIdleTimeout.Milliseconds()), seat)
+	if err != nil {
+		return fmt.Errorf("failed to create idle notification: %w", err)
+	}
+	notification.SetIdledHandler(func(ext_idle_notify.ExtIdleNotificationV1IdledEvent) {
+		s.markIdle(time.Now())
+	})
+	notification.SetResumedHandler(func(ext_idle_notify.ExtIdleNotificationV1ResumedEvent) {
+		s.markResumed()
+	})
+
+	return s.display.Roundtrip()
+}
+
+func (s *waylandSource) dispatch() {
+	defer s.wg.Done()
+	ctx := s.display.Context()
+	for {
+		if err := ctx.Dispatch(); err != nil {
+			s.mutex.Lock()
+			closed := s.closed
+			if !closed {
+				s.err = err
+				s.idleSince = time.Time{}
+			}
+			s.mutex.Unlock()
+			if !closed {
+				log.Errorf("idle: Wayland connection error: %v", err)
+			}
+			return
+		}
+	}
+}
+
+func (s *waylandSource) markIdle(now time.Time) {
+	s.mutex.Lock()
+	s.idleSince = now.Add(-waylandIdleTimeout)
+	onChange := s.onChange
+	s.mutex.Unlock()
+
+	if onChange != nil {
+		onChange()
+	}
+}
+
+func (s *waylandSource) markResumed() {
+	s.mutex.Lock()
+	s.idleSince = time.Time{}
+	onChange := s.onChange
+	s.mutex.Unlock()
+
+	if onChange != nil {
+		onChange()
+	}
+}
+
+func (s *waylandSource) setOnChange(fn func()) {
+	s.mutex.Lock()
+	s.onChange = fn
+	s.mutex.Unlock()
+}
+
+func (s *waylandSource) Name() string {
+	return "ext-idle-notify"
+}
+
+func (s *waylandSource) IdleTime() (time.Duration, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+	if s.idleSince.IsZero() {
+		return 0, nil
+	}
+	return time.Since(s.idleSince), nil
+}
+
+func (s *waylandSource) Close() {
+	s.mutex.Lock()
+	s.closed = true
+	s.onChange = nil
+	s.mutex.Unlock()
+
+	// Dispatch owns the objects; closing the connection releases them
+	s.display.Context().Close()
+	s.wg.Wait()
+}
@@ -0,0 +1,199 @@
+package idle
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+type Action string
+
+const (
+	ActionDim     Action = "dim"
+	ActionLock    Action = "lock"
+	ActionDPMS    Action = "dpms"
+	ActionSuspend Action = "suspend"
+)
+
+// Step runs Action once the session has been idle for Timeout seconds
+type Step struct {
+	Action        Action `json:"action"`
+	Timeout       int    `json:"timeout"`
+	OnBatteryOnly bool   `json:"onBatteryOnly"`
+}
+
+type Config struct {
+	Enabled         bool   `json:"enabled"`
+	Steps           []Step `json:"steps"`
+	PauseNightLight bool   `json:"pauseNightLight"`
+}
+
+// Override holds the timeline at zero until Until, or until cleared when Until is zero
+type Override struct {
+	Reason string    `json:"reason"`
+	Until  time.Time `json:"until,omitempty"`
+}
+
+type State struct {
+	Config      Config    `json:"config"`
+	Source      string    `json:"source"`
+	IdleSeconds int       `json:"idleSeconds"`
+	Fired       []Action  `json:"fired"`
+	OnBattery   bool      `json:"onBattery"`
+	Inhibited   bool      `json:"inhibited"`
+	InhibitedBy []string  `json:"inhibitedBy"`
+	Override    *Override `json:"override,omitempty"`
+	Caffeinated bool      `json:"caffeinated"`
+	// CaffeineRemaining is in seconds, 0 while caffeinated means until toggled off
+	CaffeineRemaining int `json:"caffeineRemaining"`
+}
+
+// Source reports how long the session has been idle
+type Source interface {
+	Name() string
+	IdleTime() (time.Duration, error)
+}
+
+// Executor carries out timeline actions and undoes them on activity
+type Executor interface {
+	Run(action Action) error
+	Resume(action Action) error
+}
+
+type Manager struct {
+	config      Config
+	override    *Override
+	configMutex sync.RWMutex
+	state       *State
+	stateMutex  sync.RWMutex
+
+	source     Source
+	executor   Executor
+	conn       *dbus.Conn
+	inhibitors func() []string
+	onBattery  func() bool
+	// apps maps apps that inhibit idle over IPC to their reason
+	apps map[string]string
+
+	fired        map[Action]bool
+	tickMutex    sync.Mutex
+	caffeinated  bool
+	inhibitFile  *os.File
+	nightLight   func(paused bool)
+	pollInterval time.Duration
+
+	subscribers  map[string]chan State
+	subMutex     sync.RWMutex
+	dirty        chan struct{}
+	stopChan     chan struct{}
+	wg           sync.WaitGroup
+	lastNotified *State
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Enabled: false,
+		Steps: []Step{
+			{Action: ActionDim, Timeout: 5 * 60},
+			{Action: ActionLock, Timeout: 10 * 60},
+			{Action: ActionDPMS, Timeout: 12 * 60},
+			{Action: ActionSuspend, Timeout: 30 * 60, OnBatteryOnly: true},
+		},
+	}
+}
+
+func (c *Config) Validate() error {
+	seen := make(map[Action]bool)
+	for _, step := range c.Steps {
+		switch step.Action {
+		case ActionDim, ActionLock, ActionDPMS, ActionSuspend:
+		default:
+			return fmt.Errorf("unknown idle action: %s", step.Action)
+		}
+		if step.Timeout <= 0 {
+			return fmt.Errorf("timeout for %s must be positive", step.Action)
+		}
+		if seen[step.Action] {
+			return fmt.Errorf("duplicate idle action: %s", step.Action)
+		}
+		seen[step.Action] = true
+	}
+
+	sort.SliceStable(c.Steps, func(i, j int) bool {
+		return c.Steps[i].Timeout < c.Steps[j].Timeout
+	})
+	return nil
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.state == nil {
+		return State{}
+	}
+	stateCopy := *m.state
+	return stateCopy
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func stateChanged(old, new *State) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	if old.Config.Enabled != new.Config.Enabled || old.Config.PauseNightLight != new.Config.PauseNightLight || len(old.Config.Steps) != len(new.Config.Steps) {
+		return true
+	}
+	for i := range old.Config.Steps {
+		if old.Config.Steps[i] != new.Config.Steps[i] {
+			return true
+		}
+	}
+	if len(old.Fired) != len(new.Fired) {
+		return true
+	}
+	if old.OnBattery != new.OnBattery || old.Inhibited != new.Inhibited || old.Caffeinated != new.Caffeinated {
+		return true
+	}
+	if !slices.Equal(old.InhibitedBy, new.InhibitedBy) {
+		return true
+	}
+	if (old.Override == nil) != (new.Override == nil) {
+		return true
+	}
+	if old.Override != nil && new.Override != nil && *old.Override != *new.Override {
+		return true
+	}
+	if old.CaffeineRemaining != new.CaffeineRemaining {
+		return true
+	}
+	// Idle time ticks constantly; only the minute boundary is worth an event
+	return old.IdleSeconds/60 != new.IdleSeconds/60
+}
@@ -0,0 +1,184 @@
+package kdeconnect
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	dbusDest          = "org.kde.kdeconnect"
+	dbusDaemonPath    = "/modules/kdeconnect"
+	dbusDaemonIface   = "org.kde.kdeconnect.daemon"
+	dbusDeviceIface   = "org.kde.kdeconnect.device"
+	dbusBatteryIface  = "org.kde.kdeconnect.device.battery"
+	dbusNotifsIface   = "org.kde.kdeconnect.device.notifications"
+	dbusNotifIface    = "org.kde.kdeconnect.device.notifications.notification"
+	dbusFindIface     = "org.kde.kdeconnect.device.findmyphone"
+	dbusClipIface     = "org.kde.kdeconnect.device.clipboard"
+	dbusPropsIface    = "org.freedesktop.DBus.Properties"
+	pairStatePaired   = 3
+	signalChannelSize = 64
+)
+
+type dbusBus struct {
+	conn    *dbus.Conn
+	signals chan *dbus.Signal
+	changes chan struct{}
+	stop    chan struct{}
+}
+
+func newDBusBus() (*dbusBus, error) {
+	conn, err := dbus.ConnectSessionBus(budget.DBusOptions("kdeconnect")...)
+	if err != nil {
+		return nil, err
+	}
+	if !nameAvailable(conn) {
+		conn.Close()
+		return nil, fmt.Errorf("kdeconnectd is not installed")
+	}
+
+	b := &dbusBus{
+		conn:    conn,
+		signals: make(chan *dbus.Signal, signalChannelSize),
+		changes: make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+
+	// kdeconnectd has its own signal per change, e.g. reachableChanged or
+	// notificationPosted, so any of its signals triggers a refresh
+	matches := [][]dbus.MatchOption{
+		{dbus.WithMatchSender(dbusDest), dbus.WithMatchPathNamespace(dbusDaemonPath)},
+		{dbus.WithMatchInterface("org.freedesktop.DBus"), dbus.WithMatchMember("NameOwnerChanged"), dbus.WithMatchArg(0, dbusDest)},
+	}
+	for _, match := range matches {
+		if err := conn.AddMatchSignal(match...); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	conn.Signal(b.signals)
+
+	go b.pump()
+	return b, nil
+}
+
+// nameAvailable is true when kdeconnectd runs or D-Bus can start it
+func nameAvailable(conn *dbus.Conn) bool {
+	var owned bool
+	if err := conn.BusObject().Call("org.freedesktop.DBus.NameHasOwner", 0, dbusDest).Store(&owned); err == nil && owned {
+		return true
+	}
+	var names []string
+	if err := conn.BusObject().Call("org.freedesktop.DBus.ListActivatableNames", 0).Store(&names); err != nil {
+		return false
+	}
+	for _, name := range names {
+		if name == dbusDest {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *dbusBus) pump() {
+	for {
+		select {
+		case <-b.stop:
+			return
+		case sig, ok := <-b.signals:
+			if !ok {
+				return
+			}
+			if sig == nil {
+				continue
+			}
+			select {
+			case b.changes <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func (b *dbusBus) Changes() <-chan struct{} {
+	return b.changes
+}
+
+func devicePath(id string) dbus.ObjectPath {
+	return dbus.ObjectPath(dbusDaemonPath + "/devices/" + id)
+}
+
+func (b *dbusBus) getAll(path dbus.ObjectPath, iface string) (map[string]dbus.Variant, error) {
+	var props map[string]dbus.Variant
+	err := b.conn.Object(dbusDest, path).Call(dbusPropsIface+".GetAll", 0, iface).Store(&props)
+	return props, err
+}
+
+func (b *dbusBus) Devices() ([]Device, error) {
+	var ids []string
+	if err := b.conn.Object(dbusDest, dbusDaemonPath).Call(dbusDaemonIface+".devices", 0, false, false).Store(&ids); err != nil {
+		return nil, err
+	}
+	sort.Strings(ids)
+
+	devices := make([]Device, 0, len(ids))
+	for _, id := range ids {
+		props, err := b.getAll(devicePath(id), dbusDeviceIface)
+		if err != nil {
+			continue
+		}
+		device := deviceFromProps(id, props)
+		if device.Paired && device.Reachable {
+			if props, err := b.getAll(devicePath(id)+"/battery", dbusBatteryIface); err == nil {
+				device.Battery = batteryFromProps(props)
+			}
+			device.Notifications = b.notifications(id)
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+func (b *dbusBus) notifications(device string) []Notification {
+	path := devicePath(device) + "/notifications"
+	var ids []string
+	if err := b.conn.Object(dbusDest, path).Call(dbusNotifsIface+".activeNotifications", 0).Store(&ids); err != nil {
+		return []Notification{}
+	}
+
+	notifications := make([]Notification, 0, len(ids))
+	for _, id := range ids {
+		props, err := b.getAll(path+"/"+dbus.ObjectPath(id), dbusNotifIface)
+		if err != nil {
+			continue
+		}
+		notifications = append(notifications, notificationFromProps(id, props))
+	}
+	return notifications
+}
+
+func (b *dbusBus) Ring(device string) error {
+	return b.conn.Object(dbusDest, devicePath(device)+"/findmyphone").Call(dbusFindIface+".ring", 0).Err
+}
+
+func (b *dbusBus) SendClipboard(device, text string) error {
+	obj := b.conn.Object(dbusDest, devicePath(device)+"/clipboard")
+	if text == "" {
+		return obj.Call(dbusClipIface+".sendClipboard", 0).Err
+	}
+	return obj.Call(dbusClipIface+".sendClipboard", 0, text).Err
+}
+
+func (b *dbusBus) Dismiss(device, notification string) error {
+	path := devicePath(device) + "/notifications/" + dbus.ObjectPath(notification)
+	return b.conn.Object(dbusDest, path).Call(dbusNotifIface+".dismiss", 0).Err
+}
+
+func (b *dbusBus) Close() {
+	close(b.stop)
+	b.conn.RemoveSignal(b.signals)
+	b.conn.Close()
+}
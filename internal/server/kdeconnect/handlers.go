@@ -0,0 +1,98 @@
+package kdeconnect
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "kdeconnect manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "kdeconnect.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "kdeconnect.ring":
+		device, ok := req.Params["device"].(string)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'device' parameter")
+			return
+		}
+		if err := manager.Ring(device); err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "ringing"})
+	case "kdeconnect.sendClipboard":
+		device, ok := req.Params["device"].(string)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'device' parameter")
+			return
+		}
+		text, _ := req.Params["text"].(string)
+		if err := manager.SendClipboard(device, text); err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "clipboard sent"})
+	case "kdeconnect.dismissNotification":
+		device, ok := req.Params["device"].(string)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'device' parameter")
+			return
+		}
+		id, ok := req.Params["id"].(string)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'id' parameter")
+			return
+		}
+		if err := manager.DismissNotification(device, id); err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "notification dismissed"})
+	case "kdeconnect.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
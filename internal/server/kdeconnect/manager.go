@@ -0,0 +1,183 @@
+package kdeconnect
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+// settleDelay batches the burst of signals kdeconnectd sends when a phone
+// connects into one refresh
+const settleDelay = 200 * time.Millisecond
+
+func NewManager() (*Manager, error) {
+	bus, err := newDBusBus()
+	if err != nil {
+		return nil, fmt.Errorf("kdeconnect not available: %w", err)
+	}
+
+	m := newManager(bus)
+	m.refresh()
+
+	m.wg.Add(2)
+	go m.watchLoop()
+	go m.notifier()
+	return m, nil
+}
+
+func newManager(bus Bus) *Manager {
+	return &Manager{
+		bus:         bus,
+		state:       &State{Devices: []Device{}},
+		subscribers: make(map[string]chan State),
+		dirty:       make(chan struct{}, 1),
+		stopChan:    make(chan struct{}),
+	}
+}
+
+func (m *Manager) watchLoop() {
+	defer m.wg.Done()
+	changes := m.bus.Changes()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-changes:
+		}
+
+		select {
+		case <-m.stopChan:
+			return
+		case <-time.After(settleDelay):
+		}
+		m.refresh()
+	}
+}
+
+func (m *Manager) refresh() {
+	devices, err := m.bus.Devices()
+	if err != nil {
+		log.Debugf("kdeconnect: failed to list devices: %v", err)
+		devices = nil
+	}
+	if devices == nil {
+		devices = []Device{}
+	}
+
+	m.stateMutex.Lock()
+	previous := make(map[string]bool)
+	for _, device := range m.state.Devices {
+		for _, n := range device.Notifications {
+			previous[device.ID+"/"+n.ID] = true
+		}
+	}
+
+	state := &State{Devices: devices}
+	if m.refreshed {
+		for _, device := range devices {
+			for _, n := range device.Notifications {
+				if !previous[device.ID+"/"+n.ID] && !n.Silent {
+					state.Events = append(state.Events, Event{Type: EventNotification, Device: device.ID, Notification: n})
+				}
+			}
+		}
+	}
+	m.refreshed = true
+	m.state = state
+	m.stateMutex.Unlock()
+
+	m.notifySubscribers()
+}
+
+// reachableDevice checks id is a paired device that is connected
+func (m *Manager) reachableDevice(id string) error {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	for _, device := range m.state.Devices {
+		if device.ID != id {
+			continue
+		}
+		if !device.Paired {
+			return fmt.Errorf("%s is not paired", device.Name)
+		}
+		if !device.Reachable {
+			return fmt.Errorf("%s is not connected", device.Name)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown device: %s", id)
+}
+
+// Ring makes the phone ring, even when it's on silent
+func (m *Manager) Ring(device string) error {
+	if err := m.reachableDevice(device); err != nil {
+		return err
+	}
+	if err := m.bus.Ring(device); err != nil {
+		return fmt.Errorf("failed to ring %s: %w", device, err)
+	}
+	return nil
+}
+
+func (m *Manager) SendClipboard(device, text string) error {
+	if err := m.reachableDevice(device); err != nil {
+		return err
+	}
+	if err := m.bus.SendClipboard(device, text); err != nil {
+		return fmt.Errorf("failed to send clipboard: %w", err)
+	}
+	return nil
+}
+
+// DismissNotification dismisses a mirrored notification on the phone too
+func (m *Manager) DismissNotification(device, id string) error {
+	if err := m.reachableDevice(device); err != nil {
+		return err
+	}
+	if err := m.bus.Dismiss(device, id); err != nil {
+		return fmt.Errorf("failed to dismiss notification: %w", err)
+	}
+	m.refresh()
+	return nil
+}
+
+func (m *Manager) notifier() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.dirty:
+			state := m.GetState()
+
+			m.subMutex.RLock()
+			if m.lastNotified != nil && !stateChanged(m.lastNotified, &state) {
+				m.subMutex.RUnlock()
+				continue
+			}
+			for _, ch := range m.subscribers {
+				select {
+				case ch <- state:
+				default:
+				}
+			}
+			m.subMutex.RUnlock()
+
+			m.lastNotified = &state
+		}
+	}
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	m.wg.Wait()
+	m.bus.Close()
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
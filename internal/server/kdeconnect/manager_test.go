@@ -0,0 +1,108 @@
+package kdeconnect
+
+import (
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBus struct {
+	devices   []Device
+	rung      []string
+	clipboard []string
+	dismissed []string
+	changes   chan struct{}
+}
+
+func newFakeBus() *fakeBus {
+	return &fakeBus{
+		devices: []Device{
+			{ID: "phone", Name: "Pixel", Type: "smartphone", Reachable: true, Paired: true,
+				Battery: &Battery{Charge: 64}, Notifications: []Notification{{ID: "1", App: "Chat", Title: "Old"}}},
+			{ID: "tablet", Name: "Tab", Type: "tablet", Paired: true, Notifications: []Notification{}},
+		},
+		changes: make(chan struct{}),
+	}
+}
+
+func (b *fakeBus) Devices() ([]Device, error) {
+	return append([]Device(nil), b.devices...), nil
+}
+
+func (b *fakeBus) Ring(device string) error {
+	b.rung = append(b.rung, device)
+	return nil
+}
+
+func (b *fakeBus) SendClipboard(device, text string) error {
+	b.clipboard = append(b.clipboard, device+":"+text)
+	return nil
+}
+
+func (b *fakeBus) Dismiss(device, id string) error {
+	b.dismissed = append(b.dismissed, device+"/"+id)
+	return nil
+}
+
+func (b *fakeBus) Changes() <-chan struct{} { return b.changes }
+func (b *fakeBus) Close()                   {}
+
+func TestNotificationEvents(t *testing.T) {
+	bus := newFakeBus()
+	m := newManager(bus)
+
+	m.refresh()
+	state := m.GetState()
+	require.Len(t, state.Devices, 2)
+	assert.Empty(t, state.Events, "notifications already on the phone aren't events")
+
+	posted := Notification{ID: "2", App: "Chat", Title: "New"}
+	silent := Notification{ID: "3", App: "Sync", Silent: true}
+	bus.devices[0].Notifications = append(bus.devices[0].Notifications, posted, silent)
+	m.refresh()
+	assert.Equal(t, []Event{{Type: EventNotification, Device: "phone", Notification: posted}}, m.GetState().Events)
+
+	m.refresh()
+	assert.Empty(t, m.GetState().Events)
+}
+
+func TestActionsNeedReachableDevice(t *testing.T) {
+	bus := newFakeBus()
+	m := newManager(bus)
+	m.refresh()
+
+	require.NoError(t, m.Ring("phone"))
+	require.NoError(t, m.SendClipboard("phone", "hello"))
+	require.NoError(t, m.DismissNotification("phone", "1"))
+	assert.Equal(t, []string{"phone"}, bus.rung)
+	assert.Equal(t, []string{"phone:hello"}, bus.clipboard)
+	assert.Equal(t, []string{"phone/1"}, bus.dismissed)
+
+	assert.Error(t, m.Ring("tablet"))
+	assert.Error(t, m.Ring("watch"))
+}
+
+func TestDeviceFromProps(t *testing.T) {
+	old := deviceFromProps("a", map[string]dbus.Variant{
+		"name":        dbus.MakeVariant("Pixel"),
+		"isReachable": dbus.MakeVariant(true),
+		"isPaired":    dbus.MakeVariant(true),
+	})
+	assert.True(t, old.Paired)
+	assert.True(t, old.Reachable)
+
+	newer := deviceFromProps("b", map[string]dbus.Variant{
+		"name":      dbus.MakeVariant("Pixel"),
+		"pairState": dbus.MakeVariant(int32(pairStatePaired)),
+	})
+	assert.True(t, newer.Paired)
+	assert.False(t, newer.Reachable)
+
+	assert.Nil(t, batteryFromProps(map[string]dbus.Variant{"charge": dbus.MakeVariant(int32(-1))}))
+	assert.Equal(t, &Battery{Charge: 80, Charging: true}, batteryFromProps(map[string]dbus.Variant{
+		"charge":     dbus.MakeVariant(int32(80)),
+		"isCharging": dbus.MakeVariant(true),
+	}))
+}
@@ -0,0 +1,64 @@
+package kdeconnect
+
+import "github.com/godbus/dbus/v5"
+
+func variantString(v dbus.Variant) string {
+	s, _ := v.Value().(string)
+	return s
+}
+
+func variantBool(v dbus.Variant) bool {
+	b, _ := v.Value().(bool)
+	return b
+}
+
+func variantInt(v dbus.Variant) int {
+	switch n := v.Value().(type) {
+	case int32:
+		return int(n)
+	case uint32:
+		return int(n)
+	case int64:
+		return int(n)
+	}
+	return 0
+}
+
+// deviceFromProps reads a device. Older kdeconnectd has isPaired, newer
+// releases replaced it with pairState
+func deviceFromProps(id string, props map[string]dbus.Variant) Device {
+	device := Device{
+		ID:            id,
+		Name:          variantString(props["name"]),
+		Type:          variantString(props["type"]),
+		Reachable:     variantBool(props["isReachable"]),
+		Notifications: []Notification{},
+	}
+	if v, ok := props["isPaired"]; ok {
+		device.Paired = variantBool(v)
+	} else {
+		device.Paired = variantInt(props["pairState"]) == pairStatePaired
+	}
+	return device
+}
+
+func batteryFromProps(props map[string]dbus.Variant) *Battery {
+	charge, ok := props["charge"]
+	if !ok || variantInt(charge) < 0 {
+		return nil
+	}
+	return &Battery{Charge: variantInt(charge), Charging: variantBool(props["isCharging"])}
+}
+
+func notificationFromProps(id string, props map[string]dbus.Variant) Notification {
+	return Notification{
+		ID:          id,
+		App:         variantString(props["appName"]),
+		Title:       variantString(props["title"]),
+		Text:        variantString(props["text"]),
+		Ticker:      variantString(props["ticker"]),
+		IconPath:    variantString(props["iconPath"]),
+		Dismissable: variantBool(props["dismissable"]),
+		Silent:      variantBool(props["silent"]),
+	}
+}
@@ -0,0 +1,120 @@
+package kdeconnect
+
+import (
+	"reflect"
+	"sync"
+)
+
+const EventNotification = "notification"
+
+type Battery struct {
+	Charge   int  `json:"charge"`
+	Charging bool `json:"charging"`
+}
+
+// Notification is one mirrored from the phone
+type Notification struct {
+	ID          string `json:"id"`
+	App         string `json:"app"`
+	Title       string `json:"title"`
+	Text        string `json:"text"`
+	Ticker      string `json:"ticker"`
+	IconPath    string `json:"iconPath,omitempty"`
+	Dismissable bool   `json:"dismissable"`
+	Silent      bool   `json:"silent"`
+}
+
+type Device struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Reachable bool   `json:"reachable"`
+	Paired    bool   `json:"paired"`
+	// Battery is nil when the device doesn't share its battery
+	Battery       *Battery       `json:"battery,omitempty"`
+	Notifications []Notification `json:"notifications"`
+}
+
+type Event struct {
+	Type         string       `json:"type"`
+	Device       string       `json:"device"`
+	Notification Notification `json:"notification"`
+}
+
+type State struct {
+	Devices []Device `json:"devices"`
+	// Events are the notifications posted since the previous refresh
+	Events []Event `json:"events,omitempty"`
+}
+
+// Bus talks to kdeconnectd
+type Bus interface {
+	Devices() ([]Device, error)
+	Ring(device string) error
+	// SendClipboard shares text, or the desktop clipboard when it is empty
+	SendClipboard(device, text string) error
+	Dismiss(device, notification string) error
+	// Changes fires when kdeconnectd reports anything, or restarts
+	Changes() <-chan struct{}
+	Close()
+}
+
+type Manager struct {
+	bus Bus
+
+	state      *State
+	stateMutex sync.RWMutex
+	// refreshed is set after the first refresh, whose notifications were
+	// already on the phone and aren't reported as events
+	refreshed bool
+
+	subscribers  map[string]chan State
+	subMutex     sync.RWMutex
+	dirty        chan struct{}
+	stopChan     chan struct{}
+	wg           sync.WaitGroup
+	lastNotified *State
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.state == nil {
+		return State{}
+	}
+	stateCopy := *m.state
+	stateCopy.Devices = append([]Device(nil), m.state.Devices...)
+	stateCopy.Events = append([]Event(nil), m.state.Events...)
+	return stateCopy
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func stateChanged(old, new *State) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	return !reflect.DeepEqual(old, new)
+}
@@ -0,0 +1,120 @@
+package keyboard
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type hyprlandAdapter struct{}
+
+func (a *hyprlandAdapter) Name() string {
+	return "hyprland"
+}
+
+type hyprDevices struct {
+	Keyboards []struct {
+		Name              string `json:"name"`
+		Layout            string `json:"layout"`
+		ActiveKeymap      string `json:"active_keymap"`
+		ActiveLayoutIndex *int   `json:"active_layout_index"`
+		Main              bool   `json:"main"`
+	} `json:"keyboards"`
+}
+
+// parseHyprlandDevices reads the main keyboard from hyprctl devices -j. The
+// layout codes come from its comma separated kb_layout
+func parseHyprlandDevices(data []byte) ([]string, int, error) {
+	var devices hyprDevices
+	if err := json.Unmarshal(data, &devices); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse hyprctl devices: %w", err)
+	}
+	if len(devices.Keyboards) == 0 {
+		return nil, 0, fmt.Errorf("no keyboards reported by hyprctl")
+	}
+
+	keyboard := devices.Keyboards[0]
+	for _, kb := range devices.Keyboards {
+		if kb.Main {
+			keyboard = kb
+			break
+		}
+	}
+
+	var layouts []string
+	for _, layout := range strings.Split(keyboard.Layout, ",") {
+		if layout = strings.TrimSpace(layout); layout != "" {
+			layouts = append(layouts, layout)
+		}
+	}
+
+	current := 0
+	if keyboard.ActiveLayoutIndex != nil {
+		current = *keyboard.ActiveLayoutIndex
+	}
+	return layouts, current, nil
+}
+
+func (a *hyprlandAdapter) Layouts() ([]string, int, error) {
+	output, err := exec.Command("hyprctl", "devices", "-j").Output()
+	if err != nil {
+		return nil, 0, fmt.Errorf("hyprctl devices failed: %w", err)
+	}
+	return parseHyprlandDevices(output)
+}
+
+func (a *hyprlandAdapter) Switch(target string) error {
+	return runCommand("hyprctl", "switchxkblayout", "all", target)
+}
+
+func (a *hyprlandAdapter) FocusedWindow() (string, error) {
+	output, err := exec.Command("hyprctl", "activewindow", "-j").Output()
+	if err != nil {
+		return "", err
+	}
+
+	var window struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(output, &window); err != nil {
+		return "", err
+	}
+	return window.Address, nil
+}
+
+func hyprlandEventSocket() string {
+	signature := os.Getenv("HYPRLAND_INSTANCE_SIGNATURE")
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		path := filepath.Join(runtimeDir, "hypr", signature, ".socket2.sock")
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join("/tmp", "hypr", signature, ".socket2.sock")
+}
+
+func (a *hyprlandAdapter) Watch(stop <-chan struct{}, changed func()) error {
+	conn, err := net.Dial("unix", hyprlandEventSocket())
+	if err != nil {
+		return fmt.Errorf("failed to connect to hyprland event socket: %w", err)
+	}
+
+	go func() {
+		<-stop
+		conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "activelayout>>") || strings.HasPrefix(line, "activewindowv2>>") {
+			changed()
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,81 @@
+package keyboard
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+type niriAdapter struct{}
+
+func (a *niriAdapter) Name() string {
+	return "niri"
+}
+
+type niriLayouts struct {
+	Names      []string `json:"names"`
+	CurrentIdx int      `json:"current_idx"`
+}
+
+func parseNiriLayouts(data []byte) ([]string, int, error) {
+	var layouts niriLayouts
+	if err := json.Unmarshal(data, &layouts); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse niri keyboard layouts: %w", err)
+	}
+	return layouts.Names, layouts.CurrentIdx, nil
+}
+
+func (a *niriAdapter) Layouts() ([]string, int, error) {
+	output, err := exec.Command("niri", "msg", "--json", "keyboard-layouts").Output()
+	if err != nil {
+		return nil, 0, fmt.Errorf("niri msg keyboard-layouts failed: %w", err)
+	}
+	return parseNiriLayouts(output)
+}
+
+func (a *niriAdapter) Switch(target string) error {
+	return runCommand("niri", "msg", "action", "switch-layout", target)
+}
+
+func (a *niriAdapter) FocusedWindow() (string, error) {
+	output, err := exec.Command("niri", "msg", "--json", "focused-window").Output()
+	if err != nil {
+		return "", err
+	}
+
+	var window *struct {
+		ID uint64 `json:"id"`
+	}
+	if err := json.Unmarshal(output, &window); err != nil || window == nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d", window.ID), nil
+}
+
+func (a *niriAdapter) Watch(stop <-chan struct{}, changed func()) error {
+	cmd := exec.Command("niri", "msg", "--json", "event-stream")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	go func() {
+		<-stop
+		cmd.Process.Kill()
+	}()
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, `{"KeyboardLayout`) || strings.HasPrefix(line, `{"WindowFocusChanged"`) {
+			changed()
+		}
+	}
+	return cmd.Wait()
+}
@@ -0,0 +1,105 @@
+package keyboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "keyboard manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "keyboard.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "keyboard.next":
+		respondLayout(conn, req, manager, manager.Next())
+	case "keyboard.prev":
+		respondLayout(conn, req, manager, manager.Prev())
+	case "keyboard.set":
+		handleSet(conn, req, manager)
+	case "keyboard.setPerWindow":
+		handleSetPerWindow(conn, req, manager)
+	case "keyboard.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func respondLayout(conn net.Conn, req Request, manager *Manager, err error) {
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: manager.GetState().Layout})
+}
+
+func handleSet(conn net.Conn, req Request, manager *Manager) {
+	var layout string
+	switch value := req.Params["layout"].(type) {
+	case string:
+		layout = value
+	case float64:
+		layout = fmt.Sprintf("%d", int(value))
+	}
+	if layout == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'layout' parameter")
+		return
+	}
+
+	respondLayout(conn, req, manager, manager.Set(layout))
+}
+
+func handleSetPerWindow(conn net.Conn, req Request, manager *Manager) {
+	enabled, ok := req.Params["enabled"].(bool)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'enabled' parameter")
+		return
+	}
+
+	if err := manager.SetPerWindow(enabled); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "per-window layout memory set"})
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
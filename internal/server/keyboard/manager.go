@@ -0,0 +1,221 @@
+package keyboard
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+// DetectAdapter picks the adapter for the running compositor
+func DetectAdapter() (Adapter, error) {
+	switch {
+	case os.Getenv("NIRI_SOCKET") != "":
+		return &niriAdapter{}, nil
+	case os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "":
+		return &hyprlandAdapter{}, nil
+	}
+	return nil, fmt.Errorf("keyboard layouts are only supported on niri and Hyprland")
+}
+
+func NewManager() (*Manager, error) {
+	adapter, err := DetectAdapter()
+	if err != nil {
+		return nil, err
+	}
+
+	m := newManager(adapter)
+	if err := m.refresh(); err != nil {
+		return nil, err
+	}
+
+	m.wg.Add(2)
+	go m.notifier()
+	go m.watch()
+	return m, nil
+}
+
+func newManager(adapter Adapter) *Manager {
+	return &Manager{
+		adapter:       adapter,
+		state:         &State{Compositor: adapter.Name(), Layouts: []string{}},
+		windowLayouts: make(map[string]int),
+		subscribers:   make(map[string]chan State),
+		dirty:         make(chan struct{}, 1),
+		stopChan:      make(chan struct{}),
+	}
+}
+
+func runCommand(name string, args ...string) error {
+	if output, err := exec.Command(name, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %s", name, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (m *Manager) watch() {
+	defer m.wg.Done()
+	for {
+		err := m.adapter.Watch(m.stopChan, func() {
+			if err := m.refresh(); err != nil {
+				log.Debugf("keyboard: refresh failed: %v", err)
+			}
+		})
+
+		select {
+		case <-m.stopChan:
+			return
+		case <-time.After(2 * time.Second):
+			log.Debugf("keyboard: %s event stream ended, reconnecting: %v", m.adapter.Name(), err)
+		}
+	}
+}
+
+// refresh re-reads the layouts and, with per-window memory on, remembers the
+// layout of the window losing focus and restores the one gaining it
+func (m *Manager) refresh() error {
+	m.refreshMutex.Lock()
+	defer m.refreshMutex.Unlock()
+
+	layouts, current, err := m.adapter.Layouts()
+	if err != nil {
+		return err
+	}
+
+	if m.perWindow {
+		if window, err := m.adapter.FocusedWindow(); err == nil && window != m.focused {
+			if m.focused != "" {
+				m.windowLayouts[m.focused] = current
+			}
+			m.focused = window
+
+			if remembered, ok := m.windowLayouts[window]; ok && remembered != current && remembered < len(layouts) {
+				if err := m.adapter.Switch(strconv.Itoa(remembered)); err == nil {
+					current = remembered
+				}
+			}
+		} else if err == nil && window != "" {
+			m.windowLayouts[window] = current
+		}
+	}
+
+	layout := ""
+	if current >= 0 && current < len(layouts) {
+		layout = layouts[current]
+	}
+
+	m.stateMutex.Lock()
+	m.state.Layouts = layouts
+	m.state.Current = current
+	m.state.Layout = layout
+	m.state.PerWindow = m.perWindow
+	m.stateMutex.Unlock()
+
+	m.notifySubscribers()
+	return nil
+}
+
+func (m *Manager) Next() error {
+	return m.switchTo("next")
+}
+
+func (m *Manager) Prev() error {
+	return m.switchTo("prev")
+}
+
+// Set accepts a layout index or name; names match case-insensitively, and a
+// unique prefix (e.g. "ger" for "German") is enough
+func (m *Manager) Set(layout string) error {
+	state := m.GetState()
+	index, err := resolveLayout(state.Layouts, layout)
+	if err != nil {
+		return err
+	}
+	return m.switchTo(strconv.Itoa(index))
+}
+
+func resolveLayout(layouts []string, layout string) (int, error) {
+	if index, err := strconv.Atoi(layout); err == nil {
+		if index < 0 || index >= len(layouts) {
+			return 0, fmt.Errorf("layout index %d out of range (have %d layouts)", index, len(layouts))
+		}
+		return index, nil
+	}
+
+	match := -1
+	for i, name := range layouts {
+		if strings.EqualFold(name, layout) {
+			return i, nil
+		}
+		if strings.HasPrefix(strings.ToLower(name), strings.ToLower(layout)) {
+			if match >= 0 {
+				return 0, fmt.Errorf("layout %q is ambiguous", layout)
+			}
+			match = i
+		}
+	}
+	if match < 0 {
+		return 0, fmt.Errorf("unknown layout %q (have: %s)", layout, strings.Join(layouts, ", "))
+	}
+	return match, nil
+}
+
+func (m *Manager) switchTo(target string) error {
+	if err := m.adapter.Switch(target); err != nil {
+		return err
+	}
+	return m.refresh()
+}
+
+func (m *Manager) SetPerWindow(enabled bool) error {
+	m.refreshMutex.Lock()
+	m.perWindow = enabled
+	m.focused = ""
+	m.windowLayouts = make(map[string]int)
+	m.refreshMutex.Unlock()
+
+	return m.refresh()
+}
+
+func (m *Manager) notifier() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.dirty:
+			state := m.GetState()
+
+			m.subMutex.RLock()
+			if m.lastNotified != nil && !stateChanged(m.lastNotified, &state) {
+				m.subMutex.RUnlock()
+				continue
+			}
+			for _, ch := range m.subscribers {
+				select {
+				case ch <- state:
+				default:
+				}
+			}
+			m.subMutex.RUnlock()
+
+			m.lastNotified = &state
+		}
+	}
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	m.wg.Wait()
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
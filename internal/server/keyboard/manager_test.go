@@ -0,0 +1,119 @@
+package keyboard
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAdapter struct {
+	layouts []string
+	current int
+	focused string
+}
+
+func (a *fakeAdapter) Name() string { return "fake" }
+
+func (a *fakeAdapter) Layouts() ([]string, int, error) { return a.layouts, a.current, nil }
+
+func (a *fakeAdapter) Switch(target string) error {
+	switch target {
+	case "next":
+		a.current = (a.current + 1) % len(a.layouts)
+	case "prev":
+		a.current = (a.current + len(a.layouts) - 1) % len(a.layouts)
+	default:
+		index, err := strconv.Atoi(target)
+		if err != nil {
+			return err
+		}
+		a.current = index
+	}
+	return nil
+}
+
+func (a *fakeAdapter) FocusedWindow() (string, error) { return a.focused, nil }
+
+func (a *fakeAdapter) Watch(stop <-chan struct{}, changed func()) error {
+	<-stop
+	return nil
+}
+
+func TestParseNiriLayouts(t *testing.T) {
+	layouts, current, err := parseNiriLayouts([]byte(`{"names":["English (US)","German"],"current_idx":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"English (US)", "German"}, layouts)
+	assert.Equal(t, 1, current)
+}
+
+func TestParseHyprlandDevices(t *testing.T) {
+	data := []byte(`{"keyboards":[
+		{"name":"power-button","layout":"us","active_keymap":"English (US)","main":false},
+		{"name":"at-keyboard","layout":"us, de","active_keymap":"German","active_layout_index":1,"main":true}
+	]}`)
+	layouts, current, err := parseHyprlandDevices(data)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"us", "de"}, layouts)
+	assert.Equal(t, 1, current)
+
+	_, _, err = parseHyprlandDevices([]byte(`{"keyboards":[]}`))
+	assert.Error(t, err)
+}
+
+func TestResolveLayout(t *testing.T) {
+	layouts := []string{"English (US)", "English (UK)", "German"}
+
+	index, err := resolveLayout(layouts, "german")
+	require.NoError(t, err)
+	assert.Equal(t, 2, index)
+
+	index, err = resolveLayout(layouts, "1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, index)
+
+	index, err = resolveLayout(layouts, "ger")
+	require.NoError(t, err)
+	assert.Equal(t, 2, index)
+
+	_, err = resolveLayout(layouts, "eng")
+	assert.Error(t, err, "ambiguous prefix")
+	_, err = resolveLayout(layouts, "5")
+	assert.Error(t, err)
+	_, err = resolveLayout(layouts, "french")
+	assert.Error(t, err)
+}
+
+func TestManager_NextAndSet(t *testing.T) {
+	adapter := &fakeAdapter{layouts: []string{"us", "de", "fr"}}
+	m := newManager(adapter)
+	require.NoError(t, m.refresh())
+
+	require.NoError(t, m.Next())
+	assert.Equal(t, "de", m.GetState().Layout)
+
+	require.NoError(t, m.Prev())
+	assert.Equal(t, 0, m.GetState().Current)
+
+	require.NoError(t, m.Set("fr"))
+	assert.Equal(t, "fr", m.GetState().Layout)
+}
+
+func TestManager_PerWindowMemory(t *testing.T) {
+	adapter := &fakeAdapter{layouts: []string{"us", "de"}, focused: "editor"}
+	m := newManager(adapter)
+	require.NoError(t, m.SetPerWindow(true))
+
+	adapter.focused = "chat"
+	require.NoError(t, m.refresh())
+	require.NoError(t, m.Set("de"))
+
+	adapter.focused = "editor"
+	require.NoError(t, m.refresh())
+	assert.Equal(t, "us", m.GetState().Layout, "editor keeps its layout")
+
+	adapter.focused = "chat"
+	require.NoError(t, m.refresh())
+	assert.Equal(t, "de", m.GetState().Layout, "chat gets its layout back")
+}
@@ -0,0 +1,97 @@
+package keyboard
+
+import (
+	"sync"
+)
+
+type State struct {
+	Compositor string   `json:"compositor"`
+	Layouts    []string `json:"layouts"`
+	Current    int      `json:"current"`
+	Layout     string   `json:"layout"`
+	PerWindow  bool     `json:"perWindow"`
+}
+
+// Adapter drives keyboard layouts through a compositor's own IPC
+type Adapter interface {
+	Name() string
+	// Layouts returns the configured layout names and the active index
+	Layouts() ([]string, int, error)
+	// Switch takes "next", "prev" or a layout index
+	Switch(target string) error
+	FocusedWindow() (string, error)
+	// Watch blocks, calling changed on layout or focus events, until stop closes
+	Watch(stop <-chan struct{}, changed func()) error
+}
+
+type Manager struct {
+	adapter Adapter
+
+	state      *State
+	stateMutex sync.RWMutex
+
+	perWindow     bool
+	windowLayouts map[string]int
+	focused       string
+	refreshMutex  sync.Mutex
+
+	subscribers  map[string]chan State
+	subMutex     sync.RWMutex
+	dirty        chan struct{}
+	stopChan     chan struct{}
+	wg           sync.WaitGroup
+	lastNotified *State
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.state == nil {
+		return State{}
+	}
+	stateCopy := *m.state
+	stateCopy.Layouts = append([]string(nil), m.state.Layouts...)
+	return stateCopy
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func stateChanged(old, new *State) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	if old.Current != new.Current || old.Layout != new.Layout || old.PerWindow != new.PerWindow {
+		return true
+	}
+	if len(old.Layouts) != len(new.Layouts) {
+		return true
+	}
+	for i := range old.Layouts {
+		if old.Layouts[i] != new.Layouts[i] {
+			return true
+		}
+	}
+	return false
+}
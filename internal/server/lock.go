@@ -0,0 +1,173 @@
+package server
+
+import (
+	"net"
+	"sort"
+	"strings"
+)
+
+// lockedMethods is everything that may run while the session is locked: what
+// the lock screen and the bar hidden under it need to keep their state
+// current, plus the few toggles a lock screen offers. Anything not listed,
+// including every method added later, is refused until the session unlocks
+var lockedMethods = map[string]bool{
+	"ping":          true,
+	"getServerInfo": true,
+	"getHealth":     true,
+	"subscribe":     true,
+
+	"loginctl.getState":                    true,
+	"loginctl.lock":                        true,
+	"loginctl.unlock":                      true,
+	"loginctl.activate":                    true,
+	"loginctl.setIdleHint":                 true,
+	"loginctl.lockerReady":                 true,
+	"loginctl.subscribe":                   true,
+	"network.getState":                     true,
+	"network.wifi.toggle":                  true,
+	"network.wifi.enable":                  true,
+	"network.wifi.disable":                 true,
+	"network.connectivity.get":             true,
+	"network.subscribe":                    true,
+	"idle.getState":                        true,
+	"idle.subscribe":                       true,
+	"keyboard.getState":                    true,
+	"keyboard.next":                        true,
+	"keyboard.prev":                        true,
+	"keyboard.set":                         true,
+	"keyboard.subscribe":                   true,
+	"wallpaper.getState":                   true,
+	"wallpaper.subscribe":                  true,
+	"magnifier.getState":                   true,
+	"magnifier.zoomIn":                     true,
+	"magnifier.zoomOut":                    true,
+	"magnifier.toggle":                     true,
+	"magnifier.set":                        true,
+	"magnifier.subscribe":                  true,
+	"privacy.getState":                     true,
+	"privacy.toggleMicMute":                true,
+	"privacy.setMicMute":                   true,
+	"privacy.subscribe":                    true,
+	"hwmon.getState":                       true,
+	"hwmon.subscribe":                      true,
+	"rfkill.getState":                      true,
+	"rfkill.setAirplaneMode":               true,
+	"rfkill.subscribe":                     true,
+	"breaks.getState":                      true,
+	"breaks.subscribe":                     true,
+	"timers.getState":                      true,
+	"timers.get":                           true,
+	"timers.subscribe":                     true,
+	"timezones.getState":                   true,
+	"timezones.subscribe":                  true,
+	"alerts.getState":                      true,
+	"alerts.subscribe":                     true,
+	"wayland.gamma.getState":               true,
+	"wayland.gamma.subscribe":              true,
+	"bluetooth.getState":                   true,
+	"bluetooth.subscribe":                  true,
+	"dwl.getState":                         true,
+	"dwl.subscribe":                        true,
+	"freedesktop.getState":                 true,
+	"freedesktop.accounts.getUserIconFile": true,
+}
+
+// lockedServices are the streams the meta subscribe may carry while locked.
+// Credential and pairing prompts, mirrored phone notifications and the
+// like stay off until the session unlocks, on subscriptions made before it
+// locked too
+var lockedServices = map[string]bool{
+	"network":   true,
+	"loginctl":  true,
+	"gamma":     true,
+	"bluetooth": true,
+	"dwl":       true,
+	"idle":      true,
+	"keyboard":  true,
+	"wallpaper": true,
+	"magnifier": true,
+	"privacy":   true,
+	"hwmon":     true,
+	"rfkill":    true,
+	"breaks":    true,
+	"timers":    true,
+	"timezones": true,
+	"alerts":    true,
+}
+
+// isLockedMethodAllowed reports whether method may run while the session is
+// locked. The lock screen is drawn by the shell, so anything that could
+// leak secrets to whoever is at the keyboard is refused here instead
+func isLockedMethodAllowed(method string) bool {
+	return lockedMethods[method]
+}
+
+// lockedSubscribeServices narrows the services a meta subscribe asks for to
+// lockedServices, expanding "all" (or none) to every one of them, so a
+// shell restarted under the lock still gets its state
+func lockedSubscribeServices(params map[string]interface{}) []interface{} {
+	var requested []string
+	if servicesParam, ok := params["services"].([]interface{}); ok {
+		for _, s := range servicesParam {
+			if str, ok := s.(string); ok {
+				requested = append(requested, str)
+			}
+		}
+	}
+
+	all := len(requested) == 0
+	for _, s := range requested {
+		if s == "all" {
+			all = true
+		}
+	}
+	if all {
+		requested = requested[:0]
+		for s := range lockedServices {
+			requested = append(requested, s)
+		}
+		sort.Strings(requested)
+	}
+
+	var allowed []interface{}
+	for _, s := range requested {
+		if lockedServices[s] {
+			allowed = append(allowed, s)
+		}
+	}
+	return allowed
+}
+
+// sessionLocked asks logind rather than the shell, which may have crashed
+// or been replaced while the lock is up
+var sessionLocked = func() bool {
+	if loginctlManager == nil {
+		return false
+	}
+	state := loginctlManager.GetState()
+	return state.Locked || state.LockedHint
+}
+
+// lockGatedConn carries a module's own subscribe stream. The stream outlives
+// the request that opened it, so a session that locks afterwards is checked
+// on every write, and events from services refused while locked are dropped
+// until it unlocks
+type lockGatedConn struct {
+	net.Conn
+}
+
+func (c *lockGatedConn) Write(p []byte) (int, error) {
+	if sessionLocked() {
+		return len(p), nil
+	}
+	return c.Conn.Write(p)
+}
+
+// gateLockedStream wraps conn for a subscribe method that isn't allowed while
+// locked; others keep conn as is
+func gateLockedStream(conn net.Conn, method string) net.Conn {
+	if !strings.HasSuffix(method, ".subscribe") || isLockedMethodAllowed(method) {
+		return conn
+	}
+	return &lockGatedConn{conn}
+}
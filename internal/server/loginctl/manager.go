@@ -7,11 +7,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
 	"github.com/godbus/dbus/v5"
 )
 
 func NewManager() (*Manager, error) {
-	conn, err := dbus.ConnectSystemBus()
+	conn, err := dbus.ConnectSystemBus(budget.DBusOptions("loginctl")...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to system bus: %w", err)
 	}
@@ -0,0 +1,87 @@
+package magnifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "magnifier manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "magnifier.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "magnifier.zoomIn":
+		respondZoom(conn, req, manager, manager.ZoomIn())
+	case "magnifier.zoomOut":
+		respondZoom(conn, req, manager, manager.ZoomOut())
+	case "magnifier.toggle":
+		respondZoom(conn, req, manager, manager.Toggle())
+	case "magnifier.set":
+		factor, ok := req.Params["zoom"].(float64)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'zoom' parameter")
+			return
+		}
+		respondZoom(conn, req, manager, manager.Set(factor))
+	case "magnifier.setStep":
+		step, ok := req.Params["step"].(float64)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'step' parameter")
+			return
+		}
+		respondZoom(conn, req, manager, manager.SetStep(step))
+	case "magnifier.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func respondZoom(conn net.Conn, req Request, manager *Manager, err error) {
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: fmt.Sprintf("zoom %gx", manager.GetState().Zoom)})
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
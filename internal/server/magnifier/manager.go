@@ -0,0 +1,157 @@
+package magnifier
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+func NewManager() (*Manager, error) {
+	zoomer, err := DetectZoomer()
+	if err != nil {
+		return nil, err
+	}
+
+	m := newManager(zoomer)
+	zoom, err := zoomer.Zoom()
+	if err != nil {
+		return nil, err
+	}
+	m.setState(zoom)
+
+	m.wg.Add(1)
+	go m.notifier()
+	return m, nil
+}
+
+func newManager(zoomer Zoomer) *Manager {
+	return &Manager{
+		zoomer:        zoomer,
+		state:         &State{Compositor: zoomer.Name(), Zoom: MinZoom, Step: DefaultStep},
+		lastZoom:      2,
+		animateSteps:  8,
+		animateLength: 160 * time.Millisecond,
+		subscribers:   make(map[string]chan State),
+		dirty:         make(chan struct{}, 1),
+		stopChan:      make(chan struct{}),
+	}
+}
+
+func (m *Manager) setState(zoom float64) {
+	m.stateMutex.Lock()
+	m.state.Zoom = zoom
+	m.state.Enabled = zoom > MinZoom
+	m.stateMutex.Unlock()
+	m.notifySubscribers()
+}
+
+func clampZoom(factor float64) float64 {
+	return math.Max(MinZoom, math.Min(MaxZoom, factor))
+}
+
+// Set animates to factor in small steps so zooming feels smooth rather than
+// jumping; concurrent calls queue behind each other
+func (m *Manager) Set(factor float64) error {
+	if math.IsNaN(factor) {
+		return fmt.Errorf("invalid zoom factor")
+	}
+	target := clampZoom(factor)
+
+	m.animateMutex.Lock()
+	defer m.animateMutex.Unlock()
+
+	from := m.GetState().Zoom
+	if target == from {
+		return nil
+	}
+
+	interval := m.animateLength / time.Duration(m.animateSteps)
+	for i := 1; i <= m.animateSteps; i++ {
+		// Ease out so the zoom settles gently on the target
+		t := float64(i) / float64(m.animateSteps)
+		eased := 1 - (1-t)*(1-t)
+		if err := m.zoomer.SetZoom(from + (target-from)*eased); err != nil {
+			return err
+		}
+		if i < m.animateSteps {
+			time.Sleep(interval)
+		}
+	}
+
+	if target > MinZoom {
+		m.lastZoom = target
+	}
+	m.setState(target)
+	return nil
+}
+
+func (m *Manager) ZoomIn() error {
+	state := m.GetState()
+	return m.Set(state.Zoom + state.Step)
+}
+
+func (m *Manager) ZoomOut() error {
+	state := m.GetState()
+	return m.Set(state.Zoom - state.Step)
+}
+
+// Toggle zooms out to 1x, or back to the last zoom level
+func (m *Manager) Toggle() error {
+	if m.GetState().Enabled {
+		return m.Set(MinZoom)
+	}
+	m.animateMutex.Lock()
+	last := m.lastZoom
+	m.animateMutex.Unlock()
+	return m.Set(last)
+}
+
+func (m *Manager) SetStep(step float64) error {
+	if step <= 0 || step > MaxZoom {
+		return fmt.Errorf("zoom step must be between 0 and %g", MaxZoom)
+	}
+	m.stateMutex.Lock()
+	m.state.Step = step
+	m.stateMutex.Unlock()
+	m.notifySubscribers()
+	return nil
+}
+
+func (m *Manager) notifier() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.dirty:
+			state := m.GetState()
+
+			m.subMutex.RLock()
+			if m.lastNotified != nil && !stateChanged(m.lastNotified, &state) {
+				m.subMutex.RUnlock()
+				continue
+			}
+			for _, ch := range m.subscribers {
+				select {
+				case ch <- state:
+				default:
+				}
+			}
+			m.subMutex.RUnlock()
+
+			m.lastNotified = &state
+		}
+	}
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	m.wg.Wait()
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
@@ -0,0 +1,77 @@
+package magnifier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeZoomer struct {
+	zoom  float64
+	calls []float64
+}
+
+func (z *fakeZoomer) Name() string { return "fake" }
+
+func (z *fakeZoomer) Zoom() (float64, error) { return z.zoom, nil }
+
+func (z *fakeZoomer) SetZoom(factor float64) error {
+	z.zoom = factor
+	z.calls = append(z.calls, factor)
+	return nil
+}
+
+func newTestManager() (*Manager, *fakeZoomer) {
+	zoomer := &fakeZoomer{zoom: 1}
+	m := newManager(zoomer)
+	m.animateLength = 0
+	return m, zoomer
+}
+
+func TestZoomSteps(t *testing.T) {
+	m, zoomer := newTestManager()
+
+	require.NoError(t, m.ZoomIn())
+	assert.Equal(t, 1.5, m.GetState().Zoom)
+	assert.True(t, m.GetState().Enabled)
+	assert.Len(t, zoomer.calls, m.animateSteps)
+	for i := 1; i < len(zoomer.calls); i++ {
+		assert.Greater(t, zoomer.calls[i], zoomer.calls[i-1], "animation is monotonic")
+	}
+	assert.Equal(t, 1.5, zoomer.zoom)
+
+	require.NoError(t, m.ZoomOut())
+	require.NoError(t, m.ZoomOut())
+	assert.Equal(t, MinZoom, m.GetState().Zoom, "zoom never goes below 1x")
+	assert.False(t, m.GetState().Enabled)
+
+	require.NoError(t, m.Set(50))
+	assert.Equal(t, MaxZoom, m.GetState().Zoom)
+}
+
+func TestToggleRestoresLastZoom(t *testing.T) {
+	m, _ := newTestManager()
+
+	require.NoError(t, m.Toggle())
+	assert.Equal(t, 2.0, m.GetState().Zoom)
+
+	require.NoError(t, m.Set(3))
+	require.NoError(t, m.Toggle())
+	assert.Equal(t, MinZoom, m.GetState().Zoom)
+	require.NoError(t, m.Toggle())
+	assert.Equal(t, 3.0, m.GetState().Zoom)
+
+	assert.Error(t, m.SetStep(0))
+}
+
+func TestParseHyprlandOption(t *testing.T) {
+	zoom, err := parseHyprlandOption([]byte(`{"option":"cursor:zoom_factor","float":2.500000,"set":true}`))
+	require.NoError(t, err)
+	assert.Equal(t, 2.5, zoom)
+
+	_, err = parseHyprlandOption([]byte(`{"option":"cursor:zoom_factor","int":1}`))
+	assert.Error(t, err)
+	_, err = parseHyprlandOption([]byte("no such option"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package magnifier
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	MinZoom     = 1.0
+	MaxZoom     = 10.0
+	DefaultStep = 0.5
+)
+
+type State struct {
+	Compositor string  `json:"compositor"`
+	Zoom       float64 `json:"zoom"`
+	Enabled    bool    `json:"enabled"`
+	Step       float64 `json:"step"`
+}
+
+// Zoomer drives a compositor's own zoom
+type Zoomer interface {
+	Name() string
+	Zoom() (float64, error)
+	SetZoom(factor float64) error
+}
+
+type Manager struct {
+	zoomer Zoomer
+
+	state      *State
+	stateMutex sync.RWMutex
+
+	// lastZoom is what toggle restores after zooming out to 1x
+	lastZoom      float64
+	animateMutex  sync.Mutex
+	animateSteps  int
+	animateLength time.Duration
+
+	subscribers  map[string]chan State
+	subMutex     sync.RWMutex
+	dirty        chan struct{}
+	stopChan     chan struct{}
+	wg           sync.WaitGroup
+	lastNotified *State
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.state == nil {
+		return State{}
+	}
+	stateCopy := *m.state
+	return stateCopy
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func stateChanged(old, new *State) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	return *old != *new
+}
@@ -0,0 +1,69 @@
+package magnifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// DetectZoomer picks the zoom control for the running compositor
+func DetectZoomer() (Zoomer, error) {
+	switch {
+	case os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "":
+		return newHyprlandZoomer()
+	case os.Getenv("NIRI_SOCKET") != "":
+		return nil, fmt.Errorf("niri does not provide a zoom action yet")
+	}
+	return nil, fmt.Errorf("zoom is only supported on Hyprland")
+}
+
+type hyprlandZoomer struct {
+	option string
+}
+
+// Hyprland renamed misc:cursor_zoom_factor to cursor:zoom_factor; use
+// whichever the running version knows
+func newHyprlandZoomer() (*hyprlandZoomer, error) {
+	for _, option := range []string{"cursor:zoom_factor", "misc:cursor_zoom_factor"} {
+		z := &hyprlandZoomer{option: option}
+		if _, err := z.Zoom(); err == nil {
+			return z, nil
+		}
+	}
+	return nil, fmt.Errorf("hyprland has no cursor zoom option")
+}
+
+func (z *hyprlandZoomer) Name() string { return "hyprland" }
+
+func (z *hyprlandZoomer) Zoom() (float64, error) {
+	output, err := exec.Command("hyprctl", "getoption", z.option, "-j").Output()
+	if err != nil {
+		return 0, fmt.Errorf("hyprctl getoption %s: %w", z.option, err)
+	}
+	return parseHyprlandOption(output)
+}
+
+func parseHyprlandOption(data []byte) (float64, error) {
+	var option struct {
+		Float *float64 `json:"float"`
+	}
+	if err := json.Unmarshal(data, &option); err != nil {
+		return 0, fmt.Errorf("unexpected hyprctl output: %s", strings.TrimSpace(string(data)))
+	}
+	if option.Float == nil {
+		return 0, fmt.Errorf("option is not a float")
+	}
+	return *option.Float, nil
+}
+
+func (z *hyprlandZoomer) SetZoom(factor float64) error {
+	value := strconv.FormatFloat(factor, 'f', 3, 64)
+	output, err := exec.Command("hyprctl", "keyword", z.option, value).CombinedOutput()
+	if err != nil || strings.TrimSpace(string(output)) != "ok" {
+		return fmt.Errorf("hyprctl keyword %s: %s", z.option, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package mdns
+
+import (
+	"sync"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	avahiDest          = "org.freedesktop.Avahi"
+	avahiServerIface   = "org.freedesktop.Avahi.Server"
+	avahiBrowserIface  = "org.freedesktop.Avahi.ServiceBrowser"
+	avahiIfaceUnspec   = int32(-1)
+	avahiProtoUnspec   = int32(-1)
+	avahiResultOurOwn  = 16
+	avahiSignalBufSize = 64
+)
+
+type avahiBrowser struct {
+	conn     *dbus.Conn
+	server   dbus.BusObject
+	signals  chan *dbus.Signal
+	browsers []dbus.ObjectPath
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func newAvahiBrowser() (*avahiBrowser, error) {
+	conn, err := dbus.ConnectSystemBus(budget.DBusOptions("mdns")...)
+	if err != nil {
+		return nil, err
+	}
+
+	server := conn.Object(avahiDest, "/")
+	var version string
+	if err := server.Call(avahiServerIface+".GetVersionString", 0).Store(&version); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	log.Debugf("mdns: using %s", version)
+
+	return &avahiBrowser{
+		conn:    conn,
+		server:  server,
+		signals: make(chan *dbus.Signal, avahiSignalBufSize),
+		stop:    make(chan struct{}),
+	}, nil
+}
+
+// Start browses for types. Avahi starts sending signals as soon as a browser
+// exists, before its path is known, so the match covers every browser
+func (b *avahiBrowser) Start(m *Manager, types []string) error {
+	if err := b.conn.AddMatchSignal(dbus.WithMatchInterface(avahiBrowserIface)); err != nil {
+		return err
+	}
+	b.conn.Signal(b.signals)
+
+	b.wg.Add(1)
+	go b.loop(m)
+
+	for _, t := range types {
+		var path dbus.ObjectPath
+		if err := b.server.Call(avahiServerIface+".ServiceBrowserNew", 0,
+			avahiIfaceUnspec, avahiProtoUnspec, t, "", uint32(0)).Store(&path); err != nil {
+			log.Warnf("mdns: failed to browse %s: %v", t, err)
+			continue
+		}
+		b.browsers = append(b.browsers, path)
+	}
+	return nil
+}
+
+func (b *avahiBrowser) loop(m *Manager) {
+	defer b.wg.Done()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case sig, ok := <-b.signals:
+			if !ok {
+				return
+			}
+			b.handleSignal(m, sig)
+		}
+	}
+}
+
+func (b *avahiBrowser) handleSignal(m *Manager, sig *dbus.Signal) {
+	switch sig.Name {
+	case avahiBrowserIface + ".ItemNew", avahiBrowserIface + ".ItemRemove":
+	case avahiBrowserIface + ".Failure":
+		log.Warnf("mdns: browser %s failed: %v", sig.Path, sig.Body)
+		return
+	default:
+		return
+	}
+
+	var inst instance
+	var flags uint32
+	if err := dbus.Store(sig.Body, &inst.iface, &inst.protocol, &inst.name, &inst.typ, &inst.domain, &flags); err != nil {
+		log.Debugf("mdns: unexpected %s: %v", sig.Name, err)
+		return
+	}
+	if flags&avahiResultOurOwn != 0 {
+		return
+	}
+
+	if sig.Name == avahiBrowserIface+".ItemRemove" {
+		m.itemRemoved(inst)
+		return
+	}
+
+	m.itemNew(inst)
+	// Resolving waits on the network, so it mustn't hold up other signals
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.resolve(m, inst)
+	}()
+}
+
+func (b *avahiBrowser) resolve(m *Manager, inst instance) {
+	var (
+		iface, protocol, aprotocol int32
+		name, typ, domain, host    string
+		address                    string
+		port                       uint16
+		txt                        [][]byte
+		flags                      uint32
+	)
+	err := b.server.Call(avahiServerIface+".ResolveService", 0,
+		inst.iface, inst.protocol, inst.name, inst.typ, inst.domain, avahiProtoUnspec, uint32(0)).
+		Store(&iface, &protocol, &name, &typ, &domain, &host, &aprotocol, &address, &port, &txt, &flags)
+	if err != nil {
+		log.Debugf("mdns: failed to resolve %s (%s): %v", inst.name, inst.typ, err)
+		return
+	}
+	m.itemResolved(inst, newService(inst, host, address, port, txt))
+}
+
+func (b *avahiBrowser) Close() {
+	select {
+	case <-b.stop:
+		return
+	default:
+	}
+	close(b.stop)
+
+	for _, path := range b.browsers {
+		b.conn.Object(avahiDest, path).Call(avahiBrowserIface+".Free", 0)
+	}
+	b.conn.RemoveSignal(b.signals)
+	b.conn.Close()
+	b.wg.Wait()
+}
@@ -0,0 +1,200 @@
+package mdns
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func NewManager() (*Manager, error) {
+	browser, err := newAvahiBrowser()
+	if err != nil {
+		return nil, fmt.Errorf("avahi not available: %w", err)
+	}
+
+	m := newManager(browser)
+	m.wg.Add(1)
+	go m.notifier()
+
+	types := make([]string, 0, len(serviceKinds))
+	for t := range serviceKinds {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	if err := browser.Start(m, types); err != nil {
+		m.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+func newManager(browser Browser) *Manager {
+	return &Manager{
+		browser:     browser,
+		seen:        make(map[instance]*Service),
+		state:       &State{Services: []Service{}},
+		subscribers: make(map[string]chan State),
+		dirty:       make(chan struct{}, 1),
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// itemNew records an announced instance; it shows up once resolved
+func (m *Manager) itemNew(inst instance) {
+	m.stateMutex.Lock()
+	if _, ok := m.seen[inst]; !ok {
+		m.seen[inst] = nil
+	}
+	m.stateMutex.Unlock()
+}
+
+// itemResolved adds the service unless the instance went away while it was
+// being resolved
+func (m *Manager) itemResolved(inst instance, svc Service) {
+	m.stateMutex.Lock()
+	if _, ok := m.seen[inst]; !ok {
+		m.stateMutex.Unlock()
+		return
+	}
+	m.seen[inst] = &svc
+	m.rebuild()
+	m.stateMutex.Unlock()
+
+	m.notifySubscribers()
+}
+
+func (m *Manager) itemRemoved(inst instance) {
+	m.stateMutex.Lock()
+	delete(m.seen, inst)
+	m.rebuild()
+	m.stateMutex.Unlock()
+
+	m.notifySubscribers()
+}
+
+// rebuild lists each service once, preferring an IPv4 address since
+// link-local IPv6 ones need a zone to be used. Callers hold stateMutex
+func (m *Manager) rebuild() {
+	best := make(map[serviceKey]*Service)
+	for inst, svc := range m.seen {
+		if svc == nil {
+			continue
+		}
+		key := inst.service()
+		current, ok := best[key]
+		if !ok || (!isIPv4(current.Address) && isIPv4(svc.Address)) ||
+			(isIPv4(current.Address) == isIPv4(svc.Address) && svc.Address < current.Address) {
+			best[key] = svc
+		}
+	}
+
+	services := make([]Service, 0, len(best))
+	for _, svc := range best {
+		services = append(services, *svc)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Kind != services[j].Kind {
+			return services[i].Kind < services[j].Kind
+		}
+		if services[i].Name != services[j].Name {
+			return services[i].Name < services[j].Name
+		}
+		return services[i].Type < services[j].Type
+	})
+	m.state = &State{Services: services}
+}
+
+func isIPv4(address string) bool {
+	ip := net.ParseIP(address)
+	return ip != nil && ip.To4() != nil
+}
+
+// newService fills in a resolved instance's kind, TXT records and URL
+func newService(inst instance, host, address string, port uint16, txt [][]byte) Service {
+	svc := Service{
+		Name:    inst.name,
+		Type:    inst.typ,
+		Kind:    serviceKinds[inst.typ],
+		Domain:  inst.domain,
+		Host:    host,
+		Address: address,
+		Port:    port,
+		TXT:     parseTXT(txt),
+	}
+
+	target := host
+	if isIPv4(address) {
+		target = address
+	}
+	hostPort := net.JoinHostPort(target, strconv.Itoa(int(port)))
+	switch inst.typ {
+	case "_ssh._tcp":
+		svc.URL = "ssh://" + hostPort
+	case "_sftp-ssh._tcp":
+		svc.URL = "sftp://" + hostPort
+	case "_ipp._tcp":
+		svc.URL = "ipp://" + hostPort + "/" + strings.TrimPrefix(svc.TXT["rp"], "/")
+	case "_ipps._tcp":
+		svc.URL = "ipps://" + hostPort + "/" + strings.TrimPrefix(svc.TXT["rp"], "/")
+	}
+	return svc
+}
+
+// parseTXT reads key=value TXT strings; keys are case-insensitive and only
+// the first of a repeated key counts, as RFC 6763 says
+func parseTXT(records [][]byte) map[string]string {
+	txt := make(map[string]string, len(records))
+	for _, record := range records {
+		key, value, _ := strings.Cut(string(record), "=")
+		key = strings.ToLower(key)
+		if key == "" {
+			continue
+		}
+		if _, ok := txt[key]; !ok {
+			txt[key] = value
+		}
+	}
+	return txt
+}
+
+func (m *Manager) notifier() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.dirty:
+			state := m.GetState()
+
+			m.subMutex.RLock()
+			if m.lastNotified != nil && !stateChanged(m.lastNotified, &state) {
+				m.subMutex.RUnlock()
+				continue
+			}
+			for _, ch := range m.subscribers {
+				select {
+				case ch <- state:
+				default:
+				}
+			}
+			m.subMutex.RUnlock()
+
+			m.lastNotified = &state
+		}
+	}
+}
+
+func (m *Manager) Close() {
+	m.browser.Close()
+	close(m.stopChan)
+	m.wg.Wait()
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
@@ -0,0 +1,71 @@
+package mdns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBrowser struct{}
+
+func (fakeBrowser) Start(*Manager, []string) error { return nil }
+
+func (fakeBrowser) Close() {}
+
+func sshInstance(iface, protocol int32) instance {
+	return instance{iface: iface, protocol: protocol, name: "nas", typ: "_ssh._tcp", domain: "local"}
+}
+
+func TestNewService(t *testing.T) {
+	printer := newService(
+		instance{name: "Office Laser", typ: "_ipp._tcp", domain: "local"},
+		"laser.local", "192.168.1.40", 631,
+		[][]byte{[]byte("rp=ipp/print"), []byte("TY=Laser 2000"), []byte("rp=ignored")},
+	)
+	assert.Equal(t, KindPrinter, printer.Kind)
+	assert.Equal(t, "ipp://192.168.1.40:631/ipp/print", printer.URL)
+	assert.Equal(t, map[string]string{"rp": "ipp/print", "ty": "Laser 2000"}, printer.TXT)
+
+	ssh := newService(sshInstance(2, 1), "nas.local", "fe80::1", 22, nil)
+	assert.Equal(t, "ssh://nas.local:22", ssh.URL)
+
+	cast := newService(instance{name: "Living Room", typ: "_googlecast._tcp", domain: "local"}, "tv.local", "192.168.1.50", 8009, nil)
+	assert.Equal(t, KindCast, cast.Kind)
+	assert.Empty(t, cast.URL)
+}
+
+func TestManagerMergesInstances(t *testing.T) {
+	m := newManager(fakeBrowser{})
+
+	v6, v4 := sshInstance(2, 1), sshInstance(2, 0)
+	m.itemNew(v6)
+	m.itemNew(v4)
+	assert.Empty(t, m.GetState().Services)
+
+	m.itemResolved(v6, newService(v6, "nas.local", "fe80::1", 22, nil))
+	m.itemResolved(v4, newService(v4, "nas.local", "192.168.1.20", 22, nil))
+
+	services := m.GetState().Services
+	require.Len(t, services, 1)
+	assert.Equal(t, "192.168.1.20", services[0].Address)
+
+	m.itemRemoved(v4)
+	services = m.GetState().Services
+	require.Len(t, services, 1)
+	assert.Equal(t, "fe80::1", services[0].Address)
+
+	m.itemRemoved(v6)
+	assert.Empty(t, m.GetState().Services)
+}
+
+func TestManagerDropsResolvesOfRemovedItems(t *testing.T) {
+	m := newManager(fakeBrowser{})
+
+	inst := sshInstance(2, 0)
+	m.itemNew(inst)
+	m.itemRemoved(inst)
+	m.itemResolved(inst, newService(inst, "nas.local", "192.168.1.20", 22, nil))
+
+	assert.Empty(t, m.GetState().Services)
+}
@@ -0,0 +1,122 @@
+package mdns
+
+import (
+	"reflect"
+	"sync"
+)
+
+const (
+	KindPrinter = "printer"
+	KindCast    = "cast"
+	KindSSH     = "ssh"
+	KindSFTP    = "sftp"
+)
+
+// serviceKinds are the DNS-SD service types browsed for
+var serviceKinds = map[string]string{
+	"_ipp._tcp":        KindPrinter,
+	"_ipps._tcp":       KindPrinter,
+	"_googlecast._tcp": KindCast,
+	"_airplay._tcp":    KindCast,
+	"_ssh._tcp":        KindSSH,
+	"_sftp-ssh._tcp":   KindSFTP,
+}
+
+type Service struct {
+	Name    string            `json:"name"`
+	Type    string            `json:"type"`
+	Kind    string            `json:"kind"`
+	Domain  string            `json:"domain"`
+	Host    string            `json:"host"`
+	Address string            `json:"address"`
+	Port    uint16            `json:"port"`
+	TXT     map[string]string `json:"txt"`
+	// URL opens the service, e.g. ssh://192.168.1.20:22; empty for casts
+	URL string `json:"url,omitempty"`
+}
+
+type State struct {
+	Services []Service `json:"services"`
+}
+
+// instance is one announcement of a service. Avahi reports a service once
+// per interface and IP protocol it was seen on
+type instance struct {
+	iface    int32
+	protocol int32
+	name     string
+	typ      string
+	domain   string
+}
+
+func (i instance) service() serviceKey {
+	return serviceKey{name: i.name, typ: i.typ, domain: i.domain}
+}
+
+type serviceKey struct {
+	name   string
+	typ    string
+	domain string
+}
+
+// Browser finds services on the network and reports them to the manager
+type Browser interface {
+	Start(m *Manager, types []string) error
+	Close()
+}
+
+type Manager struct {
+	browser Browser
+
+	// seen holds announced instances, resolved ones with their service
+	seen       map[instance]*Service
+	state      *State
+	stateMutex sync.RWMutex
+
+	subscribers  map[string]chan State
+	subMutex     sync.RWMutex
+	dirty        chan struct{}
+	stopChan     chan struct{}
+	wg           sync.WaitGroup
+	lastNotified *State
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.state == nil {
+		return State{}
+	}
+	return State{Services: append([]Service(nil), m.state.Services...)}
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func stateChanged(old, new *State) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	return !reflect.DeepEqual(old, new)
+}
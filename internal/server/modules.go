@@ -0,0 +1,154 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+type ModuleStatus string
+
+const (
+	ModuleRunning     ModuleStatus = "running"
+	ModuleDisabled    ModuleStatus = "disabled"
+	ModuleUnavailable ModuleStatus = "unavailable"
+)
+
+// ModulesConfig is ~/.config/dms/modules.json. Disabled lists modules by
+// capability name, e.g. {"disabled": ["bluetooth", "mdns"]}, so minimal
+// setups don't pay for subsystems they don't use
+type ModulesConfig struct {
+	Disabled []string `json:"disabled"`
+}
+
+// moduleMethodPrefixes maps each module's method prefix to its capability
+var moduleMethodPrefixes = map[string]string{
+	"network":     "network",
+	"loginctl":    "loginctl",
+	"freedesktop": "freedesktop",
+	"wayland":     "gamma",
+	"bluetooth":   "bluetooth",
+	"dwl":         "dwl",
+	"idle":        "idle",
+	"keyboard":    "keyboard",
+	"wallpaper":   "wallpaper",
+	"magnifier":   "magnifier",
+	"privacy":     "privacy",
+	"hwmon":       "hwmon",
+	"rfkill":      "rfkill",
+	"mdns":        "mdns",
+	"kdeconnect":  "kdeconnect",
+	"breaks":      "breaks",
+	"timers":      "timers",
+	"timezones":   "timezones",
+	"alerts":      "alerts",
+	"systemd":     "systemd",
+	"apps":        "apps",
+}
+
+var disabledModules map[string]bool
+
+func modulesConfigPath() string {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		configDir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(configDir, "dms", "modules.json")
+}
+
+func knownModules() []string {
+	modules := make([]string, 0, len(moduleMethodPrefixes))
+	for _, name := range moduleMethodPrefixes {
+		modules = append(modules, name)
+	}
+	sort.Strings(modules)
+	return modules
+}
+
+func isKnownModule(name string) bool {
+	for _, known := range moduleMethodPrefixes {
+		if known == name {
+			return true
+		}
+	}
+	return false
+}
+
+// loadDisabledModules reads the modules config at path. A missing file
+// leaves every module enabled
+func loadDisabledModules(path string) (map[string]bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]bool{}, nil
+		}
+		return nil, err
+	}
+
+	var config ModulesConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	disabled := make(map[string]bool, len(config.Disabled))
+	for _, name := range config.Disabled {
+		if !isKnownModule(name) {
+			log.Warnf("Unknown module %q in %s (known: %s)", name, path, strings.Join(knownModules(), ", "))
+			continue
+		}
+		disabled[name] = true
+	}
+	return disabled, nil
+}
+
+func moduleEnabled(name string) bool {
+	return !disabledModules[name]
+}
+
+// moduleForMethod is the module a method belongs to, if any
+func moduleForMethod(method string) (string, bool) {
+	prefix, _, ok := strings.Cut(method, ".")
+	if !ok {
+		return "", false
+	}
+	name, ok := moduleMethodPrefixes[prefix]
+	return name, ok
+}
+
+// startModule initializes a module unless the user disabled it
+func startModule(name string, init func() error) {
+	if !moduleEnabled(name) {
+		log.Infof("Module %s disabled in %s", name, modulesConfigPath())
+		return
+	}
+	if err := init(); err != nil {
+		log.Warnf("Module %s unavailable: %v", name, err)
+	}
+}
+
+// moduleStatuses tells the shell which modules run, so it can hide the UI of
+// those that were disabled or couldn't start
+func moduleStatuses(caps []string) map[string]ModuleStatus {
+	running := make(map[string]bool, len(caps))
+	for _, c := range caps {
+		running[c] = true
+	}
+
+	statuses := make(map[string]ModuleStatus, len(moduleMethodPrefixes))
+	for _, name := range moduleMethodPrefixes {
+		switch {
+		case running[name]:
+			statuses[name] = ModuleRunning
+		case !moduleEnabled(name):
+			statuses[name] = ModuleDisabled
+		default:
+			statuses[name] = ModuleUnavailable
+		}
+	}
+	return statuses
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/AvengeMedia/danklinux/internal/errdefs"
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
 	"github.com/godbus/dbus/v5"
 )
 
@@ -29,6 +30,7 @@ type IWDAgent struct {
 	onPromptRetry   func(ssid string)
 	lastRequestSSID string
 	stateChecker    ConnectionStateChecker
+	secrets         *agentSecrets
 }
 
 const iwdAgentIntrospectXML = `
@@ -62,8 +64,8 @@ const iwdAgentIntrospectXML = `
 	</interface>
 </node>`
 
-func NewIWDAgent(prompts PromptBroker) (*IWDAgent, error) {
-	c, err := dbus.ConnectSystemBus()
+func NewIWDAgent(prompts PromptBroker, store SecretStore) (*IWDAgent, error) {
+	c, err := dbus.ConnectSystemBus(budget.DBusOptions("network")...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to system bus: %w", err)
 	}
@@ -72,6 +74,7 @@ func NewIWDAgent(prompts PromptBroker) (*IWDAgent, error) {
 		conn:    c,
 		objPath: dbus.ObjectPath(iwdAgentObjectPath),
 		prompts: prompts,
+		secrets: newAgentSecrets(store),
 	}
 
 	if err := c.Export(agent, agent.objPath, iwdAgentInterface); err != nil {
@@ -119,6 +122,7 @@ func (a *IWDAgent) getNetworkName(networkPath dbus.ObjectPath) string {
 
 func (a *IWDAgent) RequestPassphrase(network dbus.ObjectPath) (string, *dbus.Error) {
 	ssid := a.getNetworkName(network)
+	fields := []string{"psk"}
 
 	if a.stateChecker != nil && !a.stateChecker.IsConnectingTo(ssid) {
 		return "", dbus.NewError("net.connman.iwd.Agent.Error.Canceled", nil)
@@ -138,12 +142,16 @@ func (a *IWDAgent) RequestPassphrase(network dbus.ObjectPath) (string, *dbus.Err
 	}
 	a.lastRequestSSID = ssid
 
+	if stored, ok := a.secrets.lookup(ssid, fields); ok {
+		return stored["psk"], nil
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
 	token, err := a.prompts.Ask(ctx, PromptRequest{
 		SSID:   ssid,
-		Fields: []string{"psk"},
+		Fields: fields,
 	})
 	if err != nil {
 		if a.onUserCanceled != nil {
@@ -163,6 +171,7 @@ func (a *IWDAgent) RequestPassphrase(network dbus.ObjectPath) (string, *dbus.Err
 	}
 
 	if passphrase, ok := reply.Secrets["psk"]; ok {
+		a.secrets.save(ssid, fields, reply)
 		return passphrase, nil
 	}
 
@@ -174,6 +183,7 @@ func (a *IWDAgent) RequestPassphrase(network dbus.ObjectPath) (string, *dbus.Err
 
 func (a *IWDAgent) RequestPrivateKeyPassphrase(network dbus.ObjectPath) (string, *dbus.Error) {
 	ssid := a.getNetworkName(network)
+	fields := []string{"private-key-password"}
 
 	if a.stateChecker != nil && !a.stateChecker.IsConnectingTo(ssid) {
 		return "", dbus.NewError("net.connman.iwd.Agent.Error.Canceled", nil)
@@ -190,12 +200,16 @@ func (a *IWDAgent) RequestPrivateKeyPassphrase(network dbus.ObjectPath) (string,
 	}
 	a.lastRequestSSID = ssid
 
+	if stored, ok := a.secrets.lookup(ssid, fields); ok {
+		return stored["private-key-password"], nil
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
 	token, err := a.prompts.Ask(ctx, PromptRequest{
 		SSID:   ssid,
-		Fields: []string{"private-key-password"},
+		Fields: fields,
 	})
 	if err != nil {
 		return "", dbus.NewError("net.connman.iwd.Agent.Error.Canceled", nil)
@@ -207,6 +221,7 @@ func (a *IWDAgent) RequestPrivateKeyPassphrase(network dbus.ObjectPath) (string,
 	}
 
 	if passphrase, ok := reply.Secrets["private-key-password"]; ok {
+		a.secrets.save(ssid, fields, reply)
 		return passphrase, nil
 	}
 
@@ -215,6 +230,7 @@ func (a *IWDAgent) RequestPrivateKeyPassphrase(network dbus.ObjectPath) (string,
 
 func (a *IWDAgent) RequestUserNameAndPassword(network dbus.ObjectPath) (string, string, *dbus.Error) {
 	ssid := a.getNetworkName(network)
+	fields := []string{"identity", "password"}
 
 	if a.stateChecker != nil && !a.stateChecker.IsConnectingTo(ssid) {
 		return "", "", dbus.NewError("net.connman.iwd.Agent.Error.Canceled", nil)
@@ -231,12 +247,16 @@ func (a *IWDAgent) RequestUserNameAndPassword(network dbus.ObjectPath) (string,
 	}
 	a.lastRequestSSID = ssid
 
+	if stored, ok := a.secrets.lookup(ssid, fields); ok {
+		return stored["identity"], stored["password"], nil
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
 	token, err := a.prompts.Ask(ctx, PromptRequest{
 		SSID:   ssid,
-		Fields: []string{"identity", "password"},
+		Fields: fields,
 	})
 	if err != nil {
 		return "", "", dbus.NewError("net.connman.iwd.Agent.Error.Canceled", nil)
@@ -251,6 +271,7 @@ func (a *IWDAgent) RequestUserNameAndPassword(network dbus.ObjectPath) (string,
 	password, hasPass := reply.Secrets["password"]
 
 	if hasUser && hasPass {
+		a.secrets.save(ssid, fields, reply)
 		return username, password, nil
 	}
 
@@ -259,6 +280,7 @@ func (a *IWDAgent) RequestUserNameAndPassword(network dbus.ObjectPath) (string,
 
 func (a *IWDAgent) RequestUserPassword(network dbus.ObjectPath, user string) (string, *dbus.Error) {
 	ssid := a.getNetworkName(network)
+	fields := []string{"password"}
 
 	if a.stateChecker != nil && !a.stateChecker.IsConnectingTo(ssid) {
 		return "", dbus.NewError("net.connman.iwd.Agent.Error.Canceled", nil)
@@ -275,12 +297,16 @@ func (a *IWDAgent) RequestUserPassword(network dbus.ObjectPath, user string) (st
 	}
 	a.lastRequestSSID = ssid
 
+	if stored, ok := a.secrets.lookup(ssid, fields); ok {
+		return stored["password"], nil
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
 	token, err := a.prompts.Ask(ctx, PromptRequest{
 		SSID:   ssid,
-		Fields: []string{"password"},
+		Fields: fields,
 	})
 	if err != nil {
 		return "", dbus.NewError("net.connman.iwd.Agent.Error.Canceled", nil)
@@ -292,6 +318,7 @@ func (a *IWDAgent) RequestUserPassword(network dbus.ObjectPath, user string) (st
 	}
 
 	if password, ok := reply.Secrets["password"]; ok {
+		a.secrets.save(ssid, fields, reply)
 		return password, nil
 	}
 
@@ -9,6 +9,8 @@ import (
 
 	"github.com/AvengeMedia/danklinux/internal/errdefs"
 	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/secrets"
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
 	"github.com/godbus/dbus/v5"
 )
 
@@ -29,6 +31,8 @@ type SecretAgent struct {
 	backend        *NetworkManagerBackend
 	pendingSaves   map[string]pendingSave // key: connection path
 	pendingSavesMu sync.RWMutex
+	// vpnSecrets holds VPN secrets the user stored with dms secret
+	vpnSecrets secretGetter
 }
 
 type pendingSave struct {
@@ -72,7 +76,7 @@ const introspectXML = `
 </node>`
 
 func NewSecretAgent(prompts PromptBroker, manager *Manager, backend *NetworkManagerBackend) (*SecretAgent, error) {
-	c, err := dbus.ConnectSystemBus()
+	c, err := dbus.ConnectSystemBus(budget.DBusOptions("network")...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to system bus: %w", err)
 	}
@@ -85,6 +89,7 @@ func NewSecretAgent(prompts PromptBroker, manager *Manager, backend *NetworkMana
 		manager:      manager,
 		pendingSaves: make(map[string]pendingSave),
 		backend:      backend,
+		vpnSecrets:   secrets.Open(secrets.Path()),
 	}
 
 	if err := c.Export(sa, sa.objPath, nmSecretAgentIface); err != nil {
@@ -246,6 +251,19 @@ func (a *SecretAgent) GetSecrets(
 		}
 	}
 
+	// Stored VPN secrets go first unless NetworkManager says they were
+	// just rejected
+	if settingName == "vpn" && flags&NM_SECRET_AGENT_GET_SECRETS_FLAG_REQUEST_NEW == 0 {
+		if values, ok := storedVPNSecrets(a.vpnSecrets, connUuid, fields); ok {
+			log.Infof("[SecretAgent] Answering VPN %s from stored secrets", displayName)
+			sec := nmVariantMap{}
+			for k, v := range values {
+				sec[k] = dbus.MakeVariant(v)
+			}
+			return nmSettingMap{settingName: sec}, nil
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
 
@@ -480,6 +498,12 @@ func fieldsNeeded(setting string, hints []string) []string {
 	case "802-11-wireless-security":
 		return []string{"psk"}
 	case "802-1x":
+		// EAP-TLS asks for the key's passphrase rather than a password
+		for _, hint := range hints {
+			if hint == "private-key-password" {
+				return []string{"private-key-password"}
+			}
+		}
 		return []string{"identity", "password"}
 	case "vpn":
 		return hints
@@ -11,14 +11,31 @@ type Backend interface {
 	GetWiFiNetworkDetails(ssid string) (*NetworkInfoResponse, error)
 
 	ConnectWiFi(req ConnectionRequest) error
+	CancelConnect(ssid string) error
 	DisconnectWiFi() error
 	ForgetWiFiNetwork(ssid string) error
+	ListSavedWiFiNetworks() ([]SavedWiFiNetwork, error)
+	SelectWiFiDevice(iface string) error
+
+	StartHotspot(ssid, password, band string) error
+	StopHotspot() error
 
 	GetWiredConnections() ([]WiredConnection, error)
 	GetWiredNetworkDetails(uuid string) (*WiredNetworkInfoResponse, error)
-	ConnectEthernet() error
+	// ConnectEthernet brings up the wired port; auth adds 802.1X to it
+	ConnectEthernet(auth *ConnectionRequest) error
 	DisconnectEthernet() error
-	ActivateWiredConnection(uuid string) error
+	ActivateWiredConnection(uuid, device string) error
+	SetWiredIPConfig(uuid string, config WiredIPConfig) error
+	SetConnectionDNS(uuidOrSSID string, config DNSConfig) error
+	GetSearchDomains(uuidOrSSID string) ([]string, error)
+	SetSearchDomains(uuidOrSSID string, domains []string) error
+	GetMetered(uuidOrSSID string) (*MeteredInfo, error)
+	SetMetered(uuidOrSSID string, mode string) error
+	GetIPv6Config(uuidOrSSID string) (*IPv6Config, error)
+	SetIPv6Config(uuidOrSSID string, config IPv6Config) error
+	GetWakeOnLAN(uuid string) (*WakeOnLANConfig, error)
+	SetWakeOnLAN(uuid string, config WakeOnLANConfig) error
 
 	ListVPNProfiles() ([]VPNProfile, error)
 	ListActiveVPN() ([]VPNActive, error)
@@ -26,6 +43,7 @@ type Backend interface {
 	DisconnectVPN(uuidOrName string) error
 	DisconnectAllVPN() error
 	ClearVPNCredentials(uuidOrName string) error
+	ImportOpenVPNProfile(path, name string) (*VPNProfile, error)
 
 	GetCurrentState() (*BackendState, error)
 
@@ -45,8 +63,10 @@ type BackendState struct {
 	EthernetDevice         string
 	EthernetConnected      bool
 	EthernetConnectionUuid string
+	EthernetDevices        []EthernetDeviceInfo
 	WiFiIP                 string
 	WiFiDevice             string
+	WiFiDevices            []WiFiDeviceInfo
 	WiFiConnected          bool
 	WiFiEnabled            bool
 	WiFiSSID               string
@@ -54,8 +74,10 @@ type BackendState struct {
 	WiFiSignal             uint8
 	WiFiNetworks           []WiFiNetwork
 	WiredConnections       []WiredConnection
+	Metered                bool
 	VPNProfiles            []VPNProfile
 	VPNActive              []VPNActive
+	Hotspot                HotspotState
 	IsConnecting           bool
 	ConnectingSSID         string
 	IsConnectingVPN        bool
@@ -3,6 +3,8 @@ package network
 import (
 	"fmt"
 	"sync"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
 )
 
 type HybridIwdNetworkdBackend struct {
@@ -84,6 +86,7 @@ func (b *HybridIwdNetworkdBackend) GetCurrentState() (*BackendState, error) {
 	merged.EthernetDevice = ls.EthernetDevice
 	merged.EthernetConnectionUuid = ls.EthernetConnectionUuid
 	merged.WiredConnections = ls.WiredConnections
+	merged.EthernetDevices = ls.EthernetDevices
 
 	if ls.EthernetConnected && ls.EthernetIP != "" {
 		merged.NetworkStatus = StatusEthernet
@@ -125,6 +128,10 @@ func (b *HybridIwdNetworkdBackend) ConnectWiFi(req ConnectionRequest) error {
 	return nil
 }
 
+func (b *HybridIwdNetworkdBackend) CancelConnect(ssid string) error {
+	return b.wifi.CancelConnect(ssid)
+}
+
 func (b *HybridIwdNetworkdBackend) DisconnectWiFi() error {
 	return b.wifi.DisconnectWiFi()
 }
@@ -133,6 +140,30 @@ func (b *HybridIwdNetworkdBackend) ForgetWiFiNetwork(ssid string) error {
 	return b.wifi.ForgetWiFiNetwork(ssid)
 }
 
+func (b *HybridIwdNetworkdBackend) ListSavedWiFiNetworks() ([]SavedWiFiNetwork, error) {
+	return b.wifi.ListSavedWiFiNetworks()
+}
+
+// SelectWiFiDevice switches iwd to iface and has networkd report addresses
+// for the same link
+func (b *HybridIwdNetworkdBackend) SelectWiFiDevice(iface string) error {
+	if err := b.wifi.SelectWiFiDevice(iface); err != nil {
+		return err
+	}
+	if err := b.l3.SelectWiFiDevice(iface); err != nil {
+		log.Warnf("networkd doesn't know WiFi link %s: %v", iface, err)
+	}
+	return nil
+}
+
+func (b *HybridIwdNetworkdBackend) StartHotspot(ssid, password, band string) error {
+	return b.wifi.StartHotspot(ssid, password, band)
+}
+
+func (b *HybridIwdNetworkdBackend) StopHotspot() error {
+	return b.wifi.StopHotspot()
+}
+
 func (b *HybridIwdNetworkdBackend) GetWiredConnections() ([]WiredConnection, error) {
 	return b.l3.GetWiredConnections()
 }
@@ -141,16 +172,56 @@ func (b *HybridIwdNetworkdBackend) GetWiredNetworkDetails(uuid string) (*WiredNe
 	return b.l3.GetWiredNetworkDetails(uuid)
 }
 
-func (b *HybridIwdNetworkdBackend) ConnectEthernet() error {
-	return b.l3.ConnectEthernet()
+func (b *HybridIwdNetworkdBackend) ConnectEthernet(auth *ConnectionRequest) error {
+	return b.l3.ConnectEthernet(auth)
 }
 
 func (b *HybridIwdNetworkdBackend) DisconnectEthernet() error {
 	return b.l3.DisconnectEthernet()
 }
 
-func (b *HybridIwdNetworkdBackend) ActivateWiredConnection(uuid string) error {
-	return b.l3.ActivateWiredConnection(uuid)
+func (b *HybridIwdNetworkdBackend) ActivateWiredConnection(uuid, device string) error {
+	return b.l3.ActivateWiredConnection(uuid, device)
+}
+
+func (b *HybridIwdNetworkdBackend) SetWiredIPConfig(uuid string, config WiredIPConfig) error {
+	return b.l3.SetWiredIPConfig(uuid, config)
+}
+
+func (b *HybridIwdNetworkdBackend) SetConnectionDNS(uuidOrSSID string, config DNSConfig) error {
+	return b.l3.SetConnectionDNS(uuidOrSSID, config)
+}
+
+func (b *HybridIwdNetworkdBackend) GetSearchDomains(uuidOrSSID string) ([]string, error) {
+	return b.l3.GetSearchDomains(uuidOrSSID)
+}
+
+func (b *HybridIwdNetworkdBackend) SetSearchDomains(uuidOrSSID string, domains []string) error {
+	return b.l3.SetSearchDomains(uuidOrSSID, domains)
+}
+
+func (b *HybridIwdNetworkdBackend) GetMetered(uuidOrSSID string) (*MeteredInfo, error) {
+	return b.l3.GetMetered(uuidOrSSID)
+}
+
+func (b *HybridIwdNetworkdBackend) SetMetered(uuidOrSSID string, mode string) error {
+	return b.l3.SetMetered(uuidOrSSID, mode)
+}
+
+func (b *HybridIwdNetworkdBackend) GetIPv6Config(uuidOrSSID string) (*IPv6Config, error) {
+	return b.l3.GetIPv6Config(uuidOrSSID)
+}
+
+func (b *HybridIwdNetworkdBackend) SetIPv6Config(uuidOrSSID string, config IPv6Config) error {
+	return b.l3.SetIPv6Config(uuidOrSSID, config)
+}
+
+func (b *HybridIwdNetworkdBackend) GetWakeOnLAN(uuid string) (*WakeOnLANConfig, error) {
+	return b.l3.GetWakeOnLAN(uuid)
+}
+
+func (b *HybridIwdNetworkdBackend) SetWakeOnLAN(uuid string, config WakeOnLANConfig) error {
+	return b.l3.SetWakeOnLAN(uuid, config)
 }
 
 func (b *HybridIwdNetworkdBackend) ListVPNProfiles() ([]VPNProfile, error) {
@@ -177,6 +248,10 @@ func (b *HybridIwdNetworkdBackend) ClearVPNCredentials(uuidOrName string) error
 	return fmt.Errorf("VPN not supported in hybrid mode")
 }
 
+func (b *HybridIwdNetworkdBackend) ImportOpenVPNProfile(path, name string) (*VPNProfile, error) {
+	return nil, fmt.Errorf("VPN not supported in hybrid mode")
+}
+
 func (b *HybridIwdNetworkdBackend) GetPromptBroker() PromptBroker {
 	return b.wifi.GetPromptBroker()
 }
@@ -5,19 +5,24 @@ import (
 	"sync"
 	"time"
 
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
 	"github.com/godbus/dbus/v5"
 )
 
 const (
-	iwdBusName               = "net.connman.iwd"
-	iwdObjectPath            = "/"
-	iwdAdapterInterface      = "net.connman.iwd.Adapter"
-	iwdDeviceInterface       = "net.connman.iwd.Device"
-	iwdStationInterface      = "net.connman.iwd.Station"
-	iwdNetworkInterface      = "net.connman.iwd.Network"
-	iwdKnownNetworkInterface = "net.connman.iwd.KnownNetwork"
-	dbusObjectManager        = "org.freedesktop.DBus.ObjectManager"
-	dbusPropertiesInterface  = "org.freedesktop.DBus.Properties"
+	iwdBusName                    = "net.connman.iwd"
+	iwdObjectPath                 = "/"
+	iwdAdapterInterface           = "net.connman.iwd.Adapter"
+	iwdDeviceInterface            = "net.connman.iwd.Device"
+	iwdStationInterface           = "net.connman.iwd.Station"
+	iwdStationDiagnosticInterface = "net.connman.iwd.StationDiagnostic"
+	iwdNetworkInterface           = "net.connman.iwd.Network"
+	iwdKnownNetworkInterface      = "net.connman.iwd.KnownNetwork"
+	iwdAccessPointInterface       = "net.connman.iwd.AccessPoint"
+	dbusObjectManager             = "org.freedesktop.DBus.ObjectManager"
+	dbusPropertiesInterface       = "org.freedesktop.DBus.Properties"
+
+	defaultIWDConnectTimeout = 15 * time.Second
 )
 
 type connectAttempt struct {
@@ -38,11 +43,14 @@ type IWDBackend struct {
 	state         *BackendState
 	stateMutex    sync.RWMutex
 	promptBroker  PromptBroker
+	secretStore   SecretStore
 	onStateChange func()
 
 	devicePath  dbus.ObjectPath
 	stationPath dbus.ObjectPath
 	adapterPath dbus.ObjectPath
+	// wifiDevices are all wireless devices; devicePath is the selected one
+	wifiDevices []iwdDevice
 
 	iwdAgent *IWDAgent
 
@@ -52,6 +60,10 @@ type IWDBackend struct {
 	attemptMutex  sync.RWMutex
 	recentScans   map[string]time.Time
 	recentScansMu sync.Mutex
+
+	// hotspotPassphrase is the one StartHotspot was given; iwd doesn't
+	// expose it afterwards
+	hotspotPassphrase string
 }
 
 func NewIWDBackend() (*IWDBackend, error) {
@@ -68,7 +80,7 @@ func NewIWDBackend() (*IWDBackend, error) {
 }
 
 func (b *IWDBackend) Initialize() error {
-	conn, err := dbus.ConnectSystemBus()
+	conn, err := dbus.ConnectSystemBus(budget.DBusOptions("network")...)
 	if err != nil {
 		return fmt.Errorf("failed to connect to system bus: %w", err)
 	}
@@ -109,31 +121,14 @@ func (b *IWDBackend) discoverDevices() error {
 		return fmt.Errorf("failed to get managed objects: %w", err)
 	}
 
-	for path, interfaces := range objects {
-		if _, hasStation := interfaces[iwdStationInterface]; hasStation {
-			b.stationPath = path
-		}
-		if _, hasDevice := interfaces[iwdDeviceInterface]; hasDevice {
-			b.devicePath = path
-
-			if devProps, ok := interfaces[iwdDeviceInterface]; ok {
-				if nameVar, ok := devProps["Name"]; ok {
-					if name, ok := nameVar.Value().(string); ok {
-						b.stateMutex.Lock()
-						b.state.WiFiDevice = name
-						b.stateMutex.Unlock()
-					}
-				}
-			}
-		}
-		if _, hasAdapter := interfaces[iwdAdapterInterface]; hasAdapter {
-			b.adapterPath = path
-		}
-	}
-
-	if b.stationPath == "" || b.devicePath == "" {
+	devices := iwdDevicesFromObjects(objects)
+	dev := preferredIWDDevice(devices)
+	if dev == nil {
 		return fmt.Errorf("no WiFi device found")
 	}
+	b.wifiDevices = devices
+	b.useDevice(*dev, objects)
+	b.updateWiFiDeviceList()
 
 	return nil
 }
@@ -142,6 +137,7 @@ func (b *IWDBackend) GetCurrentState() (*BackendState, error) {
 	state := *b.state
 	state.WiFiNetworks = append([]WiFiNetwork(nil), b.state.WiFiNetworks...)
 	state.WiredConnections = append([]WiredConnection(nil), b.state.WiredConnections...)
+	state.WiFiDevices = append([]WiFiDeviceInfo(nil), b.state.WiFiDevices...)
 
 	return &state, nil
 }
@@ -176,6 +172,12 @@ func (b *IWDBackend) MarkIPConfigSeen() {
 	}
 }
 
+// SetSecretStore keeps secrets saved in the prompt outside iwd, which only
+// remembers passphrases. It takes effect when monitoring starts
+func (b *IWDBackend) SetSecretStore(store SecretStore) {
+	b.secretStore = store
+}
+
 func (b *IWDBackend) GetPromptBroker() PromptBroker {
 	return b.promptBroker
 }
@@ -0,0 +1,176 @@
+package network
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/godbus/dbus/v5"
+)
+
+type iwdDevice struct {
+	path      dbus.ObjectPath
+	name      string
+	address   string
+	adapter   dbus.ObjectPath
+	station   bool
+	connected bool
+}
+
+// iwdDevicesFromObjects lists the wireless devices in iwd's managed objects,
+// sorted by path. Devices in access point mode have no station
+func iwdDevicesFromObjects(objects map[dbus.ObjectPath]map[string]map[string]dbus.Variant) []iwdDevice {
+	var devices []iwdDevice
+	for path, interfaces := range objects {
+		props, ok := interfaces[iwdDeviceInterface]
+		if !ok {
+			continue
+		}
+
+		dev := iwdDevice{path: path}
+		if v, ok := props["Name"]; ok {
+			dev.name, _ = v.Value().(string)
+		}
+		if v, ok := props["Address"]; ok {
+			dev.address, _ = v.Value().(string)
+		}
+		if v, ok := props["Adapter"]; ok {
+			dev.adapter, _ = v.Value().(dbus.ObjectPath)
+		}
+		if station, ok := interfaces[iwdStationInterface]; ok {
+			dev.station = true
+			if v, ok := station["State"]; ok {
+				state, _ := v.Value().(string)
+				dev.connected = state == "connected"
+			}
+		}
+		devices = append(devices, dev)
+	}
+
+	sort.Slice(devices, func(i, j int) bool { return devices[i].path < devices[j].path })
+	return devices
+}
+
+// preferredIWDDevice picks the connected station, else the first station
+func preferredIWDDevice(devices []iwdDevice) *iwdDevice {
+	var first *iwdDevice
+	for i := range devices {
+		if !devices[i].station {
+			continue
+		}
+		if devices[i].connected {
+			return &devices[i]
+		}
+		if first == nil {
+			first = &devices[i]
+		}
+	}
+	return first
+}
+
+func (b *IWDBackend) useDevice(dev iwdDevice, objects map[dbus.ObjectPath]map[string]map[string]dbus.Variant) {
+	adapter := dev.adapter
+	if adapter == "" {
+		for path, interfaces := range objects {
+			if _, ok := interfaces[iwdAdapterInterface]; ok {
+				adapter = path
+				break
+			}
+		}
+	}
+
+	b.stateMutex.Lock()
+	b.devicePath = dev.path
+	b.stationPath = dev.path
+	b.adapterPath = adapter
+	b.state.WiFiDevice = dev.name
+	b.stateMutex.Unlock()
+}
+
+func (b *IWDBackend) updateWiFiDeviceList() {
+	devices := make([]WiFiDeviceInfo, 0, len(b.wifiDevices))
+	for _, dev := range b.wifiDevices {
+		devices = append(devices, WiFiDeviceInfo{
+			Name:      dev.name,
+			Address:   dev.address,
+			Connected: dev.connected,
+			Selected:  dev.path == b.devicePath,
+		})
+	}
+
+	b.stateMutex.Lock()
+	b.state.WiFiDevices = devices
+	b.stateMutex.Unlock()
+}
+
+func (b *IWDBackend) watchDevice(path dbus.ObjectPath, watch bool) error {
+	opts := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(path),
+		dbus.WithMatchInterface(dbusPropertiesInterface),
+		dbus.WithMatchMember("PropertiesChanged"),
+	}
+	if watch {
+		return b.conn.AddMatchSignal(opts...)
+	}
+	return b.conn.RemoveMatchSignal(opts...)
+}
+
+func (b *IWDBackend) SelectWiFiDevice(iface string) error {
+	var objects map[dbus.ObjectPath]map[string]map[string]dbus.Variant
+	err := b.conn.Object(iwdBusName, iwdObjectPath).Call(dbusObjectManager+".GetManagedObjects", 0).Store(&objects)
+	if err != nil {
+		return fmt.Errorf("failed to get managed objects: %w", err)
+	}
+	b.wifiDevices = iwdDevicesFromObjects(objects)
+
+	var target *iwdDevice
+	for i := range b.wifiDevices {
+		if b.wifiDevices[i].name == iface {
+			target = &b.wifiDevices[i]
+			break
+		}
+	}
+	if target == nil {
+		b.updateWiFiDeviceList()
+		return fmt.Errorf("no WiFi device named %q", iface)
+	}
+
+	if target.path != b.devicePath {
+		if !target.station {
+			return fmt.Errorf("%s is not in station mode", iface)
+		}
+
+		// StartMonitoring added a match for the device and one for the
+		// station. They share a path in iwd, so one match covers both now
+		for _, path := range []dbus.ObjectPath{b.devicePath, b.stationPath} {
+			if path != "" {
+				_ = b.watchDevice(path, false)
+			}
+		}
+		b.useDevice(*target, objects)
+		if err := b.watchDevice(target.path, true); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", iface, err)
+		}
+
+		b.stateMutex.Lock()
+		b.state.WiFiConnected = false
+		b.state.WiFiSSID = ""
+		b.state.WiFiSignal = 0
+		b.state.WiFiNetworks = nil
+		b.stateMutex.Unlock()
+
+		if err := b.updateState(); err != nil {
+			return err
+		}
+		if networks, err := b.updateWiFiNetworks(); err == nil {
+			b.stateMutex.Lock()
+			b.state.WiFiNetworks = networks
+			b.stateMutex.Unlock()
+		}
+	}
+
+	b.updateWiFiDeviceList()
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/godbus/dbus/v5"
+)
+
+// StartHotspot switches the device into AP mode and starts iwd's access
+// point. iwd picks the channel itself and only supports WPA2 hotspots
+func (b *IWDBackend) StartHotspot(ssid, password, band string) error {
+	if err := validateHotspot(ssid, password); err != nil {
+		return err
+	}
+	band, err := normalizeHotspotBand(band)
+	if err != nil {
+		return err
+	}
+	if band != HotspotBandAuto {
+		return fmt.Errorf("band selection not supported by iwd backend")
+	}
+	if password == "" {
+		return fmt.Errorf("iwd hotspots require a password")
+	}
+	if b.devicePath == "" {
+		return fmt.Errorf("no WiFi device available")
+	}
+
+	if err := b.setDeviceMode("ap"); err != nil {
+		return err
+	}
+
+	obj := b.conn.Object(iwdBusName, b.devicePath)
+	if call := obj.Call(iwdAccessPointInterface+".Start", 0, ssid, password); call.Err != nil {
+		if err := b.setDeviceMode("station"); err != nil {
+			log.Warnf("[StartHotspot] Failed to restore station mode: %v", err)
+		}
+		return fmt.Errorf("failed to start hotspot: %w", call.Err)
+	}
+	log.Infof("[StartHotspot] Hotspot %s started", ssid)
+
+	b.stateMutex.Lock()
+	b.state.Hotspot = HotspotState{Active: true, SSID: ssid, Device: b.state.WiFiDevice, Secured: true}
+	b.hotspotPassphrase = password
+	b.state.WiFiConnected = false
+	b.state.WiFiSSID = ""
+	b.state.WiFiSignal = 0
+	b.state.NetworkStatus = StatusDisconnected
+	b.stateMutex.Unlock()
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	return nil
+}
+
+func (b *IWDBackend) StopHotspot() error {
+	b.stateMutex.RLock()
+	active := b.state.Hotspot.Active
+	b.stateMutex.RUnlock()
+	if !active || b.devicePath == "" {
+		return fmt.Errorf("hotspot is not running")
+	}
+
+	obj := b.conn.Object(iwdBusName, b.devicePath)
+	if call := obj.Call(iwdAccessPointInterface+".Stop", 0); call.Err != nil {
+		return fmt.Errorf("failed to stop hotspot: %w", call.Err)
+	}
+	if err := b.setDeviceMode("station"); err != nil {
+		return err
+	}
+
+	b.stateMutex.Lock()
+	b.state.Hotspot = HotspotState{}
+	b.hotspotPassphrase = ""
+	b.stateMutex.Unlock()
+
+	b.updateState()
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	return nil
+}
+
+func (b *IWDBackend) setDeviceMode(mode string) error {
+	obj := b.conn.Object(iwdBusName, b.devicePath)
+	call := obj.Call(dbusPropertiesInterface+".Set", 0, iwdDeviceInterface, "Mode", dbus.MakeVariant(mode))
+	if call.Err != nil {
+		return fmt.Errorf("failed to set device mode to %s: %w", mode, call.Err)
+	}
+	return nil
+}
+
+// updateHotspotState reads the AccessPoint interface, which only exists
+// while the device is in AP mode, and reports whether a hotspot is up
+func (b *IWDBackend) updateHotspotState() bool {
+	if b.devicePath == "" {
+		return false
+	}
+
+	obj := b.conn.Object(iwdBusName, b.devicePath)
+	var hotspot HotspotState
+	if startedVar, err := obj.GetProperty(iwdAccessPointInterface + ".Started"); err == nil {
+		if started, ok := startedVar.Value().(bool); ok && started {
+			hotspot.Active = true
+			hotspot.Secured = true
+			if nameVar, err := obj.GetProperty(iwdAccessPointInterface + ".Name"); err == nil {
+				hotspot.SSID, _ = nameVar.Value().(string)
+			}
+		}
+	}
+
+	b.stateMutex.Lock()
+	hotspot.Device = b.state.WiFiDevice
+	if !hotspot.Active {
+		hotspot.Device = ""
+		b.hotspotPassphrase = ""
+	}
+	b.state.Hotspot = hotspot
+	b.stateMutex.Unlock()
+	return hotspot.Active
+}
+
+func (b *IWDBackend) hotspotPassword() (string, error) {
+	b.stateMutex.RLock()
+	defer b.stateMutex.RUnlock()
+	if !b.state.Hotspot.Active {
+		return "", fmt.Errorf("hotspot is not running")
+	}
+	if b.hotspotPassphrase == "" {
+		return "", fmt.Errorf("the password of a hotspot started outside DMS is unknown")
+	}
+	return b.hotspotPassphrase, nil
+}
@@ -0,0 +1,118 @@
+package network
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// KnownNetworks lists iwd's saved networks in the order iwd prefers them
+// when autoconnecting. iwd ranks known networks by when they were last
+// connected and has no way to change that, so the order is read-only;
+// AutoConnect is what keeps a network from being joined
+func (b *IWDBackend) KnownNetworks() ([]KnownNetwork, error) {
+	objects, err := b.managedObjects()
+	if err != nil {
+		return nil, err
+	}
+	return knownNetworksFromObjects(objects), nil
+}
+
+// ListSavedWiFiNetworks returns iwd's known networks, most recently used
+// first
+func (b *IWDBackend) ListSavedWiFiNetworks() ([]SavedWiFiNetwork, error) {
+	known, err := b.KnownNetworks()
+	if err != nil {
+		return nil, err
+	}
+	return savedWiFiFromKnown(known), nil
+}
+
+func (b *IWDBackend) SetKnownNetworkAutoConnect(ssid string, enabled bool) error {
+	objects, err := b.managedObjects()
+	if err != nil {
+		return err
+	}
+
+	path, ok := knownNetworkPath(objects, ssid)
+	if !ok {
+		return fmt.Errorf("no known network named %q", ssid)
+	}
+
+	obj := b.conn.Object(iwdBusName, path)
+	call := obj.Call(dbusPropertiesInterface+".Set", 0, iwdKnownNetworkInterface, "AutoConnect", dbus.MakeVariant(enabled))
+	if call.Err != nil {
+		return fmt.Errorf("failed to set AutoConnect: %w", call.Err)
+	}
+	return nil
+}
+
+func (b *IWDBackend) managedObjects() (map[dbus.ObjectPath]map[string]map[string]dbus.Variant, error) {
+	obj := b.conn.Object(iwdBusName, iwdObjectPath)
+
+	var objects map[dbus.ObjectPath]map[string]map[string]dbus.Variant
+	if err := obj.Call(dbusObjectManager+".GetManagedObjects", 0).Store(&objects); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func knownNetworkPath(objects map[dbus.ObjectPath]map[string]map[string]dbus.Variant, ssid string) (dbus.ObjectPath, bool) {
+	for path, interfaces := range objects {
+		props, ok := interfaces[iwdKnownNetworkInterface]
+		if !ok {
+			continue
+		}
+		if name, _ := props["Name"].Value().(string); name == ssid {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+func knownNetworksFromObjects(objects map[dbus.ObjectPath]map[string]map[string]dbus.Variant) []KnownNetwork {
+	type ranked struct {
+		network KnownNetwork
+		last    time.Time
+	}
+
+	var all []ranked
+	for _, interfaces := range objects {
+		props, ok := interfaces[iwdKnownNetworkInterface]
+		if !ok {
+			continue
+		}
+
+		var r ranked
+		r.network.SSID, _ = props["Name"].Value().(string)
+		r.network.Security, _ = props["Type"].Value().(string)
+		r.network.Hidden, _ = props["Hidden"].Value().(bool)
+		// AutoConnect is absent on iwd versions that predate it, which
+		// always autoconnect
+		r.network.AutoConnect = true
+		if v, ok := props["AutoConnect"].Value().(bool); ok {
+			r.network.AutoConnect = v
+		}
+		if v, ok := props["LastConnectedTime"].Value().(string); ok {
+			r.network.LastConnected = v
+			r.last, _ = time.Parse(time.RFC3339, v)
+		}
+		all = append(all, r)
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		if !all[i].last.Equal(all[j].last) {
+			return all[i].last.After(all[j].last)
+		}
+		return all[i].network.SSID < all[j].network.SSID
+	})
+
+	networks := make([]KnownNetwork, len(all))
+	for i, r := range all {
+		networks[i] = r.network
+		networks[i].Rank = i + 1
+	}
+	return networks
+}
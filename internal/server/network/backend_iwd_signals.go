@@ -11,7 +11,7 @@ func (b *IWDBackend) StartMonitoring(onStateChange func()) error {
 	b.onStateChange = onStateChange
 
 	if b.promptBroker != nil {
-		agent, err := NewIWDAgent(b.promptBroker)
+		agent, err := NewIWDAgent(b.promptBroker, b.secretStore)
 		if err != nil {
 			return fmt.Errorf("failed to start IWD agent: %w", err)
 		}
@@ -101,6 +101,16 @@ func (b *IWDBackend) signalHandler(sigChan chan *dbus.Signal) {
 					}
 				}
 
+			case iwdAccessPointInterface:
+				if sig.Path == b.devicePath {
+					_, started := changed["Started"]
+					_, name := changed["Name"]
+					if started || name {
+						b.updateHotspotState()
+						stateChanged = true
+					}
+				}
+
 			case iwdStationInterface:
 				if sig.Path == b.stationPath {
 					if scanningVar, ok := changed["Scanning"]; ok {
@@ -187,7 +197,9 @@ func (b *IWDBackend) signalHandler(sigChan chan *dbus.Signal) {
 
 							targetPath := dbus.ObjectPath("")
 							if att != nil {
+								att.mu.Lock()
 								targetPath = att.netPath
+								att.mu.Unlock()
 							}
 
 							isTarget := att != nil && targetPath != "" && connPath == targetPath
@@ -236,6 +248,12 @@ func (b *IWDBackend) signalHandler(sigChan chan *dbus.Signal) {
 									}
 								}
 
+								// Also reached after roaming to another access point
+								bssid := b.connectedBSSID()
+								b.stateMutex.Lock()
+								b.state.WiFiBSSID = bssid
+								b.stateMutex.Unlock()
+
 								stateChanged = true
 
 								if att != nil && isTarget {
@@ -278,6 +296,7 @@ func (b *IWDBackend) signalHandler(sigChan chan *dbus.Signal) {
 
 								b.stateMutex.Lock()
 								b.state.WiFiConnected = false
+								b.state.WiFiBSSID = ""
 								if state == "disconnected" {
 									b.state.NetworkStatus = StatusDisconnected
 								}
@@ -4,7 +4,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/godbus/dbus/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestIWDBackend_MarkIPConfigSeen(t *testing.T) {
@@ -210,3 +212,127 @@ func TestConnectAttempt_DoubleFinalization(t *testing.T) {
 	assert.Equal(t, "bad-credentials", backend.state.LastError)
 	backend.stateMutex.RUnlock()
 }
+
+func iwdTestObjects() map[dbus.ObjectPath]map[string]map[string]dbus.Variant {
+	return map[dbus.ObjectPath]map[string]map[string]dbus.Variant{
+		"/net/connman/iwd/0": {iwdAdapterInterface: {}},
+		"/net/connman/iwd/1": {iwdAdapterInterface: {}},
+		"/net/connman/iwd/0/4": {
+			iwdDeviceInterface: {
+				"Name":    dbus.MakeVariant("wlan0"),
+				"Address": dbus.MakeVariant("aa:bb:cc:dd:ee:01"),
+				"Adapter": dbus.MakeVariant(dbus.ObjectPath("/net/connman/iwd/0")),
+			},
+			iwdStationInterface: {"State": dbus.MakeVariant("disconnected")},
+		},
+		"/net/connman/iwd/1/7": {
+			iwdDeviceInterface: {
+				"Name":    dbus.MakeVariant("wlan1"),
+				"Adapter": dbus.MakeVariant(dbus.ObjectPath("/net/connman/iwd/1")),
+			},
+			iwdStationInterface: {"State": dbus.MakeVariant("connected")},
+		},
+	}
+}
+
+func TestIWDDevicesFromObjects(t *testing.T) {
+	devices := iwdDevicesFromObjects(iwdTestObjects())
+	require.Len(t, devices, 2)
+
+	assert.Equal(t, "wlan0", devices[0].name)
+	assert.Equal(t, "aa:bb:cc:dd:ee:01", devices[0].address)
+	assert.Equal(t, dbus.ObjectPath("/net/connman/iwd/0"), devices[0].adapter)
+	assert.True(t, devices[0].station)
+	assert.False(t, devices[0].connected)
+
+	assert.Equal(t, "wlan1", devices[1].name)
+	assert.True(t, devices[1].connected)
+}
+
+func TestPreferredIWDDevice(t *testing.T) {
+	devices := iwdDevicesFromObjects(iwdTestObjects())
+	assert.Equal(t, "wlan1", preferredIWDDevice(devices).name)
+
+	devices[1].connected = false
+	assert.Equal(t, "wlan0", preferredIWDDevice(devices).name)
+
+	// A device in access point mode can't be used for scanning
+	devices[0].station = false
+	devices[1].station = false
+	assert.Nil(t, preferredIWDDevice(devices))
+}
+
+func TestIWDBackend_WiFiDeviceList(t *testing.T) {
+	backend, _ := NewIWDBackend()
+	objects := iwdTestObjects()
+	backend.wifiDevices = iwdDevicesFromObjects(objects)
+	backend.useDevice(backend.wifiDevices[0], objects)
+	backend.updateWiFiDeviceList()
+
+	state, err := backend.GetCurrentState()
+	require.NoError(t, err)
+	assert.Equal(t, "wlan0", state.WiFiDevice)
+	assert.Equal(t, dbus.ObjectPath("/net/connman/iwd/0"), backend.adapterPath)
+	assert.Equal(t, []WiFiDeviceInfo{
+		{Name: "wlan0", Address: "aa:bb:cc:dd:ee:01", Selected: true},
+		{Name: "wlan1", Connected: true},
+	}, state.WiFiDevices)
+}
+
+func TestKnownNetworksFromObjects(t *testing.T) {
+	known := func(name, typ string, last string, auto *bool) map[string]map[string]dbus.Variant {
+		props := map[string]dbus.Variant{
+			"Name":   dbus.MakeVariant(name),
+			"Type":   dbus.MakeVariant(typ),
+			"Hidden": dbus.MakeVariant(false),
+		}
+		if last != "" {
+			props["LastConnectedTime"] = dbus.MakeVariant(last)
+		}
+		if auto != nil {
+			props["AutoConnect"] = dbus.MakeVariant(*auto)
+		}
+		return map[string]map[string]dbus.Variant{iwdKnownNetworkInterface: props}
+	}
+	off := false
+
+	objects := map[dbus.ObjectPath]map[string]map[string]dbus.Variant{
+		"/net/connman/iwd/1": known("Cafe", "open", "2026-10-01T09:00:00Z", &off),
+		"/net/connman/iwd/2": known("Home", "psk", "2026-10-15T18:04:11Z", nil),
+		"/net/connman/iwd/3": known("Office", "8021x", "", nil),
+		"/net/connman/iwd/0": {iwdAdapterInterface: {}},
+	}
+
+	networks := knownNetworksFromObjects(objects)
+	require.Len(t, networks, 3)
+
+	assert.Equal(t, "Home", networks[0].SSID)
+	assert.Equal(t, 1, networks[0].Rank)
+	assert.True(t, networks[0].AutoConnect)
+
+	assert.Equal(t, "Cafe", networks[1].SSID)
+	assert.False(t, networks[1].AutoConnect)
+	assert.Equal(t, "open", networks[1].Security)
+
+	assert.Equal(t, "Office", networks[2].SSID)
+	assert.Equal(t, 3, networks[2].Rank)
+	assert.Empty(t, networks[2].LastConnected)
+}
+
+func TestValidateWPSPin(t *testing.T) {
+	assert.NoError(t, validateWPSPin("1234"))
+	assert.NoError(t, validateWPSPin("12345670"))
+	assert.Error(t, validateWPSPin(""))
+	assert.Error(t, validateWPSPin("123456"))
+	assert.Error(t, validateWPSPin("1234567a"))
+}
+
+func TestIWDBackend_MapWPSError(t *testing.T) {
+	backend, _ := NewIWDBackend()
+
+	assert.Equal(t, "wps-overlap", backend.mapWPSError("net.connman.iwd.SimpleConfiguration.SessionOverlap"))
+	assert.Equal(t, "wps-timeout", backend.mapWPSError("net.connman.iwd.SimpleConfiguration.WalkTimeExpired"))
+	assert.Equal(t, "bad-credentials", backend.mapWPSError("net.connman.iwd.SimpleConfiguration.InvalidPin"))
+	assert.Equal(t, "user-canceled", backend.mapWPSError("net.connman.iwd.Aborted"))
+	assert.Equal(t, "connection-failed", backend.mapWPSError("net.connman.iwd.Failed"))
+}
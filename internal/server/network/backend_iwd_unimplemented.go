@@ -10,7 +10,7 @@ func (b *IWDBackend) GetWiredNetworkDetails(uuid string) (*WiredNetworkInfoRespo
 	return nil, fmt.Errorf("wired connections not supported by iwd")
 }
 
-func (b *IWDBackend) ConnectEthernet() error {
+func (b *IWDBackend) ConnectEthernet(auth *ConnectionRequest) error {
 	return fmt.Errorf("wired connections not supported by iwd")
 }
 
@@ -18,7 +18,7 @@ func (b *IWDBackend) DisconnectEthernet() error {
 	return fmt.Errorf("wired connections not supported by iwd")
 }
 
-func (b *IWDBackend) ActivateWiredConnection(uuid string) error {
+func (b *IWDBackend) ActivateWiredConnection(uuid, device string) error {
 	return fmt.Errorf("wired connections not supported by iwd")
 }
 
@@ -45,3 +45,47 @@ func (b *IWDBackend) DisconnectAllVPN() error {
 func (b *IWDBackend) ClearVPNCredentials(uuidOrName string) error {
 	return fmt.Errorf("VPN not supported by iwd backend")
 }
+
+func (b *IWDBackend) ImportOpenVPNProfile(path, name string) (*VPNProfile, error) {
+	return nil, fmt.Errorf("VPN not supported by iwd backend")
+}
+
+func (b *IWDBackend) SetWiredIPConfig(uuid string, config WiredIPConfig) error {
+	return fmt.Errorf("wired connections not supported by iwd")
+}
+
+func (b *IWDBackend) SetConnectionDNS(uuidOrSSID string, config DNSConfig) error {
+	return fmt.Errorf("not supported by iwd backend: set DNS in the network's file in /var/lib/iwd")
+}
+
+func (b *IWDBackend) GetSearchDomains(uuidOrSSID string) ([]string, error) {
+	return nil, fmt.Errorf("search domains not supported by iwd backend")
+}
+
+func (b *IWDBackend) SetSearchDomains(uuidOrSSID string, domains []string) error {
+	return fmt.Errorf("search domains not supported by iwd backend")
+}
+
+func (b *IWDBackend) GetMetered(uuidOrSSID string) (*MeteredInfo, error) {
+	return nil, fmt.Errorf("metered connections not supported by iwd backend")
+}
+
+func (b *IWDBackend) SetMetered(uuidOrSSID string, mode string) error {
+	return fmt.Errorf("metered connections not supported by iwd backend")
+}
+
+func (b *IWDBackend) GetIPv6Config(uuidOrSSID string) (*IPv6Config, error) {
+	return nil, fmt.Errorf("IPv6 settings not supported by iwd backend")
+}
+
+func (b *IWDBackend) SetIPv6Config(uuidOrSSID string, config IPv6Config) error {
+	return fmt.Errorf("not supported by iwd backend: set IPv6 in the network's file in /var/lib/iwd")
+}
+
+func (b *IWDBackend) GetWakeOnLAN(uuid string) (*WakeOnLANConfig, error) {
+	return nil, fmt.Errorf("wired connections not supported by iwd backend")
+}
+
+func (b *IWDBackend) SetWakeOnLAN(uuid string, config WakeOnLANConfig) error {
+	return fmt.Errorf("wired connections not supported by iwd backend")
+}
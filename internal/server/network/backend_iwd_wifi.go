@@ -2,9 +2,11 @@ package network
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/AvengeMedia/danklinux/internal/errdefs"
+	"github.com/AvengeMedia/danklinux/internal/log"
 	"github.com/godbus/dbus/v5"
 )
 
@@ -24,6 +26,10 @@ func (b *IWDBackend) updateState() error {
 		}
 	}
 
+	if b.updateHotspotState() {
+		return nil
+	}
+
 	if b.stationPath == "" {
 		return nil
 	}
@@ -58,6 +64,11 @@ func (b *IWDBackend) updateState() error {
 				}
 			}
 
+			bssid := b.connectedBSSID()
+			b.stateMutex.Lock()
+			b.state.WiFiBSSID = bssid
+			b.stateMutex.Unlock()
+
 			var orderedNetworks [][]dbus.Variant
 			err = stationObj.Call(iwdStationInterface+".GetOrderedNetworks", 0).Store(&orderedNetworks)
 			if err == nil {
@@ -248,6 +259,18 @@ func (b *IWDBackend) updateWiFiNetworks() ([]WiFiNetwork, error) {
 	return networks, nil
 }
 
+// connectedBSSID asks iwd's diagnostics which access point the station is
+// associated with. It is empty when iwd runs without diagnostics
+func (b *IWDBackend) connectedBSSID() string {
+	var diag map[string]dbus.Variant
+	err := b.conn.Object(iwdBusName, b.stationPath).Call(iwdStationDiagnosticInterface+".GetDiagnostics", 0).Store(&diag)
+	if err != nil {
+		return ""
+	}
+	bssid, _ := diag["ConnectedBss"].Value().(string)
+	return strings.ToUpper(bssid)
+}
+
 func (b *IWDBackend) getKnownNetworks() (map[string]bool, error) {
 	obj := b.conn.Object(iwdBusName, iwdObjectPath)
 
@@ -349,6 +372,10 @@ func (b *IWDBackend) finalizeAttempt(att *connectAttempt, code string) {
 	att.finalized = true
 	att.mu.Unlock()
 
+	if code == errdefs.ErrBadCredentials && b.iwdAgent != nil {
+		b.iwdAgent.secrets.rejected(att.ssid)
+	}
+
 	b.stateMutex.Lock()
 	b.state.IsConnecting = false
 	b.state.ConnectingSSID = ""
@@ -443,21 +470,27 @@ func (b *IWDBackend) ConnectWiFi(req ConnectionRequest) error {
 		}
 		return fmt.Errorf("no WiFi device available")
 	}
+	if wantsAPPin(req) {
+		return fmt.Errorf("pinning a BSSID or band is not supported by iwd")
+	}
 
+	// Hidden networks only get a network object once iwd has found them,
+	// so their path is filled in after ConnectHiddenNetwork returns
 	networkPath, err := b.findNetworkPath(req.SSID)
-	if err != nil {
+	if err != nil && !req.Hidden {
 		b.setConnectError(errdefs.ErrNoSuchSSID)
 		if b.onStateChange != nil {
 			b.onStateChange()
 		}
 		return fmt.Errorf("network not found: %w", err)
 	}
+	hidden := err != nil
 
 	att := &connectAttempt{
 		ssid:     req.SSID,
 		netPath:  networkPath,
 		start:    time.Now(),
-		deadline: time.Now().Add(15 * time.Second),
+		deadline: time.Now().Add(connectTimeout(req, defaultIWDConnectTimeout)),
 	}
 
 	b.attemptMutex.Lock()
@@ -474,9 +507,13 @@ func (b *IWDBackend) ConnectWiFi(req ConnectionRequest) error {
 		b.onStateChange()
 	}
 
-	netObj := b.conn.Object(iwdBusName, networkPath)
 	go func() {
-		call := netObj.Call(iwdNetworkInterface+".Connect", 0)
+		var call *dbus.Call
+		if hidden {
+			call = b.conn.Object(iwdBusName, b.stationPath).Call(iwdStationInterface+".ConnectHiddenNetwork", 0, req.SSID)
+		} else {
+			call = b.conn.Object(iwdBusName, networkPath).Call(iwdNetworkInterface+".Connect", 0)
+		}
 		if call.Err != nil {
 			var code string
 			if dbusErr, ok := call.Err.(dbus.Error); ok {
@@ -497,6 +534,14 @@ func (b *IWDBackend) ConnectWiFi(req ConnectionRequest) error {
 			return
 		}
 
+		if hidden {
+			if path, err := b.findNetworkPath(req.SSID); err == nil {
+				att.mu.Lock()
+				att.netPath = path
+				att.mu.Unlock()
+			}
+		}
+
 		b.startAttemptWatchdog(att)
 	}()
 
@@ -525,6 +570,33 @@ func (b *IWDBackend) findNetworkPath(ssid string) (dbus.ObjectPath, error) {
 	return "", fmt.Errorf("network not found")
 }
 
+// CancelConnect stops the attempt to join ssid, or the current attempt when
+// ssid is empty
+func (b *IWDBackend) CancelConnect(ssid string) error {
+	b.attemptMutex.Lock()
+	att := b.curAttempt
+	b.attemptMutex.Unlock()
+
+	if att == nil || (ssid != "" && att.ssid != ssid) {
+		return fmt.Errorf("not connecting to %s", ssidOrAny(ssid))
+	}
+	att.mu.Lock()
+	finalized := att.finalized
+	att.mu.Unlock()
+	if finalized {
+		return fmt.Errorf("not connecting to %s", ssidOrAny(ssid))
+	}
+
+	b.finalizeAttempt(att, errdefs.ErrUserCanceled)
+
+	if b.stationPath != "" {
+		if err := b.conn.Object(iwdBusName, b.stationPath).Call(iwdStationInterface+".Disconnect", 0).Err; err != nil {
+			log.Warnf("[CancelConnect] Failed to disconnect station: %v", err)
+		}
+	}
+	return nil
+}
+
 func (b *IWDBackend) DisconnectWiFi() error {
 	if b.stationPath == "" {
 		return fmt.Errorf("no WiFi device available")
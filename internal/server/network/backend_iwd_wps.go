@@ -0,0 +1,181 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/errdefs"
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	iwdWPSInterface = "net.connman.iwd.SimpleConfiguration"
+
+	WPSPushButton = "pushButton"
+	WPSPin        = "pin"
+
+	// wpsPromptTimeout covers iwd's two minute walk time
+	wpsPromptTimeout = 3 * time.Minute
+)
+
+// StartWPS joins the network offered by a WPS router on the selected device.
+// It returns once the session has started, with the PIN to enter on the
+// router for the pin method. The session is shown through the prompt broker
+// as a prompt without fields, and canceling that prompt ends it
+func (b *IWDBackend) StartWPS(method, pin string) (string, error) {
+	b.stateMutex.RLock()
+	devicePath := b.devicePath
+	stationPath := b.stationPath
+	b.stateMutex.RUnlock()
+
+	if stationPath == "" {
+		return "", fmt.Errorf("no WiFi device available")
+	}
+	obj := b.conn.Object(iwdBusName, devicePath)
+
+	switch method {
+	case WPSPushButton:
+		pin = ""
+	case WPSPin:
+		if pin == "" {
+			if err := obj.Call(iwdWPSInterface+".GeneratePin", 0).Store(&pin); err != nil {
+				return "", fmt.Errorf("failed to generate WPS PIN: %w", err)
+			}
+		} else if err := validateWPSPin(pin); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("invalid WPS method: %s (use %s or %s)", method, WPSPushButton, WPSPin)
+	}
+
+	b.stateMutex.Lock()
+	b.state.IsConnecting = true
+	b.state.ConnectingSSID = ""
+	b.state.LastError = ""
+	b.stateMutex.Unlock()
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	token := b.askWPS(obj, devicePath, method, pin)
+	go b.runWPS(obj, method, pin, token)
+
+	return pin, nil
+}
+
+func (b *IWDBackend) CancelWPS() error {
+	b.stateMutex.RLock()
+	devicePath := b.devicePath
+	b.stateMutex.RUnlock()
+
+	if err := b.conn.Object(iwdBusName, devicePath).Call(iwdWPSInterface+".Cancel", 0).Err; err != nil {
+		return fmt.Errorf("failed to cancel WPS: %w", err)
+	}
+	return nil
+}
+
+// askWPS tells the user what to do on the router and cancels the session if
+// they dismiss the prompt. It returns the prompt's token, empty without a
+// broker
+func (b *IWDBackend) askWPS(obj dbus.BusObject, devicePath dbus.ObjectPath, method, pin string) string {
+	if b.promptBroker == nil {
+		return ""
+	}
+
+	req := PromptRequest{
+		Name:           "WPS",
+		ConnType:       "wps",
+		SettingName:    "wps",
+		Reason:         method,
+		ConnectionPath: string(devicePath),
+		Fields:         []string{},
+	}
+	if pin != "" {
+		req.Hints = []string{pin}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), wpsPromptTimeout)
+	token, err := b.promptBroker.Ask(ctx, req)
+	if err != nil {
+		cancel()
+		log.Warnf("[iwd] Failed to show WPS prompt: %v", err)
+		return ""
+	}
+
+	go func() {
+		defer cancel()
+		reply, err := b.promptBroker.Wait(ctx, token)
+		if err != nil && reply.Cancel {
+			if err := obj.Call(iwdWPSInterface+".Cancel", 0).Err; err != nil {
+				log.Warnf("[iwd] Failed to cancel WPS: %v", err)
+			}
+		}
+	}()
+	return token
+}
+
+func (b *IWDBackend) runWPS(obj dbus.BusObject, method, pin, token string) {
+	var call *dbus.Call
+	if method == WPSPin {
+		call = obj.Call(iwdWPSInterface+".StartPin", 0, pin)
+	} else {
+		call = obj.Call(iwdWPSInterface+".PushButton", 0)
+	}
+
+	// Ends the prompt's wait; the user may have dismissed it already
+	if token != "" {
+		_ = b.promptBroker.Resolve(token, PromptReply{})
+	}
+
+	if call.Err != nil {
+		code := errdefs.ErrConnectionFailed
+		if dbusErr, ok := call.Err.(dbus.Error); ok {
+			code = b.mapWPSError(dbusErr.Name)
+		}
+		log.Warnf("[iwd] WPS %s failed: %v", method, call.Err)
+		b.setConnectError(code)
+	} else {
+		b.stateMutex.Lock()
+		b.state.IsConnecting = false
+		b.stateMutex.Unlock()
+		if err := b.updateState(); err != nil {
+			log.Warnf("[iwd] Failed to update state after WPS: %v", err)
+		}
+	}
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+}
+
+func (b *IWDBackend) mapWPSError(name string) string {
+	switch strings.TrimPrefix(name, iwdWPSInterface+".") {
+	case "SessionOverlap":
+		return errdefs.ErrWPSOverlap
+	case "WalkTimeExpired", "TimeExpired", "NotReachable":
+		return errdefs.ErrWPSTimeout
+	case "InvalidPin", "NoCredentials":
+		return errdefs.ErrBadCredentials
+	}
+	if name == "net.connman.iwd.Aborted" {
+		return errdefs.ErrUserCanceled
+	}
+	return b.mapIwdDBusError(name)
+}
+
+// validateWPSPin accepts the 4 and 8 digit PINs iwd takes; iwd checks the
+// 8 digit checksum itself
+func validateWPSPin(pin string) error {
+	if len(pin) != 4 && len(pin) != 8 {
+		return fmt.Errorf("WPS PIN must have 4 or 8 digits")
+	}
+	for _, r := range pin {
+		if r < '0' || r > '9' {
+			return fmt.Errorf("WPS PIN must have 4 or 8 digits")
+		}
+	}
+	return nil
+}
@@ -3,10 +3,12 @@ package network
 import (
 	"fmt"
 	"net"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
 	"github.com/godbus/dbus/v5"
 )
 
@@ -35,6 +37,8 @@ type SystemdNetworkdBackend struct {
 	stopChan      chan struct{}
 	signals       chan *dbus.Signal
 	sigWG         sync.WaitGroup
+	// selectedWiFi is the wireless link chosen with SelectWiFiDevice
+	selectedWiFi string
 }
 
 func NewSystemdNetworkdBackend() (*SystemdNetworkdBackend, error) {
@@ -50,7 +54,7 @@ func NewSystemdNetworkdBackend() (*SystemdNetworkdBackend, error) {
 }
 
 func (b *SystemdNetworkdBackend) Initialize() error {
-	c, err := dbus.ConnectSystemBus()
+	c, err := dbus.ConnectSystemBus(budget.DBusOptions("network")...)
 	if err != nil {
 		return fmt.Errorf("connect bus: %w", err)
 	}
@@ -126,8 +130,12 @@ func (b *SystemdNetworkdBackend) updateState() error {
 			}
 		}
 
-		if strings.HasPrefix(name, "wlan") || strings.HasPrefix(name, "wlp") {
-			if wifiIface == nil || link.opState == "routable" || link.opState == "carrier" {
+		if isWirelessLinkName(name) {
+			switch {
+			case name == b.selectedWiFi:
+				wifiIface = link
+			case wifiIface != nil && wifiIface.name == b.selectedWiFi:
+			case wifiIface == nil || link.opState == "routable" || link.opState == "carrier":
 				wifiIface = link
 			}
 		} else if !b.isVirtualInterface(name) {
@@ -137,20 +145,42 @@ func (b *SystemdNetworkdBackend) updateState() error {
 		}
 	}
 
+	var wifiDevices []WiFiDeviceInfo
+	var ethernetDevices []EthernetDeviceInfo
 	var wiredConns []WiredConnection
 	for name, link := range b.links {
-		if b.isVirtualInterface(name) || strings.HasPrefix(name, "wlan") || strings.HasPrefix(name, "wlp") {
+		if isWirelessLinkName(name) {
+			wifiDevices = append(wifiDevices, WiFiDeviceInfo{
+				Name:      name,
+				Connected: link.opState == "routable" || link.opState == "carrier",
+				Selected:  wifiIface != nil && wifiIface.name == name,
+			})
+		}
+		if b.isVirtualInterface(name) || isWirelessLinkName(name) {
 			continue
 		}
 
 		active := link.opState == "routable" || link.opState == "carrier"
-		wiredConns = append(wiredConns, WiredConnection{
+		conn := WiredConnection{
 			Path:     link.path,
 			ID:       name,
 			UUID:     "wired:" + name,
 			Type:     "ethernet",
 			IsActive: active,
-		})
+		}
+		dev := EthernetDeviceInfo{Name: name, Connected: active}
+		if iface, err := net.InterfaceByName(name); err == nil {
+			dev.Address = iface.HardwareAddr.String()
+		}
+		if active {
+			conn.Device = name
+			dev.Connection = conn.UUID
+			if addrs := b.getAddresses(name); len(addrs) > 0 {
+				dev.IP = addrs[0]
+			}
+		}
+		wiredConns = append(wiredConns, conn)
+		ethernetDevices = append(ethernetDevices, dev)
 	}
 
 	b.stateMutex.Lock()
@@ -162,6 +192,10 @@ func (b *SystemdNetworkdBackend) updateState() error {
 	b.state.WiFiConnected = false
 	b.state.WiFiIP = ""
 	b.state.WiredConnections = wiredConns
+	sort.Slice(wifiDevices, func(i, j int) bool { return wifiDevices[i].Name < wifiDevices[j].Name })
+	b.state.WiFiDevices = wifiDevices
+	sort.Slice(ethernetDevices, func(i, j int) bool { return ethernetDevices[i].Name < ethernetDevices[j].Name })
+	b.state.EthernetDevices = ethernetDevices
 
 	if wiredIface != nil {
 		b.state.EthernetDevice = wiredIface.name
@@ -196,6 +230,32 @@ func (b *SystemdNetworkdBackend) updateState() error {
 	return nil
 }
 
+func isWirelessLinkName(name string) bool {
+	return strings.HasPrefix(name, "wlan") || strings.HasPrefix(name, "wlp")
+}
+
+// SelectWiFiDevice picks which wireless link the state reports. networkd
+// doesn't manage WiFi itself, so this only matters for addresses and status
+func (b *SystemdNetworkdBackend) SelectWiFiDevice(iface string) error {
+	b.linksMutex.Lock()
+	_, ok := b.links[iface]
+	if ok && isWirelessLinkName(iface) {
+		b.selectedWiFi = iface
+	}
+	b.linksMutex.Unlock()
+	if !ok || !isWirelessLinkName(iface) {
+		return fmt.Errorf("no WiFi device named %q", iface)
+	}
+
+	if err := b.updateState(); err != nil {
+		return err
+	}
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+	return nil
+}
+
 func (b *SystemdNetworkdBackend) isVirtualInterface(name string) bool {
 	virtualPrefixes := []string{
 		"lo", "docker", "veth", "virbr", "br-", "vnet", "tun", "tap",
@@ -70,7 +70,11 @@ func (b *SystemdNetworkdBackend) GetWiredNetworkDetails(id string) (*WiredNetwor
 	}, nil
 }
 
-func (b *SystemdNetworkdBackend) ConnectEthernet() error {
+func (b *SystemdNetworkdBackend) ConnectEthernet(auth *ConnectionRequest) error {
+	if auth != nil {
+		return fmt.Errorf("not supported by networkd backend: configure 802.1X with wpa_supplicant-wired")
+	}
+
 	b.linksMutex.RLock()
 	var primaryWired *linkInfo
 	for name, l := range b.links {
@@ -94,8 +98,13 @@ func (b *SystemdNetworkdBackend) DisconnectEthernet() error {
 	return fmt.Errorf("not supported by networkd backend")
 }
 
-func (b *SystemdNetworkdBackend) ActivateWiredConnection(id string) error {
+// ActivateWiredConnection reconfigures the link named by id. networkd has
+// no profiles to move between links, so device can only repeat that name
+func (b *SystemdNetworkdBackend) ActivateWiredConnection(id, device string) error {
 	ifname := strings.TrimPrefix(id, "wired:")
+	if device != "" && device != ifname {
+		return fmt.Errorf("%s can only be activated on %s", id, ifname)
+	}
 
 	b.linksMutex.RLock()
 	link, exists := b.links[ifname]
@@ -108,3 +117,43 @@ func (b *SystemdNetworkdBackend) ActivateWiredConnection(id string) error {
 	linkObj := b.conn.Object(networkdBusName, link.path)
 	return linkObj.Call(networkdLinkIface+".Reconfigure", 0).Err
 }
+
+func (b *SystemdNetworkdBackend) SetWiredIPConfig(id string, config WiredIPConfig) error {
+	return fmt.Errorf("not supported by networkd backend: edit the interface's .network file in /etc/systemd/network")
+}
+
+func (b *SystemdNetworkdBackend) SetConnectionDNS(id string, config DNSConfig) error {
+	return fmt.Errorf("not supported by networkd backend: set DNS= and DNSOverTLS= in the interface's .network file in /etc/systemd/network")
+}
+
+func (b *SystemdNetworkdBackend) GetSearchDomains(id string) ([]string, error) {
+	return nil, fmt.Errorf("search domains not supported by networkd backend")
+}
+
+func (b *SystemdNetworkdBackend) SetSearchDomains(id string, domains []string) error {
+	return fmt.Errorf("not supported by networkd backend: set Domains= in the interface's .network file in /etc/systemd/network")
+}
+
+func (b *SystemdNetworkdBackend) GetMetered(id string) (*MeteredInfo, error) {
+	return nil, fmt.Errorf("metered connections not supported by networkd backend")
+}
+
+func (b *SystemdNetworkdBackend) SetMetered(id string, mode string) error {
+	return fmt.Errorf("not supported by networkd backend: networkd has no metered setting")
+}
+
+func (b *SystemdNetworkdBackend) GetIPv6Config(id string) (*IPv6Config, error) {
+	return nil, fmt.Errorf("IPv6 settings not supported by networkd backend")
+}
+
+func (b *SystemdNetworkdBackend) SetIPv6Config(id string, config IPv6Config) error {
+	return fmt.Errorf("not supported by networkd backend: set IPv6AcceptRA= and IPv6PrivacyExtensions= in the interface's .network file in /etc/systemd/network")
+}
+
+func (b *SystemdNetworkdBackend) GetWakeOnLAN(id string) (*WakeOnLANConfig, error) {
+	return nil, fmt.Errorf("not supported by networkd backend: see WakeOnLan= in the interface's .link file in /etc/systemd/network")
+}
+
+func (b *SystemdNetworkdBackend) SetWakeOnLAN(id string, config WakeOnLANConfig) error {
+	return fmt.Errorf("not supported by networkd backend: set WakeOnLan= in the interface's .link file in /etc/systemd/network")
+}
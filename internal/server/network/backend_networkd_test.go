@@ -123,3 +123,11 @@ func TestSystemdNetworkdBackend_DisconnectEthernet(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not supported")
 }
+
+func TestSystemdNetworkdBackend_ActivateWiredConnectionOtherDevice(t *testing.T) {
+	backend, _ := NewSystemdNetworkdBackend()
+
+	err := backend.ActivateWiredConnection("wired:enp0s31f6", "enx001122334455")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "can only be activated on enp0s31f6")
+}
@@ -22,6 +22,10 @@ func (b *SystemdNetworkdBackend) ConnectWiFi(req ConnectionRequest) error {
 	return fmt.Errorf("WiFi connect not supported by networkd backend")
 }
 
+func (b *SystemdNetworkdBackend) CancelConnect(ssid string) error {
+	return fmt.Errorf("WiFi connect not supported by networkd backend")
+}
+
 func (b *SystemdNetworkdBackend) DisconnectWiFi() error {
 	return fmt.Errorf("WiFi disconnect not supported by networkd backend")
 }
@@ -30,6 +34,10 @@ func (b *SystemdNetworkdBackend) ForgetWiFiNetwork(ssid string) error {
 	return fmt.Errorf("WiFi forget not supported by networkd backend")
 }
 
+func (b *SystemdNetworkdBackend) ListSavedWiFiNetworks() ([]SavedWiFiNetwork, error) {
+	return nil, fmt.Errorf("saved WiFi networks not supported by networkd backend")
+}
+
 func (b *SystemdNetworkdBackend) ListVPNProfiles() ([]VPNProfile, error) {
 	return []VPNProfile{}, nil
 }
@@ -53,3 +61,15 @@ func (b *SystemdNetworkdBackend) DisconnectAllVPN() error {
 func (b *SystemdNetworkdBackend) ClearVPNCredentials(uuidOrName string) error {
 	return fmt.Errorf("VPN not supported by networkd backend")
 }
+
+func (b *SystemdNetworkdBackend) ImportOpenVPNProfile(path, name string) (*VPNProfile, error) {
+	return nil, fmt.Errorf("VPN not supported by networkd backend")
+}
+
+func (b *SystemdNetworkdBackend) StartHotspot(ssid, password, band string) error {
+	return fmt.Errorf("hotspot not supported by networkd backend")
+}
+
+func (b *SystemdNetworkdBackend) StopHotspot() error {
+	return fmt.Errorf("hotspot not supported by networkd backend")
+}
@@ -36,6 +36,11 @@ type NetworkManagerBackend struct {
 	wifiDevice     interface{}
 	settings       interface{}
 	wifiDev        interface{}
+	// wifiDevices are all wireless devices; wifiDevice is the selected one
+	wifiDevices []gonetworkmanager.Device
+	// ethernetDevices are all managed wired devices; ethernetDevice is the
+	// connected one, else the first
+	ethernetDevices []gonetworkmanager.Device
 
 	dbusConn *dbus.Conn
 	signals  chan *dbus.Signal
@@ -52,6 +57,14 @@ type NetworkManagerBackend struct {
 	lastFailedTime int64
 	failedMutex    sync.RWMutex
 
+	pendingConn        interface{}
+	pendingConnSSID    string
+	pendingConnCreated bool
+	pendingMutex       sync.Mutex
+
+	curAttempt   *nmConnectAttempt
+	attemptMutex sync.Mutex
+
 	onStateChange func()
 }
 
@@ -95,36 +108,39 @@ func (b *NetworkManagerBackend) Initialize() error {
 			if managed, _ := dev.GetPropertyManaged(); !managed {
 				continue
 			}
-			b.ethernetDevice = dev
-			if err := b.updateEthernetState(); err != nil {
-				continue
-			}
-			_, err := b.listEthernetConnections()
-			if err != nil {
-				return fmt.Errorf("failed to get wired configurations: %w", err)
-			}
+			b.ethernetDevices = append(b.ethernetDevices, dev)
 
 		case gonetworkmanager.NmDeviceTypeWifi:
-			b.wifiDevice = dev
-			if w, err := gonetworkmanager.NewDeviceWireless(dev.GetPath()); err == nil {
-				b.wifiDev = w
-			}
-			wifiEnabled, err := nm.GetPropertyWirelessEnabled()
-			if err == nil {
-				b.stateMutex.Lock()
-				b.state.WiFiEnabled = wifiEnabled
-				b.stateMutex.Unlock()
-			}
-			if err := b.updateWiFiState(); err != nil {
-				continue
+			b.wifiDevices = append(b.wifiDevices, dev)
+		}
+	}
+
+	if len(b.ethernetDevices) > 0 {
+		if err := b.updateEthernetState(); err == nil {
+			if _, err := b.listEthernetConnections(); err != nil {
+				return fmt.Errorf("failed to get wired configurations: %w", err)
 			}
-			if wifiEnabled {
-				if _, err := b.updateWiFiNetworks(); err != nil {
-					log.Warnf("Failed to get initial networks: %v", err)
-				}
+		}
+	}
+
+	if dev := preferredDevice(b.wifiDevices); dev != nil {
+		b.wifiDevice = dev
+		if w, err := gonetworkmanager.NewDeviceWireless(dev.GetPath()); err == nil {
+			b.wifiDev = w
+		}
+		wifiEnabled, err := nm.GetPropertyWirelessEnabled()
+		if err == nil {
+			b.stateMutex.Lock()
+			b.state.WiFiEnabled = wifiEnabled
+			b.stateMutex.Unlock()
+		}
+		if err := b.updateWiFiState(); err == nil && wifiEnabled {
+			if _, err := b.updateWiFiNetworks(); err != nil {
+				log.Warnf("Failed to get initial networks: %v", err)
 			}
 		}
 	}
+	b.updateWiFiDeviceList()
 
 	if err := b.updatePrimaryConnection(); err != nil {
 		return err
@@ -159,6 +175,8 @@ func (b *NetworkManagerBackend) GetCurrentState() (*BackendState, error) {
 	state.WiredConnections = append([]WiredConnection(nil), b.state.WiredConnections...)
 	state.VPNProfiles = append([]VPNProfile(nil), b.state.VPNProfiles...)
 	state.VPNActive = append([]VPNActive(nil), b.state.VPNActive...)
+	state.WiFiDevices = append([]WiFiDeviceInfo(nil), b.state.WiFiDevices...)
+	state.EthernetDevices = append([]EthernetDeviceInfo(nil), b.state.EthernetDevices...)
 
 	return &state, nil
 }
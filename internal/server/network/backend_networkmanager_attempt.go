@@ -0,0 +1,319 @@
+package network
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/errdefs"
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/Wifx/gonetworkmanager/v2"
+)
+
+// defaultNMConnectTimeout bounds an activation that never reaches activated
+// or failed, e.g. when the access point stops answering mid-handshake
+const defaultNMConnectTimeout = 45 * time.Second
+
+type nmConnectAttempt struct {
+	ssid        string
+	start       time.Time
+	timeout     time.Duration
+	deadline    time.Time
+	sawConfig   bool
+	sawIPConfig bool
+	requested   bool
+	finalized   bool
+}
+
+func connectTimeout(req ConnectionRequest, fallback time.Duration) time.Duration {
+	if req.Timeout > 0 {
+		return time.Duration(req.Timeout) * time.Second
+	}
+	return fallback
+}
+
+func (b *NetworkManagerBackend) startConnectAttempt(req ConnectionRequest) *nmConnectAttempt {
+	now := time.Now()
+	timeout := connectTimeout(req, defaultNMConnectTimeout)
+	att := &nmConnectAttempt{
+		ssid:     req.SSID,
+		start:    now,
+		timeout:  timeout,
+		deadline: now.Add(timeout),
+	}
+
+	b.attemptMutex.Lock()
+	b.curAttempt = att
+	b.attemptMutex.Unlock()
+
+	b.sigWG.Add(1)
+	go b.connectWatchdog(att)
+	return att
+}
+
+func (b *NetworkManagerBackend) connectWatchdog(att *nmConnectAttempt) {
+	defer b.sigWG.Done()
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stopChan:
+			return
+		case <-ticker.C:
+		}
+
+		b.attemptMutex.Lock()
+		current := b.curAttempt == att && !att.finalized
+		b.attemptMutex.Unlock()
+		if !current || !b.IsConnectingTo(att.ssid) {
+			return
+		}
+
+		state := b.wifiDeviceState()
+
+		b.attemptMutex.Lock()
+		switch state {
+		case gonetworkmanager.NmDeviceStateNeedAuth:
+			// Waiting on the user to type a password isn't a stuck attempt
+			att.deadline = time.Now().Add(att.timeout)
+		case gonetworkmanager.NmDeviceStateConfig:
+			att.sawConfig = true
+		case gonetworkmanager.NmDeviceStateIpConfig, gonetworkmanager.NmDeviceStateIpCheck:
+			att.sawIPConfig = true
+		}
+		expired := time.Now().After(att.deadline)
+		b.attemptMutex.Unlock()
+
+		if expired {
+			code := b.classifyNMTimeout(att)
+			log.Warnf("[connectWatchdog] Connection to %s timed out after %s: %s", att.ssid, time.Since(att.start).Round(time.Second), code)
+			b.abortConnectAttempt(att, code)
+			return
+		}
+	}
+}
+
+func (b *NetworkManagerBackend) wifiDeviceState() gonetworkmanager.NmDeviceState {
+	if b.wifiDevice == nil {
+		return gonetworkmanager.NmDeviceStateUnknown
+	}
+	state, err := b.wifiDevice.(gonetworkmanager.Device).GetPropertyState()
+	if err != nil {
+		return gonetworkmanager.NmDeviceStateUnknown
+	}
+	return state
+}
+
+// classifyNMTimeout names what a timed out attempt got stuck on, the same
+// way the iwd watchdog does
+func (b *NetworkManagerBackend) classifyNMTimeout(att *nmConnectAttempt) string {
+	b.attemptMutex.Lock()
+	sawConfig, sawIPConfig := att.sawConfig, att.sawIPConfig
+	b.attemptMutex.Unlock()
+
+	switch {
+	case sawIPConfig:
+		return errdefs.ErrDhcpTimeout
+	case sawConfig:
+		return errdefs.ErrAssocTimeout
+	}
+
+	b.stateMutex.RLock()
+	defer b.stateMutex.RUnlock()
+	for _, network := range b.state.WiFiNetworks {
+		if network.SSID == att.ssid {
+			return errdefs.ErrAssocTimeout
+		}
+	}
+	return errdefs.ErrNoSuchSSID
+}
+
+// abortConnectAttempt ends att with code and takes the device down so
+// NetworkManager stops retrying in the background
+func (b *NetworkManagerBackend) abortConnectAttempt(att *nmConnectAttempt, code string) {
+	b.attemptMutex.Lock()
+	if att.finalized {
+		b.attemptMutex.Unlock()
+		return
+	}
+	att.finalized = true
+	b.attemptMutex.Unlock()
+
+	b.stateMutex.Lock()
+	b.state.IsConnecting = false
+	b.state.ConnectingSSID = ""
+	b.state.LastError = code
+	b.stateMutex.Unlock()
+
+	if b.wifiDevice != nil {
+		if err := b.wifiDevice.(gonetworkmanager.Device).Disconnect(); err != nil {
+			log.Warnf("[abortConnectAttempt] Failed to deactivate %s: %v", att.ssid, err)
+		}
+	}
+	b.removePendingConnection(att.ssid)
+
+	b.updateWiFiState()
+	b.updatePrimaryConnection()
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+}
+
+// CancelConnect stops the attempt to join ssid, or the current attempt when
+// ssid is empty
+func (b *NetworkManagerBackend) CancelConnect(ssid string) error {
+	b.stateMutex.RLock()
+	connecting := b.state.IsConnecting
+	connectingSSID := b.state.ConnectingSSID
+	b.stateMutex.RUnlock()
+
+	if !connecting || (ssid != "" && ssid != connectingSSID) {
+		return fmt.Errorf("not connecting to %s", ssidOrAny(ssid))
+	}
+
+	b.attemptMutex.Lock()
+	att := b.curAttempt
+	b.attemptMutex.Unlock()
+	if att == nil || att.ssid != connectingSSID {
+		att = &nmConnectAttempt{ssid: connectingSSID}
+	}
+
+	b.abortConnectAttempt(att, errdefs.ErrUserCanceled)
+	return nil
+}
+
+func ssidOrAny(ssid string) string {
+	if ssid == "" {
+		return "any network"
+	}
+	return ssid
+}
+
+func (b *NetworkManagerBackend) endConnectAttempt(att *nmConnectAttempt) {
+	b.attemptMutex.Lock()
+	att.finalized = true
+	b.attemptMutex.Unlock()
+}
+
+// markAttemptRequested records that NetworkManager accepted the activation.
+// Until then the device still shows whatever it was connected to before
+func (b *NetworkManagerBackend) markAttemptRequested(att *nmConnectAttempt) {
+	b.attemptMutex.Lock()
+	att.requested = true
+	b.attemptMutex.Unlock()
+}
+
+type attemptOwnership int
+
+const (
+	attemptOwned attemptOwnership = iota
+	attemptAdopted
+	attemptSuperseded
+)
+
+// reconcileAttempt decides what the device's active connection means for an
+// attempt to join connectingSSID. Another network activating or activated
+// there means someone else (nmcli, another applet) took the device over; the
+// network we wanted coming up, by whoever's hand, completes the attempt
+func reconcileAttempt(connectingSSID, activeSSID string, activeState gonetworkmanager.NmActiveConnectionState) attemptOwnership {
+	switch activeState {
+	case gonetworkmanager.NmActiveConnectionStateActivating:
+		if activeSSID != connectingSSID {
+			return attemptSuperseded
+		}
+	case gonetworkmanager.NmActiveConnectionStateActivated:
+		if activeSSID != connectingSSID {
+			return attemptSuperseded
+		}
+		return attemptAdopted
+	}
+	return attemptOwned
+}
+
+// wifiActivation reports the SSID and state of the WiFi device's active
+// connection. An access point profile counts too, under its own SSID
+func (b *NetworkManagerBackend) wifiActivation() (string, gonetworkmanager.NmActiveConnectionState, bool) {
+	if b.wifiDevice == nil {
+		return "", 0, false
+	}
+	activeConn, err := b.wifiDevice.(gonetworkmanager.Device).GetPropertyActiveConnection()
+	if err != nil || activeConn == nil || activeConn.GetPath() == "/" {
+		return "", 0, false
+	}
+	state, err := activeConn.GetPropertyState()
+	if err != nil {
+		return "", 0, false
+	}
+	conn, err := activeConn.GetPropertyConnection()
+	if err != nil || conn == nil {
+		return "", 0, false
+	}
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return "", 0, false
+	}
+	ssid, _ := settings["802-11-wireless"]["ssid"].([]byte)
+	return string(ssid), state, true
+}
+
+// reconcileConnecting runs on every ActiveConnections change so a connection
+// made outside DMS while it shows an attempt doesn't leave isConnecting set
+// forever. A superseded attempt is dropped without touching the device,
+// which now belongs to the other connection
+func (b *NetworkManagerBackend) reconcileConnecting() {
+	b.stateMutex.RLock()
+	connecting := b.state.IsConnecting
+	connectingSSID := b.state.ConnectingSSID
+	b.stateMutex.RUnlock()
+	if !connecting || connectingSSID == "" {
+		return
+	}
+
+	b.attemptMutex.Lock()
+	att := b.curAttempt
+	requested := att != nil && att.ssid == connectingSSID && att.requested && !att.finalized
+	b.attemptMutex.Unlock()
+	if !requested {
+		return
+	}
+
+	activeSSID, activeState, ok := b.wifiActivation()
+	if !ok {
+		return
+	}
+
+	outcome := reconcileAttempt(connectingSSID, activeSSID, activeState)
+	if outcome == attemptOwned {
+		return
+	}
+
+	b.attemptMutex.Lock()
+	if att.finalized {
+		b.attemptMutex.Unlock()
+		return
+	}
+	att.finalized = true
+	b.attemptMutex.Unlock()
+
+	b.stateMutex.Lock()
+	if b.state.IsConnecting && b.state.ConnectingSSID == connectingSSID {
+		b.state.IsConnecting = false
+		b.state.ConnectingSSID = ""
+		b.state.LastError = ""
+		if outcome == attemptSuperseded {
+			b.state.LastError = errdefs.ErrSuperseded
+		}
+	}
+	b.stateMutex.Unlock()
+
+	if outcome == attemptSuperseded {
+		log.Infof("[reconcileConnecting] Attempt to join %s superseded by %s", connectingSSID, activeSSID)
+		b.removePendingConnection(connectingSSID)
+	} else {
+		log.Infof("[reconcileConnecting] %s came up outside the attempt, adopting it", connectingSSID)
+		b.clearPendingConnection()
+	}
+	b.updateWiFiState()
+}
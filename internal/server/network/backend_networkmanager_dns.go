@@ -0,0 +1,135 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/Wifx/gonetworkmanager/v2"
+)
+
+// SetConnectionDNS sets the DNS servers and DNS-over-TLS mode of a saved
+// profile, looked up by UUID or else by WiFi SSID. Active profiles are
+// reapplied so the change takes effect without reconnecting
+func (b *NetworkManagerBackend) SetConnectionDNS(uuidOrSSID string, config DNSConfig) error {
+	parsed, err := parseDNSConfig(config)
+	if err != nil {
+		return err
+	}
+
+	conn, err := b.findConnectionByUUID(uuidOrSSID)
+	if err != nil {
+		if conn, err = b.findConnection(uuidOrSSID); err != nil {
+			return fmt.Errorf("no saved connection matches %q", uuidOrSSID)
+		}
+	}
+
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return fmt.Errorf("failed to get connection settings: %w", err)
+	}
+
+	var dev gonetworkmanager.Device
+	uuid, _ := settings["connection"]["uuid"].(string)
+	connType, _ := settings["connection"]["type"].(string)
+	switch connType {
+	case "802-3-ethernet":
+		dev = b.wiredDeviceFor(uuid)
+	case "802-11-wireless":
+		if b.wifiDevice != nil {
+			dev = b.wifiDevice.(gonetworkmanager.Device)
+		}
+	default:
+		return fmt.Errorf("DNS can only be set on wired and WiFi connections")
+	}
+
+	applyDNSSettings(settings, parsed)
+	if err := conn.Update(settings); err != nil {
+		return fmt.Errorf("failed to update connection: %w", err)
+	}
+	log.Infof("[SetConnectionDNS] Set %d DNS servers on %s, dns-over-tls %s", len(parsed.IPv4)+len(parsed.IPv6), uuid, dnsOverTLSModes[parsed.DNSOverTLS+1])
+
+	if dev == nil || !b.isDeviceRunning(dev, uuid) {
+		return nil
+	}
+	if err := b.reapplyDevice(dev); err != nil {
+		log.Warnf("[SetConnectionDNS] Reapply failed, re-activating: %v", err)
+		nm := b.nmConn.(gonetworkmanager.NetworkManager)
+		if _, err := nm.ActivateConnection(conn, dev, nil); err != nil {
+			return fmt.Errorf("failed to re-activate connection: %w", err)
+		}
+	}
+	return nil
+}
+
+// isDeviceRunning reports whether the device's active connection is the
+// profile with the given UUID
+func (b *NetworkManagerBackend) isDeviceRunning(dev gonetworkmanager.Device, uuid string) bool {
+	activeConn, err := dev.GetPropertyActiveConnection()
+	if err != nil || activeConn == nil {
+		return false
+	}
+	activeUUID, err := activeConn.GetPropertyUUID()
+	return err == nil && activeUUID == uuid
+}
+
+func (b *NetworkManagerBackend) GetSearchDomains(uuidOrSSID string) ([]string, error) {
+	conn, err := b.findConnectionByUUID(uuidOrSSID)
+	if err != nil {
+		if conn, err = b.findConnection(uuidOrSSID); err != nil {
+			return nil, fmt.Errorf("no saved connection matches %q", uuidOrSSID)
+		}
+	}
+
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection settings: %w", err)
+	}
+	return searchDomainsFromSettings(settings), nil
+}
+
+// SetSearchDomains replaces the search domains of a saved wired, WiFi or VPN
+// profile. Wired and WiFi profiles in use are reapplied; a connected VPN
+// picks them up when it reconnects
+func (b *NetworkManagerBackend) SetSearchDomains(uuidOrSSID string, domains []string) error {
+	domains, err := normalizeSearchDomains(domains)
+	if err != nil {
+		return err
+	}
+
+	conn, err := b.findConnectionByUUID(uuidOrSSID)
+	if err != nil {
+		if conn, err = b.findConnection(uuidOrSSID); err != nil {
+			return fmt.Errorf("no saved connection matches %q", uuidOrSSID)
+		}
+	}
+
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return fmt.Errorf("failed to get connection settings: %w", err)
+	}
+	switch connType, _ := settings["connection"]["type"].(string); connType {
+	case "802-3-ethernet", "802-11-wireless", "vpn", "wireguard":
+	default:
+		return fmt.Errorf("search domains can only be set on wired, WiFi and VPN connections")
+	}
+	uuid, _ := settings["connection"]["uuid"].(string)
+
+	applySearchDomains(settings, domains)
+	if err := conn.Update(settings); err != nil {
+		return fmt.Errorf("failed to update connection: %w", err)
+	}
+	log.Infof("[SetSearchDomains] Set search domains %v on %s", domains, uuid)
+
+	dev := b.deviceForConnection(settings)
+	if dev == nil || !b.isDeviceRunning(dev, uuid) {
+		return nil
+	}
+	if err := b.reapplyDevice(dev); err != nil {
+		log.Warnf("[SetSearchDomains] Reapply failed, re-activating: %v", err)
+		nm := b.nmConn.(gonetworkmanager.NetworkManager)
+		if _, err := nm.ActivateConnection(conn, dev, nil); err != nil {
+			return fmt.Errorf("failed to re-activate connection: %w", err)
+		}
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/AvengeMedia/danklinux/internal/log"
 	"github.com/Wifx/gonetworkmanager/v2"
+	"github.com/godbus/dbus/v5"
 )
 
 func (b *NetworkManagerBackend) GetWiredConnections() ([]WiredConnection, error) {
@@ -15,12 +16,11 @@ func (b *NetworkManagerBackend) GetWiredConnections() ([]WiredConnection, error)
 }
 
 func (b *NetworkManagerBackend) GetWiredNetworkDetails(uuid string) (*WiredNetworkInfoResponse, error) {
-	if b.ethernetDevice == nil {
+	dev := b.wiredDeviceFor(uuid)
+	if dev == nil {
 		return nil, fmt.Errorf("no ethernet device available")
 	}
 
-	dev := b.ethernetDevice.(gonetworkmanager.Device)
-
 	iface, _ := dev.GetPropertyInterface()
 	driver, _ := dev.GetPropertyDriver()
 
@@ -34,6 +34,16 @@ func (b *NetworkManagerBackend) GetWiredNetworkDetails(uuid string) (*WiredNetwo
 	var ipv4Config WiredIPConfig
 	var ipv6Config WiredIPConfig
 
+	var ipv4Method, ipv6Method string
+	dnsConfig := DNSConfig{Servers: []string{}, DNSOverTLS: DNSOverTLSDefault}
+	if conn, err := b.findConnectionByUUID(uuid); err == nil {
+		if settings, err := conn.GetSettings(); err == nil {
+			ipv4Method, _ = settings["ipv4"]["method"].(string)
+			ipv6Method, _ = settings["ipv6"]["method"].(string)
+			dnsConfig = dnsConfigFromSettings(settings)
+		}
+	}
+
 	activeConn, err := dev.GetPropertyActiveConnection()
 	if err == nil && activeConn != nil {
 		ip4Config, err := activeConn.GetPropertyIP4Config()
@@ -60,6 +70,7 @@ func (b *NetworkManagerBackend) GetWiredNetworkDetails(uuid string) (*WiredNetwo
 			}
 
 			ipv4Config = WiredIPConfig{
+				Method:  ipv4Method,
 				IPs:     ips,
 				Gateway: gateway,
 				DNS:     dnsAddrs,
@@ -93,6 +104,7 @@ func (b *NetworkManagerBackend) GetWiredNetworkDetails(uuid string) (*WiredNetwo
 			}
 
 			ipv6Config = WiredIPConfig{
+				Method:  ipv6Method,
 				IPs:     ips,
 				Gateway: gateway,
 				DNS:     dnsAddrs,
@@ -108,10 +120,15 @@ func (b *NetworkManagerBackend) GetWiredNetworkDetails(uuid string) (*WiredNetwo
 		Speed:  strconv.Itoa(int(speed)),
 		IPv4:   ipv4Config,
 		IPv6:   ipv6Config,
+		DNS:    dnsConfig,
 	}, nil
 }
 
-func (b *NetworkManagerBackend) ConnectEthernet() error {
+// wiredEAPConnectionID names the profile made for a port behind 802.1X.
+// Connecting again with other credentials updates it in place
+const wiredEAPConnectionID = "Wired connection (802.1X)"
+
+func (b *NetworkManagerBackend) ConnectEthernet(auth *ConnectionRequest) error {
 	if b.ethernetDevice == nil {
 		return fmt.Errorf("no ethernet device available")
 	}
@@ -124,6 +141,10 @@ func (b *NetworkManagerBackend) ConnectEthernet() error {
 		return fmt.Errorf("failed to get settings: %w", err)
 	}
 
+	if auth != nil {
+		return b.connectWiredEAP(nm, settingsMgr, dev, *auth)
+	}
+
 	connections, err := settingsMgr.ListConnections()
 	if err != nil {
 		return fmt.Errorf("failed to get connections: %w", err)
@@ -177,6 +198,64 @@ func (b *NetworkManagerBackend) ConnectEthernet() error {
 	return nil
 }
 
+func wiredEAPSettings(auth ConnectionRequest) (gonetworkmanager.ConnectionSettings, error) {
+	x, err := eapSettings(auth)
+	if err != nil {
+		return nil, err
+	}
+	return gonetworkmanager.ConnectionSettings{
+		"connection": {
+			"id":          wiredEAPConnectionID,
+			"type":        "802-3-ethernet",
+			"autoconnect": true,
+		},
+		"802-3-ethernet": {},
+		"802-1x":         x,
+		"ipv4":           {"method": "auto"},
+		"ipv6":           {"method": "auto"},
+	}, nil
+}
+
+// connectWiredEAP saves the 802.1X profile and activates it, the way an
+// interactive enterprise WiFi connection is made: secrets left out of auth
+// are asked for through the secret agent with connType 802-3-ethernet
+func (b *NetworkManagerBackend) connectWiredEAP(nm gonetworkmanager.NetworkManager, settingsMgr gonetworkmanager.Settings, dev gonetworkmanager.Device, auth ConnectionRequest) error {
+	settings, err := wiredEAPSettings(auth)
+	if err != nil {
+		return err
+	}
+
+	conn, current, err := findSavedProfile(wiredEAPConnectionID)
+	if connType, _ := current["connection"]["type"].(string); err == nil && connType == "802-3-ethernet" {
+		current["802-1x"] = settings["802-1x"]
+		if err := conn.Update(current); err != nil {
+			return fmt.Errorf("failed to update 802.1X profile: %w", err)
+		}
+	} else {
+		conn, err = settingsMgr.AddConnection(settings)
+		if err != nil {
+			return fmt.Errorf("failed to add 802.1X profile: %w", err)
+		}
+	}
+
+	x := settings["802-1x"]
+	log.Infof("[ConnectEthernet] 802.1X: eap=%v, phase2-auth=%v, identity=%s, interactive=%v, ca-cert=%v",
+		x["eap"], x["phase2-auth"], auth.Username, auth.Interactive, auth.CACert != "")
+
+	if _, err := nm.ActivateConnection(conn, dev, nil); err != nil {
+		return fmt.Errorf("failed to activate ethernet: %w", err)
+	}
+
+	b.updateEthernetState()
+	b.listEthernetConnections()
+	b.updatePrimaryConnection()
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+	return nil
+}
+
 func (b *NetworkManagerBackend) DisconnectEthernet() error {
 	if b.ethernetDevice == nil {
 		return fmt.Errorf("no ethernet device available")
@@ -200,25 +279,56 @@ func (b *NetworkManagerBackend) DisconnectEthernet() error {
 	return nil
 }
 
-func (b *NetworkManagerBackend) ActivateWiredConnection(uuid string) error {
-	if b.ethernetDevice == nil {
+// ActivateWiredConnection activates a wired profile on device, or when that
+// is empty on the interface the profile is bound to or the primary device
+func (b *NetworkManagerBackend) ActivateWiredConnection(uuid, device string) error {
+	if len(b.ethernetDevices) == 0 && b.ethernetDevice == nil {
 		return fmt.Errorf("no ethernet device available")
 	}
 
 	nm := b.nmConn.(gonetworkmanager.NetworkManager)
-	dev := b.ethernetDevice.(gonetworkmanager.Device)
 
+	targetConnection, err := b.findConnectionByUUID(uuid)
+	if err != nil {
+		return err
+	}
+
+	settings, err := targetConnection.GetSettings()
+	if err != nil {
+		return fmt.Errorf("failed to get connection settings: %w", err)
+	}
+	dev, err := b.wiredTargetDevice(settings, device)
+	if err != nil {
+		return err
+	}
+
+	_, err = nm.ActivateConnection(targetConnection, dev, nil)
+	if err != nil {
+		return fmt.Errorf("error activation connection: %w", err)
+	}
+
+	b.updateEthernetState()
+	b.listEthernetConnections()
+	b.updatePrimaryConnection()
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	return nil
+}
+
+func (b *NetworkManagerBackend) findConnectionByUUID(uuid string) (gonetworkmanager.Connection, error) {
 	settingsMgr, err := gonetworkmanager.NewSettings()
 	if err != nil {
-		return fmt.Errorf("failed to get settings: %w", err)
+		return nil, fmt.Errorf("failed to get settings: %w", err)
 	}
 
 	connections, err := settingsMgr.ListConnections()
 	if err != nil {
-		return fmt.Errorf("failed to get connections: %w", err)
+		return nil, fmt.Errorf("failed to get connections: %w", err)
 	}
 
-	var targetConnection gonetworkmanager.Connection
 	for _, conn := range connections {
 		settings, err := conn.GetSettings()
 		if err != nil {
@@ -227,23 +337,54 @@ func (b *NetworkManagerBackend) ActivateWiredConnection(uuid string) error {
 
 		if connectionSettings, ok := settings["connection"]; ok {
 			if connUUID, ok := connectionSettings["uuid"].(string); ok && connUUID == uuid {
-				targetConnection = conn
-				break
+				return conn, nil
 			}
 		}
 	}
 
-	if targetConnection == nil {
-		return fmt.Errorf("connection with UUID %s not found", uuid)
+	return nil, fmt.Errorf("connection with UUID %s not found", uuid)
+}
+
+// SetWiredIPConfig switches a wired profile between DHCP, static IPv4
+// addressing and sharing this machine's connection. If the profile is active the change is reapplied in place,
+// falling back to re-activating it
+func (b *NetworkManagerBackend) SetWiredIPConfig(uuid string, config WiredIPConfig) error {
+	parsed, err := parseIPv4Config(config)
+	if err != nil {
+		return err
+	}
+
+	conn, err := b.findConnectionByUUID(uuid)
+	if err != nil {
+		return err
 	}
 
-	_, err = nm.ActivateConnection(targetConnection, dev, nil)
+	settings, err := conn.GetSettings()
 	if err != nil {
-		return fmt.Errorf("error activation connection: %w", err)
+		return fmt.Errorf("failed to get connection settings: %w", err)
+	}
+	if connType, _ := settings["connection"]["type"].(string); connType != "802-3-ethernet" {
+		return fmt.Errorf("connection %s is not a wired connection", uuid)
+	}
+
+	applyIPv4Settings(settings, parsed)
+	if err := conn.Update(settings); err != nil {
+		return fmt.Errorf("failed to update connection: %w", err)
+	}
+	log.Infof("[SetWiredIPConfig] Set %s to %s IPv4", uuid, parsed.Method)
+
+	activeUUIDs, _ := b.getActiveConnections()
+	if dev := b.wiredDeviceFor(uuid); activeUUIDs[uuid] && dev != nil {
+		if err := b.reapplyDevice(dev); err != nil {
+			log.Warnf("[SetWiredIPConfig] Reapply failed, re-activating: %v", err)
+			nm := b.nmConn.(gonetworkmanager.NetworkManager)
+			if _, err := nm.ActivateConnection(conn, dev, nil); err != nil {
+				return fmt.Errorf("failed to re-activate connection: %w", err)
+			}
+		}
 	}
 
 	b.updateEthernetState()
-	b.listEthernetConnections()
 	b.updatePrimaryConnection()
 
 	if b.onStateChange != nil {
@@ -253,6 +394,17 @@ func (b *NetworkManagerBackend) ActivateWiredConnection(uuid string) error {
 	return nil
 }
 
+// reapplyDevice applies the device's current settings-connection without
+// taking the link down. An empty settings map tells NetworkManager to use
+// the saved profile
+func (b *NetworkManagerBackend) reapplyDevice(dev gonetworkmanager.Device) error {
+	if b.dbusConn == nil {
+		return fmt.Errorf("no D-Bus connection")
+	}
+	obj := b.dbusConn.Object(dbusNMInterface, dev.GetPath())
+	return obj.Call(dbusNMDeviceInterface+".Reapply", 0, map[string]map[string]dbus.Variant{}, uint64(0), uint32(0)).Err
+}
+
 func (b *NetworkManagerBackend) listEthernetConnections() ([]WiredConnection, error) {
 	if b.ethernetDevice == nil {
 		return nil, fmt.Errorf("no ethernet device available")
@@ -280,6 +432,11 @@ func (b *NetworkManagerBackend) listEthernetConnections() ([]WiredConnection, er
 		return nil, fmt.Errorf("failed to get active wired connections: %w", err)
 	}
 
+	activeDevices := make(map[string]string)
+	for iface, uuid := range b.activeWiredUUIDs() {
+		activeDevices[uuid] = iface
+	}
+
 	currentUuid := ""
 	for _, connection := range connections {
 		path := connection.GetPath()
@@ -295,14 +452,25 @@ func (b *NetworkManagerBackend) listEthernetConnections() ([]WiredConnection, er
 		connUUID, _ := connectionSettings["uuid"].(string)
 
 		if connType == "802-3-ethernet" {
+			deviceMetered := gonetworkmanager.NmMeteredUnknown
+			if activeUUIDs[connUUID] {
+				if dev := b.wiredDeviceFor(connUUID); dev != nil {
+					deviceMetered = b.getDeviceMetered(dev)
+				}
+			}
 			wiredConfigs = append(wiredConfigs, WiredConnection{
 				Path:     path,
 				ID:       connID,
 				UUID:     connUUID,
 				Type:     connType,
 				IsActive: activeUUIDs[connUUID],
+				Metered:  isMetered(meteredModeFromSettings(settings), deviceMetered),
+				Shared:   settings["ipv4"]["method"] == IPMethodShared,
+				Device:   activeDevices[connUUID],
 			})
-			if activeUUIDs[connUUID] {
+			// Several profiles can be up at once; report the primary
+			// device's one
+			if activeUUIDs[connUUID] && (currentUuid == "" || activeDevices[connUUID] == b.primaryEthernetName()) {
 				currentUuid = connUUID
 			}
 		}
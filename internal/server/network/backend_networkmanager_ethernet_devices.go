@@ -0,0 +1,177 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/Wifx/gonetworkmanager/v2"
+	"github.com/godbus/dbus/v5"
+)
+
+// activeWiredUUIDs maps each wired device's interface to the UUID of the
+// profile active on it
+func (b *NetworkManagerBackend) activeWiredUUIDs() map[string]string {
+	active := make(map[string]string)
+	for _, dev := range b.ethernetDevices {
+		iface, err := dev.GetPropertyInterface()
+		if err != nil {
+			continue
+		}
+		activeConn, err := dev.GetPropertyActiveConnection()
+		if err != nil || activeConn == nil || activeConn.GetPath() == "/" {
+			continue
+		}
+		if uuid, err := activeConn.GetPropertyUUID(); err == nil {
+			active[iface] = uuid
+		}
+	}
+	return active
+}
+
+func (b *NetworkManagerBackend) ethernetDeviceByName(iface string) (gonetworkmanager.Device, error) {
+	for _, dev := range b.ethernetDevices {
+		if name, err := dev.GetPropertyInterface(); err == nil && name == iface {
+			return dev, nil
+		}
+	}
+	return nil, fmt.Errorf("no ethernet device named %q", iface)
+}
+
+// wiredDeviceFor returns the device a wired profile is active on, else the
+// primary ethernet device
+func (b *NetworkManagerBackend) wiredDeviceFor(uuid string) gonetworkmanager.Device {
+	for _, dev := range b.ethernetDevices {
+		if b.isDeviceRunning(dev, uuid) {
+			return dev
+		}
+	}
+	if b.ethernetDevice == nil {
+		return nil
+	}
+	return b.ethernetDevice.(gonetworkmanager.Device)
+}
+
+// wiredTargetDevice picks where ActivateWiredConnection puts a profile: the
+// requested interface, else the one the profile is bound to, else the
+// primary device
+func (b *NetworkManagerBackend) wiredTargetDevice(settings gonetworkmanager.ConnectionSettings, iface string) (gonetworkmanager.Device, error) {
+	if iface == "" {
+		iface, _ = settings["connection"]["interface-name"].(string)
+		if iface == "" {
+			if b.ethernetDevice == nil {
+				return nil, fmt.Errorf("no ethernet device available")
+			}
+			return b.ethernetDevice.(gonetworkmanager.Device), nil
+		}
+	} else if bound, _ := settings["connection"]["interface-name"].(string); bound != "" && bound != iface {
+		return nil, fmt.Errorf("connection is bound to %s", bound)
+	}
+	return b.ethernetDeviceByName(iface)
+}
+
+func (b *NetworkManagerBackend) updateEthernetDeviceList() {
+	active := b.activeWiredUUIDs()
+
+	devices := make([]EthernetDeviceInfo, 0, len(b.ethernetDevices))
+	for _, dev := range b.ethernetDevices {
+		iface, err := dev.GetPropertyInterface()
+		if err != nil {
+			continue
+		}
+		info := EthernetDeviceInfo{Name: iface, Connection: active[iface]}
+		if state, err := dev.GetPropertyState(); err == nil && state == gonetworkmanager.NmDeviceStateActivated {
+			info.Connected = true
+			info.IP = b.getDeviceIP(dev)
+		}
+		if wired, err := gonetworkmanager.NewDeviceWired(dev.GetPath()); err == nil {
+			info.Address, _ = wired.GetPropertyHwAddress()
+			if info.Connected {
+				info.Speed, _ = wired.GetPropertySpeed()
+			}
+		}
+		devices = append(devices, info)
+	}
+
+	b.stateMutex.Lock()
+	b.state.EthernetDevices = devices
+	b.stateMutex.Unlock()
+}
+
+// rescanEthernetDevices picks up docks and USB adapters that were plugged in
+// or removed, watching each for state changes
+func (b *NetworkManagerBackend) rescanEthernetDevices() {
+	nm := b.nmConn.(gonetworkmanager.NetworkManager)
+	all, err := nm.GetDevices()
+	if err != nil {
+		return
+	}
+
+	known := make(map[dbus.ObjectPath]bool, len(b.ethernetDevices))
+	for _, dev := range b.ethernetDevices {
+		known[dev.GetPath()] = true
+	}
+
+	var devices []gonetworkmanager.Device
+	for _, dev := range all {
+		if devType, err := dev.GetPropertyDeviceType(); err != nil || devType != gonetworkmanager.NmDeviceTypeEthernet {
+			continue
+		}
+		if managed, _ := dev.GetPropertyManaged(); !managed {
+			continue
+		}
+		devices = append(devices, dev)
+		if known[dev.GetPath()] {
+			delete(known, dev.GetPath())
+		} else {
+			b.watchDevice(dev.GetPath(), true)
+		}
+	}
+	for path := range known {
+		b.watchDevice(path, false)
+	}
+	b.ethernetDevices = devices
+
+	b.updateEthernetState()
+	b.listEthernetConnections()
+}
+
+// selectEthernetDevice makes the connected device the primary one, which
+// ethernetDevice and the single-device state fields describe
+func (b *NetworkManagerBackend) selectEthernetDevice() {
+	if dev := preferredDevice(b.ethernetDevices); dev != nil {
+		b.ethernetDevice = dev
+		return
+	}
+	b.ethernetDevice = nil
+	b.stateMutex.Lock()
+	b.state.EthernetDevice = ""
+	b.state.EthernetConnected = false
+	b.state.EthernetIP = ""
+	b.state.EthernetConnectionUuid = ""
+	b.state.WiredConnections = nil
+	b.state.EthernetDevices = nil
+	b.stateMutex.Unlock()
+}
+
+func (b *NetworkManagerBackend) watchDevice(path dbus.ObjectPath, watch bool) {
+	if b.dbusConn == nil {
+		return
+	}
+	opts := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(path),
+		dbus.WithMatchInterface(dbusPropsInterface),
+		dbus.WithMatchMember("PropertiesChanged"),
+	}
+	if watch {
+		_ = b.dbusConn.AddMatchSignal(opts...)
+		return
+	}
+	_ = b.dbusConn.RemoveMatchSignal(opts...)
+}
+
+func (b *NetworkManagerBackend) primaryEthernetName() string {
+	if b.ethernetDevice == nil {
+		return ""
+	}
+	iface, _ := b.ethernetDevice.(gonetworkmanager.Device).GetPropertyInterface()
+	return iface
+}
@@ -37,7 +37,7 @@ func TestNetworkManagerBackend_ConnectEthernet_NoDevice(t *testing.T) {
 	}
 
 	backend.ethernetDevice = nil
-	err = backend.ConnectEthernet()
+	err = backend.ConnectEthernet(nil)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no ethernet device available")
 }
@@ -61,7 +61,8 @@ func TestNetworkManagerBackend_ActivateWiredConnection_NoDevice(t *testing.T) {
 	}
 
 	backend.ethernetDevice = nil
-	err = backend.ActivateWiredConnection("test-uuid")
+	backend.ethernetDevices = nil
+	err = backend.ActivateWiredConnection("test-uuid", "")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no ethernet device available")
 }
@@ -76,7 +77,7 @@ func TestNetworkManagerBackend_ActivateWiredConnection_NotFound(t *testing.T) {
 		t.Skip("No ethernet device available")
 	}
 
-	err = backend.ActivateWiredConnection("non-existent-uuid-12345")
+	err = backend.ActivateWiredConnection("non-existent-uuid-12345", "")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not found")
 }
@@ -92,3 +93,16 @@ func TestNetworkManagerBackend_ListEthernetConnections_NoDevice(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no ethernet device available")
 }
+
+func TestWiredEAPSettings(t *testing.T) {
+	settings, err := wiredEAPSettings(ConnectionRequest{Username: "alice", EAPMethod: EAPMethodPEAP, Interactive: true})
+	assert.NoError(t, err)
+	assert.Equal(t, wiredEAPConnectionID, settings["connection"]["id"])
+	assert.Equal(t, "802-3-ethernet", settings["connection"]["type"])
+	assert.Equal(t, []string{EAPMethodPEAP}, settings["802-1x"]["eap"])
+	assert.Equal(t, "alice", settings["802-1x"]["identity"])
+	assert.NotContains(t, settings["802-1x"], "password", "left for the secret agent")
+
+	_, err = wiredEAPSettings(ConnectionRequest{EAPMethod: EAPMethodTLS, Username: "alice"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,133 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/Wifx/gonetworkmanager/v2"
+)
+
+func (b *NetworkManagerBackend) StartHotspot(ssid, password, band string) error {
+	if err := validateHotspot(ssid, password); err != nil {
+		return err
+	}
+	band, err := normalizeHotspotBand(band)
+	if err != nil {
+		return err
+	}
+	if b.wifiDevice == nil {
+		return fmt.Errorf("no WiFi device available")
+	}
+
+	nm := b.nmConn.(gonetworkmanager.NetworkManager)
+	dev := b.wifiDevice.(gonetworkmanager.Device)
+	iface, _ := dev.GetPropertyInterface()
+
+	s := b.settings
+	if s == nil {
+		s, err = gonetworkmanager.NewSettings()
+		if err != nil {
+			return fmt.Errorf("failed to get settings manager: %w", err)
+		}
+		b.settings = s
+	}
+	settingsMgr := s.(gonetworkmanager.Settings)
+
+	// Replace rather than update the previous profile so a changed
+	// password or band can't leave stale keys behind
+	if existing := b.findHotspotConnection(settingsMgr); existing != nil {
+		if err := existing.Delete(); err != nil {
+			return fmt.Errorf("failed to remove previous hotspot profile: %w", err)
+		}
+	}
+
+	conn, err := settingsMgr.AddConnection(hotspotSettings(ssid, password, band, iface))
+	if err != nil {
+		return fmt.Errorf("failed to add hotspot connection: %w", err)
+	}
+	if _, err := nm.ActivateConnection(conn, dev, nil); err != nil {
+		return fmt.Errorf("failed to start hotspot: %w", err)
+	}
+	log.Infof("[StartHotspot] Hotspot %s started on %s", ssid, iface)
+
+	b.updateWiFiState()
+	b.updatePrimaryConnection()
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	return nil
+}
+
+func (b *NetworkManagerBackend) StopHotspot() error {
+	if b.wifiDevice == nil {
+		return fmt.Errorf("no WiFi device available")
+	}
+
+	nm := b.nmConn.(gonetworkmanager.NetworkManager)
+	dev := b.wifiDevice.(gonetworkmanager.Device)
+
+	b.stateMutex.RLock()
+	active := b.state.Hotspot.Active
+	b.stateMutex.RUnlock()
+	if !active {
+		return fmt.Errorf("hotspot is not running")
+	}
+
+	activeConn, err := dev.GetPropertyActiveConnection()
+	if err != nil || activeConn == nil || activeConn.GetPath() == "/" {
+		return fmt.Errorf("hotspot is not running")
+	}
+	if err := nm.DeactivateConnection(activeConn); err != nil {
+		return fmt.Errorf("failed to stop hotspot: %w", err)
+	}
+
+	b.updateWiFiState()
+	b.updatePrimaryConnection()
+
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	return nil
+}
+
+func (b *NetworkManagerBackend) findHotspotConnection(settingsMgr gonetworkmanager.Settings) gonetworkmanager.Connection {
+	connections, err := settingsMgr.ListConnections()
+	if err != nil {
+		return nil
+	}
+
+	for _, conn := range connections {
+		connSettings, err := conn.GetSettings()
+		if err != nil {
+			continue
+		}
+		if id, _ := connSettings["connection"]["id"].(string); id != hotspotConnectionID {
+			continue
+		}
+		if hotspotFromSettings(connSettings, "").Active {
+			return conn
+		}
+	}
+	return nil
+}
+
+// readHotspotState checks whether the device's active connection is an
+// access point profile (ours or one made with nmcli)
+func (b *NetworkManagerBackend) readHotspotState(dev gonetworkmanager.Device, iface string) HotspotState {
+	activeConn, err := dev.GetPropertyActiveConnection()
+	if err != nil || activeConn == nil || activeConn.GetPath() == "/" {
+		return HotspotState{}
+	}
+	conn, err := activeConn.GetPropertyConnection()
+	if err != nil || conn == nil {
+		return HotspotState{}
+	}
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return HotspotState{}
+	}
+	return hotspotFromSettings(settings, iface)
+}
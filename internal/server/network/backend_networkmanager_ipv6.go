@@ -0,0 +1,69 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/Wifx/gonetworkmanager/v2"
+)
+
+func (b *NetworkManagerBackend) GetIPv6Config(uuidOrSSID string) (*IPv6Config, error) {
+	conn, err := b.findConnectionByUUID(uuidOrSSID)
+	if err != nil {
+		if conn, err = b.findConnection(uuidOrSSID); err != nil {
+			return nil, fmt.Errorf("no saved connection matches %q", uuidOrSSID)
+		}
+	}
+
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection settings: %w", err)
+	}
+
+	config := ipv6ConfigFromSettings(settings)
+	return &config, nil
+}
+
+// SetIPv6Config sets the IPv6 method and privacy of a saved wired or WiFi
+// profile, looked up by UUID or else by WiFi SSID
+func (b *NetworkManagerBackend) SetIPv6Config(uuidOrSSID string, config IPv6Config) error {
+	conn, err := b.findConnectionByUUID(uuidOrSSID)
+	if err != nil {
+		if conn, err = b.findConnection(uuidOrSSID); err != nil {
+			return fmt.Errorf("no saved connection matches %q", uuidOrSSID)
+		}
+	}
+
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return fmt.Errorf("failed to get connection settings: %w", err)
+	}
+	connType, _ := settings["connection"]["type"].(string)
+	if connType != "802-3-ethernet" && connType != "802-11-wireless" {
+		return fmt.Errorf("IPv6 settings can only be changed on wired and WiFi connections")
+	}
+	uuid, _ := settings["connection"]["uuid"].(string)
+
+	if err := applyIPv6Config(settings, config); err != nil {
+		return err
+	}
+	dropDeprecatedAddressKeys(settings)
+	if err := conn.Update(settings); err != nil {
+		return fmt.Errorf("failed to update connection: %w", err)
+	}
+	applied := ipv6ConfigFromSettings(settings)
+	log.Infof("[SetIPv6Config] Set IPv6 method=%s privacy=%s addr-gen-mode=%s on %s", applied.Method, applied.Privacy, applied.AddrGenMode, uuid)
+
+	dev := b.deviceForConnection(settings)
+	if dev == nil || !b.isDeviceRunning(dev, uuid) {
+		return nil
+	}
+	if err := b.reapplyDevice(dev); err != nil {
+		log.Warnf("[SetIPv6Config] Reapply failed, re-activating: %v", err)
+		nm := b.nmConn.(gonetworkmanager.NetworkManager)
+		if _, err := nm.ActivateConnection(conn, dev, nil); err != nil {
+			return fmt.Errorf("failed to re-activate connection: %w", err)
+		}
+	}
+	return nil
+}
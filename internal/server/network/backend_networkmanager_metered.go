@@ -0,0 +1,116 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/Wifx/gonetworkmanager/v2"
+)
+
+func (b *NetworkManagerBackend) GetMetered(uuidOrSSID string) (*MeteredInfo, error) {
+	conn, err := b.findConnectionByUUID(uuidOrSSID)
+	if err != nil {
+		if conn, err = b.findConnection(uuidOrSSID); err != nil {
+			return nil, fmt.Errorf("no saved connection matches %q", uuidOrSSID)
+		}
+	}
+
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection settings: %w", err)
+	}
+
+	info := &MeteredInfo{Mode: meteredModeFromSettings(settings)}
+	uuid, _ := settings["connection"]["uuid"].(string)
+	device := gonetworkmanager.NmMeteredUnknown
+	if dev := b.deviceForConnection(settings); dev != nil && b.isDeviceRunning(dev, uuid) {
+		device = b.getDeviceMetered(dev)
+	}
+	info.Metered = isMetered(info.Mode, device)
+	return info, nil
+}
+
+// SetMetered sets connection.metered on a saved profile, looked up by UUID or
+// else by WiFi SSID
+func (b *NetworkManagerBackend) SetMetered(uuidOrSSID string, mode string) error {
+	value, err := parseMeteredMode(mode)
+	if err != nil {
+		return err
+	}
+
+	conn, err := b.findConnectionByUUID(uuidOrSSID)
+	if err != nil {
+		if conn, err = b.findConnection(uuidOrSSID); err != nil {
+			return fmt.Errorf("no saved connection matches %q", uuidOrSSID)
+		}
+	}
+
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return fmt.Errorf("failed to get connection settings: %w", err)
+	}
+	connType, _ := settings["connection"]["type"].(string)
+	if connType != "802-3-ethernet" && connType != "802-11-wireless" {
+		return fmt.Errorf("metered can only be set on wired and WiFi connections")
+	}
+	uuid, _ := settings["connection"]["uuid"].(string)
+
+	settings["connection"]["metered"] = value
+	dropDeprecatedAddressKeys(settings)
+	if err := conn.Update(settings); err != nil {
+		return fmt.Errorf("failed to update connection: %w", err)
+	}
+	log.Infof("[SetMetered] Set metered=%s on %s", meteredModes[value], uuid)
+
+	dev := b.deviceForConnection(settings)
+	if dev == nil || !b.isDeviceRunning(dev, uuid) {
+		b.refreshMeteredState()
+		return nil
+	}
+	if err := b.reapplyDevice(dev); err != nil {
+		log.Warnf("[SetMetered] Reapply failed, re-activating: %v", err)
+		nm := b.nmConn.(gonetworkmanager.NetworkManager)
+		if _, err := nm.ActivateConnection(conn, dev, nil); err != nil {
+			return fmt.Errorf("failed to re-activate connection: %w", err)
+		}
+	}
+	b.refreshMeteredState()
+	return nil
+}
+
+func (b *NetworkManagerBackend) deviceForConnection(settings gonetworkmanager.ConnectionSettings) gonetworkmanager.Device {
+	connType, _ := settings["connection"]["type"].(string)
+	switch {
+	case connType == "802-3-ethernet":
+		uuid, _ := settings["connection"]["uuid"].(string)
+		return b.wiredDeviceFor(uuid)
+	case connType == "802-11-wireless" && b.wifiDevice != nil:
+		return b.wifiDevice.(gonetworkmanager.Device)
+	}
+	return nil
+}
+
+func (b *NetworkManagerBackend) getDeviceMetered(dev gonetworkmanager.Device) gonetworkmanager.NmMetered {
+	obj := b.dbusConn.Object("org.freedesktop.NetworkManager", dev.GetPath())
+	variant, err := obj.GetProperty(dbusNMDeviceInterface + ".Metered")
+	if err != nil {
+		return gonetworkmanager.NmMeteredUnknown
+	}
+	value, _ := variant.Value().(uint32)
+	return gonetworkmanager.NmMetered(value)
+}
+
+// refreshMeteredState updates the metered flags in the state after a profile
+// changed; the primary connection's flag follows NetworkManager's own
+func (b *NetworkManagerBackend) refreshMeteredState() {
+	if b.wifiDevice != nil {
+		b.updateWiFiNetworks()
+	}
+	if b.ethernetDevice != nil {
+		b.listEthernetConnections()
+	}
+	b.updatePrimaryConnection()
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+}
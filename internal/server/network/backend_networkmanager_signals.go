@@ -1,12 +1,13 @@
 package network
 
 import (
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
 	"github.com/Wifx/gonetworkmanager/v2"
 	"github.com/godbus/dbus/v5"
 )
 
 func (b *NetworkManagerBackend) startSignalPump() error {
-	conn, err := dbus.ConnectSystemBus()
+	conn, err := dbus.ConnectSystemBus(budget.DBusOptions("network")...)
 	if err != nil {
 		return err
 	}
@@ -79,8 +80,7 @@ func (b *NetworkManagerBackend) startSignalPump() error {
 		}
 	}
 
-	if b.ethernetDevice != nil {
-		dev := b.ethernetDevice.(gonetworkmanager.Device)
+	for i, dev := range b.ethernetDevices {
 		if err := conn.AddMatchSignal(
 			dbus.WithMatchObjectPath(dbus.ObjectPath(dev.GetPath())),
 			dbus.WithMatchInterface(dbusPropsInterface),
@@ -99,6 +99,13 @@ func (b *NetworkManagerBackend) startSignalPump() error {
 					dbus.WithMatchMember("PropertiesChanged"),
 				)
 			}
+			for _, added := range b.ethernetDevices[:i] {
+				_ = conn.RemoveMatchSignal(
+					dbus.WithMatchObjectPath(dbus.ObjectPath(added.GetPath())),
+					dbus.WithMatchInterface(dbusPropsInterface),
+					dbus.WithMatchMember("PropertiesChanged"),
+				)
+			}
 			conn.RemoveSignal(signals)
 			conn.Close()
 			return err
@@ -146,8 +153,7 @@ func (b *NetworkManagerBackend) stopSignalPump() {
 		)
 	}
 
-	if b.ethernetDevice != nil {
-		dev := b.ethernetDevice.(gonetworkmanager.Device)
+	for _, dev := range b.ethernetDevices {
 		_ = b.dbusConn.RemoveMatchSignal(
 			dbus.WithMatchObjectPath(dbus.ObjectPath(dev.GetPath())),
 			dbus.WithMatchInterface(dbusPropsInterface),
@@ -211,6 +217,10 @@ func (b *NetworkManagerBackend) handleNetworkManagerChange(changes map[string]db
 		switch key {
 		case "PrimaryConnection", "State", "ActiveConnections":
 			needsUpdate = true
+		case "Devices":
+			b.rescanWiFiDevices()
+			b.rescanEthernetDevices()
+			needsUpdate = true
 		case "WirelessEnabled":
 			nm := b.nmConn.(gonetworkmanager.NetworkManager)
 			if enabled, err := nm.GetPropertyWirelessEnabled(); err == nil {
@@ -231,6 +241,7 @@ func (b *NetworkManagerBackend) handleNetworkManagerChange(changes map[string]db
 			b.updateWiFiState()
 		}
 		if _, exists := changes["ActiveConnections"]; exists {
+			b.reconcileConnecting()
 			b.updateVPNConnectionState()
 			b.ListActiveVPN()
 		}
@@ -261,6 +272,7 @@ func (b *NetworkManagerBackend) handleDeviceChange(changes map[string]dbus.Varia
 		b.updateWiFiState()
 		if stateChanged {
 			b.updatePrimaryConnection()
+			b.updateWiFiDeviceList()
 		}
 		if b.onStateChange != nil {
 			b.onStateChange()
@@ -11,6 +11,12 @@ import (
 func (b *NetworkManagerBackend) updatePrimaryConnection() error {
 	nm := b.nmConn.(gonetworkmanager.NetworkManager)
 
+	if metered, err := nm.GetPropertyMetered(); err == nil {
+		b.stateMutex.Lock()
+		b.state.Metered = isMetered(MeteredAuto, metered)
+		b.stateMutex.Unlock()
+	}
+
 	activeConns, err := nm.GetPropertyActiveConnections()
 	if err != nil {
 		return err
@@ -72,9 +78,11 @@ func (b *NetworkManagerBackend) updatePrimaryConnection() error {
 }
 
 func (b *NetworkManagerBackend) updateEthernetState() error {
+	b.selectEthernetDevice()
 	if b.ethernetDevice == nil {
 		return nil
 	}
+	defer b.updateEthernetDeviceList()
 
 	dev := b.ethernetDevice.(gonetworkmanager.Device)
 
@@ -182,6 +190,16 @@ func (b *NetworkManagerBackend) updateWiFiState() error {
 		ip = b.getDeviceIP(dev)
 	}
 
+	// An access point isn't a client connection; report it as the hotspot
+	// instead of as the network we're connected to
+	var hotspot HotspotState
+	if connected {
+		if hotspot = b.readHotspotState(dev, iface); hotspot.Active {
+			connected = false
+			ip, ssid, bssid, signal = "", "", "", 0
+		}
+	}
+
 	b.stateMutex.RLock()
 	wasConnecting := b.state.IsConnecting
 	connectingSSID := b.state.ConnectingSSID
@@ -209,6 +227,8 @@ func (b *NetworkManagerBackend) updateWiFiState() error {
 			}
 			b.failedMutex.RUnlock()
 		}
+
+		b.removePendingConnection(connectingSSID)
 	}
 
 	b.stateMutex.Lock()
@@ -220,6 +240,7 @@ func (b *NetworkManagerBackend) updateWiFiState() error {
 	if wasConnecting && connectingSSID != "" {
 		if connected && ssid == connectingSSID {
 			log.Infof("[updateWiFiState] Connection successful: %s", ssid)
+			b.clearPendingConnection()
 			b.state.IsConnecting = false
 			b.state.ConnectingSSID = ""
 			b.state.LastError = ""
@@ -242,6 +263,7 @@ func (b *NetworkManagerBackend) updateWiFiState() error {
 	b.state.WiFiSSID = ssid
 	b.state.WiFiBSSID = bssid
 	b.state.WiFiSignal = signal
+	b.state.Hotspot = hotspot
 
 	return nil
 }
@@ -0,0 +1,119 @@
+package network
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/Wifx/gonetworkmanager/v2"
+)
+
+var nmcliAddedRegex = regexp.MustCompile(`\(([0-9a-fA-F-]{36})\)`)
+
+// ImportOpenVPNProfile adds a .ovpn file as a NetworkManager VPN profile.
+// nmcli's importer is used when available since it knows every option the
+// plugin does; otherwise the file is converted here
+func (b *NetworkManagerBackend) ImportOpenVPNProfile(path, name string) (*VPNProfile, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if name == "" {
+		name = openVPNProfileName(path)
+	}
+
+	profiles, err := b.ListVPNProfiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range profiles {
+		if p.Name == name {
+			return nil, fmt.Errorf("a VPN profile named %q already exists", name)
+		}
+	}
+
+	var uuid string
+	if _, err := exec.LookPath("nmcli"); err == nil {
+		uuid, err = importWithNmcli(path, name)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		uuid, err = b.addOpenVPNConnection(string(content), path, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	log.Infof("[ImportOpenVPNProfile] Imported %s as %s (%s)", path, name, uuid)
+
+	b.ListVPNProfiles()
+	if b.onStateChange != nil {
+		b.onStateChange()
+	}
+
+	return &VPNProfile{Name: name, UUID: uuid, Type: "vpn", ServiceType: openVPNServiceType}, nil
+}
+
+func importWithNmcli(path, name string) (string, error) {
+	output, err := exec.Command("nmcli", "connection", "import", "type", "openvpn", "file", path).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("nmcli import failed: %s", strings.TrimSpace(string(output)))
+	}
+	match := nmcliAddedRegex.FindStringSubmatch(string(output))
+	if match == nil {
+		return "", fmt.Errorf("unexpected nmcli output: %s", strings.TrimSpace(string(output)))
+	}
+	uuid := match[1]
+
+	if name != openVPNProfileName(path) {
+		if output, err := exec.Command("nmcli", "connection", "modify", uuid, "connection.id", name).CombinedOutput(); err != nil {
+			log.Warnf("[ImportOpenVPNProfile] Failed to rename %s: %s", uuid, strings.TrimSpace(string(output)))
+		}
+	}
+	return uuid, nil
+}
+
+func (b *NetworkManagerBackend) addOpenVPNConnection(content, path, name string) (string, error) {
+	profile, err := parseOpenVPNConfig(content, filepath.Dir(path))
+	if err != nil {
+		return "", err
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if err := profile.writeInlineFiles(filepath.Join(home, ".cert", "nm-openvpn"), name); err != nil {
+		return "", fmt.Errorf("failed to save inline certificates: %w", err)
+	}
+
+	s := b.settings
+	if s == nil {
+		s, err = gonetworkmanager.NewSettings()
+		if err != nil {
+			return "", fmt.Errorf("failed to get settings: %w", err)
+		}
+		b.settings = s
+	}
+
+	settingsMgr := s.(gonetworkmanager.Settings)
+	conn, err := settingsMgr.AddConnection(profile.settings(name))
+	if err != nil {
+		return "", fmt.Errorf("failed to add connection: %w", err)
+	}
+
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return "", fmt.Errorf("failed to read new connection: %w", err)
+	}
+	uuid, _ := settings["connection"]["uuid"].(string)
+	return uuid, nil
+}
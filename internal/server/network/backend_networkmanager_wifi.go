@@ -91,6 +91,7 @@ func (b *NetworkManagerBackend) GetWiFiNetworkDetails(ssid string) (*NetworkInfo
 	}
 
 	savedSSIDs := make(map[string]bool)
+	var dnsConfig *DNSConfig
 	for _, conn := range connections {
 		connSettings, err := conn.GetSettings()
 		if err != nil {
@@ -100,9 +101,16 @@ func (b *NetworkManagerBackend) GetWiFiNetworkDetails(ssid string) (*NetworkInfo
 		if connMeta, ok := connSettings["connection"]; ok {
 			if connType, ok := connMeta["type"].(string); ok && connType == "802-11-wireless" {
 				if wifiSettings, ok := connSettings["802-11-wireless"]; ok {
+					if mode, _ := wifiSettings["mode"].(string); mode == "ap" {
+						continue
+					}
 					if ssidBytes, ok := wifiSettings["ssid"].([]byte); ok {
 						savedSSID := string(ssidBytes)
 						savedSSIDs[savedSSID] = true
+						if savedSSID == ssid && dnsConfig == nil {
+							cfg := dnsConfigFromSettings(connSettings)
+							dnsConfig = &cfg
+						}
 					}
 				}
 			}
@@ -131,12 +139,9 @@ func (b *NetworkManagerBackend) GetWiFiNetworkDetails(ssid string) (*NetworkInfo
 		bssid, _ := ap.GetPropertyHWAddress()
 		mode, _ := ap.GetPropertyMode()
 
-		secured := flags != uint32(gonetworkmanager.Nm80211APFlagsNone) ||
-			wpaFlags != uint32(gonetworkmanager.Nm80211APSecNone) ||
-			rsnFlags != uint32(gonetworkmanager.Nm80211APSecNone)
-
-		enterprise := (rsnFlags&uint32(gonetworkmanager.Nm80211APSecKeyMgmt8021X) != 0) ||
-			(wpaFlags&uint32(gonetworkmanager.Nm80211APSecKeyMgmt8021X) != 0)
+		security := detectWiFiSecurity(flags, wpaFlags, rsnFlags)
+		secured := securityNeedsPassword(security)
+		enterprise := security == SecurityEAP
 
 		var modeStr string
 		switch mode {
@@ -157,6 +162,7 @@ func (b *NetworkManagerBackend) GetWiFiNetworkDetails(ssid string) (*NetworkInfo
 			BSSID:      bssid,
 			Signal:     strength,
 			Secured:    secured,
+			Security:   security,
 			Enterprise: enterprise,
 			Connected:  ssid == currentSSID && bssid == currentBSSID,
 			Saved:      savedSSIDs[ssid],
@@ -186,6 +192,7 @@ func (b *NetworkManagerBackend) GetWiFiNetworkDetails(ssid string) (*NetworkInfo
 	return &NetworkInfoResponse{
 		SSID:  ssid,
 		Bands: bands,
+		DNS:   dnsConfig,
 	}, nil
 }
 
@@ -198,7 +205,11 @@ func (b *NetworkManagerBackend) ConnectWiFi(req ConnectionRequest) error {
 	alreadyConnected := b.state.WiFiConnected && b.state.WiFiSSID == req.SSID
 	b.stateMutex.RUnlock()
 
-	if alreadyConnected && !req.Interactive {
+	if err := validateAPPin(req); err != nil {
+		return err
+	}
+
+	if alreadyConnected && !req.Interactive && !wantsAPPin(req) {
 		return nil
 	}
 
@@ -208,6 +219,8 @@ func (b *NetworkManagerBackend) ConnectWiFi(req ConnectionRequest) error {
 	b.state.LastError = ""
 	b.stateMutex.Unlock()
 
+	att := b.startConnectAttempt(req)
+
 	if b.onStateChange != nil {
 		b.onStateChange()
 	}
@@ -217,10 +230,17 @@ func (b *NetworkManagerBackend) ConnectWiFi(req ConnectionRequest) error {
 	existingConn, err := b.findConnection(req.SSID)
 	if err == nil && existingConn != nil {
 		dev := b.wifiDevice.(gonetworkmanager.Device)
+		b.setPendingConnection(existingConn, req.SSID, false)
 
-		_, err := nm.ActivateConnection(existingConn, dev, nil)
+		var err error
+		if wantsAPPin(req) {
+			err = b.activatePinned(existingConn, req)
+		} else {
+			_, err = nm.ActivateConnection(existingConn, dev, nil)
+		}
 		if err != nil {
 			log.Warnf("[ConnectWiFi] Failed to activate existing connection: %v", err)
+			b.endConnectAttempt(att)
 			b.stateMutex.Lock()
 			b.state.IsConnecting = false
 			b.state.ConnectingSSID = ""
@@ -232,11 +252,13 @@ func (b *NetworkManagerBackend) ConnectWiFi(req ConnectionRequest) error {
 			return fmt.Errorf("failed to activate connection: %w", err)
 		}
 
+		b.markAttemptRequested(att)
 		return nil
 	}
 
 	if err := b.createAndConnectWiFi(req); err != nil {
 		log.Warnf("[ConnectWiFi] Failed to create and connect: %v", err)
+		b.endConnectAttempt(att)
 		b.stateMutex.Lock()
 		b.state.IsConnecting = false
 		b.state.ConnectingSSID = ""
@@ -248,6 +270,7 @@ func (b *NetworkManagerBackend) ConnectWiFi(req ConnectionRequest) error {
 		return err
 	}
 
+	b.markAttemptRequested(att)
 	return nil
 }
 
@@ -309,6 +332,38 @@ func (b *NetworkManagerBackend) ForgetWiFiNetwork(ssid string) error {
 	return nil
 }
 
+// ListSavedWiFiNetworks returns the saved WiFi profiles, in range or not,
+// most recently used first
+func (b *NetworkManagerBackend) ListSavedWiFiNetworks() ([]SavedWiFiNetwork, error) {
+	s := b.settings
+	if s == nil {
+		var err error
+		s, err = gonetworkmanager.NewSettings()
+		if err != nil {
+			return nil, err
+		}
+		b.settings = s
+	}
+
+	connections, err := s.(gonetworkmanager.Settings).ListConnections()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connections: %w", err)
+	}
+
+	networks := []SavedWiFiNetwork{}
+	for _, conn := range connections {
+		settings, err := conn.GetSettings()
+		if err != nil {
+			continue
+		}
+		if saved, ok := savedWiFiFromSettings(settings); ok {
+			networks = append(networks, saved)
+		}
+	}
+	sortSavedWiFi(networks)
+	return networks, nil
+}
+
 func (b *NetworkManagerBackend) IsConnectingTo(ssid string) bool {
 	b.stateMutex.RLock()
 	defer b.stateMutex.RUnlock()
@@ -347,6 +402,7 @@ func (b *NetworkManagerBackend) updateWiFiNetworks() ([]WiFiNetwork, error) {
 	}
 
 	savedSSIDs := make(map[string]bool)
+	meteredModes := make(map[string]string)
 	for _, conn := range connections {
 		connSettings, err := conn.GetSettings()
 		if err != nil {
@@ -356,9 +412,13 @@ func (b *NetworkManagerBackend) updateWiFiNetworks() ([]WiFiNetwork, error) {
 		if connMeta, ok := connSettings["connection"]; ok {
 			if connType, ok := connMeta["type"].(string); ok && connType == "802-11-wireless" {
 				if wifiSettings, ok := connSettings["802-11-wireless"]; ok {
+					if mode, _ := wifiSettings["mode"].(string); mode == "ap" {
+						continue
+					}
 					if ssidBytes, ok := wifiSettings["ssid"].([]byte); ok {
 						ssid := string(ssidBytes)
 						savedSSIDs[ssid] = true
+						meteredModes[ssid] = meteredModeFromSettings(connSettings)
 					}
 				}
 			}
@@ -369,6 +429,11 @@ func (b *NetworkManagerBackend) updateWiFiNetworks() ([]WiFiNetwork, error) {
 	currentSSID := b.state.WiFiSSID
 	b.stateMutex.RUnlock()
 
+	currentMetered := gonetworkmanager.NmMeteredUnknown
+	if currentSSID != "" {
+		currentMetered = b.getDeviceMetered(b.wifiDevice.(gonetworkmanager.Device))
+	}
+
 	seenSSIDs := make(map[string]*WiFiNetwork)
 	networks := []WiFiNetwork{}
 
@@ -399,12 +464,9 @@ func (b *NetworkManagerBackend) updateWiFiNetworks() ([]WiFiNetwork, error) {
 		bssid, _ := ap.GetPropertyHWAddress()
 		mode, _ := ap.GetPropertyMode()
 
-		secured := flags != uint32(gonetworkmanager.Nm80211APFlagsNone) ||
-			wpaFlags != uint32(gonetworkmanager.Nm80211APSecNone) ||
-			rsnFlags != uint32(gonetworkmanager.Nm80211APSecNone)
-
-		enterprise := (rsnFlags&uint32(gonetworkmanager.Nm80211APSecKeyMgmt8021X) != 0) ||
-			(wpaFlags&uint32(gonetworkmanager.Nm80211APSecKeyMgmt8021X) != 0)
+		security := detectWiFiSecurity(flags, wpaFlags, rsnFlags)
+		secured := securityNeedsPassword(security)
+		enterprise := security == SecurityEAP
 
 		var modeStr string
 		switch mode {
@@ -420,14 +482,21 @@ func (b *NetworkManagerBackend) updateWiFiNetworks() ([]WiFiNetwork, error) {
 
 		channel := frequencyToChannel(freq)
 
+		deviceMetered := gonetworkmanager.NmMeteredUnknown
+		if ssid == currentSSID {
+			deviceMetered = currentMetered
+		}
+
 		network := WiFiNetwork{
 			SSID:       ssid,
 			BSSID:      bssid,
 			Signal:     strength,
 			Secured:    secured,
+			Security:   security,
 			Enterprise: enterprise,
 			Connected:  ssid == currentSSID,
 			Saved:      savedSSIDs[ssid],
+			Metered:    savedSSIDs[ssid] && isMetered(meteredModes[ssid], deviceMetered),
 			Frequency:  freq,
 			Mode:       modeStr,
 			Rate:       maxBitrate / 1000,
@@ -507,34 +576,39 @@ func (b *NetworkManagerBackend) createAndConnectWiFi(req ConnectionRequest) erro
 	}
 
 	var targetAP gonetworkmanager.AccessPoint
-	for _, ap := range apPaths {
-		ssid, err := ap.GetPropertySSID()
-		if err != nil || ssid != req.SSID {
-			continue
+	var pinnedBSSID string
+	if wantsAPPin(req) && req.Band != BandAny {
+		targetAP, pinnedBSSID, err = b.pinnedAccessPoint(req)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, ap := range apPaths {
+			ssid, err := ap.GetPropertySSID()
+			if err != nil || ssid != req.SSID {
+				continue
+			}
+			targetAP = ap
+			break
 		}
-		targetAP = ap
-		break
 	}
 
-	if targetAP == nil {
+	var flags, wpaFlags, rsnFlags uint32
+	var security string
+	switch {
+	case targetAP != nil:
+		flags, _ = targetAP.GetPropertyFlags()
+		wpaFlags, _ = targetAP.GetPropertyWPAFlags()
+		rsnFlags, _ = targetAP.GetPropertyRSNFlags()
+		security = detectWiFiSecurity(flags, wpaFlags, rsnFlags)
+	case req.Hidden:
+		security = hiddenNetworkSecurity(req)
+		log.Infof("[createAndConnectWiFi] Joining hidden network %s as %s", req.SSID, security)
+	default:
 		return fmt.Errorf("access point not found: %s", req.SSID)
 	}
-
-	flags, _ := targetAP.GetPropertyFlags()
-	wpaFlags, _ := targetAP.GetPropertyWPAFlags()
-	rsnFlags, _ := targetAP.GetPropertyRSNFlags()
-
-	const KeyMgmt8021x = uint32(512)
-	const KeyMgmtPsk = uint32(256)
-	const KeyMgmtSae = uint32(1024)
-
-	isEnterprise := (wpaFlags&KeyMgmt8021x) != 0 || (rsnFlags&KeyMgmt8021x) != 0
-	isPsk := (wpaFlags&KeyMgmtPsk) != 0 || (rsnFlags&KeyMgmtPsk) != 0
-	isSae := (wpaFlags&KeyMgmtSae) != 0 || (rsnFlags&KeyMgmtSae) != 0
-
-	secured := flags != uint32(gonetworkmanager.Nm80211APFlagsNone) ||
-		wpaFlags != uint32(gonetworkmanager.Nm80211APSecNone) ||
-		rsnFlags != uint32(gonetworkmanager.Nm80211APSecNone)
+	isEnterprise := security == SecurityEAP
+	secured := security != SecurityOpen
 
 	if isEnterprise {
 		log.Infof("[createAndConnectWiFi] Enterprise network detected (802.1x) - SSID: %s, interactive: %v",
@@ -552,73 +626,47 @@ func (b *NetworkManagerBackend) createAndConnectWiFi(req ConnectionRequest) erro
 	settings["ipv4"] = map[string]interface{}{"method": "auto"}
 	settings["ipv6"] = map[string]interface{}{"method": "auto"}
 
-	if secured {
-		settings["802-11-wireless"] = map[string]interface{}{
-			"ssid":     []byte(req.SSID),
-			"mode":     "infrastructure",
-			"security": "802-11-wireless-security",
+	wireless := map[string]interface{}{
+		"ssid": []byte(req.SSID),
+		"mode": "infrastructure",
+	}
+	if req.Hidden {
+		wireless["hidden"] = true
+	}
+	if pinnedBSSID != "" {
+		if err := applyAPPin(wireless, req, pinnedBSSID); err != nil {
+			return err
 		}
+	}
+	settings["802-11-wireless"] = wireless
+
+	if secured {
+		wireless["security"] = "802-11-wireless-security"
 
 		switch {
-		case isEnterprise || req.Username != "":
+		case isEnterprise || req.Username != "" || req.EAPMethod != "":
 			settings["802-11-wireless-security"] = map[string]interface{}{
 				"key-mgmt": "wpa-eap",
 			}
 
-			x := map[string]interface{}{
-				"eap":             []string{"peap"},
-				"phase2-auth":     "mschapv2",
-				"system-ca-certs": false,
-				"password-flags":  uint32(0),
-			}
-
-			if req.Username != "" {
-				x["identity"] = req.Username
-			}
-			if req.Password != "" {
-				x["password"] = req.Password
-			}
-
-			if req.AnonymousIdentity != "" {
-				x["anonymous-identity"] = req.AnonymousIdentity
-			}
-			if req.DomainSuffixMatch != "" {
-				x["domain-suffix-match"] = req.DomainSuffixMatch
+			x, err := eapSettings(req)
+			if err != nil {
+				return err
 			}
-
 			settings["802-1x"] = x
 
-			log.Infof("[createAndConnectWiFi] WPA-EAP settings: eap=peap, phase2-auth=mschapv2, identity=%s, interactive=%v, system-ca-certs=%v, domain-suffix-match=%q",
-				req.Username, req.Interactive, x["system-ca-certs"], req.DomainSuffixMatch)
-
-		case isPsk:
-			sec := map[string]interface{}{
-				"key-mgmt":  "wpa-psk",
-				"psk-flags": uint32(0),
-			}
-			if !req.Interactive {
-				sec["psk"] = req.Password
-			}
-			settings["802-11-wireless-security"] = sec
+			log.Infof("[createAndConnectWiFi] WPA-EAP settings: eap=%v, phase2-auth=%v, identity=%s, interactive=%v, ca-cert=%v, domain-suffix-match=%q",
+				x["eap"], x["phase2-auth"], req.Username, req.Interactive, req.CACert != "", req.DomainSuffixMatch)
 
-		case isSae:
-			sec := map[string]interface{}{
-				"key-mgmt":  "sae",
-				"pmf":       int32(3),
-				"psk-flags": uint32(0),
+		default:
+			sec := wifiSecuritySettings(security, req.Password, req.Interactive)
+			if sec == nil {
+				return fmt.Errorf("secured network but not SAE/PSK/OWE/802.1X (rsn=0x%x wpa=0x%x)", rsnFlags, wpaFlags)
 			}
-			if !req.Interactive {
-				sec["psk"] = req.Password
+			if security == SecuritySAETransition {
+				log.Infof("[createAndConnectWiFi] WPA2/WPA3 transition network %s, using %s", req.SSID, sec["key-mgmt"])
 			}
 			settings["802-11-wireless-security"] = sec
-
-		default:
-			return fmt.Errorf("secured network but not SAE/PSK/802.1X (rsn=0x%x wpa=0x%x)", rsnFlags, wpaFlags)
-		}
-	} else {
-		settings["802-11-wireless"] = map[string]interface{}{
-			"ssid": []byte(req.SSID),
-			"mode": "infrastructure",
 		}
 	}
 
@@ -643,19 +691,102 @@ func (b *NetworkManagerBackend) createAndConnectWiFi(req ConnectionRequest) erro
 			log.Infof("[createAndConnectWiFi] Enterprise connection added, activating (secret agent will be called)")
 		}
 
-		_, err = nm.ActivateWirelessConnection(conn, dev, targetAP)
+		if targetAP != nil {
+			_, err = nm.ActivateWirelessConnection(conn, dev, targetAP)
+		} else {
+			_, err = nm.ActivateConnection(conn, dev, nil)
+		}
 		if err != nil {
 			return fmt.Errorf("failed to activate connection: %w", err)
 		}
 
 		log.Infof("[createAndConnectWiFi] Connection activation initiated, waiting for NetworkManager state changes...")
 	} else {
-		_, err = nm.AddAndActivateWirelessConnection(settings, dev, targetAP)
+		var activeConn gonetworkmanager.ActiveConnection
+		var err error
+		if targetAP != nil {
+			activeConn, err = nm.AddAndActivateWirelessConnection(settings, dev, targetAP)
+		} else {
+			activeConn, err = nm.AddAndActivateConnection(settings, dev)
+		}
 		if err != nil {
 			return fmt.Errorf("failed to connect: %w", err)
 		}
+		if activeConn != nil {
+			if conn, err := activeConn.GetPropertyConnection(); err == nil && conn != nil {
+				b.setPendingConnection(conn, req.SSID, true)
+			}
+		}
 		log.Infof("[createAndConnectWiFi] Connection activation initiated, waiting for NetworkManager state changes...")
 	}
 
 	return nil
 }
+
+// setPendingConnection records the profile an attempt to join ssid uses.
+// created marks one this attempt added, as opposed to a saved profile
+func (b *NetworkManagerBackend) setPendingConnection(conn gonetworkmanager.Connection, ssid string, created bool) {
+	b.pendingMutex.Lock()
+	b.pendingConn = conn
+	b.pendingConnSSID = ssid
+	b.pendingConnCreated = created
+	b.pendingMutex.Unlock()
+}
+
+func (b *NetworkManagerBackend) clearPendingConnection() {
+	b.pendingMutex.Lock()
+	b.pendingConn = nil
+	b.pendingConnSSID = ""
+	b.pendingConnCreated = false
+	b.pendingMutex.Unlock()
+}
+
+// removesPendingProfile decides whether a failed attempt to join failedSSID
+// deletes the pending profile. Only a profile the first connect created goes;
+// a saved one that failed to reconnect (wrong password after a router
+// change, AP out of range) keeps its settings and secrets
+func removesPendingProfile(pendingSSID string, created bool, failedSSID string) bool {
+	return created && failedSSID != "" && pendingSSID == failedSSID
+}
+
+func (b *NetworkManagerBackend) removePendingConnection(ssid string) {
+	b.pendingMutex.Lock()
+	pending := b.pendingConn
+	pendingSSID := b.pendingConnSSID
+	created := b.pendingConnCreated
+	b.pendingConn = nil
+	b.pendingConnSSID = ""
+	b.pendingConnCreated = false
+	b.pendingMutex.Unlock()
+
+	if pending == nil || !removesPendingProfile(pendingSSID, created, ssid) {
+		return
+	}
+
+	conn := pending.(gonetworkmanager.Connection)
+	if err := conn.Delete(); err != nil {
+		log.Warnf("[removePendingConnection] Failed to delete profile for %s, disabling autoconnect: %v", ssid, err)
+		b.disableAutoconnect(conn)
+		return
+	}
+
+	log.Infof("[removePendingConnection] Removed profile created by failed first connection to %s", ssid)
+}
+
+func (b *NetworkManagerBackend) disableAutoconnect(conn gonetworkmanager.Connection) {
+	settings, err := conn.GetSettings()
+	if err != nil {
+		log.Warnf("[disableAutoconnect] Failed to get settings: %v", err)
+		return
+	}
+
+	connSection, ok := settings["connection"]
+	if !ok {
+		return
+	}
+	connSection["autoconnect"] = false
+
+	if err := conn.Update(settings); err != nil {
+		log.Warnf("[disableAutoconnect] Failed to update connection: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/Wifx/gonetworkmanager/v2"
+)
+
+// pinnedAccessPoint finds the access point of req.SSID that req asks for, and
+// its BSSID. With band any it returns nil, leaving the choice to
+// NetworkManager
+func (b *NetworkManagerBackend) pinnedAccessPoint(req ConnectionRequest) (gonetworkmanager.AccessPoint, string, error) {
+	if req.Band == BandAny {
+		return nil, "", nil
+	}
+
+	if err := b.ensureWiFiDevice(); err != nil {
+		return nil, "", err
+	}
+	apPaths, err := b.wifiDev.(gonetworkmanager.DeviceWireless).GetAccessPoints()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get access points: %w", err)
+	}
+
+	var matching []gonetworkmanager.AccessPoint
+	var candidates []apCandidate
+	for _, ap := range apPaths {
+		ssid, err := ap.GetPropertySSID()
+		if err != nil || ssid != req.SSID {
+			continue
+		}
+		bssid, _ := ap.GetPropertyHWAddress()
+		freq, _ := ap.GetPropertyFrequency()
+		strength, _ := ap.GetPropertyStrength()
+		matching = append(matching, ap)
+		candidates = append(candidates, apCandidate{bssid: bssid, freq: freq, strength: strength})
+	}
+
+	i, err := pickAccessPoint(candidates, req.BSSID, req.Band)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", req.SSID, err)
+	}
+	return matching[i], candidates[i].bssid, nil
+}
+
+// pinProfile saves req's band or BSSID on a saved profile, so autoconnect
+// keeps using it
+func (b *NetworkManagerBackend) pinProfile(conn gonetworkmanager.Connection, req ConnectionRequest, apBSSID string) error {
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return fmt.Errorf("failed to get connection settings: %w", err)
+	}
+	wireless, ok := settings["802-11-wireless"]
+	if !ok {
+		return fmt.Errorf("connection has no wireless settings")
+	}
+	if err := applyAPPin(wireless, req, apBSSID); err != nil {
+		return err
+	}
+
+	dropDeprecatedAddressKeys(settings)
+	if err := conn.Update(settings); err != nil {
+		return fmt.Errorf("failed to update connection: %w", err)
+	}
+	return nil
+}
+
+// activatePinned saves req's pin on a saved profile and activates it on the
+// chosen access point
+func (b *NetworkManagerBackend) activatePinned(conn gonetworkmanager.Connection, req ConnectionRequest) error {
+	ap, bssid, err := b.pinnedAccessPoint(req)
+	if err != nil {
+		return err
+	}
+	if err := b.pinProfile(conn, req, bssid); err != nil {
+		return err
+	}
+
+	nm := b.nmConn.(gonetworkmanager.NetworkManager)
+	dev := b.wifiDevice.(gonetworkmanager.Device)
+	if ap == nil {
+		_, err = nm.ActivateConnection(conn, dev, nil)
+	} else {
+		_, err = nm.ActivateWirelessConnection(conn, dev, ap)
+	}
+	return err
+}
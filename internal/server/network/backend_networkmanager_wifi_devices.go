@@ -0,0 +1,151 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/Wifx/gonetworkmanager/v2"
+	"github.com/godbus/dbus/v5"
+)
+
+// preferredDevice picks the device that is already connected, else the
+// first one
+func preferredDevice(devices []gonetworkmanager.Device) gonetworkmanager.Device {
+	for _, dev := range devices {
+		if state, err := dev.GetPropertyState(); err == nil && state == gonetworkmanager.NmDeviceStateActivated {
+			return dev
+		}
+	}
+	if len(devices) > 0 {
+		return devices[0]
+	}
+	return nil
+}
+
+func (b *NetworkManagerBackend) selectedWiFiPath() dbus.ObjectPath {
+	if b.wifiDevice == nil {
+		return ""
+	}
+	return b.wifiDevice.(gonetworkmanager.Device).GetPath()
+}
+
+func (b *NetworkManagerBackend) updateWiFiDeviceList() {
+	selected := b.selectedWiFiPath()
+
+	devices := make([]WiFiDeviceInfo, 0, len(b.wifiDevices))
+	for _, dev := range b.wifiDevices {
+		iface, err := dev.GetPropertyInterface()
+		if err != nil {
+			continue
+		}
+		info := WiFiDeviceInfo{Name: iface, Selected: dev.GetPath() == selected}
+		if state, err := dev.GetPropertyState(); err == nil {
+			info.Connected = state == gonetworkmanager.NmDeviceStateActivated
+		}
+		if w, err := gonetworkmanager.NewDeviceWireless(dev.GetPath()); err == nil {
+			info.Address, _ = w.GetPropertyHwAddress()
+		}
+		devices = append(devices, info)
+	}
+
+	b.stateMutex.Lock()
+	b.state.WiFiDevices = devices
+	b.stateMutex.Unlock()
+}
+
+// rescanWiFiDevices picks up adapters that were plugged in or removed. When
+// the selected one disappears another is selected
+func (b *NetworkManagerBackend) rescanWiFiDevices() {
+	nm := b.nmConn.(gonetworkmanager.NetworkManager)
+	all, err := nm.GetDevices()
+	if err != nil {
+		return
+	}
+
+	var devices []gonetworkmanager.Device
+	selectedPresent := false
+	selected := b.selectedWiFiPath()
+	for _, dev := range all {
+		if devType, err := dev.GetPropertyDeviceType(); err != nil || devType != gonetworkmanager.NmDeviceTypeWifi {
+			continue
+		}
+		devices = append(devices, dev)
+		if dev.GetPath() == selected {
+			selectedPresent = true
+		}
+	}
+	b.wifiDevices = devices
+
+	if !selectedPresent {
+		b.switchWiFiDevice(preferredDevice(devices))
+	}
+	b.updateWiFiDeviceList()
+}
+
+func (b *NetworkManagerBackend) SelectWiFiDevice(iface string) error {
+	for _, dev := range b.wifiDevices {
+		name, err := dev.GetPropertyInterface()
+		if err != nil || name != iface {
+			continue
+		}
+		if dev.GetPath() != b.selectedWiFiPath() {
+			b.switchWiFiDevice(dev)
+		}
+		b.updateWiFiDeviceList()
+		if b.onStateChange != nil {
+			b.onStateChange()
+		}
+		return nil
+	}
+	return fmt.Errorf("no WiFi device named %q", iface)
+}
+
+// switchWiFiDevice moves the signal subscription and the WiFi state over to
+// dev, which may be nil when the last adapter was removed
+func (b *NetworkManagerBackend) switchWiFiDevice(dev gonetworkmanager.Device) {
+	if old := b.selectedWiFiPath(); old != "" && b.dbusConn != nil {
+		_ = b.dbusConn.RemoveMatchSignal(
+			dbus.WithMatchObjectPath(old),
+			dbus.WithMatchInterface(dbusPropsInterface),
+			dbus.WithMatchMember("PropertiesChanged"),
+		)
+	}
+
+	b.wifiDev = nil
+	if dev == nil {
+		b.wifiDevice = nil
+		b.stateMutex.Lock()
+		b.state.WiFiDevice = ""
+		b.state.WiFiConnected = false
+		b.state.WiFiIP = ""
+		b.state.WiFiSSID = ""
+		b.state.WiFiBSSID = ""
+		b.state.WiFiSignal = 0
+		b.state.WiFiNetworks = nil
+		b.stateMutex.Unlock()
+		return
+	}
+	b.wifiDevice = dev
+
+	if b.dbusConn != nil {
+		if err := b.dbusConn.AddMatchSignal(
+			dbus.WithMatchObjectPath(dev.GetPath()),
+			dbus.WithMatchInterface(dbusPropsInterface),
+			dbus.WithMatchMember("PropertiesChanged"),
+		); err != nil {
+			log.Warnf("Failed to watch WiFi device %s: %v", dev.GetPath(), err)
+		}
+	}
+
+	if err := b.updateWiFiState(); err != nil {
+		log.Warnf("Failed to read WiFi device state: %v", err)
+	}
+	b.stateMutex.RLock()
+	enabled := b.state.WiFiEnabled
+	b.stateMutex.RUnlock()
+	if enabled {
+		if _, err := b.updateWiFiNetworks(); err != nil {
+			log.Warnf("Failed to list networks: %v", err)
+		}
+	}
+}
@@ -2,7 +2,10 @@ package network
 
 import (
 	"testing"
+	"time"
 
+	"github.com/AvengeMedia/danklinux/internal/errdefs"
+	"github.com/Wifx/gonetworkmanager/v2"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -192,3 +195,140 @@ func TestNetworkManagerBackend_CreateAndConnectWiFi_NoDevice(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no WiFi device available")
 }
+
+func TestRemovesPendingProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		pendingSSID string
+		created     bool
+		failedSSID  string
+		expected    bool
+	}{
+		{"failed first connect", "Home", true, "Home", true},
+		{"failed reconnect to saved profile", "Home", false, "Home", false},
+		{"other network failed", "Home", true, "Cafe", false},
+		{"nothing pending", "", false, "Home", false},
+		{"no ssid", "", true, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, removesPendingProfile(tt.pendingSSID, tt.created, tt.failedSSID))
+		})
+	}
+}
+
+func TestNetworkManagerBackend_RemovePendingConnection_ClearsPending(t *testing.T) {
+	backend := &NetworkManagerBackend{}
+	backend.pendingConnSSID = "OtherNetwork"
+	backend.pendingConnCreated = true
+
+	backend.removePendingConnection("TestNetwork")
+	assert.Nil(t, backend.pendingConn)
+	assert.Empty(t, backend.pendingConnSSID)
+	assert.False(t, backend.pendingConnCreated)
+}
+
+func TestDetectWiFiSecurity(t *testing.T) {
+	tests := []struct {
+		name     string
+		flags    uint32
+		wpa      uint32
+		rsn      uint32
+		expected string
+	}{
+		{"open", 0, 0, 0, SecurityOpen},
+		{"wep", apFlagsPrivacy, 0, 0, SecurityUnknown},
+		{"wpa2", apFlagsPrivacy, 0, apSecKeyMgmtPSK, SecurityPSK},
+		{"wpa1", apFlagsPrivacy, apSecKeyMgmtPSK, 0, SecurityPSK},
+		{"wpa3", apFlagsPrivacy, 0, apSecKeyMgmtSAE, SecuritySAE},
+		{"transition", apFlagsPrivacy, 0, apSecKeyMgmtPSK | apSecKeyMgmtSAE, SecuritySAETransition},
+		{"owe", apFlagsPrivacy, 0, apSecKeyMgmtOWE, SecurityOWE},
+		{"owe transition open side", 0, 0, apSecKeyMgmtOWETM, SecurityOWE},
+		{"enterprise", apFlagsPrivacy, 0, apSecKeyMgmt8021X, SecurityEAP},
+		{"suite-b", apFlagsPrivacy, 0, apSecKeyMgmtEAPSuiteB, SecurityEAP},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, detectWiFiSecurity(tt.flags, tt.wpa, tt.rsn))
+		})
+	}
+}
+
+func TestWiFiSecuritySettings(t *testing.T) {
+	original := supplicantSupportsSAE
+	defer func() { supplicantSupportsSAE = original }()
+
+	supplicantSupportsSAE = func() bool { return true }
+	sec := wifiSecuritySettings(SecuritySAETransition, "secret", false)
+	assert.Equal(t, "sae", sec["key-mgmt"])
+	assert.Equal(t, int32(2), sec["pmf"])
+	assert.Equal(t, "secret", sec["psk"])
+
+	supplicantSupportsSAE = func() bool { return false }
+	sec = wifiSecuritySettings(SecuritySAETransition, "secret", false)
+	assert.Equal(t, "wpa-psk", sec["key-mgmt"])
+	assert.NotContains(t, sec, "pmf")
+
+	sec = wifiSecuritySettings(SecuritySAE, "secret", true)
+	assert.Equal(t, int32(3), sec["pmf"])
+	assert.NotContains(t, sec, "psk")
+
+	assert.Equal(t, map[string]interface{}{"key-mgmt": "owe"}, wifiSecuritySettings(SecurityOWE, "", false))
+	assert.Nil(t, wifiSecuritySettings(SecurityUnknown, "", false))
+
+	assert.False(t, securityNeedsPassword(SecurityOWE))
+	assert.True(t, securityNeedsPassword(SecuritySAETransition))
+}
+
+func TestHiddenNetworkSecurity(t *testing.T) {
+	assert.Equal(t, SecurityOpen, hiddenNetworkSecurity(ConnectionRequest{SSID: "lab", Hidden: true}))
+	assert.Equal(t, SecurityPSK, hiddenNetworkSecurity(ConnectionRequest{SSID: "lab", Hidden: true, Password: "secret"}))
+	assert.Equal(t, SecurityPSK, hiddenNetworkSecurity(ConnectionRequest{SSID: "lab", Hidden: true, Interactive: true}))
+	assert.Equal(t, SecurityEAP, hiddenNetworkSecurity(ConnectionRequest{SSID: "lab", Hidden: true, Username: "alice"}))
+}
+
+func TestConnectTimeout(t *testing.T) {
+	assert.Equal(t, defaultNMConnectTimeout, connectTimeout(ConnectionRequest{}, defaultNMConnectTimeout))
+	assert.Equal(t, 20*time.Second, connectTimeout(ConnectionRequest{Timeout: 20}, defaultNMConnectTimeout))
+}
+
+func TestNetworkManagerBackend_ClassifyNMTimeout(t *testing.T) {
+	backend := &NetworkManagerBackend{state: &BackendState{
+		WiFiNetworks: []WiFiNetwork{{SSID: "InRange"}},
+	}}
+
+	assert.Equal(t, errdefs.ErrDhcpTimeout, backend.classifyNMTimeout(&nmConnectAttempt{ssid: "InRange", sawConfig: true, sawIPConfig: true}))
+	assert.Equal(t, errdefs.ErrAssocTimeout, backend.classifyNMTimeout(&nmConnectAttempt{ssid: "InRange", sawConfig: true}))
+	assert.Equal(t, errdefs.ErrAssocTimeout, backend.classifyNMTimeout(&nmConnectAttempt{ssid: "InRange"}))
+	assert.Equal(t, errdefs.ErrNoSuchSSID, backend.classifyNMTimeout(&nmConnectAttempt{ssid: "Gone"}))
+}
+
+func TestNetworkManagerBackend_CancelConnect_NotConnecting(t *testing.T) {
+	backend := &NetworkManagerBackend{state: &BackendState{IsConnecting: true, ConnectingSSID: "TestNetwork"}}
+	assert.Error(t, backend.CancelConnect("OtherNetwork"))
+
+	backend.state.IsConnecting = false
+	assert.Error(t, backend.CancelConnect(""))
+}
+
+func TestReconcileAttempt(t *testing.T) {
+	activating := gonetworkmanager.NmActiveConnectionStateActivating
+	activated := gonetworkmanager.NmActiveConnectionStateActivated
+	deactivating := gonetworkmanager.NmActiveConnectionStateDeactivating
+
+	assert.Equal(t, attemptOwned, reconcileAttempt("Home", "Home", activating))
+	assert.Equal(t, attemptAdopted, reconcileAttempt("Home", "Home", activated))
+	assert.Equal(t, attemptSuperseded, reconcileAttempt("Home", "Cafe", activating))
+	assert.Equal(t, attemptSuperseded, reconcileAttempt("Home", "Cafe", activated))
+	assert.Equal(t, attemptOwned, reconcileAttempt("Home", "Cafe", deactivating), "the old network going down is part of switching")
+}
+
+func TestNetworkManagerBackend_ReconcileConnecting_NotRequested(t *testing.T) {
+	backend := &NetworkManagerBackend{state: &BackendState{IsConnecting: true, ConnectingSSID: "Home"}}
+	backend.curAttempt = &nmConnectAttempt{ssid: "Home"}
+
+	backend.reconcileConnecting()
+	assert.True(t, backend.state.IsConnecting, "an attempt NetworkManager hasn't accepted yet is left alone")
+}
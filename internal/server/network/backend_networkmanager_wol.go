@@ -0,0 +1,78 @@
+package network
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/Wifx/gonetworkmanager/v2"
+)
+
+func (b *NetworkManagerBackend) wiredConnectionSettings(uuid string) (gonetworkmanager.Connection, gonetworkmanager.ConnectionSettings, error) {
+	conn, err := b.findConnectionByUUID(uuid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	settings, err := conn.GetSettings()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get connection settings: %w", err)
+	}
+	if connType, _ := settings["connection"]["type"].(string); connType != "802-3-ethernet" {
+		return nil, nil, fmt.Errorf("connection %s is not a wired connection", uuid)
+	}
+	return conn, settings, nil
+}
+
+func (b *NetworkManagerBackend) GetWakeOnLAN(uuid string) (*WakeOnLANConfig, error) {
+	_, settings, err := b.wiredConnectionSettings(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	// An unset wake-on-lan is NetworkManager's default mode
+	flags := uint32(0x1)
+	if value, ok := settings["802-3-ethernet"]["wake-on-lan"].(uint32); ok {
+		flags = value
+	}
+	password, _ := settings["802-3-ethernet"]["wake-on-lan-password"].(string)
+	return &WakeOnLANConfig{Modes: wakeOnLANModesFromFlags(flags), Password: password}, nil
+}
+
+// SetWakeOnLAN saves 802-3-ethernet.wake-on-lan on a wired profile. The
+// driver is configured the next time the profile is activated
+func (b *NetworkManagerBackend) SetWakeOnLAN(uuid string, config WakeOnLANConfig) error {
+	flags, err := wakeOnLANFlagsFromModes(config.Modes)
+	if err != nil {
+		return err
+	}
+	if config.Password != "" {
+		if _, err := net.ParseMAC(config.Password); err != nil {
+			return fmt.Errorf("invalid Wake-on-LAN password %q: it is written as a MAC address", config.Password)
+		}
+	}
+
+	conn, settings, err := b.wiredConnectionSettings(uuid)
+	if err != nil {
+		return err
+	}
+
+	wired := settings["802-3-ethernet"]
+	if wired == nil {
+		wired = make(map[string]interface{})
+		settings["802-3-ethernet"] = wired
+	}
+	wired["wake-on-lan"] = flags
+	if config.Password != "" {
+		wired["wake-on-lan-password"] = config.Password
+	} else {
+		delete(wired, "wake-on-lan-password")
+	}
+
+	dropDeprecatedAddressKeys(settings)
+	if err := conn.Update(settings); err != nil {
+		return fmt.Errorf("failed to update connection: %w", err)
+	}
+	log.Infof("[SetWakeOnLAN] Set wake-on-lan=%v on %s", wakeOnLANModesFromFlags(flags), uuid)
+	return nil
+}
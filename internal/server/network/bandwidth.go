@@ -0,0 +1,136 @@
+package network
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
+)
+
+const bandwidthInterval = time.Second
+
+// Bandwidth is the throughput of the device carrying the primary connection
+type Bandwidth struct {
+	Device        string `json:"device"`
+	RxBytesPerSec uint64 `json:"rxBytesPerSec"`
+	TxBytesPerSec uint64 `json:"txBytesPerSec"`
+	RxBytes       uint64 `json:"rxBytes"`
+	TxBytes       uint64 `json:"txBytes"`
+}
+
+// bandwidthSampler turns the interface byte counters in sysfs into rates.
+// Reading sysfs works the same for every backend, so it isn't part of Backend
+type bandwidthSampler struct {
+	root   string
+	device string
+	rx, tx uint64
+	at     time.Time
+}
+
+func newBandwidthSampler() *bandwidthSampler {
+	return &bandwidthSampler{root: "/sys/class/net"}
+}
+
+func (s *bandwidthSampler) readCounter(device, name string) (uint64, error) {
+	data, err := os.ReadFile(filepath.Join(s.root, device, "statistics", name))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// sample reads the counters of device and returns the rates since the last
+// sample. The first sample of a device only has totals
+func (s *bandwidthSampler) sample(device string, now time.Time) Bandwidth {
+	if device == "" {
+		s.device = ""
+		return Bandwidth{}
+	}
+
+	rx, errRx := s.readCounter(device, "rx_bytes")
+	tx, errTx := s.readCounter(device, "tx_bytes")
+	if errRx != nil || errTx != nil {
+		s.device = ""
+		return Bandwidth{Device: device}
+	}
+
+	bw := Bandwidth{Device: device, RxBytes: rx, TxBytes: tx}
+	if s.device == device {
+		if elapsed := now.Sub(s.at).Seconds(); elapsed > 0 {
+			bw.RxBytesPerSec = counterRate(s.rx, rx, elapsed)
+			bw.TxBytesPerSec = counterRate(s.tx, tx, elapsed)
+		}
+	}
+
+	s.device, s.rx, s.tx, s.at = device, rx, tx, now
+	return bw
+}
+
+func counterRate(prev, cur uint64, elapsed float64) uint64 {
+	// Counters restart when the interface is recreated
+	if cur < prev {
+		return 0
+	}
+	return uint64(float64(cur-prev) / elapsed)
+}
+
+// bandwidthChanged ignores the totals, which grow on every sample, so an idle
+// link doesn't notify subscribers each second
+func bandwidthChanged(old, new Bandwidth) bool {
+	return old.Device != new.Device ||
+		old.RxBytesPerSec != new.RxBytesPerSec ||
+		old.TxBytesPerSec != new.TxBytesPerSec
+}
+
+// bandwidthDevice picks the device whose traffic the bar should show. VPN
+// traffic is counted on the link underneath it
+func bandwidthDevice(state *NetworkState) string {
+	switch state.NetworkStatus {
+	case StatusEthernet:
+		return state.EthernetDevice
+	case StatusWiFi:
+		return state.WiFiDevice
+	case StatusVPN:
+		if state.EthernetConnected {
+			return state.EthernetDevice
+		}
+		if state.WiFiConnected {
+			return state.WiFiDevice
+		}
+	}
+	return ""
+}
+
+func (m *Manager) bandwidthMonitor() {
+	defer m.notifierWg.Done()
+
+	sampler := newBandwidthSampler()
+	ticker := time.NewTicker(bandwidthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case now := <-ticker.C:
+			m.stateMutex.RLock()
+			device := bandwidthDevice(m.state)
+			m.stateMutex.RUnlock()
+
+			bw := sampler.sample(device, now)
+
+			m.stateMutex.Lock()
+			changed := bandwidthChanged(m.state.Bandwidth, bw)
+			m.state.Bandwidth = bw
+			m.stateMutex.Unlock()
+
+			if changed {
+				m.notifySubscribers()
+			}
+			ticker.Reset(budget.Scale(bandwidthInterval))
+		}
+	}
+}
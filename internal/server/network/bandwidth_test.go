@@ -0,0 +1,88 @@
+package network
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCounters(t *testing.T, root, device string, rx, tx uint64) {
+	t.Helper()
+	dir := filepath.Join(root, device, "statistics")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "rx_bytes"), []byte(strconv.FormatUint(rx, 10)+"\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tx_bytes"), []byte(strconv.FormatUint(tx, 10)+"\n"), 0o644))
+}
+
+func TestBandwidthSampler_Rates(t *testing.T) {
+	root := t.TempDir()
+	s := &bandwidthSampler{root: root}
+	start := time.Now()
+
+	writeCounters(t, root, "wlan0", 1000, 500)
+	bw := s.sample("wlan0", start)
+	assert.Equal(t, Bandwidth{Device: "wlan0", RxBytes: 1000, TxBytes: 500}, bw)
+
+	writeCounters(t, root, "wlan0", 5000, 1500)
+	bw = s.sample("wlan0", start.Add(2*time.Second))
+	assert.Equal(t, uint64(2000), bw.RxBytesPerSec)
+	assert.Equal(t, uint64(500), bw.TxBytesPerSec)
+	assert.Equal(t, uint64(5000), bw.RxBytes)
+
+	// A reset counter doesn't produce a huge rate
+	writeCounters(t, root, "wlan0", 10, 10)
+	bw = s.sample("wlan0", start.Add(3*time.Second))
+	assert.Zero(t, bw.RxBytesPerSec)
+	assert.Zero(t, bw.TxBytesPerSec)
+}
+
+func TestBandwidthSampler_DeviceSwitch(t *testing.T) {
+	root := t.TempDir()
+	s := &bandwidthSampler{root: root}
+	start := time.Now()
+
+	writeCounters(t, root, "wlan0", 1000, 1000)
+	writeCounters(t, root, "eth0", 9000, 9000)
+	s.sample("wlan0", start)
+
+	bw := s.sample("eth0", start.Add(time.Second))
+	assert.Equal(t, "eth0", bw.Device)
+	assert.Zero(t, bw.RxBytesPerSec)
+
+	assert.Equal(t, Bandwidth{}, s.sample("", start.Add(2*time.Second)))
+	assert.Equal(t, Bandwidth{Device: "missing0"}, s.sample("missing0", start.Add(3*time.Second)))
+}
+
+func TestBandwidthDevice(t *testing.T) {
+	state := &NetworkState{
+		NetworkStatus:     StatusVPN,
+		EthernetDevice:    "eth0",
+		WiFiDevice:        "wlan0",
+		WiFiConnected:     true,
+		EthernetConnected: false,
+	}
+	assert.Equal(t, "wlan0", bandwidthDevice(state))
+
+	state.EthernetConnected = true
+	assert.Equal(t, "eth0", bandwidthDevice(state))
+
+	state.NetworkStatus = StatusWiFi
+	assert.Equal(t, "wlan0", bandwidthDevice(state))
+
+	state.NetworkStatus = StatusDisconnected
+	assert.Empty(t, bandwidthDevice(state))
+}
+
+func TestStateChangedMeaningfully_Bandwidth(t *testing.T) {
+	old := &NetworkState{Bandwidth: Bandwidth{Device: "wlan0", RxBytes: 100}}
+	same := &NetworkState{Bandwidth: Bandwidth{Device: "wlan0", RxBytes: 200}}
+	assert.False(t, stateChangedMeaningfully(old, same))
+
+	faster := &NetworkState{Bandwidth: Bandwidth{Device: "wlan0", RxBytesPerSec: 1024}}
+	assert.True(t, stateChangedMeaningfully(old, faster))
+}
@@ -0,0 +1,400 @@
+package network
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/Wifx/gonetworkmanager/v2"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	bundleVersion      = 1
+	bundleManifestName = "manifest.json"
+	bundleSecretsName  = "secrets.enc"
+	bundleProfileDir   = "profiles/"
+	maxBundleEntrySize = 4 << 20
+)
+
+// Argon2id parameters for the key sealing secrets.enc. The random salt is
+// stored in front of the nonce and the sealed data
+const (
+	bundleKDFTime    = 3
+	bundleKDFMemory  = 64 * 1024
+	bundleKDFThreads = 4
+	bundleSaltSize   = 16
+)
+
+// BundleManifest describes a bundle: every saved NetworkManager profile in
+// the format of dms network export, without secrets. With a passphrase the
+// secrets are kept apart in secrets.enc, encrypted with it
+type BundleManifest struct {
+	Version  int             `json:"version"`
+	Created  string          `json:"created"`
+	Secrets  bool            `json:"secrets"`
+	Profiles []BundleProfile `json:"profiles"`
+}
+
+type BundleProfile struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	File string `json:"file"`
+}
+
+// BundleImportResult lists what happened to each profile of a bundle.
+// SecretsSkipped is set when the bundle has secrets but no passphrase was
+// given, so passwords have to be entered again
+type BundleImportResult struct {
+	Imported       []ImportResult    `json:"imported"`
+	Failed         map[string]string `json:"failed,omitempty"`
+	SecretsSkipped bool              `json:"secretsSkipped"`
+}
+
+type bundleEntry struct {
+	profile *ProfileFile
+	secrets map[string]map[string]ProfileValue
+}
+
+// ExportBundle writes every saved profile to w as a gzipped tarball. With a
+// passphrase their secrets are included, encrypted with it
+func ExportBundle(w io.Writer, passphrase string) (*BundleManifest, error) {
+	settingsMgr, err := gonetworkmanager.NewSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+	connections, err := settingsMgr.ListConnections()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connections: %w", err)
+	}
+
+	var entries []bundleEntry
+	for _, conn := range connections {
+		settings, err := conn.GetSettings()
+		if err != nil {
+			continue
+		}
+		if connType, _ := settings["connection"]["type"].(string); connType == "loopback" {
+			continue
+		}
+
+		entry := bundleEntry{profile: profileFromSettings(settings, false)}
+		if passphrase != "" {
+			secrets := profileSecrets(conn, settings, entry.profile.ID)
+			for section, values := range secrets {
+				if entry.secrets == nil {
+					entry.secrets = make(map[string]map[string]ProfileValue)
+				}
+				entry.secrets[section] = profileValues(section, values)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no saved profiles to export")
+	}
+
+	return writeBundle(w, entries, passphrase, time.Now())
+}
+
+// ImportBundle adds every profile of a bundle, resolving conflicts as
+// ImportProfile does and carrying on past failures. Secrets are only
+// restored with the passphrase the bundle was exported with
+func ImportBundle(r io.Reader, passphrase, onConflict string) (*BundleImportResult, error) {
+	if err := checkConflictMode(onConflict); err != nil {
+		return nil, err
+	}
+
+	manifest, entries, err := readBundle(r, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BundleImportResult{
+		Imported:       []ImportResult{},
+		SecretsSkipped: manifest.Secrets && passphrase == "",
+	}
+	for _, entry := range entries {
+		mergeBundleSecrets(entry.profile, entry.secrets)
+		imported, err := ImportProfile(entry.profile, onConflict)
+		if err != nil {
+			log.Warnf("Failed to import %s: %v", entry.profile.ID, err)
+			if result.Failed == nil {
+				result.Failed = make(map[string]string)
+			}
+			result.Failed[entry.profile.ID] = err.Error()
+			continue
+		}
+		result.Imported = append(result.Imported, *imported)
+	}
+	return result, nil
+}
+
+// ExportBundleFile writes a bundle to path, readable only by the user when
+// it holds secrets
+func ExportBundleFile(path, passphrase string) (*BundleManifest, error) {
+	var buf bytes.Buffer
+	manifest, err := ExportBundle(&buf, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := os.FileMode(0644)
+	if manifest.Secrets {
+		mode = 0600
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return nil, err
+	}
+	// An existing file keeps its mode unless changed before writing
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return manifest, f.Close()
+}
+
+func ImportBundleFile(path, passphrase, onConflict string) (*BundleImportResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ImportBundle(f, passphrase, onConflict)
+}
+
+// ReadBundleManifest describes the bundle at path without importing it
+func ReadBundleManifest(path string) (*BundleManifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	manifest, _, err := readBundle(f, "")
+	return manifest, err
+}
+
+// ExportBundle and ImportBundle need NetworkManager's settings service
+func (m *Manager) ExportBundle(path, passphrase string) (*BundleManifest, error) {
+	if _, ok := m.currentBackend().(*NetworkManagerBackend); !ok {
+		return nil, fmt.Errorf("network bundles are only available with the NetworkManager backend")
+	}
+	return ExportBundleFile(path, passphrase)
+}
+
+func (m *Manager) ImportBundle(path, passphrase, onConflict string) (*BundleImportResult, error) {
+	if _, ok := m.currentBackend().(*NetworkManagerBackend); !ok {
+		return nil, fmt.Errorf("network bundles are only available with the NetworkManager backend")
+	}
+	return ImportBundleFile(path, passphrase, onConflict)
+}
+
+func writeBundle(w io.Writer, entries []bundleEntry, passphrase string, created time.Time) (*BundleManifest, error) {
+	manifest := &BundleManifest{
+		Version:  bundleVersion,
+		Created:  created.UTC().Format(time.RFC3339),
+		Secrets:  passphrase != "",
+		Profiles: make([]BundleProfile, 0, len(entries)),
+	}
+
+	files := make(map[string][]byte, len(entries))
+	secrets := make(map[string]map[string]map[string]ProfileValue)
+	for i, entry := range entries {
+		name := fmt.Sprintf("%s%03d.json", bundleProfileDir, i+1)
+		data, err := json.MarshalIndent(entry.profile, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		files[name] = data
+		manifest.Profiles = append(manifest.Profiles, BundleProfile{ID: entry.profile.ID, Type: entry.profile.Type, File: name})
+		if len(entry.secrets) > 0 {
+			secrets[name] = entry.secrets
+		}
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	add := func(name string, data []byte) error {
+		hdr := &tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: created}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := add(bundleManifestName, data); err != nil {
+		return nil, err
+	}
+	for _, p := range manifest.Profiles {
+		if err := add(p.File, files[p.File]); err != nil {
+			return nil, err
+		}
+	}
+	if manifest.Secrets {
+		plain, err := json.Marshal(secrets)
+		if err != nil {
+			return nil, err
+		}
+		sealed, err := sealBundleSecrets(passphrase, plain)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(bundleSecretsName, sealed); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// readBundle reads the profiles of a bundle, with their secrets when the
+// passphrase is given and the bundle has any
+func readBundle(r io.Reader, passphrase string) (*BundleManifest, []bundleEntry, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("not a network bundle: %w", err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if hdr.Size > maxBundleEntrySize {
+			return nil, nil, fmt.Errorf("bundle entry %s is too large", hdr.Name)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxBundleEntrySize))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", hdr.Name, err)
+		}
+		files[hdr.Name] = data
+	}
+
+	data, ok := files[bundleManifestName]
+	if !ok {
+		return nil, nil, fmt.Errorf("not a network bundle: no %s", bundleManifestName)
+	}
+	var manifest BundleManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", bundleManifestName, err)
+	}
+	if manifest.Version > bundleVersion {
+		return nil, nil, fmt.Errorf("bundle was exported by a newer dms (version %d)", manifest.Version)
+	}
+
+	var secrets map[string]map[string]map[string]ProfileValue
+	if sealed, ok := files[bundleSecretsName]; ok && passphrase != "" {
+		plain, err := openBundleSecrets(passphrase, sealed)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := json.Unmarshal(plain, &secrets); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %w", bundleSecretsName, err)
+		}
+	}
+
+	entries := make([]bundleEntry, 0, len(manifest.Profiles))
+	for _, p := range manifest.Profiles {
+		if !strings.HasPrefix(p.File, bundleProfileDir) {
+			return nil, nil, fmt.Errorf("bundle lists %s outside %s", p.File, bundleProfileDir)
+		}
+		data, ok := files[p.File]
+		if !ok {
+			return nil, nil, fmt.Errorf("bundle is missing %s (%s)", p.File, p.ID)
+		}
+		var profile ProfileFile
+		if err := json.Unmarshal(data, &profile); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %w", p.File, err)
+		}
+		entries = append(entries, bundleEntry{profile: &profile, secrets: secrets[p.File]})
+	}
+	return &manifest, entries, nil
+}
+
+func mergeBundleSecrets(p *ProfileFile, secrets map[string]map[string]ProfileValue) {
+	for section, values := range secrets {
+		if p.Settings[section] == nil {
+			p.Settings[section] = make(map[string]ProfileValue, len(values))
+		}
+		for key, value := range values {
+			p.Settings[section][key] = value
+		}
+		p.Secrets = true
+	}
+}
+
+func bundleKey(passphrase string, salt []byte) []byte {
+	return argon2.IDKey([]byte(passphrase), salt, bundleKDFTime, bundleKDFMemory, bundleKDFThreads, chacha20poly1305.KeySize)
+}
+
+func sealBundleSecrets(passphrase string, plain []byte) ([]byte, error) {
+	salt := make([]byte, bundleSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.NewX(bundleKey(passphrase, salt))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(salt)+len(nonce)+len(plain)+aead.Overhead())
+	out = append(out, salt...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plain, []byte(bundleSecretsName)), nil
+}
+
+func openBundleSecrets(passphrase string, sealed []byte) ([]byte, error) {
+	if len(sealed) < bundleSaltSize+chacha20poly1305.NonceSizeX {
+		return nil, fmt.Errorf("bundle secrets are corrupt")
+	}
+	salt := sealed[:bundleSaltSize]
+	nonce := sealed[bundleSaltSize : bundleSaltSize+chacha20poly1305.NonceSizeX]
+
+	aead, err := chacha20poly1305.NewX(bundleKey(passphrase, salt))
+	if err != nil {
+		return nil, err
+	}
+	plain, err := aead.Open(nil, nonce, sealed[bundleSaltSize+chacha20poly1305.NonceSizeX:], []byte(bundleSecretsName))
+	if err != nil {
+		return nil, fmt.Errorf("wrong passphrase, or the bundle secrets are corrupt")
+	}
+	return plain, nil
+}
@@ -0,0 +1,126 @@
+package network
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Wifx/gonetworkmanager/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBundleEntries() []bundleEntry {
+	home := profileFromSettings(gonetworkmanager.ConnectionSettings{
+		"connection":               {"id": "Home", "uuid": "0b6a9c1e-6f1f-4c43-9a4b-1c2d3e4f5a6b", "type": "802-11-wireless"},
+		"802-11-wireless":          {"ssid": []byte("Home")},
+		"802-11-wireless-security": {"key-mgmt": "wpa-psk"},
+	}, false)
+	wired := profileFromSettings(gonetworkmanager.ConnectionSettings{
+		"connection": {"id": "Wired", "uuid": "6c1f5d2e-95a3-4b1c-8f3e-2a7d9b0c4e11", "type": "802-3-ethernet"},
+		"ipv4":       {"method": "auto"},
+	}, false)
+
+	return []bundleEntry{
+		{profile: home, secrets: map[string]map[string]ProfileValue{
+			"802-11-wireless-security": profileValues("802-11-wireless-security", map[string]interface{}{"psk": "secret123"}),
+		}},
+		{profile: wired},
+	}
+}
+
+func bundleFiles(t *testing.T, data []byte) map[string][]byte {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[hdr.Name] = content
+	}
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	created := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	manifest, err := writeBundle(&buf, testBundleEntries(), "correct horse", created)
+	require.NoError(t, err)
+	assert.True(t, manifest.Secrets)
+	assert.Equal(t, "2026-05-01T12:00:00Z", manifest.Created)
+	require.Len(t, manifest.Profiles, 2)
+	assert.Equal(t, BundleProfile{ID: "Home", Type: "802-11-wireless", File: "profiles/001.json"}, manifest.Profiles[0])
+
+	files := bundleFiles(t, buf.Bytes())
+	assert.Contains(t, files, bundleManifestName)
+	assert.Contains(t, files, bundleSecretsName)
+	assert.NotContains(t, string(files["profiles/001.json"]), "secret123", "profiles hold no secrets")
+	assert.NotContains(t, string(files[bundleSecretsName]), "secret123", "secrets are encrypted")
+
+	_, entries, err := readBundle(bytes.NewReader(buf.Bytes()), "correct horse")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	mergeBundleSecrets(entries[0].profile, entries[0].secrets)
+	assert.True(t, entries[0].profile.Secrets)
+	settings, err := profileToSettings(entries[0].profile)
+	require.NoError(t, err)
+	assert.Equal(t, "secret123", settings["802-11-wireless-security"]["psk"])
+	assert.Equal(t, "wpa-psk", settings["802-11-wireless-security"]["key-mgmt"])
+
+	mergeBundleSecrets(entries[1].profile, entries[1].secrets)
+	assert.False(t, entries[1].profile.Secrets)
+}
+
+func TestBundleWithoutPassphrase(t *testing.T) {
+	var buf bytes.Buffer
+	manifest, err := writeBundle(&buf, testBundleEntries(), "", time.Now())
+	require.NoError(t, err)
+	assert.False(t, manifest.Secrets)
+	assert.NotContains(t, bundleFiles(t, buf.Bytes()), bundleSecretsName)
+}
+
+func TestBundleWrongPassphrase(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := writeBundle(&buf, testBundleEntries(), "correct horse", time.Now())
+	require.NoError(t, err)
+
+	_, _, err = readBundle(bytes.NewReader(buf.Bytes()), "battery staple")
+	assert.ErrorContains(t, err, "wrong passphrase")
+
+	manifest, entries, err := readBundle(bytes.NewReader(buf.Bytes()), "")
+	require.NoError(t, err, "profiles can be read without the passphrase")
+	assert.True(t, manifest.Secrets)
+	assert.Nil(t, entries[0].secrets)
+}
+
+func TestReadBundleRejectsInvalid(t *testing.T) {
+	_, _, err := readBundle(bytes.NewReader([]byte("not gzip")), "")
+	assert.ErrorContains(t, err, "not a network bundle")
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	manifest := []byte(`{"version": 1, "profiles": [{"id": "x", "file": "../etc/passwd"}]}`)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: bundleManifestName, Mode: 0600, Size: int64(len(manifest))}))
+	_, err = tw.Write(manifest)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	_, _, err = readBundle(bytes.NewReader(buf.Bytes()), "")
+	assert.ErrorContains(t, err, "outside")
+}
+
+func TestCheckConflictMode(t *testing.T) {
+	assert.NoError(t, checkConflictMode(ConflictFail))
+	assert.NoError(t, checkConflictMode(ConflictSkip))
+	assert.ErrorContains(t, checkConflictMode("merge"), "invalid conflict mode")
+}
@@ -83,11 +83,11 @@ func TestManager_ForgetWiFiNetwork_NotFound(t *testing.T) {
 
 func TestManager_ConnectEthernet_NoDevice(t *testing.T) {
 	backend := mocks_network.NewMockBackend(t)
-	backend.EXPECT().ConnectEthernet().Return(errors.New("no ethernet device available"))
+	backend.EXPECT().ConnectEthernet((*network.ConnectionRequest)(nil)).Return(errors.New("no ethernet device available"))
 
 	manager := network.NewTestManager(backend, &network.NetworkState{})
 
-	err := manager.ConnectEthernet()
+	err := manager.ConnectEthernet(nil)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no ethernet device available")
 }
@@ -107,3 +107,59 @@ func TestManager_DisconnectEthernet_NoDevice(t *testing.T) {
 // mocking the NetworkManager D-Bus interfaces, which is beyond the scope
 // of these unit tests. The tests above cover the basic error cases and
 // validation logic. Integration tests would be needed for full coverage.
+
+func TestManager_StartHotspot(t *testing.T) {
+	backend := mocks_network.NewMockBackend(t)
+	backend.EXPECT().StartHotspot("Laptop", "secret123", "5").Return(nil)
+	backend.EXPECT().StopHotspot().Return(errors.New("hotspot is not running"))
+
+	manager := network.NewTestManager(backend, &network.NetworkState{})
+
+	assert.NoError(t, manager.StartHotspot("Laptop", "secret123", "5"))
+	assert.Error(t, manager.StopHotspot())
+}
+
+func TestManager_SetWiredIPConfig(t *testing.T) {
+	backend := mocks_network.NewMockBackend(t)
+	config := network.WiredIPConfig{Method: "manual", IPs: []string{"192.168.1.10/24"}}
+	backend.EXPECT().SetWiredIPConfig("uuid-1", config).Return(nil)
+
+	manager := network.NewTestManager(backend, &network.NetworkState{})
+
+	assert.NoError(t, manager.SetWiredIPConfig("uuid-1", config))
+}
+
+func TestManager_ListSavedWiFiNetworks(t *testing.T) {
+	backend := mocks_network.NewMockBackend(t)
+	backend.EXPECT().ListSavedWiFiNetworks().Return([]network.SavedWiFiNetwork{
+		{SSID: "Home", Security: "psk"},
+		{SSID: "Airport", Security: "open"},
+	}, nil)
+
+	manager := network.NewTestManager(backend, &network.NetworkState{
+		WiFiConnected: true,
+		WiFiSSID:      "Home",
+		WiFiNetworks:  []network.WiFiNetwork{{SSID: "Home"}, {SSID: "Neighbour"}},
+	})
+
+	saved, err := manager.ListSavedWiFiNetworks()
+	assert.NoError(t, err)
+	assert.True(t, saved[0].InRange)
+	assert.True(t, saved[0].Connected)
+	assert.False(t, saved[1].InRange)
+	assert.False(t, saved[1].Connected)
+}
+
+func TestManager_ForgetSavedWiFiNetworks(t *testing.T) {
+	backend := mocks_network.NewMockBackend(t)
+	backend.EXPECT().ListSavedWiFiNetworks().Return([]network.SavedWiFiNetwork{{SSID: "Home"}, {SSID: "Airport"}}, nil)
+	backend.EXPECT().ForgetWiFiNetwork("Home").Return(nil)
+	backend.EXPECT().ForgetWiFiNetwork("Airport").Return(errors.New("connection not found"))
+
+	manager := network.NewTestManager(backend, &network.NetworkState{})
+
+	result, err := manager.ForgetSavedWiFiNetworks(nil, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Home"}, result.Forgotten)
+	assert.Equal(t, map[string]string{"Airport": "connection not found"}, result.Failed)
+}
@@ -0,0 +1,397 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+// ICMP needs privileges the server doesn't have, so a TCP handshake stands
+// in for ping; http times a full request to a captive portal check URL
+const (
+	ConnectivityTCP  = "tcp"
+	ConnectivityHTTP = "http"
+)
+
+const (
+	ConnectivityUnknown  = "unknown"
+	ConnectivityGood     = "good"
+	ConnectivityDegraded = "degraded"
+	ConnectivityPoor     = "poor"
+	ConnectivityOffline  = "offline"
+)
+
+const (
+	defaultConnectivityTCPTarget  = "1.1.1.1:443"
+	defaultConnectivityHTTPTarget = "http://nmcheck.gnome.org/check_network_status.txt"
+	defaultConnectivityInterval   = 30
+	defaultConnectivityProbes     = 5
+	maxConnectivityProbes         = 20
+	minConnectivityInterval       = 5
+
+	connectivityProbeTimeout = 2 * time.Second
+	connectivityProbeGap     = 200 * time.Millisecond
+)
+
+// Thresholds for the quality the shell's indicator shows
+const (
+	degradedLatencyMs = 150
+	degradedJitterMs  = 50
+	degradedLoss      = 5
+	poorLatencyMs     = 500
+	poorLoss          = 25
+)
+
+// ConnectivityConfig turns on periodic probing of the primary connection.
+// Interval is in seconds; each check sends Probes probes to Target
+type ConnectivityConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Method   string `json:"method"`
+	Target   string `json:"target"`
+	Interval int    `json:"interval"`
+	Probes   int    `json:"probes"`
+}
+
+// Connectivity is the result of the last check. Latency and jitter only
+// count probes that got through
+type Connectivity struct {
+	Enabled     bool    `json:"enabled"`
+	Method      string  `json:"method,omitempty"`
+	Target      string  `json:"target,omitempty"`
+	Quality     string  `json:"quality"`
+	LatencyMs   float64 `json:"latencyMs"`
+	JitterMs    float64 `json:"jitterMs"`
+	LossPercent float64 `json:"lossPercent"`
+	CheckedAt   string  `json:"checkedAt,omitempty"`
+}
+
+type connectivityProbe func(ctx context.Context, target string) (time.Duration, error)
+
+func defaultConnectivityPath() string {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		configDir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(configDir, "dms", "connectivity.json")
+}
+
+// normalize fills in defaults and checks the method and target
+func (c ConnectivityConfig) normalize() (ConnectivityConfig, error) {
+	switch c.Method {
+	case "":
+		c.Method = ConnectivityTCP
+	case ConnectivityTCP, ConnectivityHTTP:
+	default:
+		return c, fmt.Errorf("invalid connectivity method %q (use tcp or http)", c.Method)
+	}
+
+	switch {
+	case c.Target == "" && c.Method == ConnectivityTCP:
+		c.Target = defaultConnectivityTCPTarget
+	case c.Target == "":
+		c.Target = defaultConnectivityHTTPTarget
+	case c.Method == ConnectivityTCP:
+		if _, _, err := net.SplitHostPort(c.Target); err != nil {
+			return c, fmt.Errorf("tcp target must be host:port: %w", err)
+		}
+	case !strings.HasPrefix(c.Target, "http://") && !strings.HasPrefix(c.Target, "https://"):
+		return c, fmt.Errorf("http target must be an http:// or https:// URL")
+	}
+
+	if c.Interval == 0 {
+		c.Interval = defaultConnectivityInterval
+	}
+	if c.Interval < minConnectivityInterval {
+		return c, fmt.Errorf("interval must be at least %d seconds", minConnectivityInterval)
+	}
+	if c.Probes == 0 {
+		c.Probes = defaultConnectivityProbes
+	}
+	if c.Probes < 1 || c.Probes > maxConnectivityProbes {
+		return c, fmt.Errorf("probes must be between 1 and %d", maxConnectivityProbes)
+	}
+	return c, nil
+}
+
+func probeTCP(ctx context.Context, target string) (time.Duration, error) {
+	var dialer net.Dialer
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", target)
+	if err != nil {
+		return 0, err
+	}
+	took := time.Since(start)
+	conn.Close()
+	return took, nil
+}
+
+var connectivityHTTPClient = &http.Client{
+	Transport: &http.Transport{DisableKeepAlives: true, Proxy: http.ProxyFromEnvironment},
+	// A captive portal's redirect still shows the link is up
+	CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+}
+
+func probeHTTP(ctx context.Context, target string) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	resp, err := connectivityHTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	took := time.Since(start)
+	resp.Body.Close()
+	return took, nil
+}
+
+// summarizeProbes turns the round trips of the probes that got through out
+// of sent into latency, jitter (the mean difference between consecutive
+// round trips) and loss
+func summarizeProbes(rtts []time.Duration, sent int) Connectivity {
+	var c Connectivity
+	if sent == 0 {
+		c.Quality = ConnectivityUnknown
+		return c
+	}
+	c.LossPercent = roundMs(100 * float64(sent-len(rtts)) / float64(sent))
+
+	if len(rtts) > 0 {
+		var total, diffs float64
+		for i, rtt := range rtts {
+			total += durationMs(rtt)
+			if i > 0 {
+				diffs += math.Abs(durationMs(rtt) - durationMs(rtts[i-1]))
+			}
+		}
+		c.LatencyMs = roundMs(total / float64(len(rtts)))
+		if len(rtts) > 1 {
+			c.JitterMs = roundMs(diffs / float64(len(rtts)-1))
+		}
+	}
+
+	c.Quality = connectivityQuality(c)
+	return c
+}
+
+func connectivityQuality(c Connectivity) string {
+	switch {
+	case c.LossPercent >= 100:
+		return ConnectivityOffline
+	case c.LossPercent >= poorLoss || c.LatencyMs >= poorLatencyMs:
+		return ConnectivityPoor
+	case c.LossPercent >= degradedLoss || c.LatencyMs >= degradedLatencyMs || c.JitterMs >= degradedJitterMs:
+		return ConnectivityDegraded
+	}
+	return ConnectivityGood
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func roundMs(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// runConnectivityCheck sends the probes one after another, a little apart so
+// a single burst of loss doesn't fail them all
+func runConnectivityCheck(ctx context.Context, config ConnectivityConfig, probe connectivityProbe) Connectivity {
+	var rtts []time.Duration
+	sent := 0
+	for i := 0; i < config.Probes; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(connectivityProbeGap):
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		probeCtx, cancel := context.WithTimeout(ctx, connectivityProbeTimeout)
+		rtt, err := probe(probeCtx, config.Target)
+		cancel()
+		if ctx.Err() != nil {
+			break
+		}
+		sent++
+		if err != nil {
+			log.Debugf("network: connectivity probe to %s failed: %v", config.Target, err)
+			continue
+		}
+		rtts = append(rtts, rtt)
+	}
+
+	c := summarizeProbes(rtts, sent)
+	c.Method = config.Method
+	c.Target = config.Target
+	return c
+}
+
+func (m *Manager) loadConnectivityConfig() {
+	data, err := os.ReadFile(m.connectivityPath)
+	if err != nil {
+		return
+	}
+
+	var config ConnectivityConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		log.Warnf("network: ignoring invalid connectivity config %s: %v", m.connectivityPath, err)
+		return
+	}
+	if config, err = config.normalize(); err != nil {
+		log.Warnf("network: ignoring invalid connectivity config %s: %v", m.connectivityPath, err)
+		return
+	}
+	m.connectivityMutex.Lock()
+	m.connectivityConfig = config
+	m.connectivityMutex.Unlock()
+}
+
+func (m *Manager) saveConnectivityConfig(config ConnectivityConfig) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.connectivityPath), 0755); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(m.connectivityPath, data, 0644)
+}
+
+func (m *Manager) GetConnectivityConfig() ConnectivityConfig {
+	m.connectivityMutex.Lock()
+	defer m.connectivityMutex.Unlock()
+	return m.connectivityConfig
+}
+
+// SetConnectivityConfig saves the config and checks right away when enabled
+func (m *Manager) SetConnectivityConfig(config ConnectivityConfig) (ConnectivityConfig, error) {
+	config, err := config.normalize()
+	if err != nil {
+		return config, err
+	}
+
+	if m.connectivityPath != "" {
+		if err := m.saveConnectivityConfig(config); err != nil {
+			return config, fmt.Errorf("failed to save connectivity config: %w", err)
+		}
+	}
+
+	m.connectivityMutex.Lock()
+	m.connectivityConfig = config
+	m.connectivityMutex.Unlock()
+
+	if !config.Enabled {
+		m.stateMutex.Lock()
+		m.state.Connectivity = Connectivity{Quality: ConnectivityUnknown}
+		m.stateMutex.Unlock()
+		m.notifySubscribers()
+	}
+	m.wakeConnectivity()
+	return config, nil
+}
+
+// CheckConnectivity runs a check now with the saved config, enabled or not,
+// and publishes the result when probing is enabled
+func (m *Manager) CheckConnectivity() Connectivity {
+	config := m.GetConnectivityConfig()
+	if config.Method == "" {
+		config, _ = config.normalize()
+	}
+	return m.checkConnectivity(context.Background(), config)
+}
+
+func (m *Manager) checkConnectivity(ctx context.Context, config ConnectivityConfig) Connectivity {
+	m.stateMutex.RLock()
+	online := primaryNetwork(m.state) != "" || len(m.state.VPNActive) > 0
+	m.stateMutex.RUnlock()
+
+	var c Connectivity
+	if online {
+		probe := m.connectivityProbe
+		if probe == nil {
+			probe = probeTCP
+			if config.Method == ConnectivityHTTP {
+				probe = probeHTTP
+			}
+		}
+		c = runConnectivityCheck(ctx, config, probe)
+	} else {
+		c = Connectivity{Method: config.Method, Target: config.Target, Quality: ConnectivityOffline, LossPercent: 100}
+	}
+	c.Enabled = config.Enabled
+	c.CheckedAt = time.Now().UTC().Format(time.RFC3339)
+
+	// Probing may have been turned off while this check ran
+	if config.Enabled && m.GetConnectivityConfig().Enabled {
+		m.stateMutex.Lock()
+		m.state.Connectivity = c
+		m.stateMutex.Unlock()
+		m.notifySubscribers()
+	}
+	return c
+}
+
+// wakeConnectivity has the monitor check now, after a config or network
+// change
+func (m *Manager) wakeConnectivity() {
+	select {
+	case m.connectivityWake <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) connectivityMonitor() {
+	defer m.notifierWg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-m.stopChan
+		cancel()
+	}()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.connectivityWake:
+		case <-timer.C:
+		}
+
+		config := m.GetConnectivityConfig()
+		if config.Enabled {
+			m.checkConnectivity(ctx, config)
+		}
+
+		interval := time.Duration(config.Interval) * time.Second
+		if interval == 0 {
+			interval = defaultConnectivityInterval * time.Second
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(budget.Scale(interval))
+	}
+}
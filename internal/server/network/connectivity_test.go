@@ -0,0 +1,107 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectivityConfigNormalize(t *testing.T) {
+	config, err := ConnectivityConfig{Enabled: true}.normalize()
+	require.NoError(t, err)
+	assert.Equal(t, ConnectivityConfig{Enabled: true, Method: "tcp", Target: "1.1.1.1:443", Interval: 30, Probes: 5}, config)
+
+	config, err = ConnectivityConfig{Method: "http"}.normalize()
+	require.NoError(t, err)
+	assert.Equal(t, defaultConnectivityHTTPTarget, config.Target)
+
+	_, err = ConnectivityConfig{Method: "icmp"}.normalize()
+	assert.ErrorContains(t, err, "invalid connectivity method")
+	_, err = ConnectivityConfig{Target: "1.1.1.1"}.normalize()
+	assert.ErrorContains(t, err, "host:port")
+	_, err = ConnectivityConfig{Method: "http", Target: "example.com"}.normalize()
+	assert.ErrorContains(t, err, "URL")
+	_, err = ConnectivityConfig{Interval: 1}.normalize()
+	assert.ErrorContains(t, err, "interval")
+	_, err = ConnectivityConfig{Probes: 50}.normalize()
+	assert.ErrorContains(t, err, "probes")
+}
+
+func TestSummarizeProbes(t *testing.T) {
+	ms := time.Millisecond
+	c := summarizeProbes([]time.Duration{20 * ms, 30 * ms, 25 * ms, 25 * ms}, 4)
+	assert.Equal(t, 25.0, c.LatencyMs)
+	assert.Equal(t, 5.0, c.JitterMs)
+	assert.Equal(t, 0.0, c.LossPercent)
+	assert.Equal(t, ConnectivityGood, c.Quality)
+
+	c = summarizeProbes([]time.Duration{20 * ms, 30 * ms, 25 * ms, 25 * ms}, 5)
+	assert.Equal(t, 20.0, c.LossPercent)
+	assert.Equal(t, ConnectivityDegraded, c.Quality)
+
+	c = summarizeProbes(nil, 5)
+	assert.Equal(t, 100.0, c.LossPercent)
+	assert.Equal(t, ConnectivityOffline, c.Quality)
+
+	assert.Equal(t, ConnectivityUnknown, summarizeProbes(nil, 0).Quality)
+}
+
+func TestConnectivityQuality(t *testing.T) {
+	assert.Equal(t, ConnectivityGood, connectivityQuality(Connectivity{LatencyMs: 40, JitterMs: 5}))
+	assert.Equal(t, ConnectivityDegraded, connectivityQuality(Connectivity{LatencyMs: 200}))
+	assert.Equal(t, ConnectivityDegraded, connectivityQuality(Connectivity{LatencyMs: 40, JitterMs: 80}))
+	assert.Equal(t, ConnectivityPoor, connectivityQuality(Connectivity{LatencyMs: 40, LossPercent: 40}))
+	assert.Equal(t, ConnectivityPoor, connectivityQuality(Connectivity{LatencyMs: 900}))
+}
+
+func TestCheckConnectivity(t *testing.T) {
+	m := NewTestManager(nil, &NetworkState{EthernetConnected: true})
+	m.connectivityConfig, _ = ConnectivityConfig{Enabled: true, Probes: 2}.normalize()
+
+	calls := 0
+	m.connectivityProbe = func(ctx context.Context, target string) (time.Duration, error) {
+		assert.Equal(t, "1.1.1.1:443", target)
+		calls++
+		if calls == 2 {
+			return 0, errors.New("timeout")
+		}
+		return 40 * time.Millisecond, nil
+	}
+
+	c := m.CheckConnectivity()
+	assert.Equal(t, 2, calls)
+	assert.True(t, c.Enabled)
+	assert.Equal(t, 40.0, c.LatencyMs)
+	assert.Equal(t, 50.0, c.LossPercent)
+	assert.Equal(t, ConnectivityPoor, c.Quality)
+	assert.NotEmpty(t, c.CheckedAt)
+	assert.Equal(t, c, m.snapshotState().Connectivity, "enabled checks are published")
+
+	m.state.EthernetConnected = false
+	c = m.CheckConnectivity()
+	assert.Equal(t, 2, calls, "no probes without a connection")
+	assert.Equal(t, ConnectivityOffline, c.Quality)
+}
+
+func TestSetConnectivityConfigDisable(t *testing.T) {
+	m := NewTestManager(nil, &NetworkState{Connectivity: Connectivity{Enabled: true, Quality: ConnectivityGood}})
+
+	config, err := m.SetConnectivityConfig(ConnectivityConfig{Enabled: false, Method: "http"})
+	require.NoError(t, err)
+	assert.Equal(t, defaultConnectivityHTTPTarget, config.Target)
+	assert.Equal(t, Connectivity{Quality: ConnectivityUnknown}, m.snapshotState().Connectivity)
+
+	_, err = m.SetConnectivityConfig(ConnectivityConfig{Method: "udp"})
+	assert.Error(t, err)
+	assert.Equal(t, "http", m.GetConnectivityConfig().Method, "an invalid config isn't applied")
+}
+
+func TestStateChangedMeaningfully_Connectivity(t *testing.T) {
+	old := &NetworkState{WiFiSignal: 70, Connectivity: Connectivity{Quality: ConnectivityGood}}
+	worse := &NetworkState{WiFiSignal: 72, Connectivity: Connectivity{Quality: ConnectivityPoor}}
+	assert.True(t, stateChangedMeaningfully(old, worse), "a small signal change doesn't hide it")
+}
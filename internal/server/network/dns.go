@@ -0,0 +1,238 @@
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	resolvedDest             = "org.freedesktop.resolve1"
+	resolvedPath             = "/org/freedesktop/resolve1"
+	resolvedManagerInterface = "org.freedesktop.resolve1.Manager"
+)
+
+// DNS-over-TLS modes accepted in DNSConfig.DNSOverTLS. NetworkManager only
+// honours them when systemd-resolved is its DNS backend
+const (
+	DNSOverTLSDefault       = "default"
+	DNSOverTLSNo            = "no"
+	DNSOverTLSOpportunistic = "opportunistic"
+	DNSOverTLSYes           = "yes"
+)
+
+// connection.dns-over-tls values, in NetworkManager's order starting at -1
+var dnsOverTLSModes = []string{DNSOverTLSDefault, DNSOverTLSNo, DNSOverTLSOpportunistic, DNSOverTLSYes}
+
+type parsedDNSConfig struct {
+	IPv4       []net.IP
+	IPv6       []net.IP
+	IgnoreAuto bool
+	DNSOverTLS int32
+}
+
+func parseDNSConfig(cfg DNSConfig) (*parsedDNSConfig, error) {
+	parsed := &parsedDNSConfig{IgnoreAuto: cfg.IgnoreAuto, DNSOverTLS: -1}
+
+	mode := strings.ToLower(cfg.DNSOverTLS)
+	if mode == "" {
+		mode = DNSOverTLSDefault
+	}
+	found := false
+	for i, m := range dnsOverTLSModes {
+		if m == mode {
+			parsed.DNSOverTLS = int32(i - 1)
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("invalid dnsOverTls %q (use %s)", cfg.DNSOverTLS, strings.Join(dnsOverTLSModes, ", "))
+	}
+
+	for _, server := range cfg.Servers {
+		for _, field := range splitDNS(server) {
+			ip := net.ParseIP(field)
+			switch {
+			case ip == nil:
+				return nil, fmt.Errorf("invalid DNS server %q", field)
+			case ip.To4() != nil:
+				parsed.IPv4 = append(parsed.IPv4, ip.To4())
+			default:
+				parsed.IPv6 = append(parsed.IPv6, ip.To16())
+			}
+		}
+	}
+
+	if parsed.IgnoreAuto && len(parsed.IPv4)+len(parsed.IPv6) == 0 {
+		return nil, fmt.Errorf("ignoreAuto needs at least one DNS server")
+	}
+
+	return parsed, nil
+}
+
+// applyDNSSettings writes the DNS servers and DoT mode into a
+// NetworkManager connection. Automatic servers are only ignored for a
+// family that got servers of its own, so an IPv4-only list doesn't leave
+// IPv6 without DNS
+func applyDNSSettings(settings map[string]map[string]interface{}, cfg *parsedDNSConfig) {
+	dropDeprecatedAddressKeys(settings)
+
+	connection, ok := settings["connection"]
+	if !ok {
+		connection = make(map[string]interface{})
+		settings["connection"] = connection
+	}
+	connection["dns-over-tls"] = cfg.DNSOverTLS
+
+	ipv4 := ensureSection(settings, "ipv4")
+	dns4 := make([]uint32, 0, len(cfg.IPv4))
+	for _, ip := range cfg.IPv4 {
+		dns4 = append(dns4, binary.NativeEndian.Uint32(ip))
+	}
+	ipv4["dns"] = dns4
+	ipv4["ignore-auto-dns"] = cfg.IgnoreAuto && len(cfg.IPv4) > 0
+
+	// Disabled IPv6 can't carry DNS settings
+	ipv6 := ensureSection(settings, "ipv6")
+	if method, _ := ipv6["method"].(string); method == "ignore" || method == "disabled" {
+		return
+	}
+	dns6 := make([][]byte, 0, len(cfg.IPv6))
+	for _, ip := range cfg.IPv6 {
+		dns6 = append(dns6, []byte(ip))
+	}
+	ipv6["dns"] = dns6
+	ipv6["ignore-auto-dns"] = cfg.IgnoreAuto && len(cfg.IPv6) > 0
+}
+
+func ensureSection(settings map[string]map[string]interface{}, name string) map[string]interface{} {
+	section, ok := settings[name]
+	if !ok {
+		section = make(map[string]interface{})
+		settings[name] = section
+	}
+	return section
+}
+
+// dnsConfigFromSettings reads the saved DNS overrides of a connection
+func dnsConfigFromSettings(settings map[string]map[string]interface{}) DNSConfig {
+	cfg := DNSConfig{Servers: []string{}, DNSOverTLS: DNSOverTLSDefault}
+
+	if mode, ok := settings["connection"]["dns-over-tls"].(int32); ok && mode >= -1 && int(mode)+1 < len(dnsOverTLSModes) {
+		cfg.DNSOverTLS = dnsOverTLSModes[mode+1]
+	}
+
+	if dns, ok := settings["ipv4"]["dns"].([]uint32); ok {
+		for _, addr := range dns {
+			ip := make(net.IP, 4)
+			binary.NativeEndian.PutUint32(ip, addr)
+			cfg.Servers = append(cfg.Servers, ip.String())
+		}
+	}
+	if dns, ok := settings["ipv6"]["dns"].([][]byte); ok {
+		for _, addr := range dns {
+			if len(addr) == 16 {
+				cfg.Servers = append(cfg.Servers, net.IP(addr).String())
+			}
+		}
+	}
+
+	ignore4, _ := settings["ipv4"]["ignore-auto-dns"].(bool)
+	ignore6, _ := settings["ipv6"]["ignore-auto-dns"].(bool)
+	cfg.IgnoreAuto = ignore4 || ignore6
+
+	return cfg
+}
+
+// normalizeSearchDomains checks and lowercases search domains, dropping
+// duplicates. A leading ~ makes a routing-only domain, which
+// systemd-resolved sends to the connection's servers without searching it,
+// and ~. routes every query there, e.g. over a full-tunnel VPN
+func normalizeSearchDomains(domains []string) ([]string, error) {
+	normalized := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain == "" {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(domain, "~"), ".")
+		if name != "" && !validDomainName(name) {
+			return nil, fmt.Errorf("invalid search domain %q", domain)
+		}
+		if name == "" && domain != "~." {
+			return nil, fmt.Errorf("invalid search domain %q", domain)
+		}
+		if !slices.Contains(normalized, domain) {
+			normalized = append(normalized, domain)
+		}
+	}
+	return normalized, nil
+}
+
+func validDomainName(name string) bool {
+	if len(name) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// applySearchDomains sets dns-search on both address families, except an
+// IPv6 that is off and can't carry DNS settings
+func applySearchDomains(settings map[string]map[string]interface{}, domains []string) {
+	dropDeprecatedAddressKeys(settings)
+
+	ensureSection(settings, "ipv4")["dns-search"] = domains
+	ipv6 := ensureSection(settings, "ipv6")
+	if method, _ := ipv6["method"].(string); method == "ignore" || method == "disabled" {
+		return
+	}
+	ipv6["dns-search"] = domains
+}
+
+// searchDomainsFromSettings merges the search domains of both families
+func searchDomainsFromSettings(settings map[string]map[string]interface{}) []string {
+	domains := []string{}
+	for _, family := range []string{"ipv4", "ipv6"} {
+		search, _ := settings[family]["dns-search"].([]string)
+		for _, domain := range search {
+			if !slices.Contains(domains, domain) {
+				domains = append(domains, domain)
+			}
+		}
+	}
+	return domains
+}
+
+// FlushDNSCache empties systemd-resolved's cache, e.g. after switching
+// between a VPN and the home network left stale answers behind
+func FlushDNSCache() error {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return fmt.Errorf("failed to connect to system bus: %w", err)
+	}
+	call := conn.Object(resolvedDest, resolvedPath).Call(resolvedManagerInterface+".FlushCaches", 0)
+	if call.Err != nil {
+		var dbusErr dbus.Error
+		if errors.As(call.Err, &dbusErr) && dbusErr.Name == "org.freedesktop.DBus.Error.ServiceUnknown" {
+			return fmt.Errorf("systemd-resolved is not running, so there is no DNS cache to flush")
+		}
+		return fmt.Errorf("failed to flush DNS cache: %w", call.Err)
+	}
+	return nil
+}
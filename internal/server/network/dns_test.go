@@ -0,0 +1,118 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDNSConfig(t *testing.T) {
+	parsed, err := parseDNSConfig(DNSConfig{
+		Servers:    []string{"1.1.1.1", "2606:4700:4700::1111; 9.9.9.9"},
+		IgnoreAuto: true,
+		DNSOverTLS: "Opportunistic",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []net.IP{net.ParseIP("1.1.1.1").To4(), net.ParseIP("9.9.9.9").To4()}, parsed.IPv4)
+	assert.Equal(t, []net.IP{net.ParseIP("2606:4700:4700::1111")}, parsed.IPv6)
+	assert.Equal(t, int32(1), parsed.DNSOverTLS)
+
+	parsed, err = parseDNSConfig(DNSConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(-1), parsed.DNSOverTLS)
+}
+
+func TestParseDNSConfig_Invalid(t *testing.T) {
+	_, err := parseDNSConfig(DNSConfig{Servers: []string{"dns.google"}})
+	assert.ErrorContains(t, err, "invalid DNS server")
+
+	_, err = parseDNSConfig(DNSConfig{DNSOverTLS: "strict"})
+	assert.ErrorContains(t, err, "invalid dnsOverTls")
+
+	_, err = parseDNSConfig(DNSConfig{IgnoreAuto: true})
+	assert.ErrorContains(t, err, "at least one DNS server")
+}
+
+func TestApplyDNSSettings_RoundTrip(t *testing.T) {
+	settings := map[string]map[string]interface{}{
+		"connection": {"type": "802-11-wireless", "uuid": "abc"},
+		"ipv4":       {"method": "auto", "addresses": [][]uint32{}},
+		"ipv6":       {"method": "auto"},
+	}
+
+	parsed, err := parseDNSConfig(DNSConfig{Servers: []string{"1.1.1.1", "2606:4700:4700::1111"}, IgnoreAuto: true, DNSOverTLS: "yes"})
+	require.NoError(t, err)
+	applyDNSSettings(settings, parsed)
+
+	assert.NotContains(t, settings["ipv4"], "addresses")
+	assert.Equal(t, int32(2), settings["connection"]["dns-over-tls"])
+	assert.Equal(t, true, settings["ipv4"]["ignore-auto-dns"])
+	assert.Equal(t, true, settings["ipv6"]["ignore-auto-dns"])
+
+	assert.Equal(t, DNSConfig{
+		Servers:    []string{"1.1.1.1", "2606:4700:4700::1111"},
+		IgnoreAuto: true,
+		DNSOverTLS: DNSOverTLSYes,
+	}, dnsConfigFromSettings(settings))
+}
+
+func TestApplyDNSSettings_SingleFamily(t *testing.T) {
+	settings := map[string]map[string]interface{}{
+		"connection": {},
+		"ipv4":       {"method": "auto"},
+		"ipv6":       {"method": "ignore"},
+	}
+
+	parsed, err := parseDNSConfig(DNSConfig{Servers: []string{"9.9.9.9"}, IgnoreAuto: true})
+	require.NoError(t, err)
+	applyDNSSettings(settings, parsed)
+
+	assert.Equal(t, true, settings["ipv4"]["ignore-auto-dns"])
+	assert.NotContains(t, settings["ipv6"], "dns")
+	assert.Equal(t, int32(-1), settings["connection"]["dns-over-tls"])
+}
+
+func TestDNSConfigFromSettings_Empty(t *testing.T) {
+	cfg := dnsConfigFromSettings(map[string]map[string]interface{}{})
+	assert.Equal(t, DNSConfig{Servers: []string{}, DNSOverTLS: DNSOverTLSDefault}, cfg)
+}
+
+func TestNormalizeSearchDomains(t *testing.T) {
+	domains, err := normalizeSearchDomains([]string{" Corp.Example.com ", "~example.net", "corp.example.com", "", "~.", "lab_1.local."})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"corp.example.com", "~example.net", "~.", "lab_1.local."}, domains)
+
+	for _, bad := range []string{"bad domain", "-corp.example.com", "corp..example.com", ".", "~", "~~corp"} {
+		_, err := normalizeSearchDomains([]string{bad})
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestSearchDomains_RoundTrip(t *testing.T) {
+	settings := map[string]map[string]interface{}{
+		"connection": {"type": "vpn"},
+		"ipv6":       {"method": "auto", "dns-search": []string{"old.example.com"}},
+	}
+	applySearchDomains(settings, []string{"corp.example.com", "~example.net"})
+
+	assert.Equal(t, []string{"corp.example.com", "~example.net"}, settings["ipv4"]["dns-search"])
+	assert.Equal(t, []string{"corp.example.com", "~example.net"}, settings["ipv6"]["dns-search"])
+	assert.Equal(t, []string{"corp.example.com", "~example.net"}, searchDomainsFromSettings(settings))
+
+	applySearchDomains(settings, []string{})
+	assert.Equal(t, []string{}, searchDomainsFromSettings(settings))
+}
+
+func TestSearchDomains_IPv6Disabled(t *testing.T) {
+	settings := map[string]map[string]interface{}{
+		"ipv6": {"method": "disabled"},
+	}
+	applySearchDomains(settings, []string{"corp.example.com"})
+
+	_, hasSearch := settings["ipv6"]["dns-search"]
+	assert.False(t, hasSearch)
+	assert.Equal(t, []string{"corp.example.com"}, searchDomainsFromSettings(settings))
+}
@@ -0,0 +1,86 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEAPSettings_DefaultsToPEAP(t *testing.T) {
+	x, err := eapSettings(ConnectionRequest{Username: "alice", Password: "secret"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"peap"}, x["eap"])
+	assert.Equal(t, "mschapv2", x["phase2-auth"])
+	assert.Equal(t, "alice", x["identity"])
+	assert.Equal(t, "secret", x["password"])
+}
+
+func TestEAPSettings_TTLS(t *testing.T) {
+	x, err := eapSettings(ConnectionRequest{
+		Username:   "alice",
+		Password:   "secret",
+		EAPMethod:  "TTLS",
+		Phase2Auth: "pap",
+		CACert:     "/etc/ssl/certs/campus.pem",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"ttls"}, x["eap"])
+	assert.Equal(t, "pap", x["phase2-auth"])
+	assert.Equal(t, []byte("file:///etc/ssl/certs/campus.pem\x00"), x["ca-cert"])
+}
+
+func TestEAPSettings_InvalidPhase2(t *testing.T) {
+	_, err := eapSettings(ConnectionRequest{EAPMethod: "peap", Phase2Auth: "pap"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported phase2 method")
+}
+
+func TestEAPSettings_TLS(t *testing.T) {
+	x, err := eapSettings(ConnectionRequest{
+		Username:           "alice@example.com",
+		EAPMethod:          EAPMethodTLS,
+		ClientCert:         "/home/alice/certs/client.pem",
+		PrivateKey:         "/home/alice/certs/client.key",
+		PrivateKeyPassword: "keypass",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"tls"}, x["eap"])
+	assert.Equal(t, []byte("file:///home/alice/certs/client.pem\x00"), x["client-cert"])
+	assert.Equal(t, []byte("file:///home/alice/certs/client.key\x00"), x["private-key"])
+	assert.Equal(t, "keypass", x["private-key-password"])
+	assert.NotContains(t, x, "phase2-auth")
+	assert.NotContains(t, x, "password")
+}
+
+func TestEAPSettings_TLSRequiresCertificates(t *testing.T) {
+	_, err := eapSettings(ConnectionRequest{Username: "alice", EAPMethod: EAPMethodTLS})
+	assert.Error(t, err)
+
+	_, err = eapSettings(ConnectionRequest{
+		Username:   "alice",
+		EAPMethod:  EAPMethodTLS,
+		ClientCert: "client.pem",
+		PrivateKey: "/home/alice/client.key",
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "must be absolute")
+}
+
+func TestEAPSettings_PWD(t *testing.T) {
+	x, err := eapSettings(ConnectionRequest{Username: "alice", Password: "secret", EAPMethod: EAPMethodPWD})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"pwd"}, x["eap"])
+	assert.Equal(t, "secret", x["password"])
+	assert.NotContains(t, x, "phase2-auth")
+}
+
+func TestEAPSettings_UnknownMethod(t *testing.T) {
+	_, err := eapSettings(ConnectionRequest{EAPMethod: "leap"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported EAP method")
+}
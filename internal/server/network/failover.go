@@ -0,0 +1,189 @@
+package network
+
+import (
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	// daemonSettleDelay gives a daemon that just appeared time to export its
+	// objects before the backend is created
+	daemonSettleDelay    = 2 * time.Second
+	backendRetryInterval = 5 * time.Second
+)
+
+var networkDaemons = []string{dbusNMInterface, iwdBusName, networkdBusName}
+
+// backendDaemons are the bus names a backend talks to
+func backendDaemons(backend Backend) []string {
+	switch backend.(type) {
+	case *NetworkManagerBackend:
+		return []string{dbusNMInterface}
+	case *IWDBackend:
+		return []string{iwdBusName}
+	case *HybridIwdNetworkdBackend:
+		return []string{iwdBusName, networkdBusName}
+	case *SystemdNetworkdBackend:
+		return []string{networkdBusName}
+	}
+	return nil
+}
+
+// detectedDaemons are the bus names the backend detection would pick now
+func detectedDaemons(detection *DetectResult) []string {
+	switch detection.Backend {
+	case BackendNetworkManager:
+		return []string{dbusNMInterface}
+	case BackendIwd:
+		return []string{iwdBusName}
+	case BackendNetworkd:
+		if detection.HasIwd && !detection.HasNM {
+			return []string{iwdBusName, networkdBusName}
+		}
+		return []string{networkdBusName}
+	}
+	return nil
+}
+
+func sameDaemons(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// watchDaemons follows the network daemons on the system bus, so a crashed
+// or restarted daemon, or a different one taking over, gets a new backend
+func (m *Manager) watchDaemons() {
+	conn, err := dbus.ConnectSystemBus(budget.DBusOptions("network")...)
+	if err != nil {
+		log.Warnf("Failed to watch network daemons: %v", err)
+		return
+	}
+	for _, name := range networkDaemons {
+		if err := conn.AddMatchSignal(
+			dbus.WithMatchInterface("org.freedesktop.DBus"),
+			dbus.WithMatchMember("NameOwnerChanged"),
+			dbus.WithMatchArg(0, name),
+		); err != nil {
+			log.Warnf("Failed to watch %s: %v", name, err)
+			conn.Close()
+			return
+		}
+	}
+
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+
+	m.notifierWg.Add(1)
+	go m.daemonWatcher(conn, signals)
+}
+
+func (m *Manager) daemonWatcher(conn *dbus.Conn, signals chan *dbus.Signal) {
+	defer m.notifierWg.Done()
+	defer conn.Close()
+	defer conn.RemoveSignal(signals)
+
+	var retry <-chan time.Time
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case sig, ok := <-signals:
+			if !ok {
+				return
+			}
+			if sig == nil || sig.Name != "org.freedesktop.DBus.NameOwnerChanged" || len(sig.Body) < 3 {
+				continue
+			}
+			name, _ := sig.Body[0].(string)
+			owner, _ := sig.Body[2].(string)
+
+			if owner == "" {
+				if !m.usesDaemon(name) {
+					continue
+				}
+				log.Warnf("Network daemon %s stopped, waiting for it to come back", name)
+				m.setBackendRestarting(true)
+			}
+			retry = time.After(daemonSettleDelay)
+		case <-retry:
+			retry = nil
+			if err := m.reselectBackend(); err != nil {
+				log.Warnf("Failed to reinitialize network backend: %v", err)
+				if m.backendRestarting() {
+					retry = time.After(backendRetryInterval)
+				}
+			}
+		}
+	}
+}
+
+func (m *Manager) usesDaemon(name string) bool {
+	for _, daemon := range backendDaemons(m.currentBackend()) {
+		if daemon == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manager) backendRestarting() bool {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	return m.state.BackendRestarting
+}
+
+func (m *Manager) setBackendRestarting(restarting bool) {
+	m.stateMutex.Lock()
+	m.state.BackendRestarting = restarting
+	m.stateMutex.Unlock()
+	m.notifySubscribers()
+}
+
+// reselectBackend replaces the backend when its daemon went away or the
+// daemons running now call for a different one
+func (m *Manager) reselectBackend() error {
+	if !m.backendRestarting() {
+		detection, err := DetectNetworkStack()
+		if err != nil {
+			return err
+		}
+		if sameDaemons(detectedDaemons(detection), backendDaemons(m.currentBackend())) {
+			return nil
+		}
+	}
+
+	backend, err := selectBackend()
+	if err != nil {
+		return err
+	}
+	if err := m.prepareBackend(backend); err != nil {
+		backend.Close()
+		return err
+	}
+
+	m.backendMutex.Lock()
+	old := m.backend
+	m.backend = backend
+	m.backendMutex.Unlock()
+	if old != nil {
+		old.Close()
+	}
+
+	if err := backend.StartMonitoring(m.onBackendStateChange); err != nil {
+		return err
+	}
+	m.setBackendRestarting(false)
+	m.onBackendStateChange()
+	log.Infof("Network backend reinitialized (%s)", m.snapshotState().Backend)
+	return nil
+}
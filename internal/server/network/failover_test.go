@@ -0,0 +1,50 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackendDaemons(t *testing.T) {
+	assert.Equal(t, []string{dbusNMInterface}, backendDaemons(&NetworkManagerBackend{}))
+	assert.Equal(t, []string{iwdBusName}, backendDaemons(&IWDBackend{}))
+	assert.Equal(t, []string{iwdBusName, networkdBusName}, backendDaemons(&HybridIwdNetworkdBackend{}))
+	assert.Equal(t, []string{networkdBusName}, backendDaemons(&SystemdNetworkdBackend{}))
+	assert.Nil(t, backendDaemons(nil))
+}
+
+func TestDetectedDaemonsMatchBackend(t *testing.T) {
+	tests := []struct {
+		detection DetectResult
+		backend   Backend
+	}{
+		{DetectResult{Backend: BackendNetworkManager, HasNM: true, HasIwd: true}, &NetworkManagerBackend{}},
+		{DetectResult{Backend: BackendIwd, HasIwd: true}, &IWDBackend{}},
+		{DetectResult{Backend: BackendNetworkd, HasIwd: true, HasNetworkd: true}, &HybridIwdNetworkdBackend{}},
+		{DetectResult{Backend: BackendNetworkd, HasNetworkd: true}, &SystemdNetworkdBackend{}},
+	}
+	for _, tt := range tests {
+		assert.True(t, sameDaemons(detectedDaemons(&tt.detection), backendDaemons(tt.backend)))
+	}
+
+	// NetworkManager came back while iwd was standing in for it
+	nm := DetectResult{Backend: BackendNetworkManager, HasNM: true, HasIwd: true}
+	assert.False(t, sameDaemons(detectedDaemons(&nm), backendDaemons(&IWDBackend{})))
+}
+
+func TestUsesDaemon(t *testing.T) {
+	m := &Manager{backend: &HybridIwdNetworkdBackend{}}
+	assert.True(t, m.usesDaemon(iwdBusName))
+	assert.True(t, m.usesDaemon(networkdBusName))
+	assert.False(t, m.usesDaemon(dbusNMInterface))
+}
+
+func TestSetBackendRestartingNotifies(t *testing.T) {
+	m := &Manager{state: &NetworkState{}, dirty: make(chan struct{}, 1)}
+	m.setBackendRestarting(true)
+
+	assert.True(t, m.backendRestarting())
+	assert.True(t, m.snapshotState().BackendRestarting)
+	assert.Len(t, m.dirty, 1)
+}
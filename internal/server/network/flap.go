@@ -0,0 +1,117 @@
+package network
+
+import (
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+const (
+	// flapGrace is how long a drop is hidden: a link back within it never
+	// shows as disconnected, so the shell doesn't flash
+	flapGrace = 3 * time.Second
+	// flapWindow is how far back drops count towards FlapCount
+	flapWindow = 5 * time.Minute
+	// The link turns unstable at unstableDrops drops in the window and only
+	// calms down once the window has no drops left
+	unstableDrops = 3
+)
+
+// flapTracker debounces the reported status and counts how often the link
+// dropped. Its zero value is ready to use; the manager guards it with
+// stateMutex
+type flapTracker struct {
+	online       bool
+	drops        []time.Time
+	offlineSince time.Time
+	reported     NetworkStatus
+	unstable     bool
+}
+
+// observe takes the backend's status and returns the one to report. recheck
+// is when observe must run again without a new status: the end of the grace
+// period, or the oldest drop leaving the window
+func (f *flapTracker) observe(status NetworkStatus, now time.Time) (reported NetworkStatus, recheck time.Duration) {
+	online := status != StatusDisconnected
+	if f.online && !online {
+		f.drops = append(f.drops, now)
+		f.offlineSince = now
+	}
+	f.online = online
+
+	for len(f.drops) > 0 && now.Sub(f.drops[0]) >= flapWindow {
+		f.drops = f.drops[1:]
+	}
+
+	switch {
+	case online:
+		f.reported = status
+	case f.reported != "" && f.reported != StatusDisconnected && now.Sub(f.offlineSince) < flapGrace:
+		recheck = flapGrace - now.Sub(f.offlineSince)
+	default:
+		f.reported = StatusDisconnected
+	}
+
+	switch {
+	case len(f.drops) >= unstableDrops:
+		f.unstable = true
+	case len(f.drops) == 0:
+		f.unstable = false
+	}
+
+	if len(f.drops) > 0 {
+		if expire := flapWindow - now.Sub(f.drops[0]); recheck == 0 || expire < recheck {
+			recheck = expire
+		}
+	}
+	return f.reported, recheck
+}
+
+func (f *flapTracker) count() int {
+	return len(f.drops)
+}
+
+// applyFlapDebounce sets the reported status and flap fields. While a drop
+// is hidden the link fields keep their previous values too, so nothing in
+// the shell flips to disconnected and back. Called with stateMutex held
+func (m *Manager) applyFlapDebounce(status NetworkStatus, prev *NetworkState) {
+	reported, recheck := m.flaps.observe(status, time.Now())
+	if reported != status {
+		m.state.EthernetIP = prev.EthernetIP
+		m.state.EthernetConnected = prev.EthernetConnected
+		m.state.WiFiIP = prev.WiFiIP
+		m.state.WiFiConnected = prev.WiFiConnected
+		m.state.WiFiSSID = prev.WiFiSSID
+		m.state.WiFiBSSID = prev.WiFiBSSID
+		m.state.WiFiSignal = prev.WiFiSignal
+	}
+	m.state.NetworkStatus = reported
+	m.state.Unstable = m.flaps.unstable
+	m.state.FlapCount = m.flaps.count()
+	m.scheduleFlapRecheck(recheck)
+}
+
+// scheduleFlapRecheck re-runs the tracker when a hidden drop's grace ends or
+// a drop ages out, so the status and unstable flag don't wait for the next
+// backend event. Called with stateMutex held
+func (m *Manager) scheduleFlapRecheck(after time.Duration) {
+	if m.flapTimer != nil {
+		m.flapTimer.Stop()
+		m.flapTimer = nil
+	}
+	if after <= 0 {
+		return
+	}
+	m.flapTimer = time.AfterFunc(after, func() {
+		select {
+		case <-m.stopChan:
+			return
+		default:
+		}
+		if err := m.syncStateFromBackend(); err != nil {
+			log.Warnf("network: failed to recheck link state: %v", err)
+			return
+		}
+		m.notifySubscribers()
+	})
+}
@@ -0,0 +1,78 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlapTracker_HidesShortDrop(t *testing.T) {
+	var f flapTracker
+	now := time.Unix(1_700_000_000, 0)
+
+	status, recheck := f.observe(StatusWiFi, now)
+	assert.Equal(t, StatusWiFi, status)
+	assert.Zero(t, recheck)
+
+	status, recheck = f.observe(StatusDisconnected, now.Add(time.Second))
+	assert.Equal(t, StatusWiFi, status, "a drop within the grace period is hidden")
+	assert.Equal(t, flapGrace, recheck)
+	assert.Equal(t, 1, f.count())
+
+	status, _ = f.observe(StatusWiFi, now.Add(2*time.Second))
+	assert.Equal(t, StatusWiFi, status)
+	assert.False(t, f.unstable)
+}
+
+func TestFlapTracker_ReportsLongDrop(t *testing.T) {
+	var f flapTracker
+	now := time.Unix(1_700_000_000, 0)
+
+	f.observe(StatusEthernet, now)
+	f.observe(StatusDisconnected, now.Add(time.Second))
+	status, recheck := f.observe(StatusDisconnected, now.Add(time.Second+flapGrace))
+	assert.Equal(t, StatusDisconnected, status)
+	assert.Equal(t, flapWindow-flapGrace, recheck, "rechecks when the drop leaves the window")
+}
+
+func TestFlapTracker_UnstableHysteresis(t *testing.T) {
+	var f flapTracker
+	now := time.Unix(1_700_000_000, 0)
+
+	for i := range unstableDrops {
+		at := now.Add(time.Duration(i) * time.Minute)
+		f.observe(StatusWiFi, at)
+		f.observe(StatusDisconnected, at.Add(time.Second))
+	}
+	f.observe(StatusWiFi, now.Add(3*time.Minute))
+	assert.True(t, f.unstable)
+	assert.Equal(t, unstableDrops, f.count())
+
+	f.observe(StatusWiFi, now.Add(flapWindow+time.Minute+time.Second))
+	assert.Equal(t, 1, f.count())
+	assert.True(t, f.unstable, "stays unstable while drops remain in the window")
+
+	f.observe(StatusWiFi, now.Add(flapWindow+3*time.Minute))
+	assert.Zero(t, f.count())
+	assert.False(t, f.unstable)
+}
+
+func TestApplyFlapDebounce_KeepsLinkFields(t *testing.T) {
+	m := &Manager{state: &NetworkState{}}
+	m.state.NetworkStatus = StatusWiFi
+	m.state.WiFiConnected = true
+	m.state.WiFiSSID = "Home"
+	m.flaps.observe(StatusWiFi, time.Now())
+
+	prev := *m.state
+	m.state.WiFiConnected = false
+	m.state.WiFiSSID = ""
+	m.applyFlapDebounce(StatusDisconnected, &prev)
+	defer m.scheduleFlapRecheck(0)
+
+	assert.Equal(t, StatusWiFi, m.state.NetworkStatus)
+	assert.True(t, m.state.WiFiConnected)
+	assert.Equal(t, "Home", m.state.WiFiSSID)
+	assert.Equal(t, 1, m.state.FlapCount)
+}
@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/AvengeMedia/danklinux/internal/log"
 	"github.com/AvengeMedia/danklinux/internal/server/models"
@@ -30,24 +33,80 @@ func HandleRequest(conn net.Conn, req Request, manager *Manager) {
 		handleGetWiFiNetworks(conn, req, manager)
 	case "network.wifi.connect":
 		handleConnectWiFi(conn, req, manager)
+	case "network.wifi.cancel":
+		handleCancelConnect(conn, req, manager)
 	case "network.wifi.disconnect":
 		handleDisconnectWiFi(conn, req, manager)
 	case "network.wifi.forget":
 		handleForgetWiFi(conn, req, manager)
+	case "network.wifi.saved":
+		handleListSavedWiFi(conn, req, manager)
+	case "network.wifi.saved.forget":
+		handleForgetSavedWiFi(conn, req, manager)
+	case "network.wifi.saved.export":
+		handleExportSavedWiFi(conn, req, manager)
+	case "network.bundle.export":
+		handleExportBundle(conn, req, manager)
+	case "network.bundle.import":
+		handleImportBundle(conn, req, manager)
+	case "network.wifi.known":
+		handleListKnownNetworks(conn, req, manager)
+	case "network.wifi.autoconnect":
+		handleSetKnownNetworkAutoConnect(conn, req, manager)
+	case "network.wifi.wps.start":
+		handleStartWPS(conn, req, manager)
+	case "network.wifi.wps.cancel":
+		handleCancelWPS(conn, req, manager)
+	case "network.wifi.selectDevice":
+		handleSelectWiFiDevice(conn, req, manager)
 	case "network.wifi.toggle":
 		handleToggleWiFi(conn, req, manager)
 	case "network.wifi.enable":
 		handleEnableWiFi(conn, req, manager)
 	case "network.wifi.disable":
 		handleDisableWiFi(conn, req, manager)
+	case "network.hotspot.start":
+		handleStartHotspot(conn, req, manager)
+	case "network.hotspot.stop":
+		handleStopHotspot(conn, req, manager)
+	case "network.hotspot.qr":
+		handleHotspotQR(conn, req, manager)
 	case "network.ethernet.connect.config":
 		handleConnectEthernetSpecificConfig(conn, req, manager)
 	case "network.ethernet.connect":
 		handleConnectEthernet(conn, req, manager)
 	case "network.ethernet.disconnect":
 		handleDisconnectEthernet(conn, req, manager)
+	case "network.ethernet.setIPConfig":
+		handleSetWiredIPConfig(conn, req, manager)
+	case "network.dns.set":
+		handleSetConnectionDNS(conn, req, manager)
+	case "network.dns.flush":
+		handleFlushDNSCache(conn, req)
+	case "network.dns.search.get":
+		handleGetSearchDomains(conn, req, manager)
+	case "network.dns.search.set":
+		handleSetSearchDomains(conn, req, manager)
+	case "network.metered.get":
+		handleGetMetered(conn, req, manager)
+	case "network.metered.set":
+		handleSetMetered(conn, req, manager)
+	case "network.ipv6.get":
+		handleGetIPv6Config(conn, req, manager)
+	case "network.ipv6.set":
+		handleSetIPv6Config(conn, req, manager)
+	case "network.wol.get":
+		handleGetWakeOnLAN(conn, req, manager)
+	case "network.wol.set":
+		handleSetWakeOnLAN(conn, req, manager)
+	case "network.wol.send":
+		handleSendMagicPacket(conn, req)
 	case "network.preference.set":
 		handleSetPreference(conn, req, manager)
+	case "network.wifi.signalHistory":
+		handleGetSignalHistory(conn, req, manager)
+	case "network.wifi.qr":
+		handleWiFiQR(conn, req, manager)
 	case "network.info":
 		handleGetNetworkInfo(conn, req, manager)
 	case "network.ethernet.info":
@@ -58,6 +117,8 @@ func HandleRequest(conn net.Conn, req Request, manager *Manager) {
 		handleCredentialsSubmit(conn, req, manager)
 	case "network.credentials.cancel":
 		handleCredentialsCancel(conn, req, manager)
+	case "network.credentials.claim":
+		handleCredentialsClaim(conn, req, manager)
 	case "network.vpn.profiles":
 		handleListVPNProfiles(conn, req, manager)
 	case "network.vpn.active":
@@ -70,6 +131,18 @@ func HandleRequest(conn net.Conn, req Request, manager *Manager) {
 		handleDisconnectAllVPN(conn, req, manager)
 	case "network.vpn.clearCredentials":
 		handleClearVPNCredentials(conn, req, manager)
+	case "network.vpn.import":
+		handleImportOpenVPN(conn, req, manager)
+	case "network.connectivity.get":
+		models.Respond(conn, req.ID, manager.GetConnectivityConfig())
+	case "network.connectivity.set":
+		handleSetConnectivity(conn, req, manager)
+	case "network.connectivity.check":
+		models.Respond(conn, req.ID, manager.CheckConnectivity())
+	case "network.vpn.policy.get":
+		models.Respond(conn, req.ID, manager.GetVPNPolicy())
+	case "network.vpn.policy.set":
+		handleSetVPNPolicy(conn, req, manager)
 	default:
 		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
 	}
@@ -102,6 +175,11 @@ func handleCredentialsSubmit(conn net.Conn, req Request, manager *Manager) {
 		save = saveParam
 	}
 
+	if err := manager.ClaimCredentials(token, credentialsClient(conn, req)); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
 	if err := manager.SubmitCredentials(token, secrets, save); err != nil {
 		log.Warnf("handleCredentialsSubmit: failed to submit credentials: %v", err)
 		models.RespondError(conn, req.ID, err.Error())
@@ -119,6 +197,11 @@ func handleCredentialsCancel(conn net.Conn, req Request, manager *Manager) {
 		return
 	}
 
+	if err := manager.ClaimCredentials(token, credentialsClient(conn, req)); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
 	if err := manager.CancelCredentials(token); err != nil {
 		models.RespondError(conn, req.ID, err.Error())
 		return
@@ -127,6 +210,30 @@ func handleCredentialsCancel(conn net.Conn, req Request, manager *Manager) {
 	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "credentials cancelled"})
 }
 
+func handleCredentialsClaim(conn net.Conn, req Request, manager *Manager) {
+	token, ok := req.Params["token"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'token' parameter")
+		return
+	}
+
+	if err := manager.ClaimCredentials(token, credentialsClient(conn, req)); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "credentials claimed"})
+}
+
+// credentialsClient names who answers a prompt: the client param, for UIs
+// that send requests over more than one connection, or else the connection
+func credentialsClient(conn net.Conn, req Request) string {
+	if client, ok := req.Params["client"].(string); ok && client != "" {
+		return client
+	}
+	return fmt.Sprintf("client-%p", conn)
+}
+
 func handleGetState(conn net.Conn, req Request, manager *Manager) {
 	state := manager.GetState()
 	models.Respond(conn, req.ID, state)
@@ -161,6 +268,9 @@ func handleConnectWiFi(conn net.Conn, req Request, manager *Manager) {
 	if username, ok := req.Params["username"].(string); ok {
 		connReq.Username = username
 	}
+	if hidden, ok := req.Params["hidden"].(bool); ok {
+		connReq.Hidden = hidden
+	}
 
 	if interactive, ok := req.Params["interactive"].(bool); ok {
 		connReq.Interactive = interactive
@@ -182,11 +292,11 @@ func handleConnectWiFi(conn net.Conn, req Request, manager *Manager) {
 		}
 	}
 
-	if anonymousIdentity, ok := req.Params["anonymousIdentity"].(string); ok {
-		connReq.AnonymousIdentity = anonymousIdentity
-	}
-	if domainSuffixMatch, ok := req.Params["domainSuffixMatch"].(string); ok {
-		connReq.DomainSuffixMatch = domainSuffixMatch
+	eapParams(req, &connReq)
+	connReq.BSSID, _ = req.Params["bssid"].(string)
+	connReq.Band, _ = req.Params["band"].(string)
+	if timeout, ok := req.Params["timeout"].(float64); ok {
+		connReq.Timeout = int(timeout)
 	}
 
 	if err := manager.ConnectWiFi(connReq); err != nil {
@@ -197,6 +307,15 @@ func handleConnectWiFi(conn net.Conn, req Request, manager *Manager) {
 	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "connecting"})
 }
 
+func handleCancelConnect(conn net.Conn, req Request, manager *Manager) {
+	ssid, _ := req.Params["ssid"].(string)
+	if err := manager.CancelConnect(ssid); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "connection canceled"})
+}
+
 func handleDisconnectWiFi(conn net.Conn, req Request, manager *Manager) {
 	if err := manager.DisconnectWiFi(); err != nil {
 		models.RespondError(conn, req.ID, err.Error())
@@ -220,6 +339,221 @@ func handleForgetWiFi(conn net.Conn, req Request, manager *Manager) {
 	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "forgotten"})
 }
 
+func handleListSavedWiFi(conn net.Conn, req Request, manager *Manager) {
+	networks, err := manager.ListSavedWiFiNetworks()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, networks)
+}
+
+func handleForgetSavedWiFi(conn net.Conn, req Request, manager *Manager) {
+	all, _ := req.Params["all"].(bool)
+	var ssids []string
+	if rawSSIDs, ok := req.Params["ssids"].([]interface{}); ok {
+		for _, raw := range rawSSIDs {
+			ssid, ok := raw.(string)
+			if !ok {
+				models.RespondError(conn, req.ID, "'ssids' must be a list of strings")
+				return
+			}
+			ssids = append(ssids, ssid)
+		}
+	}
+	if !all && len(ssids) == 0 {
+		models.RespondError(conn, req.ID, "missing 'ssids' parameter (or 'all': true to forget every saved network)")
+		return
+	}
+
+	result, err := manager.ForgetSavedWiFiNetworks(ssids, all)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, result)
+}
+
+func handleExportSavedWiFi(conn net.Conn, req Request, manager *Manager) {
+	withSecrets, _ := req.Params["secrets"].(bool)
+
+	profiles, err := manager.ExportSavedWiFiNetworks(withSecrets)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, profiles)
+}
+
+func handleExportBundle(conn net.Conn, req Request, manager *Manager) {
+	path, ok := req.Params["path"].(string)
+	if !ok || !filepath.IsAbs(path) {
+		models.RespondError(conn, req.ID, "missing or invalid 'path' parameter (absolute path for the bundle)")
+		return
+	}
+	passphrase, _ := req.Params["passphrase"].(string)
+
+	manifest, err := manager.ExportBundle(path, passphrase)
+	if err != nil {
+		log.Warnf("handleExportBundle: failed: %v", err)
+		models.RespondError(conn, req.ID, fmt.Sprintf("failed to export bundle: %v", err))
+		return
+	}
+	models.Respond(conn, req.ID, manifest)
+}
+
+func handleImportBundle(conn net.Conn, req Request, manager *Manager) {
+	path, ok := req.Params["path"].(string)
+	if !ok || !filepath.IsAbs(path) {
+		models.RespondError(conn, req.ID, "missing or invalid 'path' parameter (absolute path to a bundle)")
+		return
+	}
+	passphrase, _ := req.Params["passphrase"].(string)
+	onConflict, _ := req.Params["onConflict"].(string)
+
+	result, err := manager.ImportBundle(path, passphrase, onConflict)
+	if err != nil {
+		log.Warnf("handleImportBundle: failed: %v", err)
+		models.RespondError(conn, req.ID, fmt.Sprintf("failed to import bundle: %v", err))
+		return
+	}
+	models.Respond(conn, req.ID, result)
+}
+
+func handleListKnownNetworks(conn net.Conn, req Request, manager *Manager) {
+	networks, err := manager.ListKnownNetworks()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, networks)
+}
+
+func handleSetKnownNetworkAutoConnect(conn net.Conn, req Request, manager *Manager) {
+	ssid, ok := req.Params["ssid"].(string)
+	if !ok || ssid == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'ssid' parameter")
+		return
+	}
+
+	enabled, ok := req.Params["enabled"].(bool)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'enabled' parameter")
+		return
+	}
+
+	if err := manager.SetKnownNetworkAutoConnect(ssid, enabled); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "autoconnect updated"})
+}
+
+type WPSResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Pin     string `json:"pin,omitempty"`
+}
+
+func handleStartWPS(conn net.Conn, req Request, manager *Manager) {
+	method, _ := req.Params["method"].(string)
+	if method == "" {
+		method = WPSPushButton
+	}
+	pin, _ := req.Params["pin"].(string)
+
+	pin, err := manager.StartWPS(method, pin)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, WPSResult{Success: true, Message: "wps started", Pin: pin})
+}
+
+func handleCancelWPS(conn net.Conn, req Request, manager *Manager) {
+	if err := manager.CancelWPS(); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "wps canceled"})
+}
+
+func handleGetSignalHistory(conn net.Conn, req Request, manager *Manager) {
+	var window time.Duration
+	if minutes, ok := req.Params["minutes"].(float64); ok {
+		if minutes <= 0 {
+			models.RespondError(conn, req.ID, "'minutes' must be positive")
+			return
+		}
+		window = time.Duration(minutes * float64(time.Minute))
+	}
+
+	models.Respond(conn, req.ID, manager.GetSignalHistory(window))
+}
+
+func handleWiFiQR(conn net.Conn, req Request, manager *Manager) {
+	ssid, ok := req.Params["ssid"].(string)
+	if !ok || ssid == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'ssid' parameter")
+		return
+	}
+
+	qr, err := manager.ShareWiFi(ssid)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, qr)
+}
+
+func handleSelectWiFiDevice(conn net.Conn, req Request, manager *Manager) {
+	device, ok := req.Params["device"].(string)
+	if !ok || device == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'device' parameter")
+		return
+	}
+
+	if err := manager.SelectWiFiDevice(device); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "selected"})
+}
+
+func handleStartHotspot(conn net.Conn, req Request, manager *Manager) {
+	ssid, ok := req.Params["ssid"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'ssid' parameter")
+		return
+	}
+	password, _ := req.Params["password"].(string)
+	band, _ := req.Params["band"].(string)
+
+	if err := manager.StartHotspot(ssid, password, band); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "hotspot started"})
+}
+
+func handleStopHotspot(conn net.Conn, req Request, manager *Manager) {
+	if err := manager.StopHotspot(); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "hotspot stopped"})
+}
+
 func handleToggleWiFi(conn net.Conn, req Request, manager *Manager) {
 	if err := manager.ToggleWiFi(); err != nil {
 		models.RespondError(conn, req.ID, err.Error())
@@ -252,15 +586,299 @@ func handleConnectEthernetSpecificConfig(conn net.Conn, req Request, manager *Ma
 		models.RespondError(conn, req.ID, "missing or invalid 'uuid' parameter")
 		return
 	}
-	if err := manager.activateConnection(uuid); err != nil {
+	device, _ := req.Params["device"].(string)
+	if err := manager.activateConnection(uuid, device); err != nil {
 		models.RespondError(conn, req.ID, err.Error())
 		return
 	}
 	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "connecting"})
 }
 
+func handleSetWiredIPConfig(conn net.Conn, req Request, manager *Manager) {
+	uuid, ok := req.Params["uuid"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'uuid' parameter")
+		return
+	}
+	method, ok := req.Params["method"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'method' parameter")
+		return
+	}
+
+	config := WiredIPConfig{Method: method}
+	if ips, ok := req.Params["ips"].([]interface{}); ok {
+		for _, ip := range ips {
+			if s, ok := ip.(string); ok {
+				config.IPs = append(config.IPs, s)
+			}
+		}
+	}
+	config.Gateway, _ = req.Params["gateway"].(string)
+	config.DNS = strings.Join(stringListParam(req.Params["dns"]), ", ")
+
+	if err := manager.SetWiredIPConfig(uuid, config); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "IP configuration updated"})
+}
+
+// stringListParam accepts either a JSON array of strings or a single string
+func stringListParam(param interface{}) []string {
+	switch value := param.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		var values []string
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func handleSetConnectionDNS(conn net.Conn, req Request, manager *Manager) {
+	id := connectionIDParam(req)
+	if id == "" {
+		models.RespondError(conn, req.ID, "missing 'uuid' or 'ssid' parameter")
+		return
+	}
+
+	config := DNSConfig{Servers: stringListParam(req.Params["servers"])}
+	config.IgnoreAuto, _ = req.Params["ignoreAuto"].(bool)
+	config.DNSOverTLS, _ = req.Params["dnsOverTls"].(string)
+
+	if err := manager.SetConnectionDNS(id, config); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "DNS configuration updated"})
+}
+
+func handleFlushDNSCache(conn net.Conn, req Request) {
+	if err := FlushDNSCache(); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "DNS cache flushed"})
+}
+
+func handleGetSearchDomains(conn net.Conn, req Request, manager *Manager) {
+	id := connectionIDParam(req)
+	if id == "" {
+		models.RespondError(conn, req.ID, "missing 'uuid' or 'ssid' parameter")
+		return
+	}
+
+	domains, err := manager.GetSearchDomains(id)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, map[string][]string{"domains": domains})
+}
+
+func handleSetSearchDomains(conn net.Conn, req Request, manager *Manager) {
+	id := connectionIDParam(req)
+	if id == "" {
+		models.RespondError(conn, req.ID, "missing 'uuid' or 'ssid' parameter")
+		return
+	}
+
+	if err := manager.SetSearchDomains(id, stringListParam(req.Params["domains"])); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "search domains updated"})
+}
+
+func connectionIDParam(req Request) string {
+	id, _ := req.Params["uuid"].(string)
+	if id == "" {
+		id, _ = req.Params["ssid"].(string)
+	}
+	return id
+}
+
+func handleGetMetered(conn net.Conn, req Request, manager *Manager) {
+	id := connectionIDParam(req)
+	if id == "" {
+		models.RespondError(conn, req.ID, "missing 'uuid' or 'ssid' parameter")
+		return
+	}
+
+	info, err := manager.GetMetered(id)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, info)
+}
+
+func handleSetMetered(conn net.Conn, req Request, manager *Manager) {
+	id := connectionIDParam(req)
+	if id == "" {
+		models.RespondError(conn, req.ID, "missing 'uuid' or 'ssid' parameter")
+		return
+	}
+
+	var mode string
+	switch value := req.Params["metered"].(type) {
+	case string:
+		mode = value
+	case bool:
+		mode = MeteredNo
+		if value {
+			mode = MeteredYes
+		}
+	default:
+		models.RespondError(conn, req.ID, "missing or invalid 'metered' parameter (auto, yes or no)")
+		return
+	}
+
+	if err := manager.SetMetered(id, mode); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "metered setting updated"})
+}
+
+func handleGetIPv6Config(conn net.Conn, req Request, manager *Manager) {
+	id := connectionIDParam(req)
+	if id == "" {
+		models.RespondError(conn, req.ID, "missing 'uuid' or 'ssid' parameter")
+		return
+	}
+
+	config, err := manager.GetIPv6Config(id)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, config)
+}
+
+func handleSetIPv6Config(conn net.Conn, req Request, manager *Manager) {
+	id := connectionIDParam(req)
+	if id == "" {
+		models.RespondError(conn, req.ID, "missing 'uuid' or 'ssid' parameter")
+		return
+	}
+
+	var config IPv6Config
+	config.Method, _ = req.Params["method"].(string)
+	config.AddrGenMode, _ = req.Params["addrGenMode"].(string)
+	switch value := req.Params["privacy"].(type) {
+	case string:
+		config.Privacy = value
+	case bool:
+		config.Privacy = "disabled"
+		if value {
+			config.Privacy = "prefer-temporary"
+		}
+	}
+	if config == (IPv6Config{}) {
+		models.RespondError(conn, req.ID, "nothing to set: give 'method', 'privacy' or 'addrGenMode'")
+		return
+	}
+
+	if err := manager.SetIPv6Config(id, config); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "IPv6 settings updated"})
+}
+
+func handleGetWakeOnLAN(conn net.Conn, req Request, manager *Manager) {
+	uuid, ok := req.Params["uuid"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'uuid' parameter")
+		return
+	}
+
+	config, err := manager.GetWakeOnLAN(uuid)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, config)
+}
+
+func handleSetWakeOnLAN(conn net.Conn, req Request, manager *Manager) {
+	uuid, ok := req.Params["uuid"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'uuid' parameter")
+		return
+	}
+
+	rawModes, ok := req.Params["modes"].([]interface{})
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'modes' parameter")
+		return
+	}
+	config := WakeOnLANConfig{Modes: []string{}}
+	for _, raw := range rawModes {
+		mode, ok := raw.(string)
+		if !ok {
+			models.RespondError(conn, req.ID, "'modes' must be a list of strings")
+			return
+		}
+		config.Modes = append(config.Modes, mode)
+	}
+	config.Password, _ = req.Params["password"].(string)
+
+	if err := manager.SetWakeOnLAN(uuid, config); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "wake-on-lan updated"})
+}
+
+func handleSendMagicPacket(conn net.Conn, req Request) {
+	mac, ok := req.Params["mac"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'mac' parameter")
+		return
+	}
+	broadcast, _ := req.Params["broadcast"].(string)
+
+	if err := SendMagicPacket(mac, broadcast); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "magic packet sent"})
+}
+
+// eapParams reads the 802.1X fields shared by WiFi and wired requests
+func eapParams(req Request, connReq *ConnectionRequest) {
+	connReq.AnonymousIdentity, _ = req.Params["anonymousIdentity"].(string)
+	connReq.DomainSuffixMatch, _ = req.Params["domainSuffixMatch"].(string)
+	connReq.EAPMethod, _ = req.Params["eapMethod"].(string)
+	connReq.Phase2Auth, _ = req.Params["phase2Auth"].(string)
+	connReq.CACert, _ = req.Params["caCert"].(string)
+	connReq.ClientCert, _ = req.Params["clientCert"].(string)
+	connReq.PrivateKey, _ = req.Params["privateKey"].(string)
+	connReq.PrivateKeyPassword, _ = req.Params["privateKeyPassword"].(string)
+}
+
+// handleConnectEthernet brings up the wired port, with 802.1X when an EAP
+// method or identity is given
 func handleConnectEthernet(conn net.Conn, req Request, manager *Manager) {
-	if err := manager.ConnectEthernet(); err != nil {
+	var auth *ConnectionRequest
+	eapMethod, _ := req.Params["eapMethod"].(string)
+	username, _ := req.Params["username"].(string)
+	if eapMethod != "" || username != "" {
+		auth = &ConnectionRequest{Username: username}
+		auth.Password, _ = req.Params["password"].(string)
+		auth.Interactive, _ = req.Params["interactive"].(bool)
+		eapParams(req, auth)
+	}
+
+	if err := manager.ConnectEthernet(auth); err != nil {
 		models.RespondError(conn, req.ID, err.Error())
 		return
 	}
@@ -462,3 +1080,83 @@ func handleClearVPNCredentials(conn net.Conn, req Request, manager *Manager) {
 
 	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "VPN credentials cleared"})
 }
+
+func handleImportOpenVPN(conn net.Conn, req Request, manager *Manager) {
+	path, ok := req.Params["path"].(string)
+	if !ok || !filepath.IsAbs(path) {
+		models.RespondError(conn, req.ID, "missing or invalid 'path' parameter (absolute path to a .ovpn file)")
+		return
+	}
+	name, _ := req.Params["name"].(string)
+
+	profile, err := manager.ImportOpenVPNProfile(path, name)
+	if err != nil {
+		log.Warnf("handleImportOpenVPN: failed: %v", err)
+		models.RespondError(conn, req.ID, fmt.Sprintf("failed to import VPN profile: %v", err))
+		return
+	}
+	models.Respond(conn, req.ID, profile)
+}
+
+func handleSetVPNPolicy(conn net.Conn, req Request, manager *Manager) {
+	policy := manager.GetVPNPolicy()
+	if enabled, ok := req.Params["enabled"].(bool); ok {
+		policy.Enabled = enabled
+	}
+	if profile, ok := req.Params["profile"].(string); ok {
+		policy.Profile = profile
+	}
+	if _, ok := req.Params["trustedSSIDs"]; ok {
+		policy.TrustedSSIDs = stringListParam(req.Params["trustedSSIDs"])
+	}
+	if trustEthernet, ok := req.Params["trustEthernet"].(bool); ok {
+		policy.TrustEthernet = trustEthernet
+	}
+	if disconnect, ok := req.Params["disconnectOnTrusted"].(bool); ok {
+		policy.DisconnectOnTrusted = disconnect
+	}
+
+	if err := manager.SetVPNPolicy(policy); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, manager.GetVPNPolicy())
+}
+
+func handleSetConnectivity(conn net.Conn, req Request, manager *Manager) {
+	config := manager.GetConnectivityConfig()
+	if enabled, ok := req.Params["enabled"].(bool); ok {
+		config.Enabled = enabled
+	}
+	if method, ok := req.Params["method"].(string); ok {
+		if method != config.Method {
+			config.Target = ""
+		}
+		config.Method = method
+	}
+	if target, ok := req.Params["target"].(string); ok {
+		config.Target = target
+	}
+	if interval, ok := req.Params["interval"].(float64); ok {
+		config.Interval = int(interval)
+	}
+	if probes, ok := req.Params["probes"].(float64); ok {
+		config.Probes = int(probes)
+	}
+
+	config, err := manager.SetConnectivityConfig(config)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, config)
+}
+
+func handleHotspotQR(conn net.Conn, req Request, manager *Manager) {
+	qr, err := manager.HotspotQR()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, qr)
+}
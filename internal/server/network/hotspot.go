@@ -0,0 +1,99 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+)
+
+// hotspotConnectionID names the NetworkManager profile used for the
+// hotspot so it can be found again and kept out of the saved networks list
+const hotspotConnectionID = "DMS Hotspot"
+
+// Hotspot bands, using NetworkManager's 802-11-wireless.band values. An
+// empty band lets the backend pick
+const (
+	HotspotBandAuto = ""
+	HotspotBand2GHz = "bg"
+	HotspotBand5GHz = "a"
+)
+
+func normalizeHotspotBand(band string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(band)) {
+	case "", "auto":
+		return HotspotBandAuto, nil
+	case "bg", "2.4", "2.4ghz":
+		return HotspotBand2GHz, nil
+	case "a", "5", "5ghz":
+		return HotspotBand5GHz, nil
+	default:
+		return "", fmt.Errorf("invalid band %q (use 2.4, 5 or auto)", band)
+	}
+}
+
+// validateHotspot checks the SSID and WPA2 passphrase; an empty password
+// makes an open hotspot where the backend allows it
+func validateHotspot(ssid, password string) error {
+	if ssid == "" || len(ssid) > 32 {
+		return fmt.Errorf("SSID must be 1-32 bytes")
+	}
+	if password != "" && (len(password) < 8 || len(password) > 63) {
+		return fmt.Errorf("password must be 8-63 characters")
+	}
+	return nil
+}
+
+func hotspotSettings(ssid, password, band, iface string) map[string]map[string]interface{} {
+	settings := map[string]map[string]interface{}{
+		"connection": {
+			"id":          hotspotConnectionID,
+			"type":        "802-11-wireless",
+			"autoconnect": false,
+		},
+		"ipv4": {"method": "shared"},
+		"ipv6": {"method": "ignore"},
+	}
+	if iface != "" {
+		settings["connection"]["interface-name"] = iface
+	}
+
+	wireless := map[string]interface{}{
+		"ssid": []byte(ssid),
+		"mode": "ap",
+	}
+	if band != HotspotBandAuto {
+		wireless["band"] = band
+	}
+	settings["802-11-wireless"] = wireless
+
+	if password != "" {
+		wireless["security"] = "802-11-wireless-security"
+		settings["802-11-wireless-security"] = map[string]interface{}{
+			"key-mgmt": "wpa-psk",
+			"psk":      password,
+			"proto":    []string{"rsn"},
+			"pairwise": []string{"ccmp"},
+			"group":    []string{"ccmp"},
+		}
+	}
+	return settings
+}
+
+// hotspotFromSettings reports the hotspot described by an active
+// connection's settings, or an inactive state if it isn't an AP profile
+func hotspotFromSettings(settings map[string]map[string]interface{}, iface string) HotspotState {
+	wireless, ok := settings["802-11-wireless"]
+	if !ok {
+		return HotspotState{}
+	}
+	if mode, _ := wireless["mode"].(string); mode != "ap" {
+		return HotspotState{}
+	}
+
+	state := HotspotState{Active: true, Device: iface}
+	if ssid, ok := wireless["ssid"].([]byte); ok {
+		state.SSID = string(ssid)
+	}
+	state.Band, _ = wireless["band"].(string)
+	_, state.Secured = settings["802-11-wireless-security"]
+	return state
+}
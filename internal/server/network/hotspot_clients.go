@@ -0,0 +1,205 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
+	"github.com/Wifx/gonetworkmanager/v2"
+	"github.com/skip2/go-qrcode"
+)
+
+const hotspotClientInterval = 5 * time.Second
+
+// atfComplete is the ARP flag of a neighbour whose address is resolved
+const atfComplete = 0x2
+
+// HotspotClient is a device joined to the hotspot. The hostname comes from
+// the DHCP lease, which only NetworkManager's dnsmasq keeps
+type HotspotClient struct {
+	MAC      string `json:"mac"`
+	IP       string `json:"ip,omitempty"`
+	Hostname string `json:"hostname,omitempty"`
+}
+
+// hotspotClientReader lists the neighbours on the hotspot interface and
+// names them from the dnsmasq leases. Like the bandwidth counters this reads
+// the same files for every backend, so it isn't part of Backend. A client
+// that leaves drops off once the kernel forgets its address, within a
+// minute or two
+type hotspotClientReader struct {
+	arpPath   string
+	leasesDir string
+}
+
+func newHotspotClientReader() *hotspotClientReader {
+	return &hotspotClientReader{arpPath: "/proc/net/arp", leasesDir: "/var/lib/NetworkManager"}
+}
+
+func (r *hotspotClientReader) read(iface string) []HotspotClient {
+	if iface == "" {
+		return nil
+	}
+	data, err := os.ReadFile(r.arpPath)
+	if err != nil {
+		return nil
+	}
+	clients := parseARPClients(data, iface)
+	if len(clients) == 0 {
+		return nil
+	}
+
+	if leases, err := os.ReadFile(filepath.Join(r.leasesDir, "dnsmasq-"+iface+".leases")); err == nil {
+		names := parseDnsmasqLeases(leases)
+		for i := range clients {
+			if lease, ok := names[clients[i].MAC]; ok {
+				clients[i].Hostname = lease.Hostname
+			}
+		}
+	}
+	return clients
+}
+
+// parseARPClients reads /proc/net/arp, whose columns are IP address, HW
+// type, flags, HW address, mask and device
+func parseARPClients(data []byte, iface string) []HotspotClient {
+	var clients []HotspotClient
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Scan() // header
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 || fields[5] != iface {
+			continue
+		}
+		flags, err := strconv.ParseUint(strings.TrimPrefix(fields[2], "0x"), 16, 32)
+		if err != nil || flags&atfComplete == 0 {
+			continue
+		}
+		mac := strings.ToLower(fields[3])
+		if mac == "00:00:00:00:00:00" {
+			continue
+		}
+		clients = append(clients, HotspotClient{MAC: mac, IP: fields[0]})
+	}
+	slices.SortFunc(clients, func(a, b HotspotClient) int { return strings.Compare(a.MAC, b.MAC) })
+	return slices.CompactFunc(clients, func(a, b HotspotClient) bool { return a.MAC == b.MAC })
+}
+
+// parseDnsmasqLeases reads a dnsmasq lease file, one "expiry mac ip
+// hostname client-id" line per lease, keyed by MAC. An unknown hostname is *
+func parseDnsmasqLeases(data []byte) map[string]HotspotClient {
+	leases := make(map[string]HotspotClient)
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		lease := HotspotClient{MAC: strings.ToLower(fields[1]), IP: fields[2]}
+		if fields[3] != "*" {
+			lease.Hostname = fields[3]
+		}
+		leases[lease.MAC] = lease
+	}
+	return leases
+}
+
+func (m *Manager) hotspotClientMonitor() {
+	defer m.notifierWg.Done()
+
+	reader := newHotspotClientReader()
+	ticker := time.NewTicker(hotspotClientInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			m.stateMutex.RLock()
+			hotspot := m.state.Hotspot
+			m.stateMutex.RUnlock()
+
+			var clients []HotspotClient
+			if hotspot.Active {
+				clients = reader.read(hotspot.Device)
+			}
+
+			m.stateMutex.Lock()
+			changed := !slices.Equal(m.state.HotspotClients, clients)
+			m.state.HotspotClients = clients
+			m.stateMutex.Unlock()
+
+			if changed {
+				m.notifySubscribers()
+			}
+			ticker.Reset(budget.Scale(hotspotClientInterval))
+		}
+	}
+}
+
+// HotspotQR is the QR code clients scan to join the running hotspot
+func (m *Manager) HotspotQR() (*WiFiQR, error) {
+	m.stateMutex.RLock()
+	hotspot := m.state.Hotspot
+	m.stateMutex.RUnlock()
+	if !hotspot.Active {
+		return nil, fmt.Errorf("hotspot is not running")
+	}
+
+	security, password := "nopass", ""
+	if hotspot.Secured {
+		var err error
+		backend := m.currentBackend()
+		if iwd := iwdBackendOf(backend); iwd != nil {
+			password, err = iwd.hotspotPassword()
+		} else if nm, ok := backend.(*NetworkManagerBackend); ok {
+			password, err = nm.hotspotPassword()
+		} else {
+			err = fmt.Errorf("hotspot not supported by this backend")
+		}
+		if err != nil {
+			return nil, err
+		}
+		security = "WPA"
+	}
+
+	payload := wifiQRPayload(hotspot.SSID, security, password, false)
+	png, err := qrcode.Encode(payload, qrcode.Medium, qrPNGSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render QR code: %w", err)
+	}
+	return &WiFiQR{SSID: hotspot.SSID, Payload: payload, PNG: png}, nil
+}
+
+// hotspotPassword reads the passphrase of the access point profile active
+// on the WiFi device
+func (b *NetworkManagerBackend) hotspotPassword() (string, error) {
+	if b.wifiDevice == nil {
+		return "", fmt.Errorf("no WiFi device available")
+	}
+	dev := b.wifiDevice.(gonetworkmanager.Device)
+	activeConn, err := dev.GetPropertyActiveConnection()
+	if err != nil || activeConn == nil || activeConn.GetPath() == "/" {
+		return "", fmt.Errorf("hotspot is not running")
+	}
+	conn, err := activeConn.GetPropertyConnection()
+	if err != nil || conn == nil {
+		return "", fmt.Errorf("hotspot is not running")
+	}
+	secrets, err := conn.GetSecrets("802-11-wireless-security")
+	if err != nil {
+		return "", fmt.Errorf("failed to get the hotspot password: %w", err)
+	}
+	password, _ := secrets["802-11-wireless-security"]["psk"].(string)
+	if password == "" {
+		return "", fmt.Errorf("the hotspot password is not saved")
+	}
+	return password, nil
+}
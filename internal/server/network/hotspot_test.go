@@ -0,0 +1,112 @@
+package network
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeHotspotBand(t *testing.T) {
+	tests := map[string]string{
+		"":       HotspotBandAuto,
+		"auto":   HotspotBandAuto,
+		"2.4":    HotspotBand2GHz,
+		"bg":     HotspotBand2GHz,
+		"5GHz":   HotspotBand5GHz,
+		"a":      HotspotBand5GHz,
+		" 5 ":    HotspotBand5GHz,
+		"2.4ghz": HotspotBand2GHz,
+	}
+	for input, want := range tests {
+		got, err := normalizeHotspotBand(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	_, err := normalizeHotspotBand("6")
+	assert.Error(t, err)
+}
+
+func TestValidateHotspot(t *testing.T) {
+	assert.NoError(t, validateHotspot("Laptop", "secret123"))
+	assert.NoError(t, validateHotspot("Laptop", ""))
+	assert.Error(t, validateHotspot("", "secret123"))
+	assert.Error(t, validateHotspot("Laptop", "short"))
+	assert.Error(t, validateHotspot("this-ssid-is-longer-than-32-bytes!", "secret123"))
+}
+
+func TestHotspotSettingsRoundTrip(t *testing.T) {
+	settings := hotspotSettings("Laptop", "secret123", HotspotBand5GHz, "wlan0")
+
+	assert.Equal(t, hotspotConnectionID, settings["connection"]["id"])
+	assert.Equal(t, "wlan0", settings["connection"]["interface-name"])
+	assert.Equal(t, false, settings["connection"]["autoconnect"])
+	assert.Equal(t, "shared", settings["ipv4"]["method"])
+	assert.Equal(t, "ap", settings["802-11-wireless"]["mode"])
+	assert.Equal(t, "a", settings["802-11-wireless"]["band"])
+	assert.Equal(t, "wpa-psk", settings["802-11-wireless-security"]["key-mgmt"])
+	assert.Equal(t, "secret123", settings["802-11-wireless-security"]["psk"])
+
+	state := hotspotFromSettings(settings, "wlan0")
+	assert.Equal(t, HotspotState{Active: true, SSID: "Laptop", Band: "a", Device: "wlan0", Secured: true}, state)
+}
+
+func TestHotspotSettingsOpen(t *testing.T) {
+	settings := hotspotSettings("Guests", "", HotspotBandAuto, "")
+
+	_, hasSecurity := settings["802-11-wireless-security"]
+	assert.False(t, hasSecurity)
+	_, hasBand := settings["802-11-wireless"]["band"]
+	assert.False(t, hasBand)
+	_, hasIface := settings["connection"]["interface-name"]
+	assert.False(t, hasIface)
+
+	state := hotspotFromSettings(settings, "wlan0")
+	assert.True(t, state.Active)
+	assert.False(t, state.Secured)
+}
+
+func TestHotspotFromSettings_ClientConnection(t *testing.T) {
+	settings := map[string]map[string]interface{}{
+		"802-11-wireless": {"ssid": []byte("Home"), "mode": "infrastructure"},
+	}
+	assert.Equal(t, HotspotState{}, hotspotFromSettings(settings, "wlan0"))
+	assert.Equal(t, HotspotState{}, hotspotFromSettings(map[string]map[string]interface{}{}, "wlan0"))
+}
+
+func TestParseARPClients(t *testing.T) {
+	arp := `IP address       HW type     Flags       HW address            Mask     Device
+10.42.0.57       0x1         0x2         AA:BB:CC:00:11:22     *        wlan0
+10.42.0.80       0x1         0x0         00:00:00:00:00:00     *        wlan0
+10.42.0.91       0x1         0x2         de:ad:be:ef:00:01     *        wlan0
+192.168.1.1      0x1         0x2         11:22:33:44:55:66     *        enp3s0
+`
+	clients := parseARPClients([]byte(arp), "wlan0")
+	assert.Equal(t, []HotspotClient{
+		{MAC: "aa:bb:cc:00:11:22", IP: "10.42.0.57"},
+		{MAC: "de:ad:be:ef:00:01", IP: "10.42.0.91"},
+	}, clients)
+}
+
+func TestHotspotClientReaderNamesFromLeases(t *testing.T) {
+	dir := t.TempDir()
+	arpPath := filepath.Join(dir, "arp")
+	require.NoError(t, os.WriteFile(arpPath, []byte(`IP address       HW type     Flags       HW address            Mask     Device
+10.42.0.57       0x1         0x2         aa:bb:cc:00:11:22     *        wlan0
+10.42.0.91       0x1         0x2         de:ad:be:ef:00:01     *        wlan0
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dnsmasq-wlan0.leases"), []byte(
+		"1700000000 aa:bb:cc:00:11:22 10.42.0.57 pixel-8 01:aa:bb:cc:00:11:22\n"+
+			"1700000000 de:ad:be:ef:00:01 10.42.0.91 * *\n"), 0644))
+
+	reader := &hotspotClientReader{arpPath: arpPath, leasesDir: dir}
+	assert.Equal(t, []HotspotClient{
+		{MAC: "aa:bb:cc:00:11:22", IP: "10.42.0.57", Hostname: "pixel-8"},
+		{MAC: "de:ad:be:ef:00:01", IP: "10.42.0.91"},
+	}, reader.read("wlan0"))
+	assert.Nil(t, reader.read("wlan1"))
+	assert.Nil(t, reader.read(""))
+}
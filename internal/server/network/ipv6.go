@@ -0,0 +1,133 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Wifx/gonetworkmanager/v2"
+)
+
+// IPv6 methods a profile can be switched to. Disabled turns IPv6 off on the
+// interface; ignore leaves it to the kernel without NetworkManager's help
+const (
+	IPv6MethodAuto      = "auto"
+	IPv6MethodDHCP      = "dhcp"
+	IPv6MethodLinkLocal = "link-local"
+	IPv6MethodIgnore    = "ignore"
+	IPv6MethodDisabled  = "disabled"
+)
+
+var ipv6Methods = []string{IPv6MethodAuto, IPv6MethodDHCP, IPv6MethodLinkLocal, IPv6MethodIgnore, IPv6MethodDisabled}
+
+// ipv6.ip6-privacy values, in NetworkManager's order starting at -1. The
+// prefer modes add RFC 4941 temporary addresses and pick which one
+// outgoing connections use
+var ipv6PrivacyModes = []string{"default", "disabled", "prefer-public", "prefer-temporary"}
+
+// ipv6.addr-gen-mode values, in NetworkManager's order starting at 0.
+// eui64 derives the address from the MAC, stable-privacy hashes it per network
+var ipv6AddrGenModes = []string{"eui64", "stable-privacy", "default-or-eui64", "default"}
+
+// IPv6Config is a profile's IPv6 method and address privacy. Empty fields
+// are left as they are when setting
+type IPv6Config struct {
+	Method      string `json:"method"`
+	Privacy     string `json:"privacy"`
+	AddrGenMode string `json:"addrGenMode"`
+}
+
+func parseIPv6Method(method string) (string, error) {
+	method = strings.ToLower(method)
+	for _, m := range ipv6Methods {
+		if m == method {
+			return m, nil
+		}
+	}
+	if method == "manual" {
+		return "", fmt.Errorf("manual IPv6 addresses are not supported here")
+	}
+	return "", fmt.Errorf("invalid IPv6 method %q (use %s)", method, strings.Join(ipv6Methods, ", "))
+}
+
+func parseIPv6Privacy(mode string) (int32, error) {
+	mode = strings.ToLower(mode)
+	switch mode {
+	case "true", "on", "enabled":
+		mode = "prefer-temporary"
+	case "false", "off":
+		mode = "disabled"
+	case "unknown":
+		mode = "default"
+	}
+	for i, m := range ipv6PrivacyModes {
+		if m == mode {
+			return int32(i - 1), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid IPv6 privacy mode %q (use %s)", mode, strings.Join(ipv6PrivacyModes, ", "))
+}
+
+func parseIPv6AddrGenMode(mode string) (int32, error) {
+	mode = strings.ToLower(mode)
+	for i, m := range ipv6AddrGenModes {
+		if m == mode {
+			return int32(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid IPv6 address generation mode %q (use %s)", mode, strings.Join(ipv6AddrGenModes, ", "))
+}
+
+// ipv6ConfigFromSettings reads the ipv6 section of a profile
+func ipv6ConfigFromSettings(settings gonetworkmanager.ConnectionSettings) IPv6Config {
+	config := IPv6Config{Method: IPv6MethodAuto, Privacy: "default", AddrGenMode: "default"}
+	section := settings["ipv6"]
+	if method, ok := section["method"].(string); ok && method != "" {
+		config.Method = method
+	}
+	if privacy, ok := section["ip6-privacy"].(int32); ok && privacy >= -1 && int(privacy)+1 < len(ipv6PrivacyModes) {
+		config.Privacy = ipv6PrivacyModes[privacy+1]
+	}
+	if mode, ok := section["addr-gen-mode"].(int32); ok && mode >= 0 && int(mode) < len(ipv6AddrGenModes) {
+		config.AddrGenMode = ipv6AddrGenModes[mode]
+	}
+	return config
+}
+
+// applyIPv6Config writes the set fields of config into a profile's settings
+func applyIPv6Config(settings gonetworkmanager.ConnectionSettings, config IPv6Config) error {
+	section := settings["ipv6"]
+	if section == nil {
+		section = make(map[string]interface{})
+		settings["ipv6"] = section
+	}
+
+	if config.Method != "" {
+		method, err := parseIPv6Method(config.Method)
+		if err != nil {
+			return err
+		}
+		section["method"] = method
+		// static addresses are only valid with the manual method
+		delete(section, "address-data")
+		delete(section, "gateway")
+		if method == IPv6MethodDisabled || method == IPv6MethodIgnore {
+			delete(section, "dns")
+			delete(section, "route-data")
+		}
+	}
+	if config.Privacy != "" {
+		privacy, err := parseIPv6Privacy(config.Privacy)
+		if err != nil {
+			return err
+		}
+		section["ip6-privacy"] = privacy
+	}
+	if config.AddrGenMode != "" {
+		mode, err := parseIPv6AddrGenMode(config.AddrGenMode)
+		if err != nil {
+			return err
+		}
+		section["addr-gen-mode"] = mode
+	}
+	return nil
+}
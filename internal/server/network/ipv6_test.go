@@ -0,0 +1,79 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/Wifx/gonetworkmanager/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIPv6Privacy(t *testing.T) {
+	tests := map[string]int32{
+		"default":          -1,
+		"unknown":          -1,
+		"disabled":         0,
+		"off":              0,
+		"prefer-public":    1,
+		"Prefer-Temporary": 2,
+		"true":             2,
+	}
+	for mode, want := range tests {
+		got, err := parseIPv6Privacy(mode)
+		require.NoError(t, err, mode)
+		assert.Equal(t, want, got, mode)
+	}
+
+	_, err := parseIPv6Privacy("sometimes")
+	assert.ErrorContains(t, err, "invalid IPv6 privacy mode")
+}
+
+func TestParseIPv6Method(t *testing.T) {
+	method, err := parseIPv6Method("Disabled")
+	require.NoError(t, err)
+	assert.Equal(t, IPv6MethodDisabled, method)
+
+	_, err = parseIPv6Method("manual")
+	assert.ErrorContains(t, err, "not supported")
+
+	_, err = parseIPv6Method("shared6")
+	assert.ErrorContains(t, err, "invalid IPv6 method")
+}
+
+func TestIPv6ConfigFromSettings(t *testing.T) {
+	settings := gonetworkmanager.ConnectionSettings{"ipv6": {
+		"method":        "disabled",
+		"ip6-privacy":   int32(2),
+		"addr-gen-mode": int32(1),
+	}}
+	assert.Equal(t, IPv6Config{Method: "disabled", Privacy: "prefer-temporary", AddrGenMode: "stable-privacy"}, ipv6ConfigFromSettings(settings))
+
+	assert.Equal(t, IPv6Config{Method: "auto", Privacy: "default", AddrGenMode: "default"}, ipv6ConfigFromSettings(gonetworkmanager.ConnectionSettings{}))
+}
+
+func TestApplyIPv6Config(t *testing.T) {
+	settings := gonetworkmanager.ConnectionSettings{"ipv6": {
+		"method":        "manual",
+		"address-data":  []map[string]interface{}{{"address": "fd00::2", "prefix": uint32(64)}},
+		"gateway":       "fd00::1",
+		"ip6-privacy":   int32(0),
+		"addr-gen-mode": int32(1),
+	}}
+
+	require.NoError(t, applyIPv6Config(settings, IPv6Config{Privacy: "prefer-temporary"}))
+	assert.Equal(t, int32(2), settings["ipv6"]["ip6-privacy"])
+	assert.Equal(t, "manual", settings["ipv6"]["method"], "fields left out keep their value")
+	assert.Contains(t, settings["ipv6"], "address-data")
+
+	require.NoError(t, applyIPv6Config(settings, IPv6Config{Method: "disabled"}))
+	assert.Equal(t, "disabled", settings["ipv6"]["method"])
+	assert.NotContains(t, settings["ipv6"], "address-data")
+	assert.NotContains(t, settings["ipv6"], "gateway")
+	assert.Equal(t, int32(1), settings["ipv6"]["addr-gen-mode"])
+
+	fresh := gonetworkmanager.ConnectionSettings{}
+	require.NoError(t, applyIPv6Config(fresh, IPv6Config{AddrGenMode: "eui64"}))
+	assert.Equal(t, int32(0), fresh["ipv6"]["addr-gen-mode"])
+
+	assert.Error(t, applyIPv6Config(fresh, IPv6Config{AddrGenMode: "random"}))
+}
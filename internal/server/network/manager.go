@@ -2,13 +2,17 @@ package network
 
 import (
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/secrets"
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
 )
 
-func NewManager() (*Manager, error) {
+// selectBackend picks the backend for the network daemons running now
+func selectBackend() (Backend, error) {
 	detection, err := DetectNetworkStack()
 	if err != nil {
 		return nil, fmt.Errorf("failed to detect network stack: %w", err)
@@ -58,6 +62,14 @@ func NewManager() (*Manager, error) {
 	default:
 		return nil, fmt.Errorf("no supported network backend found: %s", detection.ChosenReason)
 	}
+	return backend, nil
+}
+
+func NewManager() (*Manager, error) {
+	backend, err := selectBackend()
+	if err != nil {
+		return nil, err
+	}
 
 	m := &Manager{
 		backend: backend,
@@ -65,6 +77,7 @@ func NewManager() (*Manager, error) {
 			NetworkStatus: StatusDisconnected,
 			Preference:    PreferenceAuto,
 			WiFiNetworks:  []WiFiNetwork{},
+			Connectivity:  Connectivity{Quality: ConnectivityUnknown},
 		},
 		stateMutex:            sync.RWMutex{},
 		subscribers:           make(map[string]chan NetworkState),
@@ -73,23 +86,30 @@ func NewManager() (*Manager, error) {
 		dirty:                 make(chan struct{}, 1),
 		credentialSubscribers: make(map[string]chan CredentialPrompt),
 		credSubMutex:          sync.RWMutex{},
+		vpnPolicyPath:         defaultVPNPolicyPath(),
+		signalHistory:         newSignalHistory(),
+		connectivityPath:      defaultConnectivityPath(),
+		connectivityWake:      make(chan struct{}, 1),
 	}
+	m.loadVPNPolicy()
+	m.loadConnectivityConfig()
 
-	broker := NewSubscriptionBroker(m.broadcastCredentialPrompt)
-	if err := backend.SetPromptBroker(broker); err != nil {
-		return nil, fmt.Errorf("failed to set prompt broker: %w", err)
-	}
-
-	if err := backend.Initialize(); err != nil {
-		return nil, fmt.Errorf("failed to initialize backend: %w", err)
+	if err := m.prepareBackend(backend); err != nil {
+		return nil, err
 	}
 
 	if err := m.syncStateFromBackend(); err != nil {
 		return nil, fmt.Errorf("failed to sync initial state: %w", err)
 	}
+	m.evaluateVPNPolicy()
 
-	m.notifierWg.Add(1)
+	m.notifierWg.Add(5)
 	go m.notifier()
+	go m.bandwidthMonitor()
+	go m.signalMonitor()
+	go m.connectivityMonitor()
+	go m.hotspotClientMonitor()
+	m.watchDaemons()
 
 	if err := backend.StartMonitoring(m.onBackendStateChange); err != nil {
 		m.Close()
@@ -99,21 +119,47 @@ func NewManager() (*Manager, error) {
 	return m, nil
 }
 
+func (m *Manager) prepareBackend(backend Backend) error {
+	if iwd := iwdBackendOf(backend); iwd != nil && secrets.KeyringAvailable() {
+		iwd.SetSecretStore(KeyringSecretStore{})
+	}
+
+	broker := NewSubscriptionBroker(m.broadcastCredentialPrompt)
+	if err := backend.SetPromptBroker(broker); err != nil {
+		return fmt.Errorf("failed to set prompt broker: %w", err)
+	}
+
+	if err := backend.Initialize(); err != nil {
+		return fmt.Errorf("failed to initialize backend: %w", err)
+	}
+	return nil
+}
+
+// currentBackend is the backend in use, which changes when the network
+// daemons restart
+func (m *Manager) currentBackend() Backend {
+	m.backendMutex.RLock()
+	defer m.backendMutex.RUnlock()
+	return m.backend
+}
+
 func (m *Manager) syncStateFromBackend() error {
-	backendState, err := m.backend.GetCurrentState()
+	backendState, err := m.currentBackend().GetCurrentState()
 	if err != nil {
 		return err
 	}
 
 	m.stateMutex.Lock()
+	prev := *m.state
 	m.state.Backend = backendState.Backend
-	m.state.NetworkStatus = backendState.NetworkStatus
 	m.state.EthernetIP = backendState.EthernetIP
 	m.state.EthernetDevice = backendState.EthernetDevice
 	m.state.EthernetConnected = backendState.EthernetConnected
 	m.state.EthernetConnectionUuid = backendState.EthernetConnectionUuid
+	m.state.EthernetDevices = backendState.EthernetDevices
 	m.state.WiFiIP = backendState.WiFiIP
 	m.state.WiFiDevice = backendState.WiFiDevice
+	m.state.WiFiDevices = backendState.WiFiDevices
 	m.state.WiFiConnected = backendState.WiFiConnected
 	m.state.WiFiEnabled = backendState.WiFiEnabled
 	m.state.WiFiSSID = backendState.WiFiSSID
@@ -121,21 +167,37 @@ func (m *Manager) syncStateFromBackend() error {
 	m.state.WiFiSignal = backendState.WiFiSignal
 	m.state.WiFiNetworks = backendState.WiFiNetworks
 	m.state.WiredConnections = backendState.WiredConnections
+	m.state.Metered = backendState.Metered
 	m.state.VPNProfiles = backendState.VPNProfiles
 	m.state.VPNActive = backendState.VPNActive
+	m.state.Hotspot = backendState.Hotspot
 	m.state.IsConnecting = backendState.IsConnecting
 	m.state.ConnectingSSID = backendState.ConnectingSSID
 	m.state.LastError = backendState.LastError
+	m.applyFlapDebounce(backendState.NetworkStatus, &prev)
 	m.stateMutex.Unlock()
 
 	return nil
 }
 
 func (m *Manager) onBackendStateChange() {
+	m.stateMutex.RLock()
+	network := primaryNetwork(m.state)
+	m.stateMutex.RUnlock()
+
 	if err := m.syncStateFromBackend(); err != nil {
 		log.Errorf("failed to sync state from backend: %v", err)
 	}
+	m.recordSignal(time.Now())
+	m.evaluateVPNPolicy()
 	m.notifySubscribers()
+
+	m.stateMutex.RLock()
+	changed := primaryNetwork(m.state) != network
+	m.stateMutex.RUnlock()
+	if changed {
+		m.wakeConnectivity()
+	}
 }
 
 func signalChangeSignificant(old, new uint8) bool {
@@ -157,6 +219,8 @@ func (m *Manager) snapshotState() NetworkState {
 	s.WiredConnections = append([]WiredConnection(nil), m.state.WiredConnections...)
 	s.VPNProfiles = append([]VPNProfile(nil), m.state.VPNProfiles...)
 	s.VPNActive = append([]VPNActive(nil), m.state.VPNActive...)
+	s.WiFiDevices = append([]WiFiDeviceInfo(nil), m.state.WiFiDevices...)
+	s.EthernetDevices = append([]EthernetDeviceInfo(nil), m.state.EthernetDevices...)
 	return s
 }
 
@@ -188,6 +252,10 @@ func stateChangedMeaningfully(old, new *NetworkState) bool {
 	if old.WiFiIP != new.WiFiIP {
 		return true
 	}
+	// Checked before the signal, which ends the comparison on small changes
+	if old.Connectivity != new.Connectivity {
+		return true
+	}
 	if !signalChangeSignificant(old.WiFiSignal, new.WiFiSignal) {
 		if old.WiFiSignal != new.WiFiSignal {
 			return false
@@ -204,6 +272,43 @@ func stateChangedMeaningfully(old, new *NetworkState) bool {
 	if old.LastError != new.LastError {
 		return true
 	}
+	if old.BackendRestarting != new.BackendRestarting {
+		return true
+	}
+	if old.Hotspot != new.Hotspot {
+		return true
+	}
+	if old.Unstable != new.Unstable || old.FlapCount != new.FlapCount {
+		return true
+	}
+	if !slices.Equal(old.HotspotClients, new.HotspotClients) {
+		return true
+	}
+	if old.Metered != new.Metered {
+		return true
+	}
+	if bandwidthChanged(old.Bandwidth, new.Bandwidth) {
+		return true
+	}
+	if old.WiFiDevice != new.WiFiDevice {
+		return true
+	}
+	if len(old.WiFiDevices) != len(new.WiFiDevices) {
+		return true
+	}
+	for i := range old.WiFiDevices {
+		if old.WiFiDevices[i] != new.WiFiDevices[i] {
+			return true
+		}
+	}
+	if len(old.EthernetDevices) != len(new.EthernetDevices) {
+		return true
+	}
+	for i := range old.EthernetDevices {
+		if old.EthernetDevices[i] != new.EthernetDevices[i] {
+			return true
+		}
+	}
 	if len(old.WiFiNetworks) != len(new.WiFiNetworks) {
 		return true
 	}
@@ -223,6 +328,9 @@ func stateChangedMeaningfully(old, new *NetworkState) bool {
 		if oldNet.Saved != newNet.Saved {
 			return true
 		}
+		if oldNet.Metered != newNet.Metered {
+			return true
+		}
 	}
 
 	for i := range old.WiredConnections {
@@ -234,6 +342,12 @@ func stateChangedMeaningfully(old, new *NetworkState) bool {
 		if oldNet.IsActive != newNet.IsActive {
 			return true
 		}
+		if oldNet.Metered != newNet.Metered {
+			return true
+		}
+		if oldNet.Device != newNet.Device {
+			return true
+		}
 	}
 
 	// Check VPN profiles count
@@ -368,27 +482,41 @@ func (m *Manager) notifySubscribers() {
 }
 
 func (m *Manager) SetPromptBroker(broker PromptBroker) error {
-	return m.backend.SetPromptBroker(broker)
+	return m.currentBackend().SetPromptBroker(broker)
 }
 
 func (m *Manager) SubmitCredentials(token string, secrets map[string]string, save bool) error {
-	return m.backend.SubmitCredentials(token, secrets, save)
+	return m.currentBackend().SubmitCredentials(token, secrets, save)
 }
 
 func (m *Manager) CancelCredentials(token string) error {
-	return m.backend.CancelCredentials(token)
+	return m.currentBackend().CancelCredentials(token)
+}
+
+// ClaimCredentials reserves the prompt for client so other UIs can't answer
+// it as well
+func (m *Manager) ClaimCredentials(token, client string) error {
+	broker := m.currentBackend().GetPromptBroker()
+	if broker == nil {
+		return fmt.Errorf("prompt broker not initialized")
+	}
+	return broker.Claim(token, client)
 }
 
 func (m *Manager) GetPromptBroker() PromptBroker {
-	return m.backend.GetPromptBroker()
+	return m.currentBackend().GetPromptBroker()
 }
 
 func (m *Manager) Close() {
 	close(m.stopChan)
 	m.notifierWg.Wait()
 
-	if m.backend != nil {
-		m.backend.Close()
+	m.stateMutex.Lock()
+	m.scheduleFlapRecheck(0)
+	m.stateMutex.Unlock()
+
+	if backend := m.currentBackend(); backend != nil {
+		backend.Close()
 	}
 
 	m.subMutex.Lock()
@@ -399,8 +527,21 @@ func (m *Manager) Close() {
 	m.subMutex.Unlock()
 }
 
+// throttledScanGap is the least time between WiFi scans while the daemon is
+// throttled; scans asked for sooner keep the last results
+const throttledScanGap = 30 * time.Second
+
 func (m *Manager) ScanWiFi() error {
-	return m.backend.ScanWiFi()
+	m.scanMutex.Lock()
+	if budget.Throttled() && time.Since(m.lastScan) < throttledScanGap {
+		m.scanMutex.Unlock()
+		log.Debugf("Skipping WiFi scan while throttled")
+		return nil
+	}
+	m.lastScan = time.Now()
+	m.scanMutex.Unlock()
+
+	return m.currentBackend().ScanWiFi()
 }
 
 func (m *Manager) GetWiFiNetworks() []WiFiNetwork {
@@ -425,16 +566,16 @@ func (m *Manager) GetNetworkInfo(ssid string) (*WiFiNetwork, error) {
 }
 
 func (m *Manager) GetNetworkInfoDetailed(ssid string) (*NetworkInfoResponse, error) {
-	return m.backend.GetWiFiNetworkDetails(ssid)
+	return m.currentBackend().GetWiFiNetworkDetails(ssid)
 }
 
 func (m *Manager) ToggleWiFi() error {
-	enabled, err := m.backend.GetWiFiEnabled()
+	enabled, err := m.currentBackend().GetWiFiEnabled()
 	if err != nil {
 		return fmt.Errorf("failed to get WiFi state: %w", err)
 	}
 
-	err = m.backend.SetWiFiEnabled(!enabled)
+	err = m.currentBackend().SetWiFiEnabled(!enabled)
 	if err != nil {
 		return fmt.Errorf("failed to toggle WiFi: %w", err)
 	}
@@ -443,7 +584,7 @@ func (m *Manager) ToggleWiFi() error {
 }
 
 func (m *Manager) EnableWiFi() error {
-	err := m.backend.SetWiFiEnabled(true)
+	err := m.currentBackend().SetWiFiEnabled(true)
 	if err != nil {
 		return fmt.Errorf("failed to enable WiFi: %w", err)
 	}
@@ -452,7 +593,7 @@ func (m *Manager) EnableWiFi() error {
 }
 
 func (m *Manager) DisableWiFi() error {
-	err := m.backend.SetWiFiEnabled(false)
+	err := m.currentBackend().SetWiFiEnabled(false)
 	if err != nil {
 		return fmt.Errorf("failed to disable WiFi: %w", err)
 	}
@@ -461,15 +602,81 @@ func (m *Manager) DisableWiFi() error {
 }
 
 func (m *Manager) ConnectWiFi(req ConnectionRequest) error {
-	return m.backend.ConnectWiFi(req)
+	return m.currentBackend().ConnectWiFi(req)
+}
+
+func (m *Manager) CancelConnect(ssid string) error {
+	return m.currentBackend().CancelConnect(ssid)
 }
 
 func (m *Manager) DisconnectWiFi() error {
-	return m.backend.DisconnectWiFi()
+	return m.currentBackend().DisconnectWiFi()
 }
 
 func (m *Manager) ForgetWiFiNetwork(ssid string) error {
-	return m.backend.ForgetWiFiNetwork(ssid)
+	return m.currentBackend().ForgetWiFiNetwork(ssid)
+}
+
+// iwdBackend is the backend managing WiFi when that is iwd
+func (m *Manager) iwdBackend() (*IWDBackend, error) {
+	if iwd := iwdBackendOf(m.currentBackend()); iwd != nil {
+		return iwd, nil
+	}
+	return nil, fmt.Errorf("only available with the iwd backend")
+}
+
+func iwdBackendOf(backend Backend) *IWDBackend {
+	switch b := backend.(type) {
+	case *IWDBackend:
+		return b
+	case *HybridIwdNetworkdBackend:
+		return b.wifi
+	}
+	return nil
+}
+
+func (m *Manager) ListKnownNetworks() ([]KnownNetwork, error) {
+	iwd, err := m.iwdBackend()
+	if err != nil {
+		return nil, err
+	}
+	return iwd.KnownNetworks()
+}
+
+func (m *Manager) SetKnownNetworkAutoConnect(ssid string, enabled bool) error {
+	iwd, err := m.iwdBackend()
+	if err != nil {
+		return err
+	}
+	return iwd.SetKnownNetworkAutoConnect(ssid, enabled)
+}
+
+func (m *Manager) StartWPS(method, pin string) (string, error) {
+	iwd, err := m.iwdBackend()
+	if err != nil {
+		return "", err
+	}
+	return iwd.StartWPS(method, pin)
+}
+
+func (m *Manager) CancelWPS() error {
+	iwd, err := m.iwdBackend()
+	if err != nil {
+		return err
+	}
+	return iwd.CancelWPS()
+}
+
+func (m *Manager) SelectWiFiDevice(iface string) error {
+	return m.currentBackend().SelectWiFiDevice(iface)
+}
+
+func (m *Manager) StartHotspot(ssid, password, band string) error {
+	return m.currentBackend().StartHotspot(ssid, password, band)
+}
+
+func (m *Manager) StopHotspot() error {
+	return m.currentBackend().StopHotspot()
 }
 
 func (m *Manager) GetWiredConfigs() []WiredConnection {
@@ -481,41 +688,85 @@ func (m *Manager) GetWiredConfigs() []WiredConnection {
 }
 
 func (m *Manager) GetWiredNetworkInfoDetailed(uuid string) (*WiredNetworkInfoResponse, error) {
-	return m.backend.GetWiredNetworkDetails(uuid)
+	return m.currentBackend().GetWiredNetworkDetails(uuid)
 }
 
-func (m *Manager) ConnectEthernet() error {
-	return m.backend.ConnectEthernet()
+func (m *Manager) ConnectEthernet(auth *ConnectionRequest) error {
+	return m.currentBackend().ConnectEthernet(auth)
 }
 
 func (m *Manager) DisconnectEthernet() error {
-	return m.backend.DisconnectEthernet()
+	return m.currentBackend().DisconnectEthernet()
+}
+
+func (m *Manager) activateConnection(uuid, device string) error {
+	return m.currentBackend().ActivateWiredConnection(uuid, device)
+}
+
+func (m *Manager) SetWiredIPConfig(uuid string, config WiredIPConfig) error {
+	return m.currentBackend().SetWiredIPConfig(uuid, config)
+}
+
+func (m *Manager) SetConnectionDNS(uuidOrSSID string, config DNSConfig) error {
+	return m.currentBackend().SetConnectionDNS(uuidOrSSID, config)
 }
 
-func (m *Manager) activateConnection(uuid string) error {
-	return m.backend.ActivateWiredConnection(uuid)
+func (m *Manager) GetSearchDomains(uuidOrSSID string) ([]string, error) {
+	return m.currentBackend().GetSearchDomains(uuidOrSSID)
+}
+
+func (m *Manager) SetSearchDomains(uuidOrSSID string, domains []string) error {
+	return m.currentBackend().SetSearchDomains(uuidOrSSID, domains)
+}
+
+func (m *Manager) GetMetered(uuidOrSSID string) (*MeteredInfo, error) {
+	return m.currentBackend().GetMetered(uuidOrSSID)
+}
+
+func (m *Manager) SetMetered(uuidOrSSID string, mode string) error {
+	return m.currentBackend().SetMetered(uuidOrSSID, mode)
+}
+
+func (m *Manager) GetIPv6Config(uuidOrSSID string) (*IPv6Config, error) {
+	return m.currentBackend().GetIPv6Config(uuidOrSSID)
+}
+
+func (m *Manager) SetIPv6Config(uuidOrSSID string, config IPv6Config) error {
+	return m.currentBackend().SetIPv6Config(uuidOrSSID, config)
+}
+
+func (m *Manager) GetWakeOnLAN(uuid string) (*WakeOnLANConfig, error) {
+	return m.currentBackend().GetWakeOnLAN(uuid)
+}
+
+func (m *Manager) SetWakeOnLAN(uuid string, config WakeOnLANConfig) error {
+	return m.currentBackend().SetWakeOnLAN(uuid, config)
 }
 
 func (m *Manager) ListVPNProfiles() ([]VPNProfile, error) {
-	return m.backend.ListVPNProfiles()
+	return m.currentBackend().ListVPNProfiles()
 }
 
 func (m *Manager) ListActiveVPN() ([]VPNActive, error) {
-	return m.backend.ListActiveVPN()
+	return m.currentBackend().ListActiveVPN()
 }
 
 func (m *Manager) ConnectVPN(uuidOrName string, singleActive bool) error {
-	return m.backend.ConnectVPN(uuidOrName, singleActive)
+	return m.currentBackend().ConnectVPN(uuidOrName, singleActive)
 }
 
 func (m *Manager) DisconnectVPN(uuidOrName string) error {
-	return m.backend.DisconnectVPN(uuidOrName)
+	return m.currentBackend().DisconnectVPN(uuidOrName)
 }
 
 func (m *Manager) DisconnectAllVPN() error {
-	return m.backend.DisconnectAllVPN()
+	return m.currentBackend().DisconnectAllVPN()
 }
 
 func (m *Manager) ClearVPNCredentials(uuidOrName string) error {
-	return m.backend.ClearVPNCredentials(uuidOrName)
+	return m.currentBackend().ClearVPNCredentials(uuidOrName)
+}
+
+func (m *Manager) ImportOpenVPNProfile(path, name string) (*VPNProfile, error) {
+	return m.currentBackend().ImportOpenVPNProfile(path, name)
 }
@@ -0,0 +1,58 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Wifx/gonetworkmanager/v2"
+)
+
+// Metered modes of a saved profile. Auto lets NetworkManager guess, e.g.
+// from the DHCP vendor option phones send when tethering
+const (
+	MeteredAuto = "auto"
+	MeteredYes  = "yes"
+	MeteredNo   = "no"
+)
+
+// connection.metered values, in NetworkManager's order starting at 0
+var meteredModes = []string{MeteredAuto, MeteredYes, MeteredNo}
+
+func parseMeteredMode(mode string) (int32, error) {
+	mode = strings.ToLower(mode)
+	switch mode {
+	case "true":
+		mode = MeteredYes
+	case "false":
+		mode = MeteredNo
+	case "", "unknown":
+		mode = MeteredAuto
+	}
+	for i, m := range meteredModes {
+		if m == mode {
+			return int32(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid metered mode %q (use %s)", mode, strings.Join(meteredModes, ", "))
+}
+
+// meteredModeFromSettings reads connection.metered from a profile
+func meteredModeFromSettings(settings gonetworkmanager.ConnectionSettings) string {
+	value, _ := settings["connection"]["metered"].(int32)
+	if value < 0 || int(value) >= len(meteredModes) {
+		return MeteredAuto
+	}
+	return meteredModes[value]
+}
+
+// isMetered combines the profile's mode with what the device reports while
+// the profile is active, which includes NetworkManager's guesses
+func isMetered(mode string, device gonetworkmanager.NmMetered) bool {
+	switch mode {
+	case MeteredYes:
+		return true
+	case MeteredNo:
+		return false
+	}
+	return device == gonetworkmanager.NmMeteredYes || device == gonetworkmanager.NmMeteredGuessYes
+}
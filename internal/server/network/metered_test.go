@@ -0,0 +1,50 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/Wifx/gonetworkmanager/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMeteredMode(t *testing.T) {
+	tests := map[string]int32{
+		"auto":    0,
+		"":        0,
+		"unknown": 0,
+		"Yes":     1,
+		"true":    1,
+		"no":      2,
+		"false":   2,
+	}
+	for mode, want := range tests {
+		got, err := parseMeteredMode(mode)
+		require.NoError(t, err, mode)
+		assert.Equal(t, want, got, mode)
+	}
+
+	_, err := parseMeteredMode("sometimes")
+	assert.ErrorContains(t, err, "invalid metered mode")
+}
+
+func TestMeteredModeFromSettings(t *testing.T) {
+	settings := gonetworkmanager.ConnectionSettings{"connection": {"metered": int32(1)}}
+	assert.Equal(t, MeteredYes, meteredModeFromSettings(settings))
+
+	settings["connection"]["metered"] = int32(2)
+	assert.Equal(t, MeteredNo, meteredModeFromSettings(settings))
+
+	settings["connection"]["metered"] = int32(7)
+	assert.Equal(t, MeteredAuto, meteredModeFromSettings(settings))
+
+	assert.Equal(t, MeteredAuto, meteredModeFromSettings(gonetworkmanager.ConnectionSettings{"connection": {}}))
+}
+
+func TestIsMetered(t *testing.T) {
+	assert.True(t, isMetered(MeteredYes, gonetworkmanager.NmMeteredNo))
+	assert.False(t, isMetered(MeteredNo, gonetworkmanager.NmMeteredYes))
+	assert.True(t, isMetered(MeteredAuto, gonetworkmanager.NmMeteredGuessYes))
+	assert.False(t, isMetered(MeteredAuto, gonetworkmanager.NmMeteredGuessNo))
+	assert.False(t, isMetered(MeteredAuto, gonetworkmanager.NmMeteredUnknown))
+}
@@ -0,0 +1,218 @@
+package network
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+const openVPNServiceType = "org.freedesktop.NetworkManager.openvpn"
+
+// openVPNProfile is a parsed .ovpn file in NetworkManager-openvpn terms.
+// Inline blocks (<ca>...</ca>) are kept in Inline until written to files
+type openVPNProfile struct {
+	Data   map[string]string
+	Inline map[string]string
+}
+
+// openVPNFileKeys are options whose argument is a file path, mapped to the
+// NetworkManager-openvpn data key
+var openVPNFileKeys = map[string]string{
+	"ca":        "ca",
+	"cert":      "cert",
+	"key":       "key",
+	"tls-auth":  "ta",
+	"tls-crypt": "tls-crypt",
+	"secret":    "static-key",
+}
+
+// parseOpenVPNConfig reads the subset of OpenVPN client options that
+// NetworkManager-openvpn supports. Relative paths resolve against dir
+func parseOpenVPNConfig(content, dir string) (*openVPNProfile, error) {
+	profile := &openVPNProfile{Data: make(map[string]string), Inline: make(map[string]string)}
+	var remotes []string
+	port, proto := "", ""
+	authUserPass := false
+	keyDirection := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var inlineTag string
+	var inline strings.Builder
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if inlineTag != "" {
+			if line == "</"+inlineTag+">" {
+				profile.Inline[inlineTag] = inline.String()
+				inlineTag = ""
+				inline.Reset()
+				continue
+			}
+			inline.WriteString(line + "\n")
+			continue
+		}
+
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "<") && strings.HasSuffix(line, ">") {
+			inlineTag = strings.Trim(line, "<>")
+			continue
+		}
+
+		fields := strings.Fields(line)
+		key, args := strings.TrimPrefix(fields[0], "--"), fields[1:]
+		arg := func(i int) string {
+			if i < len(args) {
+				return strings.Trim(args[i], `"`)
+			}
+			return ""
+		}
+
+		switch key {
+		case "remote":
+			if arg(0) == "" {
+				return nil, fmt.Errorf("remote without a host")
+			}
+			remote := arg(0)
+			if arg(1) != "" {
+				remote += ":" + arg(1)
+				if arg(2) != "" {
+					remote += ":" + arg(2)
+				}
+			}
+			remotes = append(remotes, remote)
+		case "port", "rport":
+			port = arg(0)
+		case "proto":
+			proto = arg(0)
+		case "dev":
+			profile.Data["dev"] = arg(0)
+		case "dev-type":
+			profile.Data["dev-type"] = arg(0)
+		case "cipher", "auth", "tun-mtu", "fragment", "mssfix", "reneg-sec", "remote-cert-tls", "tls-cipher", "tls-version-min":
+			profile.Data[key] = arg(0)
+		case "data-ciphers":
+			profile.Data["data-ciphers"] = arg(0)
+		case "comp-lzo":
+			profile.Data["comp-lzo"] = "adaptive"
+			if arg(0) == "no" {
+				profile.Data["comp-lzo"] = "no-by-default"
+			}
+		case "compress":
+			profile.Data["compress"] = "yes"
+			if arg(0) != "" {
+				profile.Data["compress"] = arg(0)
+			}
+		case "float":
+			profile.Data["float"] = "yes"
+		case "verify-x509-name":
+			kind := arg(1)
+			if kind == "" {
+				kind = "subject"
+			}
+			profile.Data["verify-x509-name"] = kind + ":" + arg(0)
+		case "key-direction":
+			keyDirection = arg(0)
+		case "auth-user-pass":
+			authUserPass = true
+		default:
+			if dataKey, ok := openVPNFileKeys[key]; ok && arg(0) != "" && arg(0) != "[inline]" {
+				path := arg(0)
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(dir, path)
+				}
+				profile.Data[dataKey] = path
+				if key == "tls-auth" && arg(1) != "" {
+					profile.Data["ta-dir"] = arg(1)
+				}
+			}
+		}
+	}
+	if inlineTag != "" {
+		return nil, fmt.Errorf("unterminated <%s> block", inlineTag)
+	}
+
+	if len(remotes) == 0 {
+		return nil, fmt.Errorf("no remote in OpenVPN config")
+	}
+	profile.Data["remote"] = strings.Join(remotes, ", ")
+	if port != "" {
+		profile.Data["port"] = port
+	}
+	if strings.HasPrefix(proto, "tcp") {
+		profile.Data["proto-tcp"] = "yes"
+	}
+	if keyDirection != "" && (profile.Inline["tls-auth"] != "" || profile.Data["ta"] != "") {
+		profile.Data["ta-dir"] = keyDirection
+	}
+
+	_, hasStatic := profile.Data["static-key"]
+	_, inlineStatic := profile.Inline["secret"]
+	_, hasCert := profile.Data["cert"]
+	_, inlineCert := profile.Inline["cert"]
+	switch {
+	case hasStatic || inlineStatic:
+		profile.Data["connection-type"] = "static-key"
+	case authUserPass && (hasCert || inlineCert):
+		profile.Data["connection-type"] = "password-tls"
+	case authUserPass:
+		profile.Data["connection-type"] = "password"
+	default:
+		profile.Data["connection-type"] = "tls"
+	}
+	if authUserPass {
+		// Agent-owned, so the password is asked for through the prompt broker
+		profile.Data["password-flags"] = "1"
+	}
+
+	return profile, nil
+}
+
+// writeInlineFiles saves inline certificates and keys next to the ones
+// nmcli's importer writes and points the profile at them
+func (p *openVPNProfile) writeInlineFiles(dir, name string) error {
+	if len(p.Inline) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	for tag, content := range p.Inline {
+		dataKey, ok := openVPNFileKeys[tag]
+		if !ok {
+			continue
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%s-%s.pem", name, tag))
+		if err := utils.WriteFileAtomic(path, []byte(content), 0600); err != nil {
+			return err
+		}
+		p.Data[dataKey] = path
+	}
+	return nil
+}
+
+func (p *openVPNProfile) settings(name string) map[string]map[string]interface{} {
+	return map[string]map[string]interface{}{
+		"connection": {
+			"id":          name,
+			"type":        "vpn",
+			"autoconnect": false,
+		},
+		"vpn": {
+			"service-type": openVPNServiceType,
+			"data":         p.Data,
+		},
+		"ipv4": {"method": "auto"},
+		"ipv6": {"method": "auto"},
+	}
+}
+
+func openVPNProfileName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
@@ -0,0 +1,92 @@
+package network
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOVPN = `client
+dev tun
+proto tcp-client
+remote vpn.example.com 1194
+remote backup.example.com 443 udp
+cipher AES-256-GCM
+auth SHA256
+remote-cert-tls server
+verify-x509-name server.example.com name
+auth-user-pass
+key-direction 1
+cert client.crt
+key /etc/openvpn/client.key
+<ca>
+-----BEGIN CERTIFICATE-----
+MIIB
+-----END CERTIFICATE-----
+</ca>
+<tls-auth>
+-----BEGIN OpenVPN Static key V1-----
+abcd
+-----END OpenVPN Static key V1-----
+</tls-auth>
+`
+
+func TestParseOpenVPNConfig(t *testing.T) {
+	profile, err := parseOpenVPNConfig(testOVPN, "/home/user/vpn")
+	require.NoError(t, err)
+
+	assert.Equal(t, "vpn.example.com:1194, backup.example.com:443:udp", profile.Data["remote"])
+	assert.Equal(t, "yes", profile.Data["proto-tcp"])
+	assert.Equal(t, "tun", profile.Data["dev"])
+	assert.Equal(t, "AES-256-GCM", profile.Data["cipher"])
+	assert.Equal(t, "name:server.example.com", profile.Data["verify-x509-name"])
+	assert.Equal(t, "password-tls", profile.Data["connection-type"])
+	assert.Equal(t, "1", profile.Data["password-flags"])
+	assert.Equal(t, "1", profile.Data["ta-dir"])
+	assert.Equal(t, "/home/user/vpn/client.crt", profile.Data["cert"])
+	assert.Equal(t, "/etc/openvpn/client.key", profile.Data["key"])
+	assert.Contains(t, profile.Inline["ca"], "MIIB\n")
+	assert.Contains(t, profile.Inline, "tls-auth")
+}
+
+func TestParseOpenVPNConfig_Errors(t *testing.T) {
+	_, err := parseOpenVPNConfig("client\ndev tun\n", "")
+	assert.ErrorContains(t, err, "no remote")
+
+	_, err = parseOpenVPNConfig("remote a 1194\n<ca>\nMIIB\n", "")
+	assert.ErrorContains(t, err, "unterminated <ca>")
+}
+
+func TestParseOpenVPNConfig_ConnectionType(t *testing.T) {
+	profile, err := parseOpenVPNConfig("remote a\nsecret static.key\n", "/etc/openvpn")
+	require.NoError(t, err)
+	assert.Equal(t, "static-key", profile.Data["connection-type"])
+	assert.Equal(t, "/etc/openvpn/static.key", profile.Data["static-key"])
+
+	profile, err = parseOpenVPNConfig("remote a\nauth-user-pass\nca ca.crt\n", "/etc/openvpn")
+	require.NoError(t, err)
+	assert.Equal(t, "password", profile.Data["connection-type"])
+}
+
+func TestOpenVPNProfile_WriteInlineFiles(t *testing.T) {
+	profile, err := parseOpenVPNConfig(testOVPN, "/home/user/vpn")
+	require.NoError(t, err)
+
+	dir := filepath.Join(t.TempDir(), "nm-openvpn")
+	require.NoError(t, profile.writeInlineFiles(dir, "office"))
+
+	caPath := filepath.Join(dir, "office-ca.pem")
+	assert.Equal(t, caPath, profile.Data["ca"])
+	assert.Equal(t, filepath.Join(dir, "office-tls-auth.pem"), profile.Data["ta"])
+
+	info, err := os.Stat(caPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	settings := profile.settings("office")
+	assert.Equal(t, "vpn", settings["connection"]["type"])
+	assert.Equal(t, openVPNServiceType, settings["vpn"]["service-type"])
+}
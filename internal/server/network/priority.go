@@ -18,7 +18,7 @@ func (m *Manager) SetConnectionPreference(pref ConnectionPreference) error {
 	m.state.Preference = pref
 	m.stateMutex.Unlock()
 
-	if _, ok := m.backend.(*NetworkManagerBackend); !ok {
+	if _, ok := m.currentBackend().(*NetworkManagerBackend); !ok {
 		m.notifySubscribers()
 		return nil
 	}
@@ -125,7 +125,7 @@ func (m *Manager) GetConnectionPreference() ConnectionPreference {
 }
 
 func (m *Manager) WasRecentlyFailed(ssid string) bool {
-	if nm, ok := m.backend.(*NetworkManagerBackend); ok {
+	if nm, ok := m.currentBackend().(*NetworkManagerBackend); ok {
 		nm.failedMutex.RLock()
 		defer nm.failedMutex.RUnlock()
 
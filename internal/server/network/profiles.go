@@ -0,0 +1,296 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/Wifx/gonetworkmanager/v2"
+	"github.com/godbus/dbus/v5"
+)
+
+const profileFileVersion = 1
+
+// ProfileFile is a NetworkManager connection profile exported to move it to
+// another machine. Values keep their D-Bus type and are written in GVariant
+// text format, so they can be handed back to NetworkManager unchanged
+type ProfileFile struct {
+	Version  int                                `json:"version"`
+	ID       string                             `json:"id"`
+	Type     string                             `json:"type"`
+	Secrets  bool                               `json:"secrets"`
+	Settings map[string]map[string]ProfileValue `json:"settings"`
+}
+
+type ProfileValue struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+const (
+	ConflictFail    = ""
+	ConflictReplace = "replace"
+	ConflictRename  = "rename"
+	ConflictSkip    = "skip"
+)
+
+type ImportResult struct {
+	ID     string `json:"id"`
+	Action string `json:"action"`
+}
+
+// machineKeys are tied to the machine a profile was made on
+var machineKeys = map[string][]string{
+	"connection":      {"timestamp"},
+	"802-11-wireless": {"mac-address", "seen-bssids"},
+	"802-3-ethernet":  {"mac-address"},
+}
+
+// secretSections are asked for their secrets when exporting with secrets
+var secretSections = []string{"802-11-wireless-security", "802-1x", "vpn"}
+
+// ExportProfile reads a saved profile, looked up by UUID, name or WiFi SSID
+func ExportProfile(name string, withSecrets bool) (*ProfileFile, error) {
+	conn, settings, err := findSavedProfile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if withSecrets {
+		for section, values := range profileSecrets(conn, settings, name) {
+			for key, value := range values {
+				settings[section][key] = value
+			}
+		}
+	}
+
+	return profileFromSettings(settings, withSecrets), nil
+}
+
+// profileSecrets asks NetworkManager for the secrets of each secret section
+// the profile has
+func profileSecrets(conn gonetworkmanager.Connection, settings gonetworkmanager.ConnectionSettings, name string) gonetworkmanager.ConnectionSettings {
+	result := make(gonetworkmanager.ConnectionSettings)
+	for _, section := range secretSections {
+		if _, ok := settings[section]; !ok {
+			continue
+		}
+		secrets, err := conn.GetSecrets(section)
+		if err != nil {
+			log.Warnf("Failed to get %s secrets for %s: %v", section, name, err)
+			continue
+		}
+		if len(secrets[section]) > 0 {
+			result[section] = secrets[section]
+		}
+	}
+	return result
+}
+
+// ImportProfile adds an exported profile. A saved profile with the same UUID
+// or the same name and type is a conflict, resolved as onConflict says
+func ImportProfile(p *ProfileFile, onConflict string) (*ImportResult, error) {
+	if err := checkConflictMode(onConflict); err != nil {
+		return nil, err
+	}
+
+	settings, err := profileToSettings(p)
+	if err != nil {
+		return nil, err
+	}
+
+	settingsMgr, err := gonetworkmanager.NewSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+	connections, err := settingsMgr.ListConnections()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connections: %w", err)
+	}
+
+	var saved []savedProfile
+	for _, conn := range connections {
+		s, err := conn.GetSettings()
+		if err != nil {
+			continue
+		}
+		saved = append(saved, savedProfileFrom(conn, s))
+	}
+
+	uuid, _ := settings["connection"]["uuid"].(string)
+	conflict := findProfileConflict(saved, uuid, p.ID, p.Type)
+	if conflict == nil {
+		if _, err := settingsMgr.AddConnection(settings); err != nil {
+			return nil, fmt.Errorf("failed to add profile: %w", err)
+		}
+		return &ImportResult{ID: p.ID, Action: "added"}, nil
+	}
+
+	switch onConflict {
+	case ConflictReplace:
+		settings["connection"]["uuid"] = conflict.uuid
+		if err := conflict.conn.Update(settings); err != nil {
+			return nil, fmt.Errorf("failed to replace %s: %w", conflict.id, err)
+		}
+		return &ImportResult{ID: p.ID, Action: "replaced"}, nil
+	case ConflictRename:
+		id := uniqueProfileName(saved, p.ID)
+		settings["connection"]["id"] = id
+		// NetworkManager assigns a new UUID
+		delete(settings["connection"], "uuid")
+		if _, err := settingsMgr.AddConnection(settings); err != nil {
+			return nil, fmt.Errorf("failed to add profile: %w", err)
+		}
+		return &ImportResult{ID: id, Action: "renamed"}, nil
+	case ConflictSkip:
+		return &ImportResult{ID: conflict.id, Action: "skipped"}, nil
+	}
+	return nil, fmt.Errorf("a profile named %s (%s) already exists; choose to replace, rename or skip it", conflict.id, conflict.uuid)
+}
+
+func checkConflictMode(onConflict string) error {
+	switch onConflict {
+	case ConflictFail, ConflictReplace, ConflictRename, ConflictSkip:
+		return nil
+	}
+	return fmt.Errorf("invalid conflict mode %q (use replace, rename or skip)", onConflict)
+}
+
+type savedProfile struct {
+	conn     gonetworkmanager.Connection
+	uuid     string
+	id       string
+	connType string
+	ssid     string
+}
+
+func savedProfileFrom(conn gonetworkmanager.Connection, settings gonetworkmanager.ConnectionSettings) savedProfile {
+	p := savedProfile{conn: conn}
+	p.uuid, _ = settings["connection"]["uuid"].(string)
+	p.id, _ = settings["connection"]["id"].(string)
+	p.connType, _ = settings["connection"]["type"].(string)
+	if ssid, ok := settings["802-11-wireless"]["ssid"].([]byte); ok {
+		p.ssid = string(ssid)
+	}
+	return p
+}
+
+func findSavedProfile(name string) (gonetworkmanager.Connection, gonetworkmanager.ConnectionSettings, error) {
+	settingsMgr, err := gonetworkmanager.NewSettings()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get settings: %w", err)
+	}
+	connections, err := settingsMgr.ListConnections()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get connections: %w", err)
+	}
+
+	var bySSID gonetworkmanager.Connection
+	var bySSIDSettings gonetworkmanager.ConnectionSettings
+	for _, conn := range connections {
+		settings, err := conn.GetSettings()
+		if err != nil {
+			continue
+		}
+		p := savedProfileFrom(conn, settings)
+		if p.uuid == name || p.id == name {
+			return conn, settings, nil
+		}
+		if p.ssid == name && bySSID == nil {
+			bySSID, bySSIDSettings = conn, settings
+		}
+	}
+	if bySSID != nil {
+		return bySSID, bySSIDSettings, nil
+	}
+	return nil, nil, fmt.Errorf("no saved profile matches %q", name)
+}
+
+func findProfileConflict(saved []savedProfile, uuid, id, connType string) *savedProfile {
+	for i := range saved {
+		if uuid != "" && saved[i].uuid == uuid {
+			return &saved[i]
+		}
+	}
+	for i := range saved {
+		if saved[i].id == id && saved[i].connType == connType {
+			return &saved[i]
+		}
+	}
+	return nil
+}
+
+func uniqueProfileName(saved []savedProfile, id string) string {
+	taken := make(map[string]bool, len(saved))
+	for _, p := range saved {
+		taken[p.id] = true
+	}
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)", id, n)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
+
+func profileFromSettings(settings gonetworkmanager.ConnectionSettings, withSecrets bool) *ProfileFile {
+	p := &ProfileFile{
+		Version:  profileFileVersion,
+		Secrets:  withSecrets,
+		Settings: make(map[string]map[string]ProfileValue, len(settings)),
+	}
+	p.ID, _ = settings["connection"]["id"].(string)
+	p.Type, _ = settings["connection"]["type"].(string)
+
+	for section, values := range settings {
+		p.Settings[section] = profileValues(section, values)
+	}
+	return p
+}
+
+func profileValues(section string, values map[string]interface{}) map[string]ProfileValue {
+	out := make(map[string]ProfileValue, len(values))
+	for key, value := range values {
+		if isMachineKey(section, key) {
+			continue
+		}
+		v := dbus.MakeVariant(value)
+		out[key] = ProfileValue{Type: v.Signature().String(), Value: v.String()}
+	}
+	return out
+}
+
+func profileToSettings(p *ProfileFile) (gonetworkmanager.ConnectionSettings, error) {
+	if p.Version > profileFileVersion {
+		return nil, fmt.Errorf("profile was exported by a newer dms (version %d)", p.Version)
+	}
+	if _, ok := p.Settings["connection"]; !ok {
+		return nil, fmt.Errorf("profile has no connection section")
+	}
+
+	settings := make(gonetworkmanager.ConnectionSettings, len(p.Settings))
+	for section, values := range p.Settings {
+		settings[section] = make(map[string]interface{}, len(values))
+		for key, value := range values {
+			sig, err := dbus.ParseSignature(value.Type)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: invalid type %q", section, key, value.Type)
+			}
+			v, err := dbus.ParseVariant(value.Value, sig)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", section, key, err)
+			}
+			settings[section][key] = v.Value()
+		}
+	}
+	dropDeprecatedAddressKeys(settings)
+	return settings, nil
+}
+
+func isMachineKey(section, key string) bool {
+	for _, k := range machineKeys[section] {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package network
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Wifx/gonetworkmanager/v2"
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileRoundTrip(t *testing.T) {
+	settings := gonetworkmanager.ConnectionSettings{
+		"connection": {
+			"id":        "Home",
+			"uuid":      "0b6a9c1e-6f1f-4c43-9a4b-1c2d3e4f5a6b",
+			"type":      "802-11-wireless",
+			"timestamp": uint64(1760000000),
+		},
+		"802-11-wireless": {
+			"ssid":        []byte("Home"),
+			"mac-address": []byte{1, 2, 3, 4, 5, 6},
+			"seen-bssids": []string{"aa:bb:cc:dd:ee:ff"},
+		},
+		"802-11-wireless-security": {
+			"key-mgmt": "wpa-psk",
+			"psk":      "secret123",
+		},
+		"ipv4": {
+			"method":       "manual",
+			"address-data": []map[string]dbus.Variant{{"address": dbus.MakeVariant("10.0.0.2"), "prefix": dbus.MakeVariant(uint32(24))}},
+			"dns":          []uint32{16843009},
+			"route-metric": int64(50),
+		},
+	}
+
+	p := profileFromSettings(settings, true)
+	assert.Equal(t, "Home", p.ID)
+	assert.Equal(t, "802-11-wireless", p.Type)
+	assert.True(t, p.Secrets)
+	assert.NotContains(t, p.Settings["connection"], "timestamp")
+	assert.NotContains(t, p.Settings["802-11-wireless"], "mac-address")
+	assert.NotContains(t, p.Settings["802-11-wireless"], "seen-bssids")
+
+	data, err := json.Marshal(p)
+	require.NoError(t, err)
+	var decoded ProfileFile
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	restored, err := profileToSettings(&decoded)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("Home"), restored["802-11-wireless"]["ssid"])
+	assert.Equal(t, "secret123", restored["802-11-wireless-security"]["psk"])
+	assert.Equal(t, []uint32{16843009}, restored["ipv4"]["dns"])
+	assert.Equal(t, int64(50), restored["ipv4"]["route-metric"])
+	assert.Equal(t, "0b6a9c1e-6f1f-4c43-9a4b-1c2d3e4f5a6b", restored["connection"]["uuid"])
+
+	addrs, ok := restored["ipv4"]["address-data"].([]map[string]dbus.Variant)
+	require.True(t, ok)
+	assert.Equal(t, uint32(24), addrs[0]["prefix"].Value())
+}
+
+func TestProfileToSettings_Invalid(t *testing.T) {
+	_, err := profileToSettings(&ProfileFile{Version: 1})
+	assert.Error(t, err)
+
+	_, err = profileToSettings(&ProfileFile{Version: 2, Settings: map[string]map[string]ProfileValue{"connection": {}}})
+	assert.Error(t, err)
+
+	_, err = profileToSettings(&ProfileFile{Version: 1, Settings: map[string]map[string]ProfileValue{
+		"connection": {"id": {Type: "u", Value: "\"Home\""}},
+	}})
+	assert.Error(t, err)
+}
+
+func TestFindProfileConflict(t *testing.T) {
+	saved := []savedProfile{
+		{uuid: "a", id: "Home", connType: "802-11-wireless"},
+		{uuid: "b", id: "Office", connType: "vpn"},
+	}
+
+	assert.Equal(t, "a", findProfileConflict(saved, "a", "Renamed", "802-11-wireless").uuid)
+	assert.Equal(t, "b", findProfileConflict(saved, "c", "Office", "vpn").uuid)
+	assert.Nil(t, findProfileConflict(saved, "c", "Office", "802-11-wireless"))
+	assert.Nil(t, findProfileConflict(saved, "", "Cafe", "802-11-wireless"))
+}
+
+func TestUniqueProfileName(t *testing.T) {
+	saved := []savedProfile{{id: "Home"}, {id: "Home (2)"}}
+	assert.Equal(t, "Home (3)", uniqueProfileName(saved, "Home"))
+	assert.Equal(t, "Office (2)", uniqueProfileName(saved, "Office"))
+}
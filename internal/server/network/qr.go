@@ -0,0 +1,132 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	qrPNGSize          = 512
+	qrConfirmTimeout   = 2 * time.Minute
+	qrShareSettingName = "share"
+)
+
+// WiFiQR is a saved network in the WIFI: format phones scan to join it. PNG
+// is base64 encoded in JSON
+type WiFiQR struct {
+	SSID    string `json:"ssid"`
+	Payload string `json:"payload"`
+	PNG     []byte `json:"png"`
+}
+
+// WiFiQRCode reads a saved WiFi profile and its password, which
+// NetworkManager fetches from the secret agent when it isn't stored system
+// wide
+func WiFiQRCode(ssid string) (*WiFiQR, error) {
+	conn, settings, err := findSavedProfile(ssid)
+	if err != nil {
+		return nil, err
+	}
+	wireless, ok := settings["802-11-wireless"]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a WiFi network", ssid)
+	}
+	if raw, ok := wireless["ssid"].([]byte); ok {
+		ssid = string(raw)
+	}
+	hidden, _ := wireless["hidden"].(bool)
+
+	var security, password string
+	sec, secured := settings["802-11-wireless-security"]
+	keyMgmt, _ := sec["key-mgmt"].(string)
+	switch {
+	case !secured, keyMgmt == "owe":
+		security = "nopass"
+	case keyMgmt == "wpa-psk", keyMgmt == "sae":
+		security = "WPA"
+	case keyMgmt == "none":
+		security = "WEP"
+	default:
+		return nil, fmt.Errorf("%s uses %s, which can't be shared as a QR code", ssid, keyMgmt)
+	}
+
+	if security != "nopass" {
+		secrets, err := conn.GetSecrets("802-11-wireless-security")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the password of %s: %w", ssid, err)
+		}
+		key := "psk"
+		if security == "WEP" {
+			key = "wep-key0"
+		}
+		password, _ = secrets["802-11-wireless-security"][key].(string)
+		if password == "" {
+			return nil, fmt.Errorf("the password of %s is not saved", ssid)
+		}
+	}
+
+	payload := wifiQRPayload(ssid, security, password, hidden)
+	png, err := qrcode.Encode(payload, qrcode.Medium, qrPNGSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render QR code: %w", err)
+	}
+	return &WiFiQR{SSID: ssid, Payload: payload, PNG: png}, nil
+}
+
+func wifiQRPayload(ssid, security, password string, hidden bool) string {
+	var b strings.Builder
+	b.WriteString("WIFI:T:" + security + ";S:" + qrEscape(ssid) + ";")
+	if security != "nopass" {
+		b.WriteString("P:" + qrEscape(password) + ";")
+	}
+	if hidden {
+		b.WriteString("H:true;")
+	}
+	b.WriteString(";")
+	return b.String()
+}
+
+func qrEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`\;,:"`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ShareWiFi builds the QR code of a saved network once the user confirms
+// showing its password through the prompt broker
+func (m *Manager) ShareWiFi(ssid string) (*WiFiQR, error) {
+	if _, ok := m.currentBackend().(*NetworkManagerBackend); !ok {
+		return nil, fmt.Errorf("only available with the NetworkManager backend")
+	}
+
+	if broker := m.currentBackend().GetPromptBroker(); broker != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), qrConfirmTimeout)
+		defer cancel()
+
+		token, err := broker.Ask(ctx, PromptRequest{
+			Name:        ssid,
+			SSID:        ssid,
+			ConnType:    "802-11-wireless",
+			SettingName: qrShareSettingName,
+			Reason:      qrShareSettingName,
+			Fields:      []string{},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to ask for confirmation: %w", err)
+		}
+		if _, err := broker.Wait(ctx, token); err != nil {
+			return nil, err
+		}
+	}
+
+	return WiFiQRCode(ssid)
+}
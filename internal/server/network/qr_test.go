@@ -0,0 +1,13 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWiFiQRPayload(t *testing.T) {
+	assert.Equal(t, "WIFI:T:WPA;S:Home;P:hunter2;;", wifiQRPayload("Home", "WPA", "hunter2", false))
+	assert.Equal(t, "WIFI:T:nopass;S:Cafe;H:true;;", wifiQRPayload("Cafe", "nopass", "", true))
+	assert.Equal(t, `WIFI:T:WPA;S:a\;b\,c;P:p\:w\\d\";;`, wifiQRPayload("a;b,c", "WPA", `p:w\d"`, false))
+}
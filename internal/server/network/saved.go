@@ -0,0 +1,179 @@
+package network
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/Wifx/gonetworkmanager/v2"
+)
+
+// SavedWiFiNetwork is a saved WiFi profile, in range or not. Security uses
+// iwd's names (open, wep, psk, 8021x) for both backends; UUID is only set
+// with NetworkManager and LastConnected is empty for networks never joined
+type SavedWiFiNetwork struct {
+	SSID          string `json:"ssid"`
+	UUID          string `json:"uuid,omitempty"`
+	Security      string `json:"security"`
+	Hidden        bool   `json:"hidden"`
+	AutoConnect   bool   `json:"autoConnect"`
+	LastConnected string `json:"lastConnected,omitempty"`
+	InRange       bool   `json:"inRange"`
+	Connected     bool   `json:"connected"`
+}
+
+// ForgetResult lists which networks a bulk forget removed and why the
+// others failed
+type ForgetResult struct {
+	Forgotten []string          `json:"forgotten"`
+	Failed    map[string]string `json:"failed,omitempty"`
+}
+
+// savedWiFiFromSettings reads a NetworkManager profile, skipping non-WiFi
+// and hotspot profiles
+func savedWiFiFromSettings(settings gonetworkmanager.ConnectionSettings) (SavedWiFiNetwork, bool) {
+	var saved SavedWiFiNetwork
+	if connType, _ := settings["connection"]["type"].(string); connType != "802-11-wireless" {
+		return saved, false
+	}
+	wireless := settings["802-11-wireless"]
+	if mode, _ := wireless["mode"].(string); mode == "ap" {
+		return saved, false
+	}
+	ssid, ok := wireless["ssid"].([]byte)
+	if !ok || len(ssid) == 0 {
+		return saved, false
+	}
+
+	saved.SSID = string(ssid)
+	saved.UUID, _ = settings["connection"]["uuid"].(string)
+	saved.Hidden, _ = wireless["hidden"].(bool)
+	saved.AutoConnect = true
+	if autoconnect, ok := settings["connection"]["autoconnect"].(bool); ok {
+		saved.AutoConnect = autoconnect
+	}
+	if timestamp, ok := settings["connection"]["timestamp"].(uint64); ok && timestamp > 0 {
+		saved.LastConnected = time.Unix(int64(timestamp), 0).UTC().Format(time.RFC3339)
+	}
+
+	saved.Security = "open"
+	if sec, ok := settings["802-11-wireless-security"]; ok {
+		switch keyMgmt, _ := sec["key-mgmt"].(string); keyMgmt {
+		case "wpa-psk", "sae":
+			saved.Security = "psk"
+		case "wpa-eap", "wpa-eap-suite-b-192":
+			saved.Security = "8021x"
+		case "none", "ieee8021x":
+			saved.Security = "wep"
+		}
+	}
+	return saved, true
+}
+
+// sortSavedWiFi puts the most recently used networks first
+func sortSavedWiFi(networks []SavedWiFiNetwork) {
+	sort.SliceStable(networks, func(i, j int) bool {
+		if networks[i].LastConnected != networks[j].LastConnected {
+			return networks[i].LastConnected > networks[j].LastConnected
+		}
+		return networks[i].SSID < networks[j].SSID
+	})
+}
+
+func savedWiFiFromKnown(known []KnownNetwork) []SavedWiFiNetwork {
+	networks := make([]SavedWiFiNetwork, 0, len(known))
+	for _, k := range known {
+		networks = append(networks, SavedWiFiNetwork{
+			SSID:          k.SSID,
+			Security:      k.Security,
+			Hidden:        k.Hidden,
+			AutoConnect:   k.AutoConnect,
+			LastConnected: k.LastConnected,
+		})
+	}
+	return networks
+}
+
+// ListSavedWiFiNetworks returns every saved WiFi network, marking the ones
+// in the last scan and the one connected
+func (m *Manager) ListSavedWiFiNetworks() ([]SavedWiFiNetwork, error) {
+	networks, err := m.currentBackend().ListSavedWiFiNetworks()
+	if err != nil {
+		return nil, err
+	}
+
+	m.stateMutex.RLock()
+	inRange := make(map[string]bool, len(m.state.WiFiNetworks))
+	for _, n := range m.state.WiFiNetworks {
+		inRange[n.SSID] = true
+	}
+	connectedSSID := ""
+	if m.state.WiFiConnected {
+		connectedSSID = m.state.WiFiSSID
+	}
+	m.stateMutex.RUnlock()
+
+	for i := range networks {
+		networks[i].InRange = inRange[networks[i].SSID]
+		networks[i].Connected = networks[i].SSID == connectedSSID
+	}
+	return networks, nil
+}
+
+// ForgetSavedWiFiNetworks forgets each of ssids, or every saved network when
+// all is set, carrying on past failures
+func (m *Manager) ForgetSavedWiFiNetworks(ssids []string, all bool) (*ForgetResult, error) {
+	if all {
+		saved, err := m.currentBackend().ListSavedWiFiNetworks()
+		if err != nil {
+			return nil, err
+		}
+		ssids = make([]string, 0, len(saved))
+		for _, n := range saved {
+			ssids = append(ssids, n.SSID)
+		}
+	}
+
+	result := &ForgetResult{Forgotten: []string{}}
+	for _, ssid := range ssids {
+		if err := m.ForgetWiFiNetwork(ssid); err != nil {
+			if result.Failed == nil {
+				result.Failed = make(map[string]string)
+			}
+			result.Failed[ssid] = err.Error()
+			continue
+		}
+		result.Forgotten = append(result.Forgotten, ssid)
+	}
+	return result, nil
+}
+
+// ExportSavedWiFiNetworks exports every saved WiFi profile in the format of
+// dms network export; that format is NetworkManager's, so iwd can't
+func (m *Manager) ExportSavedWiFiNetworks(withSecrets bool) ([]*ProfileFile, error) {
+	if _, ok := m.currentBackend().(*NetworkManagerBackend); !ok {
+		return nil, fmt.Errorf("exporting profiles is only available with the NetworkManager backend")
+	}
+
+	saved, err := m.currentBackend().ListSavedWiFiNetworks()
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]*ProfileFile, 0, len(saved))
+	var lastErr error
+	for _, n := range saved {
+		profile, err := ExportProfile(n.UUID, withSecrets)
+		if err != nil {
+			log.Warnf("Failed to export %s: %v", n.SSID, err)
+			lastErr = err
+			continue
+		}
+		profiles = append(profiles, profile)
+	}
+	if len(profiles) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return profiles, nil
+}
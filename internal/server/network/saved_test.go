@@ -0,0 +1,66 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/Wifx/gonetworkmanager/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSavedWiFiFromSettings(t *testing.T) {
+	settings := gonetworkmanager.ConnectionSettings{
+		"connection": {
+			"type":        "802-11-wireless",
+			"uuid":        "uuid-home",
+			"autoconnect": false,
+			"timestamp":   uint64(1760551451),
+		},
+		"802-11-wireless":          {"ssid": []byte("Home"), "hidden": true},
+		"802-11-wireless-security": {"key-mgmt": "sae"},
+	}
+
+	saved, ok := savedWiFiFromSettings(settings)
+	assert.True(t, ok)
+	assert.Equal(t, SavedWiFiNetwork{
+		SSID:          "Home",
+		UUID:          "uuid-home",
+		Security:      "psk",
+		Hidden:        true,
+		AutoConnect:   false,
+		LastConnected: "2025-10-15T18:04:11Z",
+	}, saved)
+
+	open, ok := savedWiFiFromSettings(gonetworkmanager.ConnectionSettings{
+		"connection":      {"type": "802-11-wireless"},
+		"802-11-wireless": {"ssid": []byte("Airport")},
+	})
+	assert.True(t, ok)
+	assert.Equal(t, "open", open.Security)
+	assert.True(t, open.AutoConnect)
+	assert.Empty(t, open.LastConnected)
+
+	_, ok = savedWiFiFromSettings(gonetworkmanager.ConnectionSettings{
+		"connection":      {"type": "802-11-wireless"},
+		"802-11-wireless": {"ssid": []byte("Laptop"), "mode": "ap"},
+	})
+	assert.False(t, ok, "hotspot profiles are not saved networks")
+
+	_, ok = savedWiFiFromSettings(gonetworkmanager.ConnectionSettings{"connection": {"type": "802-3-ethernet"}})
+	assert.False(t, ok)
+}
+
+func TestSortSavedWiFi(t *testing.T) {
+	networks := []SavedWiFiNetwork{
+		{SSID: "Never"},
+		{SSID: "Old", LastConnected: "2025-01-01T00:00:00Z"},
+		{SSID: "Recent", LastConnected: "2026-10-01T00:00:00Z"},
+		{SSID: "Also never"},
+	}
+	sortSavedWiFi(networks)
+
+	var order []string
+	for _, n := range networks {
+		order = append(order, n.SSID)
+	}
+	assert.Equal(t, []string{"Recent", "Old", "Also never", "Never"}, order)
+}
@@ -0,0 +1,146 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/secrets"
+)
+
+// storedSecretWindow is how long after handing out stored secrets a new
+// request for the same network means they were rejected
+const storedSecretWindow = 30 * time.Second
+
+const keyringSchema = "com.danklinux.dms.NetworkSecret"
+
+// SecretStore keeps the secrets a user chose to save in the prompt, for
+// backends like iwd that don't keep every kind of secret themselves
+type SecretStore interface {
+	// Lookup returns all of fields, or secrets.ErrNotFound when one is missing
+	Lookup(ssid string, fields []string) (map[string]string, error)
+	Save(ssid string, values map[string]string) error
+	Delete(ssid string) error
+}
+
+// KeyringSecretStore keeps one keyring item per network and field, like
+// NetworkManager's own keyring schema
+type KeyringSecretStore struct{}
+
+func keyringSecretAttributes(ssid, field string) map[string]string {
+	attributes := map[string]string{"xdg:schema": keyringSchema, "ssid": ssid}
+	if field != "" {
+		attributes["field"] = field
+	}
+	return attributes
+}
+
+func (KeyringSecretStore) Lookup(ssid string, fields []string) (map[string]string, error) {
+	values := make(map[string]string, len(fields))
+	for _, field := range fields {
+		value, err := secrets.KeyringLookup(keyringSecretAttributes(ssid, field))
+		if err != nil {
+			return nil, err
+		}
+		values[field] = string(value)
+	}
+	return values, nil
+}
+
+func (KeyringSecretStore) Save(ssid string, values map[string]string) error {
+	for field, value := range values {
+		label := fmt.Sprintf("Network secret (%s) for %s", field, ssid)
+		if err := secrets.KeyringStore(label, keyringSecretAttributes(ssid, field), []byte(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (KeyringSecretStore) Delete(ssid string) error {
+	return secrets.KeyringDelete(keyringSecretAttributes(ssid, ""))
+}
+
+// agentSecrets answers agent requests from a SecretStore. Stored secrets are
+// handed out once per attempt, so a rejected secret falls back to the prompt
+type agentSecrets struct {
+	store SecretStore
+	now   func() time.Time
+
+	mu sync.Mutex
+	// given is when stored or newly saved secrets were last used per network
+	given map[string]time.Time
+}
+
+func newAgentSecrets(store SecretStore) *agentSecrets {
+	return &agentSecrets{store: store, now: time.Now, given: make(map[string]time.Time)}
+}
+
+func (s *agentSecrets) lookup(ssid string, fields []string) (map[string]string, bool) {
+	if s == nil || s.store == nil {
+		return nil, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if at, ok := s.given[ssid]; ok && s.now().Sub(at) < storedSecretWindow {
+		return nil, false
+	}
+
+	values, err := s.store.Lookup(ssid, fields)
+	if err != nil {
+		if !errors.Is(err, secrets.ErrNotFound) {
+			log.Warnf("Failed to look up saved secrets for %s: %v", ssid, err)
+		}
+		return nil, false
+	}
+	s.given[ssid] = s.now()
+	return values, true
+}
+
+// save keeps the requested fields of a reply the user asked to save
+func (s *agentSecrets) save(ssid string, fields []string, reply PromptReply) {
+	if s == nil || s.store == nil || !reply.Save {
+		return
+	}
+
+	values := make(map[string]string, len(fields))
+	for _, field := range fields {
+		if value, ok := reply.Secrets[field]; ok {
+			values[field] = value
+		}
+	}
+	if len(values) == 0 {
+		return
+	}
+	if err := s.store.Save(ssid, values); err != nil {
+		log.Warnf("Failed to save secrets for %s: %v", ssid, err)
+		return
+	}
+
+	s.mu.Lock()
+	s.given[ssid] = s.now()
+	s.mu.Unlock()
+}
+
+// rejected drops the secrets used by the attempt that just failed to
+// authenticate
+func (s *agentSecrets) rejected(ssid string) {
+	if s == nil || s.store == nil {
+		return
+	}
+
+	s.mu.Lock()
+	at, ok := s.given[ssid]
+	delete(s.given, ssid)
+	s.mu.Unlock()
+	if !ok || s.now().Sub(at) >= storedSecretWindow {
+		return
+	}
+
+	if err := s.store.Delete(ssid); err != nil {
+		log.Warnf("Failed to remove rejected secrets for %s: %v", ssid, err)
+	}
+}
@@ -0,0 +1,95 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/secrets"
+	"github.com/stretchr/testify/assert"
+)
+
+type memSecretStore struct {
+	values map[string]map[string]string
+}
+
+func (s *memSecretStore) Lookup(ssid string, fields []string) (map[string]string, error) {
+	out := make(map[string]string, len(fields))
+	for _, field := range fields {
+		value, ok := s.values[ssid][field]
+		if !ok {
+			return nil, secrets.ErrNotFound
+		}
+		out[field] = value
+	}
+	return out, nil
+}
+
+func (s *memSecretStore) Save(ssid string, values map[string]string) error {
+	if s.values[ssid] == nil {
+		s.values[ssid] = make(map[string]string)
+	}
+	for field, value := range values {
+		s.values[ssid][field] = value
+	}
+	return nil
+}
+
+func (s *memSecretStore) Delete(ssid string) error {
+	delete(s.values, ssid)
+	return nil
+}
+
+func TestAgentSecrets_SaveAndLookup(t *testing.T) {
+	store := &memSecretStore{values: make(map[string]map[string]string)}
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	s := newAgentSecrets(store)
+	s.now = func() time.Time { return now }
+
+	fields := []string{"identity", "password"}
+	_, ok := s.lookup("Corp", fields)
+	assert.False(t, ok)
+
+	s.save("Corp", fields, PromptReply{Secrets: map[string]string{"identity": "me", "password": "pw"}})
+	assert.Empty(t, store.values, "secrets are only kept when the user asks to save them")
+
+	s.save("Corp", fields, PromptReply{Secrets: map[string]string{"identity": "me", "password": "pw", "extra": "x"}, Save: true})
+	assert.Equal(t, map[string]string{"identity": "me", "password": "pw"}, store.values["Corp"])
+
+	// The attempt that saved them already used them
+	_, ok = s.lookup("Corp", fields)
+	assert.False(t, ok)
+
+	now = now.Add(time.Hour)
+	values, ok := s.lookup("Corp", fields)
+	assert.True(t, ok)
+	assert.Equal(t, "pw", values["password"])
+
+	// A second request in the same attempt prompts instead
+	_, ok = s.lookup("Corp", fields)
+	assert.False(t, ok)
+}
+
+func TestAgentSecrets_Rejected(t *testing.T) {
+	store := &memSecretStore{values: map[string]map[string]string{"Corp": {"password": "old"}}}
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	s := newAgentSecrets(store)
+	s.now = func() time.Time { return now }
+
+	// Failures of attempts that didn't use stored secrets keep them
+	s.rejected("Corp")
+	assert.Contains(t, store.values, "Corp")
+
+	_, ok := s.lookup("Corp", []string{"password"})
+	assert.True(t, ok)
+	now = now.Add(5 * time.Second)
+	s.rejected("Corp")
+	assert.NotContains(t, store.values, "Corp")
+}
+
+func TestAgentSecrets_NoStore(t *testing.T) {
+	s := newAgentSecrets(nil)
+	_, ok := s.lookup("Corp", []string{"psk"})
+	assert.False(t, ok)
+	s.save("Corp", []string{"psk"}, PromptReply{Secrets: map[string]string{"psk": "x"}, Save: true})
+	s.rejected("Corp")
+}
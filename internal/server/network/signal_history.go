@@ -0,0 +1,184 @@
+package network
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
+)
+
+const (
+	signalHistoryWindow  = 30 * time.Minute
+	signalSampleInterval = 5 * time.Second
+	maxRoamEvents        = 100
+)
+
+type SignalSample struct {
+	Time   time.Time `json:"time"`
+	Signal uint8     `json:"signal"`
+}
+
+// BSSIDHistory is the signal seen while connected to one access point
+type BSSIDHistory struct {
+	BSSID   string         `json:"bssid"`
+	SSID    string         `json:"ssid"`
+	Samples []SignalSample `json:"samples"`
+}
+
+// RoamEvent is a move between access points of the same network without
+// disconnecting
+type RoamEvent struct {
+	Time       time.Time `json:"time"`
+	SSID       string    `json:"ssid"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	FromSignal uint8     `json:"fromSignal"`
+	ToSignal   uint8     `json:"toSignal"`
+}
+
+type SignalHistory struct {
+	Since  time.Time      `json:"since"`
+	BSSIDs []BSSIDHistory `json:"bssids"`
+	Roams  []RoamEvent    `json:"roams"`
+}
+
+// signalHistory keeps the signal of the connected access point over the last
+// signalHistoryWindow. Like bandwidth it is sampled the same way for every
+// backend, so it lives in the manager
+type signalHistory struct {
+	mu      sync.Mutex
+	samples map[string][]SignalSample
+	ssids   map[string]string
+	roams   []RoamEvent
+
+	lastSSID   string
+	lastBSSID  string
+	lastSignal uint8
+}
+
+func newSignalHistory() *signalHistory {
+	return &signalHistory{
+		samples: make(map[string][]SignalSample),
+		ssids:   make(map[string]string),
+	}
+}
+
+func (h *signalHistory) record(now time.Time, connected bool, ssid, bssid string, signal uint8) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.prune(now)
+
+	if !connected || bssid == "" {
+		h.lastSSID, h.lastBSSID, h.lastSignal = "", "", 0
+		return
+	}
+
+	if h.lastBSSID != "" && h.lastBSSID != bssid && h.lastSSID == ssid {
+		h.roams = append(h.roams, RoamEvent{
+			Time:       now,
+			SSID:       ssid,
+			From:       h.lastBSSID,
+			To:         bssid,
+			FromSignal: h.lastSignal,
+			ToSignal:   signal,
+		})
+		if len(h.roams) > maxRoamEvents {
+			h.roams = h.roams[len(h.roams)-maxRoamEvents:]
+		}
+	}
+
+	samples := h.samples[bssid]
+	// State changes and the ticker can both land within the same second
+	if n := len(samples); n > 0 && now.Sub(samples[n-1].Time) < time.Second {
+		samples[n-1].Signal = signal
+	} else {
+		h.samples[bssid] = append(samples, SignalSample{Time: now, Signal: signal})
+	}
+	h.ssids[bssid] = ssid
+	h.lastSSID, h.lastBSSID, h.lastSignal = ssid, bssid, signal
+}
+
+func (h *signalHistory) prune(now time.Time) {
+	cutoff := now.Add(-signalHistoryWindow)
+	for bssid, samples := range h.samples {
+		i := sort.Search(len(samples), func(i int) bool { return !samples[i].Time.Before(cutoff) })
+		if i == len(samples) {
+			delete(h.samples, bssid)
+			delete(h.ssids, bssid)
+			continue
+		}
+		h.samples[bssid] = samples[i:]
+	}
+
+	i := sort.Search(len(h.roams), func(i int) bool { return !h.roams[i].Time.Before(cutoff) })
+	h.roams = h.roams[i:]
+}
+
+// snapshot returns what was recorded within the last window, with the most
+// recently seen access point first
+func (h *signalHistory) snapshot(now time.Time, window time.Duration) SignalHistory {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if window <= 0 || window > signalHistoryWindow {
+		window = signalHistoryWindow
+	}
+	since := now.Add(-window)
+
+	history := SignalHistory{Since: since, BSSIDs: []BSSIDHistory{}, Roams: []RoamEvent{}}
+	for bssid, samples := range h.samples {
+		var recent []SignalSample
+		for _, s := range samples {
+			if !s.Time.Before(since) {
+				recent = append(recent, s)
+			}
+		}
+		if len(recent) > 0 {
+			history.BSSIDs = append(history.BSSIDs, BSSIDHistory{BSSID: bssid, SSID: h.ssids[bssid], Samples: recent})
+		}
+	}
+	sort.Slice(history.BSSIDs, func(i, j int) bool {
+		a, b := history.BSSIDs[i].Samples, history.BSSIDs[j].Samples
+		return a[len(a)-1].Time.After(b[len(b)-1].Time)
+	})
+
+	for _, roam := range h.roams {
+		if !roam.Time.Before(since) {
+			history.Roams = append(history.Roams, roam)
+		}
+	}
+	return history
+}
+
+func (m *Manager) recordSignal(now time.Time) {
+	m.stateMutex.RLock()
+	connected, ssid, bssid, signal := m.state.WiFiConnected, m.state.WiFiSSID, m.state.WiFiBSSID, m.state.WiFiSignal
+	m.stateMutex.RUnlock()
+
+	m.signalHistory.record(now, connected, ssid, bssid, signal)
+}
+
+func (m *Manager) signalMonitor() {
+	defer m.notifierWg.Done()
+
+	ticker := time.NewTicker(signalSampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case now := <-ticker.C:
+			m.recordSignal(now)
+			ticker.Reset(budget.Scale(signalSampleInterval))
+		}
+	}
+}
+
+// GetSignalHistory returns the WiFi signal per access point and the roams
+// over the last window, at most 30 minutes
+func (m *Manager) GetSignalHistory(window time.Duration) SignalHistory {
+	return m.signalHistory.snapshot(time.Now(), window)
+}
@@ -0,0 +1,75 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignalHistory_RecordsRoams(t *testing.T) {
+	h := newSignalHistory()
+	start := time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC)
+
+	h.record(start, true, "Home", "AA:01", 40)
+	h.record(start.Add(5*time.Second), true, "Home", "AA:01", 30)
+	h.record(start.Add(10*time.Second), true, "Home", "AA:02", 70)
+
+	history := h.snapshot(start.Add(15*time.Second), 0)
+	require.Len(t, history.BSSIDs, 2)
+	assert.Equal(t, "AA:02", history.BSSIDs[0].BSSID)
+	assert.Len(t, history.BSSIDs[1].Samples, 2)
+
+	require.Len(t, history.Roams, 1)
+	roam := history.Roams[0]
+	assert.Equal(t, "AA:01", roam.From)
+	assert.Equal(t, "AA:02", roam.To)
+	assert.Equal(t, uint8(30), roam.FromSignal)
+	assert.Equal(t, uint8(70), roam.ToSignal)
+}
+
+func TestSignalHistory_DisconnectIsNotARoam(t *testing.T) {
+	h := newSignalHistory()
+	start := time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC)
+
+	h.record(start, true, "Home", "AA:01", 40)
+	h.record(start.Add(5*time.Second), false, "", "", 0)
+	h.record(start.Add(10*time.Second), true, "Home", "AA:02", 70)
+	h.record(start.Add(15*time.Second), true, "Cafe", "BB:01", 50)
+
+	assert.Empty(t, h.snapshot(start.Add(20*time.Second), 0).Roams)
+}
+
+func TestSignalHistory_MergesSamplesWithinASecond(t *testing.T) {
+	h := newSignalHistory()
+	start := time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC)
+
+	h.record(start, true, "Home", "AA:01", 40)
+	h.record(start.Add(200*time.Millisecond), true, "Home", "AA:01", 42)
+
+	history := h.snapshot(start.Add(time.Second), 0)
+	require.Len(t, history.BSSIDs, 1)
+	require.Len(t, history.BSSIDs[0].Samples, 1)
+	assert.Equal(t, uint8(42), history.BSSIDs[0].Samples[0].Signal)
+}
+
+func TestSignalHistory_Window(t *testing.T) {
+	h := newSignalHistory()
+	start := time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC)
+
+	h.record(start, true, "Home", "AA:01", 40)
+	h.record(start.Add(20*time.Minute), true, "Home", "AA:02", 60)
+
+	history := h.snapshot(start.Add(25*time.Minute), 10*time.Minute)
+	require.Len(t, history.BSSIDs, 1)
+	assert.Equal(t, "AA:02", history.BSSIDs[0].BSSID)
+	assert.Len(t, history.Roams, 1)
+
+	// Samples older than the retention window are dropped
+	h.record(start.Add(55*time.Minute), true, "Home", "AA:02", 60)
+	history = h.snapshot(start.Add(55*time.Minute), 0)
+	require.Len(t, history.BSSIDs, 1)
+	assert.Equal(t, "AA:02", history.BSSIDs[0].BSSID)
+	assert.Empty(t, history.Roams)
+}
@@ -4,25 +4,42 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/AvengeMedia/danklinux/internal/errdefs"
 	"github.com/AvengeMedia/danklinux/internal/log"
 )
 
+// defaultPromptTimeout is how long a shown prompt waits for an answer before
+// it is cancelled, so a dialog nobody answers doesn't hold up the queue
+const defaultPromptTimeout = 2 * time.Minute
+
+// SubscriptionBroker shows one prompt at a time: overlapping requests (a
+// WiFi password while a VPN asks for its own) queue up and are broadcast in
+// turn. With several UI clients attached, the first to claim a prompt is the
+// only one that can answer it
 type SubscriptionBroker struct {
-	mu                 sync.RWMutex
-	pending            map[string]chan PromptReply
-	requests           map[string]PromptRequest
+	mu                 sync.Mutex
+	prompts            map[string]*brokerPrompt
+	queue              []string
 	pathSettingToToken map[string]string
 	broadcastPrompt    func(CredentialPrompt)
+	timeout            time.Duration
+}
+
+type brokerPrompt struct {
+	req   PromptRequest
+	reply chan PromptReply
+	owner string
+	timer *time.Timer
 }
 
 func NewSubscriptionBroker(broadcastPrompt func(CredentialPrompt)) PromptBroker {
 	return &SubscriptionBroker{
-		pending:            make(map[string]chan PromptReply),
-		requests:           make(map[string]PromptRequest),
+		prompts:            make(map[string]*brokerPrompt),
 		pathSettingToToken: make(map[string]string),
 		broadcastPrompt:    broadcastPrompt,
+		timeout:            defaultPromptTimeout,
 	}
 }
 
@@ -43,37 +60,67 @@ func (b *SubscriptionBroker) Ask(ctx context.Context, req PromptRequest) (string
 		return "", err
 	}
 
-	replyChan := make(chan PromptReply, 1)
 	b.mu.Lock()
-	b.pending[token] = replyChan
-	b.requests[token] = req
+	b.prompts[token] = &brokerPrompt{req: req, reply: make(chan PromptReply, 1)}
 	b.pathSettingToToken[pathSettingKey] = token
+	b.queue = append(b.queue, token)
+	first := len(b.queue) == 1
 	b.mu.Unlock()
 
-	if b.broadcastPrompt != nil {
-		prompt := CredentialPrompt{
-			Token:          token,
-			Name:           req.Name,
-			SSID:           req.SSID,
-			ConnType:       req.ConnType,
-			VpnService:     req.VpnService,
-			Setting:        req.SettingName,
-			Fields:         req.Fields,
-			Hints:          req.Hints,
-			Reason:         req.Reason,
-			ConnectionId:   req.ConnectionId,
-			ConnectionUuid: req.ConnectionUuid,
-		}
-		b.broadcastPrompt(prompt)
+	if first {
+		b.show(token)
+	} else {
+		log.Infof("[SubscriptionBroker] Queued prompt for %s behind %d others", pathSettingKey, len(b.queue)-1)
 	}
 
 	return token, nil
 }
 
+// show broadcasts the prompt at the head of the queue and starts its timeout
+func (b *SubscriptionBroker) show(token string) {
+	b.mu.Lock()
+	p, exists := b.prompts[token]
+	if !exists {
+		b.mu.Unlock()
+		return
+	}
+	p.timer = time.AfterFunc(b.timeout, func() {
+		log.Warnf("[SubscriptionBroker] Prompt %s timed out, cancelling", token)
+		b.Resolve(token, PromptReply{Cancel: true})
+	})
+	prompt := credentialPrompt(token, p)
+	b.mu.Unlock()
+
+	b.broadcast(prompt)
+}
+
+func credentialPrompt(token string, p *brokerPrompt) CredentialPrompt {
+	return CredentialPrompt{
+		Token:          token,
+		Name:           p.req.Name,
+		SSID:           p.req.SSID,
+		ConnType:       p.req.ConnType,
+		VpnService:     p.req.VpnService,
+		Setting:        p.req.SettingName,
+		Fields:         p.req.Fields,
+		Hints:          p.req.Hints,
+		Reason:         p.req.Reason,
+		ConnectionId:   p.req.ConnectionId,
+		ConnectionUuid: p.req.ConnectionUuid,
+		ClaimedBy:      p.owner,
+	}
+}
+
+func (b *SubscriptionBroker) broadcast(prompt CredentialPrompt) {
+	if b.broadcastPrompt != nil {
+		b.broadcastPrompt(prompt)
+	}
+}
+
 func (b *SubscriptionBroker) Wait(ctx context.Context, token string) (PromptReply, error) {
-	b.mu.RLock()
-	replyChan, exists := b.pending[token]
-	b.mu.RUnlock()
+	b.mu.Lock()
+	p, exists := b.prompts[token]
+	b.mu.Unlock()
 
 	if !exists {
 		return PromptReply{}, fmt.Errorf("unknown token: %s", token)
@@ -83,7 +130,7 @@ func (b *SubscriptionBroker) Wait(ctx context.Context, token string) (PromptRepl
 	case <-ctx.Done():
 		b.cleanup(token)
 		return PromptReply{}, errdefs.ErrSecretPromptTimeout
-	case reply := <-replyChan:
+	case reply := <-p.reply:
 		b.cleanup(token)
 		if reply.Cancel {
 			return reply, errdefs.ErrSecretPromptCancelled
@@ -92,10 +139,41 @@ func (b *SubscriptionBroker) Wait(ctx context.Context, token string) (PromptRepl
 	}
 }
 
+// Claim gives client the prompt. Claiming again is a no-op; a prompt
+// claimed by someone else can't be taken over. The other clients see the
+// prompt again with claimedBy set and can close their dialogs
+func (b *SubscriptionBroker) Claim(token, client string) error {
+	b.mu.Lock()
+	p, exists := b.prompts[token]
+	if !exists {
+		b.mu.Unlock()
+		return fmt.Errorf("unknown or expired token: %s", token)
+	}
+	switch p.owner {
+	case client:
+		b.mu.Unlock()
+		return nil
+	case "":
+	default:
+		b.mu.Unlock()
+		return fmt.Errorf("prompt is being answered by another client")
+	}
+	p.owner = client
+	active := len(b.queue) > 0 && b.queue[0] == token
+	prompt := credentialPrompt(token, p)
+	b.mu.Unlock()
+
+	log.Infof("[SubscriptionBroker] Prompt %s claimed by %s", token, client)
+	if active {
+		b.broadcast(prompt)
+	}
+	return nil
+}
+
 func (b *SubscriptionBroker) Resolve(token string, reply PromptReply) error {
-	b.mu.RLock()
-	replyChan, exists := b.pending[token]
-	b.mu.RUnlock()
+	b.mu.Lock()
+	p, exists := b.prompts[token]
+	b.mu.Unlock()
 
 	if !exists {
 		log.Warnf("[SubscriptionBroker] Resolve: unknown or expired token: %s", token)
@@ -103,7 +181,7 @@ func (b *SubscriptionBroker) Resolve(token string, reply PromptReply) error {
 	}
 
 	select {
-	case replyChan <- reply:
+	case p.reply <- reply:
 		return nil
 	default:
 		log.Warnf("[SubscriptionBroker] Resolve: failed to deliver reply for token %s (channel full or closed)", token)
@@ -111,17 +189,44 @@ func (b *SubscriptionBroker) Resolve(token string, reply PromptReply) error {
 	}
 }
 
+// cleanup forgets a finished prompt. When it was the one shown, the clients
+// are told it closed and the next queued prompt is shown
 func (b *SubscriptionBroker) cleanup(token string) {
 	b.mu.Lock()
-	defer b.mu.Unlock()
-
-	if req, exists := b.requests[token]; exists {
-		pathSettingKey := fmt.Sprintf("%s:%s", req.ConnectionPath, req.SettingName)
+	p, exists := b.prompts[token]
+	if !exists {
+		b.mu.Unlock()
+		return
+	}
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	pathSettingKey := fmt.Sprintf("%s:%s", p.req.ConnectionPath, p.req.SettingName)
+	if b.pathSettingToToken[pathSettingKey] == token {
 		delete(b.pathSettingToToken, pathSettingKey)
 	}
+	delete(b.prompts, token)
+
+	wasActive := len(b.queue) > 0 && b.queue[0] == token
+	for i, queued := range b.queue {
+		if queued == token {
+			b.queue = append(b.queue[:i], b.queue[i+1:]...)
+			break
+		}
+	}
+	var next string
+	if wasActive && len(b.queue) > 0 {
+		next = b.queue[0]
+	}
+	b.mu.Unlock()
 
-	delete(b.pending, token)
-	delete(b.requests, token)
+	if !wasActive {
+		return
+	}
+	b.broadcast(CredentialPrompt{Token: token, Closed: true})
+	if next != "" {
+		b.show(next)
+	}
 }
 
 func (b *SubscriptionBroker) Cancel(path string, setting string) error {
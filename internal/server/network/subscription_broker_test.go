@@ -0,0 +1,119 @@
+package network
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/errdefs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type promptRecorder struct {
+	mu      sync.Mutex
+	prompts []CredentialPrompt
+}
+
+func (r *promptRecorder) record(p CredentialPrompt) {
+	r.mu.Lock()
+	r.prompts = append(r.prompts, p)
+	r.mu.Unlock()
+}
+
+func (r *promptRecorder) all() []CredentialPrompt {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]CredentialPrompt(nil), r.prompts...)
+}
+
+func TestSubscriptionBroker_QueuesOverlappingPrompts(t *testing.T) {
+	rec := &promptRecorder{}
+	broker := NewSubscriptionBroker(rec.record)
+	ctx := context.Background()
+
+	wifi, err := broker.Ask(ctx, PromptRequest{SSID: "Home", ConnectionPath: "/wifi", SettingName: "802-11-wireless-security"})
+	require.NoError(t, err)
+	vpn, err := broker.Ask(ctx, PromptRequest{Name: "Work", ConnectionPath: "/vpn", SettingName: "vpn"})
+	require.NoError(t, err)
+
+	prompts := rec.all()
+	require.Len(t, prompts, 1, "the VPN prompt waits for the WiFi one")
+	assert.Equal(t, wifi, prompts[0].Token)
+
+	require.NoError(t, broker.Resolve(wifi, PromptReply{Secrets: map[string]string{"psk": "secret"}}))
+	reply, err := broker.Wait(ctx, wifi)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", reply.Secrets["psk"])
+
+	prompts = rec.all()
+	require.Len(t, prompts, 3)
+	assert.Equal(t, CredentialPrompt{Token: wifi, Closed: true}, prompts[1])
+	assert.Equal(t, vpn, prompts[2].Token)
+	assert.Equal(t, "Work", prompts[2].Name)
+}
+
+func TestSubscriptionBroker_QueuedPromptCancelled(t *testing.T) {
+	rec := &promptRecorder{}
+	broker := NewSubscriptionBroker(rec.record)
+	ctx := context.Background()
+
+	wifi, err := broker.Ask(ctx, PromptRequest{ConnectionPath: "/wifi", SettingName: "802-11-wireless-security"})
+	require.NoError(t, err)
+	vpn, err := broker.Ask(ctx, PromptRequest{ConnectionPath: "/vpn", SettingName: "vpn"})
+	require.NoError(t, err)
+
+	require.NoError(t, broker.Cancel("/vpn", "vpn"))
+	_, err = broker.Wait(ctx, vpn)
+	assert.ErrorIs(t, err, errdefs.ErrSecretPromptCancelled)
+	assert.Len(t, rec.all(), 1, "a prompt never shown isn't closed")
+
+	require.NoError(t, broker.Resolve(wifi, PromptReply{Cancel: true}))
+	_, err = broker.Wait(ctx, wifi)
+	assert.ErrorIs(t, err, errdefs.ErrSecretPromptCancelled)
+	assert.Len(t, rec.all(), 2)
+}
+
+func TestSubscriptionBroker_Claim(t *testing.T) {
+	rec := &promptRecorder{}
+	broker := NewSubscriptionBroker(rec.record)
+
+	token, err := broker.Ask(context.Background(), PromptRequest{ConnectionPath: "/wifi", SettingName: "802-11-wireless-security"})
+	require.NoError(t, err)
+
+	require.NoError(t, broker.Claim(token, "bar"))
+	require.NoError(t, broker.Claim(token, "bar"))
+	assert.ErrorContains(t, broker.Claim(token, "launcher"), "another client")
+	assert.Error(t, broker.Claim("missing", "bar"))
+
+	prompts := rec.all()
+	require.Len(t, prompts, 2, "claiming rebroadcasts once")
+	assert.Equal(t, "bar", prompts[1].ClaimedBy)
+}
+
+func TestSubscriptionBroker_PromptTimeout(t *testing.T) {
+	rec := &promptRecorder{}
+	broker := NewSubscriptionBroker(rec.record).(*SubscriptionBroker)
+	broker.timeout = 20 * time.Millisecond
+
+	token, err := broker.Ask(context.Background(), PromptRequest{ConnectionPath: "/wifi", SettingName: "802-11-wireless-security"})
+	require.NoError(t, err)
+
+	_, err = broker.Wait(context.Background(), token)
+	assert.ErrorIs(t, err, errdefs.ErrSecretPromptCancelled)
+	prompts := rec.all()
+	require.Len(t, prompts, 2)
+	assert.True(t, prompts[1].Closed)
+}
+
+func TestSubscriptionBroker_DuplicateAsk(t *testing.T) {
+	broker := NewSubscriptionBroker(nil)
+	req := PromptRequest{ConnectionPath: "/wifi", SettingName: "802-11-wireless-security"}
+
+	first, err := broker.Ask(context.Background(), req)
+	require.NoError(t, err)
+	second, err := broker.Ask(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+}
@@ -2,6 +2,7 @@ package network
 
 import (
 	"sync"
+	"time"
 
 	"github.com/godbus/dbus/v5"
 )
@@ -28,15 +29,36 @@ type WiFiNetwork struct {
 	BSSID      string `json:"bssid"`
 	Signal     uint8  `json:"signal"`
 	Secured    bool   `json:"secured"`
+	Security   string `json:"security,omitempty"`
 	Enterprise bool   `json:"enterprise"`
 	Connected  bool   `json:"connected"`
 	Saved      bool   `json:"saved"`
+	Metered    bool   `json:"metered"`
 	Frequency  uint32 `json:"frequency"`
 	Mode       string `json:"mode"`
 	Rate       uint32 `json:"rate"`
 	Channel    uint32 `json:"channel"`
 }
 
+// KnownNetwork is a network saved by iwd. Rank is its place in iwd's
+// autoconnect order, 1 being tried first
+type KnownNetwork struct {
+	SSID          string `json:"ssid"`
+	Security      string `json:"security"`
+	Hidden        bool   `json:"hidden"`
+	AutoConnect   bool   `json:"autoConnect"`
+	LastConnected string `json:"lastConnected,omitempty"`
+	Rank          int    `json:"rank"`
+}
+
+type HotspotState struct {
+	Active  bool   `json:"active"`
+	SSID    string `json:"ssid,omitempty"`
+	Band    string `json:"band,omitempty"`
+	Device  string `json:"device,omitempty"`
+	Secured bool   `json:"secured"`
+}
+
 type VPNProfile struct {
 	Name        string `json:"name"`
 	UUID        string `json:"uuid"`
@@ -66,8 +88,10 @@ type NetworkState struct {
 	EthernetDevice         string               `json:"ethernetDevice"`
 	EthernetConnected      bool                 `json:"ethernetConnected"`
 	EthernetConnectionUuid string               `json:"ethernetConnectionUuid"`
+	EthernetDevices        []EthernetDeviceInfo `json:"ethernetDevices"`
 	WiFiIP                 string               `json:"wifiIP"`
 	WiFiDevice             string               `json:"wifiDevice"`
+	WiFiDevices            []WiFiDeviceInfo     `json:"wifiDevices"`
 	WiFiConnected          bool                 `json:"wifiConnected"`
 	WiFiEnabled            bool                 `json:"wifiEnabled"`
 	WiFiSSID               string               `json:"wifiSSID"`
@@ -75,11 +99,23 @@ type NetworkState struct {
 	WiFiSignal             uint8                `json:"wifiSignal"`
 	WiFiNetworks           []WiFiNetwork        `json:"wifiNetworks"`
 	WiredConnections       []WiredConnection    `json:"wiredConnections"`
+	Metered                bool                 `json:"metered"`
+	Bandwidth              Bandwidth            `json:"bandwidth"`
+	Connectivity           Connectivity         `json:"connectivity"`
 	VPNProfiles            []VPNProfile         `json:"vpnProfiles"`
 	VPNActive              []VPNActive          `json:"vpnActive"`
+	Hotspot                HotspotState         `json:"hotspot"`
+	HotspotClients         []HotspotClient      `json:"hotspotClients"`
 	IsConnecting           bool                 `json:"isConnecting"`
 	ConnectingSSID         string               `json:"connectingSSID"`
 	LastError              string               `json:"lastError"`
+	// BackendRestarting is set while the network daemon is gone, until it
+	// or another one is back and the backend is reinitialized
+	BackendRestarting bool `json:"backendRestarting"`
+	// Unstable is set once the link dropped several times in a few minutes;
+	// FlapCount is how many drops are in that window
+	Unstable  bool `json:"unstable"`
+	FlapCount int  `json:"flapCount"`
 }
 
 type ConnectionRequest struct {
@@ -88,7 +124,25 @@ type ConnectionRequest struct {
 	Username          string `json:"username,omitempty"`
 	AnonymousIdentity string `json:"anonymousIdentity,omitempty"`
 	DomainSuffixMatch string `json:"domainSuffixMatch,omitempty"`
-	Interactive       bool   `json:"interactive,omitempty"`
+	// EAPMethod is peap (the default), ttls, tls or pwd. Certificate and key
+	// fields are absolute paths
+	EAPMethod          string `json:"eapMethod,omitempty"`
+	Phase2Auth         string `json:"phase2Auth,omitempty"`
+	CACert             string `json:"caCert,omitempty"`
+	ClientCert         string `json:"clientCert,omitempty"`
+	PrivateKey         string `json:"privateKey,omitempty"`
+	PrivateKeyPassword string `json:"privateKeyPassword,omitempty"`
+	Interactive        bool   `json:"interactive,omitempty"`
+	// Hidden joins a network that doesn't broadcast its SSID
+	Hidden bool `json:"hidden,omitempty"`
+	// BSSID and Band (2.4, 5, 6 or any) pin the connection to one access
+	// point or band, as listed in NetworkInfoResponse.Bands. Band any clears
+	// a pin saved on the profile
+	BSSID string `json:"bssid,omitempty"`
+	Band  string `json:"band,omitempty"`
+	// Timeout in seconds gives up on an attempt that hasn't connected or
+	// failed by then; 0 uses the backend's default
+	Timeout int `json:"timeout,omitempty"`
 }
 
 type WiredConnection struct {
@@ -97,6 +151,38 @@ type WiredConnection struct {
 	UUID     string          `json:"uuid"`
 	Type     string          `json:"type"`
 	IsActive bool            `json:"isActive"`
+	Metered  bool            `json:"metered"`
+	// Shared is set when the profile shares this machine's connection with
+	// the device on the other end
+	Shared bool   `json:"shared"`
+	Device string `json:"device,omitempty"`
+}
+
+// EthernetDeviceInfo is a wired interface, such as a dock or USB adapter.
+// Connection is the UUID of the profile active on it
+type EthernetDeviceInfo struct {
+	Name       string `json:"name"`
+	Address    string `json:"address,omitempty"`
+	Connected  bool   `json:"connected"`
+	IP         string `json:"ip,omitempty"`
+	Speed      uint32 `json:"speed,omitempty"`
+	Connection string `json:"connection,omitempty"`
+}
+
+// WiFiDeviceInfo is a wireless adapter. Scans, connections and the hotspot
+// use the selected one
+type WiFiDeviceInfo struct {
+	Name      string `json:"name"`
+	Address   string `json:"address,omitempty"`
+	Connected bool   `json:"connected"`
+	Selected  bool   `json:"selected"`
+}
+
+// MeteredInfo is a profile's metered mode and whether it currently counts as
+// metered, including NetworkManager's guess in auto mode
+type MeteredInfo struct {
+	Mode    string `json:"mode"`
+	Metered bool   `json:"metered"`
 }
 
 type PriorityUpdate struct {
@@ -105,6 +191,7 @@ type PriorityUpdate struct {
 
 type Manager struct {
 	backend               Backend
+	backendMutex          sync.RWMutex
 	state                 *NetworkState
 	stateMutex            sync.RWMutex
 	subscribers           map[string]chan NetworkState
@@ -115,6 +202,20 @@ type Manager struct {
 	lastNotifiedState     *NetworkState
 	credentialSubscribers map[string]chan CredentialPrompt
 	credSubMutex          sync.RWMutex
+	vpnPolicy             VPNPolicy
+	vpnPolicyPath         string
+	vpnPolicyMutex        sync.Mutex
+	lastPolicyNetwork     string
+	signalHistory         *signalHistory
+	lastScan              time.Time
+	scanMutex             sync.Mutex
+	connectivityConfig    ConnectivityConfig
+	connectivityPath      string
+	connectivityMutex     sync.Mutex
+	connectivityWake      chan struct{}
+	connectivityProbe     connectivityProbe
+	flaps                 flapTracker
+	flapTimer             *time.Timer
 }
 
 type EventType string
@@ -165,11 +266,15 @@ type CredentialPrompt struct {
 	Reason         string   `json:"reason"`
 	ConnectionId   string   `json:"connectionId"`
 	ConnectionUuid string   `json:"connectionUuid"`
+	ClaimedBy      string   `json:"claimedBy,omitempty"`
+	Closed         bool     `json:"closed,omitempty"`
 }
 
 type NetworkInfoResponse struct {
 	SSID  string        `json:"ssid"`
 	Bands []WiFiNetwork `json:"bands"`
+	// DNS is the saved profile's DNS override, nil for unsaved networks
+	DNS *DNSConfig `json:"dnsConfig,omitempty"`
 }
 
 type WiredNetworkInfoResponse struct {
@@ -180,9 +285,19 @@ type WiredNetworkInfoResponse struct {
 	Speed  string        `json:"speed"`
 	IPv4   WiredIPConfig `json:"IPv4s"`
 	IPv6   WiredIPConfig `json:"IPv6s"`
+	DNS    DNSConfig     `json:"dnsConfig"`
+}
+
+// DNSConfig is the per-connection DNS override. Servers may mix IPv4 and
+// IPv6 addresses; IgnoreAuto drops the servers DHCP/RA provide
+type DNSConfig struct {
+	Servers    []string `json:"servers"`
+	IgnoreAuto bool     `json:"ignoreAuto"`
+	DNSOverTLS string   `json:"dnsOverTls"`
 }
 
 type WiredIPConfig struct {
+	Method  string   `json:"method,omitempty"`
 	IPs     []string `json:"ips"`
 	Gateway string   `json:"gateway"`
 	DNS     string   `json:"dns"`
@@ -0,0 +1,246 @@
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/secrets"
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+// VPNPolicy connects a VPN profile automatically whenever the primary
+// connection is a network that isn't trusted
+type VPNPolicy struct {
+	Enabled      bool     `json:"enabled"`
+	Profile      string   `json:"profile"`
+	TrustedSSIDs []string `json:"trustedSSIDs"`
+	// TrustEthernet treats every wired connection as trusted
+	TrustEthernet bool `json:"trustEthernet"`
+	// DisconnectOnTrusted drops the VPN again when moving to a trusted network
+	DisconnectOnTrusted bool `json:"disconnectOnTrusted"`
+}
+
+type vpnAction int
+
+const (
+	vpnActionNone vpnAction = iota
+	vpnActionConnect
+	vpnActionDisconnect
+)
+
+func defaultVPNPolicyPath() string {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		configDir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(configDir, "dms", "vpn-policy.json")
+}
+
+// primaryNetwork identifies the network traffic leaves through: wired wins
+// unless WiFi is preferred, matching the route metrics set by the preference
+func primaryNetwork(state *NetworkState) string {
+	switch {
+	case state.EthernetConnected && (state.Preference != PreferenceWiFi || !state.WiFiConnected):
+		return "ethernet"
+	case state.WiFiConnected && state.WiFiSSID != "":
+		return "wifi:" + state.WiFiSSID
+	}
+	return ""
+}
+
+func (p VPNPolicy) isTrusted(network string) bool {
+	if network == "ethernet" {
+		return p.TrustEthernet
+	}
+	for _, ssid := range p.TrustedSSIDs {
+		if "wifi:"+ssid == network {
+			return true
+		}
+	}
+	return false
+}
+
+func (p VPNPolicy) decide(network string) vpnAction {
+	if !p.Enabled || p.Profile == "" || network == "" {
+		return vpnActionNone
+	}
+	if !p.isTrusted(network) {
+		return vpnActionConnect
+	}
+	if p.DisconnectOnTrusted {
+		return vpnActionDisconnect
+	}
+	return vpnActionNone
+}
+
+func (m *Manager) loadVPNPolicy() {
+	data, err := os.ReadFile(m.vpnPolicyPath)
+	if err != nil {
+		return
+	}
+
+	var policy VPNPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		log.Warnf("network: ignoring invalid VPN policy %s: %v", m.vpnPolicyPath, err)
+		return
+	}
+	m.vpnPolicyMutex.Lock()
+	m.vpnPolicy = policy
+	m.vpnPolicyMutex.Unlock()
+}
+
+func (m *Manager) saveVPNPolicy(policy VPNPolicy) error {
+	data, err := json.MarshalIndent(policy, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.vpnPolicyPath), 0755); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(m.vpnPolicyPath, data, 0644)
+}
+
+func (m *Manager) GetVPNPolicy() VPNPolicy {
+	m.vpnPolicyMutex.Lock()
+	defer m.vpnPolicyMutex.Unlock()
+	policy := m.vpnPolicy
+	policy.TrustedSSIDs = append([]string(nil), m.vpnPolicy.TrustedSSIDs...)
+	return policy
+}
+
+// SetVPNPolicy saves the policy and applies it to the current network right
+// away
+func (m *Manager) SetVPNPolicy(policy VPNPolicy) error {
+	if policy.Enabled {
+		if policy.Profile == "" {
+			return fmt.Errorf("a VPN profile is required to enable auto-connect")
+		}
+		if _, ok := m.findVPNProfile(policy.Profile); !ok {
+			return fmt.Errorf("VPN profile not found: %s", policy.Profile)
+		}
+	}
+
+	if m.vpnPolicyPath != "" {
+		if err := m.saveVPNPolicy(policy); err != nil {
+			return fmt.Errorf("failed to save VPN policy: %w", err)
+		}
+	}
+
+	m.vpnPolicyMutex.Lock()
+	m.vpnPolicy = policy
+	m.lastPolicyNetwork = ""
+	m.vpnPolicyMutex.Unlock()
+
+	m.evaluateVPNPolicy()
+	return nil
+}
+
+func (m *Manager) findVPNProfile(uuidOrName string) (VPNProfile, bool) {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	for _, profile := range m.state.VPNProfiles {
+		if profile.UUID == uuidOrName || profile.Name == uuidOrName {
+			return profile, true
+		}
+	}
+	return VPNProfile{}, false
+}
+
+func (m *Manager) vpnActive(profile VPNProfile) bool {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	for _, active := range m.state.VPNActive {
+		if active.UUID == profile.UUID {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateVPNPolicy acts only when the primary network changes, so a VPN
+// the user disconnects by hand stays down until they switch networks
+func (m *Manager) evaluateVPNPolicy() {
+	m.stateMutex.RLock()
+	network := primaryNetwork(m.state)
+	m.stateMutex.RUnlock()
+
+	m.vpnPolicyMutex.Lock()
+	if network == m.lastPolicyNetwork {
+		m.vpnPolicyMutex.Unlock()
+		return
+	}
+	m.lastPolicyNetwork = network
+	policy := m.vpnPolicy
+	m.vpnPolicyMutex.Unlock()
+
+	action := policy.decide(network)
+	if action == vpnActionNone {
+		return
+	}
+
+	profile, ok := m.findVPNProfile(policy.Profile)
+	if !ok {
+		log.Warnf("network: VPN policy profile %s no longer exists", policy.Profile)
+		return
+	}
+
+	active := m.vpnActive(profile)
+	switch {
+	case action == vpnActionConnect && !active:
+		log.Infof("network: connecting VPN %s on untrusted network %s", profile.Name, network)
+		go func() {
+			if err := m.currentBackend().ConnectVPN(profile.UUID, false); err != nil {
+				log.Warnf("network: VPN policy failed to connect %s: %v", profile.Name, err)
+			}
+		}()
+	case action == vpnActionDisconnect && active:
+		log.Infof("network: disconnecting VPN %s on trusted network %s", profile.Name, network)
+		go func() {
+			if err := m.currentBackend().DisconnectVPN(profile.UUID); err != nil {
+				log.Warnf("network: VPN policy failed to disconnect %s: %v", profile.Name, err)
+			}
+		}()
+	}
+}
+
+// secretGetter is the part of secrets.Store the agent reads from
+type secretGetter interface {
+	Get(name string) (string, error)
+}
+
+// vpnSecretName is the dms secret a VPN field is kept under, so a VPN the
+// policy connects unattended needn't prompt, e.g.
+// `dms secret set vpn.<uuid>.password`
+func vpnSecretName(uuid, field string) string {
+	return "vpn." + uuid + "." + field
+}
+
+// storedVPNSecrets answers a VPN secrets request from the encrypted dms
+// store, but only with every requested field present
+func storedVPNSecrets(store secretGetter, uuid string, fields []string) (map[string]string, bool) {
+	if store == nil || uuid == "" {
+		return nil, false
+	}
+
+	values := make(map[string]string, len(fields))
+	for _, field := range fields {
+		// Messages the plugin wants shown aren't secrets
+		if strings.HasPrefix(field, "x-vpn-message:") {
+			continue
+		}
+		value, err := store.Get(vpnSecretName(uuid, field))
+		if err != nil {
+			if !errors.Is(err, secrets.ErrNotFound) {
+				log.Warnf("network: failed to read stored secret %s: %v", vpnSecretName(uuid, field), err)
+			}
+			return nil, false
+		}
+		values[field] = value
+	}
+	return values, len(values) > 0
+}
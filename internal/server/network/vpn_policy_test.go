@@ -0,0 +1,154 @@
+package network
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type policyBackend struct {
+	Backend
+	connected    chan string
+	disconnected chan string
+}
+
+func (b *policyBackend) ConnectVPN(uuidOrName string, singleActive bool) error {
+	b.connected <- uuidOrName
+	return nil
+}
+
+func (b *policyBackend) DisconnectVPN(uuidOrName string) error {
+	b.disconnected <- uuidOrName
+	return nil
+}
+
+func newPolicyManager(t *testing.T) (*Manager, *policyBackend) {
+	backend := &policyBackend{connected: make(chan string, 4), disconnected: make(chan string, 4)}
+	m := &Manager{
+		backend: backend,
+		state: &NetworkState{
+			Preference:  PreferenceAuto,
+			VPNProfiles: []VPNProfile{{Name: "Work", UUID: "vpn-uuid"}},
+		},
+		vpnPolicyPath: filepath.Join(t.TempDir(), "vpn-policy.json"),
+	}
+	return m, backend
+}
+
+func joinWiFi(m *Manager, ssid string) {
+	m.stateMutex.Lock()
+	m.state.WiFiConnected = ssid != ""
+	m.state.WiFiSSID = ssid
+	m.stateMutex.Unlock()
+	m.evaluateVPNPolicy()
+}
+
+func expectCall(t *testing.T, ch chan string, want string) {
+	t.Helper()
+	select {
+	case got := <-ch:
+		assert.Equal(t, want, got)
+	case <-time.After(time.Second):
+		t.Fatalf("expected a call for %s", want)
+	}
+}
+
+func expectNoCall(t *testing.T, ch chan string) {
+	t.Helper()
+	select {
+	case got := <-ch:
+		t.Fatalf("unexpected call for %s", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPrimaryNetwork(t *testing.T) {
+	assert.Equal(t, "", primaryNetwork(&NetworkState{}))
+	assert.Equal(t, "wifi:Cafe", primaryNetwork(&NetworkState{WiFiConnected: true, WiFiSSID: "Cafe"}))
+	assert.Equal(t, "ethernet", primaryNetwork(&NetworkState{EthernetConnected: true, WiFiConnected: true, WiFiSSID: "Cafe"}))
+	assert.Equal(t, "wifi:Cafe", primaryNetwork(&NetworkState{EthernetConnected: true, WiFiConnected: true, WiFiSSID: "Cafe", Preference: PreferenceWiFi}))
+}
+
+func TestVPNPolicyDecide(t *testing.T) {
+	policy := VPNPolicy{Enabled: true, Profile: "Work", TrustedSSIDs: []string{"Home"}, TrustEthernet: true}
+
+	assert.Equal(t, vpnActionConnect, policy.decide("wifi:Cafe"))
+	assert.Equal(t, vpnActionNone, policy.decide("wifi:Home"))
+	assert.Equal(t, vpnActionNone, policy.decide("ethernet"))
+	assert.Equal(t, vpnActionNone, policy.decide(""))
+
+	policy.DisconnectOnTrusted = true
+	assert.Equal(t, vpnActionDisconnect, policy.decide("wifi:Home"))
+
+	policy.Enabled = false
+	assert.Equal(t, vpnActionNone, policy.decide("wifi:Cafe"))
+}
+
+func TestVPNPolicyConnectsOnUntrustedNetwork(t *testing.T) {
+	m, backend := newPolicyManager(t)
+	require.NoError(t, m.SetVPNPolicy(VPNPolicy{Enabled: true, Profile: "Work", TrustedSSIDs: []string{"Home"}, DisconnectOnTrusted: true}))
+
+	joinWiFi(m, "Home")
+	expectNoCall(t, backend.connected)
+
+	joinWiFi(m, "Cafe")
+	expectCall(t, backend.connected, "vpn-uuid")
+
+	// Re-evaluating the same network must not reconnect a VPN the user dropped
+	m.evaluateVPNPolicy()
+	expectNoCall(t, backend.connected)
+
+	m.stateMutex.Lock()
+	m.state.VPNActive = []VPNActive{{Name: "Work", UUID: "vpn-uuid"}}
+	m.stateMutex.Unlock()
+	joinWiFi(m, "Home")
+	expectCall(t, backend.disconnected, "vpn-uuid")
+}
+
+func TestVPNPolicyPersists(t *testing.T) {
+	m, _ := newPolicyManager(t)
+	policy := VPNPolicy{Enabled: true, Profile: "vpn-uuid", TrustedSSIDs: []string{"Home", "Office"}, TrustEthernet: true}
+	require.NoError(t, m.SetVPNPolicy(policy))
+
+	reloaded := &Manager{vpnPolicyPath: m.vpnPolicyPath}
+	reloaded.loadVPNPolicy()
+	assert.Equal(t, policy, reloaded.GetVPNPolicy())
+}
+
+func TestSetVPNPolicyRequiresKnownProfile(t *testing.T) {
+	m, _ := newPolicyManager(t)
+	assert.Error(t, m.SetVPNPolicy(VPNPolicy{Enabled: true}))
+	assert.Error(t, m.SetVPNPolicy(VPNPolicy{Enabled: true, Profile: "Missing"}))
+	assert.NoError(t, m.SetVPNPolicy(VPNPolicy{Profile: "Missing"}))
+}
+
+type mapSecrets map[string]string
+
+func (m mapSecrets) Get(name string) (string, error) {
+	value, ok := m[name]
+	if !ok {
+		return "", secrets.ErrNotFound
+	}
+	return value, nil
+}
+
+func TestStoredVPNSecrets(t *testing.T) {
+	store := mapSecrets{vpnSecretName("abc-123", "password"): "hunter2"}
+
+	values, ok := storedVPNSecrets(store, "abc-123", []string{"password", "x-vpn-message:Enter your token"})
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"password": "hunter2"}, values)
+
+	_, ok = storedVPNSecrets(store, "abc-123", []string{"password", "otp"})
+	assert.False(t, ok, "every field has to be stored")
+
+	_, ok = storedVPNSecrets(store, "other", []string{"password"})
+	assert.False(t, ok)
+
+	_, ok = storedVPNSecrets(nil, "abc-123", []string{"password"})
+	assert.False(t, ok)
+}
@@ -0,0 +1,127 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Bands a WiFi connection can be pinned to. BandAny removes a pin
+const (
+	Band24  = "2.4"
+	Band5   = "5"
+	Band6   = "6"
+	BandAny = "any"
+)
+
+func frequencyBand(freq uint32) string {
+	switch {
+	case freq >= 2400 && freq < 2500:
+		return Band24
+	case freq >= 4900 && freq < 5925:
+		return Band5
+	case freq >= 5925 && freq <= 7125:
+		return Band6
+	}
+	return ""
+}
+
+func wantsAPPin(req ConnectionRequest) bool {
+	return req.BSSID != "" || req.Band != ""
+}
+
+func validateAPPin(req ConnectionRequest) error {
+	switch req.Band {
+	case "", Band24, Band5, Band6, BandAny:
+	default:
+		return fmt.Errorf("invalid band %q (use 2.4, 5, 6 or any)", req.Band)
+	}
+	if req.BSSID != "" {
+		if req.Band == BandAny {
+			return fmt.Errorf("bssid can't be combined with band any")
+		}
+		if _, err := normalizeBSSID(req.BSSID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func normalizeBSSID(bssid string) (string, error) {
+	hw, err := net.ParseMAC(bssid)
+	if err != nil || len(hw) != 6 {
+		return "", fmt.Errorf("invalid bssid %q", bssid)
+	}
+	return strings.ToUpper(hw.String()), nil
+}
+
+type apCandidate struct {
+	bssid    string
+	freq     uint32
+	strength uint8
+}
+
+// pickAccessPoint returns the index of the access point with bssid, or else
+// of the strongest one on band
+func pickAccessPoint(aps []apCandidate, bssid, band string) (int, error) {
+	if bssid != "" {
+		want, err := normalizeBSSID(bssid)
+		if err != nil {
+			return -1, err
+		}
+		for i, ap := range aps {
+			if strings.EqualFold(ap.bssid, want) {
+				if band != "" && frequencyBand(ap.freq) != band {
+					return -1, fmt.Errorf("access point %s is on the %s GHz band, not %s GHz", want, frequencyBand(ap.freq), band)
+				}
+				return i, nil
+			}
+		}
+		return -1, fmt.Errorf("access point %s not found", want)
+	}
+
+	best := -1
+	for i, ap := range aps {
+		if frequencyBand(ap.freq) != band {
+			continue
+		}
+		if best < 0 || ap.strength > aps[best].strength {
+			best = i
+		}
+	}
+	if best < 0 {
+		return -1, fmt.Errorf("no access point on the %s GHz band", band)
+	}
+	return best, nil
+}
+
+// applyAPPin sets the profile's 802-11-wireless band or bssid for req,
+// replacing any earlier pin. NetworkManager has no 6 GHz band value, so 6 GHz
+// pins the chosen access point
+func applyAPPin(wireless map[string]interface{}, req ConnectionRequest, apBSSID string) error {
+	delete(wireless, "band")
+	delete(wireless, "bssid")
+
+	pinBSSID := func() error {
+		hw, err := net.ParseMAC(apBSSID)
+		if err != nil {
+			return fmt.Errorf("invalid bssid %q", apBSSID)
+		}
+		wireless["bssid"] = []byte(hw)
+		return nil
+	}
+
+	switch {
+	case req.Band == BandAny:
+		return nil
+	case req.BSSID != "":
+		return pinBSSID()
+	case req.Band == Band24:
+		wireless["band"] = "bg"
+	case req.Band == Band5:
+		wireless["band"] = "a"
+	case req.Band == Band6:
+		return pinBSSID()
+	}
+	return nil
+}
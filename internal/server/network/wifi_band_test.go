@@ -0,0 +1,69 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrequencyBand(t *testing.T) {
+	assert.Equal(t, Band24, frequencyBand(2437))
+	assert.Equal(t, Band5, frequencyBand(5180))
+	assert.Equal(t, Band6, frequencyBand(5955))
+	assert.Equal(t, "", frequencyBand(60480))
+}
+
+func TestValidateAPPin(t *testing.T) {
+	assert.NoError(t, validateAPPin(ConnectionRequest{SSID: "home"}))
+	assert.NoError(t, validateAPPin(ConnectionRequest{SSID: "home", Band: Band5}))
+	assert.NoError(t, validateAPPin(ConnectionRequest{SSID: "home", BSSID: "aa:bb:cc:dd:ee:ff"}))
+	assert.Error(t, validateAPPin(ConnectionRequest{SSID: "home", Band: "60"}))
+	assert.Error(t, validateAPPin(ConnectionRequest{SSID: "home", BSSID: "nope"}))
+	assert.Error(t, validateAPPin(ConnectionRequest{SSID: "home", BSSID: "aa:bb:cc:dd:ee:ff", Band: BandAny}))
+}
+
+func TestPickAccessPoint(t *testing.T) {
+	aps := []apCandidate{
+		{bssid: "AA:AA:AA:AA:AA:01", freq: 2412, strength: 90},
+		{bssid: "AA:AA:AA:AA:AA:02", freq: 5180, strength: 40},
+		{bssid: "AA:AA:AA:AA:AA:03", freq: 5745, strength: 70},
+	}
+
+	i, err := pickAccessPoint(aps, "", Band5)
+	require.NoError(t, err)
+	assert.Equal(t, 2, i)
+
+	i, err = pickAccessPoint(aps, "aa:aa:aa:aa:aa:02", "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, i)
+
+	_, err = pickAccessPoint(aps, "aa:aa:aa:aa:aa:01", Band5)
+	assert.Error(t, err)
+
+	_, err = pickAccessPoint(aps, "", Band6)
+	assert.Error(t, err)
+
+	_, err = pickAccessPoint(aps, "aa:aa:aa:aa:aa:09", "")
+	assert.Error(t, err)
+}
+
+func TestApplyAPPin(t *testing.T) {
+	wireless := map[string]interface{}{"ssid": []byte("home")}
+
+	require.NoError(t, applyAPPin(wireless, ConnectionRequest{Band: Band5}, "AA:AA:AA:AA:AA:03"))
+	assert.Equal(t, "a", wireless["band"])
+	assert.NotContains(t, wireless, "bssid")
+
+	require.NoError(t, applyAPPin(wireless, ConnectionRequest{Band: Band6}, "AA:AA:AA:AA:AA:04"))
+	assert.NotContains(t, wireless, "band")
+	assert.Equal(t, []byte{0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0x04}, wireless["bssid"])
+
+	require.NoError(t, applyAPPin(wireless, ConnectionRequest{BSSID: "aa:aa:aa:aa:aa:01"}, "AA:AA:AA:AA:AA:01"))
+	assert.Equal(t, []byte{0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0x01}, wireless["bssid"])
+
+	require.NoError(t, applyAPPin(wireless, ConnectionRequest{Band: BandAny}, ""))
+	assert.NotContains(t, wireless, "band")
+	assert.NotContains(t, wireless, "bssid")
+	assert.Equal(t, []byte("home"), wireless["ssid"])
+}
@@ -0,0 +1,135 @@
+package network
+
+import (
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Security profiles reported in WiFiNetwork.Security
+const (
+	SecurityOpen          = "open"
+	SecurityOWE           = "owe"
+	SecurityPSK           = "wpa-psk"
+	SecuritySAE           = "sae"
+	SecuritySAETransition = "sae-transition"
+	SecurityEAP           = "wpa-eap"
+	SecurityUnknown       = "unknown"
+)
+
+// NM_802_11_AP_SEC_* key management bits
+const (
+	apSecKeyMgmtPSK        = uint32(0x100)
+	apSecKeyMgmt8021X      = uint32(0x200)
+	apSecKeyMgmtSAE        = uint32(0x400)
+	apSecKeyMgmtOWE        = uint32(0x800)
+	apSecKeyMgmtOWETM      = uint32(0x1000)
+	apSecKeyMgmtEAPSuiteB  = uint32(0x2000)
+	apFlagsPrivacy         = uint32(0x1)
+	wpaSupplicantDest      = "fi.w1.wpa_supplicant1"
+	wpaSupplicantPath      = "/fi/w1/wpa_supplicant1"
+	wpaSupplicantInterface = "fi.w1.wpa_supplicant1"
+)
+
+// detectWiFiSecurity maps an access point's flags to the profile to connect
+// with. APs advertising both SAE and PSK are WPA2/WPA3 transition mode
+func detectWiFiSecurity(flags, wpaFlags, rsnFlags uint32) string {
+	keyMgmt := wpaFlags | rsnFlags
+	switch {
+	case keyMgmt&(apSecKeyMgmt8021X|apSecKeyMgmtEAPSuiteB) != 0:
+		return SecurityEAP
+	case keyMgmt&apSecKeyMgmtSAE != 0 && keyMgmt&apSecKeyMgmtPSK != 0:
+		return SecuritySAETransition
+	case keyMgmt&apSecKeyMgmtSAE != 0:
+		return SecuritySAE
+	case keyMgmt&apSecKeyMgmtPSK != 0:
+		return SecurityPSK
+	case keyMgmt&(apSecKeyMgmtOWE|apSecKeyMgmtOWETM) != 0:
+		return SecurityOWE
+	case keyMgmt == 0 && flags&apFlagsPrivacy == 0:
+		return SecurityOpen
+	}
+	return SecurityUnknown
+}
+
+// securityNeedsPassword is false for open and OWE networks, which the shell
+// connects to without prompting
+func securityNeedsPassword(security string) bool {
+	return security != SecurityOpen && security != SecurityOWE
+}
+
+// hiddenNetworkSecurity guesses the profile of a hidden network, which has
+// no scan results to read flags from. NetworkManager also offers SAE for
+// wpa-psk profiles when the supplicant supports it
+func hiddenNetworkSecurity(req ConnectionRequest) string {
+	switch {
+	case req.Username != "" || req.EAPMethod != "":
+		return SecurityEAP
+	case req.Password != "" || req.Interactive:
+		return SecurityPSK
+	}
+	return SecurityOpen
+}
+
+var (
+	saeSupportOnce sync.Once
+	saeSupported   bool
+)
+
+// supplicantSupportsSAE checks wpa_supplicant's global capabilities. When
+// they can't be read, SAE is assumed to work as on any current supplicant
+var supplicantSupportsSAE = func() bool {
+	saeSupportOnce.Do(func() {
+		saeSupported = true
+
+		conn, err := dbus.SystemBus()
+		if err != nil {
+			return
+		}
+		variant, err := conn.Object(wpaSupplicantDest, wpaSupplicantPath).GetProperty(wpaSupplicantInterface + ".Capabilities")
+		if err != nil {
+			return
+		}
+		caps, ok := variant.Value().([]string)
+		if !ok {
+			return
+		}
+		saeSupported = false
+		for _, c := range caps {
+			if c == "sae" {
+				saeSupported = true
+			}
+		}
+	})
+	return saeSupported
+}
+
+// wifiSecuritySettings builds the 802-11-wireless-security block for PSK,
+// SAE and OWE networks. Transition mode prefers SAE and falls back to PSK
+// when the supplicant lacks SAE support
+func wifiSecuritySettings(security, password string, interactive bool) map[string]interface{} {
+	var sec map[string]interface{}
+	switch security {
+	case SecurityOWE:
+		return map[string]interface{}{"key-mgmt": "owe"}
+	case SecuritySAETransition:
+		if supplicantSupportsSAE() {
+			// Transition APs only offer optional PMF
+			sec = map[string]interface{}{"key-mgmt": "sae", "pmf": int32(2)}
+		} else {
+			sec = map[string]interface{}{"key-mgmt": "wpa-psk"}
+		}
+	case SecuritySAE:
+		sec = map[string]interface{}{"key-mgmt": "sae", "pmf": int32(3)}
+	case SecurityPSK:
+		sec = map[string]interface{}{"key-mgmt": "wpa-psk"}
+	default:
+		return nil
+	}
+
+	sec["psk-flags"] = uint32(0)
+	if !interactive {
+		sec["psk"] = password
+	}
+	return sec
+}
@@ -0,0 +1,143 @@
+package network
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// IPv4 methods accepted in WiredIPConfig.Method
+const (
+	IPMethodAuto   = "auto"
+	IPMethodManual = "manual"
+	// IPMethodShared hands out addresses over DHCP and NATs the device
+	// behind the machine's other connections
+	IPMethodShared = "shared"
+)
+
+type ipv4Address struct {
+	Address string
+	Prefix  uint32
+}
+
+type parsedIPConfig struct {
+	Method    string
+	Addresses []ipv4Address
+	Gateway   string
+	DNS       []net.IP
+}
+
+// splitDNS accepts the "; " separated form GetWiredNetworkDetails returns
+// as well as commas or spaces
+func splitDNS(dns string) []string {
+	return strings.FieldsFunc(dns, func(r rune) bool {
+		return r == ';' || r == ',' || r == ' '
+	})
+}
+
+func parseIPv4Config(cfg WiredIPConfig) (*parsedIPConfig, error) {
+	parsed := &parsedIPConfig{}
+
+	switch strings.ToLower(cfg.Method) {
+	case "auto", "dhcp":
+		parsed.Method = IPMethodAuto
+		if len(cfg.IPs) > 0 || cfg.Gateway != "" {
+			return nil, fmt.Errorf("addresses and gateway can only be set with the manual method")
+		}
+	case "manual", "static":
+		parsed.Method = IPMethodManual
+		if len(cfg.IPs) == 0 {
+			return nil, fmt.Errorf("manual method needs at least one address")
+		}
+	case "shared":
+		parsed.Method = IPMethodShared
+		if len(cfg.IPs) > 1 || cfg.Gateway != "" || cfg.DNS != "" {
+			return nil, fmt.Errorf("shared method takes at most one address for this machine, and no gateway or DNS")
+		}
+	default:
+		return nil, fmt.Errorf("invalid method %q (use auto, manual or shared)", cfg.Method)
+	}
+
+	var networks []*net.IPNet
+	for _, addr := range cfg.IPs {
+		ip, ipNet, err := net.ParseCIDR(strings.TrimSpace(addr))
+		if err != nil || ip.To4() == nil {
+			return nil, fmt.Errorf("invalid IPv4 address %q (use CIDR notation, e.g. 192.168.1.10/24)", addr)
+		}
+		prefix, _ := ipNet.Mask.Size()
+		parsed.Addresses = append(parsed.Addresses, ipv4Address{Address: ip.String(), Prefix: uint32(prefix)})
+		networks = append(networks, ipNet)
+	}
+
+	if cfg.Gateway != "" {
+		gateway := net.ParseIP(strings.TrimSpace(cfg.Gateway))
+		if gateway == nil || gateway.To4() == nil {
+			return nil, fmt.Errorf("invalid IPv4 gateway %q", cfg.Gateway)
+		}
+		reachable := false
+		for _, ipNet := range networks {
+			if ipNet.Contains(gateway) {
+				reachable = true
+				break
+			}
+		}
+		if !reachable {
+			return nil, fmt.Errorf("gateway %s is not in any of the configured subnets", gateway)
+		}
+		parsed.Gateway = gateway.String()
+	}
+
+	for _, server := range splitDNS(cfg.DNS) {
+		ip := net.ParseIP(server)
+		if ip == nil || ip.To4() == nil {
+			return nil, fmt.Errorf("invalid IPv4 DNS server %q", server)
+		}
+		parsed.DNS = append(parsed.DNS, ip.To4())
+	}
+
+	return parsed, nil
+}
+
+// applyIPv4Settings rewrites the ipv4 section of a NetworkManager
+// connection
+func applyIPv4Settings(settings map[string]map[string]interface{}, cfg *parsedIPConfig) {
+	ipv4 := ensureSection(settings, "ipv4")
+	dropDeprecatedAddressKeys(settings)
+
+	ipv4["method"] = cfg.Method
+	delete(ipv4, "address-data")
+	delete(ipv4, "gateway")
+	if len(cfg.Addresses) > 0 {
+		addressData := make([]map[string]interface{}, 0, len(cfg.Addresses))
+		for _, addr := range cfg.Addresses {
+			addressData = append(addressData, map[string]interface{}{
+				"address": addr.Address,
+				"prefix":  addr.Prefix,
+			})
+		}
+		ipv4["address-data"] = addressData
+	}
+	if cfg.Gateway != "" {
+		ipv4["gateway"] = cfg.Gateway
+	}
+
+	// ipv4.dns is a list of addresses as uint32 in network byte order
+	dns := make([]uint32, 0, len(cfg.DNS))
+	for _, ip := range cfg.DNS {
+		dns = append(dns, binary.NativeEndian.Uint32(ip))
+	}
+	ipv4["dns"] = dns
+}
+
+// dropDeprecatedAddressKeys removes the "addresses" and "routes" keys from
+// both families. NetworkManager returns them alongside the *-data forms and
+// refuses updates where the two disagree
+func dropDeprecatedAddressKeys(settings map[string]map[string]interface{}) {
+	for _, family := range []string{"ipv4", "ipv6"} {
+		if section, ok := settings[family]; ok {
+			delete(section, "addresses")
+			delete(section, "routes")
+		}
+	}
+}
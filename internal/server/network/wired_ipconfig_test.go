@@ -0,0 +1,103 @@
+package network
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIPv4Config_Manual(t *testing.T) {
+	parsed, err := parseIPv4Config(WiredIPConfig{
+		Method:  "static",
+		IPs:     []string{"192.168.1.10/24", "10.0.0.5/8"},
+		Gateway: "192.168.1.1",
+		DNS:     "1.1.1.1; 9.9.9.9,8.8.8.8",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, IPMethodManual, parsed.Method)
+	assert.Equal(t, []ipv4Address{{"192.168.1.10", 24}, {"10.0.0.5", 8}}, parsed.Addresses)
+	assert.Equal(t, "192.168.1.1", parsed.Gateway)
+	require.Len(t, parsed.DNS, 3)
+	assert.Equal(t, "9.9.9.9", parsed.DNS[1].String())
+}
+
+func TestParseIPv4Config_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config WiredIPConfig
+		want   string
+	}{
+		{"unknown method", WiredIPConfig{Method: "link-local"}, "invalid method"},
+		{"manual without address", WiredIPConfig{Method: "manual"}, "at least one address"},
+		{"missing prefix", WiredIPConfig{Method: "manual", IPs: []string{"192.168.1.10"}}, "CIDR"},
+		{"ipv6 address", WiredIPConfig{Method: "manual", IPs: []string{"fd00::1/64"}}, "invalid IPv4 address"},
+		{"gateway outside subnet", WiredIPConfig{Method: "manual", IPs: []string{"192.168.1.10/24"}, Gateway: "192.168.2.1"}, "not in any"},
+		{"bad dns", WiredIPConfig{Method: "auto", DNS: "one.one.one.one"}, "invalid IPv4 DNS"},
+		{"auto with address", WiredIPConfig{Method: "auto", IPs: []string{"192.168.1.10/24"}}, "only be set with the manual"},
+		{"shared with gateway", WiredIPConfig{Method: "shared", Gateway: "10.42.0.254"}, "no gateway"},
+		{"shared with two addresses", WiredIPConfig{Method: "shared", IPs: []string{"10.42.0.1/24", "10.43.0.1/24"}}, "at most one"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseIPv4Config(tt.config)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestApplyIPv4Settings(t *testing.T) {
+	settings := map[string]map[string]interface{}{
+		"connection": {"type": "802-3-ethernet"},
+		"ipv4":       {"method": "auto", "addresses": [][]uint32{}, "routes": [][]uint32{}},
+		"ipv6":       {"method": "auto", "addresses": []interface{}{}},
+	}
+
+	parsed, err := parseIPv4Config(WiredIPConfig{Method: "manual", IPs: []string{"192.168.1.10/24"}, Gateway: "192.168.1.1", DNS: "1.1.1.1"})
+	require.NoError(t, err)
+	applyIPv4Settings(settings, parsed)
+
+	ipv4 := settings["ipv4"]
+	assert.Equal(t, "manual", ipv4["method"])
+	assert.Equal(t, []map[string]interface{}{{"address": "192.168.1.10", "prefix": uint32(24)}}, ipv4["address-data"])
+	assert.Equal(t, "192.168.1.1", ipv4["gateway"])
+	assert.Equal(t, []uint32{binary.NativeEndian.Uint32(net.IPv4(1, 1, 1, 1).To4())}, ipv4["dns"])
+	assert.NotContains(t, ipv4, "addresses")
+	assert.NotContains(t, ipv4, "routes")
+	assert.NotContains(t, settings["ipv6"], "addresses")
+
+	parsed, err = parseIPv4Config(WiredIPConfig{Method: "dhcp"})
+	require.NoError(t, err)
+	applyIPv4Settings(settings, parsed)
+
+	assert.Equal(t, "auto", ipv4["method"])
+	assert.NotContains(t, ipv4, "address-data")
+	assert.NotContains(t, ipv4, "gateway")
+	assert.Equal(t, []uint32{}, ipv4["dns"])
+}
+
+func TestApplyIPv4Settings_Shared(t *testing.T) {
+	settings := map[string]map[string]interface{}{
+		"connection": {"type": "802-3-ethernet"},
+		"ipv4":       {"method": "manual", "gateway": "192.168.1.1"},
+	}
+
+	parsed, err := parseIPv4Config(WiredIPConfig{Method: "shared", IPs: []string{"192.168.50.1/24"}})
+	require.NoError(t, err)
+	applyIPv4Settings(settings, parsed)
+
+	ipv4 := settings["ipv4"]
+	assert.Equal(t, IPMethodShared, ipv4["method"])
+	assert.Equal(t, []map[string]interface{}{{"address": "192.168.50.1", "prefix": uint32(24)}}, ipv4["address-data"])
+	assert.NotContains(t, ipv4, "gateway")
+
+	// Without an address NetworkManager picks one in 10.42.x.0/24
+	parsed, err = parseIPv4Config(WiredIPConfig{Method: "shared"})
+	require.NoError(t, err)
+	applyIPv4Settings(settings, parsed)
+	assert.NotContains(t, ipv4, "address-data")
+}
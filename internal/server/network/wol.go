@@ -0,0 +1,144 @@
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// Wake-on-LAN modes, the flags of NetworkManager's 802-3-ethernet.wake-on-lan
+const (
+	WakeOnLANDefault   = "default"
+	WakeOnLANPhy       = "phy"
+	WakeOnLANUnicast   = "unicast"
+	WakeOnLANMulticast = "multicast"
+	WakeOnLANBroadcast = "broadcast"
+	WakeOnLANArp       = "arp"
+	WakeOnLANMagic     = "magic"
+	WakeOnLANIgnore    = "ignore"
+)
+
+var wakeOnLANFlags = []struct {
+	mode string
+	flag uint32
+}{
+	{WakeOnLANDefault, 0x1},
+	{WakeOnLANPhy, 0x2},
+	{WakeOnLANUnicast, 0x4},
+	{WakeOnLANMulticast, 0x8},
+	{WakeOnLANBroadcast, 0x10},
+	{WakeOnLANArp, 0x20},
+	{WakeOnLANMagic, 0x40},
+	{WakeOnLANIgnore, 0x8000},
+}
+
+const defaultWakeOnLANBroadcast = "255.255.255.255:9"
+
+// WakeOnLANConfig is a wired profile's Wake-on-LAN setting. No modes means
+// disabled; default and ignore leave it to the global default and to the
+// driver, and can't be combined with other modes. Password is the SecureOn
+// password, written as a MAC address
+type WakeOnLANConfig struct {
+	Modes    []string `json:"modes"`
+	Password string   `json:"password,omitempty"`
+}
+
+func wakeOnLANFlagsFromModes(modes []string) (uint32, error) {
+	var flags uint32
+	for _, mode := range modes {
+		found := false
+		for _, f := range wakeOnLANFlags {
+			if f.mode == strings.ToLower(mode) {
+				flags |= f.flag
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("invalid Wake-on-LAN mode %q (use default, ignore, phy, unicast, multicast, broadcast, arp or magic)", mode)
+		}
+	}
+
+	exclusive := flags & (0x1 | 0x8000)
+	if exclusive != 0 && flags != exclusive {
+		return 0, fmt.Errorf("wake-on-lan modes default and ignore can't be combined with other modes")
+	}
+	return flags, nil
+}
+
+func wakeOnLANModesFromFlags(flags uint32) []string {
+	modes := []string{}
+	for _, f := range wakeOnLANFlags {
+		if flags&f.flag != 0 {
+			modes = append(modes, f.mode)
+		}
+	}
+	return modes
+}
+
+// magicPacket is six 0xff bytes followed by the MAC address 16 times
+func magicPacket(mac string) ([]byte, error) {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAC address %q: %w", mac, err)
+	}
+	if len(hw) != 6 {
+		return nil, fmt.Errorf("invalid MAC address %q: Wake-on-LAN needs a 48-bit address", mac)
+	}
+
+	packet := bytes.Repeat([]byte{0xff}, 6)
+	for range 16 {
+		packet = append(packet, hw...)
+	}
+	return packet, nil
+}
+
+// SendMagicPacket wakes the machine with MAC mac by sending a magic packet
+// as a UDP broadcast. broadcast is the address to send to, with an optional
+// port (9 by default), e.g. a subnet's broadcast address when the limited
+// broadcast doesn't get through
+func SendMagicPacket(mac, broadcast string) error {
+	packet, err := magicPacket(mac)
+	if err != nil {
+		return err
+	}
+
+	addr := defaultWakeOnLANBroadcast
+	if broadcast != "" {
+		addr = broadcast
+		if _, _, err := net.SplitHostPort(broadcast); err != nil {
+			addr = net.JoinHostPort(broadcast, "9")
+		}
+	}
+	udpAddr, err := net.ResolveUDPAddr("udp4", addr)
+	if err != nil {
+		return fmt.Errorf("invalid broadcast address %q: %w", broadcast, err)
+	}
+
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var sockErr error
+	if err := raw.Control(func(fd uintptr) {
+		sockErr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_BROADCAST, 1)
+	}); err != nil {
+		return err
+	}
+	if sockErr != nil {
+		return fmt.Errorf("failed to enable broadcast: %w", sockErr)
+	}
+
+	if _, err := conn.WriteToUDP(packet, udpAddr); err != nil {
+		return fmt.Errorf("failed to send magic packet: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package network
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWakeOnLANFlags(t *testing.T) {
+	flags, err := wakeOnLANFlagsFromModes([]string{"magic", "Unicast"})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0x44), flags)
+	assert.Equal(t, []string{"unicast", "magic"}, wakeOnLANModesFromFlags(flags))
+
+	flags, err = wakeOnLANFlagsFromModes(nil)
+	require.NoError(t, err)
+	assert.Zero(t, flags)
+	assert.Equal(t, []string{}, wakeOnLANModesFromFlags(0))
+
+	flags, err = wakeOnLANFlagsFromModes([]string{"default"})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0x1), flags)
+
+	_, err = wakeOnLANFlagsFromModes([]string{"default", "magic"})
+	assert.Error(t, err)
+	_, err = wakeOnLANFlagsFromModes([]string{"pattern"})
+	assert.Error(t, err)
+}
+
+func TestMagicPacket(t *testing.T) {
+	packet, err := magicPacket("aa:bb:cc:dd:ee:ff")
+	require.NoError(t, err)
+	require.Len(t, packet, 102)
+	assert.Equal(t, bytes.Repeat([]byte{0xff}, 6), packet[:6])
+	for i := 6; i < len(packet); i += 6 {
+		assert.Equal(t, []byte{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}, packet[i:i+6])
+	}
+
+	_, err = magicPacket("not-a-mac")
+	assert.Error(t, err)
+	_, err = magicPacket("00:00:00:00:fe:80:00:00:00:00:00:00:02:00:5e:10:00:00:00:01")
+	assert.Error(t, err)
+}
+
+func TestSendMagicPacket(t *testing.T) {
+	listener, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer listener.Close()
+
+	require.NoError(t, SendMagicPacket("aa-bb-cc-dd-ee-ff", listener.LocalAddr().String()))
+
+	require.NoError(t, listener.SetReadDeadline(time.Now().Add(2*time.Second)))
+	buf := make([]byte, 256)
+	n, _, err := listener.ReadFromUDP(buf)
+	require.NoError(t, err)
+	want, _ := magicPacket("aa:bb:cc:dd:ee:ff")
+	assert.Equal(t, want, buf[:n])
+
+	assert.Error(t, SendMagicPacket("aa:bb:cc:dd:ee:ff", "not an address:port:x"))
+}
@@ -21,6 +21,8 @@ func HandleRequest(conn net.Conn, req models.Request) {
 		HandleUpdate(conn, req)
 	case "plugins.search":
 		HandleSearch(conn, req)
+	case "plugins.runScript":
+		HandleRunScript(conn, req)
 	default:
 		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
 	}
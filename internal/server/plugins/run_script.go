@@ -0,0 +1,76 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/plugins"
+	"github.com/AvengeMedia/danklinux/internal/sandbox"
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+// HandleRunScript runs a script the plugin declares in its plugin.json,
+// sandboxed under the plugin's policy. Plugins otherwise execute commands
+// from QML with the user's full privileges, out of the daemon's reach, so
+// this is the only path their execution can be sandboxed on; it never runs
+// anything the plugin didn't declare
+func HandleRunScript(conn net.Conn, req models.Request) {
+	pluginID, ok := req.Params["plugin"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'plugin' parameter")
+		return
+	}
+	script, ok := req.Params["script"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'script' parameter")
+		return
+	}
+
+	var args []string
+	if rawArgs, ok := req.Params["args"].([]interface{}); ok {
+		for _, arg := range rawArgs {
+			s, ok := arg.(string)
+			if !ok {
+				models.RespondError(conn, req.ID, "'args' must be a list of strings")
+				return
+			}
+			args = append(args, s)
+		}
+	}
+
+	manager, err := plugins.NewManager()
+	if err != nil {
+		models.RespondError(conn, req.ID, fmt.Sprintf("failed to create manager: %v", err))
+		return
+	}
+
+	path, err := manager.ScriptPath(pluginID, script)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	policy, err := sandbox.PolicyFor(sandbox.PoliciesPath(), pluginID)
+	if err != nil {
+		models.RespondError(conn, req.ID, fmt.Sprintf("failed to read plugin policy: %v", err))
+		return
+	}
+
+	stdout, stderr, err := sandbox.Run(policy, filepath.Dir(path), path, args...)
+	result := ScriptResult{Stdout: string(stdout), Stderr: string(stderr)}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			models.RespondError(conn, req.ID, fmt.Sprintf("failed to run script: %v", err))
+			return
+		}
+		result.ExitCode = exitErr.ExitCode()
+		log.Warnf("Plugin %s script %s exited with %d", pluginID, script, result.ExitCode)
+	}
+
+	models.Respond(conn, req.ID, result)
+}
@@ -17,6 +17,14 @@ type PluginInfo struct {
 	HasUpdate    bool     `json:"hasUpdate,omitempty"`
 }
 
+// ScriptResult is the output of a plugin script. A timeout shows up as exit
+// code 124, or -1 when the process was killed
+type ScriptResult struct {
+	ExitCode int    `json:"exitCode"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+}
+
 type SuccessResult struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
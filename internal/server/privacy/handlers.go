@@ -0,0 +1,80 @@
+package privacy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "privacy manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "privacy.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "privacy.toggleMicMute":
+		respondMute(conn, req, manager, manager.ToggleMicMute())
+	case "privacy.setMicMute":
+		muted, ok := req.Params["muted"].(bool)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'muted' parameter")
+			return
+		}
+		respondMute(conn, req, manager, manager.SetMicMuted(muted))
+	case "privacy.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func respondMute(conn net.Conn, req Request, manager *Manager, err error) {
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	message := "microphone unmuted"
+	if manager.GetState().MicMuted {
+		message = "microphone muted"
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: message})
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package privacy
+
+import (
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
+)
+
+const defaultSource = "@DEFAULT_AUDIO_SOURCE@"
+
+func NewManager() (*Manager, error) {
+	if _, err := exec.LookPath("pw-dump"); err != nil {
+		return nil, fmt.Errorf("pw-dump not found (is PipeWire installed?)")
+	}
+
+	m := newManager(pwDump, runWpctl)
+	m.poll()
+
+	m.wg.Add(2)
+	go m.pollLoop()
+	go m.notifier()
+	return m, nil
+}
+
+func newManager(dump func() ([]byte, error), wpctl func(args ...string) (string, error)) *Manager {
+	return &Manager{
+		dump:         dump,
+		wpctl:        wpctl,
+		state:        &State{},
+		pollInterval: 2 * time.Second,
+		subscribers:  make(map[string]chan State),
+		dirty:        make(chan struct{}, 1),
+		stopChan:     make(chan struct{}),
+	}
+}
+
+func (m *Manager) pollLoop() {
+	defer m.wg.Done()
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			m.poll()
+			ticker.Reset(budget.Scale(m.pollInterval))
+		}
+	}
+}
+
+func (m *Manager) poll() {
+	data, err := m.dump()
+	if err != nil {
+		log.Debugf("privacy: pw-dump failed: %v", err)
+		return
+	}
+	mic, camera, err := parseCaptures(data)
+	if err != nil {
+		log.Debugf("privacy: %v", err)
+		return
+	}
+
+	muted := m.GetState().MicMuted
+	if output, err := m.wpctl("get-volume", defaultSource); err == nil {
+		muted = parseMuted(output)
+	}
+
+	m.stateMutex.Lock()
+	events := append(diffApps(DeviceMicrophone, m.state.Microphone, mic), diffApps(DeviceCamera, m.state.Camera, camera)...)
+	changed := len(events) > 0 || muted != m.state.MicMuted
+	if changed {
+		m.state = &State{Microphone: mic, Camera: camera, MicMuted: muted, Events: events}
+	}
+	m.stateMutex.Unlock()
+
+	for _, event := range events {
+		log.Infof("privacy: %s %s using the %s", event.App.Name, event.Type, event.Device)
+	}
+	if changed {
+		m.notifySubscribers()
+	}
+}
+
+// SetMicMuted mutes the default source, which every capture stream follows
+func (m *Manager) SetMicMuted(muted bool) error {
+	value := "0"
+	if muted {
+		value = "1"
+	}
+	return m.setMute(value)
+}
+
+func (m *Manager) ToggleMicMute() error {
+	return m.setMute("toggle")
+}
+
+func (m *Manager) setMute(value string) error {
+	if _, err := m.wpctl("set-mute", defaultSource, value); err != nil {
+		return err
+	}
+	m.poll()
+	return nil
+}
+
+func (m *Manager) notifier() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.dirty:
+			state := m.GetState()
+
+			m.subMutex.RLock()
+			if m.lastNotified != nil && !stateChanged(m.lastNotified, &state) {
+				m.subMutex.RUnlock()
+				continue
+			}
+			for _, ch := range m.subscribers {
+				select {
+				case ch <- state:
+				default:
+				}
+			}
+			m.subMutex.RUnlock()
+
+			m.lastNotified = &state
+		}
+	}
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	m.wg.Wait()
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
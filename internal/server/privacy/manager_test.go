@@ -0,0 +1,72 @@
+package privacy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dumpFirefoxMic = `[
+  {"id": 30, "type": "PipeWire:Interface:Node", "info": {"state": "running", "props": {"media.class": "Audio/Source", "node.name": "alsa_input.pci"}}},
+  {"id": 70, "type": "PipeWire:Interface:Node", "info": {"state": "running", "props": {"media.class": "Stream/Input/Audio", "application.name": "Firefox", "application.process.id": 4242}}},
+  {"id": 71, "type": "PipeWire:Interface:Node", "info": {"state": "running", "props": {"media.class": "Stream/Input/Audio", "application.name": "cava", "stream.capture.sink": "true"}}},
+  {"id": 72, "type": "PipeWire:Interface:Node", "info": {"state": "idle", "props": {"media.class": "Stream/Input/Audio", "application.name": "Discord"}}},
+  {"id": 80, "type": "PipeWire:Interface:Link", "info": {"state": "active"}}
+]`
+
+const dumpCamera = `[
+  {"id": 90, "type": "PipeWire:Interface:Node", "info": {"state": "running", "props": {"media.class": "Stream/Input/Video", "node.name": "obs", "application.process.id": "77"}}}
+]`
+
+func TestParseCaptures(t *testing.T) {
+	mic, camera, err := parseCaptures([]byte(dumpFirefoxMic))
+	require.NoError(t, err)
+	assert.Equal(t, []App{{Name: "Firefox", PID: 4242}}, mic)
+	assert.Empty(t, camera)
+
+	mic, camera, err = parseCaptures([]byte(dumpCamera))
+	require.NoError(t, err)
+	assert.Empty(t, mic)
+	assert.Equal(t, []App{{Name: "obs", PID: 77}}, camera)
+
+	_, _, err = parseCaptures([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestPollEvents(t *testing.T) {
+	dump := dumpFirefoxMic
+	muted := false
+	m := newManager(
+		func() ([]byte, error) { return []byte(dump), nil },
+		func(args ...string) (string, error) {
+			switch args[0] {
+			case "set-mute":
+				muted = !muted
+				return "", nil
+			}
+			if muted {
+				return "Volume: 1.00 [MUTED]\n", nil
+			}
+			return "Volume: 1.00\n", nil
+		},
+	)
+
+	m.poll()
+	state := m.GetState()
+	assert.Equal(t, []Event{{Type: EventStarted, Device: DeviceMicrophone, App: App{Name: "Firefox", PID: 4242}}}, state.Events)
+
+	dump = dumpCamera
+	m.poll()
+	state = m.GetState()
+	assert.ElementsMatch(t, []Event{
+		{Type: EventStarted, Device: DeviceCamera, App: App{Name: "obs", PID: 77}},
+		{Type: EventStopped, Device: DeviceMicrophone, App: App{Name: "Firefox", PID: 4242}},
+	}, state.Events)
+	assert.Empty(t, state.Microphone)
+
+	require.NoError(t, m.ToggleMicMute())
+	state = m.GetState()
+	assert.True(t, state.MicMuted)
+	assert.Empty(t, state.Events)
+}
@@ -0,0 +1,124 @@
+package privacy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type pwObject struct {
+	Type string `json:"type"`
+	Info *struct {
+		State string                 `json:"state"`
+		Props map[string]interface{} `json:"props"`
+	} `json:"info"`
+}
+
+func pwDump() ([]byte, error) {
+	return exec.Command("pw-dump").Output()
+}
+
+func runWpctl(args ...string) (string, error) {
+	output, err := exec.Command("wpctl", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("wpctl: %s", strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+func propString(props map[string]interface{}, key string) string {
+	switch v := props[key].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
+// parseCaptures finds running capture streams in pw-dump output. Streams
+// recording a sink monitor (audio visualizers) don't touch the microphone
+func parseCaptures(data []byte) (mic, camera []App, err error) {
+	var objects []pwObject
+	if err := json.Unmarshal(data, &objects); err != nil {
+		return nil, nil, fmt.Errorf("invalid pw-dump output: %w", err)
+	}
+
+	for _, obj := range objects {
+		if obj.Type != "PipeWire:Interface:Node" || obj.Info == nil || obj.Info.State != "running" {
+			continue
+		}
+		props := obj.Info.Props
+
+		app := App{Name: propString(props, "application.name")}
+		if app.Name == "" {
+			app.Name = propString(props, "node.name")
+		}
+		app.PID, _ = strconv.Atoi(propString(props, "application.process.id"))
+
+		switch propString(props, "media.class") {
+		case "Stream/Input/Audio":
+			if propString(props, "stream.capture.sink") == "true" || propString(props, "stream.monitor") == "true" {
+				continue
+			}
+			mic = appendApp(mic, app)
+		case "Stream/Input/Video":
+			camera = appendApp(camera, app)
+		}
+	}
+
+	sortApps(mic)
+	sortApps(camera)
+	return mic, camera, nil
+}
+
+func appendApp(apps []App, app App) []App {
+	for _, existing := range apps {
+		if existing == app {
+			return apps
+		}
+	}
+	return append(apps, app)
+}
+
+func sortApps(apps []App) {
+	sort.Slice(apps, func(i, j int) bool {
+		if apps[i].Name != apps[j].Name {
+			return apps[i].Name < apps[j].Name
+		}
+		return apps[i].PID < apps[j].PID
+	})
+}
+
+func diffApps(device string, old, new []App) []Event {
+	var events []Event
+	for _, app := range new {
+		if !containsApp(old, app) {
+			events = append(events, Event{Type: EventStarted, Device: device, App: app})
+		}
+	}
+	for _, app := range old {
+		if !containsApp(new, app) {
+			events = append(events, Event{Type: EventStopped, Device: device, App: app})
+		}
+	}
+	return events
+}
+
+func containsApp(apps []App, app App) bool {
+	for _, a := range apps {
+		if a == app {
+			return true
+		}
+	}
+	return false
+}
+
+func parseMuted(output string) bool {
+	return strings.Contains(output, "[MUTED]")
+}
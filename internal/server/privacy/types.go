@@ -0,0 +1,98 @@
+package privacy
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+const (
+	DeviceMicrophone = "microphone"
+	DeviceCamera     = "camera"
+
+	EventStarted = "started"
+	EventStopped = "stopped"
+)
+
+// App is a client capturing from a device
+type App struct {
+	Name string `json:"name"`
+	PID  int    `json:"pid,omitempty"`
+}
+
+// Event reports an app starting or stopping use of a device, for the
+// shell's privacy indicators
+type Event struct {
+	Type   string `json:"type"`
+	Device string `json:"device"`
+	App    App    `json:"app"`
+}
+
+type State struct {
+	Microphone []App `json:"microphone"`
+	Camera     []App `json:"camera"`
+	MicMuted   bool  `json:"micMuted"`
+	// Events are the changes that led to this state
+	Events []Event `json:"events,omitempty"`
+}
+
+type Manager struct {
+	dump  func() ([]byte, error)
+	wpctl func(args ...string) (string, error)
+
+	state      *State
+	stateMutex sync.RWMutex
+
+	pollInterval time.Duration
+
+	subscribers  map[string]chan State
+	subMutex     sync.RWMutex
+	dirty        chan struct{}
+	stopChan     chan struct{}
+	wg           sync.WaitGroup
+	lastNotified *State
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.state == nil {
+		return State{}
+	}
+	stateCopy := *m.state
+	stateCopy.Microphone = append([]App(nil), m.state.Microphone...)
+	stateCopy.Camera = append([]App(nil), m.state.Camera...)
+	stateCopy.Events = append([]Event(nil), m.state.Events...)
+	return stateCopy
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func stateChanged(old, new *State) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	return !reflect.DeepEqual(old, new)
+}
@@ -0,0 +1,162 @@
+package rfkill
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+// Kernel rfkill_event, see linux/rfkill.h. The kernel accepts and returns
+// this original 8 byte layout from newer userspace and older alike
+const (
+	eventSize = 8
+
+	typeAll     = 0
+	opChangeAll = 3
+)
+
+type event struct {
+	index uint32
+	typ   uint8
+	op    uint8
+	soft  uint8
+	hard  uint8
+}
+
+func (e event) marshal() []byte {
+	b := make([]byte, eventSize)
+	b[0] = byte(e.index)
+	b[1] = byte(e.index >> 8)
+	b[2] = byte(e.index >> 16)
+	b[3] = byte(e.index >> 24)
+	b[4], b[5], b[6], b[7] = e.typ, e.op, e.soft, e.hard
+	return b
+}
+
+func NewManager() (*Manager, error) {
+	if _, err := os.Stat(rfkillDevice); err != nil {
+		return nil, fmt.Errorf("rfkill not available: %w", err)
+	}
+
+	m := newManager(rfkillSysfsDir, rfkillDevice)
+	m.refresh()
+
+	events, err := os.Open(rfkillDevice)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", rfkillDevice, err)
+	}
+	m.closeEvents = events.Close
+
+	m.wg.Add(2)
+	go m.watch(events)
+	go m.notifier()
+	return m, nil
+}
+
+func newManager(sysfsRoot, devicePath string) *Manager {
+	return &Manager{
+		sysfsRoot:   sysfsRoot,
+		devicePath:  devicePath,
+		state:       &State{Radios: []Radio{}},
+		subscribers: make(map[string]chan State),
+		dirty:       make(chan struct{}, 1),
+		stopChan:    make(chan struct{}),
+	}
+}
+
+func (m *Manager) refresh() {
+	state := buildState(readRadios(m.sysfsRoot))
+
+	m.stateMutex.Lock()
+	m.state = state
+	m.stateMutex.Unlock()
+
+	m.notifySubscribers()
+}
+
+// watch re-reads the radios on every event the kernel sends, which covers
+// radios appearing and blocks from hardware switches or other programs
+func (m *Manager) watch(events io.Reader) {
+	defer m.wg.Done()
+
+	buf := make([]byte, eventSize)
+	for {
+		if _, err := io.ReadFull(events, buf); err != nil {
+			select {
+			case <-m.stopChan:
+			default:
+				log.Warnf("rfkill: stopped watching for changes: %v", err)
+			}
+			return
+		}
+		// Events come in bursts, e.g. one per radio for airplane mode
+		time.Sleep(50 * time.Millisecond)
+		m.refresh()
+	}
+}
+
+// SetAirplaneMode blocks or unblocks every radio in a single change, so
+// WiFi, Bluetooth and WWAN switch together
+func (m *Manager) SetAirplaneMode(enabled bool) error {
+	f, err := os.OpenFile(m.devicePath, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", m.devicePath, err)
+	}
+	defer f.Close()
+
+	var soft uint8
+	if enabled {
+		soft = 1
+	}
+	if _, err := f.Write(event{typ: typeAll, op: opChangeAll, soft: soft}.marshal()); err != nil {
+		return fmt.Errorf("failed to set airplane mode: %w", err)
+	}
+
+	m.refresh()
+	return nil
+}
+
+func (m *Manager) notifier() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.dirty:
+			state := m.GetState()
+
+			m.subMutex.RLock()
+			if m.lastNotified != nil && !stateChanged(m.lastNotified, &state) {
+				m.subMutex.RUnlock()
+				continue
+			}
+			for _, ch := range m.subscribers {
+				select {
+				case ch <- state:
+				default:
+				}
+			}
+			m.subMutex.RUnlock()
+
+			m.lastNotified = &state
+		}
+	}
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	if m.closeEvents != nil {
+		m.closeEvents()
+	}
+	m.wg.Wait()
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
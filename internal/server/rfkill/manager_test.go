@@ -0,0 +1,70 @@
+package rfkill
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRadio(t *testing.T, root, dir string, files map[string]string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0755))
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(root, dir, name), []byte(content+"\n"), 0644))
+	}
+}
+
+func testRoot(t *testing.T) string {
+	root := t.TempDir()
+	writeRadio(t, root, "rfkill1", map[string]string{"name": "phy0", "type": "wlan", "soft": "1", "hard": "0"})
+	writeRadio(t, root, "rfkill0", map[string]string{"name": "hci0", "type": "bluetooth", "soft": "0", "hard": "0"})
+	writeRadio(t, root, "rfkill12", map[string]string{"name": "wwan0", "type": "wwan", "soft": "0", "hard": "1"})
+	return root
+}
+
+func TestReadRadios(t *testing.T) {
+	assert.Equal(t, []Radio{
+		{Index: 0, Name: "hci0", Type: TypeBluetooth},
+		{Index: 1, Name: "phy0", Type: TypeWLAN, SoftBlocked: true},
+		{Index: 12, Name: "wwan0", Type: TypeWWAN, HardBlocked: true},
+	}, readRadios(testRoot(t)))
+}
+
+func TestBuildState(t *testing.T) {
+	radios := readRadios(testRoot(t))
+
+	state := buildState(radios)
+	assert.False(t, state.AirplaneMode)
+	assert.True(t, state.WiFiBlocked)
+	assert.False(t, state.BluetoothBlocked)
+	assert.True(t, state.WWANBlocked)
+	assert.True(t, state.HardBlocked)
+
+	radios[0].SoftBlocked = true
+	assert.True(t, buildState(radios).AirplaneMode)
+
+	// A second WiFi card that is still on keeps WiFi unblocked
+	radios = append(radios, Radio{Index: 13, Type: TypeWLAN})
+	state = buildState(radios)
+	assert.False(t, state.WiFiBlocked)
+	assert.False(t, state.AirplaneMode)
+
+	empty := buildState(nil)
+	assert.False(t, empty.AirplaneMode)
+	assert.Equal(t, []Radio{}, empty.Radios)
+}
+
+func TestSetAirplaneModeWritesChangeAll(t *testing.T) {
+	device := filepath.Join(t.TempDir(), "rfkill")
+	require.NoError(t, os.WriteFile(device, nil, 0600))
+
+	m := newManager(t.TempDir(), device)
+	require.NoError(t, m.SetAirplaneMode(true))
+
+	data, err := os.ReadFile(device)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0, typeAll, opChangeAll, 1, 0}, data)
+}
@@ -0,0 +1,88 @@
+package rfkill
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	rfkillSysfsDir = "/sys/class/rfkill"
+	rfkillDevice   = "/dev/rfkill"
+)
+
+func readTrimmed(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readRadios reads every rfkill switch below root, sorted by index
+func readRadios(root string) []Radio {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+
+	var radios []Radio
+	for _, entry := range entries {
+		index, err := strconv.ParseUint(strings.TrimPrefix(entry.Name(), "rfkill"), 10, 32)
+		if err != nil {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		radioType := readTrimmed(filepath.Join(dir, "type"))
+		if radioType == "" {
+			continue
+		}
+		radios = append(radios, Radio{
+			Index:       uint32(index),
+			Name:        readTrimmed(filepath.Join(dir, "name")),
+			Type:        radioType,
+			SoftBlocked: readTrimmed(filepath.Join(dir, "soft")) == "1",
+			HardBlocked: readTrimmed(filepath.Join(dir, "hard")) == "1",
+		})
+	}
+	sort.Slice(radios, func(i, j int) bool { return radios[i].Index < radios[j].Index })
+	return radios
+}
+
+// buildState combines the radios. A radio type counts as blocked when all
+// radios of that type are, and airplane mode when every radio is
+func buildState(radios []Radio) *State {
+	state := &State{Radios: radios}
+	if radios == nil {
+		state.Radios = []Radio{}
+	}
+
+	byType := make(map[string][2]int)
+	blockedAll := len(radios) > 0
+	for _, r := range radios {
+		counts := byType[r.Type]
+		counts[0]++
+		blocked := r.SoftBlocked || r.HardBlocked
+		if blocked {
+			counts[1]++
+		} else {
+			blockedAll = false
+		}
+		byType[r.Type] = counts
+		if r.HardBlocked {
+			state.HardBlocked = true
+		}
+	}
+
+	typeBlocked := func(t string) bool {
+		counts, ok := byType[t]
+		return ok && counts[0] == counts[1]
+	}
+	state.AirplaneMode = blockedAll
+	state.WiFiBlocked = typeBlocked(TypeWLAN)
+	state.BluetoothBlocked = typeBlocked(TypeBluetooth)
+	state.WWANBlocked = typeBlocked(TypeWWAN)
+	return state
+}
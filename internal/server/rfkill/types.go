@@ -0,0 +1,88 @@
+package rfkill
+
+import (
+	"reflect"
+	"sync"
+)
+
+const (
+	TypeWLAN      = "wlan"
+	TypeBluetooth = "bluetooth"
+	TypeWWAN      = "wwan"
+)
+
+type Radio struct {
+	Index       uint32 `json:"index"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	SoftBlocked bool   `json:"softBlocked"`
+	HardBlocked bool   `json:"hardBlocked"`
+}
+
+type State struct {
+	// AirplaneMode is set when every radio is blocked
+	AirplaneMode     bool    `json:"airplaneMode"`
+	WiFiBlocked      bool    `json:"wifiBlocked"`
+	BluetoothBlocked bool    `json:"bluetoothBlocked"`
+	WWANBlocked      bool    `json:"wwanBlocked"`
+	HardBlocked      bool    `json:"hardBlocked"`
+	Radios           []Radio `json:"radios"`
+}
+
+type Manager struct {
+	sysfsRoot  string
+	devicePath string
+
+	state      *State
+	stateMutex sync.RWMutex
+
+	subscribers  map[string]chan State
+	subMutex     sync.RWMutex
+	dirty        chan struct{}
+	stopChan     chan struct{}
+	wg           sync.WaitGroup
+	lastNotified *State
+	closeEvents  func() error
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.state == nil {
+		return State{}
+	}
+	stateCopy := *m.state
+	stateCopy.Radios = append([]Radio(nil), m.state.Radios...)
+	return stateCopy
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func stateChanged(old, new *State) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	return !reflect.DeepEqual(old, new)
+}
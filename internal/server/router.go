@@ -5,17 +5,64 @@ import (
 	"net"
 	"strings"
 
+	"github.com/AvengeMedia/danklinux/internal/server/alerts"
+	"github.com/AvengeMedia/danklinux/internal/server/apps"
 	"github.com/AvengeMedia/danklinux/internal/server/bluez"
+	"github.com/AvengeMedia/danklinux/internal/server/breaks"
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
 	"github.com/AvengeMedia/danklinux/internal/server/dwl"
 	"github.com/AvengeMedia/danklinux/internal/server/freedesktop"
+	"github.com/AvengeMedia/danklinux/internal/server/hwmon"
+	"github.com/AvengeMedia/danklinux/internal/server/idle"
+	"github.com/AvengeMedia/danklinux/internal/server/kdeconnect"
+	"github.com/AvengeMedia/danklinux/internal/server/keyboard"
 	"github.com/AvengeMedia/danklinux/internal/server/loginctl"
+	"github.com/AvengeMedia/danklinux/internal/server/magnifier"
+	"github.com/AvengeMedia/danklinux/internal/server/mdns"
 	"github.com/AvengeMedia/danklinux/internal/server/models"
 	"github.com/AvengeMedia/danklinux/internal/server/network"
 	serverPlugins "github.com/AvengeMedia/danklinux/internal/server/plugins"
+	"github.com/AvengeMedia/danklinux/internal/server/privacy"
+	"github.com/AvengeMedia/danklinux/internal/server/rfkill"
+	"github.com/AvengeMedia/danklinux/internal/server/systemd"
+	"github.com/AvengeMedia/danklinux/internal/server/timers"
+	"github.com/AvengeMedia/danklinux/internal/server/timezones"
+	"github.com/AvengeMedia/danklinux/internal/server/wallpaper"
 	"github.com/AvengeMedia/danklinux/internal/server/wayland"
 )
 
 func RouteRequest(conn net.Conn, req models.Request) {
+	if greeterMode && !isGreeterMethodAllowed(req.Method) {
+		models.RespondError(conn, req.ID, fmt.Sprintf("method not available in greeter mode: %s", req.Method))
+		return
+	}
+
+	if !isLockedMethodAllowed(req.Method) && sessionLocked() {
+		models.RespondError(conn, req.ID, fmt.Sprintf("method not available while the session is locked: %s", req.Method))
+		return
+	}
+
+	if req.Method == "subscribe" && sessionLocked() {
+		services := lockedSubscribeServices(req.Params)
+		if len(services) == 0 {
+			models.RespondError(conn, req.ID, "no requested service is available while the session is locked")
+			return
+		}
+		params := make(map[string]interface{}, len(req.Params)+1)
+		for k, v := range req.Params {
+			params[k] = v
+		}
+		params["services"] = services
+		req.Params = params
+	}
+
+	conn = gateLockedStream(conn, req.Method)
+
+	if name, ok := moduleForMethod(req.Method); ok && !moduleEnabled(name) {
+		models.RespondError(conn, req.ID, fmt.Sprintf("module %s is disabled in %s", name, modulesConfigPath()))
+		return
+	}
+
 	if strings.HasPrefix(req.Method, "network.") {
 		if networkManager == nil {
 			models.RespondError(conn, req.ID, "network manager not initialized")
@@ -105,12 +152,224 @@ func RouteRequest(conn net.Conn, req models.Request) {
 		return
 	}
 
+	if strings.HasPrefix(req.Method, "idle.") {
+		if idleManager == nil {
+			models.RespondError(conn, req.ID, "idle manager not initialized")
+			return
+		}
+		idleReq := idle.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		idle.HandleRequest(conn, idleReq, idleManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "keyboard.") {
+		if keyboardManager == nil {
+			models.RespondError(conn, req.ID, "keyboard manager not initialized")
+			return
+		}
+		keyboardReq := keyboard.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		keyboard.HandleRequest(conn, keyboardReq, keyboardManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "wallpaper.") {
+		if wallpaperManager == nil {
+			models.RespondError(conn, req.ID, "wallpaper manager not initialized")
+			return
+		}
+		wallpaperReq := wallpaper.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		wallpaper.HandleRequest(conn, wallpaperReq, wallpaperManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "magnifier.") {
+		if magnifierManager == nil {
+			models.RespondError(conn, req.ID, "magnifier manager not initialized")
+			return
+		}
+		magnifierReq := magnifier.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		magnifier.HandleRequest(conn, magnifierReq, magnifierManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "privacy.") {
+		if privacyManager == nil {
+			models.RespondError(conn, req.ID, "privacy manager not initialized")
+			return
+		}
+		privacyReq := privacy.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		privacy.HandleRequest(conn, privacyReq, privacyManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "hwmon.") {
+		if hwmonManager == nil {
+			models.RespondError(conn, req.ID, "hwmon manager not initialized")
+			return
+		}
+		hwmonReq := hwmon.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		hwmon.HandleRequest(conn, hwmonReq, hwmonManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "rfkill.") {
+		if rfkillManager == nil {
+			models.RespondError(conn, req.ID, "rfkill manager not initialized")
+			return
+		}
+		rfkillReq := rfkill.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		rfkill.HandleRequest(conn, rfkillReq, rfkillManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "mdns.") {
+		if mdnsManager == nil {
+			models.RespondError(conn, req.ID, "mdns manager not initialized")
+			return
+		}
+		mdnsReq := mdns.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		mdns.HandleRequest(conn, mdnsReq, mdnsManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "kdeconnect.") {
+		if kdeconnectManager == nil {
+			models.RespondError(conn, req.ID, "kdeconnect manager not initialized")
+			return
+		}
+		kdeconnectReq := kdeconnect.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		kdeconnect.HandleRequest(conn, kdeconnectReq, kdeconnectManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "breaks.") {
+		if breaksManager == nil {
+			models.RespondError(conn, req.ID, "breaks manager not initialized")
+			return
+		}
+		breaksReq := breaks.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		breaks.HandleRequest(conn, breaksReq, breaksManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "timers.") {
+		if timersManager == nil {
+			models.RespondError(conn, req.ID, "timers manager not initialized")
+			return
+		}
+		timersReq := timers.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		timers.HandleRequest(conn, timersReq, timersManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "timezones.") {
+		if timezonesManager == nil {
+			models.RespondError(conn, req.ID, "timezones manager not initialized")
+			return
+		}
+		timezonesReq := timezones.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		timezones.HandleRequest(conn, timezonesReq, timezonesManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "alerts.") {
+		if alertsManager == nil {
+			models.RespondError(conn, req.ID, "alerts manager not initialized")
+			return
+		}
+		alertsReq := alerts.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		alerts.HandleRequest(conn, alertsReq, alertsManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "systemd.") {
+		if systemdManager == nil {
+			models.RespondError(conn, req.ID, "systemd manager not initialized")
+			return
+		}
+		systemdReq := systemd.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		systemd.HandleRequest(conn, systemdReq, systemdManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "apps.") {
+		if appsManager == nil {
+			models.RespondError(conn, req.ID, "apps manager not initialized")
+			return
+		}
+		appsReq := apps.Request{
+			ID:     req.ID,
+			Method: req.Method,
+			Params: req.Params,
+		}
+		apps.HandleRequest(conn, appsReq, appsManager)
+		return
+	}
+
 	switch req.Method {
 	case "ping":
 		models.Respond(conn, req.ID, "pong")
 	case "getServerInfo":
 		info := getServerInfo()
 		models.Respond(conn, req.ID, info)
+	case "getHealth":
+		models.Respond(conn, req.ID, budget.Health())
 	case "subscribe":
 		handleSubscribe(conn, req)
 	default:
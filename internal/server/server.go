@@ -11,26 +11,49 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
+	"github.com/AvengeMedia/danklinux/internal/distros"
+	"github.com/AvengeMedia/danklinux/internal/greeter"
 	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server/alerts"
+	"github.com/AvengeMedia/danklinux/internal/server/apps"
 	"github.com/AvengeMedia/danklinux/internal/server/bluez"
+	"github.com/AvengeMedia/danklinux/internal/server/breaks"
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
 	"github.com/AvengeMedia/danklinux/internal/server/dwl"
 	"github.com/AvengeMedia/danklinux/internal/server/freedesktop"
+	"github.com/AvengeMedia/danklinux/internal/server/hwmon"
+	"github.com/AvengeMedia/danklinux/internal/server/idle"
+	"github.com/AvengeMedia/danklinux/internal/server/kdeconnect"
+	"github.com/AvengeMedia/danklinux/internal/server/keyboard"
 	"github.com/AvengeMedia/danklinux/internal/server/loginctl"
+	"github.com/AvengeMedia/danklinux/internal/server/magnifier"
+	"github.com/AvengeMedia/danklinux/internal/server/mdns"
 	"github.com/AvengeMedia/danklinux/internal/server/models"
 	"github.com/AvengeMedia/danklinux/internal/server/network"
+	"github.com/AvengeMedia/danklinux/internal/server/privacy"
+	"github.com/AvengeMedia/danklinux/internal/server/rfkill"
+	"github.com/AvengeMedia/danklinux/internal/server/systemd"
+	"github.com/AvengeMedia/danklinux/internal/server/timers"
+	"github.com/AvengeMedia/danklinux/internal/server/timezones"
+	"github.com/AvengeMedia/danklinux/internal/server/wallpaper"
 	"github.com/AvengeMedia/danklinux/internal/server/wayland"
 )
 
 const APIVersion = 12
 
+// BinaryVersion is the dms build version, set by the command before Start
+var BinaryVersion = "dev"
+
 type Capabilities struct {
 	Capabilities []string `json:"capabilities"`
 }
 
 type ServerInfo struct {
-	APIVersion   int      `json:"apiVersion"`
-	Capabilities []string `json:"capabilities"`
+	APIVersion   int                     `json:"apiVersion"`
+	Capabilities []string                `json:"capabilities"`
+	Modules      map[string]ModuleStatus `json:"modules"`
 }
 
 type ServiceEvent struct {
@@ -44,6 +67,22 @@ var freedesktopManager *freedesktop.Manager
 var waylandManager *wayland.Manager
 var bluezManager *bluez.Manager
 var dwlManager *dwl.Manager
+var idleManager *idle.Manager
+var keyboardManager *keyboard.Manager
+var wallpaperManager *wallpaper.Manager
+var magnifierManager *magnifier.Manager
+var privacyManager *privacy.Manager
+var hwmonManager *hwmon.Manager
+var rfkillManager *rfkill.Manager
+var mdnsManager *mdns.Manager
+var kdeconnectManager *kdeconnect.Manager
+var breaksManager *breaks.Manager
+var timersManager *timers.Manager
+var timezonesManager *timezones.Manager
+var alertsManager *alerts.Manager
+var systemdManager *systemd.Manager
+var greeterThemeSyncer *greeter.ThemeSyncer
+var appsManager *apps.Manager
 
 func getSocketDir() string {
 	if runtime := os.Getenv("XDG_RUNTIME_DIR"); runtime != "" {
@@ -133,6 +172,7 @@ func InitializeFreedeskManager() error {
 		return err
 	}
 
+	manager.SetBackendVersion(BinaryVersion, APIVersion)
 	freedesktopManager = manager
 
 	log.Info("Freedesktop manager initialized")
@@ -181,6 +221,212 @@ func InitializeDwlManager() error {
 	return nil
 }
 
+func InitializeIdleManager() error {
+	manager, err := idle.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize idle manager: %v", err)
+		return err
+	}
+
+	idleManager = manager
+	manager.SetNightLightHook(func(paused bool) {
+		if waylandManager != nil {
+			waylandManager.SetPaused(paused)
+		}
+	})
+
+	log.Info("Idle manager initialized")
+	return nil
+}
+
+func InitializeKeyboardManager() error {
+	manager, err := keyboard.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize keyboard manager: %v", err)
+		return err
+	}
+
+	keyboardManager = manager
+
+	log.Info("Keyboard manager initialized")
+	return nil
+}
+
+func InitializeWallpaperManager() error {
+	manager, err := wallpaper.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize wallpaper manager: %v", err)
+		return err
+	}
+
+	wallpaperManager = manager
+
+	log.Info("Wallpaper manager initialized")
+	return nil
+}
+
+func InitializeMagnifierManager() error {
+	manager, err := magnifier.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize magnifier manager: %v", err)
+		return err
+	}
+
+	magnifierManager = manager
+
+	log.Info("Magnifier manager initialized")
+	return nil
+}
+
+func InitializePrivacyManager() error {
+	manager, err := privacy.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize privacy manager: %v", err)
+		return err
+	}
+
+	privacyManager = manager
+
+	log.Info("Privacy manager initialized")
+	return nil
+}
+
+func InitializeHwmonManager() error {
+	manager, err := hwmon.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize hwmon manager: %v", err)
+		return err
+	}
+
+	hwmonManager = manager
+
+	log.Info("Hwmon manager initialized")
+	return nil
+}
+
+func InitializeRfkillManager() error {
+	manager, err := rfkill.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize rfkill manager: %v", err)
+		return err
+	}
+
+	rfkillManager = manager
+
+	log.Info("Rfkill manager initialized")
+	return nil
+}
+
+func InitializeMdnsManager() error {
+	manager, err := mdns.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize mdns manager: %v", err)
+		return err
+	}
+
+	mdnsManager = manager
+
+	log.Info("Mdns manager initialized")
+	return nil
+}
+
+func InitializeKdeconnectManager() error {
+	manager, err := kdeconnect.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize kdeconnect manager: %v", err)
+		return err
+	}
+
+	kdeconnectManager = manager
+
+	log.Info("Kdeconnect manager initialized")
+	return nil
+}
+
+func InitializeBreaksManager() error {
+	manager, err := breaks.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize breaks manager: %v", err)
+		return err
+	}
+
+	breaksManager = manager
+	manager.SetSources(func() time.Duration {
+		if idleManager == nil {
+			return 0
+		}
+		return time.Duration(idleManager.GetState().IdleSeconds) * time.Second
+	}, func() bool {
+		if waylandManager == nil {
+			return false
+		}
+		state := waylandManager.GetState()
+		return state.Config.Enabled && !state.SunriseTime.IsZero() && !state.IsDay
+	})
+
+	log.Info("Breaks manager initialized")
+	return nil
+}
+
+func InitializeTimersManager() error {
+	timersManager = timers.NewManager()
+
+	log.Info("Timers manager initialized")
+	return nil
+}
+
+func InitializeTimezonesManager() error {
+	manager, err := timezones.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize timezones manager: %v", err)
+		return err
+	}
+
+	timezonesManager = manager
+
+	log.Info("Timezones manager initialized")
+	return nil
+}
+
+func InitializeAlertsManager() error {
+	manager, err := alerts.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize alerts manager: %v", err)
+		return err
+	}
+
+	alertsManager = manager
+
+	log.Info("Alerts manager initialized")
+	return nil
+}
+
+func InitializeSystemdManager() error {
+	manager, err := systemd.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize systemd manager: %v", err)
+		return err
+	}
+
+	systemdManager = manager
+
+	log.Info("Systemd manager initialized")
+	return nil
+}
+
+func InitializeAppsManager() error {
+	manager, err := apps.NewManager()
+	if err != nil {
+		log.Warnf("Failed to initialize apps manager: %v", err)
+		return err
+	}
+
+	appsManager = manager
+
+	log.Info("Apps manager initialized")
+	return nil
+}
+
 func handleConnection(conn net.Conn) {
 	defer conn.Close()
 
@@ -205,7 +451,10 @@ func handleConnection(conn net.Conn) {
 }
 
 func getCapabilities() Capabilities {
-	caps := []string{"plugins"}
+	var caps []string
+	if !greeterMode {
+		caps = append(caps, "plugins")
+	}
 
 	if networkManager != nil {
 		caps = append(caps, "network")
@@ -231,11 +480,74 @@ func getCapabilities() Capabilities {
 		caps = append(caps, "dwl")
 	}
 
+	if idleManager != nil {
+		caps = append(caps, "idle")
+	}
+
+	if keyboardManager != nil {
+		caps = append(caps, "keyboard")
+	}
+
+	if wallpaperManager != nil {
+		caps = append(caps, "wallpaper")
+	}
+
+	if magnifierManager != nil {
+		caps = append(caps, "magnifier")
+	}
+
+	if privacyManager != nil {
+		caps = append(caps, "privacy")
+	}
+
+	if hwmonManager != nil {
+		caps = append(caps, "hwmon")
+	}
+
+	if rfkillManager != nil {
+		caps = append(caps, "rfkill")
+	}
+
+	if mdnsManager != nil {
+		caps = append(caps, "mdns")
+	}
+
+	if kdeconnectManager != nil {
+		caps = append(caps, "kdeconnect")
+	}
+
+	if breaksManager != nil {
+		caps = append(caps, "breaks")
+	}
+
+	if timersManager != nil {
+		caps = append(caps, "timers")
+	}
+
+	if timezonesManager != nil {
+		caps = append(caps, "timezones")
+	}
+
+	if alertsManager != nil {
+		caps = append(caps, "alerts")
+	}
+
+	if systemdManager != nil {
+		caps = append(caps, "systemd")
+	}
+
+	if appsManager != nil {
+		caps = append(caps, "apps")
+	}
+
 	return Capabilities{Capabilities: caps}
 }
 
 func getServerInfo() ServerInfo {
-	caps := []string{"plugins"}
+	var caps []string
+	if !greeterMode {
+		caps = append(caps, "plugins")
+	}
 
 	if networkManager != nil {
 		caps = append(caps, "network")
@@ -261,9 +573,70 @@ func getServerInfo() ServerInfo {
 		caps = append(caps, "dwl")
 	}
 
+	if idleManager != nil {
+		caps = append(caps, "idle")
+	}
+
+	if keyboardManager != nil {
+		caps = append(caps, "keyboard")
+	}
+
+	if wallpaperManager != nil {
+		caps = append(caps, "wallpaper")
+	}
+
+	if magnifierManager != nil {
+		caps = append(caps, "magnifier")
+	}
+
+	if privacyManager != nil {
+		caps = append(caps, "privacy")
+	}
+
+	if hwmonManager != nil {
+		caps = append(caps, "hwmon")
+	}
+
+	if rfkillManager != nil {
+		caps = append(caps, "rfkill")
+	}
+
+	if mdnsManager != nil {
+		caps = append(caps, "mdns")
+	}
+
+	if kdeconnectManager != nil {
+		caps = append(caps, "kdeconnect")
+	}
+
+	if breaksManager != nil {
+		caps = append(caps, "breaks")
+	}
+
+	if timersManager != nil {
+		caps = append(caps, "timers")
+	}
+
+	if timezonesManager != nil {
+		caps = append(caps, "timezones")
+	}
+
+	if alertsManager != nil {
+		caps = append(caps, "alerts")
+	}
+
+	if systemdManager != nil {
+		caps = append(caps, "systemd")
+	}
+
+	if appsManager != nil {
+		caps = append(caps, "apps")
+	}
+
 	return ServerInfo{
 		APIVersion:   APIVersion,
 		Capabilities: caps,
+		Modules:      moduleStatuses(caps),
 	}
 }
 
@@ -517,96 +890,652 @@ func handleSubscribe(conn net.Conn, req models.Request) {
 		}()
 	}
 
-	go func() {
-		wg.Wait()
-		close(eventChan)
-	}()
-
-	info := getServerInfo()
-	if err := json.NewEncoder(conn).Encode(models.Response[ServiceEvent]{
-		ID:     req.ID,
-		Result: &ServiceEvent{Service: "server", Data: info},
-	}); err != nil {
-		close(stopChan)
-		return
-	}
+	if shouldSubscribe("idle") && idleManager != nil {
+		wg.Add(1)
+		idleChan := idleManager.Subscribe(clientID + "-idle")
+		go func() {
+			defer wg.Done()
+			defer idleManager.Unsubscribe(clientID + "-idle")
 
-	for event := range eventChan {
-		if err := json.NewEncoder(conn).Encode(models.Response[ServiceEvent]{
-			ID:     req.ID,
-			Result: &event,
-		}); err != nil {
-			close(stopChan)
-			return
-		}
-	}
-}
+			initialState := idleManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "idle", Data: initialState}:
+			case <-stopChan:
+				return
+			}
 
-func cleanupManagers() {
-	if networkManager != nil {
-		networkManager.Close()
-	}
-	if loginctlManager != nil {
-		loginctlManager.Close()
-	}
-	if freedesktopManager != nil {
-		freedesktopManager.Close()
-	}
-	if waylandManager != nil {
-		waylandManager.Close()
-	}
-	if bluezManager != nil {
-		bluezManager.Close()
-	}
-	if dwlManager != nil {
-		dwlManager.Close()
+			for {
+				select {
+				case state, ok := <-idleChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "idle", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
 	}
-}
 
-func Start(printDocs bool) error {
-	cleanupStaleSockets()
+	if shouldSubscribe("keyboard") && keyboardManager != nil {
+		wg.Add(1)
+		keyboardChan := keyboardManager.Subscribe(clientID + "-keyboard")
+		go func() {
+			defer wg.Done()
+			defer keyboardManager.Unsubscribe(clientID + "-keyboard")
 
-	socketPath := GetSocketPath()
-	os.Remove(socketPath)
+			initialState := keyboardManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "keyboard", Data: initialState}:
+			case <-stopChan:
+				return
+			}
 
-	listener, err := net.Listen("unix", socketPath)
-	if err != nil {
-		return err
+			for {
+				select {
+				case state, ok := <-keyboardChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "keyboard", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
 	}
-	defer listener.Close()
-	defer cleanupManagers()
 
-	go func() {
-		if err := InitializeNetworkManager(); err != nil {
-			log.Warnf("Network manager unavailable: %v", err)
-		}
-	}()
+	if shouldSubscribe("wallpaper") && wallpaperManager != nil {
+		wg.Add(1)
+		wallpaperChan := wallpaperManager.Subscribe(clientID + "-wallpaper")
+		go func() {
+			defer wg.Done()
+			defer wallpaperManager.Unsubscribe(clientID + "-wallpaper")
 
-	go func() {
-		if err := InitializeLoginctlManager(); err != nil {
-			log.Warnf("Loginctl manager unavailable: %v", err)
-		}
-	}()
+			initialState := wallpaperManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "wallpaper", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-wallpaperChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "wallpaper", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("magnifier") && magnifierManager != nil {
+		wg.Add(1)
+		magnifierChan := magnifierManager.Subscribe(clientID + "-magnifier")
+		go func() {
+			defer wg.Done()
+			defer magnifierManager.Unsubscribe(clientID + "-magnifier")
+
+			initialState := magnifierManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "magnifier", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-magnifierChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "magnifier", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("privacy") && privacyManager != nil {
+		wg.Add(1)
+		privacyChan := privacyManager.Subscribe(clientID + "-privacy")
+		go func() {
+			defer wg.Done()
+			defer privacyManager.Unsubscribe(clientID + "-privacy")
+
+			initialState := privacyManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "privacy", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-privacyChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "privacy", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("hwmon") && hwmonManager != nil {
+		wg.Add(1)
+		hwmonChan := hwmonManager.Subscribe(clientID + "-hwmon")
+		go func() {
+			defer wg.Done()
+			defer hwmonManager.Unsubscribe(clientID + "-hwmon")
+
+			initialState := hwmonManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "hwmon", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-hwmonChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "hwmon", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("rfkill") && rfkillManager != nil {
+		wg.Add(1)
+		rfkillChan := rfkillManager.Subscribe(clientID + "-rfkill")
+		go func() {
+			defer wg.Done()
+			defer rfkillManager.Unsubscribe(clientID + "-rfkill")
+
+			initialState := rfkillManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "rfkill", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-rfkillChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "rfkill", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("mdns") && mdnsManager != nil {
+		wg.Add(1)
+		mdnsChan := mdnsManager.Subscribe(clientID + "-mdns")
+		go func() {
+			defer wg.Done()
+			defer mdnsManager.Unsubscribe(clientID + "-mdns")
+
+			initialState := mdnsManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "mdns", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-mdnsChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "mdns", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("kdeconnect") && kdeconnectManager != nil {
+		wg.Add(1)
+		kdeconnectChan := kdeconnectManager.Subscribe(clientID + "-kdeconnect")
+		go func() {
+			defer wg.Done()
+			defer kdeconnectManager.Unsubscribe(clientID + "-kdeconnect")
+
+			initialState := kdeconnectManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "kdeconnect", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-kdeconnectChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "kdeconnect", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("breaks") && breaksManager != nil {
+		wg.Add(1)
+		breaksChan := breaksManager.Subscribe(clientID + "-breaks")
+		go func() {
+			defer wg.Done()
+			defer breaksManager.Unsubscribe(clientID + "-breaks")
+
+			initialState := breaksManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "breaks", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-breaksChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "breaks", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("timers") && timersManager != nil {
+		wg.Add(1)
+		timersChan := timersManager.Subscribe(clientID + "-timers")
+		go func() {
+			defer wg.Done()
+			defer timersManager.Unsubscribe(clientID + "-timers")
+
+			initialState := timersManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "timers", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-timersChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "timers", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("timezones") && timezonesManager != nil {
+		wg.Add(1)
+		timezonesChan := timezonesManager.Subscribe(clientID + "-timezones")
+		go func() {
+			defer wg.Done()
+			defer timezonesManager.Unsubscribe(clientID + "-timezones")
+
+			initialState := timezonesManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "timezones", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-timezonesChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "timezones", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("alerts") && alertsManager != nil {
+		wg.Add(1)
+		alertsChan := alertsManager.Subscribe(clientID + "-alerts")
+		go func() {
+			defer wg.Done()
+			defer alertsManager.Unsubscribe(clientID + "-alerts")
+
+			initialState := alertsManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "alerts", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-alertsChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "alerts", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("systemd") && systemdManager != nil {
+		wg.Add(1)
+		systemdChan := systemdManager.Subscribe(clientID + "-systemd")
+		go func() {
+			defer wg.Done()
+			defer systemdManager.Unsubscribe(clientID + "-systemd")
+
+			initialState := systemdManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "systemd", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-systemdChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "systemd", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("apps") && appsManager != nil {
+		wg.Add(1)
+		appsChan := appsManager.Subscribe(clientID + "-apps")
+		go func() {
+			defer wg.Done()
+			defer appsManager.Unsubscribe(clientID + "-apps")
+
+			initialState := appsManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "apps", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-appsChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "apps", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
 
 	go func() {
-		if err := InitializeFreedeskManager(); err != nil {
-			log.Warnf("Freedesktop manager unavailable: %v", err)
-		}
+		wg.Wait()
+		close(eventChan)
 	}()
 
-	if err := InitializeWaylandManager(); err != nil {
-		log.Warnf("Wayland manager unavailable: %v", err)
+	info := getServerInfo()
+	if err := json.NewEncoder(conn).Encode(models.Response[ServiceEvent]{
+		ID:     req.ID,
+		Result: &ServiceEvent{Service: "server", Data: info},
+	}); err != nil {
+		close(stopChan)
+		return
+	}
+
+	if err := writeServiceEvents(conn, req.ID, eventChan); err != nil {
+		close(stopChan)
 	}
+}
 
-	go func() {
-		if err := InitializeBluezManager(); err != nil {
-			log.Warnf("Bluez manager unavailable: %v", err)
+// writeServiceEvents streams events to conn until they run out. Services
+// picked before the session locked keep running, so their events are dropped
+// here while it is locked unless lockedServices carries them
+func writeServiceEvents(conn net.Conn, id int, events <-chan ServiceEvent) error {
+	for event := range events {
+		if !lockedServices[event.Service] && sessionLocked() {
+			continue
 		}
-	}()
+		if err := json.NewEncoder(conn).Encode(models.Response[ServiceEvent]{
+			ID:     id,
+			Result: &event,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	if err := InitializeDwlManager(); err != nil {
-		log.Warnf("DWL manager unavailable: %v", err)
+func cleanupManagers() {
+	if networkManager != nil {
+		networkManager.Close()
+	}
+	if loginctlManager != nil {
+		loginctlManager.Close()
 	}
+	if freedesktopManager != nil {
+		freedesktopManager.Close()
+	}
+	if waylandManager != nil {
+		waylandManager.Close()
+	}
+	if bluezManager != nil {
+		bluezManager.Close()
+	}
+	if dwlManager != nil {
+		dwlManager.Close()
+	}
+	if idleManager != nil {
+		idleManager.Close()
+	}
+	if keyboardManager != nil {
+		keyboardManager.Close()
+	}
+	if wallpaperManager != nil {
+		wallpaperManager.Close()
+	}
+	if magnifierManager != nil {
+		magnifierManager.Close()
+	}
+	if privacyManager != nil {
+		privacyManager.Close()
+	}
+	if hwmonManager != nil {
+		hwmonManager.Close()
+	}
+	if rfkillManager != nil {
+		rfkillManager.Close()
+	}
+	if mdnsManager != nil {
+		mdnsManager.Close()
+	}
+	if kdeconnectManager != nil {
+		kdeconnectManager.Close()
+	}
+	if breaksManager != nil {
+		breaksManager.Close()
+	}
+	if timersManager != nil {
+		timersManager.Close()
+	}
+	if timezonesManager != nil {
+		timezonesManager.Close()
+	}
+	if alertsManager != nil {
+		alertsManager.Close()
+	}
+	if systemdManager != nil {
+		systemdManager.Close()
+	}
+	if appsManager != nil {
+		appsManager.Close()
+	}
+	if greeterThemeSyncer != nil {
+		greeterThemeSyncer.Close()
+	}
+}
+
+func Start(printDocs bool) error {
+	cleanupStaleSockets()
+
+	socketPath := GetSocketPath()
+	os.Remove(socketPath)
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	defer cleanupManagers()
+
+	disabled, err := loadDisabledModules(modulesConfigPath())
+	if err != nil {
+		log.Warnf("Ignoring modules config: %v", err)
+		disabled = map[string]bool{}
+	}
+	disabledModules = disabled
+
+	budget.Start()
+	defer budget.Stop()
+
+	go startModule("network", InitializeNetworkManager)
+
+	if greeterMode {
+		log.Infof("DMS greeter network server listening on: %s", socketPath)
+		return acceptConnections(listener)
+	}
+
+	go startModule("loginctl", InitializeLoginctlManager)
+	go startModule("idle", InitializeIdleManager)
+	go startModule("freedesktop", InitializeFreedeskManager)
+
+	if env := distros.DetectEnvironment(); env.Headless() {
+		log.Infof("Headless environment (%s), skipping gamma control", env.Kind)
+	} else {
+		startModule("gamma", InitializeWaylandManager)
+	}
+
+	go startModule("keyboard", InitializeKeyboardManager)
+	go startModule("wallpaper", InitializeWallpaperManager)
+	go startModule("magnifier", InitializeMagnifierManager)
+	go startModule("privacy", InitializePrivacyManager)
+	go startModule("hwmon", InitializeHwmonManager)
+	go startModule("rfkill", InitializeRfkillManager)
+	go startModule("mdns", InitializeMdnsManager)
+	go startModule("kdeconnect", InitializeKdeconnectManager)
+	go startModule("breaks", InitializeBreaksManager)
+	go startModule("timers", InitializeTimersManager)
+	go startModule("timezones", InitializeTimezonesManager)
+	go startModule("alerts", InitializeAlertsManager)
+	go startModule("systemd", InitializeSystemdManager)
+	go startModule("apps", InitializeAppsManager)
+	go startModule("bluetooth", InitializeBluezManager)
+
+	startModule("dwl", InitializeDwlManager)
+
+	greeterThemeSyncer = greeter.NewThemeSyncer(greeter.DefaultThemeSyncPaths())
+	greeterThemeSyncer.Start()
 
 	log.Infof("DMS API Server listening on: %s", socketPath)
 	log.Info("Protocol: JSON over Unix socket")
@@ -615,7 +1544,8 @@ func Start(printDocs bool) error {
 	if printDocs {
 		log.Info("Available methods:")
 		log.Info("  ping          - Test connection")
-		log.Info("  getServerInfo - Get server info (API version and capabilities)")
+		log.Info("  getServerInfo - Get server info (API version, capabilities and each module's status: running, disabled or unavailable)")
+		log.Info("  getHealth     - Get the daemon's resource use (goroutines, memory, D-Bus messages/s per module) and whether it is throttled")
 		log.Info("  subscribe     - Subscribe to multiple services (params: services [default: all])")
 		log.Info("Plugins:")
 		log.Info(" plugins.list                - List all plugins")
@@ -624,29 +1554,61 @@ func Start(printDocs bool) error {
 		log.Info(" plugins.uninstall           - Uninstall plugin (params: name)")
 		log.Info(" plugins.update              - Update plugin (params: name)")
 		log.Info(" plugins.search              - Search plugins (params: query, category?, compositor?, capability?)")
+		log.Info(" plugins.runScript           - Run a script declared in a plugin's plugin.json, sandboxed (params: plugin, script, args?)")
 		log.Info("Network:")
 		log.Info(" network.getState            - Get current network state")
 		log.Info(" network.wifi.scan           - Scan for WiFi networks")
 		log.Info(" network.wifi.networks       - Get WiFi network list")
-		log.Info(" network.wifi.connect        - Connect to WiFi (params: ssid, password?, username?)")
+		log.Info(" network.wifi.connect        - Connect to WiFi (params: ssid, password?, username?, eapMethod?, phase2Auth?, caCert?, clientCert?, privateKey?, privateKeyPassword?, bssid?, band?)")
 		log.Info(" network.wifi.disconnect     - Disconnect WiFi")
 		log.Info(" network.wifi.forget         - Forget network (params: ssid)")
+		log.Info(" network.wifi.saved          - List all saved WiFi networks, in range or not, with when each was last connected")
+		log.Info(" network.wifi.saved.forget   - Forget several saved networks (params: ssids | all)")
+		log.Info(" network.wifi.saved.export   - Export every saved WiFi profile (params: secrets?)")
+		log.Info(" network.bundle.export       - Export every saved profile to a tarball, secrets only with a passphrase (params: path, passphrase?)")
+		log.Info(" network.bundle.import       - Import the profiles of a bundle (params: path, passphrase?, onConflict? [replace|rename|skip])")
+		log.Info(" network.wifi.selectDevice   - Select the WiFi adapter to use (params: device)")
+		log.Info(" network.wifi.known          - List iwd's saved networks in autoconnect order")
+		log.Info(" network.wifi.autoconnect    - Allow or stop iwd autoconnecting (params: ssid, enabled)")
+		log.Info(" network.wifi.wps.start      - Join a network through WPS with iwd (params: method [pushButton|pin], pin?)")
+		log.Info(" network.wifi.wps.cancel     - Cancel a WPS session")
+		log.Info(" network.wifi.signalHistory  - Signal per access point and roams (params: minutes?)")
+		log.Info(" network.wifi.qr             - QR code for sharing a saved network, after confirmation (params: ssid)")
 		log.Info(" network.wifi.toggle         - Toggle WiFi radio")
 		log.Info(" network.wifi.enable         - Enable WiFi")
 		log.Info(" network.wifi.disable        - Disable WiFi")
-		log.Info(" network.ethernet.connect    - Connect Ethernet")
-		log.Info(" network.ethernet.connect.config - Connect Ethernet to a specific configuration")
+		log.Info(" network.hotspot.start       - Share the connection over a WiFi hotspot (params: ssid, password, band [2.4|5|auto])")
+		log.Info(" network.hotspot.stop        - Stop the WiFi hotspot")
+		log.Info(" network.hotspot.qr          - QR code for joining the running hotspot")
+		log.Info(" network.ethernet.connect    - Connect Ethernet (params: eapMethod?, username?, password?, caCert?, interactive?, ... for 802.1X)")
+		log.Info(" network.ethernet.connect.config - Connect Ethernet to a specific configuration (params: uuid, device?)")
 		log.Info(" network.ethernet.disconnect - Disconnect Ethernet")
+		log.Info(" network.ethernet.setIPConfig - Set DHCP, static or shared IPv4 for a wired connection (params: uuid, method [auto|manual|shared], ips, gateway, dns)")
+		log.Info(" network.dns.set             - Set DNS servers and DNS-over-TLS for a profile (params: uuid|ssid, servers, ignoreAuto, dnsOverTls [default|no|opportunistic|yes])")
+		log.Info(" network.dns.flush           - Flush systemd-resolved's DNS cache")
+		log.Info(" network.dns.search.get      - Get a profile's search domains (params: uuid|ssid)")
+		log.Info(" network.dns.search.set      - Set a profile's search domains, ~ for routing-only (params: uuid|ssid, domains)")
+		log.Info(" network.metered.get         - Get a profile's metered mode and whether it is metered (params: uuid|ssid)")
+		log.Info(" network.metered.set         - Set a profile's metered mode (params: uuid|ssid, metered [auto|yes|no])")
+		log.Info(" network.ipv6.get            - Get a profile's IPv6 method, privacy and address generation mode (params: uuid|ssid)")
+		log.Info(" network.ipv6.set            - Set a profile's IPv6 method, privacy extensions or address generation mode (params: uuid|ssid, method?, privacy?, addrGenMode?)")
 		log.Info(" network.vpn.profiles        - List VPN profiles")
 		log.Info(" network.vpn.active          - List active VPN connections")
 		log.Info(" network.vpn.connect         - Connect VPN (params: uuidOrName|name|uuid, singleActive?)")
 		log.Info(" network.vpn.disconnect      - Disconnect VPN (params: uuidOrName|name|uuid)")
 		log.Info(" network.vpn.disconnectAll   - Disconnect all VPNs")
 		log.Info(" network.vpn.clearCredentials - Clear saved VPN credentials (params: uuidOrName|name|uuid)")
+		log.Info(" network.vpn.import          - Import an OpenVPN .ovpn file as a profile (params: path, name?)")
+		log.Info(" network.vpn.policy.get      - Get the VPN auto-connect policy")
+		log.Info(" network.vpn.policy.set      - Set the VPN auto-connect policy (params: enabled?, profile?, trustedSSIDs?, trustEthernet?, disconnectOnTrusted?)")
+		log.Info(" network.connectivity.get    - Get the connectivity probe config")
+		log.Info(" network.connectivity.set    - Probe latency, jitter and loss periodically (params: enabled?, method? [tcp|http], target?, interval?, probes?)")
+		log.Info(" network.connectivity.check  - Run a connectivity check now")
 		log.Info(" network.preference.set      - Set preference (params: preference [auto|wifi|ethernet])")
 		log.Info(" network.info                - Get network info (params: ssid)")
 		log.Info(" network.credentials.submit  - Submit credentials for prompt (params: token, secrets, save?)")
 		log.Info(" network.credentials.cancel  - Cancel credential prompt (params: token)")
+		log.Info(" network.credentials.claim   - Reserve a prompt for this client (params: token, client?)")
 		log.Info(" network.subscribe           - Subscribe to network state changes (streaming)")
 		log.Info("Loginctl:")
 		log.Info(" loginctl.getState           - Get current session state")
@@ -659,6 +1621,106 @@ func Start(printDocs bool) error {
 		log.Info(" loginctl.lockerReady        - Signal locker UI is ready (releases sleep inhibitor)")
 		log.Info(" loginctl.terminate          - Terminate session")
 		log.Info(" loginctl.subscribe          - Subscribe to session state changes (streaming)")
+		log.Info("Idle:")
+		log.Info(" idle.getState               - Get idle timeline state (idle source, idle time, fired steps, inhibitors, override)")
+		log.Info(" idle.setConfig              - Set timeline (params: steps [{action: dim|lock|dpms|suspend, timeout, onBatteryOnly?}], enabled?)")
+		log.Info(" idle.setEnabled             - Enable/disable the idle timeline (params: enabled)")
+		log.Info(" idle.setOverride            - Pause the timeline (params: reason?, duration? seconds, 0 = until cleared)")
+		log.Info(" idle.clearOverride          - Resume the timeline")
+		log.Info(" idle.caffeinate             - Toggle caffeine mode (params: duration? seconds); holds an idle/sleep inhibitor")
+		log.Info(" idle.inhibit                - Hold the timeline for an app, e.g. a media player (params: app, reason?)")
+		log.Info(" idle.uninhibit              - Release an app's inhibitor (params: app)")
+		log.Info(" idle.subscribe              - Subscribe to idle state changes (streaming)")
+		log.Info("Keyboard:")
+		log.Info(" keyboard.getState           - Get keyboard layouts and the active one")
+		log.Info(" keyboard.next               - Switch to the next layout")
+		log.Info(" keyboard.prev               - Switch to the previous layout")
+		log.Info(" keyboard.set                - Switch layout (params: layout [name, prefix or index])")
+		log.Info(" keyboard.setPerWindow       - Remember the layout per window (params: enabled)")
+		log.Info(" keyboard.subscribe          - Subscribe to layout changes (streaming)")
+		log.Info("Wallpaper:")
+		log.Info(" wallpaper.getState          - Get wallpapers, transition and slideshow state")
+		log.Info(" wallpaper.set               - Set a wallpaper (params: path, output? [default all], fill? [cover|fit|center])")
+		log.Info(" wallpaper.setTransition     - Set the transition (params: type?, duration? seconds)")
+		log.Info(" wallpaper.startSlideshow    - Cycle a directory (params: dir, interval? seconds, shuffle?, outputs?)")
+		log.Info(" wallpaper.stopSlideshow     - Stop the slideshow")
+		log.Info(" wallpaper.next              - Advance the slideshow")
+		log.Info(" wallpaper.subscribe         - Subscribe to wallpaper changes (streaming)")
+		log.Info("Magnifier:")
+		log.Info(" magnifier.getState          - Get the compositor zoom level")
+		log.Info(" magnifier.zoomIn            - Zoom in by one step")
+		log.Info(" magnifier.zoomOut           - Zoom out by one step")
+		log.Info(" magnifier.toggle            - Toggle between 1x and the last zoom level")
+		log.Info(" magnifier.set               - Set the zoom level (params: zoom [1-10])")
+		log.Info(" magnifier.setStep           - Set the zoom step (params: step)")
+		log.Info(" magnifier.subscribe         - Subscribe to zoom changes (streaming)")
+		log.Info("Privacy:")
+		log.Info(" privacy.getState            - Get apps using the microphone/camera and mic mute state")
+		log.Info(" privacy.toggleMicMute       - Toggle the microphone mute")
+		log.Info(" privacy.setMicMute          - Mute or unmute the microphone (params: muted)")
+		log.Info(" privacy.subscribe           - Subscribe to privacy events (streaming)")
+		log.Info("Hwmon:")
+		log.Info(" hwmon.getState              - Get temperatures, fan speeds and overheating sensors")
+		log.Info(" hwmon.setThreshold          - Set the overheat warning temperature (params: kind [cpu|gpu], temp)")
+		log.Info(" hwmon.subscribe             - Subscribe to sensor readings and overheat events (streaming)")
+		log.Info("Rfkill:")
+		log.Info(" rfkill.getState             - Get radio blocks and airplane mode")
+		log.Info(" rfkill.setAirplaneMode      - Block or unblock WiFi, Bluetooth and WWAN together (params: enabled)")
+		log.Info(" rfkill.subscribe            - Subscribe to radio block changes (streaming)")
+		log.Info("mDNS:")
+		log.Info(" mdns.getState               - List printers, casts and SSH/SFTP hosts found through Avahi")
+		log.Info(" mdns.subscribe              - Subscribe to discovered services (streaming)")
+		log.Info("KDE Connect:")
+		log.Info(" kdeconnect.getState         - List KDE Connect devices with battery and mirrored notifications")
+		log.Info(" kdeconnect.ring             - Make a phone ring (params: device)")
+		log.Info(" kdeconnect.sendClipboard    - Share text, or the clipboard, with a device (params: device, text?)")
+		log.Info(" kdeconnect.dismissNotification - Dismiss a notification on the phone (params: device, id)")
+		log.Info(" kdeconnect.subscribe        - Subscribe to devices and new notifications (streaming)")
+		log.Info("Breaks:")
+		log.Info(" breaks.getState             - Get break reminder config and time until the next break")
+		log.Info(" breaks.setConfig            - Configure reminders (params: enabled?, interval?, nightInterval?, duration?, idleReset?)")
+		log.Info(" breaks.setEnabled           - Turn break reminders on or off (params: enabled)")
+		log.Info(" breaks.snooze               - Remind again after some active time (params: minutes?, default 5)")
+		log.Info(" breaks.skip                 - Restart the count towards the next break")
+		log.Info(" breaks.now                  - Show the break reminder now")
+		log.Info(" breaks.subscribe            - Subscribe to break reminder state (streaming)")
+		log.Info("Timers:")
+		log.Info(" timers.getState             - List named timers and stopwatches; finished countdowns arrive as events")
+		log.Info(" timers.get                  - Get a timer with its live elapsed and remaining time (params: name)")
+		log.Info(" timers.create               - Create a timer (params: name, kind?: timer|stopwatch, duration? seconds, label?, start?)")
+		log.Info(" timers.start                - Start or resume a timer; a finished countdown starts over (params: name)")
+		log.Info(" timers.pause                - Pause a timer (params: name)")
+		log.Info(" timers.reset                - Count from zero again (params: name)")
+		log.Info(" timers.cancel               - Remove a timer (params: name)")
+		log.Info(" timers.subscribe            - Subscribe to timers and finished events (streaming)")
+		log.Info("Timezones:")
+		log.Info(" timezones.getState          - Get the local timezone, world clock cities with offsets and the next DST change, and NTP sync status")
+		log.Info(" timezones.setCities         - Set the world clock cities (params: cities: [timezone | {name?, timezone}])")
+		log.Info(" timezones.setTimezone       - Change the system timezone through timedated, authorized by polkit (params: timezone)")
+		log.Info(" timezones.syncNow           - Sync the clock with NTP now by restarting systemd-timesyncd")
+		log.Info(" timezones.subscribe         - Subscribe to timezone changes (streaming)")
+		log.Info("Alerts:")
+		log.Info(" alerts.getState             - Get the battery and disk alert levels and thresholds")
+		log.Info(" alerts.setConfig            - Set alert thresholds (params: enabled?, batteryLow?, batteryCritical?, batteryAction?: none|suspend|hibernate|poweroff, batteryActionAt?, diskPath?, diskLow? MB, diskCritical? MB)")
+		log.Info(" alerts.subscribe            - Subscribe to alert state changes (streaming)")
+		log.Info("Systemd:")
+		log.Info(" systemd.getState            - Get the status of watched systemd units")
+		log.Info(" systemd.watch               - Watch a unit (params: unit, scope [user|system])")
+		log.Info(" systemd.unwatch             - Stop watching a unit (params: unit, scope)")
+		log.Info(" systemd.restart             - Restart a unit (params: unit, scope)")
+		log.Info(" systemd.subscribe           - Subscribe to unit failures and recoveries (streaming)")
+		log.Info("Apps:")
+		log.Info(" apps.getState               - Get app index status")
+		log.Info(" apps.search                 - Search apps ranked by match and usage (params: query, limit)")
+		log.Info(" apps.list                   - List all indexed apps")
+		log.Info(" apps.get                    - Get one app (params: id)")
+		log.Info(" apps.recordLaunch           - Count a launch towards ranking (params: id)")
+		log.Info(" apps.setIconTheme           - Set the icon theme used to resolve icons (params: theme)")
+		log.Info(" apps.getDefaults            - Get default browser, terminal, file manager and image viewer")
+		log.Info(" apps.candidates             - List apps that can be the default for a category (params: category)")
+		log.Info(" apps.setDefault             - Set the default app for a category (params: category, id)")
+		log.Info(" apps.reindex                - Rescan desktop entries and icons")
+		log.Info(" apps.subscribe              - Subscribe to index changes (streaming)")
 		log.Info("Freedesktop:")
 		log.Info(" freedesktop.getState                  - Get accounts & settings state")
 		log.Info(" freedesktop.accounts.setIconFile      - Set profile icon (params: path)")
@@ -669,6 +1731,8 @@ func Start(printDocs bool) error {
 		log.Info(" freedesktop.accounts.getUserIconFile  - Get user icon (params: username)")
 		log.Info(" freedesktop.settings.getColorScheme   - Get color scheme")
 		log.Info(" freedesktop.settings.setIconTheme     - Set icon theme (params: iconTheme)")
+		log.Info(" freedesktop.system.getInfo            - Get hostname, machine model, kernel, uptime and DMS versions")
+		log.Info(" freedesktop.system.setHostname        - Set the hostname via hostnamed (params: hostname, pretty [optional])")
 		log.Info("Wayland:")
 		log.Info(" wayland.gamma.getState                - Get current gamma control state")
 		log.Info(" wayland.gamma.setTemperature          - Set temperature range (params: low, high)")
@@ -676,12 +1740,19 @@ func Start(printDocs bool) error {
 		log.Info(" wayland.gamma.setManualTimes          - Set manual times (params: sunrise, sunset)")
 		log.Info(" wayland.gamma.setGamma                - Set gamma value (params: gamma)")
 		log.Info(" wayland.gamma.setEnabled              - Enable/disable gamma control (params: enabled)")
+		log.Info(" wayland.gamma.setTransition           - Set the sunrise/sunset fade length, 0 switches at once (params: minutes)")
+		log.Info(" wayland.gamma.setTemporaryTemperature - Hold a temperature for a while, 0 resumes the schedule (params: temp, minutes [60])")
+		log.Info(" wayland.gamma.pauseUntilSunrise       - Turn night mode off until the next sunrise")
+		log.Info(" wayland.gamma.resumeSchedule          - Drop a temporary temperature or pause")
+		log.Info(" wayland.gamma.setOutputTemperature    - Hold one output at a temperature, 0 follows the schedule (params: output, temp?)")
+		log.Info(" wayland.gamma.setOutputEnabled        - Leave one output's gamma alone (params: output, enabled)")
 		log.Info(" wayland.gamma.subscribe               - Subscribe to gamma state changes (streaming)")
 		log.Info("Bluetooth:")
 		log.Info(" bluetooth.getState                    - Get current bluetooth state")
 		log.Info(" bluetooth.startDiscovery              - Start device discovery")
 		log.Info(" bluetooth.stopDiscovery               - Stop device discovery")
 		log.Info(" bluetooth.setPowered                  - Set adapter power state (params: powered)")
+		log.Info(" bluetooth.togglePowered               - Toggle adapter power")
 		log.Info(" bluetooth.pair                        - Pair with device (params: device)")
 		log.Info(" bluetooth.connect                     - Connect to device (params: device)")
 		log.Info(" bluetooth.disconnect                  - Disconnect from device (params: device)")
@@ -699,6 +1770,10 @@ func Start(printDocs bool) error {
 		log.Info(" dwl.subscribe                         - Subscribe to dwl state changes (streaming)")
 	}
 
+	return acceptConnections(listener)
+}
+
+func acceptConnections(listener net.Listener) error {
 	for {
 		conn, err := listener.Accept()
 		if err != nil {
@@ -6,6 +6,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
 
 	"github.com/AvengeMedia/danklinux/internal/server/models"
@@ -66,6 +67,194 @@ func TestGetCapabilities(t *testing.T) {
 	})
 }
 
+func TestGreeterMode(t *testing.T) {
+	originalGreeterMode := greeterMode
+	defer func() { greeterMode = originalGreeterMode }()
+
+	greeterMode = true
+
+	t.Run("capabilities exclude plugins", func(t *testing.T) {
+		caps := getCapabilities()
+		assert.NotContains(t, caps.Capabilities, "plugins")
+	})
+
+	t.Run("allowed methods", func(t *testing.T) {
+		assert.True(t, isGreeterMethodAllowed("network.wifi.connect"))
+		assert.True(t, isGreeterMethodAllowed("network.credentials.submit"))
+		assert.False(t, isGreeterMethodAllowed("network.wifi.forget"))
+		assert.False(t, isGreeterMethodAllowed("network.vpn.connect"))
+		assert.False(t, isGreeterMethodAllowed("plugins.install"))
+	})
+
+	t.Run("rejects restricted methods", func(t *testing.T) {
+		conn := &mockConn{}
+		RouteRequest(conn, models.Request{ID: 1, Method: "loginctl.lock"})
+
+		var resp models.Response[any]
+		err := json.Unmarshal(conn.written, &resp)
+		require.NoError(t, err)
+		assert.Contains(t, resp.Error, "greeter mode")
+	})
+}
+
+func TestLockedMethods(t *testing.T) {
+	assert.True(t, isLockedMethodAllowed("loginctl.unlock"))
+	assert.True(t, isLockedMethodAllowed("network.getState"))
+	assert.True(t, isLockedMethodAllowed("network.wifi.toggle"))
+	assert.True(t, isLockedMethodAllowed("privacy.toggleMicMute"))
+	assert.False(t, isLockedMethodAllowed("network.credentials.submit"))
+	assert.False(t, isLockedMethodAllowed("network.vpn.import"))
+	assert.False(t, isLockedMethodAllowed("network.wifi.qr"))
+	assert.False(t, isLockedMethodAllowed("network.wifi.forget"))
+	assert.False(t, isLockedMethodAllowed("network.wifi.saved.export"))
+	assert.False(t, isLockedMethodAllowed("network.wifi.saved.forget"))
+	assert.False(t, isLockedMethodAllowed("network.hotspot.qr"))
+	assert.False(t, isLockedMethodAllowed("network.bundle.import"))
+	assert.False(t, isLockedMethodAllowed("kdeconnect.sendClipboard"))
+	assert.False(t, isLockedMethodAllowed("kdeconnect.getState"))
+	assert.False(t, isLockedMethodAllowed("kdeconnect.subscribe"))
+	assert.False(t, isLockedMethodAllowed("bluetooth.pair"))
+	assert.False(t, isLockedMethodAllowed("bluetooth.pairing.submit"))
+	assert.False(t, isLockedMethodAllowed("clipboard.getHistory"))
+	assert.False(t, isLockedMethodAllowed("screenshot.capture"))
+	assert.False(t, isLockedMethodAllowed("plugins.install"))
+	assert.False(t, isLockedMethodAllowed("timezones.setTimezone"))
+	assert.False(t, isLockedMethodAllowed("someModule.addedLater"), "unlisted methods are refused")
+
+	services := lockedSubscribeServices(map[string]interface{}{
+		"services": []interface{}{"network", "kdeconnect", "bluetooth.pairing", "network.credentials"},
+	})
+	assert.Equal(t, []interface{}{"network"}, services)
+
+	services = lockedSubscribeServices(map[string]interface{}{"services": []interface{}{"all"}})
+	assert.Len(t, services, len(lockedServices))
+	assert.NotContains(t, services, "kdeconnect")
+	assert.Equal(t, services, lockedSubscribeServices(nil), "no services means all of them")
+
+	// Without logind the session is never considered locked
+	assert.False(t, sessionLocked())
+}
+
+func TestSubscriptionsStopWhileLocked(t *testing.T) {
+	originalSessionLocked := sessionLocked
+	defer func() { sessionLocked = originalSessionLocked }()
+	var locked atomic.Bool
+	sessionLocked = locked.Load
+
+	server, client := net.Pipe()
+	defer client.Close()
+	events := make(chan ServiceEvent)
+	go func() {
+		writeServiceEvents(server, 1, events)
+		server.Close()
+	}()
+	decoder := json.NewDecoder(client)
+	next := func() string {
+		var resp models.Response[ServiceEvent]
+		require.NoError(t, decoder.Decode(&resp))
+		return resp.Result.Service
+	}
+
+	events <- ServiceEvent{Service: "kdeconnect"}
+	assert.Equal(t, "kdeconnect", next())
+
+	locked.Store(true)
+	events <- ServiceEvent{Service: "kdeconnect"}
+	events <- ServiceEvent{Service: "network.credentials"}
+	events <- ServiceEvent{Service: "bluetooth.pairing"}
+	events <- ServiceEvent{Service: "network"}
+	assert.Equal(t, "network", next(), "only services allowed while locked get through")
+
+	locked.Store(false)
+	events <- ServiceEvent{Service: "kdeconnect"}
+	assert.Equal(t, "kdeconnect", next())
+	close(events)
+}
+
+func TestModuleStreamsStopWhileLocked(t *testing.T) {
+	originalSessionLocked := sessionLocked
+	defer func() { sessionLocked = originalSessionLocked }()
+	var locked atomic.Bool
+	sessionLocked = locked.Load
+
+	server, client := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	assert.Equal(t, server, gateLockedStream(server, "network.subscribe"), "streams allowed while locked aren't wrapped")
+	assert.Equal(t, server, gateLockedStream(server, "kdeconnect.getState"))
+
+	conn := gateLockedStream(server, "kdeconnect.subscribe")
+	lines := make(chan string, 4)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := client.Read(buf)
+			if err != nil {
+				close(lines)
+				return
+			}
+			lines <- string(buf[:n])
+		}
+	}()
+
+	_, err := conn.Write([]byte("before\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "before\n", <-lines)
+
+	locked.Store(true)
+	n, err := conn.Write([]byte("while locked\n"))
+	require.NoError(t, err)
+	assert.Equal(t, len("while locked\n"), n, "a dropped event still looks written to the stream")
+
+	locked.Store(false)
+	_, err = conn.Write([]byte("after\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "after\n", <-lines, "nothing written while locked reaches the client")
+}
+
+func TestModulesConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "modules.json")
+
+	disabled, err := loadDisabledModules(path)
+	require.NoError(t, err)
+	assert.Empty(t, disabled, "a missing config enables everything")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"disabled": ["bluetooth", "mdns", "weather"]}`), 0644))
+	disabled, err = loadDisabledModules(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"bluetooth": true, "mdns": true}, disabled)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"disabled": `), 0644))
+	_, err = loadDisabledModules(path)
+	assert.Error(t, err)
+}
+
+func TestDisabledModules(t *testing.T) {
+	original := disabledModules
+	defer func() { disabledModules = original }()
+	disabledModules = map[string]bool{"bluetooth": true, "gamma": true}
+
+	name, ok := moduleForMethod("wayland.setTemperature")
+	assert.True(t, ok)
+	assert.Equal(t, "gamma", name)
+	_, ok = moduleForMethod("getServerInfo")
+	assert.False(t, ok)
+
+	statuses := moduleStatuses([]string{"plugins", "network"})
+	assert.Equal(t, ModuleRunning, statuses["network"])
+	assert.Equal(t, ModuleDisabled, statuses["bluetooth"])
+	assert.Equal(t, ModuleDisabled, statuses["gamma"])
+	assert.Equal(t, ModuleUnavailable, statuses["mdns"])
+
+	conn := &mockConn{}
+	RouteRequest(conn, models.Request{ID: 1, Method: "bluetooth.getState"})
+	var resp models.Response[any]
+	require.NoError(t, json.Unmarshal(conn.written, &resp))
+	assert.Contains(t, resp.Error, "module bluetooth is disabled")
+}
+
 type mockConn struct {
 	net.Conn
 	written []byte
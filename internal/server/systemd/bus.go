@@ -0,0 +1,140 @@
+package systemd
+
+import (
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	dbusDest          = "org.freedesktop.systemd1"
+	dbusPath          = "/org/freedesktop/systemd1"
+	dbusManagerIface  = "org.freedesktop.systemd1.Manager"
+	dbusUnitIface     = "org.freedesktop.systemd1.Unit"
+	dbusServiceIface  = "org.freedesktop.systemd1.Service"
+	dbusPropsIface    = "org.freedesktop.DBus.Properties"
+	unitPathNamespace = "/org/freedesktop/systemd1/unit"
+)
+
+type dbusBus struct {
+	conn    *dbus.Conn
+	system  bool
+	signals chan *dbus.Signal
+	changes chan struct{}
+	stop    chan struct{}
+}
+
+func newDBusBus(scope string) (*dbusBus, error) {
+	var conn *dbus.Conn
+	var err error
+	if scope == ScopeSystem {
+		conn, err = dbus.ConnectSystemBus(budget.DBusOptions("systemd")...)
+	} else {
+		conn, err = dbus.ConnectSessionBus(budget.DBusOptions("systemd")...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	b := &dbusBus{
+		conn:    conn,
+		system:  scope == ScopeSystem,
+		signals: make(chan *dbus.Signal, 64),
+		changes: make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+
+	// Without Subscribe systemd only emits unit signals to clients that
+	// asked for them
+	manager := conn.Object(dbusDest, dbusPath)
+	if err := manager.Call(dbusManagerIface+".Subscribe", 0).Err; err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchInterface(dbusPropsIface),
+		dbus.WithMatchMember("PropertiesChanged"),
+		dbus.WithMatchPathNamespace(unitPathNamespace),
+	); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.Signal(b.signals)
+
+	go b.pump()
+	return b, nil
+}
+
+func (b *dbusBus) pump() {
+	for {
+		select {
+		case <-b.stop:
+			return
+		case sig, ok := <-b.signals:
+			if !ok {
+				return
+			}
+			if sig == nil {
+				continue
+			}
+			select {
+			case b.changes <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func (b *dbusBus) Changes() <-chan struct{} {
+	return b.changes
+}
+
+func (b *dbusBus) unitObject(unit string) (dbus.BusObject, error) {
+	var path dbus.ObjectPath
+	if err := b.conn.Object(dbusDest, dbusPath).Call(dbusManagerIface+".LoadUnit", 0, unit).Store(&path); err != nil {
+		return nil, err
+	}
+	return b.conn.Object(dbusDest, path), nil
+}
+
+func (b *dbusBus) Status(unit string) (UnitStatus, error) {
+	obj, err := b.unitObject(unit)
+	if err != nil {
+		return UnitStatus{}, err
+	}
+
+	var props map[string]dbus.Variant
+	if err := obj.Call(dbusPropsIface+".GetAll", 0, dbusUnitIface).Store(&props); err != nil {
+		return UnitStatus{}, err
+	}
+
+	status := UnitStatus{
+		Description: variantString(props["Description"]),
+		LoadState:   variantString(props["LoadState"]),
+		ActiveState: variantString(props["ActiveState"]),
+		SubState:    variantString(props["SubState"]),
+	}
+	if result, err := obj.GetProperty(dbusServiceIface + ".Result"); err == nil {
+		status.Result = variantString(result)
+	}
+	return status, nil
+}
+
+func variantString(v dbus.Variant) string {
+	s, _ := v.Value().(string)
+	return s
+}
+
+// Restart lets polkit ask for authentication when restarting system units
+func (b *dbusBus) Restart(unit string) error {
+	flags := dbus.Flags(0)
+	if b.system {
+		flags = dbus.FlagAllowInteractiveAuthorization
+	}
+	return b.conn.Object(dbusDest, dbusPath).Call(dbusManagerIface+".RestartUnit", flags, unit, "replace").Err
+}
+
+func (b *dbusBus) Close() {
+	close(b.stop)
+	b.conn.RemoveSignal(b.signals)
+	b.conn.Close()
+}
@@ -0,0 +1,80 @@
+package systemd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "systemd manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "systemd.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "systemd.watch":
+		handleUnitAction(conn, req, manager.Watch, "watching %s")
+	case "systemd.unwatch":
+		handleUnitAction(conn, req, manager.Unwatch, "stopped watching %s")
+	case "systemd.restart":
+		handleUnitAction(conn, req, manager.Restart, "restarted %s")
+	case "systemd.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleUnitAction(conn net.Conn, req Request, action func(name, scope string) error, message string) {
+	unit, ok := req.Params["unit"].(string)
+	if !ok || unit == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'unit' parameter")
+		return
+	}
+	scope, _ := req.Params["scope"].(string)
+
+	if err := action(unit, scope); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: fmt.Sprintf(message, unit)})
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package systemd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+const refreshInterval = 30 * time.Second
+
+func defaultStatePath() string {
+	return filepath.Join(os.Getenv("HOME"), ".local", "state", "DankMaterialShell", "units.json")
+}
+
+func NewManager() (*Manager, error) {
+	buses := make(map[string]Bus)
+	for _, scope := range []string{ScopeUser, ScopeSystem} {
+		bus, err := newDBusBus(scope)
+		if err != nil {
+			log.Warnf("systemd: %s manager unavailable: %v", scope, err)
+			continue
+		}
+		buses[scope] = bus
+	}
+	if len(buses) == 0 {
+		return nil, fmt.Errorf("no systemd manager reachable over D-Bus")
+	}
+
+	m := newManager(buses, defaultStatePath())
+	m.load()
+	m.refresh()
+
+	m.wg.Add(2)
+	go m.watchLoop()
+	go m.notifier()
+	return m, nil
+}
+
+func newManager(buses map[string]Bus, statePath string) *Manager {
+	return &Manager{
+		buses:       buses,
+		statePath:   statePath,
+		watched:     append([]WatchedUnit(nil), DefaultWatched...),
+		state:       &State{},
+		subscribers: make(map[string]chan State),
+		dirty:       make(chan struct{}, 1),
+		stopChan:    make(chan struct{}),
+	}
+}
+
+func (m *Manager) load() {
+	data, err := os.ReadFile(m.statePath)
+	if err != nil {
+		return
+	}
+
+	var watched []WatchedUnit
+	if err := json.Unmarshal(data, &watched); err != nil {
+		log.Warnf("systemd: ignoring invalid watch list %s: %v", m.statePath, err)
+		return
+	}
+	m.stateMutex.Lock()
+	m.watched = watched
+	m.stateMutex.Unlock()
+}
+
+func (m *Manager) save() error {
+	m.stateMutex.RLock()
+	data, err := json.MarshalIndent(m.watched, "", "  ")
+	m.stateMutex.RUnlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.statePath), 0755); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(m.statePath, data, 0644)
+}
+
+func (m *Manager) watchLoop() {
+	defer m.wg.Done()
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	var userChanges, systemChanges <-chan struct{}
+	if bus, ok := m.buses[ScopeUser]; ok {
+		userChanges = bus.Changes()
+	}
+	if bus, ok := m.buses[ScopeSystem]; ok {
+		systemChanges = bus.Changes()
+	}
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+		case <-userChanges:
+		case <-systemChanges:
+		}
+		m.refresh()
+	}
+}
+
+func isFailed(status UnitStatus) bool {
+	return status.ActiveState == "failed"
+}
+
+// refresh reads every watched unit. Units that don't exist on this system
+// are left out rather than reported as failures
+func (m *Manager) refresh() {
+	m.stateMutex.RLock()
+	watched := append([]WatchedUnit(nil), m.watched...)
+	previous := make(map[WatchedUnit]bool)
+	for _, unit := range m.state.Units {
+		previous[unit.WatchedUnit] = unit.Failed
+	}
+	m.stateMutex.RUnlock()
+
+	state := &State{}
+	for _, w := range watched {
+		bus, ok := m.buses[w.Scope]
+		if !ok {
+			continue
+		}
+		status, err := bus.Status(w.Name)
+		if err != nil {
+			log.Debugf("systemd: failed to read %s: %v", w.Name, err)
+			continue
+		}
+		if status.LoadState == "not-found" {
+			continue
+		}
+
+		unit := Unit{WatchedUnit: w, UnitStatus: status, Failed: isFailed(status)}
+		state.Units = append(state.Units, unit)
+		if unit.Failed {
+			state.Failed = append(state.Failed, w.Name)
+		}
+
+		wasFailed, seen := previous[w]
+		switch {
+		case unit.Failed && !wasFailed:
+			state.Events = append(state.Events, Event{Type: EventFailed, Unit: w.Name, Scope: w.Scope, Result: status.Result})
+			log.Warnf("systemd: %s unit %s failed (%s)", w.Scope, w.Name, status.Result)
+		case seen && wasFailed && !unit.Failed && status.ActiveState == "active":
+			state.Events = append(state.Events, Event{Type: EventRecovered, Unit: w.Name, Scope: w.Scope})
+		case seen && wasFailed && !unit.Failed:
+			// Still restarting; keep it failed until it comes back up
+			unit.Failed = true
+			state.Units[len(state.Units)-1] = unit
+			state.Failed = append(state.Failed, w.Name)
+		}
+	}
+
+	m.stateMutex.Lock()
+	m.state = state
+	m.stateMutex.Unlock()
+	m.notifySubscribers()
+}
+
+func parseScope(scope string) (string, error) {
+	switch scope {
+	case "", ScopeUser:
+		return ScopeUser, nil
+	case ScopeSystem:
+		return ScopeSystem, nil
+	}
+	return "", fmt.Errorf("invalid scope %q (use user or system)", scope)
+}
+
+func normalizeUnit(name string) string {
+	if !strings.Contains(name, ".") {
+		return name + ".service"
+	}
+	return name
+}
+
+func (m *Manager) Watch(name, scope string) error {
+	scope, err := parseScope(scope)
+	if err != nil {
+		return err
+	}
+	if _, ok := m.buses[scope]; !ok {
+		return fmt.Errorf("%s systemd manager not available", scope)
+	}
+	w := WatchedUnit{Name: normalizeUnit(name), Scope: scope}
+
+	m.stateMutex.Lock()
+	for _, existing := range m.watched {
+		if existing == w {
+			m.stateMutex.Unlock()
+			return nil
+		}
+	}
+	m.watched = append(m.watched, w)
+	sort.Slice(m.watched, func(i, j int) bool {
+		if m.watched[i].Scope != m.watched[j].Scope {
+			return m.watched[i].Scope > m.watched[j].Scope
+		}
+		return m.watched[i].Name < m.watched[j].Name
+	})
+	m.stateMutex.Unlock()
+
+	m.refresh()
+	return m.save()
+}
+
+func (m *Manager) Unwatch(name, scope string) error {
+	scope, err := parseScope(scope)
+	if err != nil {
+		return err
+	}
+	w := WatchedUnit{Name: normalizeUnit(name), Scope: scope}
+
+	m.stateMutex.Lock()
+	found := false
+	for i, existing := range m.watched {
+		if existing == w {
+			m.watched = append(m.watched[:i], m.watched[i+1:]...)
+			found = true
+			break
+		}
+	}
+	m.stateMutex.Unlock()
+	if !found {
+		return fmt.Errorf("%s is not watched", w.Name)
+	}
+
+	m.refresh()
+	return m.save()
+}
+
+func (m *Manager) Restart(name, scope string) error {
+	scope, err := parseScope(scope)
+	if err != nil {
+		return err
+	}
+	bus, ok := m.buses[scope]
+	if !ok {
+		return fmt.Errorf("%s systemd manager not available", scope)
+	}
+	if err := bus.Restart(normalizeUnit(name)); err != nil {
+		return fmt.Errorf("failed to restart %s: %w", name, err)
+	}
+	m.refresh()
+	return nil
+}
+
+func (m *Manager) notifier() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.dirty:
+			state := m.GetState()
+
+			m.subMutex.RLock()
+			if m.lastNotified != nil && !stateChanged(m.lastNotified, &state) {
+				m.subMutex.RUnlock()
+				continue
+			}
+			for _, ch := range m.subscribers {
+				select {
+				case ch <- state:
+				default:
+				}
+			}
+			m.subMutex.RUnlock()
+
+			m.lastNotified = &state
+		}
+	}
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	m.wg.Wait()
+
+	for _, bus := range m.buses {
+		bus.Close()
+	}
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
@@ -0,0 +1,98 @@
+package systemd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBus struct {
+	units     map[string]UnitStatus
+	restarted []string
+	changes   chan struct{}
+}
+
+func newFakeBus() *fakeBus {
+	return &fakeBus{
+		units: map[string]UnitStatus{
+			"pipewire.service":           {LoadState: "loaded", ActiveState: "active", SubState: "running", Result: "success"},
+			"wireplumber.service":        {LoadState: "loaded", ActiveState: "active", SubState: "running", Result: "success"},
+			"xdg-desktop-portal.service": {LoadState: "loaded", ActiveState: "active", SubState: "running", Result: "success"},
+		},
+		changes: make(chan struct{}),
+	}
+}
+
+func (b *fakeBus) Status(unit string) (UnitStatus, error) {
+	if status, ok := b.units[unit]; ok {
+		return status, nil
+	}
+	return UnitStatus{LoadState: "not-found", ActiveState: "inactive"}, nil
+}
+
+func (b *fakeBus) Restart(unit string) error {
+	b.restarted = append(b.restarted, unit)
+	b.units[unit] = UnitStatus{LoadState: "loaded", ActiveState: "active", SubState: "running", Result: "success"}
+	return nil
+}
+
+func (b *fakeBus) Changes() <-chan struct{} { return b.changes }
+func (b *fakeBus) Close()                   {}
+
+func TestFailureAndRecovery(t *testing.T) {
+	user := newFakeBus()
+	m := newManager(map[string]Bus{ScopeUser: user}, filepath.Join(t.TempDir(), "units.json"))
+
+	m.refresh()
+	state := m.GetState()
+	assert.Len(t, state.Units, 3)
+	assert.Empty(t, state.Failed)
+	assert.Empty(t, state.Events)
+
+	user.units["xdg-desktop-portal.service"] = UnitStatus{LoadState: "loaded", ActiveState: "failed", SubState: "failed", Result: "core-dump"}
+	m.refresh()
+	state = m.GetState()
+	assert.Equal(t, []string{"xdg-desktop-portal.service"}, state.Failed)
+	assert.Equal(t, []Event{{Type: EventFailed, Unit: "xdg-desktop-portal.service", Scope: ScopeUser, Result: "core-dump"}}, state.Events)
+
+	m.refresh()
+	assert.Empty(t, m.GetState().Events)
+
+	require.NoError(t, m.Restart("xdg-desktop-portal", ""))
+	assert.Equal(t, []string{"xdg-desktop-portal.service"}, user.restarted)
+	state = m.GetState()
+	assert.Empty(t, state.Failed)
+	assert.Equal(t, EventRecovered, state.Events[0].Type)
+}
+
+func TestWatchPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "units.json")
+	user, system := newFakeBus(), newFakeBus()
+	system.units["tailscaled.service"] = UnitStatus{LoadState: "loaded", ActiveState: "failed", Result: "exit-code"}
+
+	m := newManager(map[string]Bus{ScopeUser: user, ScopeSystem: system}, path)
+	require.NoError(t, m.Watch("tailscaled", ScopeSystem))
+	require.NoError(t, m.Unwatch("wireplumber.service", ScopeUser))
+	assert.Error(t, m.Unwatch("missing.service", ScopeUser))
+	assert.Error(t, m.Watch("foo", "session"))
+	assert.Equal(t, []string{"tailscaled.service"}, m.GetState().Failed)
+
+	reloaded := newManager(map[string]Bus{ScopeUser: user, ScopeSystem: system}, path)
+	reloaded.load()
+	assert.Equal(t, []WatchedUnit{
+		{Name: "pipewire.service", Scope: ScopeUser},
+		{Name: "xdg-desktop-portal.service", Scope: ScopeUser},
+		{Name: "tailscaled.service", Scope: ScopeSystem},
+	}, reloaded.watched)
+}
+
+func TestMissingUnitsSkipped(t *testing.T) {
+	user := newFakeBus()
+	delete(user.units, "wireplumber.service")
+	m := newManager(map[string]Bus{ScopeUser: user}, filepath.Join(t.TempDir(), "units.json"))
+
+	m.refresh()
+	assert.Len(t, m.GetState().Units, 2)
+}
@@ -0,0 +1,126 @@
+package systemd
+
+import (
+	"reflect"
+	"sync"
+)
+
+const (
+	ScopeUser   = "user"
+	ScopeSystem = "system"
+
+	EventFailed    = "failed"
+	EventRecovered = "recovered"
+)
+
+// WatchedUnit names a unit on the user or system manager
+type WatchedUnit struct {
+	Name  string `json:"name"`
+	Scope string `json:"scope"`
+}
+
+// DefaultWatched are the services whose failure breaks the shell in ways
+// that are hard to trace back to them
+var DefaultWatched = []WatchedUnit{
+	{Name: "pipewire.service", Scope: ScopeUser},
+	{Name: "wireplumber.service", Scope: ScopeUser},
+	{Name: "xdg-desktop-portal.service", Scope: ScopeUser},
+}
+
+type UnitStatus struct {
+	Description string `json:"description"`
+	LoadState   string `json:"loadState"`
+	ActiveState string `json:"activeState"`
+	SubState    string `json:"subState"`
+	// Result is the service's last exit result, e.g. exit-code or core-dump
+	Result string `json:"result,omitempty"`
+}
+
+type Unit struct {
+	WatchedUnit
+	UnitStatus
+	Failed bool `json:"failed"`
+}
+
+type Event struct {
+	Type   string `json:"type"`
+	Unit   string `json:"unit"`
+	Scope  string `json:"scope"`
+	Result string `json:"result,omitempty"`
+}
+
+type State struct {
+	Units  []Unit   `json:"units"`
+	Failed []string `json:"failed"`
+	// Events are the failures and recoveries found by the latest refresh
+	Events []Event `json:"events,omitempty"`
+}
+
+// Bus talks to one systemd manager
+type Bus interface {
+	Status(unit string) (UnitStatus, error)
+	Restart(unit string) error
+	// Changes fires when any unit's properties change
+	Changes() <-chan struct{}
+	Close()
+}
+
+type Manager struct {
+	buses     map[string]Bus
+	statePath string
+
+	watched    []WatchedUnit
+	state      *State
+	stateMutex sync.RWMutex
+
+	subscribers  map[string]chan State
+	subMutex     sync.RWMutex
+	dirty        chan struct{}
+	stopChan     chan struct{}
+	wg           sync.WaitGroup
+	lastNotified *State
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.state == nil {
+		return State{}
+	}
+	stateCopy := *m.state
+	stateCopy.Units = append([]Unit(nil), m.state.Units...)
+	stateCopy.Failed = append([]string(nil), m.state.Failed...)
+	stateCopy.Events = append([]Event(nil), m.state.Events...)
+	return stateCopy
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func stateChanged(old, new *State) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	return !reflect.DeepEqual(old, new)
+}
@@ -0,0 +1,124 @@
+package timers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "timers manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "timers.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "timers.get":
+		name, ok := req.Params["name"].(string)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'name' parameter")
+			return
+		}
+		timer, err := manager.Get(name)
+		if err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		models.Respond(conn, req.ID, timer)
+	case "timers.create":
+		handleCreate(conn, req, manager)
+	case "timers.start":
+		handleAction(conn, req, manager.Start, "started")
+	case "timers.pause":
+		handleAction(conn, req, manager.Pause, "paused")
+	case "timers.reset":
+		handleAction(conn, req, manager.Reset, "reset")
+	case "timers.cancel":
+		handleAction(conn, req, manager.Cancel, "canceled")
+	case "timers.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleAction(conn net.Conn, req Request, action func(string) error, done string) {
+	name, ok := req.Params["name"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'name' parameter")
+		return
+	}
+	if err := action(name); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: fmt.Sprintf("timer %s %s", name, done)})
+}
+
+func handleCreate(conn net.Conn, req Request, manager *Manager) {
+	name, ok := req.Params["name"].(string)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'name' parameter")
+		return
+	}
+	kind, _ := req.Params["kind"].(string)
+	if kind == "" {
+		kind = KindTimer
+	}
+	label, _ := req.Params["label"].(string)
+
+	var duration time.Duration
+	if seconds, ok := req.Params["duration"].(float64); ok {
+		duration = time.Duration(seconds * float64(time.Second))
+	}
+	start := true
+	if s, ok := req.Params["start"].(bool); ok {
+		start = s
+	}
+
+	if err := manager.Create(name, kind, label, duration, start); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	timer, _ := manager.Get(name)
+	models.Respond(conn, req.ID, timer)
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
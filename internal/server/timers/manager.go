@@ -0,0 +1,305 @@
+package timers
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+const (
+	maxTimers     = 64
+	maxNameLength = 64
+	maxDuration   = 7 * 24 * time.Hour
+)
+
+func NewManager() *Manager {
+	m := newManager()
+	m.wg.Add(1)
+	go m.notifier()
+	return m
+}
+
+func newManager() *Manager {
+	return &Manager{
+		timers:      make(map[string]*timer),
+		now:         time.Now,
+		state:       &State{Timers: []Timer{}},
+		subscribers: make(map[string]chan State),
+		dirty:       make(chan struct{}, 1),
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// validateName keeps names usable as IPC arguments, e.g. pomodoro.work
+func validateName(name string) error {
+	if name == "" || len(name) > maxNameLength {
+		return fmt.Errorf("timer name must have 1 to %d characters", maxNameLength)
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '.', r == '-', r == '_':
+		default:
+			return fmt.Errorf("invalid timer name %q (use letters, digits, '.', '-' and '_')", name)
+		}
+	}
+	return nil
+}
+
+// Create adds a countdown of duration, or a stopwatch, and starts it right
+// away if start is set
+func (m *Manager) Create(name, kind, label string, duration time.Duration, start bool) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+	switch kind {
+	case KindTimer:
+		if duration <= 0 || duration > maxDuration {
+			return fmt.Errorf("timer duration must be between 1 second and 7 days")
+		}
+	case KindStopwatch:
+		duration = 0
+	default:
+		return fmt.Errorf("invalid kind %q (use %s or %s)", kind, KindTimer, KindStopwatch)
+	}
+
+	m.timersMutex.Lock()
+	if _, ok := m.timers[name]; ok {
+		m.timersMutex.Unlock()
+		return fmt.Errorf("timer %q already exists", name)
+	}
+	if len(m.timers) >= maxTimers {
+		m.timersMutex.Unlock()
+		return fmt.Errorf("too many timers (at most %d)", maxTimers)
+	}
+	t := &timer{kind: kind, label: label, duration: duration}
+	m.timers[name] = t
+	if start {
+		m.resume(name, t, m.now())
+	}
+	m.timersMutex.Unlock()
+
+	m.publish(nil)
+	return nil
+}
+
+// Start resumes a paused timer. A finished countdown starts over
+func (m *Manager) Start(name string) error {
+	m.timersMutex.Lock()
+	t, ok := m.timers[name]
+	if !ok {
+		m.timersMutex.Unlock()
+		return fmt.Errorf("no timer named %q", name)
+	}
+	if t.finished {
+		t.elapsed = 0
+		t.finished = false
+	}
+	if t.resumedAt.IsZero() {
+		m.resume(name, t, m.now())
+	}
+	m.timersMutex.Unlock()
+
+	m.publish(nil)
+	return nil
+}
+
+func (m *Manager) Pause(name string) error {
+	m.timersMutex.Lock()
+	t, ok := m.timers[name]
+	if !ok {
+		m.timersMutex.Unlock()
+		return fmt.Errorf("no timer named %q", name)
+	}
+	if !t.resumedAt.IsZero() {
+		t.elapsed = t.elapsedAt(m.now())
+		t.resumedAt = time.Time{}
+		t.stop()
+	}
+	m.timersMutex.Unlock()
+
+	m.publish(nil)
+	return nil
+}
+
+// Reset counts from zero again, leaving the timer running or paused
+func (m *Manager) Reset(name string) error {
+	m.timersMutex.Lock()
+	t, ok := m.timers[name]
+	if !ok {
+		m.timersMutex.Unlock()
+		return fmt.Errorf("no timer named %q", name)
+	}
+	running := !t.resumedAt.IsZero()
+	t.stop()
+	t.elapsed = 0
+	t.resumedAt = time.Time{}
+	t.finished = false
+	if running {
+		m.resume(name, t, m.now())
+	}
+	m.timersMutex.Unlock()
+
+	m.publish(nil)
+	return nil
+}
+
+func (m *Manager) Cancel(name string) error {
+	m.timersMutex.Lock()
+	t, ok := m.timers[name]
+	if !ok {
+		m.timersMutex.Unlock()
+		return fmt.Errorf("no timer named %q", name)
+	}
+	t.stop()
+	delete(m.timers, name)
+	m.timersMutex.Unlock()
+
+	m.publish(nil)
+	return nil
+}
+
+// Get returns a timer with its elapsed and remaining time as of now
+func (m *Manager) Get(name string) (Timer, error) {
+	m.timersMutex.Lock()
+	defer m.timersMutex.Unlock()
+
+	t, ok := m.timers[name]
+	if !ok {
+		return Timer{}, fmt.Errorf("no timer named %q", name)
+	}
+	return t.snapshot(name, t.elapsedAt(m.now())), nil
+}
+
+func (m *Manager) resume(name string, t *timer, now time.Time) {
+	t.resumedAt = now
+	if t.kind != KindTimer {
+		return
+	}
+	t.generation++
+	generation := t.generation
+	t.alarm = time.AfterFunc(t.duration-t.elapsed, func() {
+		m.finish(name, t, generation)
+	})
+}
+
+// finish ends a countdown. An alarm that was already firing when its timer
+// was paused, reset or canceled is ignored
+func (m *Manager) finish(name string, t *timer, generation uint64) {
+	m.timersMutex.Lock()
+	if m.timers[name] != t || t.generation != generation || t.resumedAt.IsZero() {
+		m.timersMutex.Unlock()
+		return
+	}
+	t.elapsed = t.duration
+	t.resumedAt = time.Time{}
+	t.finished = true
+	t.alarm = nil
+	event := Event{Type: EventFinished, Name: name, Label: t.label, Time: m.now()}
+	m.timersMutex.Unlock()
+
+	log.Infof("Timer %s finished", name)
+	m.publish([]Event{event})
+}
+
+func (t *timer) stop() {
+	t.generation++
+	if t.alarm != nil {
+		t.alarm.Stop()
+		t.alarm = nil
+	}
+}
+
+func (t *timer) elapsedAt(now time.Time) time.Duration {
+	elapsed := t.elapsed
+	if !t.resumedAt.IsZero() {
+		elapsed += now.Sub(t.resumedAt)
+	}
+	if t.kind == KindTimer && elapsed > t.duration {
+		elapsed = t.duration
+	}
+	return elapsed
+}
+
+func (t *timer) snapshot(name string, elapsed time.Duration) Timer {
+	out := Timer{
+		Name:      name,
+		Kind:      t.kind,
+		Label:     t.label,
+		Elapsed:   elapsed.Seconds(),
+		Running:   !t.resumedAt.IsZero(),
+		Finished:  t.finished,
+		ResumedAt: t.resumedAt,
+	}
+	if t.kind == KindTimer {
+		out.Duration = t.duration.Seconds()
+		out.Remaining = (t.duration - elapsed).Seconds()
+		if out.Running {
+			out.EndsAt = t.resumedAt.Add(t.duration - t.elapsed)
+		}
+	}
+	return out
+}
+
+func (m *Manager) publish(events []Event) {
+	m.timersMutex.Lock()
+	state := &State{Timers: make([]Timer, 0, len(m.timers)), Events: events}
+	for name, t := range m.timers {
+		state.Timers = append(state.Timers, t.snapshot(name, t.elapsed))
+	}
+	m.timersMutex.Unlock()
+	sort.Slice(state.Timers, func(i, j int) bool { return state.Timers[i].Name < state.Timers[j].Name })
+
+	m.stateMutex.Lock()
+	m.state = state
+	m.stateMutex.Unlock()
+
+	m.notifySubscribers()
+}
+
+func (m *Manager) notifier() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.dirty:
+			state := m.GetState()
+
+			m.subMutex.RLock()
+			if m.lastNotified != nil && !stateChanged(m.lastNotified, &state) {
+				m.subMutex.RUnlock()
+				continue
+			}
+			for _, ch := range m.subscribers {
+				select {
+				case ch <- state:
+				default:
+				}
+			}
+			m.subMutex.RUnlock()
+
+			m.lastNotified = &state
+		}
+	}
+}
+
+func (m *Manager) Close() {
+	m.timersMutex.Lock()
+	for _, t := range m.timers {
+		t.stop()
+	}
+	m.timersMutex.Unlock()
+
+	close(m.stopChan)
+	m.wg.Wait()
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
@@ -0,0 +1,114 @@
+package timers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func newTestManager() (*Manager, *clock) {
+	m := newManager()
+	c := &clock{now: time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)}
+	m.now = func() time.Time { return c.now }
+	return m, c
+}
+
+func TestStopwatch(t *testing.T) {
+	m, c := newTestManager()
+
+	require.NoError(t, m.Create("sw", KindStopwatch, "", time.Hour, true))
+	c.now = c.now.Add(10 * time.Second)
+	require.NoError(t, m.Pause("sw"))
+	c.now = c.now.Add(time.Minute)
+	require.NoError(t, m.Start("sw"))
+	c.now = c.now.Add(5 * time.Second)
+
+	timer, err := m.Get("sw")
+	require.NoError(t, err)
+	assert.Equal(t, 15.0, timer.Elapsed)
+	assert.Zero(t, timer.Duration)
+	assert.True(t, timer.Running)
+
+	state := m.GetState()
+	require.Len(t, state.Timers, 1)
+	assert.Equal(t, 10.0, state.Timers[0].Elapsed, "state holds the time counted up to resumedAt")
+	assert.Equal(t, c.now.Add(-5*time.Second), state.Timers[0].ResumedAt)
+}
+
+func TestCountdownFinishes(t *testing.T) {
+	m := newManager()
+
+	require.NoError(t, m.Create("tea", KindTimer, "Tea", 20*time.Millisecond, true))
+	timer, err := m.Get("tea")
+	require.NoError(t, err)
+	assert.False(t, timer.EndsAt.IsZero())
+
+	require.Eventually(t, func() bool { return len(m.GetState().Events) == 1 }, time.Second, 5*time.Millisecond)
+	state := m.GetState()
+	assert.Equal(t, EventFinished, state.Events[0].Type)
+	assert.Equal(t, "Tea", state.Events[0].Label)
+	require.Len(t, state.Timers, 1)
+	assert.True(t, state.Timers[0].Finished)
+	assert.False(t, state.Timers[0].Running)
+	assert.Zero(t, state.Timers[0].Remaining)
+
+	// Starting a finished countdown runs it again
+	require.NoError(t, m.Start("tea"))
+	state = m.GetState()
+	assert.Empty(t, state.Events)
+	assert.False(t, state.Timers[0].Finished)
+	assert.True(t, state.Timers[0].Running)
+}
+
+func TestPausedCountdownDoesNotFinish(t *testing.T) {
+	m := newManager()
+
+	require.NoError(t, m.Create("t", KindTimer, "", 20*time.Millisecond, true))
+	require.NoError(t, m.Pause("t"))
+	time.Sleep(50 * time.Millisecond)
+
+	timer, err := m.Get("t")
+	require.NoError(t, err)
+	assert.False(t, timer.Finished)
+	assert.Empty(t, m.GetState().Events)
+}
+
+func TestResetAndCancel(t *testing.T) {
+	m, c := newTestManager()
+
+	require.NoError(t, m.Create("t", KindTimer, "", time.Hour, false))
+	timer, _ := m.Get("t")
+	assert.False(t, timer.Running)
+	assert.Equal(t, 3600.0, timer.Remaining)
+
+	require.NoError(t, m.Start("t"))
+	c.now = c.now.Add(time.Minute)
+	require.NoError(t, m.Reset("t"))
+	timer, _ = m.Get("t")
+	assert.True(t, timer.Running)
+	assert.Zero(t, timer.Elapsed)
+
+	require.NoError(t, m.Cancel("t"))
+	assert.Empty(t, m.GetState().Timers)
+	_, err := m.Get("t")
+	assert.Error(t, err)
+	assert.Error(t, m.Pause("t"))
+}
+
+func TestCreateValidation(t *testing.T) {
+	m, _ := newTestManager()
+
+	assert.Error(t, m.Create("", KindTimer, "", time.Minute, false))
+	assert.Error(t, m.Create("has space", KindTimer, "", time.Minute, false))
+	assert.Error(t, m.Create("t", KindTimer, "", 0, false))
+	assert.Error(t, m.Create("t", "alarm", "", time.Minute, false))
+
+	require.NoError(t, m.Create("pomodoro.work", KindTimer, "", 25*time.Minute, false))
+	assert.Error(t, m.Create("pomodoro.work", KindTimer, "", 25*time.Minute, false))
+}
@@ -0,0 +1,119 @@
+package timers
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+const (
+	KindTimer     = "timer"
+	KindStopwatch = "stopwatch"
+
+	EventFinished = "finished"
+)
+
+// Timer is a countdown or stopwatch. Elapsed is the time counted up to
+// ResumedAt, so a running timer's live value is Elapsed plus the time since
+// ResumedAt; the state only changes on start, pause and finish
+type Timer struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// Label is free text for the widget that owns the timer
+	Label string `json:"label,omitempty"`
+	// Duration is the countdown length in seconds, 0 for stopwatches
+	Duration  float64   `json:"duration,omitempty"`
+	Elapsed   float64   `json:"elapsed"`
+	Remaining float64   `json:"remaining,omitempty"`
+	Running   bool      `json:"running"`
+	Finished  bool      `json:"finished"`
+	ResumedAt time.Time `json:"resumedAt,omitempty"`
+	// EndsAt is when a running countdown finishes
+	EndsAt time.Time `json:"endsAt,omitempty"`
+}
+
+type Event struct {
+	Type  string    `json:"type"`
+	Name  string    `json:"name"`
+	Label string    `json:"label,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+type State struct {
+	Timers []Timer `json:"timers"`
+	// Events are the timers that finished with this change
+	Events []Event `json:"events,omitempty"`
+}
+
+type timer struct {
+	kind     string
+	label    string
+	duration time.Duration
+	// elapsed is counted up to resumedAt, which is zero while paused
+	elapsed   time.Duration
+	resumedAt time.Time
+	finished  bool
+	// alarm fires when a running countdown reaches its duration; generation
+	// tells a stale alarm from the current one
+	alarm      *time.Timer
+	generation uint64
+}
+
+type Manager struct {
+	timers      map[string]*timer
+	timersMutex sync.Mutex
+	now         func() time.Time
+
+	state      *State
+	stateMutex sync.RWMutex
+
+	subscribers  map[string]chan State
+	subMutex     sync.RWMutex
+	dirty        chan struct{}
+	stopChan     chan struct{}
+	wg           sync.WaitGroup
+	lastNotified *State
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.state == nil {
+		return State{}
+	}
+	stateCopy := *m.state
+	stateCopy.Timers = append([]Timer(nil), m.state.Timers...)
+	stateCopy.Events = append([]Event(nil), m.state.Events...)
+	return stateCopy
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func stateChanged(old, new *State) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	return !reflect.DeepEqual(old, new)
+}
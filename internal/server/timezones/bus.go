@@ -0,0 +1,168 @@
+package timezones
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	timedateDest  = "org.freedesktop.timedate1"
+	timedatePath  = "/org/freedesktop/timedate1"
+	timedateIface = "org.freedesktop.timedate1"
+	localtimePath = "/etc/localtime"
+
+	timesyncDest  = "org.freedesktop.timesync1"
+	timesyncPath  = "/org/freedesktop/timesync1"
+	timesyncIface = "org.freedesktop.timesync1.Manager"
+	timesyncUnit  = "systemd-timesyncd.service"
+)
+
+type dbusTimedate struct {
+	conn    *dbus.Conn
+	signals chan *dbus.Signal
+	changes chan struct{}
+	stop    chan struct{}
+}
+
+func newDBusTimedate() (*dbusTimedate, error) {
+	conn, err := dbus.ConnectSystemBus(budget.DBusOptions("timezones")...)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range []dbus.ObjectPath{timedatePath, timesyncPath} {
+		if err := conn.AddMatchSignal(
+			dbus.WithMatchObjectPath(path),
+			dbus.WithMatchInterface("org.freedesktop.DBus.Properties"),
+			dbus.WithMatchMember("PropertiesChanged"),
+		); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	t := &dbusTimedate{
+		conn:    conn,
+		signals: make(chan *dbus.Signal, 16),
+		changes: make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+	}
+	conn.Signal(t.signals)
+
+	go t.pump()
+	return t, nil
+}
+
+func (t *dbusTimedate) pump() {
+	for {
+		select {
+		case <-t.stop:
+			return
+		case sig, ok := <-t.signals:
+			if !ok {
+				return
+			}
+			if sig == nil {
+				continue
+			}
+			select {
+			case t.changes <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// Timezone asks timedated, which D-Bus starts on demand, and falls back to
+// the /etc/localtime link
+func (t *dbusTimedate) Timezone() (string, error) {
+	v, err := t.conn.Object(timedateDest, timedatePath).GetProperty(timedateIface + ".Timezone")
+	if err == nil {
+		if tz, ok := v.Value().(string); ok && tz != "" {
+			return tz, nil
+		}
+	}
+
+	target, err := os.Readlink(localtimePath)
+	if err != nil {
+		return "", err
+	}
+	if _, tz, ok := strings.Cut(target, "zoneinfo/"); ok {
+		return tz, nil
+	}
+	return "UTC", nil
+}
+
+func (t *dbusTimedate) SetTimezone(timezone string) error {
+	return t.conn.Object(timedateDest, timedatePath).Call(timedateIface+".SetTimezone", 0, timezone, true).Err
+}
+
+func (t *dbusTimedate) Sync() (Sync, error) {
+	var sync Sync
+	timedate := t.conn.Object(timedateDest, timedatePath)
+	for name, dst := range map[string]*bool{"NTP": &sync.NTP, "CanNTP": &sync.CanNTP, "NTPSynchronized": &sync.Synchronized} {
+		v, err := timedate.GetProperty(timedateIface + "." + name)
+		if err != nil {
+			return sync, err
+		}
+		*dst, _ = v.Value().(bool)
+	}
+
+	// timesyncd only runs with NTP on, and other NTP daemons don't report
+	// their exchanges
+	timesync := t.conn.Object(timesyncDest, timesyncPath)
+	if v, err := timesync.GetProperty(timesyncIface + ".ServerName"); err == nil {
+		sync.Server, _ = v.Value().(string)
+	}
+	if v, err := timesync.GetProperty(timesyncIface + ".NTPMessage"); err == nil {
+		if fields, ok := v.Value().([]interface{}); ok {
+			sync.Offset, sync.LastSync = ntpOffset(fields)
+		}
+	}
+	return sync, nil
+}
+
+// ntpOffset reads timesyncd's NTPMessage, (uuuuittayttttbtt), whose origin,
+// receive, transmit and destination timestamps are in microseconds
+func ntpOffset(fields []interface{}) (float64, time.Time) {
+	if len(fields) < 12 {
+		return 0, time.Time{}
+	}
+	var ts [4]int64
+	for i := range ts {
+		v, ok := fields[8+i].(uint64)
+		if !ok {
+			return 0, time.Time{}
+		}
+		ts[i] = int64(v)
+	}
+	origin, receive, transmit, dest := ts[0], ts[1], ts[2], ts[3]
+	if dest == 0 {
+		return 0, time.Time{}
+	}
+	offset := float64((receive-origin)+(transmit-dest)) / 2 / 1000
+	return offset, time.UnixMicro(dest)
+}
+
+func (t *dbusTimedate) SyncNow() error {
+	var owned bool
+	if err := t.conn.BusObject().Call("org.freedesktop.DBus.NameHasOwner", 0, timesyncDest).Store(&owned); err != nil || !owned {
+		return fmt.Errorf("syncing needs systemd-timesyncd running")
+	}
+	return t.conn.Object("org.freedesktop.systemd1", "/org/freedesktop/systemd1").Call(
+		"org.freedesktop.systemd1.Manager.RestartUnit", dbus.FlagAllowInteractiveAuthorization, timesyncUnit, "replace").Err
+}
+
+func (t *dbusTimedate) Changes() <-chan struct{} {
+	return t.changes
+}
+
+func (t *dbusTimedate) Close() {
+	close(t.stop)
+	t.conn.RemoveSignal(t.signals)
+	t.conn.Close()
+}
@@ -0,0 +1,111 @@
+package timezones
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "timezones manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "timezones.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "timezones.setCities":
+		cities, err := parseCities(req.Params["cities"])
+		if err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		if err := manager.SetCities(cities); err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		models.Respond(conn, req.ID, manager.GetState())
+	case "timezones.setTimezone":
+		timezone, ok := req.Params["timezone"].(string)
+		if !ok {
+			models.RespondError(conn, req.ID, "missing or invalid 'timezone' parameter")
+			return
+		}
+		if err := manager.SetTimezone(timezone); err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		models.Respond(conn, req.ID, SuccessResult{Success: true, Message: fmt.Sprintf("timezone set to %s", timezone)})
+	case "timezones.syncNow":
+		if err := manager.SyncNow(); err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "clock sync started"})
+	case "timezones.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+// parseCities takes timezone names or {name, timezone} objects
+func parseCities(raw interface{}) ([]City, error) {
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("missing or invalid 'cities' parameter")
+	}
+
+	cities := make([]City, 0, len(list))
+	for _, item := range list {
+		switch v := item.(type) {
+		case string:
+			cities = append(cities, City{Timezone: v})
+		case map[string]interface{}:
+			var city City
+			city.Name, _ = v["name"].(string)
+			city.Timezone, _ = v["timezone"].(string)
+			cities = append(cities, city)
+		default:
+			return nil, fmt.Errorf("invalid city: %v", item)
+		}
+	}
+	return cities, nil
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
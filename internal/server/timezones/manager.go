@@ -0,0 +1,265 @@
+package timezones
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+const (
+	maxCities = 32
+	// maxWait bounds the wait for the next change, since timers don't count
+	// time spent suspended
+	maxWait = time.Hour
+	// syncInterval polls the sync status, as timedated doesn't signal when
+	// NTPSynchronized changes
+	syncInterval = 5 * time.Minute
+	// skewThreshold is far enough off to matter, e.g. for TLS certificates
+	// and one-time codes
+	skewThreshold = 30 * time.Second
+)
+
+func NewManager() (*Manager, error) {
+	timedate, err := newDBusTimedate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to system bus: %w", err)
+	}
+
+	m := newManager(timedate)
+	m.refresh()
+
+	m.wg.Add(2)
+	go m.loop()
+	go m.notifier()
+	return m, nil
+}
+
+func newManager(timedate Timedate) *Manager {
+	return &Manager{
+		timedate:    timedate,
+		now:         time.Now,
+		cities:      []City{},
+		state:       &State{Cities: []Zone{}},
+		reschedule:  make(chan struct{}, 1),
+		subscribers: make(map[string]chan State),
+		dirty:       make(chan struct{}, 1),
+		stopChan:    make(chan struct{}),
+	}
+}
+
+func (m *Manager) loop() {
+	defer m.wg.Done()
+	timer := time.NewTimer(m.untilNextChange())
+	defer timer.Stop()
+	syncTicker := time.NewTicker(syncInterval)
+	defer syncTicker.Stop()
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.timedate.Changes():
+			m.refresh()
+		case <-timer.C:
+			m.refresh()
+		case <-syncTicker.C:
+			m.refresh()
+		case <-m.reschedule:
+		}
+		timer.Reset(m.untilNextChange())
+	}
+}
+
+func (m *Manager) untilNextChange() time.Duration {
+	m.stateMutex.RLock()
+	next := m.nextChange
+	m.stateMutex.RUnlock()
+
+	if next.IsZero() {
+		return maxWait
+	}
+	// Lands just past the change so the new offset is in effect
+	wait := next.Sub(m.now()) + time.Second
+	return min(max(wait, time.Second), maxWait)
+}
+
+func (m *Manager) refresh() {
+	now := m.now()
+
+	localName, err := m.timedate.Timezone()
+	if err != nil {
+		log.Warnf("timezones: failed to read the system timezone: %v", err)
+		localName = "UTC"
+	}
+	loc, err := time.LoadLocation(localName)
+	if err != nil {
+		log.Warnf("timezones: unknown system timezone %s: %v", localName, err)
+		loc = time.UTC
+	}
+
+	m.citiesMutex.RLock()
+	cities := append([]City(nil), m.cities...)
+	m.citiesMutex.RUnlock()
+
+	state := &State{Local: zoneAt(cityName(localName), localName, loc, now), Cities: make([]Zone, 0, len(cities))}
+	next := state.Local.NextChange
+	for _, city := range cities {
+		loc, err := time.LoadLocation(city.Timezone)
+		if err != nil {
+			continue
+		}
+		zone := zoneAt(city.Name, city.Timezone, loc, now)
+		state.Cities = append(state.Cities, zone)
+		if !zone.NextChange.IsZero() && (next.IsZero() || zone.NextChange.Before(next)) {
+			next = zone.NextChange
+		}
+	}
+
+	sync, err := m.timedate.Sync()
+	if err != nil {
+		log.Debugf("timezones: failed to read the sync status: %v", err)
+	}
+	sync.Skewed = time.Duration(math.Abs(sync.Offset))*time.Millisecond >= skewThreshold
+	state.Sync = sync
+
+	m.stateMutex.Lock()
+	wasSkewed := m.state.Sync.Skewed
+	m.state = state
+	m.nextChange = next
+	m.stateMutex.Unlock()
+
+	if sync.Skewed && !wasSkewed {
+		log.Warnf("timezones: the clock was off by %.1fs at the last NTP sync", sync.Offset/1000)
+	}
+
+	m.notifySubscribers()
+}
+
+func zoneAt(name, timezone string, loc *time.Location, now time.Time) Zone {
+	t := now.In(loc)
+	abbreviation, offset := t.Zone()
+	zone := Zone{
+		Name:         name,
+		Timezone:     timezone,
+		Abbreviation: abbreviation,
+		Offset:       offset,
+		DST:          t.IsDST(),
+	}
+	if _, end := t.ZoneBounds(); !end.IsZero() {
+		zone.NextChange = end
+		_, zone.NextOffset = end.In(loc).Zone()
+	}
+	return zone
+}
+
+// cityName turns America/New_York into New York
+func cityName(timezone string) string {
+	name := timezone[strings.LastIndex(timezone, "/")+1:]
+	return strings.ReplaceAll(name, "_", " ")
+}
+
+// SetCities replaces the world clock list; a city without a name is named
+// after its timezone
+func (m *Manager) SetCities(cities []City) error {
+	if len(cities) > maxCities {
+		return fmt.Errorf("too many cities (at most %d)", maxCities)
+	}
+	checked := make([]City, 0, len(cities))
+	for _, city := range cities {
+		if _, err := time.LoadLocation(city.Timezone); err != nil || city.Timezone == "" {
+			return fmt.Errorf("unknown timezone %q", city.Timezone)
+		}
+		if city.Name == "" {
+			city.Name = cityName(city.Timezone)
+		}
+		checked = append(checked, city)
+	}
+
+	m.citiesMutex.Lock()
+	m.cities = checked
+	m.citiesMutex.Unlock()
+
+	m.refresh()
+	m.rescheduleLoop()
+	return nil
+}
+
+func (m *Manager) GetCities() []City {
+	m.citiesMutex.RLock()
+	defer m.citiesMutex.RUnlock()
+	return append([]City(nil), m.cities...)
+}
+
+// SetTimezone changes the system timezone. polkit asks for authorization
+// unless the user may change it without
+func (m *Manager) SetTimezone(timezone string) error {
+	if _, err := time.LoadLocation(timezone); err != nil || timezone == "" || timezone == "Local" {
+		return fmt.Errorf("unknown timezone %q", timezone)
+	}
+	if err := m.timedate.SetTimezone(timezone); err != nil {
+		return fmt.Errorf("failed to set timezone: %w", err)
+	}
+
+	m.refresh()
+	m.rescheduleLoop()
+	return nil
+}
+
+// SyncNow syncs the clock with NTP right away
+func (m *Manager) SyncNow() error {
+	if err := m.timedate.SyncNow(); err != nil {
+		return fmt.Errorf("failed to sync the clock: %w", err)
+	}
+	m.refresh()
+	return nil
+}
+
+func (m *Manager) rescheduleLoop() {
+	select {
+	case m.reschedule <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) notifier() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.dirty:
+			state := m.GetState()
+
+			m.subMutex.RLock()
+			if m.lastNotified != nil && !stateChanged(m.lastNotified, &state) {
+				m.subMutex.RUnlock()
+				continue
+			}
+			for _, ch := range m.subscribers {
+				select {
+				case ch <- state:
+				default:
+				}
+			}
+			m.subMutex.RUnlock()
+
+			m.lastNotified = &state
+		}
+	}
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	m.wg.Wait()
+	m.timedate.Close()
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
@@ -0,0 +1,140 @@
+package timezones
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTimedate struct {
+	timezone string
+	sync     Sync
+	synced   int
+	changes  chan struct{}
+}
+
+func (t *fakeTimedate) Timezone() (string, error) { return t.timezone, nil }
+
+func (t *fakeTimedate) SetTimezone(timezone string) error {
+	t.timezone = timezone
+	return nil
+}
+
+func (t *fakeTimedate) Sync() (Sync, error) { return t.sync, nil }
+
+func (t *fakeTimedate) SyncNow() error {
+	t.synced++
+	return nil
+}
+
+func (t *fakeTimedate) Changes() <-chan struct{} { return t.changes }
+func (t *fakeTimedate) Close()                   {}
+
+func newTestManager(now time.Time) (*Manager, *fakeTimedate) {
+	timedate := &fakeTimedate{timezone: "Europe/Berlin", changes: make(chan struct{})}
+	m := newManager(timedate)
+	m.now = func() time.Time { return now }
+	m.refresh()
+	return m, timedate
+}
+
+func TestZones(t *testing.T) {
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	m, _ := newTestManager(now)
+
+	require.NoError(t, m.SetCities([]City{{Timezone: "America/New_York"}, {Name: "Home", Timezone: "Asia/Kolkata"}}))
+	state := m.GetState()
+
+	assert.Equal(t, "Berlin", state.Local.Name)
+	assert.Equal(t, "CET", state.Local.Abbreviation)
+	assert.Equal(t, 3600, state.Local.Offset)
+	assert.Equal(t, time.Date(2025, 3, 30, 1, 0, 0, 0, time.UTC), state.Local.NextChange.UTC())
+	assert.Equal(t, 7200, state.Local.NextOffset)
+
+	require.Len(t, state.Cities, 2)
+	ny := state.Cities[0]
+	assert.Equal(t, "New York", ny.Name)
+	assert.Equal(t, -5*3600, ny.Offset)
+	assert.False(t, ny.DST)
+	assert.Equal(t, time.Date(2025, 3, 9, 7, 0, 0, 0, time.UTC), ny.NextChange.UTC())
+	assert.Equal(t, -4*3600, ny.NextOffset)
+
+	kolkata := state.Cities[1]
+	assert.Equal(t, "Home", kolkata.Name)
+	assert.Equal(t, 5*3600+1800, kolkata.Offset)
+	assert.True(t, kolkata.NextChange.IsZero(), "India has no DST")
+
+	// New York changes first
+	assert.Equal(t, ny.NextChange, m.nextChange)
+}
+
+func TestUntilNextChange(t *testing.T) {
+	now := time.Date(2025, 3, 30, 0, 59, 0, 0, time.UTC)
+	m, _ := newTestManager(now)
+	assert.Equal(t, time.Minute+time.Second, m.untilNextChange())
+
+	now = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	m, _ = newTestManager(now)
+	assert.Equal(t, maxWait, m.untilNextChange())
+}
+
+func TestSetCitiesValidation(t *testing.T) {
+	m, _ := newTestManager(time.Now())
+
+	assert.Error(t, m.SetCities([]City{{Timezone: "Mars/Olympus_Mons"}}))
+	assert.Error(t, m.SetCities([]City{{Name: "Nowhere"}}))
+	assert.Empty(t, m.GetCities())
+}
+
+func TestSetTimezone(t *testing.T) {
+	m, timedate := newTestManager(time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC))
+
+	require.NoError(t, m.SetTimezone("Asia/Tokyo"))
+	assert.Equal(t, "Asia/Tokyo", timedate.timezone)
+	assert.Equal(t, "JST", m.GetState().Local.Abbreviation)
+
+	assert.Error(t, m.SetTimezone("Nope/Nowhere"))
+	assert.Error(t, m.SetTimezone("Local"))
+	assert.Equal(t, "Asia/Tokyo", timedate.timezone)
+}
+
+func TestParseCities(t *testing.T) {
+	cities, err := parseCities([]interface{}{"Europe/Paris", map[string]interface{}{"name": "Office", "timezone": "America/Chicago"}})
+	require.NoError(t, err)
+	assert.Equal(t, []City{{Timezone: "Europe/Paris"}, {Name: "Office", Timezone: "America/Chicago"}}, cities)
+
+	_, err = parseCities("Europe/Paris")
+	assert.Error(t, err)
+}
+
+func TestSyncSkew(t *testing.T) {
+	m, timedate := newTestManager(time.Now())
+	assert.False(t, m.GetState().Sync.Skewed)
+
+	timedate.sync = Sync{NTP: true, Synchronized: true, Offset: -45000}
+	require.NoError(t, m.SyncNow())
+	assert.Equal(t, 1, timedate.synced)
+	assert.True(t, m.GetState().Sync.Skewed)
+
+	timedate.sync.Offset = 12
+	m.refresh()
+	assert.False(t, m.GetState().Sync.Skewed)
+}
+
+func TestNTPOffset(t *testing.T) {
+	message := []interface{}{
+		uint32(0), uint32(4), uint32(4), uint32(2), int32(-20), uint64(0), uint64(0), []byte("GPS"),
+		// origin, receive, transmit, destination
+		uint64(1_000_000_000), uint64(1_002_000_500), uint64(1_002_000_600), uint64(1_000_001_000),
+		false, uint64(3), uint64(0),
+	}
+	offset, last := ntpOffset(message)
+	assert.InDelta(t, 2000.05, offset, 0.001)
+	assert.Equal(t, time.UnixMicro(1_000_001_000), last)
+
+	offset, last = ntpOffset([]interface{}{uint32(0)})
+	assert.Zero(t, offset)
+	assert.True(t, last.IsZero())
+}
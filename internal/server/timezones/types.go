@@ -0,0 +1,123 @@
+package timezones
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// City is a clock the user added, named after a city in its timezone
+type City struct {
+	Name     string `json:"name"`
+	Timezone string `json:"timezone"`
+}
+
+type Zone struct {
+	Name         string `json:"name"`
+	Timezone     string `json:"timezone"`
+	Abbreviation string `json:"abbreviation"`
+	// Offset is in seconds east of UTC
+	Offset int  `json:"offset"`
+	DST    bool `json:"dst"`
+	// NextChange is when the offset or abbreviation changes next, e.g. for
+	// DST; zero when the zone has no more changes
+	NextChange time.Time `json:"nextChange,omitempty"`
+	NextOffset int       `json:"nextOffset,omitempty"`
+}
+
+// Sync is the clock's network time sync, from timedated and timesyncd
+type Sync struct {
+	// NTP is whether network time sync is turned on
+	NTP          bool   `json:"ntp"`
+	CanNTP       bool   `json:"canNtp"`
+	Synchronized bool   `json:"synchronized"`
+	Server       string `json:"server,omitempty"`
+	// Offset is how far the clock was off at the last NTP exchange, in
+	// milliseconds; positive when it was behind
+	Offset   float64   `json:"offset"`
+	LastSync time.Time `json:"lastSync,omitempty"`
+	// Skewed is set while the last exchange found the clock off by more than
+	// skewThreshold
+	Skewed bool `json:"skewed"`
+}
+
+type State struct {
+	Local  Zone   `json:"local"`
+	Cities []Zone `json:"cities"`
+	Sync   Sync   `json:"sync"`
+}
+
+// Timedate is the system timezone, through systemd-timedated
+type Timedate interface {
+	Timezone() (string, error)
+	// SetTimezone changes it, asking polkit to authorize the user
+	SetTimezone(timezone string) error
+	Sync() (Sync, error)
+	// SyncNow restarts timesyncd, which syncs right away
+	SyncNow() error
+	Changes() <-chan struct{}
+	Close()
+}
+
+type Manager struct {
+	timedate Timedate
+	now      func() time.Time
+
+	cities      []City
+	citiesMutex sync.RWMutex
+
+	state      *State
+	stateMutex sync.RWMutex
+	// nextChange is the earliest NextChange in the state
+	nextChange time.Time
+	reschedule chan struct{}
+
+	subscribers  map[string]chan State
+	subMutex     sync.RWMutex
+	dirty        chan struct{}
+	stopChan     chan struct{}
+	wg           sync.WaitGroup
+	lastNotified *State
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.state == nil {
+		return State{}
+	}
+	stateCopy := *m.state
+	stateCopy.Cities = append([]Zone(nil), m.state.Cities...)
+	return stateCopy
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func stateChanged(old, new *State) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	return !reflect.DeepEqual(old, new)
+}
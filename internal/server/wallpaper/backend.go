@@ -0,0 +1,94 @@
+package wallpaper
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+func runCommand(name string, args ...string) error {
+	if output, err := exec.Command(name, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %s", name, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// DetectBackend prefers swww, which supports transitions, and falls back to
+// hyprpaper on Hyprland
+func DetectBackend() (Backend, error) {
+	for _, backend := range []Backend{&swwwBackend{}, &hyprpaperBackend{}} {
+		if backend.Available() {
+			return backend, nil
+		}
+	}
+	return nil, fmt.Errorf("no wallpaper backend found (install swww, or run hyprpaper on Hyprland)")
+}
+
+type swwwBackend struct{}
+
+func (b *swwwBackend) Name() string { return "swww" }
+
+func (b *swwwBackend) Available() bool {
+	_, err := exec.LookPath("swww")
+	return err == nil
+}
+
+func (b *swwwBackend) Set(output string, wallpaper Output, transition Transition) error {
+	// swww-daemon may not be running yet; query fails until it is
+	if exec.Command("swww", "query").Run() != nil {
+		if err := exec.Command("swww-daemon").Start(); err != nil {
+			return fmt.Errorf("failed to start swww-daemon: %w", err)
+		}
+	}
+
+	resize := map[Fill]string{FillCover: "crop", FillFit: "fit", FillCenter: "no"}[wallpaper.Fill]
+	args := []string{"img", "--resize", resize,
+		"--transition-type", transition.Type,
+		"--transition-duration", strconv.FormatFloat(transition.Duration, 'f', -1, 64),
+	}
+	if output != AllOutputs {
+		args = append(args, "--outputs", output)
+	}
+	args = append(args, wallpaper.Path)
+	return runCommand("swww", args...)
+}
+
+type hyprpaperBackend struct{}
+
+func (b *hyprpaperBackend) Name() string { return "hyprpaper" }
+
+func (b *hyprpaperBackend) Available() bool {
+	if os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") == "" {
+		return false
+	}
+	_, err := exec.LookPath("hyprpaper")
+	return err == nil
+}
+
+func (b *hyprpaperBackend) Set(output string, wallpaper Output, transition Transition) error {
+	if exec.Command("pgrep", "-x", "hyprpaper").Run() != nil {
+		if err := exec.Command("hyprpaper").Start(); err != nil {
+			return fmt.Errorf("failed to start hyprpaper: %w", err)
+		}
+	}
+
+	if err := runCommand("hyprctl", "hyprpaper", "preload", wallpaper.Path); err != nil {
+		return err
+	}
+
+	target := wallpaper.Path
+	if wallpaper.Fill == FillFit || wallpaper.Fill == FillCenter {
+		target = "contain:" + target
+	}
+	monitor := output
+	if output == AllOutputs {
+		monitor = ""
+	}
+	if err := runCommand("hyprctl", "hyprpaper", "wallpaper", monitor+","+target); err != nil {
+		return err
+	}
+	// Drop images no output shows any more
+	return runCommand("hyprctl", "hyprpaper", "unload", "unused")
+}
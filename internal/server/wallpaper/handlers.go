@@ -0,0 +1,140 @@
+package wallpaper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/server/models"
+)
+
+type Request struct {
+	ID     int                    `json:"id,omitempty"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type SuccessResult struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+func HandleRequest(conn net.Conn, req Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "wallpaper manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "wallpaper.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "wallpaper.set":
+		handleSet(conn, req, manager)
+	case "wallpaper.setTransition":
+		handleSetTransition(conn, req, manager)
+	case "wallpaper.startSlideshow":
+		handleStartSlideshow(conn, req, manager)
+	case "wallpaper.stopSlideshow":
+		manager.StopSlideshow()
+		models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "slideshow stopped"})
+	case "wallpaper.next":
+		if err := manager.Next(); err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "next wallpaper"})
+	case "wallpaper.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleSet(conn net.Conn, req Request, manager *Manager) {
+	path, ok := req.Params["path"].(string)
+	if !ok || path == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'path' parameter")
+		return
+	}
+	output, _ := req.Params["output"].(string)
+	fillParam, _ := req.Params["fill"].(string)
+	fill, err := ParseFill(fillParam)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := manager.Set(output, path, fill); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "wallpaper set"})
+}
+
+func handleSetTransition(conn net.Conn, req Request, manager *Manager) {
+	transition := manager.GetState().Transition
+	if value, ok := req.Params["type"].(string); ok {
+		transition.Type = value
+	}
+	if value, ok := req.Params["duration"].(float64); ok {
+		transition.Duration = value
+	}
+
+	if err := manager.SetTransition(transition); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "transition set"})
+}
+
+func handleStartSlideshow(conn net.Conn, req Request, manager *Manager) {
+	dir, ok := req.Params["dir"].(string)
+	if !ok || dir == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'dir' parameter")
+		return
+	}
+	interval := 10 * time.Minute
+	if value, ok := req.Params["interval"].(float64); ok {
+		interval = time.Duration(value * float64(time.Second))
+	}
+	shuffle, _ := req.Params["shuffle"].(bool)
+
+	var outputs []string
+	if values, ok := req.Params["outputs"].([]interface{}); ok {
+		for _, value := range values {
+			if output, ok := value.(string); ok {
+				outputs = append(outputs, output)
+			}
+		}
+	}
+
+	if err := manager.StartSlideshow(dir, interval, shuffle, outputs); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "slideshow started"})
+}
+
+func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			ID:     req.ID,
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package wallpaper
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+var imageExtensions = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".webp": true, ".gif": true, ".bmp": true,
+}
+
+func defaultStatePath() string {
+	return filepath.Join(os.Getenv("HOME"), ".local", "state", "DankMaterialShell", "wallpaper.json")
+}
+
+func NewManager() (*Manager, error) {
+	backend, err := DetectBackend()
+	if err != nil {
+		return nil, err
+	}
+
+	m := newManager(backend, defaultStatePath())
+	m.load()
+	m.restore()
+
+	m.wg.Add(2)
+	go m.notifier()
+	go m.slideshowLoop()
+	return m, nil
+}
+
+func newManager(backend Backend, statePath string) *Manager {
+	return &Manager{
+		backend:   backend,
+		statePath: statePath,
+		state: &State{
+			Backend:    backend.Name(),
+			Outputs:    make(map[string]Output),
+			Transition: DefaultTransition(),
+		},
+		slideshowReset: make(chan struct{}, 1),
+		images:         listImages,
+		shuffle:        rand.Intn,
+		subscribers:    make(map[string]chan State),
+		dirty:          make(chan struct{}, 1),
+		stopChan:       make(chan struct{}),
+	}
+}
+
+func (m *Manager) load() {
+	data, err := os.ReadFile(m.statePath)
+	if err != nil {
+		return
+	}
+
+	var saved State
+	if err := json.Unmarshal(data, &saved); err != nil {
+		log.Warnf("wallpaper: ignoring invalid state file %s: %v", m.statePath, err)
+		return
+	}
+
+	m.stateMutex.Lock()
+	if saved.Outputs != nil {
+		m.state.Outputs = saved.Outputs
+	}
+	if saved.Transition.Validate() == nil {
+		m.state.Transition = saved.Transition
+	}
+	m.state.Slideshow = saved.Slideshow
+	m.stateMutex.Unlock()
+}
+
+func (m *Manager) save() {
+	state := m.GetState()
+	state.NextChange = nil
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(m.statePath), 0755); err != nil {
+		log.Warnf("wallpaper: failed to save state: %v", err)
+		return
+	}
+	if err := utils.WriteFileAtomic(m.statePath, data, 0644); err != nil {
+		log.Warnf("wallpaper: failed to save state: %v", err)
+	}
+}
+
+// restore re-applies the persisted wallpapers, all-outputs first so
+// per-output images end up on top
+func (m *Manager) restore() {
+	state := m.GetState()
+	names := make([]string, 0, len(state.Outputs))
+	for name := range state.Outputs {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == AllOutputs || names[j] == AllOutputs {
+			return names[i] == AllOutputs
+		}
+		return names[i] < names[j]
+	})
+
+	noTransition := Transition{Type: "none"}
+	for _, name := range names {
+		if err := m.backend.Set(name, state.Outputs[name], noTransition); err != nil {
+			log.Warnf("wallpaper: failed to restore %s: %v", name, err)
+		}
+	}
+}
+
+// Set shows path on output ("" or "*" for all outputs). Setting all outputs
+// replaces any per-output wallpapers
+func (m *Manager) Set(output, path string, fill Fill) error {
+	if output == "" {
+		output = AllOutputs
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("wallpaper not found: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory (use slideshow for directories)", path)
+	}
+
+	wallpaper := Output{Path: path, Fill: fill}
+	if err := m.backend.Set(output, wallpaper, m.GetState().Transition); err != nil {
+		return err
+	}
+
+	m.stateMutex.Lock()
+	if output == AllOutputs {
+		m.state.Outputs = make(map[string]Output)
+	}
+	m.state.Outputs[output] = wallpaper
+	m.stateMutex.Unlock()
+
+	m.save()
+	m.notifySubscribers()
+	return nil
+}
+
+func (m *Manager) SetTransition(transition Transition) error {
+	if err := transition.Validate(); err != nil {
+		return err
+	}
+
+	m.stateMutex.Lock()
+	m.state.Transition = transition
+	m.stateMutex.Unlock()
+
+	m.save()
+	m.notifySubscribers()
+	return nil
+}
+
+// StartSlideshow cycles through the images in dir every interval, on the
+// given outputs or all of them
+func (m *Manager) StartSlideshow(dir string, interval time.Duration, shuffle bool, outputs []string) error {
+	if interval < 10*time.Second {
+		return fmt.Errorf("slideshow interval must be at least 10s")
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	images, err := m.images(dir)
+	if err != nil {
+		return err
+	}
+	if len(images) == 0 {
+		return fmt.Errorf("no images found in %s", dir)
+	}
+
+	m.stateMutex.Lock()
+	m.state.Slideshow = &Slideshow{
+		Dir:      dir,
+		Interval: int(interval.Seconds()),
+		Shuffle:  shuffle,
+		Outputs:  outputs,
+		Index:    -1,
+	}
+	m.stateMutex.Unlock()
+
+	if err := m.Next(); err != nil {
+		return err
+	}
+	m.resetSlideshowTimer()
+	return nil
+}
+
+func (m *Manager) StopSlideshow() {
+	m.stateMutex.Lock()
+	m.state.Slideshow = nil
+	m.state.NextChange = nil
+	m.stateMutex.Unlock()
+
+	m.save()
+	m.resetSlideshowTimer()
+	m.notifySubscribers()
+}
+
+// Next advances the slideshow immediately
+func (m *Manager) Next() error {
+	state := m.GetState()
+	slideshow := state.Slideshow
+	if slideshow == nil {
+		return fmt.Errorf("no slideshow running")
+	}
+
+	images, err := m.images(slideshow.Dir)
+	if err != nil {
+		return err
+	}
+	if len(images) == 0 {
+		return fmt.Errorf("no images found in %s", slideshow.Dir)
+	}
+
+	index := (slideshow.Index + 1) % len(images)
+	switch {
+	case slideshow.Shuffle && slideshow.Index < 0:
+		index = m.shuffle(len(images))
+	case slideshow.Shuffle && len(images) > 1:
+		// Never repeat the current image
+		index = m.shuffle(len(images) - 1)
+		if index >= slideshow.Index {
+			index++
+		}
+	}
+
+	outputs := slideshow.Outputs
+	if len(outputs) == 0 {
+		outputs = []string{AllOutputs}
+	}
+	fill := FillCover
+	if current, ok := state.Outputs[outputs[0]]; ok {
+		fill = current.Fill
+	}
+	for _, output := range outputs {
+		if err := m.Set(output, images[index], fill); err != nil {
+			return err
+		}
+	}
+
+	m.stateMutex.Lock()
+	if m.state.Slideshow != nil {
+		m.state.Slideshow.Index = index
+	}
+	m.stateMutex.Unlock()
+
+	m.save()
+	m.notifySubscribers()
+	return nil
+}
+
+func (m *Manager) resetSlideshowTimer() {
+	select {
+	case m.slideshowReset <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) slideshowLoop() {
+	defer m.wg.Done()
+
+	for {
+		var timer <-chan time.Time
+		if slideshow := m.GetState().Slideshow; slideshow != nil {
+			interval := time.Duration(slideshow.Interval) * time.Second
+			next := time.Now().Add(interval)
+
+			m.stateMutex.Lock()
+			m.state.NextChange = &next
+			m.stateMutex.Unlock()
+			m.notifySubscribers()
+
+			timer = time.After(interval)
+		}
+
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.slideshowReset:
+		case <-timer:
+			if err := m.Next(); err != nil {
+				log.Warnf("wallpaper: slideshow failed: %v", err)
+			}
+		}
+	}
+}
+
+func listImages(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []string
+	for _, entry := range entries {
+		if entry.IsDir() || !imageExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+			continue
+		}
+		images = append(images, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(images)
+	return images, nil
+}
+
+func (m *Manager) notifier() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-m.dirty:
+			state := m.GetState()
+
+			m.subMutex.RLock()
+			if m.lastNotified != nil && !stateChanged(m.lastNotified, &state) {
+				m.subMutex.RUnlock()
+				continue
+			}
+			for _, ch := range m.subscribers {
+				select {
+				case ch <- state:
+				default:
+				}
+			}
+			m.subMutex.RUnlock()
+
+			m.lastNotified = &state
+		}
+	}
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	m.wg.Wait()
+
+	m.subMutex.Lock()
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = make(map[string]chan State)
+	m.subMutex.Unlock()
+}
@@ -0,0 +1,94 @@
+package wallpaper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBackend struct {
+	calls []string
+}
+
+func (b *fakeBackend) Name() string { return "fake" }
+
+func (b *fakeBackend) Available() bool { return true }
+
+func (b *fakeBackend) Set(output string, wallpaper Output, transition Transition) error {
+	b.calls = append(b.calls, output+"="+filepath.Base(wallpaper.Path)+":"+string(wallpaper.Fill)+":"+transition.Type)
+	return nil
+}
+
+func writeImages(t *testing.T, dir string, names ...string) {
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("img"), 0644))
+	}
+}
+
+func TestSetAndPersist(t *testing.T) {
+	dir := t.TempDir()
+	writeImages(t, dir, "a.png", "b.jpg")
+	statePath := filepath.Join(dir, "state", "wallpaper.json")
+
+	backend := &fakeBackend{}
+	m := newManager(backend, statePath)
+	require.NoError(t, m.Set("", filepath.Join(dir, "a.png"), FillCover))
+	require.NoError(t, m.Set("DP-1", filepath.Join(dir, "b.jpg"), FillFit))
+	assert.Equal(t, []string{"*=a.png:cover:fade", "DP-1=b.jpg:fit:fade"}, backend.calls)
+
+	assert.Error(t, m.Set("", filepath.Join(dir, "missing.png"), FillCover))
+	assert.Error(t, m.Set("", dir, FillCover))
+
+	restored := &fakeBackend{}
+	m2 := newManager(restored, statePath)
+	m2.load()
+	m2.restore()
+	assert.Equal(t, []string{"*=a.png:cover:none", "DP-1=b.jpg:fit:none"}, restored.calls)
+
+	require.NoError(t, m2.Set("*", filepath.Join(dir, "b.jpg"), FillCenter))
+	assert.Len(t, m2.GetState().Outputs, 1, "setting all outputs replaces per-output wallpapers")
+}
+
+func TestSlideshow(t *testing.T) {
+	dir := t.TempDir()
+	writeImages(t, dir, "1.png", "2.png", "3.webp", "notes.txt")
+
+	backend := &fakeBackend{}
+	m := newManager(backend, filepath.Join(dir, "wallpaper.json"))
+
+	assert.Error(t, m.StartSlideshow(dir, time.Second, false, nil))
+	require.NoError(t, m.StartSlideshow(dir, time.Minute, false, []string{"DP-1"}))
+	require.NoError(t, m.Next())
+	require.NoError(t, m.Next())
+	require.NoError(t, m.Next())
+	assert.Equal(t, []string{
+		"DP-1=1.png:cover:fade", "DP-1=2.png:cover:fade", "DP-1=3.webp:cover:fade", "DP-1=1.png:cover:fade",
+	}, backend.calls)
+
+	m.shuffle = func(n int) int { return 0 }
+	m.stateMutex.Lock()
+	m.state.Slideshow.Shuffle = true
+	m.stateMutex.Unlock()
+	require.NoError(t, m.Next())
+	assert.Equal(t, "DP-1=2.png:cover:fade", backend.calls[4], "shuffle skips the current image")
+
+	m.StopSlideshow()
+	assert.Nil(t, m.GetState().Slideshow)
+	assert.Error(t, m.Next())
+}
+
+func TestTransitionValidate(t *testing.T) {
+	assert.NoError(t, Transition{Type: "wipe", Duration: 2}.Validate())
+	assert.Error(t, Transition{Type: "explode", Duration: 1}.Validate())
+	assert.Error(t, Transition{Type: "fade", Duration: -1}.Validate())
+
+	fill, err := ParseFill("")
+	require.NoError(t, err)
+	assert.Equal(t, FillCover, fill)
+	_, err = ParseFill("stretch")
+	assert.Error(t, err)
+}
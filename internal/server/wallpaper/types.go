@@ -0,0 +1,174 @@
+package wallpaper
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type Fill string
+
+const (
+	FillCover  Fill = "cover"
+	FillFit    Fill = "fit"
+	FillCenter Fill = "center"
+)
+
+type Transition struct {
+	Type     string  `json:"type"`
+	Duration float64 `json:"duration"`
+}
+
+// Output is the wallpaper shown on one output; the "*" output applies to all
+type Output struct {
+	Path string `json:"path"`
+	Fill Fill   `json:"fill"`
+}
+
+type Slideshow struct {
+	Dir      string   `json:"dir"`
+	Interval int      `json:"interval"`
+	Shuffle  bool     `json:"shuffle"`
+	Outputs  []string `json:"outputs,omitempty"`
+	Index    int      `json:"index"`
+}
+
+// State is also what gets persisted, so wallpapers and slideshows survive restarts
+type State struct {
+	Backend    string            `json:"backend"`
+	Outputs    map[string]Output `json:"outputs"`
+	Transition Transition        `json:"transition"`
+	Slideshow  *Slideshow        `json:"slideshow,omitempty"`
+	NextChange *time.Time        `json:"nextChange,omitempty"`
+}
+
+// Backend draws the wallpaper, either through a daemon's IPC or a surface of our own
+type Backend interface {
+	Name() string
+	Available() bool
+	Set(output string, wallpaper Output, transition Transition) error
+}
+
+type Manager struct {
+	backend   Backend
+	statePath string
+
+	state      *State
+	stateMutex sync.RWMutex
+
+	slideshowReset chan struct{}
+	images         func(dir string) ([]string, error)
+	shuffle        func(n int) int
+
+	subscribers  map[string]chan State
+	subMutex     sync.RWMutex
+	dirty        chan struct{}
+	stopChan     chan struct{}
+	wg           sync.WaitGroup
+	lastNotified *State
+}
+
+const AllOutputs = "*"
+
+var Transitions = []string{"none", "simple", "fade", "left", "right", "top", "bottom", "wipe", "wave", "grow", "center", "outer", "random"}
+
+func DefaultTransition() Transition {
+	return Transition{Type: "fade", Duration: 1}
+}
+
+func ParseFill(value string) (Fill, error) {
+	switch Fill(value) {
+	case "", FillCover:
+		return FillCover, nil
+	case FillFit, FillCenter:
+		return Fill(value), nil
+	}
+	return "", fmt.Errorf("invalid fill mode %q (use cover, fit or center)", value)
+}
+
+func (t Transition) Validate() error {
+	if t.Duration < 0 || t.Duration > 30 {
+		return fmt.Errorf("transition duration must be between 0 and 30 seconds")
+	}
+	for _, name := range Transitions {
+		if t.Type == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid transition %q", t.Type)
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.state == nil {
+		return State{}
+	}
+	return copyState(m.state)
+}
+
+func copyState(s *State) State {
+	stateCopy := *s
+	stateCopy.Outputs = make(map[string]Output, len(s.Outputs))
+	for name, output := range s.Outputs {
+		stateCopy.Outputs[name] = output
+	}
+	if s.Slideshow != nil {
+		slideshow := *s.Slideshow
+		slideshow.Outputs = append([]string(nil), s.Slideshow.Outputs...)
+		stateCopy.Slideshow = &slideshow
+	}
+	if s.NextChange != nil {
+		next := *s.NextChange
+		stateCopy.NextChange = &next
+	}
+	return stateCopy
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subMutex.Lock()
+	m.subscribers[id] = ch
+	m.subMutex.Unlock()
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	m.subMutex.Lock()
+	if ch, ok := m.subscribers[id]; ok {
+		close(ch)
+		delete(m.subscribers, id)
+	}
+	m.subMutex.Unlock()
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func stateChanged(old, new *State) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	if old.Transition != new.Transition || len(old.Outputs) != len(new.Outputs) {
+		return true
+	}
+	for name, output := range old.Outputs {
+		if new.Outputs[name] != output {
+			return true
+		}
+	}
+	if (old.Slideshow == nil) != (new.Slideshow == nil) {
+		return true
+	}
+	if old.Slideshow != nil && (old.Slideshow.Dir != new.Slideshow.Dir ||
+		old.Slideshow.Interval != new.Slideshow.Interval ||
+		old.Slideshow.Shuffle != new.Slideshow.Shuffle ||
+		old.Slideshow.Index != new.Slideshow.Index) {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package wayland
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+)
+
+// BenchRampSizes are the gamma sizes compositors report: 256 for most
+// laptop panels, 1024 and 4096 for external monitors with deeper LUTs
+var BenchRampSizes = []uint32{256, 1024, 4096}
+
+// BenchResult is the average cost of one ramp size: generating it, packing
+// it for wlr-gamma-control and writing it to the memfd sent to the
+// compositor. The compositor's side is only in the server's ApplyLatency
+type BenchResult struct {
+	RampSize uint32        `json:"rampSize"`
+	Generate time.Duration `json:"generate"`
+	Pack     time.Duration `json:"pack"`
+	Write    time.Duration `json:"write"`
+}
+
+func (r BenchResult) Total() time.Duration {
+	return r.Generate + r.Pack + r.Write
+}
+
+// BenchRamps times each step of an apply for every size, sweeping the
+// temperature like a transition does
+func BenchRamps(sizes []uint32, iterations int, gamma float64) ([]BenchResult, error) {
+	if iterations < 1 {
+		return nil, fmt.Errorf("iterations must be at least 1")
+	}
+
+	results := make([]BenchResult, 0, len(sizes))
+	for _, size := range sizes {
+		if size < 2 {
+			return nil, fmt.Errorf("invalid ramp size %d", size)
+		}
+
+		result := BenchResult{RampSize: size}
+		for i := 0; i < iterations; i++ {
+			temp := 1000 + (i*100)%9000
+
+			start := time.Now()
+			ramp := GenerateGammaRamp(size, temp, gamma)
+			generated := time.Now()
+			data := PackGammaRamp(ramp)
+			packed := time.Now()
+			fd, err := rampMemfd(data)
+			if err != nil {
+				return nil, err
+			}
+			syscall.Close(fd)
+
+			result.Generate += generated.Sub(start)
+			result.Pack += packed.Sub(generated)
+			result.Write += time.Since(packed)
+		}
+
+		n := time.Duration(iterations)
+		result.Generate /= n
+		result.Pack /= n
+		result.Write /= n
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -1,7 +1,13 @@
 package wayland
 
 import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
 	"math"
+	"os"
+	"syscall"
 
 	"github.com/AvengeMedia/danklinux/internal/utils"
 )
@@ -34,6 +40,56 @@ func GenerateGammaRamp(size uint32, temp int, gamma float64) GammaRamp {
 	return ramp
 }
 
+// PackGammaRamp lays a ramp out as wlr-gamma-control expects it: all red
+// values, then green, then blue, little-endian
+func PackGammaRamp(ramp GammaRamp) []byte {
+	buf := bytes.NewBuffer(make([]byte, 0, len(ramp.Red)*6))
+	for _, v := range ramp.Red {
+		_ = binary.Write(buf, binary.LittleEndian, v)
+	}
+	for _, v := range ramp.Green {
+		_ = binary.Write(buf, binary.LittleEndian, v)
+	}
+	for _, v := range ramp.Blue {
+		_ = binary.Write(buf, binary.LittleEndian, v)
+	}
+	return buf.Bytes()
+}
+
+// rampMemfd writes a packed ramp to a memfd rewound for the compositor to
+// read. The caller closes it
+func rampMemfd(data []byte) (int, error) {
+	fd, err := MemfdCreate("gamma-ramp", 0)
+	if err != nil {
+		return -1, fmt.Errorf("memfd_create: %w", err)
+	}
+
+	if err := syscall.Ftruncate(fd, int64(len(data))); err != nil {
+		syscall.Close(fd)
+		return -1, fmt.Errorf("ftruncate: %w", err)
+	}
+
+	dupFd, err := syscall.Dup(fd)
+	if err != nil {
+		syscall.Close(fd)
+		return -1, fmt.Errorf("dup: %w", err)
+	}
+	f := os.NewFile(uintptr(dupFd), "gamma")
+	defer f.Close()
+
+	n, err := f.Write(data)
+	if err != nil || n != len(data) {
+		syscall.Close(fd)
+		return -1, fmt.Errorf("write gamma: %w (n=%d want=%d)", err, n, len(data))
+	}
+
+	if _, err := syscall.Seek(fd, 0, 0); err != nil {
+		syscall.Close(fd)
+		return -1, fmt.Errorf("seek: %w", err)
+	}
+	return fd, nil
+}
+
 func GenerateIdentityRamp(size uint32) GammaRamp {
 	ramp := GammaRamp{
 		Red:   make([]uint16, size),
@@ -86,3 +142,51 @@ func temperatureToRGB(temp int) (float64, float64, float64) {
 
 	return r, g, b
 }
+
+// rampFd writes a packed ramp to the output's memfd and rewinds it for the
+// compositor to read. The compositor's copy of the descriptor shares the
+// offset, so a read of the previous ramp leaves it at the end. Until then a
+// set_gamma may still be queued on that memfd, and rewriting it would hand
+// the compositor an offset at EOF or a half-written ramp, so the ramp goes
+// to a fresh memfd instead. Compositors that pread or mmap never move the
+// offset and get a fresh memfd every time
+func (out *outputState) rampFd(data []byte) (int, error) {
+	if out.rampFile != nil && !out.rampConsumed() {
+		out.rampFile.Close()
+		out.rampFile = nil
+	}
+	if out.rampFile == nil {
+		fd, err := MemfdCreate("gamma-ramp", 0)
+		if err != nil {
+			return -1, fmt.Errorf("memfd_create: %w", err)
+		}
+		out.rampFile = os.NewFile(uintptr(fd), "gamma")
+	}
+
+	if err := out.rampFile.Truncate(int64(len(data))); err != nil {
+		return -1, fmt.Errorf("ftruncate: %w", err)
+	}
+	if n, err := out.rampFile.WriteAt(data, 0); err != nil || n != len(data) {
+		return -1, fmt.Errorf("write gamma: %w (n=%d want=%d)", err, n, len(data))
+	}
+	if _, err := out.rampFile.Seek(0, io.SeekStart); err != nil {
+		return -1, fmt.Errorf("seek: %w", err)
+	}
+	out.rampLen = int64(len(data))
+	return int(out.rampFile.Fd()), nil
+}
+
+// rampConsumed reports whether the last ramp written to the memfd has been
+// read to the end
+func (out *outputState) rampConsumed() bool {
+	offset, err := out.rampFile.Seek(0, io.SeekCurrent)
+	return err == nil && offset == out.rampLen
+}
+
+func (out *outputState) closeRampFile() {
+	if out.rampFile != nil {
+		out.rampFile.Close()
+		out.rampFile = nil
+	}
+	out.appliedControl = nil
+}
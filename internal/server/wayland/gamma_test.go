@@ -118,3 +118,48 @@ func TestClamp(t *testing.T) {
 		}
 	}
 }
+
+func TestPackGammaRamp(t *testing.T) {
+	ramp := GammaRamp{
+		Red:   []uint16{0x0102, 0xffff},
+		Green: []uint16{0x0304, 0},
+		Blue:  []uint16{0x0506, 1},
+	}
+	want := []byte{0x02, 0x01, 0xff, 0xff, 0x04, 0x03, 0, 0, 0x06, 0x05, 1, 0}
+
+	got := PackGammaRamp(ramp)
+	if string(got) != string(want) {
+		t.Errorf("PackGammaRamp = %v, want %v", got, want)
+	}
+}
+
+func TestBenchRamps(t *testing.T) {
+	results, err := BenchRamps([]uint32{16, 256}, 3, 1.0)
+	if err != nil {
+		t.Fatalf("BenchRamps: %v", err)
+	}
+	if len(results) != 2 || results[0].RampSize != 16 || results[1].RampSize != 256 {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if results[1].Total() <= 0 {
+		t.Errorf("expected a measured duration, got %v", results[1].Total())
+	}
+
+	if _, err := BenchRamps([]uint32{1}, 1, 1.0); err == nil {
+		t.Error("expected an error for a ramp size of 1")
+	}
+}
+
+func BenchmarkGenerateGammaRamp(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		GenerateGammaRamp(1024, 4500, 1.0)
+	}
+}
+
+func BenchmarkPackGammaRamp(b *testing.B) {
+	ramp := GenerateGammaRamp(1024, 4500, 1.0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		PackGammaRamp(ramp)
+	}
+}
@@ -0,0 +1,114 @@
+package wayland
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	geoclueDest              = "org.freedesktop.GeoClue2"
+	geoclueManagerPath       = "/org/freedesktop/GeoClue2/Manager"
+	geoclueManagerInterface  = "org.freedesktop.GeoClue2.Manager"
+	geoclueClientInterface   = "org.freedesktop.GeoClue2.Client"
+	geoclueLocationInterface = "org.freedesktop.GeoClue2.Location"
+
+	// geoclueDesktopID is what Geoclue's agent or geoclue.conf authorizes
+	geoclueDesktopID = "dms"
+	// City level is plenty for sun times and doesn't need WiFi or GPS
+	geoclueAccuracyCity = uint32(4)
+	geoclueTimeout      = 15 * time.Second
+	// geoclueRetryAfter keeps a Geoclue that refuses or times out from
+	// stalling every schedule calculation
+	geoclueRetryAfter = 10 * time.Minute
+)
+
+// Location sources reported in State.LocationSource
+const (
+	LocationSourceGeoclue     = "geoclue"
+	LocationSourceIP          = "ip"
+	LocationSourceCoordinates = "coordinates"
+	LocationSourceManual      = "manual"
+)
+
+type location struct {
+	lat, lon float64
+	// accuracy is the radius in meters, 0 when the source doesn't say
+	accuracy float64
+	source   string
+}
+
+// fetchGeoclueLocation asks Geoclue for a city level fix, waiting for the
+// first LocationUpdated. Geoclue only answers once its agent or
+// /etc/geoclue/geoclue.conf allows the dms desktop ID
+func fetchGeoclueLocation() (*location, error) {
+	conn, err := dbus.ConnectSystemBus(budget.DBusOptions("gamma")...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to system bus: %w", err)
+	}
+	defer conn.Close()
+
+	manager := conn.Object(geoclueDest, geoclueManagerPath)
+	var clientPath dbus.ObjectPath
+	if err := manager.Call(geoclueManagerInterface+".CreateClient", 0).Store(&clientPath); err != nil {
+		return nil, fmt.Errorf("geoclue unavailable: %w", err)
+	}
+	defer manager.Call(geoclueManagerInterface+".DeleteClient", 0, clientPath)
+
+	client := conn.Object(geoclueDest, clientPath)
+	if err := client.SetProperty(geoclueClientInterface+".DesktopId", dbus.MakeVariant(geoclueDesktopID)); err != nil {
+		return nil, fmt.Errorf("failed to set geoclue desktop id: %w", err)
+	}
+	if err := client.SetProperty(geoclueClientInterface+".RequestedAccuracyLevel", dbus.MakeVariant(geoclueAccuracyCity)); err != nil {
+		return nil, fmt.Errorf("failed to set geoclue accuracy: %w", err)
+	}
+
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(clientPath),
+		dbus.WithMatchInterface(geoclueClientInterface),
+		dbus.WithMatchMember("LocationUpdated"),
+	); err != nil {
+		return nil, fmt.Errorf("failed to watch geoclue: %w", err)
+	}
+	signals := make(chan *dbus.Signal, 4)
+	conn.Signal(signals)
+
+	if err := client.Call(geoclueClientInterface+".Start", 0).Err; err != nil {
+		return nil, fmt.Errorf("geoclue refused to locate: %w", err)
+	}
+	defer client.Call(geoclueClientInterface+".Stop", 0)
+
+	timeout := time.After(geoclueTimeout)
+	for {
+		select {
+		case sig := <-signals:
+			if sig == nil || sig.Name != geoclueClientInterface+".LocationUpdated" || len(sig.Body) < 2 {
+				continue
+			}
+			path, ok := sig.Body[1].(dbus.ObjectPath)
+			if !ok {
+				continue
+			}
+			return readGeoclueLocation(conn.Object(geoclueDest, path))
+		case <-timeout:
+			return nil, fmt.Errorf("geoclue gave no location within %v", geoclueTimeout)
+		}
+	}
+}
+
+func readGeoclueLocation(obj dbus.BusObject) (*location, error) {
+	loc := &location{source: LocationSourceGeoclue}
+	for name, dst := range map[string]*float64{"Latitude": &loc.lat, "Longitude": &loc.lon, "Accuracy": &loc.accuracy} {
+		variant, err := obj.GetProperty(geoclueLocationInterface + "." + name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read geoclue %s: %w", name, err)
+		}
+		*dst, _ = variant.Value().(float64)
+	}
+
+	log.Infof("Fetched Geoclue location (%.4f, %.4f), accurate to %.0fm", loc.lat, loc.lon, loc.accuracy)
+	return loc, nil
+}
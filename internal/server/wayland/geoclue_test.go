@@ -0,0 +1,59 @@
+package wayland
+
+import (
+	"errors"
+	"testing"
+)
+
+func stubLocators(t *testing.T, geoclue func() (*location, error), ip func() (*float64, *float64, error)) {
+	origGeoclue, origIP := locateGeoclue, locateIP
+	locateGeoclue, locateIP = geoclue, ip
+	t.Cleanup(func() { locateGeoclue, locateIP = origGeoclue, origIP })
+}
+
+func TestLocatePrefersGeoclue(t *testing.T) {
+	stubLocators(t,
+		func() (*location, error) {
+			return &location{lat: 52.52, lon: 13.40, accuracy: 5000, source: LocationSourceGeoclue}, nil
+		},
+		func() (*float64, *float64, error) {
+			t.Error("IP lookup used although Geoclue answered")
+			return nil, nil, errors.New("unexpected")
+		})
+
+	m := &Manager{}
+	lat, lon, err := m.getAutoLocation()
+	if err != nil || *lat != 52.52 || *lon != 13.40 {
+		t.Fatalf("getAutoLocation() = %v, %v, %v", lat, lon, err)
+	}
+	if m.cachedLocation.source != LocationSourceGeoclue || m.cachedLocation.accuracy != 5000 {
+		t.Errorf("expected the Geoclue fix to be cached, got %+v", m.cachedLocation)
+	}
+}
+
+func TestLocateFallsBackToIP(t *testing.T) {
+	geoclueCalls := 0
+	stubLocators(t,
+		func() (*location, error) {
+			geoclueCalls++
+			return nil, errors.New("not authorized")
+		},
+		func() (*float64, *float64, error) {
+			lat, lon := 48.85, 2.35
+			return &lat, &lon, nil
+		})
+
+	m := &Manager{}
+	loc, err := m.locate()
+	if err != nil || loc.source != LocationSourceIP || loc.lat != 48.85 {
+		t.Fatalf("locate() = %+v, %v", loc, err)
+	}
+
+	// A refusal isn't retried right away
+	if _, err := m.locate(); err != nil {
+		t.Fatal(err)
+	}
+	if geoclueCalls != 1 {
+		t.Errorf("expected Geoclue to be asked once, asked %d times", geoclueCalls)
+	}
+}
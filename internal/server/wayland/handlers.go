@@ -41,6 +41,18 @@ func HandleRequest(conn net.Conn, req Request, manager *Manager) {
 		handleSetGamma(conn, req, manager)
 	case "wayland.gamma.setEnabled":
 		handleSetEnabled(conn, req, manager)
+	case "wayland.gamma.setTransition":
+		handleSetTransition(conn, req, manager)
+	case "wayland.gamma.setTemporaryTemperature":
+		handleSetTemporaryTemperature(conn, req, manager)
+	case "wayland.gamma.pauseUntilSunrise":
+		handlePauseUntilSunrise(conn, req, manager)
+	case "wayland.gamma.resumeSchedule":
+		handleResumeSchedule(conn, req, manager)
+	case "wayland.gamma.setOutputTemperature":
+		handleSetOutputTemperature(conn, req, manager)
+	case "wayland.gamma.setOutputEnabled":
+		handleSetOutputEnabled(conn, req, manager)
 	case "wayland.gamma.subscribe":
 		handleSubscribe(conn, req, manager)
 	default:
@@ -182,6 +194,93 @@ func handleSetEnabled(conn net.Conn, req Request, manager *Manager) {
 	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "enabled state set"})
 }
 
+func handleSetTransition(conn net.Conn, req Request, manager *Manager) {
+	minutes, ok := req.Params["minutes"].(float64)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'minutes' parameter")
+		return
+	}
+
+	if err := manager.SetTransition(time.Duration(minutes * float64(time.Minute))); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "transition set"})
+}
+
+func handleSetTemporaryTemperature(conn net.Conn, req Request, manager *Manager) {
+	temp, ok := req.Params["temp"].(float64)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'temp' parameter")
+		return
+	}
+	minutes, _ := req.Params["minutes"].(float64)
+	if minutes == 0 {
+		minutes = 60
+	}
+
+	if err := manager.SetTemporaryTemperature(int(temp), time.Duration(minutes*float64(time.Minute))); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "temporary temperature set"})
+}
+
+func handlePauseUntilSunrise(conn net.Conn, req Request, manager *Manager) {
+	if err := manager.PauseUntilSunrise(); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "paused until sunrise"})
+}
+
+func handleResumeSchedule(conn net.Conn, req Request, manager *Manager) {
+	manager.ResumeSchedule()
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "schedule resumed"})
+}
+
+func handleSetOutputTemperature(conn net.Conn, req Request, manager *Manager) {
+	output, ok := req.Params["output"].(string)
+	if !ok || output == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'output' parameter")
+		return
+	}
+
+	// No temp hands the output back to the schedule
+	temp, _ := req.Params["temp"].(float64)
+
+	if err := manager.SetOutputTemperature(output, int(temp)); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "output temperature set"})
+}
+
+func handleSetOutputEnabled(conn net.Conn, req Request, manager *Manager) {
+	output, ok := req.Params["output"].(string)
+	if !ok || output == "" {
+		models.RespondError(conn, req.ID, "missing or invalid 'output' parameter")
+		return
+	}
+
+	enabled, ok := req.Params["enabled"].(bool)
+	if !ok {
+		models.RespondError(conn, req.ID, "missing or invalid 'enabled' parameter")
+		return
+	}
+
+	if err := manager.SetOutputEnabled(output, enabled); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, SuccessResult{Success: true, Message: "output enabled state set"})
+}
+
 func handleSubscribe(conn net.Conn, req Request, manager *Manager) {
 	clientID := fmt.Sprintf("client-%p", conn)
 	stateChan := manager.Subscribe(clientID)
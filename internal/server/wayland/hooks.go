@@ -0,0 +1,70 @@
+package wayland
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/AvengeMedia/danklinux/internal/log"
+	"github.com/AvengeMedia/danklinux/internal/sandbox"
+)
+
+// Periods use redshift's names so hooks written for redshift/gammastep work unchanged
+const (
+	PeriodNone       = "none"
+	PeriodDaytime    = "daytime"
+	PeriodNight      = "night"
+	PeriodTransition = "transition"
+)
+
+// hookPolicy lets hooks reach the session, e.g. to send a notification or
+// switch a theme, within the sandbox's resource limits
+var hookPolicy = sandbox.Policy{
+	Network: true,
+	Timeout: 10,
+	Env:     []string{"XDG_RUNTIME_DIR", "DBUS_SESSION_BUS_ADDRESS", "WAYLAND_DISPLAY"},
+}
+
+func gammaHooksDir() string {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		configDir = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(configDir, "dms", "gamma-hooks.d")
+}
+
+func periodFor(enabled bool, current, target, low, high int) string {
+	switch {
+	case !enabled:
+		return PeriodNone
+	case current != target:
+		return PeriodTransition
+	case current == high && current != low:
+		return PeriodDaytime
+	case current == low:
+		return PeriodNight
+	}
+	return PeriodTransition
+}
+
+// runPeriodHooks executes every executable in dir as
+// "<hook> period-changed <old> <new>", like redshift does
+func runPeriodHooks(dir, oldPeriod, newPeriod string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() || info.Mode()&0111 == 0 {
+			continue
+		}
+
+		if _, stderr, err := sandbox.Run(hookPolicy, dir, path, "period-changed", oldPeriod, newPeriod); err != nil {
+			log.Warnf("Gamma hook %s failed: %v: %s", entry.Name(), err, stderr)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package wayland
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriodFor(t *testing.T) {
+	assert.Equal(t, PeriodNone, periodFor(false, 4000, 4000, 4000, 6500))
+	assert.Equal(t, PeriodDaytime, periodFor(true, 6500, 6500, 4000, 6500))
+	assert.Equal(t, PeriodNight, periodFor(true, 4000, 4000, 4000, 6500))
+	assert.Equal(t, PeriodTransition, periodFor(true, 5000, 4000, 4000, 6500))
+	assert.Equal(t, PeriodNight, periodFor(true, 5000, 5000, 5000, 5000))
+}
+
+func TestRunPeriodHooks(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	out := filepath.Join(t.TempDir(), "calls")
+
+	hook := "#!/bin/sh\necho \"$(basename \"$0\") $1 $2 $3\" >> " + out + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "10-first"), []byte(hook), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "20-second"), []byte(hook), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte(hook), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "subdir"), 0755))
+
+	runPeriodHooks(dir, PeriodDaytime, PeriodTransition)
+
+	calls, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "10-first period-changed daytime transition\n20-second period-changed daytime transition\n", string(calls))
+
+	runPeriodHooks(filepath.Join(dir, "missing"), PeriodNone, PeriodNight)
+}
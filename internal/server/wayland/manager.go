@@ -1,11 +1,9 @@
 package wayland
 
 import (
-	"bytes"
-	"encoding/binary"
 	"fmt"
-	"os"
-	"syscall"
+	"maps"
+	"sort"
 	"time"
 
 	"github.com/godbus/dbus/v5"
@@ -15,6 +13,7 @@ import (
 	"github.com/AvengeMedia/danklinux/internal/errdefs"
 	"github.com/AvengeMedia/danklinux/internal/log"
 	"github.com/AvengeMedia/danklinux/internal/proto/wlr_gamma_control"
+	"github.com/AvengeMedia/danklinux/internal/server/budget"
 )
 
 func NewManager(config Config) (*Manager, error) {
@@ -31,12 +30,15 @@ func NewManager(config Config) (*Manager, error) {
 		config:        config,
 		display:       display,
 		outputs:       make(map[uint32]*outputState),
+		outputNames:   make(map[uint32]*outputName),
 		cmdq:          make(chan cmd, 128),
 		stopChan:      make(chan struct{}),
 		updateTrigger: make(chan struct{}, 1),
 		subscribers:   make(map[string]chan State),
 		dirty:         make(chan struct{}, 1),
 		dbusSignal:    make(chan *dbus.Signal, 16),
+		hooksDir:      gammaHooksDir(),
+		ramps:         newRampCache(rampCacheSize),
 	}
 
 	if err := m.setupRegistry(); err != nil {
@@ -139,6 +141,9 @@ func (m *Manager) allOutputsReady() bool {
 		return false
 	}
 	for _, o := range m.outputs {
+		if o.released {
+			continue
+		}
 		if o.rampSize == 0 || o.failed {
 			return false
 		}
@@ -150,6 +155,9 @@ func (m *Manager) handleDisconnect(err error) {
 	log.Warnf("Wayland disconnected: %v, attempting reconnect...", err)
 	m.alive = false
 
+	for _, out := range m.outputs {
+		out.closeRampFile()
+	}
 	m.outputs = make(map[uint32]*outputState)
 	m.controlsInitialized = false
 
@@ -204,7 +212,7 @@ func (m *Manager) handleDisconnect(err error) {
 }
 
 func (m *Manager) setupDBusMonitor() error {
-	conn, err := dbus.ConnectSystemBus()
+	conn, err := dbus.ConnectSystemBus(budget.DBusOptions("gamma")...)
 	if err != nil {
 		return fmt.Errorf("failed to connect to system bus: %w", err)
 	}
@@ -273,7 +281,9 @@ func (m *Manager) setupRegistry() error {
 				if m.outputRegNames != nil {
 					m.outputRegNames[outputID] = e.Name
 				}
+				m.outputNames[outputID] = &outputName{}
 				m.outputsMutex.Unlock()
+				m.watchOutputName(output)
 
 				m.configMutex.RLock()
 				enabled := m.config.Enabled
@@ -307,6 +317,12 @@ func (m *Manager) setupRegistry() error {
 			m.outputsMutex.Lock()
 			defer m.outputsMutex.Unlock()
 
+			for id, regName := range m.outputRegNames {
+				if regName == e.Name {
+					delete(m.outputNames, id)
+				}
+			}
+
 			for id, out := range m.outputs {
 				if out.registryName == e.Name {
 					log.Infof("Output %d (registry name %d) removed, destroying gamma control", id, e.Name)
@@ -314,6 +330,7 @@ func (m *Manager) setupRegistry() error {
 						control := out.gammaControl.(*wlr_gamma_control.ZwlrGammaControlV1)
 						control.Destroy()
 					}
+					out.closeRampFile()
 					delete(m.outputs, id)
 
 					if len(m.outputs) == 0 {
@@ -354,6 +371,28 @@ func (m *Manager) setupRegistry() error {
 	return nil
 }
 
+// watchOutputName keeps the name and description an output announces, which
+// per-output overrides are keyed by
+func (m *Manager) watchOutputName(output *wlclient.Output) {
+	id := output.ID()
+	output.SetNameHandler(func(e wlclient.OutputNameEvent) {
+		m.outputsMutex.Lock()
+		if n, ok := m.outputNames[id]; ok {
+			n.name = e.Name
+		}
+		m.outputsMutex.Unlock()
+		m.post(m.updateState)
+	})
+	output.SetDescriptionHandler(func(e wlclient.OutputDescriptionEvent) {
+		m.outputsMutex.Lock()
+		if n, ok := m.outputNames[id]; ok {
+			n.description = e.Description
+		}
+		m.outputsMutex.Unlock()
+		m.post(m.updateState)
+	})
+}
+
 func (m *Manager) setupOutputControls(outputs []*wlclient.Output, manager *wlr_gamma_control.ZwlrGammaControlManagerV1, doRoundtrip bool) error {
 	log.Infof("setupOutputControls: creating gamma controls for %d outputs", len(outputs))
 
@@ -546,7 +585,7 @@ func (m *Manager) updateLoop() {
 			}
 
 			m.configMutex.RLock()
-			enabled := m.config.Enabled
+			enabled := m.config.Enabled && !m.paused
 			m.configMutex.RUnlock()
 			if enabled {
 				newTargetTemp := m.calculateTemperature(time.Now())
@@ -565,7 +604,7 @@ func (m *Manager) updateLoop() {
 			}
 			// Recompute once, then kick a single transition (if enabled)
 			m.configMutex.RLock()
-			enabled := m.config.Enabled
+			enabled := m.config.Enabled && !m.paused
 			m.configMutex.RUnlock()
 			if enabled {
 				newTargetTemp := m.calculateTemperature(time.Now())
@@ -600,8 +639,11 @@ func (m *Manager) startTransition(targetTemp int) {
 
 	go func(currentTemp, targetTemp int, mySerial int64) {
 		const dur = 1 * time.Second
-		const fps = 30
-		steps := int(dur.Seconds() * fps)
+		fps := 30
+		if budget.Throttled() {
+			fps = 10
+		}
+		steps := int(dur.Seconds() * float64(fps))
 
 		log.Debugf("Starting smooth transition: %dK -> %dK over %v", currentTemp, targetTemp, dur)
 
@@ -641,6 +683,7 @@ func (m *Manager) startTransition(targetTemp int) {
 						control.Destroy()
 						log.Debugf("Destroyed gamma control for output %d", id)
 					}
+					out.closeRampFile()
 				}
 				m.outputs = make(map[uint32]*outputState)
 				m.controlsInitialized = false
@@ -666,6 +709,10 @@ func (m *Manager) recreateOutputControl(out *outputState) error {
 		log.Debugf("Output %d no longer exists, skipping recreation", out.id)
 		return nil
 	}
+	if out.released {
+		log.Debugf("Output %d is disabled by an override, skipping recreation", out.id)
+		return nil
+	}
 
 	gammaMgr, ok := m.gammaControl.(*wlr_gamma_control.ZwlrGammaControlManagerV1)
 	if !ok || gammaMgr == nil {
@@ -727,7 +774,7 @@ func (m *Manager) applyGammaImmediate(temp int) {
 
 func (m *Manager) applyNowOnActor(temp int) {
 	m.configMutex.RLock()
-	gamma := m.config.Gamma
+	config := m.config
 	m.configMutex.RUnlock()
 
 	if !m.controlsInitialized {
@@ -737,8 +784,12 @@ func (m *Manager) applyNowOnActor(temp int) {
 	// Lock while snapshotting outputs to prevent races with recreateOutputControl
 	m.outputsMutex.RLock()
 	var outs []*outputState
-	for _, out := range m.outputs {
+	names := make(map[uint32]outputName, len(m.outputs))
+	for id, out := range m.outputs {
 		outs = append(outs, out)
+		if n, ok := m.outputNames[id]; ok {
+			names[id] = *n
+		}
 	}
 	m.outputsMutex.RUnlock()
 
@@ -746,38 +797,54 @@ func (m *Manager) applyNowOnActor(temp int) {
 		return
 	}
 
+	start := time.Now()
+
 	// Collect ready outputs & pack their buffers first (atomic apply)
 	type job struct {
 		out  *outputState
+		name string
+		key  rampKey
 		data []byte
 	}
 	var jobs []job
 
 	for _, out := range outs {
-		if out.failed || out.rampSize == 0 {
+		name := names[out.id]
+		override := config.outputOverride(name.name, name.description)
+		if override.Disabled {
+			if !out.released {
+				m.releaseOutputControl(out)
+			}
 			continue
 		}
-
-		ramp := GenerateGammaRamp(out.rampSize, temp, gamma)
-
-		// Pack once into []byte
-		buf := bytes.NewBuffer(make([]byte, 0, int(out.rampSize)*6))
-		for _, v := range ramp.Red {
-			_ = binary.Write(buf, binary.LittleEndian, v)
-		}
-		for _, v := range ramp.Green {
-			_ = binary.Write(buf, binary.LittleEndian, v)
+		if out.released {
+			m.reclaimOutputControl(out)
+			continue
 		}
-		for _, v := range ramp.Blue {
-			_ = binary.Write(buf, binary.LittleEndian, v)
+		if out.failed || out.rampSize == 0 {
+			continue
 		}
 
-		jobs = append(jobs, job{out: out, data: buf.Bytes()})
+		outTemp := outputTemperature(config.Enabled, override, temp)
+		key := rampKey{size: out.rampSize, temp: outTemp, gamma: config.Gamma}
+		if out.appliedControl == out.gammaControl && out.applied == key {
+			continue
+		}
+		jobs = append(jobs, job{out: out, name: name.name, key: key, data: m.ramps.packed(out.rampSize, outTemp, config.Gamma)})
 	}
+	packed := time.Since(start)
 
 	// Now send to all ready outputs in this tick
+	var outputLatency []OutputLatency
 	for _, j := range jobs {
-		if err := m.setGammaBytesActor(j.out, j.data); err != nil {
+		sendStart := time.Now()
+		err := m.setGammaBytesActor(j.out, j.key, j.data)
+		outputLatency = append(outputLatency, OutputLatency{
+			Output:   j.name,
+			RampSize: j.out.rampSize,
+			Ms:       durationMs(packed/time.Duration(len(jobs)) + time.Since(sendStart)),
+		})
+		if err != nil {
 			log.Warnf("Failed to set gamma for output %d: %v", j.out.id, err)
 			outID := j.out.id
 			m.outputsMutex.Lock()
@@ -801,6 +868,10 @@ func (m *Manager) applyNowOnActor(temp int) {
 		}
 	}
 
+	if len(jobs) > 0 {
+		m.recordApplyLatency(time.Since(start), outputLatency)
+	}
+
 	m.transitionMutex.Lock()
 	m.currentTemp = temp
 	m.transitionMutex.Unlock()
@@ -808,31 +879,68 @@ func (m *Manager) applyNowOnActor(temp int) {
 	m.updateState()
 }
 
-func (m *Manager) setGammaBytesActor(out *outputState, data []byte) error {
-	fd, err := MemfdCreate("gamma-ramp", 0)
-	if err != nil {
-		return fmt.Errorf("memfd_create: %w", err)
-	}
-	defer syscall.Close(fd)
+// releaseOutputControl drops the gamma control of an output disabled by an
+// override; the compositor then restores the output's own ramps
+func (m *Manager) releaseOutputControl(out *outputState) {
+	m.outputsMutex.Lock()
+	control, _ := out.gammaControl.(*wlr_gamma_control.ZwlrGammaControlV1)
+	out.gammaControl = nil
+	out.rampSize = 0
+	out.released = true
+	m.outputsMutex.Unlock()
 
-	if err := syscall.Ftruncate(fd, int64(len(data))); err != nil {
-		return fmt.Errorf("ftruncate: %w", err)
+	if control != nil {
+		control.Destroy()
 	}
+	log.Infof("Output %d disabled by override, released gamma control", out.id)
+}
 
-	dupFd, err := syscall.Dup(fd)
-	if err != nil {
-		return fmt.Errorf("dup: %w", err)
+// reclaimOutputControl takes an output back once its override no longer
+// disables it. The ramp is applied when its gamma_size arrives
+func (m *Manager) reclaimOutputControl(out *outputState) {
+	m.outputsMutex.Lock()
+	out.released = false
+	m.outputsMutex.Unlock()
+
+	if err := m.recreateOutputControl(out); err != nil {
+		log.Warnf("Failed to reclaim gamma control for output %d: %v", out.id, err)
 	}
-	f := os.NewFile(uintptr(dupFd), "gamma")
-	defer f.Close()
+}
 
-	n, err := f.Write(data)
-	if err != nil || n != len(data) {
-		return fmt.Errorf("write gamma: %w (n=%d want=%d)", err, n, len(data))
+// recordApplyLatency keeps the last, average and slowest apply for the
+// state, to tell compositor stalls from slow ramp generation
+func (m *Manager) recordApplyLatency(took time.Duration, outputs []OutputLatency) {
+	ms := durationMs(took)
+
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+	latency := &m.applyLatency
+	if latency.Count == 0 {
+		latency.AvgMs = ms
+	} else {
+		latency.AvgMs += (ms - latency.AvgMs) * applyLatencyWeight
 	}
+	latency.Count++
+	latency.LastMs = ms
+	if ms > latency.MaxMs {
+		latency.MaxMs = ms
+	}
+	latency.Outputs = outputs
+	latency.RampCacheHits, latency.RampCacheMisses = m.ramps.stats()
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
 
-	if _, err := syscall.Seek(fd, 0, 0); err != nil {
-		return fmt.Errorf("seek: %w", err)
+// setGammaBytesActor sends a ramp through the output's memfd and remembers
+// it as applied, so the next frame can skip an unchanged output
+func (m *Manager) setGammaBytesActor(out *outputState, key rampKey, data []byte) error {
+	out.appliedControl = nil
+	fd, err := out.rampFd(data)
+	if err != nil {
+		out.closeRampFile()
+		return err
 	}
 
 	ctrl := out.gammaControl.(*wlr_gamma_control.ZwlrGammaControlV1)
@@ -840,6 +948,7 @@ func (m *Manager) setGammaBytesActor(out *outputState, data []byte) error {
 		return fmt.Errorf("SetGamma: %w", err)
 	}
 
+	out.applied, out.appliedControl = key, out.gammaControl
 	return nil
 }
 
@@ -863,7 +972,7 @@ func (m *Manager) updateState() {
 			configCopy.ManualSunset.Minute(),
 			configCopy.ManualSunset.Second(), 0, loc)
 	} else if configCopy.UseIPLocation {
-		lat, lon, err := m.getIPLocation()
+		lat, lon, err := m.getAutoLocation()
 		if err == nil {
 			times := CalculateSunTimes(*lat, *lon, now)
 			sunrise = times.Sunrise
@@ -877,27 +986,88 @@ func (m *Manager) updateState() {
 
 	m.transitionMutex.RLock()
 	temp := m.currentTemp
+	target := m.targetTemp
 	m.transitionMutex.RUnlock()
 
 	nextTransition := m.calculateNextTransition(now)
 	isDay := now.After(sunrise) && now.Before(sunset)
 
+	var override *TemperatureOverride
+	if active, ok := m.activeOverride(now); ok {
+		override = &active
+	}
+
+	var locationSource string
+	var locationAccuracy float64
+	switch {
+	case configCopy.ManualSunrise != nil && configCopy.ManualSunset != nil:
+		locationSource = LocationSourceManual
+	case configCopy.UseIPLocation:
+		m.locationMutex.RLock()
+		if loc := m.cachedLocation; loc != nil {
+			locationSource, locationAccuracy = loc.source, loc.accuracy
+		}
+		m.locationMutex.RUnlock()
+	case configCopy.Latitude != nil && configCopy.Longitude != nil:
+		locationSource = LocationSourceCoordinates
+	}
+
+	m.configMutex.RLock()
+	paused := m.paused
+	m.configMutex.RUnlock()
+
 	newState := State{
-		Config:         configCopy,
-		CurrentTemp:    temp,
-		NextTransition: nextTransition,
-		SunriseTime:    sunrise,
-		SunsetTime:     sunset,
-		IsDay:          isDay,
+		Config:           configCopy,
+		Paused:           paused,
+		CurrentTemp:      temp,
+		NextTransition:   nextTransition,
+		SunriseTime:      sunrise,
+		SunsetTime:       sunset,
+		IsDay:            isDay,
+		Period:           periodFor(configCopy.Enabled, temp, target, configCopy.LowTemp, configCopy.HighTemp),
+		Override:         override,
+		LocationSource:   locationSource,
+		LocationAccuracy: locationAccuracy,
+		Outputs:          m.outputGamma(configCopy),
 	}
 
 	m.stateMutex.Lock()
+	newState.ApplyLatency = m.applyLatency
 	m.state = &newState
+	oldPeriod := m.period
+	m.period = newState.Period
 	m.stateMutex.Unlock()
 
+	if oldPeriod != "" && oldPeriod != newState.Period {
+		go func() {
+			// Serialised so hooks see period changes in order
+			m.hookMutex.Lock()
+			defer m.hookMutex.Unlock()
+			runPeriodHooks(m.hooksDir, oldPeriod, newState.Period)
+		}()
+	}
+
 	m.notifySubscribers()
 }
 
+func (m *Manager) outputGamma(config Config) []OutputGamma {
+	m.outputsMutex.RLock()
+	outputs := make([]OutputGamma, 0, len(m.outputNames))
+	for _, n := range m.outputNames {
+		override := config.outputOverride(n.name, n.description)
+		outputs = append(outputs, OutputGamma{
+			Name:        n.name,
+			Description: n.description,
+			Enabled:     !override.Disabled,
+			Temperature: override.Temperature,
+		})
+	}
+	m.outputsMutex.RUnlock()
+
+	sort.Slice(outputs, func(i, j int) bool { return outputs[i].Name < outputs[j].Name })
+	return outputs
+}
+
 func (m *Manager) notifier() {
 	defer m.notifierWg.Done()
 	const minGap = 100 * time.Millisecond
@@ -1053,34 +1223,63 @@ func (m *Manager) SetUseIPLocation(use bool) {
 
 	if use {
 		m.locationMutex.Lock()
-		m.cachedIPLat = nil
-		m.cachedIPLon = nil
+		m.cachedLocation = nil
+		m.geoclueFailedAt = time.Time{}
 		m.locationMutex.Unlock()
 	}
 
 	m.triggerUpdate()
 }
 
-func (m *Manager) getIPLocation() (*float64, *float64, error) {
+func (m *Manager) getAutoLocation() (*float64, *float64, error) {
 	m.locationMutex.RLock()
-	if m.cachedIPLat != nil && m.cachedIPLon != nil {
-		lat, lon := m.cachedIPLat, m.cachedIPLon
-		m.locationMutex.RUnlock()
-		return lat, lon, nil
-	}
+	cached := m.cachedLocation
 	m.locationMutex.RUnlock()
 
-	lat, lon, err := FetchIPLocation()
-	if err != nil {
-		return nil, nil, err
+	if cached == nil {
+		loc, err := m.locate()
+		if err != nil {
+			return nil, nil, err
+		}
+		m.locationMutex.Lock()
+		m.cachedLocation = loc
+		m.locationMutex.Unlock()
+		cached = loc
 	}
 
-	m.locationMutex.Lock()
-	m.cachedIPLat = lat
-	m.cachedIPLon = lon
-	m.locationMutex.Unlock()
+	lat, lon := cached.lat, cached.lon
+	return &lat, &lon, nil
+}
 
-	return lat, lon, nil
+// Swapped out in tests
+var (
+	locateGeoclue = fetchGeoclueLocation
+	locateIP      = FetchIPLocation
+)
+
+// locate prefers Geoclue, which doesn't hand the address to a web service,
+// and falls back to the IP lookup
+func (m *Manager) locate() (*location, error) {
+	m.locationMutex.RLock()
+	skipGeoclue := time.Since(m.geoclueFailedAt) < geoclueRetryAfter
+	m.locationMutex.RUnlock()
+
+	if !skipGeoclue {
+		loc, err := locateGeoclue()
+		if err == nil {
+			return loc, nil
+		}
+		log.Warnf("Geoclue location failed, falling back to IP lookup: %v", err)
+		m.locationMutex.Lock()
+		m.geoclueFailedAt = time.Now()
+		m.locationMutex.Unlock()
+	}
+
+	lat, lon, err := locateIP()
+	if err != nil {
+		return nil, err
+	}
+	return &location{lat: *lat, lon: *lon, source: LocationSourceIP}, nil
 }
 
 func (m *Manager) calculateTemperature(now time.Time) int {
@@ -1088,6 +1287,13 @@ func (m *Manager) calculateTemperature(now time.Time) int {
 	config := m.config
 	m.configMutex.RUnlock()
 
+	if override, ok := m.activeOverride(now); ok && config.Enabled {
+		return override.Temperature
+	}
+	return m.scheduledTemperature(config, now)
+}
+
+func (m *Manager) scheduledTemperature(config Config, now time.Time) int {
 	if !config.Enabled {
 		return config.HighTemp
 	}
@@ -1111,7 +1317,7 @@ func (m *Manager) calculateTemperature(now time.Time) int {
 			sunset = sunset.Add(24 * time.Hour)
 		}
 	} else if config.UseIPLocation {
-		lat, lon, err := m.getIPLocation()
+		lat, lon, err := m.getAutoLocation()
 		if err != nil {
 			return config.HighTemp
 		}
@@ -1126,10 +1332,7 @@ func (m *Manager) calculateTemperature(now time.Time) int {
 		return config.LowTemp
 	}
 
-	if now.Before(sunrise) || now.After(sunset) {
-		return config.LowTemp
-	}
-	return config.HighTemp
+	return scheduleTemperature(now, sunrise, sunset, config.Transition, config.LowTemp, config.HighTemp)
 }
 
 func (m *Manager) calculateNextTransition(now time.Time) time.Time {
@@ -1137,6 +1340,13 @@ func (m *Manager) calculateNextTransition(now time.Time) time.Time {
 	config := m.config
 	m.configMutex.RUnlock()
 
+	if override, ok := m.activeOverride(now); ok && config.Enabled {
+		return override.Until
+	}
+	return m.scheduledNextTransition(config, now)
+}
+
+func (m *Manager) scheduledNextTransition(config Config, now time.Time) time.Time {
 	if !config.Enabled {
 		return now.Add(24 * time.Hour)
 	}
@@ -1160,7 +1370,7 @@ func (m *Manager) calculateNextTransition(now time.Time) time.Time {
 			sunset = sunset.Add(24 * time.Hour)
 		}
 	} else if config.UseIPLocation {
-		lat, lon, err := m.getIPLocation()
+		lat, lon, err := m.getAutoLocation()
 		if err != nil {
 			return now.Add(24 * time.Hour)
 		}
@@ -1175,11 +1385,8 @@ func (m *Manager) calculateNextTransition(now time.Time) time.Time {
 		return now.Add(24 * time.Hour)
 	}
 
-	if now.Before(sunrise) {
-		return sunrise
-	}
-	if now.Before(sunset) {
-		return sunset
+	if next, ok := nextScheduleChange(now, sunrise, sunset, config.Transition); ok {
+		return next
 	}
 
 	if config.ManualSunrise != nil && config.ManualSunset != nil {
@@ -1189,21 +1396,21 @@ func (m *Manager) calculateNextTransition(now time.Time) time.Time {
 			config.ManualSunrise.Hour(),
 			config.ManualSunrise.Minute(),
 			config.ManualSunrise.Second(), 0, loc)
-		return nextSunrise
+		return nextSunrise.Add(-config.Transition)
 	}
 
 	if config.UseIPLocation {
-		lat, lon, err := m.getIPLocation()
+		lat, lon, err := m.getAutoLocation()
 		if err != nil {
 			return now.Add(24 * time.Hour)
 		}
 		nextDayTimes := CalculateSunTimes(*lat, *lon, now.Add(24*time.Hour))
-		return nextDayTimes.Sunrise
+		return nextDayTimes.Sunrise.Add(-config.Transition)
 	}
 
 	if config.Latitude != nil && config.Longitude != nil {
 		nextDayTimes := CalculateSunTimes(*config.Latitude, *config.Longitude, now.Add(24*time.Hour))
-		return nextDayTimes.Sunrise
+		return nextDayTimes.Sunrise.Add(-config.Transition)
 	}
 
 	return now.Add(24 * time.Hour)
@@ -1244,9 +1451,29 @@ func (m *Manager) SetGamma(gamma float64) error {
 	return nil
 }
 
+// SetTransition sets how long the fade around sunrise and sunset takes;
+// zero switches at once
+func (m *Manager) SetTransition(transition time.Duration) error {
+	m.configMutex.Lock()
+	config := m.config
+	config.Transition = transition
+	if err := config.Validate(); err != nil {
+		m.configMutex.Unlock()
+		return err
+	}
+	m.config = config
+	m.configMutex.Unlock()
+
+	m.triggerUpdate()
+	return nil
+}
+
 func (m *Manager) SetEnabled(enabled bool) {
 	m.configMutex.Lock()
 	m.config.Enabled = enabled
+	if !enabled {
+		m.override = nil
+	}
 	m.configMutex.Unlock()
 
 	if enabled {
@@ -1272,6 +1499,177 @@ func (m *Manager) SetEnabled(enabled bool) {
 	}
 }
 
+// SetOutputTemperature holds output, by name or description, at temp while
+// night light is on; 0 hands it back to the schedule
+func (m *Manager) SetOutputTemperature(output string, temp int) error {
+	return m.updateOutputOverride(output, func(o *OutputOverride) { o.Temperature = temp })
+}
+
+// SetOutputEnabled leaves output alone when disabled, with its own ramps
+func (m *Manager) SetOutputEnabled(output string, enabled bool) error {
+	return m.updateOutputOverride(output, func(o *OutputOverride) { o.Disabled = !enabled })
+}
+
+func (m *Manager) updateOutputOverride(output string, update func(*OutputOverride)) error {
+	if output == "" {
+		return fmt.Errorf("output name is required")
+	}
+
+	m.configMutex.Lock()
+	// Copied, since state snapshots share the map
+	overrides := maps.Clone(m.config.OutputOverrides)
+	if overrides == nil {
+		overrides = make(map[string]OutputOverride)
+	}
+	override := overrides[output]
+	update(&override)
+	if override == (OutputOverride{}) {
+		delete(overrides, output)
+	} else {
+		overrides[output] = override
+	}
+	config := m.config
+	config.OutputOverrides = overrides
+	if err := config.Validate(); err != nil {
+		m.configMutex.Unlock()
+		return err
+	}
+	m.config = config
+	m.configMutex.Unlock()
+
+	m.transitionMutex.RLock()
+	temp := m.currentTemp
+	m.transitionMutex.RUnlock()
+
+	m.applyGammaImmediate(temp)
+	m.updateState()
+	return nil
+}
+
+// SetPaused holds the current temperature, e.g. while caffeine mode keeps a
+// presentation's colours stable, and catches up with the schedule on resume
+func (m *Manager) SetPaused(paused bool) {
+	m.configMutex.Lock()
+	changed := m.paused != paused
+	m.paused = paused
+	m.configMutex.Unlock()
+
+	if !changed {
+		return
+	}
+	if !paused {
+		m.triggerUpdate()
+	}
+	m.updateState()
+}
+
+// SetTemporaryTemperature holds temp for duration instead of the schedule,
+// e.g. warmer for late reading, then fades back. A zero temp goes back to
+// the schedule now
+func (m *Manager) SetTemporaryTemperature(temp int, duration time.Duration) error {
+	if temp == 0 {
+		m.ResumeSchedule()
+		return nil
+	}
+	if temp < 1000 || temp > 10000 {
+		return errdefs.ErrInvalidTemperature
+	}
+	if duration <= 0 || duration > maxOverrideDuration {
+		return fmt.Errorf("duration must be between 0 and %v", maxOverrideDuration)
+	}
+
+	m.configMutex.Lock()
+	if !m.config.Enabled {
+		m.configMutex.Unlock()
+		return fmt.Errorf("gamma control is not enabled")
+	}
+	m.override = &TemperatureOverride{Temperature: temp, Until: time.Now().Add(duration)}
+	m.configMutex.Unlock()
+
+	m.triggerUpdate()
+	m.updateState()
+	return nil
+}
+
+// PauseUntilSunrise turns night mode off, e.g. for a film, holding the
+// daytime temperature until the schedule is back at day on its own. Paused
+// during the day it skips the coming night
+func (m *Manager) PauseUntilSunrise() error {
+	m.configMutex.RLock()
+	config := m.config
+	m.configMutex.RUnlock()
+
+	if !config.Enabled {
+		return fmt.Errorf("gamma control is not enabled")
+	}
+	until, ok := m.nextSunrise(config, time.Now())
+	if !ok {
+		return fmt.Errorf("the schedule has no sunrise; set a location or manual times")
+	}
+
+	m.configMutex.Lock()
+	m.override = &TemperatureOverride{Temperature: config.HighTemp, Until: until, UntilSunrise: true}
+	m.configMutex.Unlock()
+
+	m.triggerUpdate()
+	m.updateState()
+	return nil
+}
+
+// ResumeSchedule drops a temporary temperature or pause
+func (m *Manager) ResumeSchedule() {
+	m.configMutex.Lock()
+	had := m.override != nil
+	m.override = nil
+	m.configMutex.Unlock()
+
+	if !had {
+		return
+	}
+	m.triggerUpdate()
+	m.updateState()
+}
+
+// activeOverride returns the override in effect at now, forgetting one that
+// ran out
+func (m *Manager) activeOverride(now time.Time) (TemperatureOverride, bool) {
+	m.configMutex.Lock()
+	defer m.configMutex.Unlock()
+
+	if m.override == nil {
+		return TemperatureOverride{}, false
+	}
+	if !now.Before(m.override.Until) {
+		m.override = nil
+		return TemperatureOverride{}, false
+	}
+	return *m.override, true
+}
+
+// nextSunrise follows the schedule from now to when it reaches the daytime
+// temperature after some night. Without a location or manual times the
+// schedule never gets there
+func (m *Manager) nextSunrise(config Config, now time.Time) (time.Time, bool) {
+	night := m.scheduledTemperature(config, now) != config.HighTemp
+	limit := now.Add(48 * time.Hour)
+
+	for t := now; t.Before(limit); {
+		next := m.scheduledNextTransition(config, t)
+		if !next.After(t) {
+			return time.Time{}, false
+		}
+		t = next
+		if night && m.scheduledTemperature(config, t) == config.HighTemp {
+			return t, true
+		}
+		// Without a transition the night starts just after sunset
+		if m.scheduledTemperature(config, t.Add(time.Second)) != config.HighTemp {
+			night = true
+		}
+	}
+	return time.Time{}, false
+}
+
 func (m *Manager) Close() {
 	close(m.stopChan)
 	m.wg.Wait()
@@ -1289,6 +1687,7 @@ func (m *Manager) Close() {
 		if control, ok := out.gammaControl.(*wlr_gamma_control.ZwlrGammaControlV1); ok {
 			control.Destroy()
 		}
+		out.closeRampFile()
 	}
 	m.outputs = make(map[uint32]*outputState)
 	m.outputsMutex.Unlock()
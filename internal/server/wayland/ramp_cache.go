@@ -0,0 +1,80 @@
+package wayland
+
+import (
+	"container/list"
+	"sync"
+)
+
+// rampCacheSize covers a full transition (30 steps) on two ramp sizes with
+// room to spare. A packed 4096 entry ramp is 24KB
+const rampCacheSize = 64
+
+type rampKey struct {
+	size  uint32
+	temp  int
+	gamma float64
+}
+
+type rampEntry struct {
+	key  rampKey
+	data []byte
+}
+
+// rampCache keeps recently packed ramps so transitions, which revisit the
+// same temperatures, don't regenerate them for every output each frame.
+// Cached data is shared and must not be modified
+type rampCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[rampKey]*list.Element
+	hits    uint64
+	misses  uint64
+}
+
+func newRampCache(size int) *rampCache {
+	return &rampCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[rampKey]*list.Element, size),
+	}
+}
+
+// packed returns the packed ramp for size, temp and gamma, generating it on
+// a miss and evicting the least recently used one when full
+func (c *rampCache) packed(size uint32, temp int, gamma float64) []byte {
+	key := rampKey{size: size, temp: temp, gamma: gamma}
+
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		c.hits++
+		data := elem.Value.(*rampEntry).data
+		c.mu.Unlock()
+		return data
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	data := PackGammaRamp(GenerateGammaRamp(size, temp, gamma))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		return elem.Value.(*rampEntry).data
+	}
+	c.entries[key] = c.order.PushFront(&rampEntry{key: key, data: data})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*rampEntry).key)
+	}
+	return data
+}
+
+func (c *rampCache) stats() (hits, misses uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
@@ -0,0 +1,83 @@
+package wayland
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestRampCache(t *testing.T) {
+	c := newRampCache(2)
+
+	first := c.packed(256, 4000, 1.0)
+	assert.Equal(t, PackGammaRamp(GenerateGammaRamp(256, 4000, 1.0)), first)
+	assert.Same(t, &first[0], &c.packed(256, 4000, 1.0)[0], "a hit returns the cached ramp")
+
+	c.packed(1024, 4000, 1.0)
+	c.packed(256, 4000, 1.0)
+	c.packed(256, 4100, 1.0) // evicts 1024/4000, the least recently used
+	assert.Len(t, c.entries, 2)
+	assert.NotContains(t, c.entries, rampKey{size: 1024, temp: 4000, gamma: 1.0})
+	assert.Contains(t, c.entries, rampKey{size: 256, temp: 4000, gamma: 1.0})
+
+	hits, misses := c.stats()
+	assert.Equal(t, uint64(2), hits)
+	assert.Equal(t, uint64(3), misses)
+
+	c.packed(256, 4000, 1.2)
+	_, misses = c.stats()
+	assert.Equal(t, uint64(4), misses, "gamma is part of the key")
+}
+
+func TestOutputRampFdReused(t *testing.T) {
+	out := &outputState{}
+	defer out.closeRampFile()
+
+	_, err := out.rampFd(PackGammaRamp(GenerateGammaRamp(256, 4000, 1.0)))
+	require.NoError(t, err)
+	first := out.rampFile
+	_, err = io.ReadAll(out.rampFile)
+	require.NoError(t, err, "the compositor reads the ramp")
+
+	want := PackGammaRamp(GenerateGammaRamp(16, 3000, 1.0))
+	_, err = out.rampFd(want)
+	require.NoError(t, err)
+	assert.Same(t, first, out.rampFile, "a consumed memfd is reused")
+
+	got, err := io.ReadAll(out.rampFile)
+	require.NoError(t, err)
+	assert.Equal(t, want, got, "the smaller ramp replaces the old one, rewound")
+}
+
+func TestOutputRampFdQueued(t *testing.T) {
+	out := &outputState{}
+	defer out.closeRampFile()
+
+	queued := PackGammaRamp(GenerateGammaRamp(16, 4000, 1.0))
+	fd, err := out.rampFd(queued)
+	require.NoError(t, err)
+	pending, err := unix.Dup(fd)
+	require.NoError(t, err)
+	pendingFile := os.NewFile(uintptr(pending), "pending")
+	defer pendingFile.Close()
+
+	_, err = out.rampFd(PackGammaRamp(GenerateGammaRamp(16, 3000, 1.0)))
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(pendingFile)
+	require.NoError(t, err)
+	assert.Equal(t, queued, got, "a ramp the compositor hasn't read yet is left alone")
+}
+
+func BenchmarkRampCacheTransition(b *testing.B) {
+	c := newRampCache(rampCacheSize)
+	for i := 0; i < b.N; i++ {
+		for step := 0; step < 30; step++ {
+			c.packed(1024, 6500-step*100, 1.0)
+		}
+	}
+}
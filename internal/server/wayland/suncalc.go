@@ -12,6 +12,13 @@ const (
 	sunriseAngle = -0.833
 )
 
+const (
+	maxTransition = 3 * time.Hour
+	// minTransitionStep keeps a long fade from waking the update loop more
+	// often than the change is visible
+	minTransitionStep = 10 * time.Second
+)
+
 func CalculateSunTimes(lat, lon float64, date time.Time) SunTimes {
 	utcDate := date.UTC()
 	year, month, day := utcDate.Date()
@@ -84,3 +91,79 @@ func timeOfDayToTime(hours float64, year int, month time.Month, day int, loc *ti
 
 	return time.Date(year, month, day, h, m, s, 0, loc)
 }
+
+// scheduleTemperature is the temperature at now: low at night, high by day,
+// fading linearly over transition before sunrise and after sunset like
+// redshift does across civil twilight
+func scheduleTemperature(now, sunrise, sunset time.Time, transition time.Duration, low, high int) int {
+	if transition <= 0 {
+		if now.Before(sunrise) || now.After(sunset) {
+			return low
+		}
+		return high
+	}
+
+	dawn := sunrise.Add(-transition)
+	// Yesterday's dusk can run past midnight
+	lastDusk := sunset.Add(-24 * time.Hour)
+
+	switch {
+	case now.Before(dawn):
+		if !now.Before(lastDusk) && now.Before(lastDusk.Add(transition)) {
+			return fadeTemperature(high, low, now.Sub(lastDusk), transition)
+		}
+		return low
+	case now.Before(sunrise):
+		return fadeTemperature(low, high, now.Sub(dawn), transition)
+	case !now.After(sunset):
+		return high
+	case now.Before(sunset.Add(transition)):
+		return fadeTemperature(high, low, now.Sub(sunset), transition)
+	}
+	return low
+}
+
+func fadeTemperature(from, to int, elapsed, transition time.Duration) int {
+	progress := float64(elapsed) / float64(transition)
+	return from + int(math.Round(float64(to-from)*progress))
+}
+
+// nextScheduleChange is when scheduleTemperature next changes today: the
+// start or end of a fade, or a step within one. False means nothing changes
+// until the fade before tomorrow's sunrise
+func nextScheduleChange(now, sunrise, sunset time.Time, transition time.Duration) (time.Time, bool) {
+	if transition <= 0 {
+		switch {
+		case now.Before(sunrise):
+			return sunrise, true
+		case now.Before(sunset):
+			return sunset, true
+		}
+		return time.Time{}, false
+	}
+
+	step := max(transition/100, minTransitionStep)
+	stepUntil := func(end time.Time) time.Time {
+		if next := now.Add(step); next.Before(end) {
+			return next
+		}
+		return end
+	}
+
+	dawn := sunrise.Add(-transition)
+	lastDuskEnd := sunset.Add(-24 * time.Hour).Add(transition)
+
+	switch {
+	case now.Before(dawn) && now.Before(lastDuskEnd):
+		return stepUntil(lastDuskEnd), true
+	case now.Before(dawn):
+		return dawn, true
+	case now.Before(sunrise):
+		return stepUntil(sunrise), true
+	case now.Before(sunset):
+		return sunset, true
+	case now.Before(sunset.Add(transition)):
+		return stepUntil(sunset.Add(transition)), true
+	}
+	return time.Time{}, false
+}
@@ -45,10 +45,7 @@ func calculateTemperature(config Config, now time.Time) int {
 		return config.HighTemp
 	}
 
-	if now.Before(sunrise) || now.After(sunset) {
-		return config.LowTemp
-	}
-	return config.HighTemp
+	return scheduleTemperature(now, sunrise, sunset, config.Transition, config.LowTemp, config.HighTemp)
 }
 
 func calculateNextTransition(config Config, now time.Time) time.Time {
@@ -90,11 +87,8 @@ func calculateNextTransition(config Config, now time.Time) time.Time {
 		return now.Add(24 * time.Hour)
 	}
 
-	if now.Before(sunrise) {
-		return sunrise
-	}
-	if now.Before(sunset) {
-		return sunset
+	if next, ok := nextScheduleChange(now, sunrise, sunset, config.Transition); ok {
+		return next
 	}
 
 	if config.ManualSunrise != nil && config.ManualSunset != nil {
@@ -104,7 +98,7 @@ func calculateNextTransition(config Config, now time.Time) time.Time {
 			config.ManualSunrise.Hour(),
 			config.ManualSunrise.Minute(),
 			config.ManualSunrise.Second(), 0, loc)
-		return nextSunrise
+		return nextSunrise.Add(-config.Transition)
 	}
 
 	if config.UseIPLocation {
@@ -113,12 +107,12 @@ func calculateNextTransition(config Config, now time.Time) time.Time {
 			return now.Add(24 * time.Hour)
 		}
 		nextDayTimes := CalculateSunTimes(*lat, *lon, now.Add(24*time.Hour))
-		return nextDayTimes.Sunrise
+		return nextDayTimes.Sunrise.Add(-config.Transition)
 	}
 
 	if config.Latitude != nil && config.Longitude != nil {
 		nextDayTimes := CalculateSunTimes(*config.Latitude, *config.Longitude, now.Add(24*time.Hour))
-		return nextDayTimes.Sunrise
+		return nextDayTimes.Sunrise.Add(-config.Transition)
 	}
 
 	return now.Add(24 * time.Hour)
@@ -340,6 +334,88 @@ func TestCalculateNextTransition(t *testing.T) {
 	}
 }
 
+func TestScheduleTemperatureFades(t *testing.T) {
+	day := time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)
+	sunrise := day.Add(6 * time.Hour)
+	sunset := day.Add(18 * time.Hour)
+	fade := 30 * time.Minute
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want int
+	}{
+		{"night", day.Add(3 * time.Hour), 4000},
+		{"dawn_start", sunrise.Add(-fade), 4000},
+		{"mid_dawn", sunrise.Add(-15 * time.Minute), 5250},
+		{"sunrise", sunrise, 6500},
+		{"day", day.Add(12 * time.Hour), 6500},
+		{"sunset", sunset, 6500},
+		{"mid_dusk", sunset.Add(15 * time.Minute), 5250},
+		{"dusk_end", sunset.Add(fade), 4000},
+		{"late", day.Add(23 * time.Hour), 4000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scheduleTemperature(tt.now, sunrise, sunset, fade, 4000, 6500); got != tt.want {
+				t.Errorf("scheduleTemperature() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	if got := scheduleTemperature(sunrise.Add(-time.Minute), sunrise, sunset, 0, 4000, 6500); got != 4000 {
+		t.Errorf("without a transition expected a step change, got %d", got)
+	}
+}
+
+func TestScheduleTemperatureDuskPastMidnight(t *testing.T) {
+	day := time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC)
+	sunrise := day.Add(4 * time.Hour)
+	sunset := day.Add(23*time.Hour + 30*time.Minute)
+	fade := time.Hour
+
+	// Yesterday's dusk started at 23:30 and is halfway through at 00:00
+	if got := scheduleTemperature(day, sunrise, sunset, fade, 4000, 6500); got != 5250 {
+		t.Errorf("expected the fade to carry past midnight, got %d", got)
+	}
+	next, ok := nextScheduleChange(day, sunrise, sunset, fade)
+	if !ok || !next.After(day) || next.After(day.Add(30*time.Minute)) {
+		t.Errorf("expected a step within the fade, got %v %v", next, ok)
+	}
+}
+
+func TestNextScheduleChange(t *testing.T) {
+	day := time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)
+	sunrise := day.Add(6 * time.Hour)
+	sunset := day.Add(18 * time.Hour)
+	fade := 30 * time.Minute
+	step := fade / 100
+
+	tests := []struct {
+		name   string
+		now    time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{"night", day.Add(3 * time.Hour), sunrise.Add(-fade), true},
+		{"dawn", sunrise.Add(-fade), sunrise.Add(-fade).Add(step), true},
+		{"dawn_last_step", sunrise.Add(-5 * time.Second), sunrise, true},
+		{"day", day.Add(12 * time.Hour), sunset, true},
+		{"dusk", sunset, sunset.Add(step), true},
+		{"after_dusk", sunset.Add(fade), time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := nextScheduleChange(tt.now, sunrise, sunset, fade)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("nextScheduleChange() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
 func TestTimeOfDayToTime(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -376,3 +452,57 @@ func TestTimeOfDayToTime(t *testing.T) {
 		})
 	}
 }
+
+func TestNextSunrise(t *testing.T) {
+	sunrise := time.Date(0, 1, 1, 6, 0, 0, 0, time.Local)
+	sunset := time.Date(0, 1, 1, 20, 0, 0, 0, time.Local)
+	m := &Manager{}
+
+	for _, transition := range []time.Duration{0, 30 * time.Minute} {
+		config := Config{
+			LowTemp:       4000,
+			HighTemp:      6500,
+			ManualSunrise: &sunrise,
+			ManualSunset:  &sunset,
+			Enabled:       true,
+			Transition:    transition,
+		}
+		tomorrow := time.Date(2024, 6, 22, 6, 0, 0, 0, time.Local)
+
+		for _, now := range []time.Time{
+			time.Date(2024, 6, 21, 22, 0, 0, 0, time.Local),
+			time.Date(2024, 6, 21, 12, 0, 0, 0, time.Local),
+		} {
+			got, ok := m.nextSunrise(config, now)
+			if !ok || !got.Equal(tomorrow) {
+				t.Errorf("transition %v, now %v: expected %v, got %v %v", transition, now, tomorrow, got, ok)
+			}
+		}
+
+		early := time.Date(2024, 6, 21, 3, 0, 0, 0, time.Local)
+		today := time.Date(2024, 6, 21, 6, 0, 0, 0, time.Local)
+		if got, ok := m.nextSunrise(config, early); !ok || !got.Equal(today) {
+			t.Errorf("transition %v: expected %v, got %v %v", transition, today, got, ok)
+		}
+	}
+
+	noSchedule := Config{LowTemp: 4000, HighTemp: 6500, Enabled: true}
+	if _, ok := m.nextSunrise(noSchedule, time.Now()); ok {
+		t.Error("expected no sunrise without a location or manual times")
+	}
+}
+
+func TestActiveOverrideExpires(t *testing.T) {
+	now := time.Now()
+	m := &Manager{override: &TemperatureOverride{Temperature: 3000, Until: now.Add(time.Hour)}}
+
+	if override, ok := m.activeOverride(now); !ok || override.Temperature != 3000 {
+		t.Errorf("expected the override to be active, got %v %v", override, ok)
+	}
+	if _, ok := m.activeOverride(now.Add(time.Hour)); ok {
+		t.Error("expected the override to have run out")
+	}
+	if m.override != nil {
+		t.Error("expected an expired override to be dropped")
+	}
+}
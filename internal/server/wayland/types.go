@@ -2,6 +2,8 @@ package wayland
 
 import (
 	"math"
+	"os"
+	"slices"
 	"sync"
 	"time"
 
@@ -22,6 +24,20 @@ type Config struct {
 	ManualDuration *time.Duration
 	Gamma          float64
 	Enabled        bool
+	// Transition is how long the fade between day and night takes: it
+	// starts Transition before sunrise and ends Transition after sunset,
+	// covering civil twilight. Zero switches at sunrise and sunset
+	Transition time.Duration
+	// OutputOverrides is keyed by output name (eDP-1) or description
+	OutputOverrides map[string]OutputOverride
+}
+
+// OutputOverride holds one output at Temperature instead of the schedule,
+// or with Disabled leaves it alone entirely, e.g. a colour-critical monitor.
+// A zero Temperature follows the schedule
+type OutputOverride struct {
+	Temperature int  `json:"temperature,omitempty"`
+	Disabled    bool `json:"disabled,omitempty"`
 }
 
 type State struct {
@@ -31,6 +47,62 @@ type State struct {
 	SunriseTime    time.Time `json:"sunriseTime"`
 	SunsetTime     time.Time `json:"sunsetTime"`
 	IsDay          bool      `json:"isDay"`
+	Paused         bool      `json:"paused"`
+	Period         string    `json:"period"`
+	// Override is a temporary temperature in effect instead of the schedule
+	Override *TemperatureOverride `json:"override,omitempty"`
+	// LocationSource is where the sun times come from: geoclue, ip,
+	// coordinates or manual. LocationAccuracy is in meters, 0 when the
+	// source doesn't say (the IP lookup is roughly city level)
+	LocationSource   string  `json:"locationSource,omitempty"`
+	LocationAccuracy float64 `json:"locationAccuracy,omitempty"`
+	// Outputs lists the connected outputs with their overrides
+	Outputs []OutputGamma `json:"outputs"`
+	// ApplyLatency is how long pushing ramps to the outputs takes, for
+	// troubleshooting stutter during transitions
+	ApplyLatency ApplyLatency `json:"applyLatency"`
+}
+
+// applyLatencyWeight is the weight of each new apply in the moving average
+const applyLatencyWeight = 0.1
+
+// ApplyLatency covers generating, packing and sending the ramps of one
+// apply: the last one, a moving average and the slowest since startup
+type ApplyLatency struct {
+	LastMs  float64         `json:"lastMs"`
+	AvgMs   float64         `json:"avgMs"`
+	MaxMs   float64         `json:"maxMs"`
+	Count   int             `json:"count"`
+	Outputs []OutputLatency `json:"outputs"`
+	// RampCacheHits and RampCacheMisses count ramps reused from and added
+	// to the ramp cache
+	RampCacheHits   uint64 `json:"rampCacheHits"`
+	RampCacheMisses uint64 `json:"rampCacheMisses"`
+}
+
+// OutputLatency is one output's share of the last apply; the ramp size is
+// what the compositor asked for and drives the cost
+type OutputLatency struct {
+	Output   string  `json:"output"`
+	RampSize uint32  `json:"rampSize"`
+	Ms       float64 `json:"ms"`
+}
+
+type OutputGamma struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	Temperature int    `json:"temperature,omitempty"`
+}
+
+const maxOverrideDuration = 24 * time.Hour
+
+// TemperatureOverride holds Temperature until Until, after which the
+// schedule takes over again. UntilSunrise marks a paused night
+type TemperatureOverride struct {
+	Temperature  int       `json:"temperature"`
+	Until        time.Time `json:"until"`
+	UntilSunrise bool      `json:"untilSunrise"`
 }
 
 type cmd struct {
@@ -39,15 +111,21 @@ type cmd struct {
 
 type Manager struct {
 	config      Config
+	paused      bool
+	override    *TemperatureOverride
 	configMutex sync.RWMutex
 	state       *State
 	stateMutex  sync.RWMutex
+	// applyLatency is guarded by stateMutex
+	applyLatency ApplyLatency
+	ramps        *rampCache
 
 	display             *wlclient.Display
 	registry            *wlclient.Registry
 	gammaControl        interface{}
 	availableOutputs    []*wlclient.Output
 	outputRegNames      map[uint32]uint32
+	outputNames         map[uint32]*outputName
 	outputs             map[uint32]*outputState
 	outputsMutex        sync.RWMutex
 	controlsInitialized bool
@@ -67,9 +145,13 @@ type Manager struct {
 
 	applyTimer *time.Timer
 
-	cachedIPLat   *float64
-	cachedIPLon   *float64
-	locationMutex sync.RWMutex
+	period    string
+	hooksDir  string
+	hookMutex sync.Mutex
+
+	cachedLocation  *location
+	geoclueFailedAt time.Time
+	locationMutex   sync.RWMutex
 
 	subscribers  map[string]chan State
 	subMutex     sync.RWMutex
@@ -83,12 +165,27 @@ type Manager struct {
 
 type outputState struct {
 	id           uint32
-	name         string
 	registryName uint32
 	output       *wlclient.Output
 	gammaControl interface{}
 	rampSize     uint32
 	failed       bool
+	// released outputs are disabled by an override and have no gamma
+	// control, so the compositor restores their own ramps
+	released bool
+	// rampFile is the memfd ramps for this output are written to, reused
+	// once the compositor has read it; rampLen is the ramp last written
+	rampFile *os.File
+	rampLen  int64
+	// applied is the ramp last sent through appliedControl; an apply that
+	// would send it again through the same control is skipped
+	applied        rampKey
+	appliedControl interface{}
+}
+
+type outputName struct {
+	name        string
+	description string
 }
 
 type SunTimes struct {
@@ -98,11 +195,12 @@ type SunTimes struct {
 
 func DefaultConfig() Config {
 	return Config{
-		Outputs:  []string{},
-		LowTemp:  4000,
-		HighTemp: 6500,
-		Gamma:    1.0,
-		Enabled:  false,
+		Outputs:    []string{},
+		LowTemp:    4000,
+		HighTemp:   6500,
+		Gamma:      1.0,
+		Enabled:    false,
+		Transition: 30 * time.Minute,
 	}
 }
 
@@ -131,9 +229,39 @@ func (c *Config) Validate() error {
 	if (c.ManualSunrise != nil) != (c.ManualSunset != nil) {
 		return errdefs.ErrInvalidManualTimes
 	}
+	if c.Transition < 0 || c.Transition > maxTransition {
+		return errdefs.ErrInvalidTransition
+	}
+	for _, override := range c.OutputOverrides {
+		if override.Temperature != 0 && (override.Temperature < 1000 || override.Temperature > 10000) {
+			return errdefs.ErrInvalidTemperature
+		}
+	}
 	return nil
 }
 
+// outputOverride finds the override for an output, by name first and then
+// by description
+func (c *Config) outputOverride(name, description string) OutputOverride {
+	if override, ok := c.OutputOverrides[name]; ok && name != "" {
+		return override
+	}
+	if override, ok := c.OutputOverrides[description]; ok && description != "" {
+		return override
+	}
+	return OutputOverride{}
+}
+
+// outputTemperature is what an output shows while the shared temperature is
+// temp. Overrides only apply while enabled, so disabling still fades every
+// output back to identity
+func outputTemperature(enabled bool, override OutputOverride, temp int) int {
+	if enabled && override.Temperature != 0 {
+		return override.Temperature
+	}
+	return temp
+}
+
 func (m *Manager) GetState() State {
 	m.stateMutex.RLock()
 	defer m.stateMutex.RUnlock()
@@ -190,5 +318,20 @@ func stateChanged(old, new *State) bool {
 	if old.Config.Enabled != new.Config.Enabled {
 		return true
 	}
+	if old.Paused != new.Paused {
+		return true
+	}
+	if old.LocationSource != new.LocationSource || old.LocationAccuracy != new.LocationAccuracy {
+		return true
+	}
+	if (old.Override == nil) != (new.Override == nil) || (old.Override != nil && *old.Override != *new.Override) {
+		return true
+	}
+	if old.Period != new.Period {
+		return true
+	}
+	if !slices.Equal(old.Outputs, new.Outputs) {
+		return true
+	}
 	return false
 }
@@ -196,6 +196,26 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid_output_override",
+			config: Config{
+				LowTemp:         4000,
+				HighTemp:        6500,
+				Gamma:           1.0,
+				OutputOverrides: map[string]OutputOverride{"eDP-1": {Temperature: 3500}, "DP-1": {Disabled: true}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid_output_temperature",
+			config: Config{
+				LowTemp:         4000,
+				HighTemp:        6500,
+				Gamma:           1.0,
+				OutputOverrides: map[string]OutputOverride{"eDP-1": {Temperature: 500}},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -291,6 +311,20 @@ func TestStateChanged(t *testing.T) {
 			},
 			wantChanged: true,
 		},
+		{
+			name: "outputs_changed",
+			old:  baseState,
+			new: &State{
+				CurrentTemp:    baseState.CurrentTemp,
+				NextTransition: baseState.NextTransition,
+				SunriseTime:    baseState.SunriseTime,
+				SunsetTime:     baseState.SunsetTime,
+				IsDay:          baseState.IsDay,
+				Config:         baseState.Config,
+				Outputs:        []OutputGamma{{Name: "DP-1", Enabled: false}},
+			},
+			wantChanged: true,
+		},
 		{
 			name: "enabled_changed",
 			old:  baseState,
@@ -309,6 +343,20 @@ func TestStateChanged(t *testing.T) {
 			},
 			wantChanged: true,
 		},
+		{
+			name: "override_set",
+			old:  baseState,
+			new: &State{
+				CurrentTemp:    baseState.CurrentTemp,
+				NextTransition: baseState.NextTransition,
+				SunriseTime:    baseState.SunriseTime,
+				SunsetTime:     baseState.SunsetTime,
+				IsDay:          baseState.IsDay,
+				Config:         baseState.Config,
+				Override:       &TemperatureOverride{Temperature: 6500, Until: baseState.SunriseTime, UntilSunrise: true},
+			},
+			wantChanged: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -321,6 +369,38 @@ func TestStateChanged(t *testing.T) {
 	}
 }
 
+func TestOutputOverride(t *testing.T) {
+	config := Config{OutputOverrides: map[string]OutputOverride{
+		"eDP-1":              {Temperature: 3500},
+		"Dell Inc. U2720Q":   {Disabled: true},
+		"BOE 0x095F (eDP-1)": {Temperature: 5000},
+	}}
+
+	if got := config.outputOverride("eDP-1", "BOE 0x095F (eDP-1)"); got.Temperature != 3500 {
+		t.Errorf("name should win over description, got %+v", got)
+	}
+	if got := config.outputOverride("DP-1", "Dell Inc. U2720Q"); !got.Disabled {
+		t.Errorf("expected description match, got %+v", got)
+	}
+	if got := config.outputOverride("HDMI-A-1", ""); got != (OutputOverride{}) {
+		t.Errorf("expected no override, got %+v", got)
+	}
+}
+
+func TestOutputTemperature(t *testing.T) {
+	override := OutputOverride{Temperature: 3500}
+
+	if got := outputTemperature(true, override, 5000); got != 3500 {
+		t.Errorf("override while enabled = %d, want 3500", got)
+	}
+	if got := outputTemperature(false, override, 6000); got != 6000 {
+		t.Errorf("override while disabling = %d, want the shared 6000", got)
+	}
+	if got := outputTemperature(true, OutputOverride{}, 5000); got != 5000 {
+		t.Errorf("no override = %d, want 5000", got)
+	}
+}
+
 func floatPtr(f float64) *float64 {
 	return &f
 }
@@ -0,0 +1,190 @@
+package settingsync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+// Files maps a path relative to the item to its content; a single file
+// item uses the empty path
+type Files map[string][]byte
+
+func (f Files) Hash() string {
+	if len(f) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%s\x00%d\x00", name, len(f[name]))
+		h.Write(f[name])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Store is one side of a synced item
+type Store interface {
+	Read() (Files, error)
+	// Write applies files, returning a note for the user when it did more
+	// than copy them
+	Write(files Files) (string, error)
+}
+
+type fileStore struct {
+	path string
+}
+
+func (s fileStore) Read() (Files, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Files{"": data}, nil
+}
+
+func (s fileStore) Write(files Files) (string, error) {
+	data, ok := files[""]
+	if !ok {
+		return "", nil
+	}
+	return "", writeFileAtomic(s.path, data)
+}
+
+// dirStore mirrors regular files below a directory; files only present on
+// the receiving side are kept
+type dirStore struct {
+	path string
+}
+
+func (s dirStore) Read() (Files, error) {
+	files := Files{}
+	err := filepath.WalkDir(s.path, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == s.path {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() && entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(s.path, path)
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (s dirStore) Write(files Files) (string, error) {
+	for name, data := range files {
+		if strings.HasPrefix(filepath.Clean(name), "..") {
+			return "", fmt.Errorf("refusing to write outside %s: %s", s.path, name)
+		}
+		if err := writeFileAtomic(filepath.Join(s.path, filepath.FromSlash(name)), data); err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+type pluginLock struct {
+	Plugins []string `json:"plugins"`
+}
+
+// pluginLockStore describes installed plugins as a lockfile; writing it
+// installs the plugins that are missing here
+type pluginLockStore struct {
+	list    func() ([]string, error)
+	install func(id string) error
+}
+
+func (s pluginLockStore) Read() (Files, error) {
+	installed, err := s.list()
+	if err != nil {
+		return nil, err
+	}
+	if len(installed) == 0 {
+		return nil, nil
+	}
+	sort.Strings(installed)
+	data, err := json.MarshalIndent(pluginLock{Plugins: installed}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return Files{"": append(data, '\n')}, nil
+}
+
+func (s pluginLockStore) Write(files Files) (string, error) {
+	data, ok := files[""]
+	if !ok {
+		return "", nil
+	}
+	var lock pluginLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return "", fmt.Errorf("invalid plugin lockfile: %w", err)
+	}
+
+	installed, err := s.list()
+	if err != nil {
+		return "", err
+	}
+	have := make(map[string]bool, len(installed))
+	for _, id := range installed {
+		have[id] = true
+	}
+
+	var added, failed []string
+	for _, id := range lock.Plugins {
+		if have[id] {
+			continue
+		}
+		if err := s.install(id); err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", id, err))
+			continue
+		}
+		added = append(added, id)
+	}
+
+	var notes []string
+	if len(added) > 0 {
+		notes = append(notes, "installed "+strings.Join(added, ", "))
+	}
+	if len(failed) > 0 {
+		notes = append(notes, "failed to install "+strings.Join(failed, ", "))
+	}
+	return strings.Join(notes, "; "), nil
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(path, data, 0644)
+}
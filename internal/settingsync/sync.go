@@ -0,0 +1,337 @@
+// Package settingsync keeps shell settings, daemon config, installed plugins
+// and theme state in a folder shared between machines, either a git
+// repository with a remote or a plain folder synced by e.g. Syncthing
+package settingsync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type State string
+
+const (
+	StateInSync   State = "in sync"
+	StateLocal    State = "local changes"
+	StateRemote   State = "remote changes"
+	StateConflict State = "conflict"
+	StateEmpty    State = "not present"
+)
+
+type Item struct {
+	Name  string
+	Local Store
+	// Dir items are stored as a directory in the sync folder
+	Dir bool
+}
+
+type ItemStatus struct {
+	Name  string `json:"name"`
+	State State  `json:"state"`
+	Note  string `json:"note,omitempty"`
+}
+
+// syncState is the machine-local record of the last synced content, which
+// tells local edits, remote edits and conflicts apart
+type syncState struct {
+	Dir  string            `json:"dir"`
+	Base map[string]string `json:"base"`
+}
+
+type Syncer struct {
+	Dir       string
+	StatePath string
+	Items     []Item
+	base      map[string]string
+}
+
+func defaultStatePath() string {
+	return filepath.Join(os.Getenv("HOME"), ".local", "state", "DankMaterialShell", "sync.json")
+}
+
+func configHome() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.Getenv("HOME"), ".config")
+}
+
+// DefaultItems are the synced files: shell settings, the theme and
+// wallpaper in the shell session, dms daemon config and the plugin list
+func DefaultItems(listPlugins func() ([]string, error), installPlugin func(string) error) []Item {
+	home := os.Getenv("HOME")
+	return []Item{
+		{Name: "settings.json", Local: fileStore{filepath.Join(configHome(), "DankMaterialShell", "settings.json")}},
+		{Name: "session.json", Local: fileStore{filepath.Join(home, ".local", "state", "DankMaterialShell", "session.json")}},
+		{Name: "dms", Dir: true, Local: dirStore{filepath.Join(configHome(), "dms")}},
+		{Name: "plugins.lock.json", Local: pluginLockStore{list: listPlugins, install: installPlugin}},
+	}
+}
+
+// Load returns the syncer configured by Init, or an error when sync has
+// not been set up on this machine
+func Load(items []Item) (*Syncer, error) {
+	statePath := defaultStatePath()
+	data, err := os.ReadFile(statePath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("sync is not set up (run 'dms sync init <git-remote|folder>')")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state syncState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid sync state %s: %w", statePath, err)
+	}
+	return &Syncer{Dir: state.Dir, StatePath: statePath, Items: items, base: state.Base}, nil
+}
+
+// Init opts this machine into sync. A target that looks like a git URL is
+// cloned into the dms data dir; anything else is used as a plain folder
+func Init(target string, items []Item) (*Syncer, error) {
+	dir := target
+	if isGitURL(target) {
+		dir = filepath.Join(os.Getenv("HOME"), ".local", "share", "DankMaterialShell", "sync")
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+			if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+				return nil, err
+			}
+			if output, err := exec.Command("git", "clone", target, dir).CombinedOutput(); err != nil {
+				return nil, fmt.Errorf("git clone failed: %s", strings.TrimSpace(string(output)))
+			}
+		}
+	} else {
+		abs, err := filepath.Abs(target)
+		if err != nil {
+			return nil, err
+		}
+		dir = abs
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Syncer{Dir: dir, StatePath: defaultStatePath(), Items: items, base: map[string]string{}}
+	return s, s.saveState()
+}
+
+func isGitURL(target string) bool {
+	return strings.HasPrefix(target, "git@") || strings.HasSuffix(target, ".git") ||
+		strings.Contains(target, "://")
+}
+
+func (s *Syncer) isGit() bool {
+	_, err := os.Stat(filepath.Join(s.Dir, ".git"))
+	return err == nil
+}
+
+func (s *Syncer) git(args ...string) (string, error) {
+	output, err := exec.Command("git", append([]string{"-C", s.Dir}, args...)...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (s *Syncer) hasRemote() bool {
+	remotes, err := s.git("remote")
+	return err == nil && remotes != ""
+}
+
+func (s *Syncer) remoteStore(item Item) Store {
+	path := filepath.Join(s.Dir, item.Name)
+	if item.Dir {
+		return dirStore{path}
+	}
+	return fileStore{path}
+}
+
+func (s *Syncer) saveState() error {
+	data, err := json.MarshalIndent(syncState{Dir: s.Dir, Base: s.base}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.StatePath, data)
+}
+
+type itemContent struct {
+	item   Item
+	local  Files
+	remote Files
+	status ItemStatus
+}
+
+func (s *Syncer) compare() ([]itemContent, error) {
+	var contents []itemContent
+	for _, item := range s.Items {
+		local, err := item.Local.Read()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", item.Name, err)
+		}
+		remote, err := s.remoteStore(item).Read()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read synced %s: %w", item.Name, err)
+		}
+
+		contents = append(contents, itemContent{
+			item:   item,
+			local:  local,
+			remote: remote,
+			status: ItemStatus{Name: item.Name, State: classify(local.Hash(), remote.Hash(), s.base[item.Name])},
+		})
+	}
+	return contents, nil
+}
+
+func classify(local, remote, base string) State {
+	switch {
+	case local == remote && local == "":
+		return StateEmpty
+	case local == remote:
+		return StateInSync
+	case remote == "" || (base != "" && remote == base):
+		return StateLocal
+	case local == "" || (base != "" && local == base):
+		return StateRemote
+	}
+	return StateConflict
+}
+
+// Status fetches remote changes first when the folder is a git repo
+func (s *Syncer) Status() ([]ItemStatus, error) {
+	if s.isGit() && s.hasRemote() {
+		if _, err := s.git("pull", "--ff-only", "--quiet"); err != nil {
+			return nil, err
+		}
+	}
+
+	contents, err := s.compare()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]ItemStatus, 0, len(contents))
+	for _, content := range contents {
+		statuses = append(statuses, content.status)
+	}
+	return statuses, nil
+}
+
+func conflictError(contents []itemContent, command string) error {
+	var names []string
+	for _, content := range contents {
+		if content.status.State == StateConflict {
+			names = append(names, content.item.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return fmt.Errorf("changed both here and remotely: %s (use 'dms sync %s --force' to overwrite)", strings.Join(names, ", "), command)
+}
+
+// Push copies local changes into the sync folder and, for git, commits and
+// pushes them. Conflicts stop the push unless force is set
+func (s *Syncer) Push(force bool) ([]ItemStatus, error) {
+	useGit := s.isGit()
+	if useGit && s.hasRemote() {
+		if _, err := s.git("pull", "--ff-only", "--quiet"); err != nil {
+			return nil, err
+		}
+	}
+
+	contents, err := s.compare()
+	if err != nil {
+		return nil, err
+	}
+	if !force {
+		if err := conflictError(contents, "push"); err != nil {
+			return nil, err
+		}
+	}
+
+	var statuses []ItemStatus
+	for _, content := range contents {
+		status := content.status
+		if status.State == StateLocal || status.State == StateConflict {
+			if _, err := s.remoteStore(content.item).Write(content.local); err != nil {
+				return nil, fmt.Errorf("failed to write synced %s: %w", content.item.Name, err)
+			}
+			status.Note = "pushed"
+			s.base[content.item.Name] = content.local.Hash()
+		} else if status.State == StateInSync {
+			s.base[content.item.Name] = content.local.Hash()
+		}
+		statuses = append(statuses, status)
+	}
+
+	if useGit {
+		if err := s.commitAndPush(); err != nil {
+			return nil, err
+		}
+	}
+	return statuses, s.saveState()
+}
+
+func (s *Syncer) commitAndPush() error {
+	if _, err := s.git("add", "-A"); err != nil {
+		return err
+	}
+	if changes, err := s.git("status", "--porcelain"); err != nil || changes == "" {
+		return err
+	}
+
+	hostname, _ := os.Hostname()
+	if _, err := s.git("commit", "--quiet", "-m", "Sync from "+hostname); err != nil {
+		return err
+	}
+	if s.hasRemote() {
+		if _, err := s.git("push", "--quiet"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pull applies remote changes locally. Conflicts stop the pull unless force is set
+func (s *Syncer) Pull(force bool) ([]ItemStatus, error) {
+	if s.isGit() && s.hasRemote() {
+		if _, err := s.git("pull", "--ff-only", "--quiet"); err != nil {
+			return nil, err
+		}
+	}
+
+	contents, err := s.compare()
+	if err != nil {
+		return nil, err
+	}
+	if !force {
+		if err := conflictError(contents, "pull"); err != nil {
+			return nil, err
+		}
+	}
+
+	var statuses []ItemStatus
+	for _, content := range contents {
+		status := content.status
+		if status.State == StateRemote || status.State == StateConflict {
+			note, err := content.item.Local.Write(content.remote)
+			if err != nil {
+				return nil, fmt.Errorf("failed to apply %s: %w", content.item.Name, err)
+			}
+			status.Note = "pulled"
+			if note != "" {
+				status.Note += ", " + note
+			}
+			s.base[content.item.Name] = content.remote.Hash()
+		} else if status.State == StateInSync {
+			s.base[content.item.Name] = content.local.Hash()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, s.saveState()
+}
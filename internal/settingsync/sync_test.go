@@ -0,0 +1,140 @@
+package settingsync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name                string
+		local, remote, base string
+		expected            State
+	}{
+		{"both empty", "", "", "", StateEmpty},
+		{"equal", "a", "a", "", StateInSync},
+		{"first push", "a", "", "", StateLocal},
+		{"first pull", "", "a", "", StateRemote},
+		{"local edit", "b", "a", "a", StateLocal},
+		{"remote edit", "a", "b", "a", StateRemote},
+		{"both edited", "b", "c", "a", StateConflict},
+		{"unknown base", "b", "c", "", StateConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, classify(tt.local, tt.remote, tt.base))
+		})
+	}
+}
+
+type machine struct {
+	dir       string
+	installed []string
+}
+
+func (m *machine) syncer(t *testing.T, shared string, base map[string]string) *Syncer {
+	items := []Item{
+		{Name: "settings.json", Local: fileStore{filepath.Join(m.dir, "settings.json")}},
+		{Name: "dms", Dir: true, Local: dirStore{filepath.Join(m.dir, "dms")}},
+		{Name: "plugins.lock.json", Local: pluginLockStore{
+			list: func() ([]string, error) { return append([]string(nil), m.installed...), nil },
+			install: func(id string) error {
+				m.installed = append(m.installed, id)
+				return nil
+			},
+		}},
+	}
+	return &Syncer{Dir: shared, StatePath: filepath.Join(m.dir, "sync.json"), Items: items, base: base}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func stateOf(statuses []ItemStatus, name string) State {
+	for _, status := range statuses {
+		if status.Name == name {
+			return status.State
+		}
+	}
+	return ""
+}
+
+func TestPushPull(t *testing.T) {
+	shared := t.TempDir()
+	a := &machine{dir: t.TempDir(), installed: []string{"weather"}}
+	b := &machine{dir: t.TempDir()}
+	baseA, baseB := map[string]string{}, map[string]string{}
+
+	writeFile(t, filepath.Join(a.dir, "settings.json"), `{"theme":"blue"}`)
+	writeFile(t, filepath.Join(a.dir, "dms", "hooks.d", "on-lock"), "#!/bin/sh\n")
+
+	statuses, err := a.syncer(t, shared, baseA).Push(false)
+	require.NoError(t, err)
+	assert.Equal(t, StateLocal, stateOf(statuses, "settings.json"))
+	assert.Equal(t, `{"theme":"blue"}`, readFile(t, filepath.Join(shared, "settings.json")))
+	assert.FileExists(t, filepath.Join(shared, "dms", "hooks.d", "on-lock"))
+
+	statuses, err = b.syncer(t, shared, baseB).Pull(false)
+	require.NoError(t, err)
+	assert.Equal(t, StateRemote, stateOf(statuses, "settings.json"))
+	assert.Equal(t, `{"theme":"blue"}`, readFile(t, filepath.Join(b.dir, "settings.json")))
+	assert.Equal(t, "#!/bin/sh\n", readFile(t, filepath.Join(b.dir, "dms", "hooks.d", "on-lock")))
+	assert.Equal(t, []string{"weather"}, b.installed)
+
+	statuses, err = b.syncer(t, shared, baseB).Status()
+	require.NoError(t, err)
+	for _, status := range statuses {
+		assert.Equal(t, StateInSync, status.State, status.Name)
+	}
+}
+
+func TestConflict(t *testing.T) {
+	shared := t.TempDir()
+	a := &machine{dir: t.TempDir()}
+	b := &machine{dir: t.TempDir()}
+	baseA, baseB := map[string]string{}, map[string]string{}
+
+	writeFile(t, filepath.Join(a.dir, "settings.json"), `{"theme":"blue"}`)
+	_, err := a.syncer(t, shared, baseA).Push(false)
+	require.NoError(t, err)
+	_, err = b.syncer(t, shared, baseB).Pull(false)
+	require.NoError(t, err)
+
+	writeFile(t, filepath.Join(a.dir, "settings.json"), `{"theme":"red"}`)
+	_, err = a.syncer(t, shared, baseA).Push(false)
+	require.NoError(t, err)
+
+	writeFile(t, filepath.Join(b.dir, "settings.json"), `{"theme":"green"}`)
+	statuses, err := b.syncer(t, shared, baseB).Status()
+	require.NoError(t, err)
+	assert.Equal(t, StateConflict, stateOf(statuses, "settings.json"))
+
+	_, err = b.syncer(t, shared, baseB).Push(false)
+	assert.Error(t, err)
+	assert.Equal(t, `{"theme":"red"}`, readFile(t, filepath.Join(shared, "settings.json")))
+
+	_, err = b.syncer(t, shared, baseB).Pull(true)
+	require.NoError(t, err)
+	assert.Equal(t, `{"theme":"red"}`, readFile(t, filepath.Join(b.dir, "settings.json")))
+}
+
+func TestFilesHash(t *testing.T) {
+	assert.Equal(t, "", Files{}.Hash())
+	assert.Equal(t, Files{"a": []byte("1"), "b": []byte("2")}.Hash(), Files{"b": []byte("2"), "a": []byte("1")}.Hash())
+	assert.NotEqual(t, Files{"a": []byte("12")}.Hash(), Files{"a1": []byte("2")}.Hash())
+}
@@ -0,0 +1,215 @@
+// Package testsession runs a compositor nested in a window of the current
+// session with DMS inside it, so config and theme changes can be previewed
+// without logging out
+package testsession
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/kdl"
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+const (
+	Niri     = "niri"
+	Hyprland = "hyprland"
+)
+
+// nestedConfigName is written next to the real config so relative includes
+// and sources keep resolving
+const nestedConfigName = ".dms-test-session"
+
+type compositor struct {
+	binary     string
+	configPath string
+	extension  string
+	comment    string
+	// startup matches the lines that launch programs with the session
+	startup *regexp.Regexp
+	spawn   func(dms string) string
+	args    func(config string) []string
+}
+
+var hyprStartupRegex = regexp.MustCompile(`^\s*exec(-once)?\s*=`)
+var niriStartupRegex = regexp.MustCompile(`^\s*spawn(-sh)?-at-startup\s`)
+
+func compositors(configHome string) map[string]compositor {
+	return map[string]compositor{
+		Niri: {
+			binary:     "niri",
+			configPath: filepath.Join(configHome, "niri", "config.kdl"),
+			extension:  ".kdl",
+			comment:    "//",
+			startup:    niriStartupRegex,
+			spawn: func(dms string) string {
+				return fmt.Sprintf(`spawn-at-startup %s "run"`, kdl.String(dms).Raw)
+			},
+			args: func(config string) []string { return []string{"-c", config} },
+		},
+		Hyprland: {
+			binary:     "Hyprland",
+			configPath: filepath.Join(configHome, "hypr", "hyprland.conf"),
+			extension:  ".conf",
+			comment:    "#",
+			startup:    hyprStartupRegex,
+			spawn:      func(dms string) string { return "exec-once = " + dms + " run" },
+			args:       func(config string) []string { return []string{"--config", config} },
+		},
+	}
+}
+
+type Options struct {
+	// Compositor is Niri or Hyprland; empty picks one with Detect
+	Compositor string
+	// Config is the compositor config to start from, defaulting to the
+	// user's own
+	Config string
+	// DMS is the dms binary started inside the nested session
+	DMS string
+}
+
+// Session is a prepared nested session. Cleanup removes the generated
+// compositor config
+type Session struct {
+	Compositor string
+	Config     string
+	Cmd        *exec.Cmd
+}
+
+func (s *Session) Cleanup() {
+	if s.Config != "" {
+		os.Remove(s.Config)
+	}
+}
+
+func configHome() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.Getenv("HOME"), ".config")
+}
+
+// Detect prefers the compositor running the current session and otherwise
+// the first one installed
+func Detect() (string, error) {
+	switch {
+	case os.Getenv("NIRI_SOCKET") != "":
+		return Niri, nil
+	case os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "":
+		return Hyprland, nil
+	}
+
+	for _, name := range []string{Niri, Hyprland} {
+		if _, err := exec.LookPath(compositors("")[name].binary); err == nil {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no supported compositor found (niri or Hyprland required)")
+}
+
+// NestedConfig returns content with the session's startup programs removed,
+// so clipboard daemons and agents aren't started twice, and DMS spawned in
+// their place
+func NestedConfig(name, content, dms string) (string, error) {
+	c, ok := compositors("")[name]
+	if !ok {
+		return "", fmt.Errorf("unsupported compositor %q (use %s or %s)", name, Niri, Hyprland)
+	}
+
+	lines := strings.Split(content, "\n")
+	out := make([]string, 0, len(lines)+3)
+	for _, line := range lines {
+		if c.startup.MatchString(line) {
+			out = append(out, c.comment+" "+strings.TrimSpace(line))
+			continue
+		}
+		out = append(out, line)
+	}
+
+	result := strings.TrimRight(strings.Join(out, "\n"), "\n")
+	if result != "" {
+		result += "\n\n"
+	}
+	return result + c.comment + " Added by dms test-session\n" + c.spawn(dms) + "\n", nil
+}
+
+// Prepare writes the nested compositor config and builds the command that
+// starts the compositor. It needs a Wayland session to nest in
+func Prepare(opts Options) (*Session, error) {
+	if os.Getenv("WAYLAND_DISPLAY") == "" {
+		return nil, fmt.Errorf("WAYLAND_DISPLAY is not set; run this from a Wayland session")
+	}
+
+	name := strings.ToLower(opts.Compositor)
+	if name == "" {
+		detected, err := Detect()
+		if err != nil {
+			return nil, err
+		}
+		name = detected
+	}
+
+	c, ok := compositors(configHome())[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported compositor %q (use %s or %s)", opts.Compositor, Niri, Hyprland)
+	}
+	binary, err := exec.LookPath(c.binary)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not installed", c.binary)
+	}
+
+	source := opts.Config
+	if source == "" {
+		source = c.configPath
+	}
+	var content string
+	data, err := os.ReadFile(source)
+	switch {
+	case err == nil:
+		content = string(data)
+	case os.IsNotExist(err) && opts.Config == "":
+		// Run with the compositor's defaults
+	default:
+		return nil, fmt.Errorf("failed to read %s: %w", source, err)
+	}
+
+	dms := opts.DMS
+	if dms == "" {
+		dms = "dms"
+	}
+	nested, err := NestedConfig(name, content, dms)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(source)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, nestedConfigName+c.extension)
+	if err := utils.WriteFileAtomic(path, []byte(nested), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write nested config: %w", err)
+	}
+
+	cmd := exec.Command(binary, c.args(path)...)
+	cmd.Env = nestedEnv(os.Environ())
+	return &Session{Compositor: name, Config: path, Cmd: cmd}, nil
+}
+
+// nestedEnv drops the variables that point at the outer compositor. The
+// nested one sets its own for the programs it starts
+func nestedEnv(env []string) []string {
+	out := make([]string, 0, len(env))
+	for _, kv := range env {
+		if strings.HasPrefix(kv, "NIRI_SOCKET=") || strings.HasPrefix(kv, "HYPRLAND_INSTANCE_SIGNATURE=") {
+			continue
+		}
+		out = append(out, kv)
+	}
+	return out
+}
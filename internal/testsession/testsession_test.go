@@ -0,0 +1,52 @@
+package testsession
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNestedConfig_Niri(t *testing.T) {
+	content := `input {
+    keyboard { numlock; }
+}
+spawn-at-startup "bash" "-c" "wl-paste --watch cliphist store &"
+spawn-at-startup "dms" "run"
+spawn-sh-at-startup "nm-applet"
+binds {
+    Mod+Space { spawn "dms" "ipc" "call" "spotlight" "toggle"; }
+}
+`
+	got, err := NestedConfig(Niri, content, "/usr/bin/dms")
+	require.NoError(t, err)
+
+	assert.Contains(t, got, `// spawn-at-startup "dms" "run"`)
+	assert.Contains(t, got, `// spawn-sh-at-startup "nm-applet"`)
+	assert.Contains(t, got, `Mod+Space { spawn "dms" "ipc" "call" "spotlight" "toggle"; }`)
+	assert.Contains(t, got, "\n// Added by dms test-session\nspawn-at-startup \"/usr/bin/dms\" \"run\"\n")
+}
+
+func TestNestedConfig_Hyprland(t *testing.T) {
+	content := "exec-once = dms run\nexec = hyprctl reload\nbind = SUPER, Space, exec, dms ipc call spotlight toggle\n"
+	got, err := NestedConfig(Hyprland, content, "dms")
+	require.NoError(t, err)
+
+	assert.Contains(t, got, "# exec-once = dms run\n# exec = hyprctl reload\n")
+	assert.Contains(t, got, "bind = SUPER, Space, exec, dms ipc call spotlight toggle\n")
+	assert.Contains(t, got, "exec-once = dms run\n")
+}
+
+func TestNestedConfig_Empty(t *testing.T) {
+	got, err := NestedConfig(Hyprland, "", "dms")
+	require.NoError(t, err)
+	assert.Equal(t, "# Added by dms test-session\nexec-once = dms run\n", got)
+
+	_, err = NestedConfig("sway", "", "dms")
+	assert.ErrorContains(t, err, "unsupported compositor")
+}
+
+func TestNestedEnv(t *testing.T) {
+	env := nestedEnv([]string{"WAYLAND_DISPLAY=wayland-1", "NIRI_SOCKET=/run/niri.sock", "HYPRLAND_INSTANCE_SIGNATURE=abc", "HOME=/home/u"})
+	assert.Equal(t, []string{"WAYLAND_DISPLAY=wayland-1", "HOME=/home/u"}, env)
+}
@@ -0,0 +1,223 @@
+package themes
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/config"
+	"github.com/AvengeMedia/danklinux/internal/kdl"
+	"github.com/AvengeMedia/danklinux/internal/log"
+)
+
+const (
+	gsettingsSchema   = "org.gnome.desktop.interface"
+	defaultCursorSize = 24
+)
+
+// Applier writes a theme into every place that names it. All file edits
+// and gsettings changes are undone when one of them fails
+type Applier struct {
+	ConfigHome string
+	IconDir    string
+	Hyprland   string
+	Niri       string
+	// GSettings reads and writes org.gnome.desktop.interface keys; nil
+	// skips gsettings
+	GSettings GSettings
+}
+
+type GSettings interface {
+	Get(key string) (string, error)
+	Set(key, value string) error
+}
+
+type gsettingsCLI struct{}
+
+func (gsettingsCLI) Get(key string) (string, error) {
+	out, err := exec.Command("gsettings", "get", gsettingsSchema, key).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(strings.TrimSpace(string(out)), "'"), nil
+}
+
+func (gsettingsCLI) Set(key, value string) error {
+	if output, err := exec.Command("gsettings", "set", gsettingsSchema, key, value).CombinedOutput(); err != nil {
+		return fmt.Errorf("gsettings set %s failed: %s", key, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func DefaultApplier() *Applier {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	a := &Applier{
+		ConfigHome: configHome,
+		IconDir:    userIconDir(),
+		Hyprland:   filepath.Join(configHome, "hypr", "hyprland.conf"),
+		Niri:       filepath.Join(configHome, "niri", "config.kdl"),
+	}
+	if _, err := exec.LookPath("gsettings"); err == nil {
+		a.GSettings = gsettingsCLI{}
+	}
+	return a
+}
+
+// Apply makes an installed theme the current icon or cursor theme, chosen
+// by whether it has cursors. cursorSize 0 keeps the current size
+func (a *Applier) Apply(name string, cursorSize int) (Kind, error) {
+	path, ok := FindInstalled(name)
+	if !ok {
+		return "", fmt.Errorf("theme %s is not installed", name)
+	}
+	name = filepath.Base(path)
+	kind := DetectKind(path)
+
+	tx := &transaction{}
+	var err error
+	if kind == KindCursor {
+		err = a.applyCursor(tx, name, cursorSize)
+	} else {
+		err = a.applyIcons(tx, name)
+	}
+	if err != nil {
+		if rbErr := tx.rollback(); rbErr != nil {
+			log.Warnf("Rolling back theme change failed: %v", rbErr)
+		}
+		return kind, err
+	}
+	return kind, nil
+}
+
+func (a *Applier) gtkSettingsFiles() []string {
+	return []string{
+		filepath.Join(a.ConfigHome, "gtk-3.0", "settings.ini"),
+		filepath.Join(a.ConfigHome, "gtk-4.0", "settings.ini"),
+	}
+}
+
+func (a *Applier) applyIcons(tx *transaction, name string) error {
+	if err := a.setGSettings(tx, map[string]string{"icon-theme": name}); err != nil {
+		return err
+	}
+
+	for _, path := range a.gtkSettingsFiles() {
+		if err := tx.editIni(path, "Settings", map[string]string{"gtk-icon-theme-name": name}, true); err != nil {
+			return err
+		}
+	}
+
+	// qt5ct/qt6ct only matter when the user set them up
+	for _, ct := range []string{"qt5ct", "qt6ct"} {
+		path := filepath.Join(a.ConfigHome, ct, ct+".conf")
+		if err := tx.editIni(path, "Appearance", map[string]string{"icon_theme": name}, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *Applier) applyCursor(tx *transaction, name string, size int) error {
+	if size <= 0 {
+		size = a.currentCursorSize()
+	}
+	sizeStr := strconv.Itoa(size)
+
+	if err := a.setGSettings(tx, map[string]string{"cursor-theme": name, "cursor-size": sizeStr}); err != nil {
+		return err
+	}
+
+	for _, path := range a.gtkSettingsFiles() {
+		values := map[string]string{"gtk-cursor-theme-name": name, "gtk-cursor-theme-size": sizeStr}
+		if err := tx.editIni(path, "Settings", values, true); err != nil {
+			return err
+		}
+	}
+
+	// The "default" theme is what X11 apps and toolkits without their own
+	// setting fall back to
+	defaultIndex := filepath.Join(a.IconDir, "default", "index.theme")
+	if err := tx.editIni(defaultIndex, "Icon Theme", map[string]string{"Name": "Default", "Inherits": name}, true); err != nil {
+		return err
+	}
+
+	if err := tx.editFile(a.Hyprland, func(content string) (string, error) {
+		content = setHyprlandEnv(content, "XCURSOR_THEME", name)
+		return setHyprlandEnv(content, "XCURSOR_SIZE", sizeStr), nil
+	}); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(a.Niri); err == nil {
+		tx.snapshot(a.Niri)
+		if err := config.EditNiriConfig(a.Niri, func(doc *kdl.Document) error {
+			if err := config.SetNiriOption(doc, "niri.cursor.xcursor-theme", []string{kdl.String(name).Raw}); err != nil {
+				return err
+			}
+			return config.SetNiriOption(doc, "niri.cursor.xcursor-size", []string{sizeStr})
+		}); err != nil {
+			return err
+		}
+	}
+
+	// Hyprland only reads the env at startup; switch the running session too
+	if os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "" {
+		if err := exec.Command("hyprctl", "setcursor", name, sizeStr).Run(); err != nil {
+			log.Debugf("hyprctl setcursor failed: %v", err)
+		}
+	}
+	return nil
+}
+
+func (a *Applier) currentCursorSize() int {
+	if a.GSettings != nil {
+		if value, err := a.GSettings.Get("cursor-size"); err == nil {
+			if size, err := strconv.Atoi(value); err == nil && size > 0 {
+				return size
+			}
+		}
+	}
+	return defaultCursorSize
+}
+
+func (a *Applier) setGSettings(tx *transaction, values map[string]string) error {
+	if a.GSettings == nil {
+		return nil
+	}
+	for _, key := range sortedKeys(values) {
+		previous, err := a.GSettings.Get(key)
+		if err != nil {
+			// No schema, e.g. without gsettings-desktop-schemas installed
+			log.Debugf("gsettings get %s failed: %v", key, err)
+			return nil
+		}
+		if err := a.GSettings.Set(key, values[key]); err != nil {
+			return err
+		}
+		gs, k := a.GSettings, key
+		tx.undo = append(tx.undo, func() error { return gs.Set(k, previous) })
+	}
+	return nil
+}
+
+// setHyprlandEnv replaces the first "env = NAME,..." line or appends one
+func setHyprlandEnv(content, name, value string) string {
+	re := regexp.MustCompile(`(?m)^([ \t]*)env[ \t]*=[ \t]*` + regexp.QuoteMeta(name) + `[ \t]*,.*$`)
+
+	line := fmt.Sprintf("env = %s,%s", name, value)
+	if loc := re.FindStringSubmatchIndex(content); loc != nil {
+		indent := content[loc[2]:loc[3]]
+		return content[:loc[0]] + indent + line + content[loc[1]:]
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return content + line + "\n"
+}
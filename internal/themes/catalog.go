@@ -0,0 +1,181 @@
+// Package themes installs and applies icon and cursor themes, keeping the
+// GTK, Qt and compositor settings that name them in step
+package themes
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/distros"
+)
+
+type Kind string
+
+const (
+	KindIcon   Kind = "icon"
+	KindCursor Kind = "cursor"
+)
+
+// Theme is a supported theme. Variants are the theme directory names it
+// installs, the first being the one applied by default
+type Theme struct {
+	ID          string
+	Kind        Kind
+	Description string
+	Variants    []string
+	// Packages maps a distro family to the package shipping the theme;
+	// families without one fall back to Release
+	Packages map[distros.DistroFamily]string
+	// Release is a tarball with the theme directories, installed to
+	// ~/.local/share/icons
+	Release string
+}
+
+var catalog = []Theme{
+	{
+		ID:          "papirus",
+		Kind:        KindIcon,
+		Description: "Papirus icon theme",
+		Variants:    []string{"Papirus", "Papirus-Dark", "Papirus-Light"},
+		Packages: map[distros.DistroFamily]string{
+			distros.FamilyArch:   "papirus-icon-theme",
+			distros.FamilyFedora: "papirus-icon-theme",
+			distros.FamilySUSE:   "papirus-icon-theme",
+			distros.FamilyUbuntu: "papirus-icon-theme",
+			distros.FamilyDebian: "papirus-icon-theme",
+		},
+		Release: "https://github.com/PapirusDevelopmentTeam/papirus-icon-theme/archive/refs/heads/master.tar.gz",
+	},
+	{
+		ID:          "bibata",
+		Kind:        KindCursor,
+		Description: "Bibata cursors",
+		Variants: []string{
+			"Bibata-Modern-Classic", "Bibata-Modern-Ice", "Bibata-Modern-Amber",
+			"Bibata-Original-Classic", "Bibata-Original-Ice", "Bibata-Original-Amber",
+		},
+		Release: "https://github.com/ful1e5/Bibata_Cursor/releases/latest/download/Bibata.tar.xz",
+	},
+	{
+		ID:          "phinger",
+		Kind:        KindCursor,
+		Description: "Phinger cursors",
+		Variants:    []string{"phinger-cursors-dark", "phinger-cursors-light"},
+		Release:     "https://github.com/phisch/phinger-cursors/releases/latest/download/phinger-cursors-variants.tar.bz2",
+	},
+	{
+		ID:          "breeze",
+		Kind:        KindCursor,
+		Description: "KDE Breeze cursors",
+		Variants:    []string{"breeze_cursors"},
+		Packages: map[distros.DistroFamily]string{
+			distros.FamilyArch:   "breeze",
+			distros.FamilyFedora: "breeze-cursor-theme",
+			distros.FamilyUbuntu: "breeze-cursor-theme",
+			distros.FamilyDebian: "breeze-cursor-theme",
+		},
+	},
+}
+
+func Catalog() []Theme {
+	return catalog
+}
+
+// Lookup finds a theme by ID or by one of its variant names
+func Lookup(name string) (Theme, bool) {
+	for _, theme := range catalog {
+		if strings.EqualFold(theme.ID, name) {
+			return theme, true
+		}
+		for _, variant := range theme.Variants {
+			if strings.EqualFold(variant, name) {
+				return theme, true
+			}
+		}
+	}
+	return Theme{}, false
+}
+
+func userIconDir() string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(os.Getenv("HOME"), ".local", "share")
+	}
+	return filepath.Join(dataHome, "icons")
+}
+
+// iconDirs are the theme search paths, in lookup order
+func iconDirs() []string {
+	dirs := []string{userIconDir(), filepath.Join(os.Getenv("HOME"), ".icons")}
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range filepath.SplitList(dataDirs) {
+		dirs = append(dirs, filepath.Join(dir, "icons"))
+	}
+	return append(dirs, "/usr/share/pixmaps")
+}
+
+// FindInstalled returns the directory of an installed theme
+func FindInstalled(name string) (string, bool) {
+	for _, dir := range iconDirs() {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(filepath.Join(path, "index.theme")); err == nil {
+			return path, true
+		}
+		if _, err := os.Stat(filepath.Join(path, "cursors")); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// DetectKind tells cursor themes (which have a cursors directory) from icon
+// themes
+func DetectKind(path string) Kind {
+	if info, err := os.Stat(filepath.Join(path, "cursors")); err == nil && info.IsDir() {
+		return KindCursor
+	}
+	return KindIcon
+}
+
+type Installed struct {
+	Name string `json:"name"`
+	Kind Kind   `json:"kind"`
+	Path string `json:"path"`
+}
+
+// ListInstalled returns every installed icon and cursor theme, skipping the
+// hicolor fallback and the "default" cursor alias
+func ListInstalled() []Installed {
+	seen := make(map[string]bool)
+	var themes []Installed
+	for _, dir := range iconDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if seen[name] || name == "hicolor" || name == "default" || !entry.IsDir() {
+				continue
+			}
+			path, ok := FindInstalled(name)
+			if !ok || filepath.Dir(path) != dir {
+				continue
+			}
+			seen[name] = true
+			themes = append(themes, Installed{Name: name, Kind: DetectKind(path), Path: path})
+		}
+	}
+	sort.Slice(themes, func(i, j int) bool {
+		if themes[i].Kind != themes[j].Kind {
+			return themes[i].Kind < themes[j].Kind
+		}
+		return strings.ToLower(themes[i].Name) < strings.ToLower(themes[j].Name)
+	})
+	return themes
+}
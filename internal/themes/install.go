@@ -0,0 +1,142 @@
+package themes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/AvengeMedia/danklinux/internal/distros"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
+)
+
+func distroFamily() (distros.DistroFamily, error) {
+	osInfo, err := distros.GetOSInfo()
+	if err != nil {
+		return "", fmt.Errorf("failed to detect OS: %w", err)
+	}
+	config, exists := distros.Registry[osInfo.Distribution.ID]
+	if !exists {
+		return "", fmt.Errorf("unsupported distribution: %s", osInfo.Distribution.ID)
+	}
+	return config.Family, nil
+}
+
+func packageInstallArgs(family distros.DistroFamily, pkg string) ([]string, error) {
+	switch family {
+	case distros.FamilyArch:
+		return []string{"pacman", "-S", "--needed", "--noconfirm", pkg}, nil
+	case distros.FamilyFedora:
+		return []string{"dnf", "install", "-y", pkg}, nil
+	case distros.FamilySUSE:
+		return []string{"zypper", "install", "-y", pkg}, nil
+	case distros.FamilyUbuntu, distros.FamilyDebian:
+		return []string{"apt-get", "install", "-y", pkg}, nil
+	default:
+		return nil, fmt.Errorf("no package install for %s", family)
+	}
+}
+
+// Install installs a theme from the distro's packages, falling back to its
+// release tarball in the user's icon directory
+func Install(ctx context.Context, theme Theme, logFunc func(string)) error {
+	family, err := distroFamily()
+	if err != nil && theme.Release == "" {
+		return err
+	}
+
+	if pkg, ok := theme.Packages[family]; ok {
+		args, err := packageInstallArgs(family, pkg)
+		if err != nil {
+			return err
+		}
+		logFunc(fmt.Sprintf("Installing %s with %s...", pkg, args[0]))
+		cmd := exec.CommandContext(ctx, string(privesc.CurrentTool()), args...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to install %s: %w", pkg, err)
+		}
+		return nil
+	}
+
+	if theme.Release == "" {
+		if family == distros.FamilyNix {
+			return fmt.Errorf("on NixOS, add %s to your configuration.nix", theme.ID)
+		}
+		return fmt.Errorf("%s has no package for %s and no release download", theme.ID, family)
+	}
+	return installRelease(ctx, theme, logFunc)
+}
+
+func installRelease(ctx context.Context, theme Theme, logFunc func(string)) error {
+	tmpDir, err := os.MkdirTemp("", "dms-theme-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	logFunc(fmt.Sprintf("Downloading %s...", theme.Release))
+	archive := filepath.Join(tmpDir, filepath.Base(theme.Release))
+	if err := exec.CommandContext(ctx, "curl", "-fsSL", theme.Release, "-o", archive).Run(); err != nil {
+		return fmt.Errorf("failed to download %s: %w", theme.Release, err)
+	}
+
+	extractDir := filepath.Join(tmpDir, "extract")
+	if err := os.MkdirAll(extractDir, 0755); err != nil {
+		return err
+	}
+	if output, err := exec.CommandContext(ctx, "tar", "-xf", archive, "-C", extractDir).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to extract %s: %s", filepath.Base(archive), output)
+	}
+
+	found := findVariantDirs(extractDir, theme.Variants)
+	if len(found) == 0 {
+		return fmt.Errorf("%s contains none of the expected themes", filepath.Base(archive))
+	}
+
+	dest := userIconDir()
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+	for _, variant := range theme.Variants {
+		src, ok := found[variant]
+		if !ok {
+			continue
+		}
+		target := filepath.Join(dest, variant)
+		if err := os.RemoveAll(target); err != nil {
+			return err
+		}
+		if err := os.Rename(src, target); err != nil {
+			if output, err := exec.Command("cp", "-a", src, target).CombinedOutput(); err != nil {
+				return fmt.Errorf("failed to install %s: %s", variant, output)
+			}
+		}
+		logFunc(fmt.Sprintf("Installed %s to %s", variant, target))
+	}
+	return nil
+}
+
+// findVariantDirs locates the theme directories in an extracted archive,
+// which may nest them one level down (e.g. repo-branch/Papirus)
+func findVariantDirs(root string, variants []string) map[string]string {
+	found := make(map[string]string)
+	for _, base := range []string{root, filepath.Join(root, "*")} {
+		for _, variant := range variants {
+			if _, ok := found[variant]; ok {
+				continue
+			}
+			matches, _ := filepath.Glob(filepath.Join(base, variant))
+			for _, match := range matches {
+				if info, err := os.Stat(match); err == nil && info.IsDir() {
+					found[variant] = match
+					break
+				}
+			}
+		}
+	}
+	return found
+}
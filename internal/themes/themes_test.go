@@ -0,0 +1,138 @@
+package themes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeGSettings struct {
+	values  map[string]string
+	failSet string
+}
+
+func (f *fakeGSettings) Get(key string) (string, error) {
+	return f.values[key], nil
+}
+
+func (f *fakeGSettings) Set(key, value string) error {
+	if key == f.failSet {
+		return errors.New("denied")
+	}
+	f.values[key] = value
+	return nil
+}
+
+func setupApplier(t *testing.T) (*Applier, *fakeGSettings) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, ".local", "share"))
+	t.Setenv("XDG_DATA_DIRS", filepath.Join(home, "system"))
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "")
+
+	icons := filepath.Join(home, ".local", "share", "icons")
+	require.NoError(t, os.MkdirAll(filepath.Join(icons, "Papirus"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(icons, "Papirus", "index.theme"), []byte("[Icon Theme]\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(home, "system", "icons", "Bibata-Modern-Ice", "cursors"), 0755))
+
+	configHome := filepath.Join(home, ".config")
+	require.NoError(t, os.MkdirAll(filepath.Join(configHome, "hypr"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configHome, "hypr", "hyprland.conf"), []byte("env = XCURSOR_SIZE,32\n"), 0644))
+
+	gs := &fakeGSettings{values: map[string]string{"icon-theme": "Adwaita", "cursor-theme": "Adwaita", "cursor-size": "32"}}
+	return &Applier{
+		ConfigHome: configHome,
+		IconDir:    icons,
+		Hyprland:   filepath.Join(configHome, "hypr", "hyprland.conf"),
+		Niri:       filepath.Join(configHome, "niri", "config.kdl"),
+		GSettings:  gs,
+	}, gs
+}
+
+func read(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestSetIniValues(t *testing.T) {
+	content := "[Settings]\ngtk-theme-name=adw-gtk3\ngtk-icon-theme-name=Adwaita\n\n[Other]\nkey=1\n"
+	got := setIniValues(content, "Settings", map[string]string{"gtk-icon-theme-name": "Papirus", "gtk-cursor-theme-name": "Bibata"})
+	assert.Equal(t, "[Settings]\ngtk-theme-name=adw-gtk3\ngtk-icon-theme-name=Papirus\ngtk-cursor-theme-name=Bibata\n\n[Other]\nkey=1\n", got)
+
+	assert.Equal(t, "[Icon Theme]\nInherits=x\n", setIniValues("", "Icon Theme", map[string]string{"Inherits": "x"}))
+	assert.Equal(t, "a=1\n\n[S]\nk=v\n", setIniValues("a=1\n", "S", map[string]string{"k": "v"}))
+}
+
+func TestSetHyprlandEnv(t *testing.T) {
+	content := "monitor = ,preferred,auto,1\n  env = XCURSOR_THEME, Adwaita\n"
+	got := setHyprlandEnv(content, "XCURSOR_THEME", "Bibata")
+	assert.Equal(t, "monitor = ,preferred,auto,1\n  env = XCURSOR_THEME,Bibata\n", got)
+
+	got = setHyprlandEnv(got, "XCURSOR_SIZE", "24")
+	assert.Contains(t, got, "env = XCURSOR_THEME,Bibata\nenv = XCURSOR_SIZE,24\n")
+}
+
+func TestLookup(t *testing.T) {
+	theme, ok := Lookup("bibata-modern-ice")
+	require.True(t, ok)
+	assert.Equal(t, "bibata", theme.ID)
+	assert.Equal(t, KindCursor, theme.Kind)
+
+	_, ok = Lookup("nope")
+	assert.False(t, ok)
+}
+
+func TestApplyIcons(t *testing.T) {
+	a, gs := setupApplier(t)
+
+	kind, err := a.Apply("Papirus", 0)
+	require.NoError(t, err)
+	assert.Equal(t, KindIcon, kind)
+	assert.Equal(t, "Papirus", gs.values["icon-theme"])
+	assert.Equal(t, "[Settings]\ngtk-icon-theme-name=Papirus\n", read(t, filepath.Join(a.ConfigHome, "gtk-4.0", "settings.ini")))
+	assert.NoFileExists(t, filepath.Join(a.ConfigHome, "qt6ct", "qt6ct.conf"))
+}
+
+func TestApplyCursor(t *testing.T) {
+	a, gs := setupApplier(t)
+
+	kind, err := a.Apply("Bibata-Modern-Ice", 0)
+	require.NoError(t, err)
+	assert.Equal(t, KindCursor, kind)
+	assert.Equal(t, "Bibata-Modern-Ice", gs.values["cursor-theme"])
+	assert.Equal(t, "env = XCURSOR_SIZE,32\nenv = XCURSOR_THEME,Bibata-Modern-Ice\n", read(t, a.Hyprland))
+	assert.Contains(t, read(t, filepath.Join(a.IconDir, "default", "index.theme")), "Inherits=Bibata-Modern-Ice\n")
+	assert.Contains(t, read(t, filepath.Join(a.ConfigHome, "gtk-3.0", "settings.ini")), "gtk-cursor-theme-size=32\n")
+}
+
+func TestApplyRollsBack(t *testing.T) {
+	a, gs := setupApplier(t)
+	gs.failSet = "cursor-theme"
+
+	_, err := a.Apply("Bibata-Modern-Ice", 48)
+	require.Error(t, err)
+	assert.Equal(t, "32", gs.values["cursor-size"])
+	assert.Equal(t, "Adwaita", gs.values["cursor-theme"])
+	assert.Equal(t, "env = XCURSOR_SIZE,32\n", read(t, a.Hyprland))
+
+	// A failure after file edits restores them
+	gs.failSet = ""
+	require.NoError(t, os.MkdirAll(a.Niri, 0755))
+	_, err = a.Apply("Bibata-Modern-Ice", 48)
+	require.Error(t, err)
+	assert.Equal(t, "32", gs.values["cursor-size"])
+	assert.Equal(t, "env = XCURSOR_SIZE,32\n", read(t, a.Hyprland))
+	assert.NoFileExists(t, filepath.Join(a.ConfigHome, "gtk-3.0", "settings.ini"))
+	assert.NoFileExists(t, filepath.Join(a.IconDir, "default", "index.theme"))
+}
+
+func TestApplyNotInstalled(t *testing.T) {
+	a, _ := setupApplier(t)
+	_, err := a.Apply("Missing", 0)
+	assert.ErrorContains(t, err, "not installed")
+}
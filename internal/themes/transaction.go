@@ -0,0 +1,174 @@
+package themes
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/utils"
+)
+
+type fileSnapshot struct {
+	path    string
+	content []byte
+	mode    os.FileMode
+	existed bool
+}
+
+// transaction records the previous state of everything it changes so a
+// failed apply leaves no half-switched theme behind
+type transaction struct {
+	files []fileSnapshot
+	undo  []func() error
+}
+
+func (t *transaction) snapshot(path string) {
+	for _, snap := range t.files {
+		if snap.path == path {
+			return
+		}
+	}
+	snap := fileSnapshot{path: path, mode: 0644}
+	if info, err := os.Stat(path); err == nil {
+		snap.existed = true
+		snap.mode = info.Mode().Perm()
+		snap.content, _ = os.ReadFile(path)
+	}
+	t.files = append(t.files, snap)
+}
+
+func (t *transaction) write(path string, content string) error {
+	t.snapshot(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(path, []byte(content), 0644)
+}
+
+// editFile rewrites an existing file; missing files are skipped
+func (t *transaction) editFile(path string, edit func(string) (string, error)) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	content, err := edit(string(data))
+	if err != nil || content == string(data) {
+		return err
+	}
+	return t.write(path, content)
+}
+
+// editIni sets keys in a section, creating the file when create is set
+func (t *transaction) editIni(path, section string, values map[string]string, create bool) error {
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err) && !create:
+		return nil
+	case err != nil && !os.IsNotExist(err):
+		return err
+	}
+	content := setIniValues(string(data), section, values)
+	if content == string(data) {
+		return nil
+	}
+	return t.write(path, content)
+}
+
+func (t *transaction) rollback() error {
+	var errs []error
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		errs = append(errs, t.undo[i]())
+	}
+	for i := len(t.files) - 1; i >= 0; i-- {
+		snap := t.files[i]
+		if !snap.existed {
+			if err := os.Remove(snap.path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err := utils.WriteFileAtomic(snap.path, snap.content, snap.mode); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, os.Chmod(snap.path, snap.mode))
+	}
+	return errors.Join(errs...)
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// setIniValues sets keys in a section, keeping every other line as is.
+// Missing keys go at the end of the section, a missing section at the end
+// of the file
+func setIniValues(content, section string, values map[string]string) string {
+	var lines []string
+	if content != "" {
+		lines = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	}
+
+	remaining := make(map[string]bool, len(values))
+	for key := range values {
+		remaining[key] = true
+	}
+
+	start, end := -1, len(lines)
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			if start >= 0 {
+				end = i
+				break
+			}
+			if trimmed == "["+section+"]" {
+				start = i
+			}
+			continue
+		}
+		if start < 0 {
+			continue
+		}
+		key, _, ok := strings.Cut(trimmed, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !remaining[key] {
+			continue
+		}
+		lines[i] = key + "=" + values[key]
+		delete(remaining, key)
+	}
+
+	var added []string
+	for _, key := range sortedKeys(values) {
+		if remaining[key] {
+			added = append(added, key+"="+values[key])
+		}
+	}
+
+	if start < 0 {
+		if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+section+"]")
+		lines = append(lines, added...)
+	} else if len(added) > 0 {
+		// Insert before trailing blank lines of the section
+		insertAt := end
+		for insertAt > start+1 && strings.TrimSpace(lines[insertAt-1]) == "" {
+			insertAt--
+		}
+		lines = append(lines[:insertAt], append(added, lines[insertAt:]...)...)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
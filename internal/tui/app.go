@@ -1,8 +1,13 @@
 package tui
 
 import (
+	"fmt"
+	"io"
+
+	"github.com/AvengeMedia/danklinux/internal/config"
 	"github.com/AvengeMedia/danklinux/internal/deps"
 	"github.com/AvengeMedia/danklinux/internal/distros"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -23,6 +28,12 @@ type Model struct {
 	isLoading     bool
 	styles        Styles
 
+	// passwordRequest is the broker prompt shown as a modal over the
+	// current state, nil when none is waiting
+	passwordRequests chan privesc.PasswordRequest
+	passwordRequest  *privesc.PasswordRequest
+	rememberSudo     bool
+
 	logMessages         []string
 	logChan             chan string
 	packageProgressChan chan packageInstallProgressMsg
@@ -35,8 +46,21 @@ type Model struct {
 	selectedConfig   int
 	reinstallItems   map[string]bool
 	replaceConfigs   map[string]bool
-	sudoPassword     string
 	existingConfigs  []ExistingConfigInfo
+
+	configConflicts  []config.ConfigConflict
+	conflictChoices  map[config.ConflictSection]config.ConflictChoice
+	selectedConflict int
+
+	accessibility         map[string]bool
+	selectedAccessibility int
+
+	migration         *config.MigrationSource
+	migrationImports  map[string]bool
+	selectedMigration int
+
+	stagingDir    string
+	deployResults []config.DeploymentResult
 }
 
 func NewModel(version string) Model {
@@ -54,6 +78,7 @@ func NewModel(version string) Model {
 	pi.Focus()
 
 	logChan := make(chan string, 1000)
+	passwordRequests := make(chan privesc.PasswordRequest)
 	packageProgressChan := make(chan packageInstallProgressMsg, 100)
 
 	return Model{
@@ -61,8 +86,10 @@ func NewModel(version string) Model {
 		state:         StateWelcome,
 		spinner:       s,
 		passwordInput: pi,
-		isLoading:     true,
-		styles:        styles,
+
+		passwordRequests: passwordRequests,
+		isLoading:        true,
+		styles:           styles,
 
 		logMessages:         []string{},
 		logChan:             logChan,
@@ -78,14 +105,47 @@ func NewModel(version string) Model {
 		selectedConfig:   0,
 		reinstallItems:   make(map[string]bool),
 		replaceConfigs:   make(map[string]bool),
+		migrationImports: make(map[string]bool),
+		conflictChoices:  make(map[config.ConflictSection]config.ConflictChoice),
+		accessibility:    make(map[string]bool),
 		installationLogs: []string{},
 	}
 }
 
+// SetStagingDir makes the installer write generated configs into dir instead
+// of directly into ~/.config, for dotfile-managed systems
+func (m *Model) SetStagingDir(dir string) {
+	m.stagingDir = dir
+}
+
+// PrintStagedMapping prints where each staged config should be placed
+func (m Model) PrintStagedMapping(w io.Writer) {
+	if m.stagingDir == "" {
+		return
+	}
+
+	var staged []config.DeploymentResult
+	for _, result := range m.deployResults {
+		if result.Deployed && result.StagedPath != "" {
+			staged = append(staged, result)
+		}
+	}
+
+	if len(staged) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "Configs staged in %s:\n", m.stagingDir)
+	for _, result := range staged {
+		fmt.Fprintf(w, "  %s -> %s\n", result.StagedPath, result.Path)
+	}
+}
+
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(
 		m.spinner.Tick,
 		m.listenForLogs(),
+		m.listenForPasswordRequests(),
 		m.detectOS(),
 	)
 }
@@ -114,6 +174,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, m.listenForLogs()
 	}
 
+	if reqMsg, ok := msg.(passwordRequestMsg); ok {
+		req := privesc.PasswordRequest(reqMsg)
+		m.passwordRequest = &req
+		m.passwordInput.SetValue("")
+		m.passwordInput.Focus()
+		return m, nil
+	}
+
+	if _, ok := msg.(tea.KeyMsg); ok && m.passwordRequest != nil {
+		return m.updatePasswordPrompt(msg)
+	}
+
 	switch m.state {
 	case StateWelcome:
 		return m.updateWelcomeState(msg)
@@ -121,20 +193,26 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateSelectWindowManagerState(msg)
 	case StateSelectTerminal:
 		return m.updateSelectTerminalState(msg)
+	case StateSelectAccessibility:
+		return m.updateSelectAccessibilityState(msg)
 	case StateMissingWMInstructions:
 		return m.updateMissingWMInstructionsState(msg)
 	case StateDetectingDeps:
 		return m.updateDetectingDepsState(msg)
 	case StateDependencyReview:
 		return m.updateDependencyReviewState(msg)
-	case StatePasswordPrompt:
-		return m.updatePasswordPromptState(msg)
 	case StateInstallingPackages:
 		return m.updateInstallingPackagesState(msg)
 	case StateConfigConfirmation:
 		return m.updateConfigConfirmationState(msg)
+	case StateConfigConflicts:
+		return m.updateConfigConflictsState(msg)
+	case StateMigrationReview:
+		return m.updateMigrationReviewState(msg)
 	case StateDeployingConfigs:
 		return m.updateDeployingConfigsState(msg)
+	case StateConfiguringAccessibility:
+		return m.updateConfiguringAccessibilityState(msg)
 	case StateInstallComplete:
 		return m.updateInstallCompleteState(msg)
 	case StateError:
@@ -145,6 +223,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) View() string {
+	if m.passwordRequest != nil {
+		return m.viewPasswordPrompt()
+	}
+
 	switch m.state {
 	case StateWelcome:
 		return m.viewWelcome()
@@ -152,20 +234,26 @@ func (m Model) View() string {
 		return m.viewSelectWindowManager()
 	case StateSelectTerminal:
 		return m.viewSelectTerminal()
+	case StateSelectAccessibility:
+		return m.viewSelectAccessibility()
 	case StateMissingWMInstructions:
 		return m.viewMissingWMInstructions()
 	case StateDetectingDeps:
 		return m.viewDetectingDeps()
 	case StateDependencyReview:
 		return m.viewDependencyReview()
-	case StatePasswordPrompt:
-		return m.viewPasswordPrompt()
 	case StateInstallingPackages:
 		return m.viewInstallingPackages()
 	case StateConfigConfirmation:
 		return m.viewConfigConfirmation()
+	case StateConfigConflicts:
+		return m.viewConfigConflicts()
+	case StateMigrationReview:
+		return m.viewMigrationReview()
 	case StateDeployingConfigs:
 		return m.viewDeployingConfigs()
+	case StateConfiguringAccessibility:
+		return m.viewConfiguringAccessibility()
 	case StateInstallComplete:
 		return m.viewInstallComplete()
 	case StateError:
@@ -199,6 +287,7 @@ func (m Model) detectOS() tea.Cmd {
 			osInfoMsg.VersionID = info.VersionID
 			osInfoMsg.PrettyName = info.PrettyName
 			osInfoMsg.Architecture = info.Architecture
+			osInfoMsg.Environment = info.Environment
 		}
 		return osInfoCompleteMsg{info: osInfoMsg, err: err}
 	}
@@ -3,6 +3,7 @@ package tui
 import (
 	"github.com/AvengeMedia/danklinux/internal/deps"
 	"github.com/AvengeMedia/danklinux/internal/distros"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
 )
 
 type logMsg struct {
@@ -20,6 +21,7 @@ type depsDetectedMsg struct {
 }
 
 type packageInstallProgressMsg struct {
+	phase       distros.InstallPhase
 	progress    float64
 	step        string
 	isComplete  bool
@@ -31,7 +33,4 @@ type packageInstallProgressMsg struct {
 
 type packageProgressCompletedMsg struct{}
 
-type passwordValidMsg struct {
-	password string
-	valid    bool
-}
+type passwordRequestMsg privesc.PasswordRequest
@@ -0,0 +1,470 @@
+package tui
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/AvengeMedia/danklinux/internal/accessibility"
+	"github.com/AvengeMedia/danklinux/internal/deps"
+	"github.com/AvengeMedia/danklinux/internal/distros"
+	"github.com/AvengeMedia/danklinux/internal/privesc"
+	"github.com/charmbracelet/x/term"
+)
+
+// PlainRunner drives the install flow as sequential prompts and log lines,
+// without the alt-screen or animations, for screen readers, dumb terminals
+// and CI log capture
+type PlainRunner struct {
+	model  Model
+	input  io.Reader
+	in     *bufio.Reader
+	out    io.Writer
+	outMu  sync.Mutex
+	events *json.Encoder
+}
+
+func NewPlainRunner(model Model, in io.Reader, out io.Writer) *PlainRunner {
+	return &PlainRunner{
+		model: model,
+		input: in,
+		in:    bufio.NewReader(in),
+		out:   out,
+	}
+}
+
+func RunPlain(model Model) error {
+	return NewPlainRunner(model, os.Stdin, os.Stdout).Run()
+}
+
+// NewProgressJSONRunner is a PlainRunner that writes one distros.ProgressEvent
+// per line instead of text. Prompts are answered with one line on stdin:
+// a 1-based option number, y/n, or the password.
+func NewProgressJSONRunner(model Model, in io.Reader, out io.Writer) *PlainRunner {
+	r := NewPlainRunner(model, in, out)
+	r.events = json.NewEncoder(out)
+	return r
+}
+
+func RunProgressJSON(model Model) error {
+	r := NewProgressJSONRunner(model, os.Stdin, os.Stdout)
+	r.emit(distros.ProgressEvent{Type: "hello", Version: distros.ProgressProtocolVersion, Message: r.model.version})
+
+	if err := r.Run(); err != nil {
+		r.emit(distros.ProgressEvent{Type: "error", Error: err.Error()})
+		return err
+	}
+
+	r.emit(distros.ProgressEvent{Type: "complete"})
+	return nil
+}
+
+func (r *PlainRunner) Run() error {
+	go r.printLogs()
+
+	broker, err := privesc.StartBroker(r.promptPassword)
+	if err != nil {
+		return err
+	}
+	defer broker.Close()
+
+	r.println(fmt.Sprintf("dankinstall %s - Dank Desktop \"dotfiles\" installer", r.model.version))
+	r.println("")
+	r.println("Detecting system...")
+
+	osMsg := r.model.detectOS()().(osInfoCompleteMsg)
+	if osMsg.err != nil {
+		return fmt.Errorf("failed to detect system: %w", osMsg.err)
+	}
+	r.model.osInfo = osMsg.info
+
+	if distros.IsUnsupportedDistro(r.model.osInfo.Distribution.ID, r.model.osInfo.VersionID) {
+		return fmt.Errorf("%s is not supported", r.model.osInfo.PrettyName)
+	}
+	r.println(fmt.Sprintf("System: %s / %s", r.model.osInfo.PrettyName, r.model.osInfo.Architecture))
+	for _, line := range r.model.osInfo.Environment.Guidance() {
+		r.println("Note: " + line)
+	}
+	r.println("")
+
+	wmOptions := []string{"niri - Scrollable-tiling Wayland compositor."}
+	if r.model.osInfo.Distribution.ID != "debian" {
+		wmOptions = append(wmOptions, "Hyprland - Dynamic tiling Wayland compositor.")
+	}
+	wm, err := r.choose("Choose window manager", wmOptions)
+	if err != nil {
+		return err
+	}
+	r.model.selectedWM = wm
+
+	terminal, err := r.choose("Choose terminal emulator", []string{
+		"ghostty - A fast, native terminal emulator built in Zig.",
+		"kitty - A feature-rich, customizable terminal emulator.",
+		"alacritty - A simple terminal emulator. (No Dynamic Theming)",
+	})
+	if err != nil {
+		return err
+	}
+	r.model.selectedTerminal = terminal
+
+	for _, feature := range accessibility.Features() {
+		enable, err := r.confirm(fmt.Sprintf("Enable %s? (%s)", strings.ToLower(feature.Name), feature.Description), false)
+		if err != nil {
+			return err
+		}
+		r.model.accessibility[feature.Key] = enable
+	}
+
+	if r.model.osInfo.Distribution.ID == "nixos" {
+		var wmInstalled bool
+		if r.model.selectedWM == 0 {
+			wmInstalled = r.model.commandExists("niri")
+		} else {
+			wmInstalled = r.model.commandExists("hyprland") || r.model.commandExists("Hyprland")
+		}
+		if !wmInstalled {
+			return fmt.Errorf("the selected window manager must be installed system-wide in configuration.nix on NixOS")
+		}
+	}
+
+	r.println("Detecting dependencies...")
+	depsMsg := r.model.detectDependencies()().(depsDetectedMsg)
+	if depsMsg.err != nil {
+		return fmt.Errorf("failed to detect dependencies: %w", depsMsg.err)
+	}
+	r.model.dependencies = depsMsg.deps
+
+	r.println("")
+	r.println("Dependency review:")
+	for _, dep := range r.model.dependencies {
+		line := fmt.Sprintf("  %-25s %s", dep.Name, plainDependencyStatus(dep.Status))
+		if dep.Version != "" {
+			line += fmt.Sprintf(" (%s)", dep.Version)
+		}
+		r.println(line)
+	}
+	r.println("")
+
+	proceed, err := r.confirm("Install missing dependencies?", true)
+	if err != nil {
+		return err
+	}
+	if !proceed {
+		return fmt.Errorf("installation cancelled")
+	}
+
+	if err := r.installPackages(); err != nil {
+		return err
+	}
+
+	if err := r.deployConfigurations(); err != nil {
+		return err
+	}
+
+	if r.model.accessibilityRequested() {
+		r.println("")
+		r.println("Configuring accessibility...")
+		result := r.model.configureAccessibility()().(accessibilityResult)
+		for _, line := range result.logs {
+			r.println(line)
+		}
+		if result.err != nil {
+			r.println(fmt.Sprintf("Accessibility setup incomplete: %v", result.err))
+		}
+	}
+
+	r.println("")
+	r.println("Setup complete! All packages installed and configurations deployed.")
+	r.println("Log out and log back in to start using your new desktop environment.")
+	r.println("If you do not have a greeter, login with \"niri-session\" or \"Hyprland\".")
+
+	return nil
+}
+
+// promptPassword is the broker's prompter: privileged steps ask here when
+// sudo needs a password, in between progress lines
+func (r *PlainRunner) promptPassword(prompt string, retry bool) (string, bool, bool) {
+	if retry {
+		r.println("Incorrect password. Please try again.")
+	}
+
+	r.ask(distros.ProgressEvent{Prompt: "password", Message: "Sudo password"}, "Sudo password: ")
+	password, err := r.readPassword()
+	r.println("")
+	if err != nil || password == "" {
+		return "", false, false
+	}
+
+	remember, err := r.confirm("Remember the password for this run?", false)
+	if err != nil {
+		return "", false, false
+	}
+	return password, remember, true
+}
+
+func (r *PlainRunner) installPackages() error {
+	r.println("")
+	r.println("Installing packages...")
+
+	start := r.model.installPackages()().(packageInstallProgressMsg)
+	r.printProgress(start)
+
+	lastStep := start.step
+	for msg := range r.model.packageProgressChan {
+		if r.events != nil {
+			r.emit(msg.event())
+		} else if msg.step != lastStep {
+			r.printProgress(msg)
+			lastStep = msg.step
+		}
+		if r.events == nil && msg.commandInfo != "" {
+			r.println("$ " + msg.commandInfo)
+		}
+		if r.events == nil && msg.logOutput != "" {
+			r.println("  " + msg.logOutput)
+		}
+
+		if msg.isComplete {
+			if msg.error != nil {
+				return fmt.Errorf("installation failed: %w", msg.error)
+			}
+			r.println("Installation complete.")
+			return nil
+		}
+	}
+
+	return fmt.Errorf("installer exited without reporting completion")
+}
+
+func (r *PlainRunner) deployConfigurations() error {
+	r.println("")
+	r.println("Checking existing configurations...")
+
+	checkMsg := r.model.checkExistingConfigurations()().(configCheckResult)
+	if checkMsg.error != nil {
+		return checkMsg.error
+	}
+
+	for _, configInfo := range checkMsg.configs {
+		if !configInfo.Exists {
+			continue
+		}
+		replace, err := r.confirm(fmt.Sprintf("Replace existing %s configuration at %s? (a backup will be created)", configInfo.ConfigType, configInfo.Path), true)
+		if err != nil {
+			return err
+		}
+		r.model.replaceConfigs[configInfo.ConfigType] = replace
+	}
+
+	r.model.existingConfigs = checkMsg.configs
+	r.model.configConflicts = checkMsg.conflicts
+	if r.model.replacesCustomizedCompositor() {
+		options := make([]string, len(conflictChoiceOrder))
+		for i, choice := range conflictChoiceOrder {
+			options[i] = choice.String()
+		}
+		for _, conflict := range checkMsg.conflicts {
+			title := fmt.Sprintf("%s: %d of your %d entries aren't in DMS", conflictSectionNames[conflict.Section], len(conflict.Mine), conflict.Total)
+			choice, err := r.choose(title, options)
+			if err != nil {
+				return err
+			}
+			r.model.conflictChoices[conflict.Section] = conflictChoiceOrder[choice]
+		}
+	}
+
+	r.model.migration = checkMsg.migration
+	for _, item := range r.model.migrationItems() {
+		importItem, err := r.confirm(fmt.Sprintf("Import %s from existing setup (%s)?", strings.ToLower(item.name), item.description), true)
+		if err != nil {
+			return err
+		}
+		r.model.migrationImports[item.key] = importItem
+	}
+
+	r.println("Deploying configurations...")
+	result := r.model.deployConfigurations()().(configDeploymentResult)
+	if result.error != nil {
+		return result.error
+	}
+
+	r.model.deployResults = result.results
+	for _, deployResult := range result.results {
+		if !deployResult.Deployed {
+			continue
+		}
+		line := fmt.Sprintf("%s configuration deployed", deployResult.ConfigType)
+		if deployResult.StagedPath != "" {
+			line = fmt.Sprintf("%s configuration staged: %s -> %s", deployResult.ConfigType, deployResult.StagedPath, deployResult.Path)
+		}
+		if deployResult.BackupPath != "" {
+			line += fmt.Sprintf(" (backup: %s)", deployResult.BackupPath)
+		}
+		r.println(line)
+	}
+
+	return nil
+}
+
+func (r *PlainRunner) choose(title string, options []string) (int, error) {
+	if len(options) == 1 {
+		r.println(fmt.Sprintf("%s: %s", title, options[0]))
+		return 0, nil
+	}
+
+	if r.events == nil {
+		r.println(title + ":")
+		for i, option := range options {
+			r.println(fmt.Sprintf("  %d) %s", i+1, option))
+		}
+	}
+
+	for {
+		r.ask(distros.ProgressEvent{Prompt: "choice", Message: title, Options: options, Default: "1"}, fmt.Sprintf("Enter choice (1-%d) [1]: ", len(options)))
+		response, err := r.readLine()
+		if err != nil {
+			return 0, err
+		}
+		if response == "" {
+			r.println("")
+			return 0, nil
+		}
+
+		choice, err := strconv.Atoi(response)
+		if err == nil && choice >= 1 && choice <= len(options) {
+			r.println("")
+			return choice - 1, nil
+		}
+		r.println("Invalid choice.")
+	}
+}
+
+func (r *PlainRunner) confirm(question string, defaultYes bool) (bool, error) {
+	suffix, def := " [y/N]: ", "n"
+	if defaultYes {
+		suffix, def = " [Y/n]: ", "y"
+	}
+
+	for {
+		r.ask(distros.ProgressEvent{Prompt: "confirm", Message: question, Default: def}, question+suffix)
+		response, err := r.readLine()
+		if err != nil {
+			return false, err
+		}
+
+		switch strings.ToLower(response) {
+		case "":
+			return defaultYes, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		r.println("Please answer y or n.")
+	}
+}
+
+func (r *PlainRunner) readLine() (string, error) {
+	line, err := r.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("error reading input: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func (r *PlainRunner) readPassword() (string, error) {
+	if f, ok := r.input.(*os.File); ok && r.in.Buffered() == 0 && term.IsTerminal(f.Fd()) {
+		password, err := term.ReadPassword(f.Fd())
+		if err != nil {
+			return "", err
+		}
+		return string(password), nil
+	}
+	return r.readLine()
+}
+
+func (r *PlainRunner) printProgress(msg packageInstallProgressMsg) {
+	if r.events != nil {
+		r.emit(msg.event())
+		return
+	}
+	r.println(fmt.Sprintf("[%3.0f%%] %s", msg.progress*100, msg.step))
+}
+
+func (r *PlainRunner) printLogs() {
+	for msg := range r.model.logChan {
+		if r.events != nil {
+			r.emit(distros.ProgressEvent{Type: "log", Message: msg})
+			continue
+		}
+		r.println(msg)
+	}
+}
+
+// ask shows a prompt as text, or as a prompt event in JSON mode
+func (r *PlainRunner) ask(event distros.ProgressEvent, text string) {
+	if r.events != nil {
+		event.Type = "prompt"
+		r.emit(event)
+		return
+	}
+	r.print(text)
+}
+
+func (r *PlainRunner) emit(event distros.ProgressEvent) {
+	r.outMu.Lock()
+	defer r.outMu.Unlock()
+	r.events.Encode(event)
+}
+
+func (r *PlainRunner) print(s string) {
+	r.outMu.Lock()
+	defer r.outMu.Unlock()
+	fmt.Fprint(r.out, s)
+}
+
+func (r *PlainRunner) println(s string) {
+	if r.events != nil {
+		if s != "" {
+			r.emit(distros.ProgressEvent{Type: "message", Message: s})
+		}
+		return
+	}
+
+	r.outMu.Lock()
+	defer r.outMu.Unlock()
+	fmt.Fprintln(r.out, s)
+}
+
+func plainDependencyStatus(status deps.DependencyStatus) string {
+	switch status {
+	case deps.StatusInstalled:
+		return "Already installed"
+	case deps.StatusMissing:
+		return "Will be installed"
+	case deps.StatusNeedsUpdate:
+		return "Needs update"
+	case deps.StatusNeedsReinstall:
+		return "Needs reinstall"
+	default:
+		return "Unknown"
+	}
+}
+
+func (msg packageInstallProgressMsg) event() distros.ProgressEvent {
+	return distros.InstallProgressMsg{
+		Phase:       msg.phase,
+		Progress:    msg.progress,
+		Step:        msg.step,
+		IsComplete:  msg.isComplete,
+		NeedsSudo:   msg.needsSudo,
+		CommandInfo: msg.commandInfo,
+		LogOutput:   msg.logOutput,
+		Error:       msg.error,
+	}.Event()
+}
@@ -6,13 +6,16 @@ const (
 	StateWelcome ApplicationState = iota
 	StateSelectWindowManager
 	StateSelectTerminal
+	StateSelectAccessibility
 	StateMissingWMInstructions
 	StateDetectingDeps
 	StateDependencyReview
-	StatePasswordPrompt
 	StateInstallingPackages
 	StateConfigConfirmation
+	StateConfigConflicts
+	StateMigrationReview
 	StateDeployingConfigs
+	StateConfiguringAccessibility
 	StateInstallComplete
 	StateFinalComplete
 	StateError
@@ -0,0 +1,161 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/accessibility"
+	"github.com/AvengeMedia/danklinux/internal/distros"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type accessibilityResult struct {
+	logs []string
+	err  error
+}
+
+func (m Model) accessibilityRequested() bool {
+	for _, selected := range m.accessibility {
+		if selected {
+			return true
+		}
+	}
+	return false
+}
+
+func (m Model) viewSelectAccessibility() string {
+	var b strings.Builder
+
+	b.WriteString(m.renderBanner())
+	b.WriteString("\n")
+
+	title := m.styles.Title.Render("Accessibility (Optional)")
+	b.WriteString(title)
+	b.WriteString("\n\n")
+
+	for i, feature := range accessibility.Features() {
+		marker := "[ ]"
+		if m.accessibility[feature.Key] {
+			marker = "[x]"
+		}
+
+		var line string
+		if i == m.selectedAccessibility {
+			line = fmt.Sprintf("▶ %s %-15s", marker, feature.Name)
+			line += fmt.Sprintf("\n      %s", feature.Description)
+			line = m.styles.SelectedOption.Render(line)
+		} else {
+			line = fmt.Sprintf("  %s %-15s", marker, feature.Name)
+			line += fmt.Sprintf("\n      %s", feature.Description)
+			line = m.styles.Normal.Render(line)
+		}
+
+		b.WriteString(line)
+		b.WriteString("\n\n")
+	}
+
+	info := m.styles.Subtle.Render("Selected options are applied after the configurations are deployed")
+	b.WriteString(info)
+	b.WriteString("\n\n")
+
+	help := m.styles.Subtle.Render("↑/↓: Navigate, Space: Toggle, Enter: Continue, Esc: Back")
+	b.WriteString(help)
+
+	return b.String()
+}
+
+func (m Model) updateSelectAccessibilityState(msg tea.Msg) (tea.Model, tea.Cmd) {
+	features := accessibility.Features()
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "up":
+			if m.selectedAccessibility > 0 {
+				m.selectedAccessibility--
+			}
+		case "down":
+			if m.selectedAccessibility < len(features)-1 {
+				m.selectedAccessibility++
+			}
+		case " ":
+			key := features[m.selectedAccessibility].Key
+			m.accessibility[key] = !m.accessibility[key]
+		case "enter":
+			// On NixOS, check if the selected WM is actually installed
+			if m.osInfo != nil && m.osInfo.Distribution.ID == "nixos" {
+				var wmInstalled bool
+				if m.selectedWM == 0 {
+					wmInstalled = m.commandExists("niri")
+				} else {
+					wmInstalled = m.commandExists("hyprland") || m.commandExists("Hyprland")
+				}
+
+				if !wmInstalled {
+					m.state = StateMissingWMInstructions
+					return m, m.listenForLogs()
+				}
+			}
+
+			m.state = StateDetectingDeps
+			m.isLoading = true
+			return m, tea.Batch(m.spinner.Tick, m.detectDependencies())
+		case "esc":
+			m.state = StateSelectTerminal
+			return m, m.listenForLogs()
+		}
+	}
+	return m, m.listenForLogs()
+}
+
+func (m Model) viewConfiguringAccessibility() string {
+	var b strings.Builder
+
+	b.WriteString(m.renderBanner())
+	b.WriteString("\n")
+
+	title := m.styles.Title.Render("Configuring Accessibility")
+	b.WriteString(title)
+	b.WriteString("\n\n")
+
+	spinner := m.spinner.View()
+	status := m.styles.Normal.Render("Applying accessibility settings...")
+	b.WriteString(fmt.Sprintf("%s %s", spinner, status))
+
+	return b.String()
+}
+
+func (m Model) updateConfiguringAccessibilityState(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if result, ok := msg.(accessibilityResult); ok {
+		m.installationLogs = append(m.installationLogs, result.logs...)
+		// Accessibility is optional, so a failure here shouldn't fail the
+		// whole install
+		if result.err != nil {
+			m.installationLogs = append(m.installationLogs, fmt.Sprintf("⚠ Accessibility setup incomplete: %v", result.err))
+		}
+
+		m.state = StateInstallComplete
+		m.isLoading = false
+		return m, nil
+	}
+
+	return m, m.listenForLogs()
+}
+
+func (m Model) configureAccessibility() tea.Cmd {
+	return func() tea.Msg {
+		var family distros.DistroFamily
+		if m.osInfo != nil {
+			if config, exists := distros.Registry[m.osInfo.Distribution.ID]; exists {
+				family = config.Family
+			}
+		}
+
+		var logs []string
+		err := accessibility.Setup(context.Background(), m.accessibility, family, accessibility.DefaultPaths(), func(line string) {
+			logs = append(logs, line)
+		})
+
+		return accessibilityResult{logs: logs, err: err}
+	}
+}
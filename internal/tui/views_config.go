@@ -24,8 +24,10 @@ type ExistingConfigInfo struct {
 }
 
 type configCheckResult struct {
-	configs []ExistingConfigInfo
-	error   error
+	configs   []ExistingConfigInfo
+	conflicts []config.ConfigConflict
+	migration *config.MigrationSource
+	error     error
 }
 
 func (m Model) viewDeployingConfigs() string {
@@ -82,9 +84,13 @@ func (m Model) updateDeployingConfigsState(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		m.deployResults = result.results
 		for _, deployResult := range result.results {
 			if deployResult.Deployed {
 				logMsg := fmt.Sprintf("✓ %s configuration deployed", deployResult.ConfigType)
+				if deployResult.StagedPath != "" {
+					logMsg = fmt.Sprintf("✓ %s configuration staged: %s → %s", deployResult.ConfigType, deployResult.StagedPath, deployResult.Path)
+				}
 				if deployResult.BackupPath != "" {
 					logMsg += fmt.Sprintf(" (backup: %s)", deployResult.BackupPath)
 				}
@@ -92,6 +98,11 @@ func (m Model) updateDeployingConfigsState(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		if m.accessibilityRequested() {
+			m.state = StateConfiguringAccessibility
+			return m, tea.Batch(m.spinner.Tick, m.configureAccessibility())
+		}
+
 		m.state = StateInstallComplete
 		m.isLoading = false
 		return m, nil
@@ -100,33 +111,36 @@ func (m Model) updateDeployingConfigsState(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, m.listenForLogs()
 }
 
+// deployedTerminal is the terminal the deployed configs launch. Only Ghostty
+// and Kitty ship configs here, so any other choice gets Ghostty's
+func (m Model) deployedTerminal() deps.Terminal {
+	switch m.selectedTerminal {
+	case 1:
+		return deps.TerminalKitty
+	default:
+		return deps.TerminalGhostty
+	}
+}
+
 func (m Model) deployConfigurations() tea.Cmd {
 	return func() tea.Msg {
-		// Determine the selected window manager
-		var wm deps.WindowManager
-		switch m.selectedWM {
-		case 0:
-			wm = deps.WindowManagerNiri
-		case 1:
-			wm = deps.WindowManagerHyprland
-		default:
-			wm = deps.WindowManagerNiri
-		}
-
-		// Determine the selected terminal
-		var terminal deps.Terminal
-		switch m.selectedTerminal {
-		case 0:
-			terminal = deps.TerminalGhostty
-		case 1:
-			terminal = deps.TerminalKitty
-		default:
-			terminal = deps.TerminalGhostty
-		}
+		wm := m.getSelectedWM()
+		terminal := m.deployedTerminal()
 
+		migration := m.selectedMigrationImports()
 		deployer := config.NewConfigDeployer(m.logChan)
+		deployer.SetMigration(migration)
+		if m.stagingDir != "" {
+			deployer.SetStagingDir(m.stagingDir)
+		}
+		if m.replacesCustomizedCompositor() {
+			deployer.SetConflictChoices(m.conflictChoices)
+		}
 
 		results, err := deployer.DeployConfigurationsSelectiveWithReinstalls(context.Background(), wm, terminal, m.dependencies, m.replaceConfigs, m.reinstallItems)
+		if err == nil && migration != nil && migration.NetworkBundle != "" {
+			m.importNetworkBundle(migration.NetworkBundle)
+		}
 
 		return configDeploymentResult{
 			results: results,
@@ -207,6 +221,8 @@ func (m Model) updateConfigConfirmationState(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		m.existingConfigs = result.configs
+		m.configConflicts = result.conflicts
+		m.migration = result.migration
 
 		firstExistingSet := false
 		for i, config := range result.configs {
@@ -229,8 +245,7 @@ func (m Model) updateConfigConfirmationState(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		if !hasExisting {
 			// No existing configs, proceed directly to deployment
-			m.state = StateDeployingConfigs
-			return m, m.deployConfigurations()
+			return m.continueToDeployment()
 		}
 
 		// Show confirmation view
@@ -265,8 +280,7 @@ func (m Model) updateConfigConfirmationState(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 		case "enter":
-			m.state = StateDeployingConfigs
-			return m, m.deployConfigurations()
+			return m.continueToDeployment()
 		}
 	}
 
@@ -326,8 +340,10 @@ func (m Model) checkExistingConfigurations() tea.Cmd {
 		}
 
 		return configCheckResult{
-			configs: configs,
-			error:   nil,
+			configs:   configs,
+			conflicts: m.detectConfigConflicts(configs),
+			migration: config.DetectMigrationSource(os.Getenv("HOME")),
+			error:     nil,
 		}
 	}
 }
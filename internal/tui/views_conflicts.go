@@ -0,0 +1,153 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// conflictChoiceOrder is the order Space cycles through; the first is the
+// default and matches a plain replace
+var conflictChoiceOrder = []config.ConflictChoice{config.ConflictUseDMS, config.ConflictKeepMine, config.ConflictCommentBoth}
+
+// conflictPreviewLines caps the entries shown for the selected section
+const conflictPreviewLines = 4
+
+var conflictSectionNames = map[config.ConflictSection]string{
+	config.ConflictBinds:   "Keybinds",
+	config.ConflictStartup: "Startup programs",
+	config.ConflictEnv:     "Environment",
+}
+
+func existingCompositorConfig(configs []ExistingConfigInfo) (ExistingConfigInfo, bool) {
+	for _, info := range configs {
+		if (info.ConfigType == "Niri" || info.ConfigType == "Hyprland") && info.Exists {
+			return info, true
+		}
+	}
+	return ExistingConfigInfo{}, false
+}
+
+// replacesCustomizedCompositor reports whether the compositor config is set
+// to be replaced while it has sections worth resolving
+func (m Model) replacesCustomizedCompositor() bool {
+	info, ok := existingCompositorConfig(m.existingConfigs)
+	return ok && m.replaceConfigs[info.ConfigType] && len(m.configConflicts) > 0
+}
+
+// detectConfigConflicts reads the existing compositor config and compares
+// it with the template. A config that can't be read has no conflicts; the
+// deployer reports the problem
+func (m Model) detectConfigConflicts(configs []ExistingConfigInfo) []config.ConfigConflict {
+	info, ok := existingCompositorConfig(configs)
+	if !ok {
+		return nil
+	}
+	data, err := os.ReadFile(info.Path)
+	if err != nil {
+		return nil
+	}
+	conflicts, err := config.DetectConfigConflicts(m.getSelectedWM(), m.deployedTerminal(), string(data))
+	if err != nil {
+		return nil
+	}
+	return conflicts
+}
+
+func (m Model) viewConfigConflicts() string {
+	var b strings.Builder
+
+	b.WriteString(m.renderBanner())
+	b.WriteString("\n")
+
+	title := m.styles.Title.Render("Resolve Config Conflicts")
+	b.WriteString(title)
+	b.WriteString("\n\n")
+
+	info, _ := existingCompositorConfig(m.existingConfigs)
+	intro := m.styles.Normal.Render(fmt.Sprintf("Your %s config at %s customizes these sections.\nChoose what the new config keeps:", info.ConfigType, info.Path))
+	b.WriteString(intro)
+	b.WriteString("\n\n")
+
+	for i, conflict := range m.configConflicts {
+		choice := m.conflictChoices[conflict.Section]
+		var status string
+		switch choice {
+		case config.ConflictKeepMine:
+			status = m.styles.Success.Render(choice.String())
+		case config.ConflictCommentBoth:
+			status = m.styles.Warning.Render(choice.String())
+		default:
+			status = m.styles.Normal.Render(choice.String())
+		}
+
+		name := conflictSectionNames[conflict.Section]
+		summary := fmt.Sprintf("%d of your %d entries aren't in DMS", len(conflict.Mine), conflict.Total)
+
+		var line string
+		if i == m.selectedConflict {
+			line = fmt.Sprintf("▶ %-18s %s", name, status)
+			line += fmt.Sprintf("\n    %s", summary)
+			for j, entry := range conflict.Mine {
+				if j == conflictPreviewLines {
+					line += fmt.Sprintf("\n      … and %d more", len(conflict.Mine)-j)
+					break
+				}
+				line += fmt.Sprintf("\n      %s", entry)
+			}
+			line = m.styles.SelectedOption.Render(line)
+		} else {
+			line = fmt.Sprintf("  %-18s %s", name, status)
+			line += fmt.Sprintf("\n    %s", summary)
+			line = m.styles.Normal.Render(line)
+		}
+
+		b.WriteString(line)
+		b.WriteString("\n\n")
+	}
+
+	legend := m.styles.Subtle.Render("Use DMS: the DMS defaults • Keep mine: your entries in their place • Comment both: both commented out, to merge by hand")
+	b.WriteString(legend)
+	b.WriteString("\n\n")
+
+	help := m.styles.Subtle.Render("↑/↓: Navigate, Space: Change choice, Enter: Continue")
+	b.WriteString(help)
+
+	return b.String()
+}
+
+func (m Model) updateConfigConflictsState(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "up":
+			if m.selectedConflict > 0 {
+				m.selectedConflict--
+			}
+		case "down":
+			if m.selectedConflict < len(m.configConflicts)-1 {
+				m.selectedConflict++
+			}
+		case " ":
+			if m.selectedConflict < len(m.configConflicts) {
+				section := m.configConflicts[m.selectedConflict].Section
+				m.conflictChoices[section] = nextConflictChoice(m.conflictChoices[section])
+			}
+		case "enter":
+			return m.continueToDeployment()
+		}
+	}
+
+	return m, nil
+}
+
+func nextConflictChoice(choice config.ConflictChoice) config.ConflictChoice {
+	for i, c := range conflictChoiceOrder {
+		if c == choice {
+			return conflictChoiceOrder[(i+1)%len(conflictChoiceOrder)]
+		}
+	}
+	return conflictChoiceOrder[0]
+}
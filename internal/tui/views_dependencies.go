@@ -47,6 +47,8 @@ func (m Model) viewDependencyReview() string {
 
 			if dep.CanToggle && dep.Variant == deps.VariantGit {
 				variantMarker = "[git] "
+			} else if dep.Variant == deps.VariantBinary {
+				variantMarker = "[bin] "
 			}
 
 			if m.reinstallItems[dep.Name] {
@@ -98,7 +100,7 @@ func (m Model) viewDependencyReview() string {
 	}
 
 	b.WriteString("\n")
-	help := m.styles.Subtle.Render("↑/↓: Navigate, Space: Toggle reinstall, G: Toggle stable/git, Enter: Continue")
+	help := m.styles.Subtle.Render("↑/↓: Navigate, Space: Toggle reinstall, G: Toggle stable/git, B: Toggle release binary, Enter: Continue")
 	b.WriteString(help)
 
 	return b.String()
@@ -147,10 +149,21 @@ func (m Model) updateDependencyReviewState(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.dependencies[m.selectedDep].Variant = deps.VariantStable
 				}
 			}
+		case "b", "B":
+			if len(m.dependencies) > 0 && distros.SupportsReleaseBinary(m.dependencies[m.selectedDep].Name) {
+				if m.dependencies[m.selectedDep].Variant == deps.VariantBinary {
+					m.dependencies[m.selectedDep].Variant = deps.VariantStable
+				} else {
+					m.dependencies[m.selectedDep].Variant = deps.VariantBinary
+				}
+			}
 		case "enter":
-			m.state = StatePasswordPrompt
-			m.isLoading = false
-			return m, nil
+			// Privileged steps ask for a password through the broker
+			// when they need one
+			m.packageProgress = packageInstallProgressMsg{}
+			m.state = StateInstallingPackages
+			m.isLoading = true
+			return m, tea.Batch(m.spinner.Tick, m.installPackages())
 		case "esc":
 			m.state = StateSelectWindowManager
 			return m, nil
@@ -178,19 +191,17 @@ func (m Model) installPackages() tea.Cmd {
 			}
 		}
 
-		// Convert TUI selection to deps enum
-		var wm deps.WindowManager
-		if m.selectedWM == 0 {
-			wm = deps.WindowManagerNiri
-		} else {
-			wm = deps.WindowManagerHyprland
-		}
+		wm := m.getSelectedWM()
 
 		installerProgressChan := make(chan distros.InstallProgressMsg, 100)
 
 		go func() {
 			defer close(installerProgressChan)
-			err := installer.InstallPackages(context.Background(), m.dependencies, wm, m.sudoPassword, m.reinstallItems, installerProgressChan)
+			binaries, dependencies := distros.SplitReleaseBinaries(m.dependencies, m.reinstallItems)
+			err := distros.InstallReleaseBinaries(context.Background(), binaries, installerProgressChan, m.logChan)
+			if err == nil {
+				err = installer.InstallPackages(context.Background(), dependencies, wm, m.reinstallItems, installerProgressChan)
+			}
 			if err != nil {
 				installerProgressChan <- distros.InstallProgressMsg{
 					Progress:   0.0,
@@ -205,6 +216,7 @@ func (m Model) installPackages() tea.Cmd {
 		go func() {
 			for msg := range installerProgressChan {
 				tuiMsg := packageInstallProgressMsg{
+					phase:       msg.Phase,
 					progress:    msg.Progress,
 					step:        msg.Step,
 					isComplete:  msg.IsComplete,
@@ -101,7 +101,7 @@ func (m Model) viewInstallingPackages() string {
 
 		// Show sudo prompt if needed
 		if m.packageProgress.needsSudo {
-			sudoWarning := m.styles.Warning.Render("⚠ Using provided sudo password")
+			sudoWarning := m.styles.Warning.Render("⚠ Running with sudo privileges")
 			b.WriteString(sudoWarning)
 		}
 	} else {
@@ -145,6 +145,18 @@ func (m Model) viewInstallComplete() string {
 		b.WriteString("\n")
 	}
 
+	if m.stagingDir != "" {
+		b.WriteString("\n")
+		b.WriteString(m.styles.Normal.Render("Configs were staged in " + m.stagingDir + ":"))
+		b.WriteString("\n")
+		for _, result := range m.deployResults {
+			if result.Deployed && result.StagedPath != "" {
+				b.WriteString(m.styles.Subtle.Render(fmt.Sprintf("  %s → %s", result.StagedPath, result.Path)))
+				b.WriteString("\n")
+			}
+		}
+	}
+
 	b.WriteString("\n")
 	info := m.styles.Normal.Render("Your system is ready! Log out and log back in to start using\nyour new desktop environment.\nIf you do not have a greeter, login with \"niri-session\" or \"Hyprland\" \n\nPress Enter to exit.")
 	b.WriteString(info)
@@ -0,0 +1,214 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AvengeMedia/danklinux/internal/config"
+	"github.com/AvengeMedia/danklinux/internal/server/network"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	migrationKeybinds  = "keybinds"
+	migrationMonitors  = "monitors"
+	migrationWallpaper = "wallpaper"
+	migrationNetwork   = "network"
+)
+
+type migrationItem struct {
+	key         string
+	name        string
+	description string
+}
+
+func (m Model) migrationItems() []migrationItem {
+	var items []migrationItem
+	if m.migration == nil {
+		return items
+	}
+
+	if len(m.migration.Keybinds) > 0 {
+		items = append(items, migrationItem{
+			key:         migrationKeybinds,
+			name:        "Keybinds",
+			description: fmt.Sprintf("%d custom launcher bind(s)", len(m.migration.Keybinds)),
+		})
+	}
+	if len(m.migration.Monitors) > 0 {
+		items = append(items, migrationItem{
+			key:         migrationMonitors,
+			name:        "Monitor layout",
+			description: fmt.Sprintf("%d monitor(s)", len(m.migration.Monitors)),
+		})
+	}
+	if m.migration.Wallpaper != "" {
+		items = append(items, migrationItem{
+			key:         migrationWallpaper,
+			name:        "Wallpaper",
+			description: m.migration.Wallpaper,
+		})
+	}
+	if m.migration.NetworkBundle != "" {
+		description := m.migration.NetworkBundle
+		if manifest, err := network.ReadBundleManifest(m.migration.NetworkBundle); err == nil {
+			description = fmt.Sprintf("%d connection profile(s) from %s", len(manifest.Profiles), m.migration.NetworkBundle)
+		}
+		items = append(items, migrationItem{
+			key:         migrationNetwork,
+			name:        "Network profiles",
+			description: description,
+		})
+	}
+
+	return items
+}
+
+func (m Model) continueToDeployment() (tea.Model, tea.Cmd) {
+	if m.state == StateConfigConfirmation && m.replacesCustomizedCompositor() {
+		m.selectedConflict = 0
+		m.state = StateConfigConflicts
+		return m, nil
+	}
+
+	if m.state != StateMigrationReview && m.migration.HasImports() {
+		for _, item := range m.migrationItems() {
+			if _, exists := m.migrationImports[item.key]; !exists {
+				m.migrationImports[item.key] = true
+			}
+		}
+		m.selectedMigration = 0
+		m.state = StateMigrationReview
+		return m, nil
+	}
+
+	m.state = StateDeployingConfigs
+	return m, m.deployConfigurations()
+}
+
+func (m Model) selectedMigrationImports() *config.MigrationSource {
+	if m.migration == nil {
+		return nil
+	}
+
+	selected := &config.MigrationSource{Setups: m.migration.Setups}
+	if m.migrationImports[migrationKeybinds] {
+		selected.Keybinds = m.migration.Keybinds
+	}
+	if m.migrationImports[migrationMonitors] {
+		selected.Monitors = m.migration.Monitors
+	}
+	if m.migrationImports[migrationWallpaper] {
+		selected.Wallpaper = m.migration.Wallpaper
+	}
+	if m.migrationImports[migrationNetwork] {
+		selected.NetworkBundle = m.migration.NetworkBundle
+	}
+
+	if !selected.HasImports() {
+		return nil
+	}
+	return selected
+}
+
+func (m Model) viewMigrationReview() string {
+	var b strings.Builder
+
+	b.WriteString(m.renderBanner())
+	b.WriteString("\n")
+
+	title := m.styles.Title.Render("Import Existing Setup")
+	b.WriteString(title)
+	b.WriteString("\n\n")
+
+	if len(m.migration.Setups) > 0 {
+		b.WriteString(m.styles.Normal.Render("Detected setups:"))
+		b.WriteString("\n")
+		for _, setup := range m.migration.Setups {
+			b.WriteString(m.styles.Subtle.Render(fmt.Sprintf("  • %s (%s)", setup.Kind, setup.Path)))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	for i, item := range m.migrationItems() {
+		marker := "[ ]"
+		if m.migrationImports[item.key] {
+			marker = "[x]"
+		}
+
+		var line string
+		if i == m.selectedMigration {
+			line = fmt.Sprintf("▶ %s %-15s", marker, item.name)
+			line += fmt.Sprintf("\n      %s", item.description)
+			line = m.styles.SelectedOption.Render(line)
+		} else {
+			line = fmt.Sprintf("  %s %-15s", marker, item.name)
+			line += fmt.Sprintf("\n      %s", item.description)
+			line = m.styles.Normal.Render(line)
+		}
+
+		b.WriteString(line)
+		b.WriteString("\n\n")
+	}
+
+	info := m.styles.Subtle.Render("Selected pieces are imported into the DMS templates instead of being discarded")
+	b.WriteString(info)
+	b.WriteString("\n")
+	if m.migration.NetworkBundle != "" {
+		note := m.styles.Subtle.Render("Network profiles are added without passwords; run dms ipc network import-bundle later to restore them")
+		b.WriteString(note)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	help := m.styles.Subtle.Render("↑/↓: Navigate, Space: Toggle import, Enter: Continue")
+	b.WriteString(help)
+
+	return b.String()
+}
+
+func (m Model) updateMigrationReviewState(msg tea.Msg) (tea.Model, tea.Cmd) {
+	items := m.migrationItems()
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "up":
+			if m.selectedMigration > 0 {
+				m.selectedMigration--
+			}
+		case "down":
+			if m.selectedMigration < len(items)-1 {
+				m.selectedMigration++
+			}
+		case " ":
+			if m.selectedMigration < len(items) {
+				key := items[m.selectedMigration].key
+				m.migrationImports[key] = !m.migrationImports[key]
+			}
+		case "enter":
+			return m.continueToDeployment()
+		}
+	}
+
+	return m, nil
+}
+
+// importNetworkBundle adds the profiles of a bundle from the previous
+// install. Without the passphrase secrets stay out, and profiles already
+// saved on this install are kept
+func (m Model) importNetworkBundle(path string) {
+	result, err := network.ImportBundleFile(path, "", network.ConflictSkip)
+	if err != nil {
+		m.logChan <- fmt.Sprintf("Failed to import network profiles from %s: %v", path, err)
+		return
+	}
+
+	m.logChan <- fmt.Sprintf("Imported %d network profile(s) from %s", len(result.Imported), path)
+	for id, reason := range result.Failed {
+		m.logChan <- fmt.Sprintf("Failed to import network profile %s: %s", id, reason)
+	}
+	if result.SecretsSkipped {
+		m.logChan <- fmt.Sprintf("Run dms ipc network import-bundle %s replace with the bundle's passphrase to restore passwords", path)
+	}
+}
@@ -120,24 +120,8 @@ func (m Model) updateSelectTerminalState(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.selectedTerminal++
 			}
 		case "enter":
-			// On NixOS, check if the selected WM is actually installed
-			if m.osInfo != nil && m.osInfo.Distribution.ID == "nixos" {
-				var wmInstalled bool
-				if m.selectedWM == 0 {
-					wmInstalled = m.commandExists("niri")
-				} else {
-					wmInstalled = m.commandExists("hyprland") || m.commandExists("Hyprland")
-				}
-
-				if !wmInstalled {
-					m.state = StateMissingWMInstructions
-					return m, m.listenForLogs()
-				}
-			}
-
-			m.state = StateDetectingDeps
-			m.isLoading = true
-			return m, tea.Batch(m.spinner.Tick, m.detectDependencies())
+			m.state = StateSelectAccessibility
+			return m, m.listenForLogs()
 		case "esc":
 			// Go back to window manager selection
 			m.state = StateSelectWindowManager
@@ -191,14 +175,6 @@ func (m Model) detectDependencies() tea.Cmd {
 			return depsDetectedMsg{deps: nil, err: err}
 		}
 
-		// Convert TUI selection to deps enum
-		var wm deps.WindowManager
-		if m.selectedWM == 0 {
-			wm = deps.WindowManagerNiri // First option is Niri
-		} else {
-			wm = deps.WindowManagerHyprland // Second option is Hyprland
-		}
-
 		// Convert TUI terminal selection to deps enum
 		var terminal deps.Terminal
 		if m.selectedTerminal == 0 {
@@ -209,7 +185,11 @@ func (m Model) detectDependencies() tea.Cmd {
 			terminal = deps.TerminalAlacritty
 		}
 
-		dependencies, err := detector.DetectDependenciesWithTerminal(context.Background(), wm, terminal)
+		dependencies, err := detector.DetectDependenciesWithTerminal(context.Background(), m.getSelectedWM(), terminal)
+		if err == nil && m.osInfo.Environment.Headless() {
+			m.logChan <- fmt.Sprintf("Headless environment (%s): skipping compositor and GUI dependencies", m.osInfo.Environment.Kind)
+			dependencies = distros.FilterHeadlessDependencies(dependencies)
+		}
 		return depsDetectedMsg{deps: dependencies, err: err}
 	}
 }
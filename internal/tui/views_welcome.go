@@ -101,6 +101,16 @@ func (m Model) viewWelcome() string {
 			b.WriteString(sysBox.Render(sysInfo))
 			b.WriteString("\n")
 
+			if guidance := m.osInfo.Environment.Guidance(); len(guidance) > 0 {
+				warnStyle := lipgloss.NewStyle().
+					Foreground(lipgloss.Color(theme.Warning))
+				for _, line := range guidance {
+					b.WriteString(warnStyle.Render("⚠ " + line))
+					b.WriteString("\n")
+				}
+				b.WriteString("\n")
+			}
+
 			// Feature list with better styling
 			featTitle := lipgloss.NewStyle().
 				Foreground(lipgloss.Color(theme.Primary)).
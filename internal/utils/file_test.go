@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	require.NoError(t, WriteFileAtomic(path, []byte("one"), 0600))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "one", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	require.NoError(t, os.Chmod(path, 0640))
+	require.NoError(t, WriteFileAtomic(path, []byte("two"), 0644))
+	data, _ = os.ReadFile(path)
+	assert.Equal(t, "two", string(data))
+	info, _ = os.Stat(path)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm(), "an existing file keeps its mode")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary file is left behind")
+}
+
+func TestWriteFileAtomicFollowsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "kitty.conf")
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0755))
+	require.NoError(t, os.WriteFile(target, []byte("old"), 0644))
+	link := filepath.Join(dir, "kitty.conf")
+	require.NoError(t, os.Symlink(target, link))
+
+	require.NoError(t, WriteFileAtomic(link, []byte("new"), 0644))
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&os.ModeSymlink, "the link stays a link")
+	data, _ := os.ReadFile(target)
+	assert.Equal(t, "new", string(data))
+}
+
+func TestWriteFileAtomicValidated(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.kdl")
+	require.NoError(t, os.WriteFile(path, []byte("good"), 0644))
+
+	err := WriteFileAtomicValidated(path, []byte("bad"), 0644, func(tmp string) error {
+		data, _ := os.ReadFile(tmp)
+		assert.Equal(t, "bad", string(data))
+		return errors.New("rejected")
+	})
+	assert.EqualError(t, err, "rejected")
+
+	data, _ := os.ReadFile(path)
+	assert.Equal(t, "good", string(data))
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 1)
+}
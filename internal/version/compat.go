@@ -0,0 +1,194 @@
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// CompatFile sits at the root of the DMS shell repo and declares what that
+// revision needs from the other components, e.g.
+//
+//	{"minApiVersion": 12, "minQuickshell": "0.2.0"}
+//
+// Revisions without it declare nothing and aren't checked
+const CompatFile = "compat.json"
+
+// Requirements is the parsed CompatFile. Zero values aren't checked
+type Requirements struct {
+	MinAPIVersion int    `json:"minApiVersion"`
+	MinQuickshell string `json:"minQuickshell"`
+}
+
+func ParseRequirements(data []byte) (Requirements, error) {
+	var req Requirements
+	if err := json.Unmarshal(data, &req); err != nil {
+		return Requirements{}, fmt.Errorf("invalid %s: %w", CompatFile, err)
+	}
+	return req, nil
+}
+
+// RequirementsAt reads CompatFile from a shell git checkout at rev without
+// checking it out, so an update can be refused before it happens. ok is
+// false when that revision ships no CompatFile; a rev that doesn't resolve is
+// an error, since nothing about it can be checked
+func RequirementsAt(dmsPath, rev string) (req Requirements, ok bool, err error) {
+	if exec.Command("git", "-C", dmsPath, "rev-parse", "-q", "--verify", rev+"^{commit}").Run() != nil {
+		return Requirements{}, false, fmt.Errorf("unknown shell revision %s", rev)
+	}
+
+	object := rev + ":" + CompatFile
+	if exec.Command("git", "-C", dmsPath, "cat-file", "-e", object).Run() != nil {
+		return Requirements{}, false, nil
+	}
+
+	data, err := exec.Command("git", "-C", dmsPath, "show", object).Output()
+	if err != nil {
+		return Requirements{}, false, fmt.Errorf("failed to read %s: %w", object, err)
+	}
+	req, err = ParseRequirements(data)
+	return req, err == nil, err
+}
+
+// RequirementsForRelease fetches CompatFile for the latest DMS shell release,
+// for installs that update through a package rather than a git checkout
+func RequirementsForRelease() (tag string, req Requirements, ok bool, err error) {
+	output, err := exec.Command("curl", "-fsSL", "--max-time", "5", "https://api.github.com/repos/AvengeMedia/DankMaterialShell/releases/latest").Output()
+	if err != nil {
+		return "", Requirements{}, false, fmt.Errorf("failed to fetch latest shell release: %w", err)
+	}
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.Unmarshal(output, &release); err != nil || release.TagName == "" {
+		return "", Requirements{}, false, fmt.Errorf("failed to parse latest shell release")
+	}
+	tag = release.TagName
+
+	url := fmt.Sprintf("https://raw.githubusercontent.com/AvengeMedia/DankMaterialShell/%s/%s", tag, CompatFile)
+	output, err = exec.Command("curl", "-sSL", "--max-time", "5", "-w", "\n%{http_code}", url).Output()
+	if err != nil {
+		return tag, Requirements{}, false, fmt.Errorf("failed to fetch %s for %s: %w", CompatFile, tag, err)
+	}
+	i := strings.LastIndex(string(output), "\n")
+	body, status := output[:i+1], string(output[i+1:])
+	switch status {
+	case "200":
+	case "404":
+		return tag, Requirements{}, false, nil
+	default:
+		return tag, Requirements{}, false, fmt.Errorf("failed to fetch %s for %s: HTTP %s", CompatFile, tag, status)
+	}
+
+	req, err = ParseRequirements(body)
+	return tag, req, err == nil, err
+}
+
+// shellRepoURL is where packages building the shell from git fetch it
+const shellRepoURL = "https://github.com/AvengeMedia/DankMaterialShell.git"
+
+// RequirementsForBranch fetches the tip of branch of the DMS shell repo and
+// reads CompatFile there, for -git packages that build that tip. rev is
+// branch@shortrev, like a git checkout's version
+func RequirementsForBranch(branch string) (rev string, req Requirements, ok bool, err error) {
+	return requirementsForRemoteBranch(shellRepoURL, branch)
+}
+
+func requirementsForRemoteBranch(url, branch string) (rev string, req Requirements, ok bool, err error) {
+	dir, err := os.MkdirTemp("", "dms-compat-")
+	if err != nil {
+		return "", Requirements{}, false, err
+	}
+	defer os.RemoveAll(dir)
+
+	if output, err := exec.Command("git", "-C", dir, "init", "-q").CombinedOutput(); err != nil {
+		return "", Requirements{}, false, fmt.Errorf("failed to init %s: %s", dir, strings.TrimSpace(string(output)))
+	}
+	fetch := exec.Command("timeout", "30s", "git", "-C", dir, "fetch", "-q", "--depth", "1", url, branch)
+	if output, err := fetch.CombinedOutput(); err != nil {
+		return "", Requirements{}, false, fmt.Errorf("failed to fetch shell %s: %s", branch, strings.TrimSpace(string(output)))
+	}
+
+	short, err := exec.Command("git", "-C", dir, "rev-parse", "--short", "FETCH_HEAD").Output()
+	if err != nil {
+		return "", Requirements{}, false, fmt.Errorf("failed to resolve shell %s: %w", branch, err)
+	}
+	rev = branch + "@" + strings.TrimSpace(string(short))
+
+	req, ok, err = RequirementsAt(dir, "FETCH_HEAD")
+	return rev, req, ok, err
+}
+
+// RequirementsIn reads CompatFile from an installed shell directory
+func RequirementsIn(dir string) (req Requirements, ok bool, err error) {
+	data, err := os.ReadFile(filepath.Join(dir, CompatFile))
+	if os.IsNotExist(err) {
+		return Requirements{}, false, nil
+	}
+	if err != nil {
+		return Requirements{}, false, err
+	}
+	req, err = ParseRequirements(data)
+	return req, err == nil, err
+}
+
+// Components describes an installed (or about to be installed) combination.
+// Zero values are unknown and not checked.
+type Components struct {
+	Shell      string // tag, or branch@rev for git checkouts
+	APIVersion int
+	Quickshell string
+}
+
+// Incompatibility is a single failed requirement
+type Incompatibility struct {
+	Component string
+	Have      string
+	Need      string
+}
+
+func (i Incompatibility) String() string {
+	return fmt.Sprintf("%s %s is too old (needs %s)", i.Component, i.Have, i.Need)
+}
+
+// CheckCompatibility returns every requirement of the shell the combination
+// misses
+func CheckCompatibility(req Requirements, c Components) []Incompatibility {
+	var problems []Incompatibility
+	if c.APIVersion > 0 && c.APIVersion < req.MinAPIVersion {
+		problems = append(problems, Incompatibility{
+			Component: "dms binary API",
+			Have:      fmt.Sprintf("v%d", c.APIVersion),
+			Need:      fmt.Sprintf("v%d+ for shell %s", req.MinAPIVersion, c.Shell),
+		})
+	}
+	if c.Quickshell != "" && req.MinQuickshell != "" && CompareVersions(c.Quickshell, req.MinQuickshell) < 0 {
+		problems = append(problems, Incompatibility{
+			Component: "quickshell",
+			Have:      c.Quickshell,
+			Need:      fmt.Sprintf("%s+ for shell %s", req.MinQuickshell, c.Shell),
+		})
+	}
+
+	return problems
+}
+
+var quickshellVersionRegex = regexp.MustCompile(`quickshell (\d+\.\d+\.\d+)`)
+
+// GetQuickshellVersion returns the installed quickshell version
+func GetQuickshellVersion() (string, error) {
+	output, err := exec.Command("qs", "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run qs --version: %w", err)
+	}
+
+	matches := quickshellVersionRegex.FindStringSubmatch(string(output))
+	if len(matches) < 2 {
+		return "", fmt.Errorf("unrecognized quickshell version: %s", strings.TrimSpace(string(output)))
+	}
+	return matches[1], nil
+}
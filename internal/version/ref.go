@@ -0,0 +1,105 @@
+package version
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ShellRef describes how a user supplied DMS shell ref is fetched and checked out
+type ShellRef struct {
+	Input     string
+	FetchSpec string // refspec passed to git fetch origin, tags when empty
+	Checkout  string // ref handed to git checkout
+}
+
+// ParseShellRef accepts a tag (v0.1.20), a branch (master), a pull request
+// (pr/123 or #123) or a commit
+func ParseShellRef(ref string) (ShellRef, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ShellRef{}, fmt.Errorf("empty shell ref")
+	}
+	if strings.ContainsAny(ref, " \t~^:?*[\\") || strings.HasPrefix(ref, "-") {
+		return ShellRef{}, fmt.Errorf("invalid shell ref: %q", ref)
+	}
+
+	for _, prefix := range []string{"pr/", "#", "pull/"} {
+		if !strings.HasPrefix(ref, prefix) {
+			continue
+		}
+		number := strings.TrimSuffix(strings.TrimPrefix(ref, prefix), "/head")
+		if number == "" || strings.Trim(number, "0123456789") != "" {
+			return ShellRef{}, fmt.Errorf("invalid pull request ref: %q", ref)
+		}
+		branch := "pr-" + number
+		return ShellRef{
+			Input:     ref,
+			FetchSpec: fmt.Sprintf("+pull/%s/head:%s", number, branch),
+			Checkout:  branch,
+		}, nil
+	}
+
+	return ShellRef{Input: ref, Checkout: ref}, nil
+}
+
+// FetchShellRef fetches ref from origin so it can be inspected before
+// checking it out
+func FetchShellRef(dmsPath, ref string) (ShellRef, error) {
+	parsed, err := ParseShellRef(ref)
+	if err != nil {
+		return ShellRef{}, err
+	}
+
+	fetchArgs := []string{"-C", dmsPath, "fetch", "origin", "--force", "--tags"}
+	if parsed.FetchSpec != "" {
+		fetchArgs = append(fetchArgs, parsed.FetchSpec)
+	}
+	if output, err := exec.Command("git", fetchArgs...).CombinedOutput(); err != nil {
+		return ShellRef{}, fmt.Errorf("failed to fetch %s: %s", ref, strings.TrimSpace(string(output)))
+	}
+
+	return parsed, nil
+}
+
+// Revision is what a fetched ref resolves to in the checkout at dmsPath:
+// the remote branch when ref names one, otherwise Checkout itself
+func (r ShellRef) Revision(dmsPath string) string {
+	if !isTag(dmsPath, r.Checkout) && remoteBranchExists(dmsPath, r.Checkout) {
+		return "origin/" + r.Checkout
+	}
+	return r.Checkout
+}
+
+// CheckoutShellRef switches the git checkout at dmsPath to ref, tracking the
+// remote branch when ref names one so later updates keep following it
+func CheckoutShellRef(dmsPath, ref string) (string, error) {
+	parsed, err := FetchShellRef(dmsPath, ref)
+	if err != nil {
+		return "", err
+	}
+	if err := SwitchShellRef(dmsPath, parsed); err != nil {
+		return "", err
+	}
+	return parsed.Checkout, nil
+}
+
+// SwitchShellRef checks out a ref already fetched with FetchShellRef
+func SwitchShellRef(dmsPath string, parsed ShellRef) error {
+	checkoutArgs := []string{"-C", dmsPath, "checkout", parsed.Checkout}
+	if rev := parsed.Revision(dmsPath); rev != parsed.Checkout {
+		checkoutArgs = []string{"-C", dmsPath, "checkout", "-B", parsed.Checkout, rev}
+	}
+	if output, err := exec.Command("git", checkoutArgs...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to checkout %s: %s", parsed.Input, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func isTag(dmsPath, ref string) bool {
+	return exec.Command("git", "-C", dmsPath, "rev-parse", "-q", "--verify", "refs/tags/"+ref).Run() == nil
+}
+
+func remoteBranchExists(dmsPath, ref string) bool {
+	return exec.Command("git", "-C", dmsPath, "rev-parse", "-q", "--verify", "refs/remotes/origin/"+ref).Run() == nil
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -299,3 +300,130 @@ func TestCompareVersions_EdgeCases(t *testing.T) {
 		}
 	}
 }
+
+func TestRequirementsAt(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+
+	git("init", "-q")
+	if err := os.WriteFile(filepath.Join(dir, "shell.qml"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "before compat file")
+	git("tag", "v0.1.0")
+
+	if err := os.WriteFile(filepath.Join(dir, CompatFile), []byte(`{"minApiVersion": 13, "minQuickshell": "0.2.0"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "declare requirements")
+	git("tag", "v0.2.0")
+	git("checkout", "-q", "v0.1.0")
+
+	if _, ok, err := RequirementsAt(dir, "v0.1.0"); ok || err != nil {
+		t.Errorf("Expected a revision without %s to declare nothing, got %v %v", CompatFile, ok, err)
+	}
+
+	req, ok, err := RequirementsAt(dir, "v0.2.0")
+	if err != nil || !ok {
+		t.Fatalf("Expected requirements at v0.2.0, got %v %v", ok, err)
+	}
+	if req.MinAPIVersion != 13 || req.MinQuickshell != "0.2.0" {
+		t.Errorf("Unexpected requirements %+v", req)
+	}
+
+	if _, ok, err := RequirementsAt(dir, "origin/pr-42"); ok || err == nil {
+		t.Errorf("Expected an unresolvable revision to be an error, got %v %v", ok, err)
+	}
+
+	git("branch", "-q", "-f", "master", "v0.2.0")
+	rev, req, ok, err := requirementsForRemoteBranch(dir, "master")
+	if err != nil || !ok || req.MinAPIVersion != 13 {
+		t.Errorf("Expected the master tip's requirements, got %+v %v %v", req, ok, err)
+	}
+	if !strings.HasPrefix(rev, "master@") {
+		t.Errorf("Expected a branch@rev revision, got %q", rev)
+	}
+	if _, _, _, err := requirementsForRemoteBranch(dir, "missing"); err == nil {
+		t.Error("Expected a missing branch to be an error")
+	}
+
+	if _, ok, _ := RequirementsIn(dir); ok {
+		t.Error("Expected the checked-out v0.1.0 tree to declare nothing")
+	}
+}
+
+func TestParseRequirementsInvalid(t *testing.T) {
+	if _, err := ParseRequirements([]byte("not json")); err == nil {
+		t.Error("Expected invalid compat file to fail")
+	}
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	req := Requirements{MinAPIVersion: 13, MinQuickshell: "0.2.0"}
+
+	problems := CheckCompatibility(req, Components{Shell: "v0.2.0", APIVersion: 8, Quickshell: "0.1.0"})
+	if len(problems) != 2 {
+		t.Fatalf("Expected 2 incompatibilities, got %v", problems)
+	}
+	if problems[0].Component != "dms binary API" || problems[1].Component != "quickshell" {
+		t.Errorf("Unexpected incompatibilities: %v", problems)
+	}
+
+	if problems := CheckCompatibility(req, Components{Shell: "v0.2.0", APIVersion: 13, Quickshell: "0.2.1"}); len(problems) != 0 {
+		t.Errorf("Expected compatible combination, got %v", problems)
+	}
+
+	if problems := CheckCompatibility(req, Components{Shell: "v0.2.0"}); len(problems) != 0 {
+		t.Errorf("Expected unknown components to be skipped, got %v", problems)
+	}
+}
+
+func TestParseShellRef(t *testing.T) {
+	tests := []struct {
+		ref       string
+		fetchSpec string
+		checkout  string
+		wantErr   bool
+	}{
+		{ref: "v0.1.20", checkout: "v0.1.20"},
+		{ref: "master", checkout: "master"},
+		{ref: "feature/dock", checkout: "feature/dock"},
+		{ref: "pr/123", fetchSpec: "+pull/123/head:pr-123", checkout: "pr-123"},
+		{ref: "#45", fetchSpec: "+pull/45/head:pr-45", checkout: "pr-45"},
+		{ref: "pull/7/head", fetchSpec: "+pull/7/head:pr-7", checkout: "pr-7"},
+		{ref: "", wantErr: true},
+		{ref: "pr/abc", wantErr: true},
+		{ref: "--upload-pack=x", wantErr: true},
+		{ref: "a:b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			parsed, err := ParseShellRef(tt.ref)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for %q", tt.ref)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if parsed.FetchSpec != tt.fetchSpec || parsed.Checkout != tt.checkout {
+				t.Errorf("ParseShellRef(%q) = %+v", tt.ref, parsed)
+			}
+		})
+	}
+}